@@ -1,12 +1,20 @@
 package client
 
 import (
+	"context"
 	"fmt"
+	"net"
 
+	"github.com/craftcms/nitro/pkg/apitoken"
 	"github.com/craftcms/nitro/protob"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// Dialer returns a connection to the gRPC API running in the proxy container.
+type Dialer func(ctx context.Context) (net.Conn, error)
+
 // NewClient is used for generating a new client to interact
 // with the gRPC API running in the proxy container
 func NewClient(ip, port string) (protob.NitroClient, error) {
@@ -17,3 +25,57 @@ func NewClient(ip, port string) (protob.NitroClient, error) {
 
 	return protob.NewNitroClient(cc), nil
 }
+
+// NewClientWithPort is like NewClient but looks up the port each time it connects,
+// so the port can come from a config that is loaded after the client is created.
+func NewClientWithPort(ip string, port func() string) (protob.NitroClient, error) {
+	return NewClientWithDialer(func(ctx context.Context) (net.Conn, error) {
+		return DialTCP(ctx, ip, port())
+	})
+}
+
+// NewClientWithDialer is like NewClient but uses the dialer each time it connects,
+// so the API can be reached without a port on the host (e.g. with docker exec).
+func NewClientWithDialer(dial Dialer, opts ...grpc.DialOption) (protob.NitroClient, error) {
+	dialer := func(ctx context.Context, _ string) (net.Conn, error) {
+		return dial(ctx)
+	}
+
+	opts = append([]grpc.DialOption{grpc.WithInsecure(), grpc.WithContextDialer(dialer)}, opts...)
+
+	cc, err := grpc.Dial("nitrod", opts...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create a gRPC client for nitrod, %w", err)
+	}
+
+	return protob.NewNitroClient(cc), nil
+}
+
+// WithToken sends the token for the nitrod API, from the users home directory, with each request.
+func WithToken(home string) grpc.DialOption {
+	return grpc.WithPerRPCCredentials(apitoken.Credentials{Home: home})
+}
+
+// DialTCP connects to the gRPC API on the ip and port.
+func DialTCP(ctx context.Context, ip, port string) (net.Conn, error) {
+	return (&net.Dialer{}).DialContext(ctx, "tcp", net.JoinHostPort(ip, port))
+}
+
+// Capabilities returns the version and the capabilities of the API. Proxies from before the
+// Capabilities RPC do not return an error and have no version or capabilities.
+func Capabilities(ctx context.Context, nitrod protob.NitroClient) (string, map[string]bool, error) {
+	resp, err := nitrod.Capabilities(ctx, &protob.CapabilitiesRequest{})
+	if status.Code(err) == codes.Unimplemented {
+		return "", map[string]bool{}, nil
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to get the capabilities of the proxy, %w", err)
+	}
+
+	capabilities := map[string]bool{}
+	for _, c := range resp.GetCapabilities() {
+		capabilities[c] = true
+	}
+
+	return resp.GetVersion(), capabilities, nil
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/craftcms/nitro/protob"
+)
+
+type capabilitiesMock struct {
+	protob.NitroClient
+
+	resp *protob.CapabilitiesResponse
+	err  error
+}
+
+func (m *capabilitiesMock) Capabilities(ctx context.Context, in *protob.CapabilitiesRequest, opts ...grpc.CallOption) (*protob.CapabilitiesResponse, error) {
+	return m.resp, m.err
+}
+
+func TestCapabilities(t *testing.T) {
+	tests := []struct {
+		name        string
+		mock        *capabilitiesMock
+		wantVersion string
+		want        map[string]bool
+		wantErr     bool
+	}{
+		{
+			name:        "returns the version and capabilities",
+			mock:        &capabilitiesMock{resp: &protob.CapabilitiesResponse{Version: "2.1.0", Capabilities: []string{"status", "websockets"}}},
+			wantVersion: "2.1.0",
+			want:        map[string]bool{"status": true, "websockets": true},
+		},
+		{
+			name: "proxies without the rpc have no capabilities",
+			mock: &capabilitiesMock{err: status.Error(codes.Unimplemented, "unknown method Capabilities")},
+			want: map[string]bool{},
+		},
+		{
+			name:    "other errors are returned",
+			mock:    &capabilitiesMock{err: status.Error(codes.Unavailable, "connection refused")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, got, err := Capabilities(context.Background(), tt.mock)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Capabilities() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if version != tt.wantVersion {
+				t.Errorf("expected the version to be %q, got %q", tt.wantVersion, version)
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected the capabilities to be %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
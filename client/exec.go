@@ -0,0 +1,60 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// DialExec connects to the gRPC API by running nitrod -pipe in the container with docker exec,
+// which pipes stdin and stdout to the API socket, so the API port is not bound on the host.
+func DialExec(ctx context.Context, docker client.ContainerAPIClient, container string) (net.Conn, error) {
+	exec, err := docker.ContainerExecCreate(ctx, container, types.ExecConfig{
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+		Cmd:          []string{"nitrod", "-pipe"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the exec for nitrod, %w", err)
+	}
+
+	resp, err := docker.ContainerExecAttach(ctx, exec.ID, types.ExecStartCheck{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to attach to the exec for nitrod, %w", err)
+	}
+
+	// stdout and stderr are multiplexed since the exec does not use a tty
+	r, w := io.Pipe()
+	go func() {
+		_, err := stdcopy.StdCopy(w, ioutil.Discard, resp.Reader)
+		w.CloseWithError(err)
+	}()
+
+	return &execConn{Conn: resp.Conn, resp: resp, stdout: r}, nil
+}
+
+// execConn writes to the stdin of the exec and reads from its stdout.
+type execConn struct {
+	net.Conn
+
+	resp   types.HijackedResponse
+	stdout *io.PipeReader
+}
+
+func (c *execConn) Read(b []byte) (int, error) {
+	return c.stdout.Read(b)
+}
+
+func (c *execConn) Close() error {
+	c.stdout.Close()
+	c.resp.Close()
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+type execMock struct {
+	client.ContainerAPIClient
+
+	container string
+	config    types.ExecConfig
+	output    []byte
+	conn      net.Conn
+}
+
+func (m *execMock) ContainerExecCreate(ctx context.Context, container string, config types.ExecConfig) (types.IDResponse, error) {
+	m.container = container
+	m.config = config
+
+	return types.IDResponse{ID: "exec-id"}, nil
+}
+
+func (m *execMock) ContainerExecAttach(ctx context.Context, execID string, config types.ExecStartCheck) (types.HijackedResponse, error) {
+	return types.HijackedResponse{Conn: m.conn, Reader: bufio.NewReader(bytes.NewReader(m.output))}, nil
+}
+
+func TestDialExec(t *testing.T) {
+	// the exec output is multiplexed
+	output := &bytes.Buffer{}
+	stdcopy.NewStdWriter(output, stdcopy.Stdout).Write([]byte("pong"))
+	stdcopy.NewStdWriter(output, stdcopy.Stderr).Write([]byte("nitrod: not shown"))
+
+	local, remote := net.Pipe()
+	defer remote.Close()
+
+	mock := &execMock{output: output.Bytes(), conn: local}
+
+	conn, err := DialExec(context.Background(), mock, "nitro-proxy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if mock.container != "nitro-proxy" {
+		t.Errorf("expected the container to be nitro-proxy, got %s", mock.container)
+	}
+
+	if want := []string{"nitrod", "-pipe"}; !reflect.DeepEqual(mock.config.Cmd, want) {
+		t.Errorf("expected the command to be %v, got %v", want, mock.config.Cmd)
+	}
+
+	// writes go to the stdin of the exec
+	go conn.Write([]byte("ping"))
+
+	stdin := make([]byte, 4)
+	if _, err := remote.Read(stdin); err != nil {
+		t.Fatal(err)
+	}
+
+	if string(stdin) != "ping" {
+		t.Errorf("expected the stdin to be ping, got %q", stdin)
+	}
+
+	// reads only return stdout
+	stdout, err := ioutil.ReadAll(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(stdout) != "pong" {
+		t.Errorf("expected the stdout to be pong, got %q", stdout)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"flag"
+	"io"
 	"log"
 	"net"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"google.golang.org/grpc"
 
 	"github.com/craftcms/nitro/pkg/api"
+	"github.com/craftcms/nitro/pkg/apitoken"
 	"github.com/craftcms/nitro/protob"
 )
 
@@ -19,23 +21,76 @@ func main() {
 	// assign the port as a flag with a default
 	port := flag.String("port", "5000", "which port API should listen on")
 	addr := flag.String("addr", "http://127.0.0.1:2019", "the address for the Caddy API")
+	socket := flag.String("socket", "/var/run/nitrod.sock", "the unix socket the API should listen on")
+	pipe := flag.Bool("pipe", false, "connect stdin and stdout to the API socket, used by the CLI through docker exec")
 	flag.Parse()
 
+	// the CLI runs nitrod -pipe with docker exec when the API port is not bound on the host
+	if *pipe {
+		if err := pipeSocket(*socket); err != nil {
+			// log to stderr so the output is not sent to the CLI
+			log.SetOutput(os.Stderr)
+			log.Fatal(err)
+		}
+
+		return
+	}
+
 	// create the network listener
 	lis, err := net.Listen("tcp", "0.0.0.0:"+*port)
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	// remove the socket from a previous run
+	if err := os.RemoveAll(*socket); err != nil {
+		log.Fatal(err)
+	}
+
+	sock, err := net.Listen("unix", *socket)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// require the token when the proxy was created with one
+	var opts []grpc.ServerOption
+	if token := os.Getenv(apitoken.EnvironmentVariable); token != "" {
+		opts = append(opts, grpc.UnaryInterceptor(apitoken.UnaryServerInterceptor(token)), grpc.StreamInterceptor(apitoken.StreamServerInterceptor(token)))
+	}
+
 	// create the grpc server
-	s := grpc.NewServer()
+	s := grpc.NewServer(opts...)
 
 	protob.RegisterNitroServer(s, api.NewService(*addr))
 
-	log.Println("gRPC API listening on port", *port)
+	log.Println("gRPC API listening on port", *port, "and socket", *socket)
+
+	go func() {
+		if err := s.Serve(sock); err != nil {
+			log.Fatal("error when running the gRPC API on the socket", err)
+		}
+	}()
 
 	// server the grpc service
 	if err := s.Serve(lis); err != nil {
 		log.Fatal("error when running the gRPC API", err)
 	}
 }
+
+// pipeSocket copies stdin to the socket and the socket to stdout until either side is closed.
+func pipeSocket(socket string) error {
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	go func() {
+		io.Copy(conn, os.Stdin)
+		conn.(*net.UnixConn).CloseWrite()
+	}()
+
+	_, err = io.Copy(os.Stdout, conn)
+
+	return err
+}
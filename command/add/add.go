@@ -11,6 +11,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 
+	"github.com/craftcms/nitro/pkg/config"
 	"github.com/craftcms/nitro/pkg/envedit"
 	"github.com/craftcms/nitro/pkg/pathexists"
 	"github.com/craftcms/nitro/pkg/prompt"
@@ -21,7 +22,10 @@ const exampleText = `  # add the current directory as a site
   nitro add
 
   # add a directory as the site
-  nitro add my-project`
+  nitro add my-project
+
+  # add each project in a directory as a site
+  nitro add --scan ~/dev`
 
 // NewCommand returns the command to add a site to the nitro config.
 func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
@@ -36,6 +40,10 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 			return prompt.RunApply(cmd, args, false, output)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if scan := cmd.Flag("scan").Value.String(); scan != "" {
+				return addProjects(cmd, home, scan, output)
+			}
+
 			// get the current working directory
 			wd, err := os.Getwd()
 			if err != nil {
@@ -159,5 +167,74 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 		},
 	}
 
+	cmd.Flags().String("scan", "", "add each project in the directory as a site")
+
 	return cmd
 }
+
+// addProjects scans the directory for projects and prompts the user to add each project as a site.
+func addProjects(cmd *cobra.Command, home, dir string, output terminal.Outputer) error {
+	// check if the path is using the ~
+	if strings.HasPrefix(dir, "~") {
+		dir = strings.Replace(dir, "~", home, 1)
+	}
+
+	if !pathexists.IsDirectory(dir) {
+		return fmt.Errorf("unable to find the directory: %s", dir)
+	}
+
+	cfg, err := config.Load(home)
+	if err != nil {
+		return err
+	}
+
+	output.Pending("scanning", dir)
+
+	sites, err := scanProjects(home, dir, cfg)
+	if err != nil {
+		output.Warning()
+		return err
+	}
+
+	output.Done()
+
+	if len(sites) == 0 {
+		output.Info("No new projects found in", dir)
+		return nil
+	}
+
+	output.Info(fmt.Sprintf("Found %d new projects…", len(sites)))
+
+	var added int
+	for _, site := range sites {
+		confirm, err := output.Confirm(fmt.Sprintf("Add %s (%s, web root %s, PHP %s)?", site.Hostname, site.Path, site.Webroot, site.Version), true, "")
+		if err != nil {
+			return err
+		}
+
+		if !confirm {
+			continue
+		}
+
+		if err := cfg.AddSite(site); err != nil {
+			output.Info("Warning:", "unable to add", site.Hostname+",", err.Error())
+			continue
+		}
+
+		output.Success("adding site", site.Hostname)
+
+		added++
+	}
+
+	if added == 0 {
+		return nil
+	}
+
+	if err := cfg.Save(); err != nil {
+		return err
+	}
+
+	output.Info(fmt.Sprintf("%d new sites added! 🎉", added))
+
+	return nil
+}
@@ -0,0 +1,254 @@
+package add
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/pathexists"
+	"github.com/craftcms/nitro/pkg/phpversions"
+)
+
+var (
+	// webroots are the directories checked for the web root of a project, in order
+	webroots = []string{"web", "public", "public_html", "html"}
+
+	// operatorRegex matches the spaces after an operator in a constraint (e.g. >= 7.2)
+	operatorRegex = regexp.MustCompile(`(>=|<=|!=|>|<|=|\^|~)\s+`)
+)
+
+// scanProjects returns a site for each directory in the dir with a composer.json file. The
+// web root and PHP version are detected from the project and sites that are already in the
+// config are skipped.
+func scanProjects(home, dir string, cfg *config.Config) ([]config.Site, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the directory %s, %w", dir, err)
+	}
+
+	// the existing sites are skipped
+	existing := map[string]bool{}
+	for _, s := range cfg.Sites {
+		if p, err := s.GetAbsPath(home); err == nil {
+			existing[p] = true
+		}
+	}
+
+	var sites []config.Site
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+
+		path, err := filepath.Abs(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		if existing[path] || !pathexists.IsFile(filepath.Join(path, "composer.json")) {
+			continue
+		}
+
+		site := config.Site{
+			Hostname: e.Name(),
+			Path:     strings.Replace(path, home, "~", 1),
+			Webroot:  detectWebroot(path),
+			Version:  detectVersion(path),
+		}
+
+		// append the configs domain if there are no periods
+		if !strings.Contains(site.Hostname, ".") {
+			site.Hostname = fmt.Sprintf("%s.%s", site.Hostname, cfg.GetTLD())
+		}
+
+		// the project file settings are used first
+		project, err := config.LoadProject(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if project != nil {
+			if project.Hostname != "" {
+				site.Hostname = project.Hostname
+			}
+
+			site = site.Merge(*project)
+		}
+
+		sites = append(sites, site)
+	}
+
+	sort.SliceStable(sites, func(i, j int) bool {
+		return sites[i].Hostname < sites[j].Hostname
+	})
+
+	return sites, nil
+}
+
+// detectWebroot returns the first web root in the project, only the top level directories are
+// checked so scanning a large number of projects is fast.
+func detectWebroot(path string) string {
+	for _, w := range webroots {
+		if pathexists.IsDirectory(filepath.Join(path, w)) {
+			return w
+		}
+	}
+
+	return "web"
+}
+
+// detectVersion returns the PHP version for the project from the composer.json. The platform
+// config is used first, then the highest supported version that matches the PHP requirement.
+func detectVersion(path string) string {
+	fallback := phpversions.Versions[0]
+
+	b, err := ioutil.ReadFile(filepath.Join(path, "composer.json"))
+	if err != nil {
+		return fallback
+	}
+
+	var pkg struct {
+		Require map[string]string `json:"require"`
+		Config  struct {
+			Platform map[string]string `json:"platform"`
+		} `json:"config"`
+	}
+
+	if err := json.Unmarshal(b, &pkg); err != nil {
+		return fallback
+	}
+
+	if v := pkg.Config.Platform["php"]; v != "" {
+		parts := strings.Split(v, ".")
+		if len(parts) >= 2 {
+			for _, s := range phpversions.Versions {
+				if s == parts[0]+"."+parts[1] {
+					return s
+				}
+			}
+		}
+	}
+
+	constraint := pkg.Require["php"]
+	if constraint == "" {
+		return fallback
+	}
+
+	for _, v := range phpversions.Versions {
+		if satisfies(v, constraint) {
+			return v
+		}
+	}
+
+	return fallback
+}
+
+// satisfies returns true when the PHP version (e.g. 7.4) matches the composer constraint. Any
+// patch release of the version can match (e.g. 7.2 matches ^7.2.5).
+func satisfies(version, constraint string) bool {
+	v := parseVersion(version)
+	v[2] = 999
+
+	constraint = strings.ReplaceAll(constraint, "||", "|")
+	constraint = operatorRegex.ReplaceAllString(constraint, "$1")
+	for _, set := range strings.Split(constraint, "|") {
+		matched := true
+		for _, c := range strings.FieldsFunc(set, func(r rune) bool { return r == ' ' || r == ',' }) {
+			if !matches(v, c) {
+				matched = false
+				break
+			}
+		}
+
+		if matched && strings.TrimSpace(set) != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matches returns true when the version matches a single comparator (e.g. >=7.2 or ^8.0).
+func matches(v [3]int, c string) bool {
+	for _, op := range []string{">=", "<=", "!=", ">", "<", "^", "~", "="} {
+		if !strings.HasPrefix(c, op) {
+			continue
+		}
+
+		target := parseVersion(strings.TrimPrefix(c, op))
+		switch op {
+		case ">=":
+			return compare(v, target) >= 0
+		case "<=":
+			return compare(v, target) <= 0
+		case "!=":
+			return compare(v, target) != 0
+		case ">":
+			return compare(v, target) > 0
+		case "<":
+			// the patch is not known, so only the minor version is compared
+			return compare([3]int{v[0], v[1]}, target) < 0
+		case "^":
+			upper := [3]int{target[0] + 1}
+			if target[0] == 0 {
+				upper = [3]int{0, target[1] + 1}
+			}
+
+			return compare(v, target) >= 0 && compare(v, upper) < 0
+		case "~":
+			upper := [3]int{target[0] + 1}
+			if strings.Count(strings.TrimPrefix(c, op), ".") >= 2 {
+				upper = [3]int{target[0], target[1] + 1}
+			}
+
+			return compare(v, target) >= 0 && compare(v, upper) < 0
+		}
+
+		c = strings.TrimPrefix(c, op)
+		break
+	}
+
+	// exact versions and wildcards (e.g. 7.4 or 7.*) match the version prefix
+	parts := strings.Split(strings.TrimPrefix(c, "v"), ".")
+	for i, p := range parts {
+		if p == "*" || p == "x" || i > 1 {
+			return true
+		}
+
+		n, err := strconv.Atoi(p)
+		if err != nil || n != v[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func parseVersion(s string) [3]int {
+	var v [3]int
+	for i, p := range strings.SplitN(strings.TrimPrefix(strings.TrimSpace(s), "v"), ".", 3) {
+		n, _ := strconv.Atoi(strings.TrimRight(p, ".*x"))
+		v[i] = n
+	}
+
+	return v
+}
+
+func compare(a, b [3]int) int {
+	for i := range a {
+		switch {
+		case a[i] > b[i]:
+			return 1
+		case a[i] < b[i]:
+			return -1
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,86 @@
+package add
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/craftcms/nitro/pkg/config"
+)
+
+func Test_scanProjects(t *testing.T) {
+	home := t.TempDir()
+	dir := filepath.Join(home, "dev")
+
+	files := map[string]string{
+		"craft/composer.json":       `{"require": {"php": "^7.2.5"}}`,
+		"craft/web/index.php":       "<?php",
+		"laravel/composer.json":     `{"require": {"php": "^8.0"}}`,
+		"laravel/public/index.php":  "<?php",
+		"existing/composer.json":    `{}`,
+		"notes/readme.md":           "not a project",
+		"custom/composer.json":      `{"config": {"platform": {"php": "7.3.33"}}}`,
+		"custom/.nitro.yaml":        "hostname: custom.test\n",
+		".hidden/composer.json":     `{}`,
+		"craft/vendor/web/file.php": "<?php",
+	}
+
+	for f, content := range files {
+		file := filepath.Join(dir, f)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := &config.Config{
+		Sites: []config.Site{{Hostname: "existing.nitro", Path: "~/dev/existing"}},
+	}
+
+	got, err := scanProjects(home, dir, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []config.Site{
+		{Hostname: "craft.nitro", Path: "~/dev/craft", Webroot: "web", Version: "7.4"},
+		{Hostname: "custom.test", Path: "~/dev/custom", Webroot: "web", Version: "7.3"},
+		{Hostname: "laravel.nitro", Path: "~/dev/laravel", Webroot: "public", Version: "8.1"},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("scanProjects() = %v, want %v", got, want)
+	}
+}
+
+func Test_satisfies(t *testing.T) {
+	tests := []struct {
+		version    string
+		constraint string
+		want       bool
+	}{
+		{version: "7.2", constraint: "^7.2.5", want: true},
+		{version: "8.0", constraint: "^7.2.5", want: false},
+		{version: "8.0", constraint: "^7.2.5|^8.0", want: true},
+		{version: "8.1", constraint: "^7.4 || ~8.0.0", want: false},
+		{version: "8.0", constraint: ">= 7.3, <8.1", want: true},
+		{version: "8.1", constraint: ">=7.3 <8.1", want: false},
+		{version: "7.4", constraint: "7.4.*", want: true},
+		{version: "7.3", constraint: "7.4.*", want: false},
+		{version: "8.1", constraint: "~8.0", want: true},
+		{version: "7.1", constraint: ">7.1", want: true},
+		{version: "7.0", constraint: ">7.1", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version+" "+tt.constraint, func(t *testing.T) {
+			if got := satisfies(tt.version, tt.constraint); got != tt.want {
+				t.Errorf("satisfies() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
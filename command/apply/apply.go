@@ -1,36 +1,48 @@
 package apply
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/client"
+	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 
+	nitroclient "github.com/craftcms/nitro/client"
 	"github.com/craftcms/nitro/command/apply/internal/customcontainer"
 	"github.com/craftcms/nitro/command/apply/internal/databasecontainer"
 	"github.com/craftcms/nitro/command/apply/internal/sitecontainer"
+	"github.com/craftcms/nitro/pkg/api"
+	"github.com/craftcms/nitro/pkg/apitoken"
 	"github.com/craftcms/nitro/pkg/backup"
+	"github.com/craftcms/nitro/pkg/backupcontainer"
 	"github.com/craftcms/nitro/pkg/config"
 	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/dnscontainer"
 	"github.com/craftcms/nitro/pkg/wsl"
 
-	"github.com/craftcms/nitro/pkg/datetime"
 	"github.com/craftcms/nitro/pkg/hostedit"
+	"github.com/craftcms/nitro/pkg/platform"
 	"github.com/craftcms/nitro/pkg/proxycontainer"
 	"github.com/craftcms/nitro/pkg/sudo"
 	"github.com/craftcms/nitro/pkg/svc/dynamodb"
+	"github.com/craftcms/nitro/pkg/svc/elasticsearch"
 	"github.com/craftcms/nitro/pkg/svc/mailhog"
+	"github.com/craftcms/nitro/pkg/svc/meilisearch"
 	"github.com/craftcms/nitro/pkg/svc/minio"
+	"github.com/craftcms/nitro/pkg/svc/rabbitmq"
 	"github.com/craftcms/nitro/pkg/svc/redis"
 	"github.com/craftcms/nitro/pkg/terminal"
 	"github.com/craftcms/nitro/protob"
@@ -40,6 +52,37 @@ var (
 	defaultFile = "/etc/hosts"
 	hostnames   []string
 	isWSL       = false
+	runID       string
+
+	// dependencyTimeout is how long to wait for a sites dependencies to be ready
+	dependencyTimeout = time.Minute
+
+	// proxyTimeout is the default time to wait for the proxy API to be ready
+	proxyTimeout = time.Minute
+
+	// proxyBackoff is the first wait between pings to the proxy API, it doubles after each
+	// ping up to proxyMaxBackoff
+	proxyBackoff    = 250 * time.Millisecond
+	proxyMaxBackoff = 5 * time.Second
+
+	// ErrNoNetwork is returned when the nitro network does not exist
+	ErrNoNetwork = fmt.Errorf("No network was found…\nrun `nitro init` to get started")
+
+	// ErrNoExternalNetwork is returned when the external network in the config does not exist
+	ErrNoExternalNetwork = fmt.Errorf("unable to find the external network")
+
+	// ErrProxyTimeout is returned when the proxy API does not respond before the timeout
+	ErrProxyTimeout = fmt.Errorf("The proxy API is not responding…\nrun `nitro restart` and try again")
+
+	// ErrRunningAsRoot is returned when apply is run as root, which would create root owned files in ~/.nitro
+	ErrRunningAsRoot = fmt.Errorf("Nitro should not be run as root, it will create files in ~/.nitro that are owned by root…\nrun `nitro apply` without sudo or use --allow-root")
+
+	// getuid returns the user id of the current process and can be replaced in tests
+	getuid = os.Getuid
+
+	// LogFile is the file in the nitro directory with the output from the last apply, which is
+	// included in the bug report bundle
+	LogFile = "apply.log"
 )
 
 const exampleText = `  # apply changes from a config
@@ -48,7 +91,22 @@ const exampleText = `  # apply changes from a config
   # skip editing the hosts file
   nitro apply --skip-hosts
 
-  # you can also set the environment variable "NITRO_EDIT_HOSTS" to "false"`
+  # you can also set the environment variable "NITRO_EDIT_HOSTS" to "false"
+
+  # fail instead of creating a missing proxy (useful for CI)
+  nitro apply --strict
+
+  # show the planned changes without making them
+  nitro apply --dry-run
+
+  # show the PHP setting changes when sites are updated
+  nitro apply --preserve-env
+
+  # wait longer for the proxy on slower machines
+  nitro apply --proxy-timeout 3m
+
+  # only apply the changes for a single site
+  nitro apply --site craft-dev.nitro`
 
 // NewCommand returns the command used to apply configuration file changes to a nitro environment.
 func NewCommand(home string, docker client.CommonAPIClient, nitrod protob.NitroClient, output terminal.Outputer) *cobra.Command {
@@ -65,6 +123,11 @@ func NewCommand(home string, docker client.CommonAPIClient, nitrod protob.NitroC
 			return nil
 		},
 		PostRunE: func(cmd *cobra.Command, args []string) error {
+			// single site applies do not clean up other containers
+			if cmd.Flag("site").Value.String() != "" {
+				return nil
+			}
+
 			ctx := cmd.Context()
 			if ctx == nil {
 				c, cancel := context.WithTimeout(context.Background(), time.Minute*5)
@@ -78,6 +141,11 @@ func NewCommand(home string, docker client.CommonAPIClient, nitrod protob.NitroC
 				return err
 			}
 
+			// use the services from the profile
+			if err := cfg.UseProfile(cmd.Flag("profile").Value.String()); err != nil {
+				return err
+			}
+
 			// store all of the known container names
 			names := map[string]bool{}
 
@@ -91,30 +159,22 @@ func NewCommand(home string, docker client.CommonAPIClient, nitrod protob.NitroC
 				names[fmt.Sprintf("%s%s", c.Name, customcontainer.Suffix)] = true
 			}
 
-			// get all of the databases
-			for _, d := range cfg.Databases {
-				h, _ := d.GetHostname()
-				names[h] = true
-			}
-
-			// is dynamodb enabled
-			if cfg.Services.DynamoDB {
-				names[dynamodb.Host] = true
-			}
-
-			// is mailhog enabled
-			if cfg.Services.Mailhog {
-				names[mailhog.Host] = true
+			// get all of the databases and services
+			for _, n := range sharedNames(cfg) {
+				names[n] = true
 			}
 
-			// is minio enabled
-			if cfg.Services.Minio {
-				names[minio.Host] = true
+			// keep the databases and services the other environments use, if the other
+			// configs cannot be loaded none of the databases or services are removed
+			keepShared := false
+			others, err := otherEnvironmentNames(home)
+			if err != nil {
+				output.Info("Warning:", err.Error()+", skipping the removal of databases and services")
+				keepShared = true
 			}
 
-			// is redis enabled
-			if cfg.Services.Redis {
-				names[redis.Host] = true
+			for _, n := range others {
+				names[n] = true
 			}
 
 			// create a filter for the environment
@@ -131,9 +191,36 @@ func NewCommand(home string, docker client.CommonAPIClient, nitrod protob.NitroC
 				output.Info("Cleaning up…")
 			}
 
+			dryRun := cmd.Flag("dry-run").Value.String() == "true"
+			noBackup := cmd.Flag("no-backup").Value.String() == "true"
+
 			for _, c := range containers {
+				// sites and containers for other environments are stopped instead of removed
+				if containerlabels.OtherEnvironment(c) {
+					if c.State != "running" {
+						continue
+					}
+
+					name := strings.TrimLeft(c.Names[0], "/")
+					if dryRun {
+						output.Info("  would stop", name)
+						continue
+					}
+
+					output.Pending("stopping", name)
+
+					if err := docker.ContainerStop(ctx, c.ID, nil); err != nil {
+						output.Warning()
+						return err
+					}
+
+					output.Done()
+
+					continue
+				}
+
 				// start the container if not running
-				if c.State != "running" {
+				if c.State != "running" && !dryRun {
 					for _, command := range cmd.Root().Commands() {
 						if command.Use == "start" {
 							if err := command.RunE(cmd, []string{}); err != nil {
@@ -148,17 +235,38 @@ func NewCommand(home string, docker client.CommonAPIClient, nitrod protob.NitroC
 					continue
 				}
 
+				// skip tunnels for shared sites, they are removed when sharing stops
+				if c.Labels[containerlabels.Share] != "" {
+					continue
+				}
+
+				// skip the databases and services when the other environments are unknown
+				if keepShared && c.Labels[containerlabels.Host] == "" && c.Labels[containerlabels.NitroContainer] == "" {
+					continue
+				}
+
 				// set the container name
 				name := strings.TrimLeft(c.Names[0], "/")
 
 				// check if this is a known container
 				if _, ok := names[name]; !ok {
-					// don't remove the proxy container
+					// only report the removal for dry runs
+					if dryRun {
+						if c.Labels[containerlabels.DatabaseEngine] != "" && !skipBackup(c, noBackup) {
+							planned.backup(output, name)
+						}
+
+						planned.remove(output, name)
+
+						continue
+					}
 
 					output.Pending("removing", name)
 
 					// only perform a backup if the container is for databases
-					if c.Labels[containerlabels.DatabaseEngine] != "" {
+					if c.Labels[containerlabels.DatabaseEngine] != "" && skipBackup(c, noBackup) {
+						output.Info("Skipping the backup for", name)
+					} else if c.Labels[containerlabels.DatabaseEngine] != "" {
 						// get all of the databases
 						databases, err := backup.Databases(ctx, docker, c.ID, c.Labels[containerlabels.DatabaseCompatibility])
 						if err != nil {
@@ -167,39 +275,18 @@ func NewCommand(home string, docker client.CommonAPIClient, nitrod protob.NitroC
 							break
 						}
 
-						// backup each database
-						for _, db := range databases {
-							// create the database specific backup options
-							opts := &backup.Options{
-								BackupName:    fmt.Sprintf("%s-%s.sql", db, datetime.Parse(time.Now())),
-								ContainerID:   c.ID,
-								ContainerName: name,
-								Database:      db,
-								Home:          home,
-							}
-
-							// create the backup command based on the compatibility type
-							switch c.Labels[containerlabels.DatabaseCompatibility] {
-							case "postgres":
-								opts.Commands = []string{"pg_dump", "--username=nitro", db, "-f", "/tmp/" + opts.BackupName}
-							default:
-								opts.Commands = []string{"/usr/bin/mysqldump", "-h", "127.0.0.1", "-unitro", "--password=nitro", db, "--result-file=" + "/tmp/" + opts.BackupName}
-							}
-
-							output.Pending("creating backup", opts.BackupName)
-
-							// backup the container
-							if err := backup.Perform(ctx, docker, opts); err != nil {
-								output.Warning()
-								output.Info("Unable to backup database", db, err.Error())
-								break
-							}
-
-							output.Done()
+						// backup the databases at the same time
+						if failed := backupDatabases(ctx, docker, home, name, c, databases, cfg.Backups.Storage, output); failed > 0 {
+							output.Warning()
 						}
 
 						// show where all backups are saved for this container
 						output.Info("Backups saved in", filepath.Join(home, config.DirectoryName, name), "💾")
+
+						// remove old backups so the backups directory does not grow with each apply
+						if _, err := backup.RemoveOld(home, backup.Retention{Keep: cfg.Backups.Keep, KeepDays: cfg.Backups.KeepDays}, time.Now()); err != nil {
+							output.Info("Warning:", "unable to remove old backups,", err.Error())
+						}
 					}
 
 					// stop and remove a container we don't know about
@@ -216,6 +303,12 @@ func NewCommand(home string, docker client.CommonAPIClient, nitrod protob.NitroC
 				}
 			}
 
+			if dryRun {
+				output.Info(planned.summary())
+
+				return nil
+			}
+
 			if isWSL {
 				output.Info(fmt.Sprintf("For your hostnames to work, add the following to `%s`:", `C:\Windows\System32\Drivers\etc\hosts`))
 				output.Info("---- COPY BELOW ----")
@@ -225,12 +318,33 @@ func NewCommand(home string, docker client.CommonAPIClient, nitrod protob.NitroC
 				output.Info("---- COPY ABOVE ----")
 			}
 
-			output.Info("Nitro is up and running 😃")
+			output.Info("Nitro is up and running 😃 (run id " + runID + ")")
 
 			return nil
 		},
-		RunE: func(cmd *cobra.Command, args []string) error {
-			ctx := cmd.Root().Context()
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			// running as root creates files that break later runs without sudo
+			if isRoot() && cmd.Flag("allow-root").Value.String() != "true" {
+				return ErrRunningAsRoot
+			}
+
+			// keep the output from the last apply for bug reports
+			output := output
+			if f, ferr := os.Create(filepath.Join(home, config.DirectoryName, LogFile)); ferr == nil {
+				defer f.Close()
+				defer func() {
+					if err != nil {
+						fmt.Fprintf(f, "Error: %s\n", err)
+					}
+				}()
+
+				output = terminal.Tee(output, f)
+			}
+
+			// generate a short run id to label all of the containers created by this apply
+			runID = strings.Split(uuid.New().String(), "-")[0]
+
+			ctx := containerlabels.WithRunID(cmd.Root().Context(), runID)
 
 			// load the config
 			cfg, err := config.Load(home)
@@ -238,47 +352,127 @@ func NewCommand(home string, docker client.CommonAPIClient, nitrod protob.NitroC
 				return err
 			}
 
-			// create a filter for the environment
-			filter := filters.NewArgs()
-			filter.Add("label", containerlabels.Nitro+"=true")
+			// use the services from the profile
+			if err := cfg.UseProfile(cmd.Flag("profile").Value.String()); err != nil {
+				return err
+			}
 
-			// add the filter for the network name
-			filter.Add("name", "nitro-network")
+			// merge the project files from the sites
+			merged, err := cfg.MergeProjects(home)
+			if err != nil {
+				return err
+			}
 
-			output.Info("Checking network…")
+			for _, m := range merged {
+				output.Info("using project settings for", m)
+			}
 
-			// check the network
-			var network types.NetworkResource
-			networks, err := docker.NetworkList(ctx, types.NetworkListOptions{Filters: filter})
+			// validate the config
+			warnings, err := cfg.Validate(home)
 			if err != nil {
-				return fmt.Errorf("unable to list docker networks\n%w", err)
+				return err
 			}
 
-			// get the network for the environment
-			for _, n := range networks {
-				if n.Name == "nitro-network" {
-					network = n
-					break
+			for _, w := range warnings {
+				output.Info("Warning:", w)
+			}
+
+			// report the changes without making them
+			if cmd.Flag("dry-run").Value.String() == "true" {
+				return planApply(ctx, docker, home, cfg, cmd.Flag("site").Value.String(), cmd.Flag("skip-hosts").Value.String() == "true", output)
+			}
+
+			// keep the replaced site containers until the apply completes
+			rollback := &sitecontainer.Rollback{}
+			defer func() {
+				if rollback.Len() == 0 {
+					return
+				}
+
+				if err == nil {
+					if err := rollback.Commit(ctx, docker); err != nil {
+						output.Info("Warning:", err.Error())
+					}
+
+					return
+				}
+
+				confirm, cerr := output.Confirm("The apply failed, roll back the site containers?", true, "")
+				if cerr != nil || !confirm {
+					// remove the replaced containers so each site only has one container
+					if err := rollback.Commit(ctx, docker); err != nil {
+						output.Info("Warning:", err.Error())
+					}
+
+					return
+				}
+
+				output.Pending("rolling back")
+
+				if err := rollback.Restore(ctx, docker); err != nil {
+					output.Warning()
+					output.Info("Warning:", err.Error())
+
+					return
+				}
+
+				output.Done()
+			}()
+
+			// run the pre apply hooks before changing any containers
+			if len(cfg.Hooks.PreApply) > 0 {
+				output.Info("Running pre apply hooks…")
+
+				if err := runHooks(ctx, cfg.Hooks.PreApply, cmd.OutOrStdout(), cmd.ErrOrStderr()); err != nil {
+					return err
 				}
 			}
 
-			// if the network is not found
-			if network.ID == "" {
-				return fmt.Errorf("No network was found…\nrun `nitro init` to get started")
+			// only apply the changes for a single site
+			if hostname := cmd.Flag("site").Value.String(); hostname != "" {
+				return applySite(ctx, cmd, docker, nitrod, home, cfg, hostname, rollback, output)
 			}
 
-			// remove the filter
-			filter.Del("name", "nitro-network")
+			output.Info("Checking network…")
+
+			// check the network
+			network, err := findNetwork(ctx, docker)
+			if err != nil {
+				return err
+			}
+
+			// check the external network exists, nitro does not manage it
+			var external string
+			if cfg.Network.External != "" {
+				external, err = externalNetwork(ctx, docker, cfg.Network.External)
+				if err != nil {
+					return err
+				}
+			}
 
 			output.Success("network ready")
 
 			output.Info("Checking proxy…")
 
+			ports := proxycontainer.ConfigPorts(cfg)
+
+			// the proxy requires the token for the nitrod API
+			token, err := apitoken.LoadOrCreate(home)
+			if err != nil {
+				return err
+			}
+
 			// check the proxy and ensure its started
-			_, err = proxycontainer.FindAndStart(ctx, docker)
+			proxy, err := proxycontainer.FindAndStart(ctx, docker)
 			if errors.Is(err, proxycontainer.ErrNoProxyContainer) {
+				// in strict mode a missing proxy means nitro was never initialized
+				if cmd.Flag("strict").Value.String() == "true" {
+					output.Info("unable to find the nitro proxy…\n run `nitro init` to resolve")
+					return err
+				}
+
 				// create the proxy
-				if err := proxycontainer.Create(ctx, docker, output, network.ID); err != nil {
+				if err := proxycontainer.Create(ctx, docker, output, network.ID, ports, token); err != nil {
 					output.Info("unable to find the nitro proxy…\n run `nitro init` to resolve")
 					return err
 				}
@@ -287,22 +481,64 @@ func NewCommand(home string, docker client.CommonAPIClient, nitrod protob.NitroC
 				return err
 			}
 
+			// recreate the proxy when the ports in the config or the token have changed
+			if err == nil && (!proxycontainer.HasPorts(proxy, ports) || !proxycontainer.HasToken(proxy, token)) {
+				if err := recreateProxy(ctx, docker, output, proxy.ID, network.ID, ports, token); err != nil {
+					return err
+				}
+			}
+
 			output.Success("proxy ready")
 
+			if cfg.Proxy.DNS {
+				if err := dnscontainer.StartOrCreate(ctx, docker, output, cfg.GetTLD(), cfg.GetDNSPort()); err != nil {
+					return err
+				}
+
+				output.Success("dns ready")
+			}
+
+			if cfg.Backups.Schedule != "" {
+				if err := backupcontainer.StartOrCreate(ctx, docker, output, home, cfg.Backups); err != nil {
+					return err
+				}
+
+				output.Success("backup schedule ready")
+			}
+
+			// track the containers to check their health after applying
+			var applied []string
+
+			// track the sites with post start commands to run
+			var poststart []started
+
 			output.Info("Checking databases…")
 
 			// check the databases
 			for _, db := range cfg.Databases {
 				n, _ := db.GetHostname()
+
+				// warn when the image will run using emulation
+				if p, err := platform.Parse(db.Platform); err == nil && platform.IsEmulated(p) {
+					output.Info("Warning:", n, "uses the", db.Platform, "platform and will run using emulation")
+				}
+
 				output.Pending("checking", n)
 
 				// start or create the database
-				_, hostname, err := databasecontainer.StartOrCreate(ctx, docker, network.ID, db, output)
+				id, hostname, err := databasecontainer.StartOrCreate(ctx, docker, network.ID, db, output)
 				if err != nil {
 					output.Warning()
 					return err
 				}
 
+				if err := connectExternal(ctx, docker, external, id); err != nil {
+					output.Warning()
+					return err
+				}
+
+				applied = append(applied, id)
+
 				// add the hostname to the hosts files
 				hostnames = append(hostnames, hostname)
 
@@ -325,11 +561,50 @@ func NewCommand(home string, docker client.CommonAPIClient, nitrod protob.NitroC
 			default:
 				output.Pending("checking dynamodb")
 
-				_, hostname, err := dynamodb.VerifyCreated(ctx, docker, network.ID, output)
+				id, hostname, err := dynamodb.VerifyCreated(ctx, docker, network.ID, output)
 				if err != nil {
 					return err
 				}
 
+				if err := connectExternal(ctx, docker, external, id); err != nil {
+					return err
+				}
+
+				applied = append(applied, id)
+
+				if hostname != "" {
+					hostnames = append(hostnames, hostname)
+				}
+
+				output.Done()
+			}
+
+			// check elasticsearch service
+			switch cfg.Services.Elasticsearch {
+			case false:
+				output.Pending("checking elasticsearch")
+
+				// make sure the service container is removed
+				if err := elasticsearch.VerifyRemoved(ctx, docker, output); err != nil {
+					return err
+				}
+
+				output.Done()
+			default:
+				output.Pending("checking elasticsearch")
+
+				// verify the elasticsearch container is created
+				id, hostname, err := elasticsearch.VerifyCreated(ctx, docker, network.ID, cfg.Services.ElasticsearchVersion, cfg.Services.ElasticsearchHeapSize, output)
+				if err != nil {
+					return err
+				}
+
+				if err := connectExternal(ctx, docker, external, id); err != nil {
+					return err
+				}
+
+				applied = append(applied, id)
+
 				if hostname != "" {
 					hostnames = append(hostnames, hostname)
 				}
@@ -352,11 +627,50 @@ func NewCommand(home string, docker client.CommonAPIClient, nitrod protob.NitroC
 				output.Pending("checking mailhog")
 
 				// verify the mailhog container is created
-				_, hostname, err := mailhog.VerifyCreated(ctx, docker, network.ID, output)
+				id, hostname, err := mailhog.VerifyCreated(ctx, docker, network.ID, output)
+				if err != nil {
+					return err
+				}
+
+				if err := connectExternal(ctx, docker, external, id); err != nil {
+					return err
+				}
+
+				applied = append(applied, id)
+
+				if hostname != "" {
+					hostnames = append(hostnames, hostname)
+				}
+
+				output.Done()
+			}
+
+			// check meilisearch service
+			switch cfg.Services.Meilisearch {
+			case false:
+				output.Pending("checking meilisearch")
+
+				// make sure the service container is removed
+				if err := meilisearch.VerifyRemoved(ctx, docker, output); err != nil {
+					return err
+				}
+
+				output.Done()
+			default:
+				output.Pending("checking meilisearch")
+
+				// verify the meilisearch container is created
+				id, hostname, err := meilisearch.VerifyCreated(ctx, docker, network.ID, cfg.Services.MeilisearchMasterKey, output)
 				if err != nil {
 					return err
 				}
 
+				if err := connectExternal(ctx, docker, external, id); err != nil {
+					return err
+				}
+
+				applied = append(applied, id)
+
 				if hostname != "" {
 					hostnames = append(hostnames, hostname)
 				}
@@ -376,11 +690,50 @@ func NewCommand(home string, docker client.CommonAPIClient, nitrod protob.NitroC
 				output.Pending("checking minio")
 
 				// verify the minio container is created
-				_, hostname, err := minio.VerifyCreated(ctx, docker, network.ID, output)
+				id, hostname, err := minio.VerifyCreated(ctx, docker, network.ID, cfg.Services.MinioUser, cfg.Services.MinioPassword, output)
 				if err != nil {
 					return err
 				}
 
+				if err := connectExternal(ctx, docker, external, id); err != nil {
+					return err
+				}
+
+				applied = append(applied, id)
+
+				if hostname != "" {
+					hostnames = append(hostnames, hostname)
+				}
+
+				output.Done()
+			}
+
+			// check rabbitmq service
+			switch cfg.Services.RabbitMQ {
+			case false:
+				output.Pending("checking rabbitmq")
+
+				// make sure the service container is removed
+				if err := rabbitmq.VerifyRemoved(ctx, docker, output); err != nil {
+					return err
+				}
+
+				output.Done()
+			default:
+				output.Pending("checking rabbitmq")
+
+				// verify the rabbitmq container is created
+				id, hostname, err := rabbitmq.VerifyCreated(ctx, docker, network.ID, output)
+				if err != nil {
+					return err
+				}
+
+				if err := connectExternal(ctx, docker, external, id); err != nil {
+					return err
+				}
+
+				applied = append(applied, id)
+
 				if hostname != "" {
 					hostnames = append(hostnames, hostname)
 				}
@@ -401,11 +754,17 @@ func NewCommand(home string, docker client.CommonAPIClient, nitrod protob.NitroC
 			default:
 				output.Pending("checking redis")
 
-				_, hostname, err := redis.VerifyCreated(ctx, docker, network.ID, output)
+				id, hostname, err := redis.VerifyCreated(ctx, docker, network.ID, cfg.Services.RedisVersion, output)
 				if err != nil {
 					return err
 				}
 
+				if err := connectExternal(ctx, docker, external, id); err != nil {
+					return err
+				}
+
+				applied = append(applied, id)
+
 				if hostname != "" {
 					hostnames = append(hostnames, hostname)
 				}
@@ -418,15 +777,27 @@ func NewCommand(home string, docker client.CommonAPIClient, nitrod protob.NitroC
 				output.Info("Checking containers…")
 
 				for _, c := range cfg.Containers {
+					// warn when the image will run using emulation
+					if p, err := platform.Parse(c.Platform); err == nil && platform.IsEmulated(p) {
+						output.Info("Warning:", c.Name, "uses the", c.Platform, "platform and will run using emulation")
+					}
+
 					output.Pending("checking", fmt.Sprintf("%s.containers.nitro", c.Name))
 
 					// start, update or create the custom container
-					_, err := customcontainer.StartOrCreate(ctx, docker, home, network.ID, c)
+					id, err := customcontainer.StartOrCreate(ctx, docker, home, network.ID, c)
 					if err != nil {
 						output.Warning()
 						return err
 					}
 
+					if err := connectExternal(ctx, docker, external, id); err != nil {
+						output.Warning()
+						return err
+					}
+
+					applied = append(applied, id)
+
 					output.Done()
 				}
 			}
@@ -435,31 +806,93 @@ func NewCommand(home string, docker client.CommonAPIClient, nitrod protob.NitroC
 				// get all of the sites, their local path, the php version, and the type of project (nginx or PHP-FPM)
 				output.Info("Checking sites…")
 
+				// order the sites so dependencies are started first
+				sites, err := cfg.SitesInOrder()
+				if err != nil {
+					return err
+				}
+
 				// get the envs for the sites
-				for _, site := range cfg.Sites {
+				for _, site := range sites {
 					output.Pending("checking", site.Hostname)
 
+					// the sites php settings override the defaults
+					site.PHP = cfg.PHP.Override(site.PHP)
+
+					// wait for the sites dependencies to be ready
+					if err := waitForDependencies(ctx, docker, cfg, site); err != nil {
+						output.Warning()
+						return err
+					}
+
 					// start, update or create the site container
-					_, err := sitecontainer.StartOrCreate(ctx, docker, home, network.ID, site, cfg)
+					id, created, err := sitecontainer.StartOrCreate(ctx, docker, home, network.ID, site, cfg, cmd.Flag("preserve-env").Value.String() == "true", rollback)
 					if err != nil {
 						output.Warning()
 						return err
 					}
 
+					// run the post start commands after the proxy is updated
+					if created && len(site.PostStart) > 0 {
+						poststart = append(poststart, started{id: id, site: site})
+					}
+
+					if err := connectExternal(ctx, docker, external, id); err != nil {
+						output.Warning()
+						return err
+					}
+
+					applied = append(applied, id)
+
 					output.Done()
 				}
 			}
 
 			output.Info("Checking proxy…")
 
-			output.Pending("updating proxy")
+			timeout, err := cmd.Flags().GetDuration("proxy-timeout")
+			if err != nil {
+				return err
+			}
 
-			if err := updateProxy(ctx, docker, nitrod, cfg); err != nil {
-				output.Warning()
+			if err := updateProxy(ctx, docker, nitrod, home, cfg, timeout, output); err != nil {
 				return err
 			}
 
-			output.Done()
+			// report the containers that did not start or are unhealthy
+			report, err := checkHealth(ctx, docker, applied, cmd.Flag("show-logs-on-failure").Value.String() == "true")
+			if err != nil {
+				return err
+			}
+
+			if len(report) > 0 {
+				output.Info("Warning: some containers are not healthy…")
+
+				for _, l := range healthSummary(report) {
+					output.Info(l)
+				}
+
+				if cmd.Flag("show-logs-on-failure").Value.String() != "true" {
+					output.Info("Run `nitro apply --show-logs-on-failure` to include the logs")
+				}
+			}
+
+			// run the post start commands for the sites that were created or started
+			for _, p := range poststart {
+				output.Info("Running post start commands for", p.site.Hostname+"…")
+
+				if err := runPostStart(ctx, docker, p.id, p.site, cmd.OutOrStdout()); err != nil {
+					return err
+				}
+			}
+
+			if len(cfg.Hooks.PostApply) > 0 {
+				output.Info("Running post apply hooks…")
+
+				if err := runHooks(ctx, cfg.Hooks.PostApply, cmd.OutOrStdout(), cmd.ErrOrStderr()); err != nil {
+					return err
+				}
+			}
 
 			// should we update the hosts file?
 			if os.Getenv("NITRO_EDIT_HOSTS") == "false" || cmd.Flag("skip-hosts").Value.String() == "true" {
@@ -473,9 +906,24 @@ func NewCommand(home string, docker client.CommonAPIClient, nitrod protob.NitroC
 				hostnames = append(hostnames, s.Aliases...)
 			}
 
-			// get custom container hostnames
-			for _, c := range cfg.Containers {
-				hostnames = append(hostnames, fmt.Sprintf("%s.containers.nitro", c.Name))
+			// get the host proxy hostnames
+			for _, p := range cfg.HostProxies {
+				hostnames = append(hostnames, p.Hostname)
+			}
+
+			// get the custom container and proxied service hostnames
+			hostnames = append(hostnames, proxyHostnames(cfg)...)
+
+			// the dns container resolves the top level domain
+			if cfg.Proxy.DNS && len(hostnames) > 0 {
+				resolved, err := installResolver(cfg, output)
+				if err != nil {
+					return err
+				}
+
+				if resolved {
+					hostnames = withoutTLD(hostnames, cfg.GetTLD())
+				}
 			}
 
 			if len(hostnames) > 0 {
@@ -506,12 +954,7 @@ func NewCommand(home string, docker client.CommonAPIClient, nitrod protob.NitroC
 					case "windows":
 						// windows users should be running as admin, so just execute the hosts command
 						// as is
-						c := exec.Command(nitro, "hosts", "--hostnames="+strings.Join(hostnames, ","))
-
-						c.Stdout = os.Stdout
-						c.Stderr = os.Stderr
-
-						if c.Run() != nil {
+						if err := runHostsCommand(nitro, hostnames); err != nil {
 							return err
 						}
 					default:
@@ -531,35 +974,335 @@ func NewCommand(home string, docker client.CommonAPIClient, nitrod protob.NitroC
 
 	// add flag to skip pulling images
 	cmd.Flags().Bool("skip-hosts", false, "skip modifying the hosts file")
+	cmd.Flags().Bool("strict", false, "return an error if the network or proxy is missing")
+	cmd.Flags().Bool("dry-run", false, "show the planned changes without making them")
+	cmd.Flags().Bool("preserve-env", false, "show the environment variable changes when recreating site containers")
+	cmd.Flags().Bool("allow-root", false, "allow running as the root user")
+	cmd.Flags().String("profile", "", "the services profile to use instead of the services in the config")
+	cmd.Flags().Bool("show-logs-on-failure", false, "show the logs for containers that are not healthy")
+	cmd.Flags().Bool("no-backup", false, "skip the backup of removed databases, the data will be lost")
+	cmd.Flags().Duration("proxy-timeout", proxyTimeout, "how long to wait for the proxy API to be ready")
+	cmd.Flags().String("site", "", "only apply the changes for the site with the hostname")
 
 	return cmd
 }
 
-func updateProxy(ctx context.Context, docker client.ContainerAPIClient, nitrod protob.NitroClient, cfg *config.Config) error {
+// isRoot returns true when a user is running as root using sudo. Running as the root user
+// without sudo (e.g. in CI or a container) is expected and returns false. Windows does not
+// have user ids so it always returns false.
+func isRoot() bool {
+	return runtime.GOOS != "windows" && getuid() == 0 && os.Getenv("SUDO_USER") != ""
+}
+
+// runHostsCommand executes the nitro hosts command directly (without sudo) and returns
+// any error from the command, including the commands stderr to help users debug.
+func runHostsCommand(nitro string, hostnames []string) error {
+	c := exec.Command(nitro, "hosts", "--hostnames="+strings.Join(hostnames, ","))
+
+	// capture stderr while still showing it to the user
+	stderr := &bytes.Buffer{}
+
+	c.Stdout = os.Stdout
+	c.Stderr = io.MultiWriter(os.Stderr, stderr)
+
+	if err := c.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("unable to update the hosts file, %s: %w", msg, err)
+		}
+
+		return fmt.Errorf("unable to update the hosts file, %w", err)
+	}
+
+	return nil
+}
+
+// sharedNames returns the container names of the databases and services in the config, which
+// are shared by all of the environments.
+func sharedNames(cfg *config.Config) []string {
+	var names []string
+
+	// get all of the databases
+	for _, d := range cfg.Databases {
+		h, _ := d.GetHostname()
+		names = append(names, h)
+	}
+
+	// is dynamodb enabled
+	if cfg.Services.DynamoDB {
+		names = append(names, dynamodb.Host)
+	}
+
+	// is elasticsearch enabled
+	if cfg.Services.Elasticsearch {
+		names = append(names, elasticsearch.Host)
+	}
+
+	// is mailhog enabled
+	if cfg.Services.Mailhog {
+		names = append(names, mailhog.Host)
+	}
+
+	// is meilisearch enabled
+	if cfg.Services.Meilisearch {
+		names = append(names, meilisearch.Host)
+	}
+
+	// is minio enabled
+	if cfg.Services.Minio {
+		names = append(names, minio.Host)
+	}
+
+	// is rabbitmq enabled
+	if cfg.Services.RabbitMQ {
+		names = append(names, rabbitmq.Host)
+	}
+
+	// is redis enabled
+	if cfg.Services.Redis {
+		names = append(names, redis.Host)
+	}
+
+	// is the dns container enabled
+	if cfg.Proxy.DNS {
+		names = append(names, dnscontainer.Name)
+	}
+
+	// are the scheduled backups enabled
+	if cfg.Backups.Schedule != "" {
+		names = append(names, backupcontainer.Name)
+	}
+
+	return names
+}
+
+// otherEnvironmentNames returns the container names of the databases and services the other
+// environments use, so applying one environment does not remove them.
+func otherEnvironmentNames(home string) ([]string, error) {
+	current := config.Environment()
+	if current == "" {
+		current = "default"
+	}
+
+	envs, err := config.Environments(home)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, e := range envs {
+		if e == current {
+			continue
+		}
+
+		cfg, err := config.LoadEnvironment(home, e)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load the config for the %s environment, %w", e, err)
+		}
+
+		names = append(names, sharedNames(cfg)...)
+	}
+
+	return names, nil
+}
+
+// externalNetwork returns the ID of an existing network that is not managed by nitro.
+func externalNetwork(ctx context.Context, docker client.NetworkAPIClient, name string) (string, error) {
+	filter := filters.NewArgs()
+	filter.Add("name", name)
+
+	networks, err := docker.NetworkList(ctx, types.NetworkListOptions{Filters: filter})
+	if err != nil {
+		return "", fmt.Errorf("unable to list docker networks\n%w", err)
+	}
+
+	// the name filter matches partial names
+	for _, n := range networks {
+		if n.Name == name {
+			return n.ID, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w %q, create the network or remove it from the config", ErrNoExternalNetwork, name)
+}
+
+// proxyHostnames returns the hostnames for the custom containers and the proxied services, which
+// use the configs top level domain. The services only need a hosts entry for a custom domain.
+func proxyHostnames(cfg *config.Config) []string {
+	var hostnames []string
+	for _, c := range cfg.Containers {
+		hostnames = append(hostnames, cfg.ProxyHostname(c.Name+customcontainer.Suffix))
+	}
+
+	if cfg.GetTLD() == config.DefaultTLD {
+		return hostnames
+	}
+
+	for _, s := range []struct {
+		enabled bool
+		host    string
+	}{
+		{enabled: cfg.Services.Mailhog, host: mailhog.Host},
+		{enabled: cfg.Services.Minio, host: minio.Host},
+		{enabled: cfg.Services.RabbitMQ, host: rabbitmq.Host},
+	} {
+		if s.enabled {
+			hostnames = append(hostnames, cfg.ProxyHostname(s.host))
+		}
+	}
+
+	return hostnames
+}
+
+// connectExternal connects a container to the external network if it is not
+// already connected. If there is no external network, it does nothing.
+func connectExternal(ctx context.Context, docker client.CommonAPIClient, networkID, containerID string) error {
+	if networkID == "" || containerID == "" {
+		return nil
+	}
+
+	details, err := docker.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return fmt.Errorf("unable to inspect the container, %w", err)
+	}
+
+	if details.NetworkSettings != nil {
+		for _, n := range details.NetworkSettings.Networks {
+			if n.NetworkID == networkID {
+				return nil
+			}
+		}
+	}
+
+	if err := docker.NetworkConnect(ctx, networkID, containerID, nil); err != nil {
+		return fmt.Errorf("unable to connect %s to the external network, %w", strings.TrimLeft(details.Name, "/"), err)
+	}
+
+	return nil
+}
+
+// findNetwork returns the nitro network or ErrNoNetwork if it does not exist.
+func findNetwork(ctx context.Context, docker client.NetworkAPIClient) (types.NetworkResource, error) {
+	filter := filters.NewArgs()
+	filter.Add("label", containerlabels.Nitro+"=true")
+	filter.Add("name", "nitro-network")
+
+	networks, err := docker.NetworkList(ctx, types.NetworkListOptions{Filters: filter})
+	if err != nil {
+		return types.NetworkResource{}, fmt.Errorf("unable to list docker networks\n%w", err)
+	}
+
+	// get the network for the environment
+	for _, n := range networks {
+		if n.Name == "nitro-network" {
+			return n, nil
+		}
+	}
+
+	return types.NetworkResource{}, ErrNoNetwork
+}
+
+// recreateProxy removes the proxy container and creates it with the ports. The proxy volume
+// is kept, so the certificates are not regenerated.
+func recreateProxy(ctx context.Context, docker client.CommonAPIClient, output terminal.Outputer, id, networkID string, ports proxycontainer.Ports, token string) error {
+	output.Pending("updating proxy")
+
+	if err := docker.ContainerStop(ctx, id, nil); err != nil {
+		output.Warning()
+
+		return fmt.Errorf("unable to stop the proxy container, %w", err)
+	}
+
+	if err := docker.ContainerRemove(ctx, id, types.ContainerRemoveOptions{}); err != nil {
+		output.Warning()
+
+		return fmt.Errorf("unable to remove the proxy container, %w", err)
+	}
+
+	output.Done()
+
+	return proxycontainer.Create(ctx, docker, output, networkID, ports, token)
+}
+
+// waitForDependencies waits for the containers a site depends on to be running and, if the
+// container has a health check, healthy.
+func waitForDependencies(ctx context.Context, docker client.ContainerAPIClient, cfg *config.Config, site config.Site) error {
+	for _, d := range site.DependsOn {
+		name, err := cfg.DependencyContainerName(d)
+		if err != nil {
+			return err
+		}
+
+		deadline := time.Now().Add(dependencyTimeout)
+		for {
+			info, err := docker.ContainerInspect(ctx, name)
+			if err != nil {
+				return fmt.Errorf("unable to find the dependency %s for %s, %w", d, site.Hostname, err)
+			}
+
+			if info.State != nil && info.State.Running && (info.State.Health == nil || info.State.Health.Status == types.Healthy) {
+				break
+			}
+
+			if time.Now().After(deadline) {
+				return fmt.Errorf("timed out waiting for the dependency %s for %s", d, site.Hostname)
+			}
+
+			time.Sleep(time.Second)
+		}
+	}
+
+	return nil
+}
+
+// updateProxy configures the proxy with the sites, services, and host proxies and shows the
+// progress of the proxy as it is updated.
+func updateProxy(ctx context.Context, docker client.ContainerAPIClient, nitrod protob.NitroClient, home string, cfg *config.Config, timeout time.Duration, output terminal.Outputer) error {
 	// convert the sites into the gRPC API Apply request
 	sites := make(map[string]*protob.Site)
 	for _, s := range cfg.Sites {
 		// create the site
 		sites[s.Hostname] = &protob.Site{
-			Hostname: s.Hostname,
-			Aliases:  strings.Join(s.Aliases, ","),
-			Port:     8080,
+			Hostname:       s.Hostname,
+			Aliases:        strings.Join(s.Aliases, ","),
+			Port:           8080,
+			WebsocketsPort: int32(s.GetWebSocketsPort()),
+		}
+
+		if s.BasicAuth != nil {
+			sites[s.Hostname].BasicAuthUser = s.BasicAuth.User
+			sites[s.Hostname].BasicAuthPassword = s.BasicAuth.Password
 		}
+
+		cert, key, err := s.GetCertificate(home)
+		if err != nil {
+			return err
+		}
+
+		sites[s.Hostname].Certificate = string(cert)
+		sites[s.Hostname].Key = string(key)
 	}
 
 	// check the mailhog service
 	if cfg.Services.Mailhog {
 		sites["mailhog.service.nitro"] = &protob.Site{
-			Hostname: "mailhog.service.nitro",
+			Hostname: cfg.ProxyHostname("mailhog.service.nitro"),
 			Port:     8025,
 		}
 	}
 
-	// check the minio service
+	// proxy the minio console
 	if cfg.Services.Minio {
 		sites["minio.service.nitro"] = &protob.Site{
-			Hostname: "minio.service.nitro",
-			Port:     9000,
+			Hostname: cfg.ProxyHostname("minio.service.nitro"),
+			Port:     9001,
+		}
+	}
+
+	// proxy the rabbitmq management ui
+	if cfg.Services.RabbitMQ {
+		sites[rabbitmq.Host] = &protob.Site{
+			Hostname: cfg.ProxyHostname(rabbitmq.Host),
+			Port:     rabbitmq.ManagementPort,
 		}
 	}
 
@@ -567,34 +1310,180 @@ func updateProxy(ctx context.Context, docker client.ContainerAPIClient, nitrod p
 	for _, c := range cfg.Containers {
 		if c.WebGui != 0 {
 			sites[fmt.Sprintf("%s.containers.nitro", c.Name)] = &protob.Site{
-				Hostname: fmt.Sprintf("%s.containers.nitro", c.Name),
+				Hostname: cfg.ProxyHostname(fmt.Sprintf("%s.containers.nitro", c.Name)),
 				Port:     int32(c.WebGui),
 			}
 		}
 	}
 
+	// route the host proxies to the host machine
+	for _, p := range cfg.HostProxies {
+		sites[p.Hostname] = &protob.Site{
+			Hostname: p.Hostname,
+			Port:     int32(p.Port),
+			Upstream: "host.docker.internal",
+		}
+	}
+
 	// if there are no sites, we are done
 	if len(sites) == 0 {
 		return nil
 	}
 
+	output.Pending("waiting for the proxy")
+
 	// wait for the api to be ready
-	for {
-		_, err := nitrod.Ping(ctx, &protob.PingRequest{})
-		if err == nil {
-			break
-		}
+	if err := waitForProxy(ctx, nitrod, timeout); err != nil {
+		output.Warning()
+		return err
+	}
+
+	output.Done()
+
+	_, capabilities, err := nitroclient.Capabilities(ctx, nitrod)
+	if err != nil {
+		return err
+	}
+
+	// an outdated proxy does not understand the newer site options, so they are not sent
+	for _, w := range degradeSites(sites, capabilities) {
+		output.Info("Warning:", w)
 	}
 
 	// configure the proxy with the sites
+	if capabilities[api.CapabilityApplyStream] {
+		return streamApply(ctx, nitrod, &protob.ApplyRequest{Sites: sites}, output)
+	}
+
+	// proxies before ApplyStream only support Apply
+	output.Pending("updating proxy")
+
 	resp, err := nitrod.Apply(ctx, &protob.ApplyRequest{Sites: sites})
 	if err != nil {
+		output.Warning()
 		return err
 	}
 
 	if resp.Error {
+		output.Warning()
 		return fmt.Errorf("unable to update the proxy, %s", resp.GetMessage())
 	}
 
+	output.Done()
+
 	return nil
 }
+
+// siteCapabilities are the capabilities of the proxy required by the site options, and the
+// description used to warn when the proxy does not support them.
+var siteCapabilities = []struct {
+	capability  string
+	description string
+}{
+	{capability: api.CapabilityUpstream, description: "host proxies"},
+	{capability: api.CapabilityWebSockets, description: "websockets"},
+	{capability: api.CapabilityCertificates, description: "site certificates"},
+}
+
+// degradeSites removes the options the proxy does not support from the sites, and removes the sites
+// that are unable to be routed without them (e.g. host proxies). It returns a warning for each of
+// the capabilities the proxy is missing.
+func degradeSites(sites map[string]*protob.Site, capabilities map[string]bool) []string {
+	missing := map[string][]string{}
+	for k, s := range sites {
+		if s.GetUpstream() != "" && !capabilities[api.CapabilityUpstream] {
+			missing[api.CapabilityUpstream] = append(missing[api.CapabilityUpstream], s.GetHostname())
+			delete(sites, k)
+			continue
+		}
+
+		if s.GetWebsocketsPort() != 0 && !capabilities[api.CapabilityWebSockets] {
+			missing[api.CapabilityWebSockets] = append(missing[api.CapabilityWebSockets], s.GetHostname())
+			s.WebsocketsPort = 0
+		}
+
+		if s.GetCertificate() != "" && !capabilities[api.CapabilityCertificates] {
+			missing[api.CapabilityCertificates] = append(missing[api.CapabilityCertificates], s.GetHostname())
+			s.Certificate, s.Key = "", ""
+		}
+	}
+
+	var warnings []string
+	for _, c := range siteCapabilities {
+		hosts := missing[c.capability]
+		if len(hosts) == 0 {
+			continue
+		}
+
+		sort.Strings(hosts)
+
+		warnings = append(warnings, fmt.Sprintf("the proxy does not support %s for %s…\n run `nitro update` to update the proxy", c.description, strings.Join(hosts, ", ")))
+	}
+
+	return warnings
+}
+
+// streamApply configures the proxy with ApplyStream and shows each event as the proxy sends it.
+func streamApply(ctx context.Context, nitrod protob.NitroClient, request *protob.ApplyRequest, output terminal.Outputer) error {
+	stream, err := nitrod.ApplyStream(ctx, request)
+	if err != nil {
+		return err
+	}
+
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch event.GetType() {
+		case api.EventSite:
+			output.Success("routed", event.GetHostname())
+		case api.EventCertificate:
+			output.Success("loaded the certificate for", event.GetHostname())
+		case api.EventRoute:
+			output.Success("proxy updated")
+		case api.EventDone:
+			if event.GetError() {
+				return fmt.Errorf("unable to update the proxy, %s", event.GetMessage())
+			}
+		}
+	}
+}
+
+// waitForProxy pings the proxy API until it responds, doubling the wait between each ping. If
+// the API does not respond before the timeout, ErrProxyTimeout is returned.
+func waitForProxy(ctx context.Context, nitrod protob.NitroClient, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	wait := proxyBackoff
+	for {
+		_, err := nitrod.Ping(ctx, &protob.PingRequest{})
+		if err == nil {
+			return nil
+		}
+
+		if time.Now().Add(wait).After(deadline) {
+			return fmt.Errorf("%w\nlast error: %s", ErrProxyTimeout, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+
+		wait *= 2
+		if wait > proxyMaxBackoff {
+			wait = proxyMaxBackoff
+		}
+	}
+}
+
+// skipBackup returns true if the backup should be skipped for a database container
+// that is being removed, either with the no-backup flag or the skip_backup config.
+func skipBackup(c types.Container, noBackup bool) bool {
+	return noBackup || c.Labels[containerlabels.DatabaseSkipBackup] == "true"
+}
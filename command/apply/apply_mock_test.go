@@ -0,0 +1,240 @@
+package apply
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/craftcms/nitro/pkg/terminal"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+	volumetypes "github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+type spyOutputer struct {
+	infos []string
+}
+
+func (spy *spyOutputer) Ask(message, fallback, sep string, validator terminal.Validator) (string, error) {
+	return fallback, nil
+}
+
+func (spy *spyOutputer) Confirm(message string, fallback bool, sep string) (bool, error) {
+	return fallback, nil
+}
+
+func (spy *spyOutputer) Info(s ...string) {
+	spy.infos = append(spy.infos, strings.Join(s, " "))
+}
+
+func (spy *spyOutputer) Select(r io.Reader, msg string, opts []string) (int, error) {
+	return 0, nil
+}
+
+func (spy *spyOutputer) Warning() {}
+
+func (spy *spyOutputer) Success(s ...string) {}
+
+func (spy *spyOutputer) Pending(s ...string) {}
+
+func (spy *spyOutputer) Done() {}
+
+type mockDockerClient struct {
+	client.CommonAPIClient
+
+	// filters are the filters passed to list funcs
+	filterArgs []filters.Args
+
+	// container related resources
+	containers              []types.Container
+	containerCreateRequests []types.ContainerCreateConfig
+	containerCreateResponse container.ContainerCreateCreatedBody
+	containerStartIDs       []string
+	containerStopIDs        []string
+	containerRemoveIDs      []string
+	containerInspect        map[string]types.ContainerJSON
+	containerLogs           map[string]string
+
+	// network related resources
+	networks               []types.NetworkResource
+	networkConnectRequests []string
+
+	// volume related resources
+	volumes volumetypes.VolumeListOKBody
+
+	// exec related resources, execs are the commands by exec id and
+	// execFailures are the last argument of commands that fail
+	execMu       sync.Mutex
+	execs        map[string][]string
+	execFailures map[string]bool
+
+	// mockError allows us to override any func to return a method, we do not
+	// set the error by default.
+	mockError error
+}
+
+func (c *mockDockerClient) Ping(ctx context.Context) (types.Ping, error) {
+	return types.Ping{}, c.mockError
+}
+
+func (c *mockDockerClient) NetworkList(ctx context.Context, options types.NetworkListOptions) ([]types.NetworkResource, error) {
+	c.filterArgs = append(c.filterArgs, options.Filters)
+
+	return c.networks, c.mockError
+}
+
+func (c *mockDockerClient) NetworkConnect(ctx context.Context, networkID, containerID string, config *network.EndpointSettings) error {
+	c.networkConnectRequests = append(c.networkConnectRequests, networkID+":"+containerID)
+
+	return c.mockError
+}
+
+func (c *mockDockerClient) VolumeList(ctx context.Context, filter filters.Args) (volumetypes.VolumeListOKBody, error) {
+	c.filterArgs = append(c.filterArgs, filter)
+
+	return c.volumes, c.mockError
+}
+
+func (c *mockDockerClient) VolumeCreate(ctx context.Context, options volumetypes.VolumeCreateBody) (types.Volume, error) {
+	return types.Volume{Name: options.Name}, c.mockError
+}
+
+func (c *mockDockerClient) ContainerList(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error) {
+	c.filterArgs = append(c.filterArgs, options.Filters)
+
+	// only return the containers that match the label filters
+	var containers []types.Container
+	for _, container := range c.containers {
+		if matchesLabels(container, options.Filters) {
+			containers = append(containers, container)
+		}
+	}
+
+	return containers, c.mockError
+}
+
+func (c *mockDockerClient) ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *v1.Platform, containerName string) (container.ContainerCreateCreatedBody, error) {
+	c.containerCreateRequests = append(c.containerCreateRequests, types.ContainerCreateConfig{
+		Name:             containerName,
+		Config:           config,
+		HostConfig:       hostConfig,
+		NetworkingConfig: networkingConfig,
+	})
+
+	return c.containerCreateResponse, c.mockError
+}
+
+func (c *mockDockerClient) ContainerStart(ctx context.Context, container string, options types.ContainerStartOptions) error {
+	c.containerStartIDs = append(c.containerStartIDs, container)
+
+	return c.mockError
+}
+
+func (c *mockDockerClient) ContainerStop(ctx context.Context, containerID string, timeout *time.Duration) error {
+	c.containerStopIDs = append(c.containerStopIDs, containerID)
+
+	return c.mockError
+}
+
+func (c *mockDockerClient) ContainerRemove(ctx context.Context, containerID string, options types.ContainerRemoveOptions) error {
+	c.containerRemoveIDs = append(c.containerRemoveIDs, containerID)
+
+	return c.mockError
+}
+
+func (c *mockDockerClient) ContainerInspect(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+	info, ok := c.containerInspect[containerID]
+	if !ok {
+		return types.ContainerJSON{}, fmt.Errorf("no such container: %s", containerID)
+	}
+
+	return info, c.mockError
+}
+
+func (c *mockDockerClient) ContainerLogs(ctx context.Context, container string, options types.ContainerLogsOptions) (io.ReadCloser, error) {
+	// multiplex the logs the same as the docker api
+	buf := &bytes.Buffer{}
+	if _, err := stdcopy.NewStdWriter(buf, stdcopy.Stdout).Write([]byte(c.containerLogs[container])); err != nil {
+		return nil, err
+	}
+
+	return ioutil.NopCloser(buf), c.mockError
+}
+
+func (c *mockDockerClient) ImageList(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error) {
+	return []types.ImageSummary{{Containers: 1}}, c.mockError
+}
+
+func (c *mockDockerClient) ContainerExecCreate(ctx context.Context, container string, config types.ExecConfig) (types.IDResponse, error) {
+	c.execMu.Lock()
+	defer c.execMu.Unlock()
+
+	if c.execs == nil {
+		c.execs = make(map[string][]string)
+	}
+
+	id := fmt.Sprintf("exec-%d", len(c.execs))
+	c.execs[id] = config.Cmd
+
+	return types.IDResponse{ID: id}, c.mockError
+}
+
+func (c *mockDockerClient) ContainerExecAttach(ctx context.Context, execID string, config types.ExecStartCheck) (types.HijackedResponse, error) {
+	c.execMu.Lock()
+	cmd := c.execs[execID]
+	c.execMu.Unlock()
+
+	// the output is multiplexed like the docker api
+	out := &bytes.Buffer{}
+	stdcopy.NewStdWriter(out, stdcopy.Stdout).Write([]byte("dump of " + cmd[len(cmd)-1]))
+
+	conn, _ := net.Pipe()
+
+	return types.HijackedResponse{Conn: conn, Reader: bufio.NewReader(out)}, c.mockError
+}
+
+func (c *mockDockerClient) ContainerExecStart(ctx context.Context, execID string, config types.ExecStartCheck) error {
+	return c.mockError
+}
+
+func (c *mockDockerClient) ContainerExecInspect(ctx context.Context, execID string) (types.ContainerExecInspect, error) {
+	c.execMu.Lock()
+	defer c.execMu.Unlock()
+
+	cmd := c.execs[execID]
+	if c.execFailures[cmd[len(cmd)-1]] {
+		return types.ContainerExecInspect{ExitCode: 1}, c.mockError
+	}
+
+	return types.ContainerExecInspect{}, c.mockError
+}
+
+// matchesLabels checks the label filters (e.g. key=value) against a containers labels
+func matchesLabels(c types.Container, args filters.Args) bool {
+	for _, l := range args.Get("label") {
+		parts := strings.SplitN(l, "=", 2)
+
+		v, ok := c.Labels[parts[0]]
+		if !ok {
+			return false
+		}
+
+		if len(parts) == 2 && v != parts[1] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,948 @@
+package apply
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/craftcms/nitro/pkg/api"
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/proxycontainer"
+	"github.com/craftcms/nitro/pkg/terminal"
+	"github.com/craftcms/nitro/protob"
+)
+
+func Test_runHostsCommand(t *testing.T) {
+	shPath, err := exec.LookPath("sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	truePath, err := exec.LookPath("true")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type args struct {
+		nitro     string
+		hostnames []string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "successful hosts command returns no error",
+			args: args{
+				nitro:     truePath,
+				hostnames: []string{"example.nitro"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "failed hosts command returns an error",
+			args: args{
+				// sh will fail trying to open a script named "hosts"
+				nitro:     shPath,
+				hostnames: []string{"example.nitro"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing executable returns an error",
+			args: args{
+				nitro:     "missingpath",
+				hostnames: []string{"example.nitro"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := runHostsCommand(tt.args.nitro, tt.args.hostnames); (err != nil) != tt.wantErr {
+				t.Errorf("runHostsCommand() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplyStrictMode(t *testing.T) {
+	os.Setenv("NITRO_DEVELOPMENT", "true")
+	defer os.Unsetenv("NITRO_DEVELOPMENT")
+
+	home, _ := os.Getwd()
+	home = filepath.Join(home, "testdata")
+	defer os.Remove(filepath.Join(home, ".nitro", LogFile))
+
+	network := types.NetworkResource{ID: "some-network-id", Name: "nitro-network"}
+
+	tests := []struct {
+		name     string
+		strict   bool
+		networks []types.NetworkResource
+		wantErr  error
+	}{
+		{
+			name:     "missing proxy is created when not strict",
+			networks: []types.NetworkResource{network},
+			wantErr:  nil,
+		},
+		{
+			name:     "missing proxy returns an error when strict",
+			strict:   true,
+			networks: []types.NetworkResource{network},
+			wantErr:  proxycontainer.ErrNoProxyContainer,
+		},
+		{
+			name:    "missing network returns an error when not strict",
+			wantErr: ErrNoNetwork,
+		},
+		{
+			name:    "missing network returns an error when strict",
+			strict:  true,
+			wantErr: ErrNoNetwork,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockDockerClient{
+				networks:                tt.networks,
+				containerCreateResponse: container.ContainerCreateCreatedBody{ID: "proxy-id"},
+			}
+
+			cmd := NewCommand(home, mock, nil, &spyOutputer{})
+			cmd.Flags().Set("skip-hosts", "true")
+			cmd.Flags().Set("strict", strconv.FormatBool(tt.strict))
+
+			err := cmd.RunE(cmd, []string{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected the error to be %v, got %v", tt.wantErr, err)
+			}
+
+			// the proxy should only be created when not strict
+			created := len(mock.containerCreateRequests) > 0
+			if tt.wantErr == nil && !created {
+				t.Errorf("expected the proxy container to be created")
+			}
+			if tt.wantErr != nil && created {
+				t.Errorf("expected no containers to be created, got %d", len(mock.containerCreateRequests))
+			}
+
+			// created containers should be labeled with the run id
+			for _, c := range mock.containerCreateRequests {
+				if runID == "" || c.Config.Labels[containerlabels.RunID] != runID {
+					t.Errorf("expected the container to have the run id label %q, got %q", runID, c.Config.Labels[containerlabels.RunID])
+				}
+			}
+		})
+	}
+}
+
+func TestApplySite(t *testing.T) {
+	home, err := ioutil.TempDir("", "nitro-apply")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+
+	if err := os.MkdirAll(filepath.Join(home, ".nitro"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := "sites:\n  - hostname: craft-dev.nitro\n    path: ~/craft-dev\n    version: \"8.0\"\n    webroot: web\n"
+	if err := ioutil.WriteFile(filepath.Join(home, ".nitro", "nitro.yaml"), []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	network := types.NetworkResource{ID: "some-network-id", Name: "nitro-network"}
+
+	tests := []struct {
+		name     string
+		site     string
+		networks []types.NetworkResource
+		wantErr  error
+	}{
+		{
+			name:     "unknown sites return an error",
+			site:     "missing.nitro",
+			networks: []types.NetworkResource{network},
+			wantErr:  errors.New("unable to find site with hostname missing.nitro"),
+		},
+		{
+			name:    "missing networks return an error",
+			site:    "craft-dev.nitro",
+			wantErr: ErrNoNetwork,
+		},
+		{
+			name:     "missing proxies are not created",
+			site:     "craft-dev.nitro",
+			networks: []types.NetworkResource{network},
+			wantErr:  proxycontainer.ErrNoProxyContainer,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockDockerClient{networks: tt.networks}
+
+			cmd := NewCommand(home, mock, nil, &spyOutputer{})
+			cmd.Flags().Set("site", tt.site)
+
+			err := cmd.RunE(cmd, []string{})
+			if err == nil || (!errors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error()) {
+				t.Errorf("expected the error to be %v, got %v", tt.wantErr, err)
+			}
+
+			if len(mock.containerCreateRequests) > 0 {
+				t.Errorf("expected no containers to be created, got %d", len(mock.containerCreateRequests))
+			}
+		})
+	}
+}
+
+func TestApplyDryRun(t *testing.T) {
+	os.Setenv("NITRO_DEVELOPMENT", "true")
+	defer os.Unsetenv("NITRO_DEVELOPMENT")
+
+	home, _ := os.Getwd()
+	home = filepath.Join(home, "testdata")
+	defer os.Remove(filepath.Join(home, ".nitro", LogFile))
+
+	mock := &mockDockerClient{
+		containers: []types.Container{
+			{
+				ID:    "redis-id",
+				Names: []string{"/redis.service.nitro"},
+				State: "exited",
+				Labels: map[string]string{
+					containerlabels.Nitro: "true",
+					containerlabels.Type:  "redis",
+				},
+			},
+			{
+				ID:    "database-id",
+				Names: []string{"/mysql-5.7-3306"},
+				State: "running",
+				Labels: map[string]string{
+					containerlabels.Nitro:                 "true",
+					containerlabels.Type:                  "database",
+					containerlabels.DatabaseEngine:        "mysql",
+					containerlabels.DatabaseCompatibility: "mysql",
+				},
+			},
+		},
+	}
+	spy := &spyOutputer{}
+
+	cmd := NewCommand(home, mock, nil, spy)
+	cmd.Flags().Set("skip-hosts", "true")
+	cmd.Flags().Set("dry-run", "true")
+
+	// a missing network and proxy should be reported instead of returning an error
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatalf("expected the error to be nil, got %v", err)
+	}
+
+	if err := cmd.PostRunE(cmd, []string{}); err != nil {
+		t.Fatalf("expected the error to be nil, got %v", err)
+	}
+
+	// nothing should be changed
+	if len(mock.containerCreateRequests) > 0 || len(mock.containerStartIDs) > 0 || len(mock.containerStopIDs) > 0 || len(mock.containerRemoveIDs) > 0 {
+		t.Errorf("expected no changes, got creates=%d starts=%v stops=%v removes=%v", len(mock.containerCreateRequests), mock.containerStartIDs, mock.containerStopIDs, mock.containerRemoveIDs)
+	}
+
+	expected := []string{
+		"  would run `nitro init` to create the network",
+		"  would run `nitro init` to create the proxy",
+		"  would remove redis.service.nitro",
+		"  would back up mysql-5.7-3306",
+		"  would remove mysql-5.7-3306",
+		"Dry run complete: 0 to create, 0 to update, 2 to remove, 1 to back up",
+	}
+	for _, e := range expected {
+		found := false
+		for _, i := range spy.infos {
+			if i == e {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			t.Errorf("expected the output to contain %q, got %v", e, spy.infos)
+		}
+	}
+}
+
+func TestApplySiteDryRun(t *testing.T) {
+	home, err := ioutil.TempDir("", "nitro-apply")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+
+	if err := os.MkdirAll(filepath.Join(home, ".nitro"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := "sites:\n  - hostname: craft-dev.nitro\n    path: ~/craft-dev\n    version: \"8.0\"\n    webroot: web\n"
+	if err := ioutil.WriteFile(filepath.Join(home, ".nitro", "nitro.yaml"), []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mock := &mockDockerClient{
+		containers: []types.Container{
+			{
+				ID:    "redis-id",
+				Names: []string{"/redis.service.nitro"},
+				State: "exited",
+				Labels: map[string]string{
+					containerlabels.Nitro: "true",
+					containerlabels.Type:  "redis",
+				},
+			},
+		},
+	}
+	spy := &spyOutputer{}
+
+	cmd := NewCommand(home, mock, nil, spy)
+	cmd.Flags().Set("skip-hosts", "true")
+	cmd.Flags().Set("dry-run", "true")
+	cmd.Flags().Set("site", "craft-dev.nitro")
+
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatalf("expected the error to be nil, got %v", err)
+	}
+
+	if err := cmd.PostRunE(cmd, []string{}); err != nil {
+		t.Fatalf("expected the error to be nil, got %v", err)
+	}
+
+	// only the site is planned, the other containers are not cleaned up
+	want := "Dry run complete: 1 to create, 0 to update, 0 to remove, 0 to back up"
+	found := false
+	for _, i := range spy.infos {
+		if strings.HasPrefix(i, "  would remove") || strings.HasPrefix(i, "  would back up") {
+			t.Errorf("expected the plan to only include the site, got %q", i)
+		}
+
+		if i == want {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Errorf("expected the output to contain %q, got %v", want, spy.infos)
+	}
+}
+
+func TestApplySkipBackup(t *testing.T) {
+	home, _ := os.Getwd()
+	home = filepath.Join(home, "testdata")
+	defer os.Remove(filepath.Join(home, ".nitro", LogFile))
+
+	tests := []struct {
+		name     string
+		labels   map[string]string
+		noBackup bool
+	}{
+		{
+			name: "databases with skip backup are removed without a backup",
+			labels: map[string]string{
+				containerlabels.DatabaseSkipBackup: "true",
+			},
+		},
+		{
+			name:     "the no backup flag removes databases without a backup",
+			labels:   map[string]string{},
+			noBackup: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			labels := map[string]string{
+				containerlabels.Nitro:                 "true",
+				containerlabels.Type:                  "database",
+				containerlabels.DatabaseEngine:        "mysql",
+				containerlabels.DatabaseCompatibility: "mysql",
+			}
+			for k, v := range tt.labels {
+				labels[k] = v
+			}
+
+			// the mock does not implement exec, so a backup would panic
+			mock := &mockDockerClient{
+				containers: []types.Container{
+					{
+						ID:     "database-id",
+						Names:  []string{"/mysql-5.7-3306.database.nitro"},
+						State:  "running",
+						Labels: labels,
+					},
+				},
+			}
+			spy := &spyOutputer{}
+
+			cmd := NewCommand(home, mock, nil, spy)
+			if tt.noBackup {
+				cmd.Flags().Set("no-backup", "true")
+			}
+
+			if err := cmd.PostRunE(cmd, []string{}); err != nil {
+				t.Fatalf("expected the error to be nil, got %v", err)
+			}
+
+			if !reflect.DeepEqual(mock.containerRemoveIDs, []string{"database-id"}) {
+				t.Errorf("expected the database container to be removed, got %v", mock.containerRemoveIDs)
+			}
+
+			found := false
+			for _, i := range spy.infos {
+				if i == "Skipping the backup for mysql-5.7-3306.database.nitro" {
+					found = true
+				}
+			}
+
+			if !found {
+				t.Errorf("expected the backup to be skipped, got %v", spy.infos)
+			}
+		})
+	}
+}
+
+func TestApplyProfileRemovesInactiveServices(t *testing.T) {
+	home, err := ioutil.TempDir("", "nitro-home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+
+	if err := os.Mkdir(filepath.Join(home, ".nitro"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := []byte(`services:
+  mailhog: true
+  redis: true
+profiles:
+  minimal:
+    - mailhog
+`)
+	if err := ioutil.WriteFile(filepath.Join(home, ".nitro", "nitro.yaml"), cfg, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mock := &mockDockerClient{
+		containers: []types.Container{
+			{
+				ID:     "mailhog-id",
+				Names:  []string{"/mailhog.service.nitro"},
+				State:  "running",
+				Labels: map[string]string{containerlabels.Nitro: "true"},
+			},
+			{
+				ID:     "redis-id",
+				Names:  []string{"/redis.service.nitro"},
+				State:  "running",
+				Labels: map[string]string{containerlabels.Nitro: "true"},
+			},
+		},
+	}
+
+	cmd := NewCommand(home, mock, nil, &spyOutputer{})
+	cmd.Flags().Set("profile", "minimal")
+
+	if err := cmd.PostRunE(cmd, []string{}); err != nil {
+		t.Fatalf("expected the error to be nil, got %v", err)
+	}
+
+	if !reflect.DeepEqual(mock.containerRemoveIDs, []string{"redis-id"}) {
+		t.Errorf("expected only the redis container to be removed, got %v", mock.containerRemoveIDs)
+	}
+}
+
+func Test_externalNetwork(t *testing.T) {
+	mock := &mockDockerClient{
+		networks: []types.NetworkResource{
+			{ID: "mesh-dev-id", Name: "mesh-dev"},
+			{ID: "mesh-id", Name: "mesh"},
+		},
+	}
+
+	id, err := externalNetwork(context.Background(), mock, "mesh")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id != "mesh-id" {
+		t.Errorf("expected the network id to be mesh-id, got %q", id)
+	}
+
+	if _, err := externalNetwork(context.Background(), mock, "missing"); !errors.Is(err, ErrNoExternalNetwork) {
+		t.Errorf("expected the error to be ErrNoExternalNetwork, got %v", err)
+	}
+}
+
+func Test_proxyHostnames(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Config
+		want []string
+	}{
+		{
+			name: "custom containers use the nitro domain",
+			cfg:  config.Config{Containers: []config.Container{{Name: "adminer"}}, Services: config.Services{Mailhog: true}},
+			want: []string{"adminer.containers.nitro"},
+		},
+		{
+			name: "custom containers and proxied services use the configs domain",
+			cfg:  config.Config{TLD: "test", Containers: []config.Container{{Name: "adminer"}}, Services: config.Services{Mailhog: true, Redis: true}},
+			want: []string{"adminer.containers.test", "mailhog.service.test"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NITRO_DEFAULT_TLD", "")
+
+			if got := proxyHostnames(&tt.cfg); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("proxyHostnames() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_connectExternal(t *testing.T) {
+	tests := []struct {
+		name      string
+		networkID string
+		networks  map[string]*network.EndpointSettings
+		want      []string
+	}{
+		{
+			name:      "containers are connected to the external network",
+			networkID: "mesh-id",
+			networks: map[string]*network.EndpointSettings{
+				"nitro-network": {NetworkID: "nitro-network-id"},
+			},
+			want: []string{"mesh-id:site-id"},
+		},
+		{
+			name:      "containers already on the external network are not connected again",
+			networkID: "mesh-id",
+			networks: map[string]*network.EndpointSettings{
+				"nitro-network": {NetworkID: "nitro-network-id"},
+				"mesh":          {NetworkID: "mesh-id"},
+			},
+			want: nil,
+		},
+		{
+			name:      "no external network does nothing",
+			networkID: "",
+			want:      nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockDockerClient{
+				containerInspect: map[string]types.ContainerJSON{
+					"site-id": {
+						ContainerJSONBase: &types.ContainerJSONBase{Name: "/tutorial.nitro"},
+						NetworkSettings:   &types.NetworkSettings{Networks: tt.networks},
+					},
+				},
+			}
+
+			if err := connectExternal(context.Background(), mock, tt.networkID, "site-id"); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(mock.networkConnectRequests, tt.want) {
+				t.Errorf("expected the network connect requests to be %v, got %v", tt.want, mock.networkConnectRequests)
+			}
+		})
+	}
+}
+
+func Test_waitForDependencies(t *testing.T) {
+	timeout := dependencyTimeout
+	dependencyTimeout = 0
+	defer func() { dependencyTimeout = timeout }()
+
+	cfg := &config.Config{
+		Services: config.Services{Redis: true},
+		Sites: []config.Site{
+			{Hostname: "apple.nitro", DependsOn: []string{"redis"}},
+		},
+	}
+
+	running := func(health *types.Health) types.ContainerJSON {
+		return types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{State: &types.ContainerState{Running: true, Health: health}}}
+	}
+
+	tests := []struct {
+		name    string
+		inspect map[string]types.ContainerJSON
+		wantErr bool
+	}{
+		{
+			name:    "running dependencies without a health check are ready",
+			inspect: map[string]types.ContainerJSON{"redis.service.nitro": running(nil)},
+			wantErr: false,
+		},
+		{
+			name:    "healthy dependencies are ready",
+			inspect: map[string]types.ContainerJSON{"redis.service.nitro": running(&types.Health{Status: types.Healthy})},
+			wantErr: false,
+		},
+		{
+			name:    "unhealthy dependencies time out",
+			inspect: map[string]types.ContainerJSON{"redis.service.nitro": running(&types.Health{Status: types.Unhealthy})},
+			wantErr: true,
+		},
+		{
+			name:    "missing dependencies return an error",
+			inspect: map[string]types.ContainerJSON{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockDockerClient{containerInspect: tt.inspect}
+
+			if err := waitForDependencies(context.Background(), mock, cfg, cfg.Sites[0]); (err != nil) != tt.wantErr {
+				t.Errorf("waitForDependencies() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// mockNitroClient fails the number of pings before responding
+type mockNitroClient struct {
+	protob.NitroClient
+
+	failures int
+	pings    int
+
+	events    []*protob.ApplyEvent
+	streamErr error
+}
+
+func (m *mockNitroClient) Ping(ctx context.Context, in *protob.PingRequest, opts ...grpc.CallOption) (*protob.PingResponse, error) {
+	m.pings++
+	if m.pings <= m.failures {
+		return nil, errors.New("connection refused")
+	}
+
+	return &protob.PingResponse{Pong: "pong"}, nil
+}
+
+func (m *mockNitroClient) ApplyStream(ctx context.Context, in *protob.ApplyRequest, opts ...grpc.CallOption) (protob.Nitro_ApplyStreamClient, error) {
+	if m.streamErr != nil {
+		return nil, m.streamErr
+	}
+
+	return &mockApplyStream{events: m.events}, nil
+}
+
+// mockApplyStream returns the events and then io.EOF
+type mockApplyStream struct {
+	grpc.ClientStream
+
+	events []*protob.ApplyEvent
+}
+
+func (m *mockApplyStream) Recv() (*protob.ApplyEvent, error) {
+	if len(m.events) == 0 {
+		return nil, io.EOF
+	}
+
+	event := m.events[0]
+	m.events = m.events[1:]
+
+	return event, nil
+}
+
+func Test_streamApply(t *testing.T) {
+	tests := []struct {
+		name       string
+		events     []*protob.ApplyEvent
+		streamErr  error
+		wantOutput string
+		wantErr    bool
+	}{
+		{
+			name: "events are shown as they are received",
+			events: []*protob.ApplyEvent{
+				{Type: api.EventSite, Hostname: "craft-dev.nitro", Message: "added the routes for craft-dev.nitro"},
+				{Type: api.EventCertificate, Hostname: "craft-dev.nitro", Message: "loaded the certificate for craft-dev.nitro"},
+				{Type: api.EventRoute, Message: "updated the routes for 1 sites"},
+				{Type: api.EventDone, Message: "successfully applied changes"},
+			},
+			wantOutput: "  ✓ routed craft-dev.nitro\n  ✓ loaded the certificate for craft-dev.nitro\n  ✓ proxy updated\n",
+		},
+		{
+			name: "done events with an error return the error",
+			events: []*protob.ApplyEvent{
+				{Type: api.EventSite, Hostname: "craft-dev.nitro"},
+				{Type: api.EventDone, Message: "unable to update the routes", Error: true},
+			},
+			wantOutput: "  ✓ routed craft-dev.nitro\n",
+			wantErr:    true,
+		},
+		{
+			name:      "stream errors are returned",
+			streamErr: status.Error(codes.Unimplemented, "unknown method ApplyStream"),
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			output := terminal.NewRenderer(buf, false).Worker()
+			mock := &mockNitroClient{events: tt.events, streamErr: tt.streamErr}
+
+			if err := streamApply(context.Background(), mock, &protob.ApplyRequest{}, output); (err != nil) != tt.wantErr {
+				t.Errorf("streamApply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if buf.String() != tt.wantOutput {
+				t.Errorf("expected the output to be %q, got %q", tt.wantOutput, buf.String())
+			}
+		})
+	}
+}
+
+func Test_degradeSites(t *testing.T) {
+	tests := []struct {
+		name         string
+		capabilities map[string]bool
+		want         map[string]*protob.Site
+		wantWarnings int
+	}{
+		{
+			name:         "proxies with the capabilities receive every option",
+			capabilities: map[string]bool{api.CapabilityUpstream: true, api.CapabilityWebSockets: true, api.CapabilityCertificates: true},
+			want: map[string]*protob.Site{
+				"craft-dev.nitro": {Hostname: "craft-dev.nitro", Port: 8080, WebsocketsPort: 6001, Certificate: "cert", Key: "key"},
+				"vite.nitro":      {Hostname: "vite.nitro", Port: 5173, Upstream: "host.docker.internal"},
+			},
+		},
+		{
+			name:         "outdated proxies do not receive the options or host proxies",
+			capabilities: map[string]bool{},
+			want: map[string]*protob.Site{
+				"craft-dev.nitro": {Hostname: "craft-dev.nitro", Port: 8080},
+			},
+			wantWarnings: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sites := map[string]*protob.Site{
+				"craft-dev.nitro": {Hostname: "craft-dev.nitro", Port: 8080, WebsocketsPort: 6001, Certificate: "cert", Key: "key"},
+				"vite.nitro":      {Hostname: "vite.nitro", Port: 5173, Upstream: "host.docker.internal"},
+			}
+
+			warnings := degradeSites(sites, tt.capabilities)
+			if len(warnings) != tt.wantWarnings {
+				t.Errorf("expected %d warnings, got %v", tt.wantWarnings, warnings)
+			}
+
+			if len(sites) != len(tt.want) {
+				t.Fatalf("expected the sites to be %v, got %v", tt.want, sites)
+			}
+
+			for k, want := range tt.want {
+				if sites[k].String() != want.String() {
+					t.Errorf("expected the site %s to be %v, got %v", k, want, sites[k])
+				}
+			}
+		})
+	}
+}
+
+func Test_waitForProxy(t *testing.T) {
+	backoff, max := proxyBackoff, proxyMaxBackoff
+	proxyBackoff, proxyMaxBackoff = time.Millisecond, 2*time.Millisecond
+	defer func() { proxyBackoff, proxyMaxBackoff = backoff, max }()
+
+	tests := []struct {
+		name      string
+		failures  int
+		timeout   time.Duration
+		wantPings int
+		wantErr   error
+	}{
+		{
+			name:      "responding proxies are ready",
+			timeout:   time.Second,
+			wantPings: 1,
+		},
+		{
+			name:      "proxies are pinged until they respond",
+			failures:  3,
+			timeout:   time.Second,
+			wantPings: 4,
+		},
+		{
+			name:      "proxies that do not respond time out",
+			failures:  1000,
+			timeout:   0,
+			wantPings: 1,
+			wantErr:   ErrProxyTimeout,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockNitroClient{failures: tt.failures}
+
+			if err := waitForProxy(context.Background(), mock, tt.timeout); !errors.Is(err, tt.wantErr) {
+				t.Errorf("waitForProxy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if mock.pings != tt.wantPings {
+				t.Errorf("expected %d pings, got %d", tt.wantPings, mock.pings)
+			}
+		})
+	}
+}
+
+func TestApplyAsRoot(t *testing.T) {
+	home, _ := os.Getwd()
+	home = filepath.Join(home, "testdata")
+	defer os.Remove(filepath.Join(home, ".nitro", LogFile))
+
+	uid := getuid
+	defer func() { getuid = uid }()
+
+	sudoUser, hadSudoUser := os.LookupEnv("SUDO_USER")
+	defer func() {
+		if hadSudoUser {
+			os.Setenv("SUDO_USER", sudoUser)
+		} else {
+			os.Unsetenv("SUDO_USER")
+		}
+	}()
+
+	tests := []struct {
+		name      string
+		uid       int
+		sudoUser  string
+		allowRoot bool
+		wantErr   error
+	}{
+		{
+			name:     "sudo returns an error",
+			uid:      0,
+			sudoUser: "nitro",
+			wantErr:  ErrRunningAsRoot,
+		},
+		{
+			name:      "sudo with allow root continues",
+			uid:       0,
+			sudoUser:  "nitro",
+			allowRoot: true,
+			wantErr:   ErrNoNetwork,
+		},
+		{
+			name:    "root without sudo continues",
+			uid:     0,
+			wantErr: ErrNoNetwork,
+		},
+		{
+			name:    "non-root users continue",
+			uid:     501,
+			wantErr: ErrNoNetwork,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if runtime.GOOS == "windows" {
+				t.Skip("windows does not have user ids")
+			}
+
+			getuid = func() int { return tt.uid }
+			os.Setenv("SUDO_USER", tt.sudoUser)
+
+			cmd := NewCommand(home, &mockDockerClient{}, nil, &spyOutputer{})
+			cmd.Flags().Set("skip-hosts", "true")
+			cmd.Flags().Set("allow-root", strconv.FormatBool(tt.allowRoot))
+
+			// without a network, apply returns the no network error after the root check
+			if err := cmd.RunE(cmd, []string{}); !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected the error to be %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func Test_otherEnvironmentNames(t *testing.T) {
+	defer config.UseEnvironment("")
+
+	home := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(home, config.DirectoryName), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		"nitro.yaml": "databases:\n  - engine: mysql\n    version: \"8.0\"\n    port: \"3306\"\nservices:\n  redis: true\n",
+		"work.yaml":  "databases:\n  - engine: postgres\n    version: \"13\"\n    port: \"5432\"\nservices:\n  mailhog: true\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(home, config.DirectoryName, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		env  string
+		want []string
+	}{
+		{
+			name: "the default environment keeps the work databases and services",
+			want: []string{"postgres-13-5432.database.nitro", "mailhog.service.nitro"},
+		},
+		{
+			name: "the work environment keeps the default databases and services",
+			env:  "work",
+			want: []string{"mysql-8.0-3306.database.nitro", "redis.service.nitro"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := config.UseEnvironment(tt.env); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := otherEnvironmentNames(home)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("otherEnvironmentNames() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package apply
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+
+	"github.com/craftcms/nitro/pkg/backup"
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+// backupWorkers is the number of databases that are backed up at the same time
+var backupWorkers = 4
+
+// backupDatabases backs up the databases in the container using a pool of workers so
+// containers with a lot of databases are removed faster. It returns the number of
+// databases that could not be backed up.
+func backupDatabases(ctx context.Context, docker client.ContainerAPIClient, home, name string, c types.Container, databases []string, storage *config.Storage, output terminal.Outputer) int {
+	compatibility := c.Labels[containerlabels.DatabaseCompatibility]
+
+	// the workers share the output
+	renderer := terminal.NewOutputRenderer(output)
+
+	var wg sync.WaitGroup
+	var failed int32
+
+	jobs := make(chan string)
+	for i := 0; i < backupWorkers; i++ {
+		wg.Add(1)
+		go func(output terminal.Outputer) {
+			defer wg.Done()
+
+			for db := range jobs {
+				// create the database specific backup options
+				opts := &backup.Options{
+					BackupName:    backup.FileName(compatibility, db, time.Now()),
+					ContainerID:   c.ID,
+					ContainerName: name,
+					Database:      db,
+					Commands:      backup.Commands(compatibility, db),
+					Storage:       storage,
+				}
+
+				if err := backup.ToFile(ctx, docker, home, opts); err != nil {
+					atomic.AddInt32(&failed, 1)
+					output.Info("Unable to backup database", db, err.Error())
+
+					continue
+				}
+
+				output.Info("  created backup", opts.BackupName)
+			}
+		}(renderer.Worker())
+	}
+
+	for _, db := range databases {
+		jobs <- db
+	}
+	close(jobs)
+
+	wg.Wait()
+
+	return int(failed)
+}
@@ -0,0 +1,61 @@
+package apply
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+
+	"github.com/craftcms/nitro/pkg/containerlabels"
+)
+
+func Test_backupDatabases(t *testing.T) {
+	home, err := ioutil.TempDir("", "nitro-apply")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+
+	if err := os.Mkdir(filepath.Join(home, ".nitro"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	mock := &mockDockerClient{execFailures: map[string]bool{"broken": true}}
+	spy := &spyOutputer{}
+
+	c := types.Container{
+		ID:     "database-id",
+		Labels: map[string]string{containerlabels.DatabaseCompatibility: "mysql"},
+	}
+
+	databases := []string{"one", "two", "three", "four", "five", "six", "broken"}
+
+	failed := backupDatabases(context.Background(), mock, home, "mysql-8.0-3306", c, databases, nil, spy)
+	if failed != 1 {
+		t.Errorf("expected 1 failed backup, got %d", failed)
+	}
+
+	if len(mock.execs) != len(databases) {
+		t.Errorf("expected %d backups, got %d", len(databases), len(mock.execs))
+	}
+
+	files, err := ioutil.ReadDir(filepath.Join(home, ".nitro", "backups", "mysql-8.0-3306"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, f := range files {
+		names = append(names, strings.SplitN(f.Name(), "-", 2)[0])
+	}
+	sort.Strings(names)
+
+	if want := "five four one six three two"; strings.Join(names, " ") != want {
+		t.Errorf("expected the backups %q, got %q", want, strings.Join(names, " "))
+	}
+}
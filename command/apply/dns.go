@@ -0,0 +1,77 @@
+package apply
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/resolver"
+	"github.com/craftcms/nitro/pkg/sudo"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+// installResolver makes sure the system resolver sends queries for the top level domain to
+// the DNS container, it will run the dns install command with sudo when the resolver needs to
+// be installed. It returns false when the system does not support the resolver, so the hosts
+// file should be used instead.
+func installResolver(cfg *config.Config, output terminal.Outputer) (bool, error) {
+	tld, port := cfg.GetTLD(), cfg.GetDNSPort()
+
+	installed, err := resolver.IsInstalled(tld, port)
+	if errors.Is(err, resolver.ErrUnsupported) || errors.Is(err, resolver.ErrUnsupportedPort) {
+		output.Info("Warning:", "unable to use the dns container,", err.Error()+", the hosts file will be used")
+
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if installed {
+		return true, nil
+	}
+
+	nitro, err := os.Executable()
+	if err != nil {
+		return false, fmt.Errorf("unable to locate the nitro path, %w", err)
+	}
+
+	args := []string{"dns", "install", "--tld=" + tld, "--port=" + port}
+
+	switch runtime.GOOS {
+	case "windows":
+		// windows users should be running as admin
+		c := exec.Command(nitro, args...)
+		c.Stdout = os.Stdout
+		c.Stderr = os.Stderr
+
+		if err := c.Run(); err != nil {
+			return false, fmt.Errorf("unable to install the resolver, %w", err)
+		}
+	default:
+		output.Info("Installing the resolver for", "."+tld, "(you might be prompted for your password)")
+
+		if err := sudo.Run(nitro, append([]string{"nitro"}, args...)...); err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// withoutTLD returns the hostnames that do not use the top level domain, which are not
+// resolved by the DNS container and still need to be in the hosts file.
+func withoutTLD(hostnames []string, tld string) []string {
+	var remaining []string
+	for _, h := range hostnames {
+		if !strings.HasSuffix(h, "."+tld) {
+			remaining = append(remaining, h)
+		}
+	}
+
+	return remaining
+}
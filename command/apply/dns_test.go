@@ -0,0 +1,34 @@
+package apply
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_withoutTLD(t *testing.T) {
+	tests := []struct {
+		name      string
+		hostnames []string
+		tld       string
+		want      []string
+	}{
+		{
+			name:      "hostnames using the domain are removed",
+			hostnames: []string{"tutorial.nitro", "www.tutorial.nitro", "example.test", "nitro.com"},
+			tld:       "nitro",
+			want:      []string{"example.test", "nitro.com"},
+		},
+		{
+			name:      "all hostnames use the domain",
+			hostnames: []string{"tutorial.test"},
+			tld:       "test",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withoutTLD(tt.hostnames, tt.tld); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("withoutTLD() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package apply
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+
+	"github.com/craftcms/nitro/pkg/containerlogs"
+)
+
+// healthLogLines is the number of log lines to show for unhealthy containers
+var healthLogLines = "10"
+
+// unhealthy is a container that is not running or failed its health check.
+type unhealthy struct {
+	name   string
+	status string
+	logs   []string
+}
+
+// checkHealth inspects the containers and returns the containers that are not running or are
+// unhealthy. Containers that are still starting are not reported. When showLogs is true, the
+// last lines of the logs are included for each unhealthy container.
+func checkHealth(ctx context.Context, docker client.ContainerAPIClient, ids []string, showLogs bool) ([]unhealthy, error) {
+	var report []unhealthy
+	for _, id := range ids {
+		if id == "" {
+			continue
+		}
+
+		info, err := docker.ContainerInspect(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("unable to inspect the container, %w", err)
+		}
+
+		u := unhealthy{name: strings.TrimLeft(info.Name, "/")}
+
+		switch {
+		case info.State == nil:
+			continue
+		case !info.State.Running:
+			u.status = info.State.Status
+			if info.State.ExitCode != 0 {
+				u.status = fmt.Sprintf("%s (exit code %d)", info.State.Status, info.State.ExitCode)
+			}
+		case info.State.Health != nil && info.State.Health.Status == types.Unhealthy:
+			u.status = types.Unhealthy
+		default:
+			continue
+		}
+
+		if showLogs {
+			u.logs, err = tail(ctx, docker, id)
+			if err != nil {
+				u.logs = []string{fmt.Sprintf("unable to get the logs, %s", err)}
+			}
+		}
+
+		report = append(report, u)
+	}
+
+	return report, nil
+}
+
+// healthSummary returns the lines to display for the unhealthy containers.
+func healthSummary(report []unhealthy) []string {
+	var lines []string
+	for _, u := range report {
+		lines = append(lines, fmt.Sprintf("  %s is %s", u.name, u.status))
+
+		for _, l := range u.logs {
+			lines = append(lines, "    | "+l)
+		}
+	}
+
+	return lines
+}
+
+// tail returns the last lines of the containers logs.
+func tail(ctx context.Context, docker client.ContainerAPIClient, id string) ([]string, error) {
+	out, err := containerlogs.Tail(ctx, docker, id, healthLogLines)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	return lines, scanner.Err()
+}
@@ -0,0 +1,98 @@
+package apply
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+)
+
+func Test_checkHealth(t *testing.T) {
+	mock := &mockDockerClient{
+		containerInspect: map[string]types.ContainerJSON{
+			"running-id": {
+				ContainerJSONBase: &types.ContainerJSONBase{
+					Name:  "/running.nitro",
+					State: &types.ContainerState{Running: true, Status: "running"},
+				},
+			},
+			"healthy-id": {
+				ContainerJSONBase: &types.ContainerJSONBase{
+					Name:  "/mysql-8.0-3306.database.nitro",
+					State: &types.ContainerState{Running: true, Status: "running", Health: &types.Health{Status: types.Healthy}},
+				},
+			},
+			"starting-id": {
+				ContainerJSONBase: &types.ContainerJSONBase{
+					Name:  "/starting.nitro",
+					State: &types.ContainerState{Running: true, Status: "running", Health: &types.Health{Status: types.Starting}},
+				},
+			},
+			"unhealthy-id": {
+				ContainerJSONBase: &types.ContainerJSONBase{
+					Name:  "/unhealthy.nitro",
+					State: &types.ContainerState{Running: true, Status: "running", Health: &types.Health{Status: types.Unhealthy}},
+				},
+			},
+			"exited-id": {
+				ContainerJSONBase: &types.ContainerJSONBase{
+					Name:  "/exited.nitro",
+					State: &types.ContainerState{Running: false, Status: "exited", ExitCode: 1},
+				},
+			},
+		},
+		containerLogs: map[string]string{
+			"unhealthy-id": "php-fpm is ready\nnginx: [emerg] unknown directive\n",
+			"exited-id":    "PHP Fatal error: Allowed memory size exhausted\n",
+		},
+	}
+
+	ids := []string{"running-id", "healthy-id", "starting-id", "unhealthy-id", "exited-id"}
+
+	t.Run("unhealthy containers are reported without logs", func(t *testing.T) {
+		report, err := checkHealth(context.Background(), mock, ids, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := []string{
+			"  unhealthy.nitro is unhealthy",
+			"  exited.nitro is exited (exit code 1)",
+		}
+
+		if got := healthSummary(report); !reflect.DeepEqual(got, want) {
+			t.Errorf("expected the summary to be\n%v\ngot\n%v", want, got)
+		}
+	})
+
+	t.Run("logs are included when requested", func(t *testing.T) {
+		report, err := checkHealth(context.Background(), mock, ids, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := []string{
+			"  unhealthy.nitro is unhealthy",
+			"    | php-fpm is ready",
+			"    | nginx: [emerg] unknown directive",
+			"  exited.nitro is exited (exit code 1)",
+			"    | PHP Fatal error: Allowed memory size exhausted",
+		}
+
+		if got := healthSummary(report); !reflect.DeepEqual(got, want) {
+			t.Errorf("expected the summary to be\n%v\ngot\n%v", want, got)
+		}
+	})
+
+	t.Run("healthy containers return an empty report", func(t *testing.T) {
+		report, err := checkHealth(context.Background(), mock, []string{"running-id", "healthy-id"}, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(report) != 0 {
+			t.Errorf("expected an empty report, got %v", report)
+		}
+	})
+}
@@ -0,0 +1,100 @@
+package apply
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+
+	"github.com/craftcms/nitro/pkg/config"
+)
+
+// started is a site container that was created or started during apply.
+type started struct {
+	id   string
+	site config.Site
+}
+
+// hostShell returns the shell and arguments to run a hook on the host.
+func hostShell(command string) (string, []string) {
+	if runtime.GOOS == "windows" {
+		return "cmd", []string{"/C", command}
+	}
+
+	return "sh", []string{"-c", command}
+}
+
+// runHooks runs the commands on the host in order and stops at the first command that fails.
+func runHooks(ctx context.Context, commands []string, stdout, stderr io.Writer) error {
+	for _, c := range commands {
+		name, args := hostShell(c)
+
+		cmd := exec.CommandContext(ctx, name, args...)
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("the hook %q failed, %w", c, err)
+		}
+	}
+
+	return nil
+}
+
+// runPostStart runs the sites post start commands in the container from the directory with the
+// craft executable and stops at the first command that fails.
+func runPostStart(ctx context.Context, docker client.ContainerAPIClient, id string, site config.Site, w io.Writer) error {
+	dir := "/app"
+	if p := site.GetContainerPath(); p != "" {
+		dir = "/app/" + p
+	}
+
+	for _, c := range site.PostStart {
+		created, err := docker.ContainerExecCreate(ctx, id, types.ExecConfig{
+			AttachStdout: true,
+			AttachStderr: true,
+			Tty:          false,
+			WorkingDir:   dir,
+			Cmd:          []string{"sh", "-c", c},
+		})
+		if err != nil {
+			return err
+		}
+
+		resp, err := docker.ContainerExecAttach(ctx, created.ID, types.ExecStartCheck{Tty: false})
+		if err != nil {
+			return err
+		}
+
+		if err := docker.ContainerExecStart(ctx, created.ID, types.ExecStartCheck{}); err != nil {
+			resp.Close()
+			return fmt.Errorf("unable to start the container exec, %w", err)
+		}
+
+		// show the output, stderr is also kept for errors
+		stderr := new(bytes.Buffer)
+		_, err = stdcopy.StdCopy(w, io.MultiWriter(w, stderr), resp.Reader)
+		resp.Close()
+		if err != nil {
+			return err
+		}
+
+		info, err := docker.ContainerExecInspect(ctx, created.ID)
+		if err != nil {
+			return err
+		}
+
+		if info.ExitCode != 0 {
+			return fmt.Errorf("the post start command %q for %s exited with code %d, %s", c, site.Hostname, info.ExitCode, strings.TrimSpace(stderr.String()))
+		}
+	}
+
+	return nil
+}
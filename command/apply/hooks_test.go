@@ -0,0 +1,104 @@
+package apply
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/craftcms/nitro/pkg/config"
+)
+
+func Test_runHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the hooks use sh")
+	}
+
+	tests := []struct {
+		name     string
+		commands []string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "commands run in order",
+			commands: []string{"echo one", "echo two"},
+			want:     "one\ntwo\n",
+		},
+		{
+			name:     "failed commands stop the hooks",
+			commands: []string{"echo one", "exit 3", "echo two"},
+			want:     "one\n",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+
+			if err := runHooks(context.Background(), tt.commands, out, out); (err != nil) != tt.wantErr {
+				t.Errorf("runHooks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if out.String() != tt.want {
+				t.Errorf("expected the output to be %q, got %q", tt.want, out.String())
+			}
+		})
+	}
+}
+
+func Test_runPostStart(t *testing.T) {
+	tests := []struct {
+		name     string
+		site     config.Site
+		failures map[string]bool
+		want     [][]string
+		wantErr  bool
+	}{
+		{
+			name: "commands run in the container with the shell",
+			site: config.Site{
+				Hostname:  "craft-dev.nitro",
+				Webroot:   "web",
+				PostStart: []string{"composer install", "php craft migrate/all"},
+			},
+			want: [][]string{
+				{"sh", "-c", "composer install"},
+				{"sh", "-c", "php craft migrate/all"},
+			},
+		},
+		{
+			name: "failed commands stop the post start commands",
+			site: config.Site{
+				Hostname:  "craft-dev.nitro",
+				Webroot:   "web",
+				PostStart: []string{"composer install", "php craft migrate/all"},
+			},
+			failures: map[string]bool{"composer install": true},
+			want: [][]string{
+				{"sh", "-c", "composer install"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockDockerClient{execFailures: tt.failures}
+
+			if err := runPostStart(context.Background(), mock, "site-id", tt.site, &bytes.Buffer{}); (err != nil) != tt.wantErr {
+				t.Errorf("runPostStart() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var got [][]string
+			for i := 0; i < len(mock.execs); i++ {
+				got = append(got, mock.execs[fmt.Sprintf("exec-%d", i)])
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected the commands to be %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
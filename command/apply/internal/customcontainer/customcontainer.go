@@ -6,12 +6,15 @@ import (
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
+	"sort"
 	"strings"
 
+	"github.com/craftcms/nitro/command/apply/internal/imagepull"
 	"github.com/craftcms/nitro/command/apply/internal/match"
 	"github.com/craftcms/nitro/pkg/config"
 	"github.com/craftcms/nitro/pkg/containerlabels"
 	"github.com/craftcms/nitro/pkg/pathexists"
+	"github.com/craftcms/nitro/pkg/platform"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
@@ -82,8 +85,14 @@ func create(ctx context.Context, docker client.CommonAPIClient, home, networkID
 	// create the container
 	image := fmt.Sprintf("%s:%s", c.Image, c.Tag)
 
+	// get the platform for the image, nil uses the host platform
+	plat, err := platform.Parse(c.Platform)
+	if err != nil {
+		return "", err
+	}
+
 	// pull the image
-	rdr, err := docker.ImagePull(ctx, image, types.ImagePullOptions{All: false})
+	rdr, err := docker.ImagePull(ctx, image, types.ImagePullOptions{All: false, Platform: c.Platform})
 	if err != nil {
 		return "", fmt.Errorf("unable to pull the image, %w", err)
 	}
@@ -93,8 +102,19 @@ func create(ctx context.Context, docker client.CommonAPIClient, home, networkID
 		return "", fmt.Errorf("unable to read output from pulling image %s, %w", image, err)
 	}
 
-	// get the containers custom environment variables from the file
+	// get the containers environment variables from the config
+	var keys []string
+	for k := range c.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
 	var customEnvs []string
+	for _, k := range keys {
+		customEnvs = append(customEnvs, k+"="+c.Env[k])
+	}
+
+	// get the containers custom environment variables from the file
 	if c.EnvFile != "" {
 		// get the file
 		envFilePath := filepath.Join(home, config.DirectoryName, "."+c.Name)
@@ -116,7 +136,7 @@ func create(ctx context.Context, docker client.CommonAPIClient, home, networkID
 		}
 	}
 
-	labels := containerlabels.ForCustomContainer(c)
+	labels := containerlabels.StampRunID(ctx, containerlabels.ForCustomContainer(c))
 
 	config := &container.Config{
 		Image:  image,
@@ -145,18 +165,17 @@ func create(ctx context.Context, docker client.CommonAPIClient, home, networkID
 			}
 
 			if len(resp.Volumes) == 0 {
-				vol, err := docker.VolumeCreate(ctx, volume.VolumeCreateBody{Driver: "local", Name: name, Labels: labels})
-				if err != nil {
+				if _, err := docker.VolumeCreate(ctx, volume.VolumeCreateBody{Driver: "local", Name: name, Labels: labels}); err != nil {
 					return "", err
 				}
-
-				// append the mount
-				mounts = append(mounts, mount.Mount{
-					Type:   mount.TypeVolume,
-					Source: vol.Name,
-					Target: v,
-				})
 			}
+
+			// append the mount, existing volumes are reused when the container is recreated
+			mounts = append(mounts, mount.Mount{
+				Type:   mount.TypeVolume,
+				Source: name,
+				Target: v,
+			})
 		}
 	}
 
@@ -190,8 +209,9 @@ func create(ctx context.Context, docker client.CommonAPIClient, home, networkID
 	config.ExposedPorts = portSettings
 
 	// create the container
-	resp, err := docker.ContainerCreate(
+	resp, err := imagepull.Create(
 		ctx,
+		docker,
 		config,
 		&container.HostConfig{
 			Mounts:       mounts,
@@ -204,7 +224,7 @@ func create(ctx context.Context, docker client.CommonAPIClient, home, networkID
 				},
 			},
 		},
-		nil,
+		plat,
 		fmt.Sprintf("%s%s", c.Name, Suffix),
 	)
 	if err != nil {
@@ -8,8 +8,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/craftcms/nitro/command/apply/internal/imagepull"
+	"github.com/craftcms/nitro/command/apply/internal/match"
 	"github.com/craftcms/nitro/pkg/config"
 	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/platform"
 	"github.com/craftcms/nitro/pkg/terminal"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
@@ -42,21 +45,55 @@ func StartOrCreate(ctx context.Context, docker client.CommonAPIClient, networkID
 		return "", "", err
 	}
 
-	// set the container database compatibility
-	if db.Engine == "mariadb" || db.Engine == "mysql" {
-		filter.Add("label", containerlabels.DatabaseCompatibility+"=mysql")
-	} else {
-		filter.Add("label", containerlabels.DatabaseCompatibility+"=postgres")
+	// get the cpu and memory limits for the container
+	resources, err := limits(db)
+	if err != nil {
+		return "", "", err
 	}
 
+	// set the container database compatibility
+	filter.Add("label", containerlabels.DatabaseCompatibility+"="+compatibility(db.Engine))
+
 	// get the containers for the database
 	containers, err := docker.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: filter})
 	if err != nil {
 		return "", "", fmt.Errorf("error getting a list of containers")
 	}
 
+	// labels cannot be changed, so the container is recreated to change the skip backup
+	// label. The data is kept in the volume for the database.
+	if len(containers) == 1 && (containers[0].Labels[containerlabels.DatabaseSkipBackup] == "true") != db.SkipBackup {
+		output.Pending("updating", hostname)
+
+		if err := docker.ContainerStop(ctx, containers[0].ID, nil); err != nil {
+			output.Warning()
+
+			return "", "", fmt.Errorf("unable to stop %s, %w", hostname, err)
+		}
+
+		if err := docker.ContainerRemove(ctx, containers[0].ID, types.ContainerRemoveOptions{}); err != nil {
+			output.Warning()
+
+			return "", "", fmt.Errorf("unable to remove %s, %w", hostname, err)
+		}
+
+		output.Done()
+
+		containers = nil
+	}
+
 	// if there is a container, we should start it and return
 	if len(containers) == 1 {
+		// the database is not recreated when the config changes to prevent losing data
+		if match.Drifted(containers[0].Labels, db.Hash()) {
+			output.Info("Warning:", hostname, "was created with different settings, remove the database from the config and apply to recreate it")
+		}
+
+		// the limits are updated in place since they do not require recreating the container
+		if err := updateLimits(ctx, docker, containers[0].ID, hostname, resources, output); err != nil {
+			return "", "", err
+		}
+
 		// check if the container is running
 		if containers[0].State != "running" {
 			// start the container
@@ -69,25 +106,22 @@ func StartOrCreate(ctx context.Context, docker client.CommonAPIClient, networkID
 	}
 
 	// create the database labels for the new container
-	labels := map[string]string{
+	labels := containerlabels.StampRunID(ctx, map[string]string{
 		containerlabels.Nitro:           "true",
 		containerlabels.DatabaseEngine:  db.Engine,
 		containerlabels.DatabaseVersion: db.Version,
 		containerlabels.Type:            "database",
 		containerlabels.DatabasePort:    db.Port,
-	}
+		containerlabels.ConfigHash:      db.Hash(),
+	})
 
-	// if the database is mysql or mariadb, mark them as
+	// mark the database compatibility, mysql and mariadb are
 	// mysql compatible (used for importing backups)
-	if db.Engine == "mariadb" || db.Engine == "mysql" {
-		labels[containerlabels.DatabaseCompatibility] = "mysql"
-	}
+	labels[containerlabels.DatabaseCompatibility] = compatibility(db.Engine)
 
-	// if the database is postgres, mark it as compatible
-	// with postgres. This is not needed but a place holder
-	// if cockroachdb is ever supported by craft.
-	if db.Engine == "postgres" {
-		labels[containerlabels.DatabaseCompatibility] = "postgres"
+	// mark the database to skip the backup when it is removed
+	if db.SkipBackup {
+		labels[containerlabels.DatabaseSkipBackup] = "true"
 	}
 
 	// create the volume
@@ -97,15 +131,25 @@ func StartOrCreate(ctx context.Context, docker client.CommonAPIClient, networkID
 	}
 
 	// determine the image name
-	image := fmt.Sprintf(DatabaseImage, db.Engine, db.Version)
+	image := fmt.Sprintf(DatabaseImage, imageName(db.Engine), db.Version)
+
+	// get the platform for the image, nil uses the host platform
+	p, err := platform.Parse(db.Platform)
+	if err != nil {
+		return "", "", err
+	}
 
 	// set mounts and environment based on the database type
 	target := "/var/lib/mysql"
 	var envs []string
-	if strings.Contains(image, "postgres") {
+	switch {
+	case strings.Contains(image, "postgres"):
 		target = "/var/lib/postgresql/data"
 		envs = []string{"POSTGRES_USER=nitro", "POSTGRES_DB=nitro", "POSTGRES_PASSWORD=nitro"}
-	} else {
+	case db.Engine == "mongodb":
+		target = "/data/db"
+		envs = []string{"MONGO_INITDB_ROOT_USERNAME=nitro", "MONGO_INITDB_ROOT_PASSWORD=nitro", "MONGO_INITDB_DATABASE=nitro"}
+	default:
 		envs = []string{"MYSQL_ROOT_PASSWORD=nitro", "MYSQL_DATABASE=nitro", "MYSQL_USER=nitro", "MYSQL_PASSWORD=nitro"}
 	}
 
@@ -121,12 +165,12 @@ func StartOrCreate(ctx context.Context, docker client.CommonAPIClient, networkID
 		return "", "", fmt.Errorf("unable to get a list of images, %w", err)
 	}
 
-	// if there are no images, pull one
-	if len(images) == 0 {
+	// if there are no images or a specific platform is needed, pull one
+	if len(images) == 0 || p != nil {
 		output.Pending("downloading", image)
 
 		// pull the image
-		rdr, err := docker.ImagePull(ctx, image, types.ImagePullOptions{All: false})
+		rdr, err := docker.ImagePull(ctx, image, types.ImagePullOptions{All: false, Platform: db.Platform})
 		if err != nil {
 			output.Warning()
 
@@ -149,6 +193,11 @@ func StartOrCreate(ctx context.Context, docker client.CommonAPIClient, networkID
 		if err != nil {
 			return "", "", fmt.Errorf("unable to create the port, %w", err)
 		}
+	case "mongodb":
+		port, err = nat.NewPort("tcp", "27017")
+		if err != nil {
+			return "", "", fmt.Errorf("unable to create the port, %w", err)
+		}
 	default:
 		port, err = nat.NewPort("tcp", "3306")
 		if err != nil {
@@ -171,7 +220,8 @@ func StartOrCreate(ctx context.Context, docker client.CommonAPIClient, networkID
 	}
 
 	hostConfig := &container.HostConfig{
-		CapAdd: []string{"SYS_NICE"},
+		CapAdd:    []string{"SYS_NICE"},
+		Resources: resources,
 		Mounts: []mount.Mount{
 			{
 				Type:   mount.TypeVolume,
@@ -198,7 +248,7 @@ func StartOrCreate(ctx context.Context, docker client.CommonAPIClient, networkID
 	}
 
 	// create the container for the database
-	resp, err := docker.ContainerCreate(ctx, containerConfig, hostConfig, networkConfig, nil, hostname)
+	resp, err := imagepull.Create(ctx, docker, containerConfig, hostConfig, networkConfig, p, hostname)
 	if err != nil {
 		return "", "", fmt.Errorf("unable to create the container, %w", err)
 	}
@@ -218,6 +268,69 @@ func StartOrCreate(ctx context.Context, docker client.CommonAPIClient, networkID
 	return resp.ID, hostname, nil
 }
 
+// limits returns the cpu and memory limits for the database container, zero values
+// do not limit the container.
+func limits(db config.Database) (container.Resources, error) {
+	memory, err := config.MemoryBytes(db.Memory)
+	if err != nil {
+		return container.Resources{}, err
+	}
+
+	return container.Resources{NanoCPUs: config.NanoCPUs(db.CPUs), Memory: memory}, nil
+}
+
+// updateLimits updates the cpu and memory limits for an existing database container when they
+// changed. Docker cannot remove limits from a container, so removed limits show a warning.
+func updateLimits(ctx context.Context, docker client.ContainerAPIClient, id, hostname string, resources container.Resources, output terminal.Outputer) error {
+	details, err := docker.ContainerInspect(ctx, id)
+	if err != nil {
+		return fmt.Errorf("unable to inspect %s, %w", hostname, err)
+	}
+
+	current := details.HostConfig.Resources
+	if current.NanoCPUs == resources.NanoCPUs && current.Memory == resources.Memory {
+		return nil
+	}
+
+	if (resources.NanoCPUs == 0 && current.NanoCPUs != 0) || (resources.Memory == 0 && current.Memory != 0) {
+		output.Info("Warning:", hostname, "has limits that were removed from the config, remove the database from the config and apply to recreate it")
+
+		return nil
+	}
+
+	// docker defaults the swap to twice the memory and requires the memory to be less than the swap
+	if resources.Memory != 0 {
+		resources.MemorySwap = resources.Memory * 2
+	}
+
+	if _, err := docker.ContainerUpdate(ctx, id, container.UpdateConfig{Resources: resources}); err != nil {
+		return fmt.Errorf("unable to update the limits for %s, %w", hostname, err)
+	}
+
+	return nil
+}
+
+// compatibility returns the compatibility label for the engine, mysql and mariadb are mysql compatible.
+func compatibility(engine string) string {
+	switch engine {
+	case "mariadb", "mysql":
+		return "mysql"
+	case "mongodb":
+		return "mongodb"
+	default:
+		return "postgres"
+	}
+}
+
+// imageName returns the name of the image for the engine, the mongodb image is named mongo.
+func imageName(engine string) string {
+	if engine == "mongodb" {
+		return "mongo"
+	}
+
+	return engine
+}
+
 func waitForMySQLContainer(ctx context.Context, docker client.CommonAPIClient, containerID string, d config.Database) error {
 	// verify the mysql socket exists in the container
 	for {
@@ -0,0 +1,142 @@
+package databasecontainer
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/craftcms/nitro/pkg/terminal"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	volumetypes "github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+type spyOutputer struct {
+	infos []string
+}
+
+func (spy *spyOutputer) Ask(message, fallback, sep string, validator terminal.Validator) (string, error) {
+	return fallback, nil
+}
+
+func (spy *spyOutputer) Confirm(message string, fallback bool, sep string) (bool, error) {
+	return fallback, nil
+}
+
+func (spy *spyOutputer) Info(s ...string) {
+	spy.infos = append(spy.infos, strings.Join(s, " "))
+}
+
+func (spy *spyOutputer) Select(r io.Reader, msg string, opts []string) (int, error) {
+	return 0, nil
+}
+
+func (spy *spyOutputer) Warning() {}
+
+func (spy *spyOutputer) Success(s ...string) {}
+
+func (spy *spyOutputer) Pending(s ...string) {}
+
+func (spy *spyOutputer) Done() {}
+
+type mockDockerClient struct {
+	client.CommonAPIClient
+
+	// container related resources
+	containers              []types.Container
+	containerCreatePlatform []*v1.Platform
+	containerCreateLabels   []map[string]string
+	containerCreateConfig   *container.Config
+	containerCreateResponse container.ContainerCreateCreatedBody
+
+	// containerCreateErrors are returned in order for each create request
+	containerCreateErrors []error
+
+	// the containers that were stopped and removed
+	containerStopRequests   []string
+	containerRemoveRequests []string
+
+	// resource limits for existing containers and the updates to them
+	containerResources     container.Resources
+	containerUpdateConfigs []container.UpdateConfig
+
+	// image related resources
+	images            []types.ImageSummary
+	imagePullRequests []types.ImagePullOptions
+
+	// mockError allows us to override any func to return a method, we do not
+	// set the error by default.
+	mockError error
+}
+
+func (c *mockDockerClient) ContainerList(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error) {
+	return c.containers, c.mockError
+}
+
+func (c *mockDockerClient) ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *v1.Platform, containerName string) (container.ContainerCreateCreatedBody, error) {
+	c.containerCreatePlatform = append(c.containerCreatePlatform, platform)
+	c.containerCreateLabels = append(c.containerCreateLabels, config.Labels)
+	c.containerCreateConfig = config
+
+	if len(c.containerCreateErrors) > 0 {
+		err := c.containerCreateErrors[0]
+		c.containerCreateErrors = c.containerCreateErrors[1:]
+
+		if err != nil {
+			return container.ContainerCreateCreatedBody{}, err
+		}
+	}
+
+	return c.containerCreateResponse, c.mockError
+}
+
+func (c *mockDockerClient) ContainerInspect(ctx context.Context, id string) (types.ContainerJSON, error) {
+	return types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			ID:         id,
+			HostConfig: &container.HostConfig{Resources: c.containerResources},
+		},
+	}, c.mockError
+}
+
+func (c *mockDockerClient) ContainerUpdate(ctx context.Context, id string, updateConfig container.UpdateConfig) (container.ContainerUpdateOKBody, error) {
+	c.containerUpdateConfigs = append(c.containerUpdateConfigs, updateConfig)
+
+	return container.ContainerUpdateOKBody{}, c.mockError
+}
+
+func (c *mockDockerClient) ContainerStop(ctx context.Context, container string, timeout *time.Duration) error {
+	c.containerStopRequests = append(c.containerStopRequests, container)
+
+	return c.mockError
+}
+
+func (c *mockDockerClient) ContainerRemove(ctx context.Context, container string, options types.ContainerRemoveOptions) error {
+	c.containerRemoveRequests = append(c.containerRemoveRequests, container)
+
+	return c.mockError
+}
+
+func (c *mockDockerClient) ContainerStart(ctx context.Context, container string, options types.ContainerStartOptions) error {
+	return c.mockError
+}
+
+func (c *mockDockerClient) VolumeCreate(ctx context.Context, options volumetypes.VolumeCreateBody) (types.Volume, error) {
+	return types.Volume{Name: options.Name}, c.mockError
+}
+
+func (c *mockDockerClient) ImageList(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error) {
+	return c.images, c.mockError
+}
+
+func (c *mockDockerClient) ImagePull(ctx context.Context, ref string, options types.ImagePullOptions) (io.ReadCloser, error) {
+	c.imagePullRequests = append(c.imagePullRequests, options)
+
+	return ioutil.NopCloser(&bytes.Buffer{}), c.mockError
+}
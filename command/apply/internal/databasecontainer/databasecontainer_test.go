@@ -0,0 +1,262 @@
+package databasecontainer
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/errdefs"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestStartOrCreatePlatform(t *testing.T) {
+	tests := []struct {
+		name         string
+		db           config.Database
+		images       []types.ImageSummary
+		wantPlatform *v1.Platform
+		wantPulls    []types.ImagePullOptions
+		wantErr      bool
+	}{
+		{
+			name:         "databases without a platform use the host platform",
+			db:           config.Database{Engine: "postgres", Version: "13", Port: "5432"},
+			images:       []types.ImageSummary{{ID: "postgres"}},
+			wantPlatform: nil,
+			wantPulls:    nil,
+		},
+		{
+			name:         "databases with a platform pull and create using the platform",
+			db:           config.Database{Engine: "postgres", Version: "13", Port: "5432", Platform: "linux/amd64"},
+			images:       []types.ImageSummary{{ID: "postgres"}},
+			wantPlatform: &v1.Platform{OS: "linux", Architecture: "amd64"},
+			wantPulls:    []types.ImagePullOptions{{Platform: "linux/amd64"}},
+		},
+		{
+			name:    "invalid platforms return an error",
+			db:      config.Database{Engine: "postgres", Version: "13", Port: "5432", Platform: "amd64"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockDockerClient{
+				images:                  tt.images,
+				containerCreateResponse: container.ContainerCreateCreatedBody{ID: "database-id"},
+			}
+
+			_, _, err := StartOrCreate(context.Background(), mock, "network-id", tt.db, &spyOutputer{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("StartOrCreate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				if len(mock.containerCreatePlatform) > 0 {
+					t.Errorf("expected no containers to be created")
+				}
+				return
+			}
+
+			if len(mock.containerCreatePlatform) != 1 || !reflect.DeepEqual(mock.containerCreatePlatform[0], tt.wantPlatform) {
+				t.Errorf("expected the container to be created with platform %v, got %v", tt.wantPlatform, mock.containerCreatePlatform)
+			}
+
+			if !reflect.DeepEqual(mock.imagePullRequests, tt.wantPulls) {
+				t.Errorf("expected the image pulls to be %v, got %v", tt.wantPulls, mock.imagePullRequests)
+			}
+		})
+	}
+}
+
+func TestStartOrCreateSkipBackup(t *testing.T) {
+	tests := []struct {
+		name        string
+		db          config.Database
+		containers  []types.Container
+		wantCreated bool
+		want        string
+	}{
+		{
+			name:        "databases are backed up by default",
+			db:          config.Database{Engine: "postgres", Version: "13", Port: "5432"},
+			wantCreated: true,
+			want:        "",
+		},
+		{
+			name:        "databases with skip backup are labeled",
+			db:          config.Database{Engine: "postgres", Version: "13", Port: "5432", SkipBackup: true},
+			wantCreated: true,
+			want:        "true",
+		},
+		{
+			name:       "existing containers with the same skip backup are kept",
+			db:         config.Database{Engine: "postgres", Version: "13", Port: "5432", SkipBackup: true},
+			containers: []types.Container{{ID: "existing-id", State: "running", Labels: map[string]string{containerlabels.DatabaseSkipBackup: "true"}}},
+		},
+		{
+			name:        "existing containers are recreated when skip backup is added",
+			db:          config.Database{Engine: "postgres", Version: "13", Port: "5432", SkipBackup: true},
+			containers:  []types.Container{{ID: "existing-id", State: "running"}},
+			wantCreated: true,
+			want:        "true",
+		},
+		{
+			name:        "existing containers are recreated when skip backup is removed",
+			db:          config.Database{Engine: "postgres", Version: "13", Port: "5432"},
+			containers:  []types.Container{{ID: "existing-id", State: "running", Labels: map[string]string{containerlabels.DatabaseSkipBackup: "true"}}},
+			wantCreated: true,
+			want:        "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockDockerClient{
+				containers:              tt.containers,
+				images:                  []types.ImageSummary{{ID: "postgres"}},
+				containerCreateResponse: container.ContainerCreateCreatedBody{ID: "database-id"},
+			}
+
+			if _, _, err := StartOrCreate(context.Background(), mock, "network-id", tt.db, &spyOutputer{}); err != nil {
+				t.Fatal(err)
+			}
+
+			if created := len(mock.containerCreateLabels) == 1; created != tt.wantCreated {
+				t.Fatalf("expected the container to be created to be %v, got %v", tt.wantCreated, created)
+			}
+
+			if !tt.wantCreated {
+				return
+			}
+
+			if got := mock.containerCreateLabels[0][containerlabels.DatabaseSkipBackup]; got != tt.want {
+				t.Errorf("expected the skip backup label to be %q, got %q", tt.want, got)
+			}
+
+			// the existing container is replaced
+			if len(tt.containers) > 0 && (!reflect.DeepEqual(mock.containerStopRequests, []string{"existing-id"}) || !reflect.DeepEqual(mock.containerRemoveRequests, []string{"existing-id"})) {
+				t.Errorf("expected the existing container to be stopped and removed, got %v and %v", mock.containerStopRequests, mock.containerRemoveRequests)
+			}
+		})
+	}
+}
+
+func TestStartOrCreatePullsMissingImages(t *testing.T) {
+	// the image exists when listed but was pruned before the container is created
+	mock := &mockDockerClient{
+		images:                  []types.ImageSummary{{ID: "postgres"}},
+		containerCreateErrors:   []error{errdefs.NotFound(errors.New("No such image: postgres:13"))},
+		containerCreateResponse: container.ContainerCreateCreatedBody{ID: "database-id"},
+	}
+
+	id, _, err := StartOrCreate(context.Background(), mock, "network-id", config.Database{Engine: "postgres", Version: "13", Port: "5432"}, &spyOutputer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id != "database-id" {
+		t.Errorf("expected the id to be database-id, got %q", id)
+	}
+
+	if len(mock.imagePullRequests) != 1 {
+		t.Errorf("expected the image to be pulled once, got %d pulls", len(mock.imagePullRequests))
+	}
+
+	if len(mock.containerCreateLabels) != 2 {
+		t.Errorf("expected the create to be retried, got %d creates", len(mock.containerCreateLabels))
+	}
+}
+
+func TestStartOrCreateMongoDB(t *testing.T) {
+	mock := &mockDockerClient{
+		images:                  []types.ImageSummary{{ID: "mongo"}},
+		containerCreateResponse: container.ContainerCreateCreatedBody{ID: "database-id"},
+	}
+
+	_, hostname, err := StartOrCreate(context.Background(), mock, "network-id", config.Database{Engine: "mongodb", Version: "4.4", Port: "27017"}, &spyOutputer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hostname != "mongodb-4.4-27017.database.nitro" {
+		t.Errorf("expected the hostname to be mongodb-4.4-27017.database.nitro, got %q", hostname)
+	}
+
+	if mock.containerCreateConfig.Image != "mongo:4.4" {
+		t.Errorf("expected the image to be mongo:4.4, got %q", mock.containerCreateConfig.Image)
+	}
+
+	if got := mock.containerCreateLabels[0][containerlabels.DatabaseCompatibility]; got != "mongodb" {
+		t.Errorf("expected the compatibility to be mongodb, got %q", got)
+	}
+
+	if _, ok := mock.containerCreateConfig.ExposedPorts["27017/tcp"]; !ok {
+		t.Errorf("expected port 27017 to be exposed, got %v", mock.containerCreateConfig.ExposedPorts)
+	}
+
+	want := []string{"MONGO_INITDB_ROOT_USERNAME=nitro", "MONGO_INITDB_ROOT_PASSWORD=nitro", "MONGO_INITDB_DATABASE=nitro"}
+	if !reflect.DeepEqual(mock.containerCreateConfig.Env, want) {
+		t.Errorf("expected the envs to be %v, got %v", want, mock.containerCreateConfig.Env)
+	}
+}
+
+func TestStartOrCreateLimits(t *testing.T) {
+	existing := []types.Container{{ID: "database-id", State: "running"}}
+
+	tests := []struct {
+		name        string
+		db          config.Database
+		containers  []types.Container
+		current     container.Resources
+		wantUpdates []container.UpdateConfig
+		wantErr     bool
+	}{
+		{
+			name:       "existing containers with the same limits are not updated",
+			db:         config.Database{Engine: "postgres", Version: "13", Port: "5432", CPUs: 1.5, Memory: "1G"},
+			containers: existing,
+			current:    container.Resources{NanoCPUs: 1500000000, Memory: 1 << 30},
+		},
+		{
+			name:       "existing containers with different limits are updated",
+			db:         config.Database{Engine: "postgres", Version: "13", Port: "5432", CPUs: 2, Memory: "2G"},
+			containers: existing,
+			current:    container.Resources{NanoCPUs: 1500000000, Memory: 1 << 30},
+			wantUpdates: []container.UpdateConfig{
+				{Resources: container.Resources{NanoCPUs: 2000000000, Memory: 2 << 30, MemorySwap: 4 << 30}},
+			},
+		},
+		{
+			name:       "existing containers are not updated when the limits are removed",
+			db:         config.Database{Engine: "postgres", Version: "13", Port: "5432"},
+			containers: existing,
+			current:    container.Resources{Memory: 1 << 30},
+		},
+		{
+			name:    "invalid memory returns an error",
+			db:      config.Database{Engine: "postgres", Version: "13", Port: "5432", Memory: "1GB"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockDockerClient{
+				containers:         tt.containers,
+				containerResources: tt.current,
+			}
+
+			_, _, err := StartOrCreate(context.Background(), mock, "network-id", tt.db, &spyOutputer{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("StartOrCreate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(mock.containerUpdateConfigs, tt.wantUpdates) {
+				t.Errorf("expected the updates to be %v, got %v", tt.wantUpdates, mock.containerUpdateConfigs)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package imagepull
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Pull is used to pull an image and wait for the pull to complete.
+func Pull(ctx context.Context, docker client.ImageAPIClient, image, platform string) error {
+	rdr, err := docker.ImagePull(ctx, image, types.ImagePullOptions{All: false, Platform: platform})
+	if err != nil {
+		return fmt.Errorf("unable to pull the image, %w", err)
+	}
+	defer rdr.Close()
+
+	// read the output to pull the image
+	buf := &bytes.Buffer{}
+	if _, err := buf.ReadFrom(rdr); err != nil {
+		return fmt.Errorf("unable to read output from pulling image %s, %w", image, err)
+	}
+
+	return nil
+}
+
+// IsImageNotFound returns true when the error is because the image does not exist
+// on the host, other not found errors (e.g. a missing network) return false.
+func IsImageNotFound(err error) bool {
+	var nf interface{ NotFound() }
+	if !errors.As(err, &nf) {
+		return false
+	}
+
+	return strings.Contains(strings.ToLower(err.Error()), "no such image")
+}
+
+// Create is used to create a container and, if the image was removed from the host
+// (e.g. by docker system prune), pull the image and retry creating the container once.
+func Create(ctx context.Context, docker client.CommonAPIClient, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *v1.Platform, name string) (container.ContainerCreateCreatedBody, error) {
+	resp, err := docker.ContainerCreate(ctx, config, hostConfig, networkingConfig, platform, name)
+	if !IsImageNotFound(err) {
+		return resp, err
+	}
+
+	var plat string
+	if platform != nil {
+		plat = platform.OS + "/" + platform.Architecture
+		if platform.Variant != "" {
+			plat += "/" + platform.Variant
+		}
+	}
+
+	if err := Pull(ctx, docker, config.Image, plat); err != nil {
+		return resp, err
+	}
+
+	return docker.ContainerCreate(ctx, config, hostConfig, networkingConfig, platform, name)
+}
@@ -0,0 +1,53 @@
+package imagepull
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+type mockDockerClient struct {
+	client.CommonAPIClient
+
+	// container related resources
+	containerCreateRequests []string
+	containerCreateResponse container.ContainerCreateCreatedBody
+
+	// containerCreateErrors are returned in order for each create request
+	containerCreateErrors []error
+
+	// image related resources
+	imagePullRequests []string
+
+	// mockError allows us to override any func to return a method, we do not
+	// set the error by default.
+	mockError error
+}
+
+func (c *mockDockerClient) ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *v1.Platform, containerName string) (container.ContainerCreateCreatedBody, error) {
+	c.containerCreateRequests = append(c.containerCreateRequests, containerName)
+
+	if len(c.containerCreateErrors) > 0 {
+		err := c.containerCreateErrors[0]
+		c.containerCreateErrors = c.containerCreateErrors[1:]
+
+		if err != nil {
+			return container.ContainerCreateCreatedBody{}, err
+		}
+	}
+
+	return c.containerCreateResponse, nil
+}
+
+func (c *mockDockerClient) ImagePull(ctx context.Context, ref string, options types.ImagePullOptions) (io.ReadCloser, error) {
+	c.imagePullRequests = append(c.imagePullRequests, ref+"@"+options.Platform)
+
+	return ioutil.NopCloser(&bytes.Buffer{}), c.mockError
+}
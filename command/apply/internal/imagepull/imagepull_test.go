@@ -0,0 +1,144 @@
+package imagepull
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/errdefs"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestIsImageNotFound(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "nil errors are not image not found errors",
+		},
+		{
+			name: "missing images are image not found errors",
+			err:  errdefs.NotFound(errors.New("No such image: craftcms/nginx:7.4-dev")),
+			want: true,
+		},
+		{
+			name: "wrapped missing image errors are image not found errors",
+			err:  fmt.Errorf("unable to create, %w", errdefs.NotFound(errors.New("No such image: mysql:8.0"))),
+			want: true,
+		},
+		{
+			name: "missing networks are not image not found errors",
+			err:  errdefs.NotFound(errors.New("network nitro-network not found")),
+		},
+		{
+			name: "other errors are not image not found errors",
+			err:  errors.New("No such image: mysql:8.0"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsImageNotFound(tt.err); got != tt.want {
+				t.Errorf("IsImageNotFound() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	notFound := errdefs.NotFound(errors.New("No such image: mysql:8.0"))
+
+	tests := []struct {
+		name         string
+		errors       []error
+		platform     *v1.Platform
+		wantID       string
+		wantPulls    []string
+		wantCreates  int
+		wantErr      bool
+		wantNotFound bool
+	}{
+		{
+			name:        "existing images are not pulled",
+			wantID:      "abc",
+			wantCreates: 1,
+		},
+		{
+			name:        "missing images are pulled and the create is retried",
+			errors:      []error{notFound},
+			wantID:      "abc",
+			wantPulls:   []string{"mysql:8.0@"},
+			wantCreates: 2,
+		},
+		{
+			name:        "missing images are pulled for the platform",
+			errors:      []error{notFound},
+			platform:    &v1.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"},
+			wantID:      "abc",
+			wantPulls:   []string{"mysql:8.0@linux/arm64/v8"},
+			wantCreates: 2,
+		},
+		{
+			name:         "the create is only retried once",
+			errors:       []error{notFound, notFound},
+			wantPulls:    []string{"mysql:8.0@"},
+			wantCreates:  2,
+			wantErr:      true,
+			wantNotFound: true,
+		},
+		{
+			name:        "other errors are not retried",
+			errors:      []error{errors.New("port is already allocated")},
+			wantCreates: 1,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docker := &mockDockerClient{
+				containerCreateErrors:   tt.errors,
+				containerCreateResponse: container.ContainerCreateCreatedBody{ID: "abc"},
+			}
+
+			resp, err := Create(context.Background(), docker, &container.Config{Image: "mysql:8.0"}, nil, nil, tt.platform, "mysql-8.0-3306.database.nitro")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Create() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if IsImageNotFound(err) != tt.wantNotFound {
+				t.Errorf("expected the image not found error to be %v, got %v", tt.wantNotFound, err)
+			}
+
+			if resp.ID != tt.wantID {
+				t.Errorf("expected the id to be %q, got %q", tt.wantID, resp.ID)
+			}
+
+			if !reflect.DeepEqual(docker.imagePullRequests, tt.wantPulls) {
+				t.Errorf("expected the pulls to be %v, got %v", tt.wantPulls, docker.imagePullRequests)
+			}
+
+			if len(docker.containerCreateRequests) != tt.wantCreates {
+				t.Errorf("expected %d create requests, got %d", tt.wantCreates, len(docker.containerCreateRequests))
+			}
+		})
+	}
+}
+
+func TestCreatePullError(t *testing.T) {
+	docker := &mockDockerClient{
+		containerCreateErrors: []error{errdefs.NotFound(errors.New("No such image: mysql:8.0"))},
+		mockError:             errors.New("pull access denied"),
+	}
+
+	if _, err := Create(context.Background(), docker, &container.Config{Image: "mysql:8.0"}, nil, nil, nil, "mysql"); err == nil {
+		t.Fatal("expected an error when the pull fails")
+	}
+
+	if len(docker.containerCreateRequests) != 1 {
+		t.Errorf("expected the create not to be retried when the pull fails, got %d requests", len(docker.containerCreateRequests))
+	}
+}
@@ -9,7 +9,9 @@ import (
 	"strings"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/mount"
 
+	"github.com/craftcms/nitro/command/apply/internal/nginx"
 	"github.com/craftcms/nitro/pkg/config"
 	"github.com/craftcms/nitro/pkg/containerlabels"
 )
@@ -19,6 +21,7 @@ var (
 	ErrMisMatchedLabel  = fmt.Errorf("container label does not match")
 	ErrEnvFileNotFound  = fmt.Errorf("unable to find the containers env file")
 	ErrMisMatchedEnvVar = fmt.Errorf("container environment variables do not match")
+	ErrMisMatchedConfig = fmt.Errorf("container config does not match")
 )
 
 // Container checks if a custom container is up to date with the configuration
@@ -33,6 +36,11 @@ func Container(home string, container config.Container, details types.ContainerJ
 		return ErrMisMatchedLabel
 	}
 
+	// check the env, ports, and volumes have not changed since the container was created
+	if Drifted(details.Config.Labels, container.Hash()) {
+		return ErrMisMatchedConfig
+	}
+
 	if container.EnvFile != "" {
 		customEnvs := make(map[string]string)
 
@@ -63,15 +71,29 @@ func Container(home string, container config.Container, details types.ContainerJ
 		}
 	}
 
-	// TODO(jasonmccallister) check the port mappings
-	// TODO(jasonmccallister) check the volumes
-
 	return nil
 }
 
+// Drifted compares the config hash label on a container to the hash of
+// the config. Containers created before the label was added have not
+// drifted, so the other checks are used for them.
+func Drifted(labels map[string]string, hash string) bool {
+	existing, ok := labels[containerlabels.ConfigHash]
+	if !ok {
+		return false
+	}
+
+	return existing != hash
+}
+
 // Site takes the home directory, site, and a container to determine if they
 // match whats expected.
 func Site(home string, site config.Site, container types.ContainerJSON, blackfire config.Blackfire) bool {
+	// check if the site config has changed since the container was created
+	if Drifted(container.Config.Labels, site.Hash()) {
+		return false
+	}
+
 	// check if the image does not match - this uses the image name, not ref
 	if fmt.Sprintf("docker.io/craftcms/nginx:%s-dev", site.Version) != container.Config.Image {
 		return false
@@ -98,9 +120,69 @@ func Site(home string, site config.Site, container types.ContainerJSON, blackfir
 		return false
 	}
 
-	// check the path
-	if len(container.Mounts) > 0 {
-		if path != container.Mounts[0].Source {
+	// check the path and collect the volumes for excluded directories
+	excluded := make(map[string]bool)
+	for _, m := range container.Mounts {
+		if m.Type == mount.TypeVolume {
+			if strings.HasPrefix(m.Destination, "/app/") {
+				excluded[strings.TrimPrefix(m.Destination, "/app/")] = true
+			}
+
+			continue
+		}
+
+		// the xdebug directory is only mounted for profiling and tracing
+		if m.Destination == config.XdebugOutputDir {
+			if site.XdebugProfile == "" {
+				return false
+			}
+
+			continue
+		}
+
+		// the ssh agent socket changes each time the agent starts, so it is checked when applying
+		if m.Destination == config.SSHAgentSocket {
+			if !site.SSHAgent {
+				return false
+			}
+
+			continue
+		}
+
+		if path != m.Source {
+			return false
+		}
+	}
+
+	// check the excluded directories have a volume
+	dirs, err := site.GetExcludedDirs(home)
+	if err != nil {
+		return false
+	}
+
+	if len(dirs) != len(excluded) {
+		return false
+	}
+
+	for _, d := range dirs {
+		if !excluded[d] {
+			return false
+		}
+	}
+
+	// check the custom nginx directives have not changed
+	directives, err := site.GetNginx(home)
+	if err != nil {
+		return false
+	}
+
+	switch directives {
+	case "":
+		if container.Config.Labels[containerlabels.Nginx] != "" {
+			return false
+		}
+	default:
+		if container.Config.Labels[containerlabels.Nginx] != nginx.Hash(directives) {
 			return false
 		}
 	}
@@ -122,12 +204,19 @@ func Site(home string, site config.Site, container types.ContainerJSON, blackfir
 }
 
 func checkEnvs(site config.Site, blackfire config.Blackfire, envs []string) bool {
+	timezone := false
+
 	// check the environment variables
 	for _, e := range envs {
 		sp := strings.Split(e, "=")
 		env := sp[0]
 		val := sp[1]
 
+		// the sites variables replace the defaults and are checked with the site hash
+		if _, ok := site.Env[env]; ok {
+			continue
+		}
+
 		// TODO(jasonmccallister) consider adding checks for if blackfire is
 		// enabled for this site
 		if env == "BLACKFIRE_SERVER_ID" && blackfire.ServerID != val {
@@ -179,17 +268,31 @@ func checkEnvs(site config.Site, blackfire config.Blackfire, envs []string) bool
 				if (site.PHP.OpcacheValidateTimestamps && val == config.DefaultEnvs[env]) || (!site.PHP.OpcacheValidateTimestamps && val != config.DefaultEnvs[env]) {
 					return false
 				}
+			case "TZ":
+				timezone = true
+
+				if (site.Timezone == "" && val != config.DefaultEnvs[env]) || (site.Timezone != "" && val != site.Timezone) {
+					return false
+				}
 			case "XDEBUG_MODE":
-				if site.Xdebug && val == config.DefaultEnvs[env] {
+				// profiling and tracing also enable xdebug
+				xdebug := site.Xdebug || site.XdebugProfile != ""
+
+				if xdebug && val == config.DefaultEnvs[env] {
 					return false
 				}
 
-				if !site.Xdebug && val != config.DefaultEnvs[env] {
+				if !xdebug && val != config.DefaultEnvs[env] {
 					return false
 				}
 			}
 		}
 	}
 
+	// containers created before a timezone was set will not have the env
+	if site.Timezone != "" && !timezone {
+		return false
+	}
+
 	return true
 }
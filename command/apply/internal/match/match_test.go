@@ -1,14 +1,17 @@
 package match
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/craftcms/nitro/command/apply/internal/nginx"
 	"github.com/craftcms/nitro/pkg/config"
 	"github.com/craftcms/nitro/pkg/containerlabels"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
 )
 
 func Test_checkEnvs(t *testing.T) {
@@ -214,6 +217,42 @@ func Test_checkEnvs(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "mismatched timezone returns false",
+			args: args{
+				site: config.Site{
+					Timezone: "America/Chicago",
+				},
+				envs: []string{
+					"TZ=UTC",
+				},
+			},
+			want: false,
+		},
+		{
+			name: "missing timezone returns false",
+			args: args{
+				site: config.Site{
+					Timezone: "America/Chicago",
+				},
+				envs: []string{
+					"PHP_DISPLAY_ERRORS=on",
+				},
+			},
+			want: false,
+		},
+		{
+			name: "matching timezone returns true",
+			args: args{
+				site: config.Site{
+					Timezone: "America/Chicago",
+				},
+				envs: []string{
+					"TZ=America/Chicago",
+				},
+			},
+			want: true,
+		},
 		{
 			name: "display_errors returns false",
 			args: args{
@@ -228,6 +267,18 @@ func Test_checkEnvs(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "site variables that replace the php variables return true",
+			args: args{
+				site: config.Site{
+					Env: map[string]string{"PHP_DISPLAY_ERRORS": "off"},
+				},
+				envs: []string{
+					"PHP_DISPLAY_ERRORS=off",
+				},
+			},
+			want: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -244,6 +295,22 @@ func TestSite(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	// hashed is a site with a config hash label on the container
+	hashed := config.Site{
+		Hostname: "newname",
+		Path:     "testdata/example-site",
+		Version:  "7.4",
+		Webroot:  "web",
+	}
+
+	// directives is a site with custom nginx directives
+	directives := hashed
+	directives.Nginx = "add_header X-Frame-Options SAMEORIGIN;"
+
+	// agent is a site that forwards the ssh agent
+	agent := hashed
+	agent.SSHAgent = true
+
 	type args struct {
 		home      string
 		site      config.Site
@@ -333,6 +400,258 @@ func TestSite(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "excluded directories without a volume return false",
+			args: args{
+				home: "testdata/excluded-site",
+				site: config.Site{
+					Hostname: "newname",
+					Path:     "testdata/excluded-site",
+					Version:  "7.4",
+					Webroot:  "web",
+					Exclude:  []string{"vendor"},
+				},
+				container: types.ContainerJSON{
+					Config: &container.Config{
+						Image: "docker.io/craftcms/nginx:7.4-dev",
+						Labels: map[string]string{
+							containerlabels.Host:    "newname",
+							containerlabels.Webroot: "web",
+						},
+					},
+					Mounts: []types.MountPoint{
+						{
+							Type:   mount.TypeBind,
+							Source: filepath.Join(wd, "testdata", "excluded-site"),
+						},
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "volumes for directories that are no longer excluded return false",
+			args: args{
+				home: "testdata/excluded-site",
+				site: config.Site{
+					Hostname: "newname",
+					Path:     "testdata/excluded-site",
+					Version:  "7.4",
+					Webroot:  "web",
+				},
+				container: types.ContainerJSON{
+					Config: &container.Config{
+						Image: "docker.io/craftcms/nginx:7.4-dev",
+						Labels: map[string]string{
+							containerlabels.Host:    "newname",
+							containerlabels.Webroot: "web",
+						},
+					},
+					Mounts: []types.MountPoint{
+						{
+							Type:   mount.TypeBind,
+							Source: filepath.Join(wd, "testdata", "excluded-site"),
+						},
+						{
+							Type:        mount.TypeVolume,
+							Name:        "newname_vendor",
+							Destination: "/app/vendor",
+						},
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "excluded directories with a volume return true",
+			args: args{
+				home: "testdata/excluded-site",
+				site: config.Site{
+					Hostname: "newname",
+					Path:     "testdata/excluded-site",
+					Version:  "7.4",
+					Webroot:  "web",
+					Exclude:  []string{"vendor"},
+				},
+				container: types.ContainerJSON{
+					Config: &container.Config{
+						Image: "docker.io/craftcms/nginx:7.4-dev",
+						Labels: map[string]string{
+							containerlabels.Host:    "newname",
+							containerlabels.Webroot: "web",
+						},
+					},
+					Mounts: []types.MountPoint{
+						{
+							Type:   mount.TypeBind,
+							Source: filepath.Join(wd, "testdata", "excluded-site"),
+						},
+						{
+							Type:        mount.TypeVolume,
+							Name:        "newname_vendor",
+							Destination: "/app/vendor",
+						},
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "matching config hashes return true",
+			args: args{
+				home: "testdata/example-site",
+				site: hashed,
+				container: types.ContainerJSON{
+					Config: &container.Config{
+						Image: "docker.io/craftcms/nginx:7.4-dev",
+						Labels: map[string]string{
+							containerlabels.Host:       "newname",
+							containerlabels.Webroot:    "web",
+							containerlabels.ConfigHash: hashed.Hash(),
+						},
+					},
+					Mounts: []types.MountPoint{
+						{
+							Type:   mount.TypeBind,
+							Source: filepath.Join(wd, "testdata", "example-site"),
+						},
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "sites with the ssh agent socket mounted return true",
+			args: args{
+				home: "testdata/example-site",
+				site: agent,
+				container: types.ContainerJSON{
+					Config: &container.Config{
+						Image: "docker.io/craftcms/nginx:7.4-dev",
+						Labels: map[string]string{
+							containerlabels.Host:       "newname",
+							containerlabels.Webroot:    "web",
+							containerlabels.ConfigHash: agent.Hash(),
+						},
+					},
+					Mounts: []types.MountPoint{
+						{
+							Type:   mount.TypeBind,
+							Source: filepath.Join(wd, "testdata", "example-site"),
+						},
+						{
+							Type:        mount.TypeBind,
+							Source:      "/tmp/ssh-abc123/agent.1234",
+							Destination: config.SSHAgentSocket,
+						},
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "sites without the ssh agent and the socket mounted return false",
+			args: args{
+				home: "testdata/example-site",
+				site: hashed,
+				container: types.ContainerJSON{
+					Config: &container.Config{
+						Image: "docker.io/craftcms/nginx:7.4-dev",
+						Labels: map[string]string{
+							containerlabels.Host:       "newname",
+							containerlabels.Webroot:    "web",
+							containerlabels.ConfigHash: hashed.Hash(),
+						},
+					},
+					Mounts: []types.MountPoint{
+						{
+							Type:   mount.TypeBind,
+							Source: filepath.Join(wd, "testdata", "example-site"),
+						},
+						{
+							Type:        mount.TypeBind,
+							Source:      "/tmp/ssh-abc123/agent.1234",
+							Destination: config.SSHAgentSocket,
+						},
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "matching nginx directives return true",
+			args: args{
+				home: "testdata/example-site",
+				site: directives,
+				container: types.ContainerJSON{
+					Config: &container.Config{
+						Image: "docker.io/craftcms/nginx:7.4-dev",
+						Labels: map[string]string{
+							containerlabels.Host:       "newname",
+							containerlabels.Webroot:    "web",
+							containerlabels.ConfigHash: directives.Hash(),
+							containerlabels.Nginx:      nginx.Hash(directives.Nginx),
+						},
+					},
+					Mounts: []types.MountPoint{
+						{
+							Type:   mount.TypeBind,
+							Source: filepath.Join(wd, "testdata", "example-site"),
+						},
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "changed nginx directives return false",
+			args: args{
+				home: "testdata/example-site",
+				site: directives,
+				container: types.ContainerJSON{
+					Config: &container.Config{
+						Image: "docker.io/craftcms/nginx:7.4-dev",
+						Labels: map[string]string{
+							containerlabels.Host:       "newname",
+							containerlabels.Webroot:    "web",
+							containerlabels.ConfigHash: directives.Hash(),
+							containerlabels.Nginx:      nginx.Hash("return 404;"),
+						},
+					},
+					Mounts: []types.MountPoint{
+						{
+							Type:   mount.TypeBind,
+							Source: filepath.Join(wd, "testdata", "example-site"),
+						},
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "drifted config hashes return false",
+			args: args{
+				home: "testdata/example-site",
+				site: hashed,
+				container: types.ContainerJSON{
+					Config: &container.Config{
+						Image: "docker.io/craftcms/nginx:7.4-dev",
+						Labels: map[string]string{
+							containerlabels.Host:       "newname",
+							containerlabels.Webroot:    "web",
+							containerlabels.ConfigHash: "0123456789abcdef",
+						},
+					},
+					Mounts: []types.MountPoint{
+						{
+							Type:   mount.TypeBind,
+							Source: filepath.Join(wd, "testdata", "example-site"),
+						},
+					},
+				},
+			},
+			want: false,
+		},
 		{
 			name: "hostname updates return false using labels",
 			args: args{
@@ -392,3 +711,86 @@ func TestSite(t *testing.T) {
 		})
 	}
 }
+
+func TestDrifted(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels map[string]string
+		hash   string
+		want   bool
+	}{
+		{
+			name:   "matching hashes have not drifted",
+			labels: map[string]string{containerlabels.ConfigHash: "abc"},
+			hash:   "abc",
+			want:   false,
+		},
+		{
+			name:   "different hashes have drifted",
+			labels: map[string]string{containerlabels.ConfigHash: "abc"},
+			hash:   "def",
+			want:   true,
+		},
+		{
+			name:   "containers without a hash have not drifted",
+			labels: map[string]string{containerlabels.Nitro: "true"},
+			hash:   "def",
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Drifted(tt.labels, tt.hash); got != tt.want {
+				t.Errorf("Drifted() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContainer(t *testing.T) {
+	redis := config.Container{Name: "redis", Image: "redis", Tag: "6", Ports: []string{"6380:6379"}, Env: map[string]string{"REDIS_ARGS": "--save 60 1"}}
+
+	changed := redis
+	changed.Env = map[string]string{"REDIS_ARGS": "--save 30 1"}
+
+	tests := []struct {
+		name      string
+		container config.Container
+		details   types.ContainerJSON
+		want      error
+	}{
+		{
+			name:      "matching containers are up to date",
+			container: redis,
+			details: types.ContainerJSON{Config: &container.Config{
+				Image:  "redis:6",
+				Labels: containerlabels.ForCustomContainer(redis),
+			}},
+		},
+		{
+			name:      "containers with a different image do not match",
+			container: redis,
+			details: types.ContainerJSON{Config: &container.Config{
+				Image:  "redis:5",
+				Labels: containerlabels.ForCustomContainer(redis),
+			}},
+			want: ErrMisMatchedImage,
+		},
+		{
+			name:      "containers with changed env do not match",
+			container: changed,
+			details: types.ContainerJSON{Config: &container.Config{
+				Image:  "redis:6",
+				Labels: containerlabels.ForCustomContainer(redis),
+			}},
+			want: ErrMisMatchedConfig,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Container("testdata", tt.container, tt.details); !errors.Is(err, tt.want) {
+				t.Errorf("Container() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
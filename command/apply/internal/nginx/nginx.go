@@ -1,25 +1,33 @@
 package nginx
 
-import "fmt"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/craftcms/nitro/pkg/config"
+)
 
 var conf = `server {
     listen      8080 default_server;
     listen      [::]:8080 default_server;
     server_name _;
     set         $base /app;
-    root        $base/%s;
+    root        $base/%[1]s;
 
-    proxy_send_timeout 240s;
-    proxy_read_timeout 240s;
-    fastcgi_send_timeout 240s;
-    fastcgi_read_timeout 240s;
+    proxy_send_timeout %[3]s;
+    proxy_read_timeout %[4]s;
+    fastcgi_send_timeout %[3]s;
+    fastcgi_read_timeout %[4]s;
 
     # security
     include     craftcms/security.conf;
 
     # include custom conf files
     include     /app/*nitro.conf;
-
+%[2]s
     # index.php
     index       index.php;
 
@@ -33,7 +41,7 @@ var conf = `server {
 
     # handle .php
     location ~ \.php$ {
-        include craftcms/php_fastcgi.conf;
+        include craftcms/php_fastcgi.conf;%[5]s
     }
 
     # Allow fpm ping and status from localhost
@@ -47,12 +55,69 @@ var conf = `server {
     }
 }`
 
-// Generate takes a root directory generates a nginx configuration file
-func Generate(root string) string {
+// DefaultTimeout is the nginx read and send timeout when a site does not set one
+const DefaultTimeout = "240s"
+
+// Generate takes a root directory, the sites directives, and the sites limits and
+// generates a nginx configuration file. The directives are added to the server
+// block before the default locations.
+func Generate(root, directives string, limits *config.Limits) string {
 	// if the root was not provided, default to web
 	if root == "" {
-		return fmt.Sprintf(conf, "web")
+		root = "web"
+	}
+
+	send, read := DefaultTimeout, DefaultTimeout
+	var maxBodySize string
+	if limits != nil {
+		send = timeout(limits.SendTimeout)
+		read = timeout(limits.ReadTimeout)
+
+		// the php location is where uploads are handled, setting the size there
+		// overrides the size in the images config without a duplicate directive
+		if limits.MaxBodySize != "" {
+			maxBodySize = fmt.Sprintf("\n        client_max_body_size %s;", limits.MaxBodySize)
+		}
+	}
+
+	return fmt.Sprintf(conf, root, block(directives), send, read, maxBodySize)
+}
+
+// timeout converts the duration to seconds for nginx (e.g. 10m to 600s)
+func timeout(duration string) string {
+	d, err := time.ParseDuration(duration)
+	if err != nil {
+		return DefaultTimeout
+	}
+
+	return fmt.Sprintf("%ds", int(d.Seconds()))
+}
+
+// Hash returns a short sha256 of the directives, it is used to label the
+// container so changes to a directives file are detected.
+func Hash(directives string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(directives)))
+
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// block indents the directives for the server block
+func block(directives string) string {
+	directives = strings.TrimSpace(directives)
+	if directives == "" {
+		return ""
+	}
+
+	lines := []string{"", "    # site directives"}
+	for _, l := range strings.Split(directives, "\n") {
+		l = strings.TrimRight(l, " \t\r")
+		if l == "" {
+			lines = append(lines, "")
+			continue
+		}
+
+		lines = append(lines, "    "+l)
 	}
 
-	return fmt.Sprintf(conf, root)
+	return strings.Join(lines, "\n") + "\n"
 }
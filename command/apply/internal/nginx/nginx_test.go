@@ -1,10 +1,27 @@
 package nginx
 
-import "testing"
+import (
+	"strings"
+	"testing"
+
+	"github.com/craftcms/nitro/pkg/config"
+)
+
+func TestHash(t *testing.T) {
+	if Hash("return 404;") != Hash("  return 404;\n") {
+		t.Errorf("expected surrounding whitespace to be ignored")
+	}
+
+	if Hash("return 404;") == Hash("return 410;") {
+		t.Errorf("expected different directives to have different hashes")
+	}
+}
 
 func TestGenerate(t *testing.T) {
 	type args struct {
-		root string
+		root       string
+		directives string
+		limits     *config.Limits
 	}
 	tests := []struct {
 		name string
@@ -18,10 +35,37 @@ func TestGenerate(t *testing.T) {
 			},
 			want: defaultConf,
 		},
+		{
+			name: "adds the directives to the server block",
+			args: args{
+				directives: "add_header X-Frame-Options SAMEORIGIN;\n\nlocation /old {\n    return 301 /new;\n}\n",
+			},
+			want: strings.Replace(defaultConf, "include     /app/*nitro.conf;\n", "include     /app/*nitro.conf;\n\n    # site directives\n    add_header X-Frame-Options SAMEORIGIN;\n\n    location /old {\n        return 301 /new;\n    }\n", 1),
+		},
+		{
+			name: "sets the limits",
+			args: args{
+				limits: &config.Limits{MaxBodySize: "1G", ReadTimeout: "10m", SendTimeout: "90s"},
+			},
+			want: strings.NewReplacer(
+				"proxy_send_timeout 240s;", "proxy_send_timeout 90s;",
+				"proxy_read_timeout 240s;", "proxy_read_timeout 600s;",
+				"fastcgi_send_timeout 240s;", "fastcgi_send_timeout 90s;",
+				"fastcgi_read_timeout 240s;", "fastcgi_read_timeout 600s;",
+				"include craftcms/php_fastcgi.conf;\n", "include craftcms/php_fastcgi.conf;\n        client_max_body_size 1G;\n",
+			).Replace(defaultConf),
+		},
+		{
+			name: "empty limits use the defaults",
+			args: args{
+				limits: &config.Limits{},
+			},
+			want: defaultConf,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := Generate(tt.args.root); got != tt.want {
+			if got := Generate(tt.args.root, tt.args.directives, tt.args.limits); got != tt.want {
 				t.Errorf("Generate() = %v, want %v", got, tt.want)
 			}
 		})
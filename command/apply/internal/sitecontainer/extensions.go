@@ -0,0 +1,126 @@
+package sitecontainer
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/craftcms/nitro/pkg/config"
+)
+
+var (
+	// ErrUnsupportedExtension is returned when a site extension is not included in the site
+	// image for the PHP version and cannot be installed
+	ErrUnsupportedExtension = errors.New("the PHP extensions are not included in the site image and cannot be installed")
+)
+
+// defaultExtensions are included in all of the site images
+var defaultExtensions = []string{
+	"bcmath",
+	"curl",
+	"gd",
+	"iconv",
+	"intl",
+	"json",
+	"mbstring",
+	"mysqli",
+	"opcache",
+	"pdo_mysql",
+	"pdo_pgsql",
+	"pgsql",
+	"redis",
+	"soap",
+	"xdebug",
+	"xml",
+	"zip",
+}
+
+// imageExtensions are the PHP versions the site image is published for and the extensions
+// that are compiled into them. There is no imagick release in the PHP 8 images, so it is
+// installed from pecl instead.
+var imageExtensions = []struct {
+	versions   []string
+	extensions []string
+}{
+	{
+		versions:   []string{"7.0", "7.1", "7.2", "7.3", "7.4"},
+		extensions: append([]string{"imagick"}, defaultExtensions...),
+	},
+	{
+		versions:   []string{"8.0", "8.1"},
+		extensions: defaultExtensions,
+	},
+}
+
+// peclExtensions are installed with pecl when they are not included in the site image
+var peclExtensions = []string{"imagick"}
+
+// CheckExtensions returns an error when a site extension is not included in the site image
+// for the PHP version and cannot be installed with docker-php-ext-install or pecl.
+func CheckExtensions(version string, extensions []string) error {
+	var unsupported []string
+	for _, e := range missingExtensions(version, extensions) {
+		if !contains(config.PHPExtensions, e) && !contains(peclExtensions, e) {
+			unsupported = append(unsupported, e)
+		}
+	}
+
+	if len(unsupported) == 0 {
+		return nil
+	}
+
+	sort.Strings(unsupported)
+
+	return fmt.Errorf("%w, PHP %s does not support %s", ErrUnsupportedExtension, version, strings.Join(unsupported, ", "))
+}
+
+// installCommands returns the commands to install the site extensions that are not included
+// in the site image for the PHP version.
+func installCommands(version string, extensions []string) []command {
+	var commands []command
+	for _, e := range missingExtensions(version, extensions) {
+		name := "installing-" + e + "-extension"
+
+		if contains(peclExtensions, e) {
+			commands = append(commands, command{Name: name, Commands: []string{"sh", "-c", "pecl install " + e + " && docker-php-ext-enable " + e}})
+			continue
+		}
+
+		commands = append(commands, command{Name: name, Commands: []string{"docker-php-ext-install", e}})
+	}
+
+	return commands
+}
+
+// missingExtensions returns the extensions that are not included in the site image for the
+// PHP version. Versions that are not in imageExtensions do not include any extensions.
+func missingExtensions(version string, extensions []string) []string {
+	var included []string
+	for _, i := range imageExtensions {
+		if contains(i.versions, version) {
+			included = i.extensions
+			break
+		}
+	}
+
+	var missing []string
+	for _, e := range extensions {
+		e = strings.ToLower(strings.TrimSpace(e))
+		if !contains(included, e) {
+			missing = append(missing, e)
+		}
+	}
+
+	return missing
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+
+	return false
+}
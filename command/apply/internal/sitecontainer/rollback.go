@@ -0,0 +1,78 @@
+package sitecontainer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+)
+
+// RollbackSuffix is added to the name of a replaced site container until the apply completes
+const RollbackSuffix = "-rollback"
+
+// Rollback keeps the site containers that are replaced during an apply, so the previous
+// containers can be restored if the apply fails. Commit removes the replaced containers
+// once the apply succeeds.
+type Rollback struct {
+	created  []string
+	replaced []replaced
+}
+
+type replaced struct {
+	id   string
+	name string
+}
+
+// Len returns the number of site containers created or replaced.
+func (r *Rollback) Len() int {
+	return len(r.created) + len(r.replaced)
+}
+
+// retire keeps the stopped container under a different name so a new container can be
+// created with the sites hostname.
+func (r *Rollback) retire(ctx context.Context, docker client.ContainerAPIClient, id, name string) error {
+	if err := docker.ContainerRename(ctx, id, name+RollbackSuffix); err != nil {
+		return fmt.Errorf("unable to keep the container %s for a rollback, %w", name, err)
+	}
+
+	r.replaced = append(r.replaced, replaced{id: id, name: name})
+
+	return nil
+}
+
+// Restore removes the containers created during the apply and renames and starts
+// the containers they replaced.
+func (r *Rollback) Restore(ctx context.Context, docker client.ContainerAPIClient) error {
+	for i := len(r.created) - 1; i >= 0; i-- {
+		// the container may not exist if the create failed
+		_ = docker.ContainerRemove(ctx, r.created[i], types.ContainerRemoveOptions{Force: true})
+	}
+
+	for _, c := range r.replaced {
+		if err := docker.ContainerRename(ctx, c.id, c.name); err != nil {
+			return fmt.Errorf("unable to restore the container %s, %w", c.name, err)
+		}
+
+		if err := docker.ContainerStart(ctx, c.id, types.ContainerStartOptions{}); err != nil {
+			return fmt.Errorf("unable to start the container %s, %w", c.name, err)
+		}
+	}
+
+	r.created, r.replaced = nil, nil
+
+	return nil
+}
+
+// Commit removes the replaced containers.
+func (r *Rollback) Commit(ctx context.Context, docker client.ContainerAPIClient) error {
+	for _, c := range r.replaced {
+		if err := docker.ContainerRemove(ctx, c.id, types.ContainerRemoveOptions{}); err != nil {
+			return fmt.Errorf("unable to remove the replaced container %s, %w", c.name, err)
+		}
+	}
+
+	r.created, r.replaced = nil, nil
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package sitecontainer
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+)
+
+type rollbackMock struct {
+	client.ContainerAPIClient
+
+	calls []string
+}
+
+func (m *rollbackMock) ContainerRename(ctx context.Context, container, newContainerName string) error {
+	m.calls = append(m.calls, "rename "+container+" "+newContainerName)
+	return nil
+}
+
+func (m *rollbackMock) ContainerRemove(ctx context.Context, container string, options types.ContainerRemoveOptions) error {
+	m.calls = append(m.calls, "remove "+container)
+	return nil
+}
+
+func (m *rollbackMock) ContainerStart(ctx context.Context, container string, options types.ContainerStartOptions) error {
+	m.calls = append(m.calls, "start "+container)
+	return nil
+}
+
+func TestRollback(t *testing.T) {
+	tests := []struct {
+		name    string
+		restore bool
+		want    []string
+	}{
+		{
+			name:    "restore removes the new containers and restores the replaced containers",
+			restore: true,
+			want: []string{
+				"rename old-id craft-dev.nitro-rollback",
+				"remove craft-dev.nitro",
+				"remove new.nitro",
+				"rename old-id craft-dev.nitro",
+				"start old-id",
+			},
+		},
+		{
+			name: "commit removes the replaced containers",
+			want: []string{
+				"rename old-id craft-dev.nitro-rollback",
+				"remove old-id",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mock := &rollbackMock{}
+
+			r := &Rollback{created: []string{"new.nitro"}}
+			if err := r.retire(ctx, mock, "old-id", "craft-dev.nitro"); err != nil {
+				t.Fatal(err)
+			}
+			r.created = append(r.created, "craft-dev.nitro")
+
+			if r.Len() != 3 {
+				t.Errorf("expected the rollback to have 3 containers, got %d", r.Len())
+			}
+
+			var err error
+			if tt.restore {
+				err = r.Restore(ctx, mock)
+			} else {
+				err = r.Commit(ctx, mock)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(mock.calls, tt.want) {
+				t.Errorf("expected the calls to be %v, got %v", tt.want, mock.calls)
+			}
+
+			if r.Len() != 0 {
+				t.Errorf("expected the rollback to be empty, got %d", r.Len())
+			}
+		})
+	}
+}
@@ -3,19 +3,30 @@ package sitecontainer
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 
+	"github.com/craftcms/nitro/command/apply/internal/imagepull"
 	"github.com/craftcms/nitro/command/apply/internal/match"
 	"github.com/craftcms/nitro/command/apply/internal/nginx"
 	"github.com/craftcms/nitro/pkg/config"
 	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/svc/elasticsearch"
+	"github.com/craftcms/nitro/pkg/svc/meilisearch"
+	"github.com/craftcms/nitro/pkg/svc/rabbitmq"
+	"github.com/craftcms/nitro/pkg/svc/redis"
+	"github.com/craftcms/nitro/pkg/volumename"
 	"github.com/craftcms/nitro/pkg/wsl"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/archive"
@@ -33,59 +44,111 @@ var (
 )
 
 // StartOrCreate is responsible for finding a sites existing container or creating a new one based on the values from the configuration file.
-func StartOrCreate(ctx context.Context, docker client.CommonAPIClient, home, networkID string, site config.Site, cfg *config.Config) (string, error) {
+// When showEnvDiff is true, the changes to the environment variables are printed before an out of date container is recreated. It returns
+// true when the container was created or started. When rollback is not nil, replaced containers are kept so they can be restored.
+func StartOrCreate(ctx context.Context, docker client.CommonAPIClient, home, networkID string, site config.Site, cfg *config.Config, showEnvDiff bool, rollback *Rollback) (string, bool, error) {
+	// use the global timezone if the site does not set one
+	if site.Timezone == "" {
+		site.Timezone = cfg.Timezone
+	}
+
+	// make sure the extensions are included in the image or can be installed
+	if err := CheckExtensions(site.Version, site.Extensions); err != nil {
+		return "", false, fmt.Errorf("unable to create %s, %w", site.Hostname, err)
+	}
+
+	// get the ssh agent socket to forward to the container
+	agent, err := agentMount(site)
+	if err != nil {
+		return "", false, err
+	}
+
 	// set filters for the container
 	filter := filters.NewArgs()
 	filter.Add("label", containerlabels.Host+"="+site.Hostname)
 
+	// hash the effective config to check if the container changed
+	effective, err := effectiveHash(home, site, cfg)
+	if err != nil {
+		return "", false, err
+	}
+
 	// look for a container for the site
 	containers, err := docker.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: filter})
 	if err != nil {
-		return "", fmt.Errorf("error getting a list of containers")
+		return "", false, fmt.Errorf("error getting a list of containers")
 	}
 
 	// if there are no containers we need to create one
 	if len(containers) == 0 {
-		return create(ctx, docker, home, networkID, site, cfg)
+		if rollback != nil {
+			rollback.created = append(rollback.created, site.Hostname)
+		}
+
+		id, err := create(ctx, docker, home, networkID, site, cfg, effective)
+		return id, err == nil, err
 	}
 
 	// there is a container, so inspect it and make sure it matched
 	container := containers[0]
 
-	if container.State != "running" {
+	started := container.State != "running"
+	if started {
 		if err := docker.ContainerStart(ctx, container.ID, types.ContainerStartOptions{}); err != nil {
-			return "", err
+			return "", false, err
 		}
 	}
 
+	// skip the checks when the container was created with the same effective config
+	if container.Labels[containerlabels.EffectiveHash] == effective {
+		return container.ID, started, nil
+	}
+
 	// get the containers details that include environment variables
 	details, err := docker.ContainerInspect(ctx, container.ID)
 	if err != nil {
-		return "", err
+		return "", false, err
 	}
 
 	// if the container is out of date
-	if !match.Site(home, site, details, cfg.Blackfire) {
+	if !match.Site(home, site, details, cfg.Blackfire) || servicesChanged(details.Config.Env, cfg.Services) || agentChanged(details.Mounts, agent) {
 		fmt.Print("- updating… ")
 
+		// show what changed in the environment
+		if showEnvDiff {
+			for _, d := range EnvDiff(details.Config.Env, envs(site, cfg)) {
+				fmt.Print("\n    ", d)
+			}
+			fmt.Print("\n  ")
+		}
+
 		// stop container
 		if err := docker.ContainerStop(ctx, container.ID, nil); err != nil {
-			return "", err
+			return "", false, err
 		}
 
-		// remove container
-		if err := docker.ContainerRemove(ctx, container.ID, types.ContainerRemoveOptions{}); err != nil {
-			return "", err
+		// keep the container for a rollback or remove it
+		if rollback == nil {
+			if err := docker.ContainerRemove(ctx, container.ID, types.ContainerRemoveOptions{}); err != nil {
+				return "", false, err
+			}
+		} else {
+			if err := rollback.retire(ctx, docker, container.ID, site.Hostname); err != nil {
+				return "", false, err
+			}
+
+			rollback.created = append(rollback.created, site.Hostname)
 		}
 
-		return create(ctx, docker, home, networkID, site, cfg)
+		id, err := create(ctx, docker, home, networkID, site, cfg, effective)
+		return id, err == nil, err
 	}
 
-	return container.ID, nil
+	return container.ID, started, nil
 }
 
-func create(ctx context.Context, docker client.CommonAPIClient, home, networkID string, site config.Site, cfg *config.Config) (string, error) {
-	// create the container
+func create(ctx context.Context, docker client.CommonAPIClient, home, networkID string, site config.Site, cfg *config.Config, effective string) (string, error) {
+	// get the image for the version
 	image := fmt.Sprintf(NginxImage, site.Version)
 
 	// pull the image if we are not in a development environment
@@ -108,6 +171,57 @@ func create(ctx context.Context, docker client.CommonAPIClient, home, networkID
 		return "", err
 	}
 
+	// get the directories to exclude from the mount
+	excluded, err := site.GetExcludedDirs(home)
+	if err != nil {
+		return "", err
+	}
+
+	// mount a volume over each excluded directory so it is not shared with the host
+	var mounts []mount.Mount
+	for _, dir := range excluded {
+		mounts = append(mounts, mount.Mount{
+			Type:   mount.TypeVolume,
+			Source: volumename.FromPath(filepath.Join(site.Hostname, filepath.FromSlash(dir))),
+			Target: "/app/" + dir,
+			VolumeOptions: &mount.VolumeOptions{
+				Labels: map[string]string{
+					containerlabels.Nitro: "true",
+					containerlabels.Host:  site.Hostname,
+				},
+			},
+		})
+	}
+
+	// mount the xdebug directory so profiles and traces are on the host
+	if site.XdebugProfile != "" {
+		dir := site.XdebugDir(home)
+		if err := os.MkdirAll(dir, 0777); err != nil {
+			return "", fmt.Errorf("unable to create the xdebug directory, %w", err)
+		}
+
+		// the web server user in the container needs to write to the directory
+		if err := os.Chmod(dir, 0777); err != nil {
+			return "", fmt.Errorf("unable to set the permissions for the xdebug directory, %w", err)
+		}
+
+		mounts = append(mounts, mount.Mount{
+			Type:   mount.TypeBind,
+			Source: dir,
+			Target: config.XdebugOutputDir,
+		})
+	}
+
+	// mount the ssh agent socket so composer and git can use the hosts keys
+	agent, err := agentMount(site)
+	if err != nil {
+		return "", err
+	}
+
+	if agent != nil {
+		mounts = append(mounts, *agent)
+	}
+
 	// add the site itself and any aliases to the extra hosts
 	extraHosts := []string{fmt.Sprintf("%s:%s", site.Hostname, "127.0.0.1")}
 	for _, s := range site.Aliases {
@@ -119,31 +233,42 @@ func create(ctx context.Context, docker client.CommonAPIClient, home, networkID
 		extraHosts = append(extraHosts, fmt.Sprintf("%s:%s", "host.docker.internal", "host-gateway"))
 	}
 
-	// get the sites environment variables
-	envs := site.AsEnvs("host.docker.internal")
-
-	// does the config have blackfire credentials
-	if cfg.Blackfire.ServerID != "" {
-		envs = append(envs, "BLACKFIRE_SERVER_ID="+cfg.Blackfire.ServerID)
+	// get the custom nginx directives
+	directives, err := site.GetNginx(home)
+	if err != nil {
+		return "", err
 	}
 
-	if cfg.Blackfire.ServerToken != "" {
-		envs = append(envs, "BLACKFIRE_SERVER_TOKEN="+cfg.Blackfire.ServerToken)
+	// limit the cpus and memory for the container
+	memory, err := config.MemoryBytes(site.Memory)
+	if err != nil {
+		return "", err
 	}
 
 	// set the labels
-	labels := containerlabels.ForSite(site)
+	labels := containerlabels.StampRunID(ctx, containerlabels.ForSite(site))
+	labels[containerlabels.EffectiveHash] = effective
+	if directives != "" {
+		labels[containerlabels.Nginx] = nginx.Hash(directives)
+	}
+
 	// create the container
-	resp, err := docker.ContainerCreate(
+	resp, err := imagepull.Create(
 		ctx,
+		docker,
 		&container.Config{
 			Image:  image,
 			Labels: labels,
-			Env:    envs,
+			Env:    envs(site, cfg),
 		},
 		&container.HostConfig{
 			Binds:      []string{fmt.Sprintf("%s:/app:rw", path)},
+			Mounts:     mounts,
 			ExtraHosts: extraHosts,
+			Resources: container.Resources{
+				NanoCPUs: config.NanoCPUs(site.CPUs),
+				Memory:   memory,
+			},
 		},
 		&network.NetworkingConfig{
 			EndpointsConfig: map[string]*network.EndpointSettings{
@@ -167,10 +292,10 @@ func create(ctx context.Context, docker client.CommonAPIClient, home, networkID
 	// post installation commands
 	var commands []command
 
-	// check for a custom root and copt the template to the container
-	if site.Webroot != "web" {
+	// check for a custom root, directives, or limits and copy the template to the container
+	if site.Webroot != "web" || directives != "" || site.Limits != nil {
 		// create the nginx file
-		conf := nginx.Generate(site.Webroot)
+		conf := nginx.Generate(site.Webroot, directives, site.Limits)
 
 		// create the temp file
 		tr, err := archive.Generate("default.conf", conf)
@@ -187,11 +312,19 @@ func create(ctx context.Context, docker client.CommonAPIClient, home, networkID
 		commands = append(commands, command{Commands: []string{"chmod", "0644", "/etc/nginx/conf.d/default.conf"}})
 	}
 
-	// check if there are custom extensions
-	for _, ext := range site.Extensions {
-		commands = append(commands, command{Name: "installing-" + ext + "-extension", Commands: []string{"docker-php-ext-install", ext}})
+	// link the timezone so logs and system tools use it
+	if site.Timezone != "" {
+		commands = append(commands, command{Name: "timezone", Commands: []string{"ln", "-sf", "/usr/share/zoneinfo/" + site.Timezone, "/etc/localtime"}})
 	}
 
+	// the Docker Desktop socket is owned by root, so let the web server user connect to it
+	if agent != nil && agent.Source == dockerDesktopAgentSocket {
+		commands = append(commands, command{Name: "ssh-agent", Commands: []string{"chown", "www-data:www-data", config.SSHAgentSocket}})
+	}
+
+	// install the extensions that are not included in the image
+	commands = append(commands, installCommands(site.Version, site.Extensions)...)
+
 	// run the commands
 	for _, c := range commands {
 		// create the exec
@@ -218,7 +351,7 @@ func create(ctx context.Context, docker client.CommonAPIClient, home, networkID
 		// if the option is for a php extension, don't show output
 		if strings.Contains(c.Name, "-extension") {
 			// read the output to pull the image
-			fmt.Print("installing ", c.Commands[len(c.Commands)-1], "… ")
+			fmt.Print("installing ", strings.TrimSuffix(strings.TrimPrefix(c.Name, "installing-"), "-extension"), "… ")
 
 			buf := &bytes.Buffer{}
 			if _, err := buf.ReadFrom(attach.Reader); err != nil {
@@ -255,3 +388,202 @@ func create(ctx context.Context, docker client.CommonAPIClient, home, networkID
 
 	return resp.ID, nil
 }
+
+// envs returns the environment variables for a sites container.
+func envs(site config.Site, cfg *config.Config) []string {
+	// get the sites environment variables
+	envs := site.AsEnvs("host.docker.internal")
+
+	// does the config have blackfire credentials
+	if cfg.Blackfire.ServerID != "" {
+		envs = append(envs, "BLACKFIRE_SERVER_ID="+cfg.Blackfire.ServerID)
+	}
+
+	if cfg.Blackfire.ServerToken != "" {
+		envs = append(envs, "BLACKFIRE_SERVER_TOKEN="+cfg.Blackfire.ServerToken)
+	}
+
+	envs = append(envs, serviceEnvs(cfg.Services)...)
+
+	return siteEnvs(envs, site.Env)
+}
+
+// effectiveHash returns a hash of the effective config for a sites container. It includes the
+// settings that are not in the site config, such as the environment variables, ignore files, and
+// nginx directive files, so an unchanged container can be skipped without inspecting it.
+func effectiveHash(home string, site config.Site, cfg *config.Config) (string, error) {
+	image := fmt.Sprintf(NginxImage, site.Version)
+
+	path, err := site.GetAbsPath(home)
+	if err != nil {
+		return "", err
+	}
+
+	excluded, err := site.GetExcludedDirs(home)
+	if err != nil {
+		return "", err
+	}
+
+	directives, err := site.GetNginx(home)
+	if err != nil {
+		return "", err
+	}
+
+	agent, err := agentMount(site)
+	if err != nil {
+		return "", err
+	}
+
+	parts := []string{site.Hash(), image, path, strings.Join(excluded, ","), nginx.Hash(directives), config.Environment()}
+	if agent != nil {
+		parts = append(parts, agent.Source)
+	}
+	parts = append(parts, envs(site, cfg)...)
+
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+
+	return hex.EncodeToString(sum[:])[:16], nil
+}
+
+// siteEnvs adds the sites environment variables in order by name, the variables
+// replace existing variables with the same name.
+func siteEnvs(envs []string, env map[string]string) []string {
+	if len(env) == 0 {
+		return envs
+	}
+
+	var merged []string
+	for _, e := range envs {
+		if _, ok := env[strings.SplitN(e, "=", 2)[0]]; !ok {
+			merged = append(merged, e)
+		}
+	}
+
+	var names []string
+	for k := range env {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	for _, k := range names {
+		merged = append(merged, k+"="+env[k])
+	}
+
+	return merged
+}
+
+// serviceEnvs returns the environment variables for connecting to the
+// enabled services from a site (e.g. REDIS_HOST).
+func serviceEnvs(services config.Services) []string {
+	var envs []string
+	if services.Elasticsearch {
+		envs = append(envs, "ELASTICSEARCH_HOST="+elasticsearch.Host, "ELASTICSEARCH_PORT="+elasticsearch.Port)
+	}
+
+	if services.Meilisearch {
+		envs = append(envs, "MEILISEARCH_HOST="+meilisearch.Host, "MEILISEARCH_PORT="+meilisearch.Port, "MEILISEARCH_MASTER_KEY="+services.MeilisearchMasterKey)
+	}
+
+	if services.RabbitMQ {
+		envs = append(envs, "RABBITMQ_HOST="+rabbitmq.Host, "RABBITMQ_PORT="+rabbitmq.Port)
+	}
+
+	if services.Redis {
+		envs = append(envs, "REDIS_HOST="+redis.Host, "REDIS_PORT="+redis.Port)
+	}
+
+	return envs
+}
+
+// servicesChanged returns true when the service environment variables for
+// the container do not match the enabled services.
+func servicesChanged(current []string, services config.Services) bool {
+	want := map[string]string{
+		"ELASTICSEARCH_HOST":     "",
+		"ELASTICSEARCH_PORT":     "",
+		"MEILISEARCH_HOST":       "",
+		"MEILISEARCH_PORT":       "",
+		"MEILISEARCH_MASTER_KEY": "",
+		"RABBITMQ_HOST":          "",
+		"RABBITMQ_PORT":          "",
+		"REDIS_HOST":             "",
+		"REDIS_PORT":             "",
+	}
+	for _, e := range serviceEnvs(services) {
+		parts := strings.SplitN(e, "=", 2)
+		want[parts[0]] = parts[1]
+	}
+
+	got := make(map[string]string)
+	for _, e := range current {
+		parts := strings.SplitN(e, "=", 2)
+		if _, ok := want[parts[0]]; ok && len(parts) == 2 {
+			got[parts[0]] = parts[1]
+		}
+	}
+
+	for k, v := range want {
+		if got[k] != v {
+			return true
+		}
+	}
+
+	return false
+}
+
+// EnvDiff compares the environment variables from an existing container with the
+// variables for the new container and returns a line for each change (e.g.
+// PHP_MEMORY_LIMIT: 512M → 1G). Only variables that nitro sets are compared, so
+// variables from the image (e.g. PATH) are ignored.
+func EnvDiff(previous, current []string) []string {
+	toMap := func(envs []string) map[string]string {
+		m := make(map[string]string)
+		for _, e := range envs {
+			parts := strings.SplitN(e, "=", 2)
+			if len(parts) == 2 {
+				m[parts[0]] = parts[1]
+			} else {
+				m[parts[0]] = ""
+			}
+		}
+
+		return m
+	}
+
+	prev := toMap(previous)
+	curr := toMap(current)
+
+	// collect the variables that nitro manages
+	keys := make(map[string]bool)
+	for k := range curr {
+		keys[k] = true
+	}
+	for k := range prev {
+		if _, ok := config.DefaultEnvs[k]; ok {
+			keys[k] = true
+		}
+	}
+
+	var sorted []string
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	var diff []string
+	for _, k := range sorted {
+		p, hadPrev := prev[k]
+		c, hasCurr := curr[k]
+
+		switch {
+		case !hadPrev:
+			diff = append(diff, fmt.Sprintf("%s: (unset) → %s", k, c))
+		case !hasCurr:
+			diff = append(diff, fmt.Sprintf("%s: %s → (unset)", k, p))
+		case p != c:
+			diff = append(diff, fmt.Sprintf("%s: %s → %s", k, p, c))
+		}
+	}
+
+	return diff
+}
@@ -0,0 +1,417 @@
+package sitecontainer
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/mount"
+)
+
+func TestEnvDiff(t *testing.T) {
+	type args struct {
+		previous []string
+		current  []string
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "matching envs have no changes",
+			args: args{
+				previous: []string{"PHP_MEMORY_LIMIT=512M", "PATH=/usr/bin"},
+				current:  []string{"PHP_MEMORY_LIMIT=512M"},
+			},
+			want: nil,
+		},
+		{
+			name: "changed values show the previous and new value",
+			args: args{
+				previous: []string{"PHP_MEMORY_LIMIT=512M", "XDEBUG_MODE=off"},
+				current:  []string{"PHP_MEMORY_LIMIT=1G", "XDEBUG_MODE=develop,debug"},
+			},
+			want: []string{
+				"PHP_MEMORY_LIMIT: 512M → 1G",
+				"XDEBUG_MODE: off → develop,debug",
+			},
+		},
+		{
+			name: "added and removed variables are shown as unset",
+			args: args{
+				previous: []string{"BLACKFIRE_SERVER_ID=someid", "PATH=/usr/bin"},
+				current:  []string{"TZ=America/Chicago"},
+			},
+			want: []string{
+				"BLACKFIRE_SERVER_ID: someid → (unset)",
+				"TZ: (unset) → America/Chicago",
+			},
+		},
+		{
+			name: "values with equal signs are compared as a whole",
+			args: args{
+				previous: []string{"PHP_IDE_CONFIG=serverName=old.nitro"},
+				current:  []string{"PHP_IDE_CONFIG=serverName=new.nitro"},
+			},
+			want: []string{"PHP_IDE_CONFIG: serverName=old.nitro → serverName=new.nitro"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EnvDiff(tt.args.previous, tt.args.current); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("EnvDiff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckExtensions(t *testing.T) {
+	tests := []struct {
+		name       string
+		version    string
+		extensions []string
+		wantErr    error
+	}{
+		{
+			name:    "no extensions are valid",
+			version: "7.4",
+		},
+		{
+			name:       "included extensions are valid",
+			version:    "8.0",
+			extensions: []string{"gd", "Redis"},
+		},
+		{
+			name:       "extensions that can be installed are valid",
+			version:    "7.4",
+			extensions: []string{"gmp", "xsl"},
+		},
+		{
+			name:       "imagick can be installed on php 8",
+			version:    "8.0",
+			extensions: []string{"imagick", "gd"},
+		},
+		{
+			name:       "unknown extensions return an error",
+			version:    "7.4",
+			extensions: []string{"sodium"},
+			wantErr:    ErrUnsupportedExtension,
+		},
+		{
+			name:       "unknown versions must install the extensions",
+			version:    "5.6",
+			extensions: []string{"gd"},
+			wantErr:    ErrUnsupportedExtension,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckExtensions(tt.version, tt.extensions); !errors.Is(err, tt.wantErr) {
+				t.Errorf("CheckExtensions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_installCommands(t *testing.T) {
+	tests := []struct {
+		name       string
+		version    string
+		extensions []string
+		want       []command
+	}{
+		{
+			name:       "included extensions are not installed",
+			version:    "7.4",
+			extensions: []string{"imagick", "redis"},
+			want:       nil,
+		},
+		{
+			name:       "extensions use docker-php-ext-install",
+			version:    "7.4",
+			extensions: []string{"gd", "gmp"},
+			want: []command{
+				{Name: "installing-gmp-extension", Commands: []string{"docker-php-ext-install", "gmp"}},
+			},
+		},
+		{
+			name:       "imagick is installed from pecl on php 8",
+			version:    "8.0",
+			extensions: []string{"imagick"},
+			want: []command{
+				{Name: "installing-imagick-extension", Commands: []string{"sh", "-c", "pecl install imagick && docker-php-ext-enable imagick"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := installCommands(tt.version, tt.extensions); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("installCommands() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServicesChanged(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  []string
+		services config.Services
+		want     bool
+	}{
+		{
+			name:    "containers without redis do not change when redis is disabled",
+			current: []string{"PHP_MEMORY_LIMIT=512M"},
+		},
+		{
+			name:     "containers without redis change when redis is enabled",
+			current:  []string{"PHP_MEMORY_LIMIT=512M"},
+			services: config.Services{Redis: true},
+			want:     true,
+		},
+		{
+			name:     "containers with redis do not change when redis is enabled",
+			current:  []string{"PHP_MEMORY_LIMIT=512M", "REDIS_HOST=redis.service.nitro", "REDIS_PORT=6379"},
+			services: config.Services{Redis: true, RedisVersion: "6.2"},
+		},
+		{
+			name:     "containers without elasticsearch change when elasticsearch is enabled",
+			current:  []string{"REDIS_HOST=redis.service.nitro", "REDIS_PORT=6379"},
+			services: config.Services{Redis: true, Elasticsearch: true},
+			want:     true,
+		},
+		{
+			name:     "containers with elasticsearch do not change when elasticsearch is enabled",
+			current:  []string{"ELASTICSEARCH_HOST=elasticsearch.service.nitro", "ELASTICSEARCH_PORT=9200"},
+			services: config.Services{Elasticsearch: true},
+		},
+		{
+			name:     "containers without rabbitmq change when rabbitmq is enabled",
+			current:  []string{"PHP_MEMORY_LIMIT=512M"},
+			services: config.Services{RabbitMQ: true},
+			want:     true,
+		},
+		{
+			name:     "containers change when the meilisearch master key changes",
+			current:  []string{"MEILISEARCH_HOST=meilisearch.service.nitro", "MEILISEARCH_PORT=7700", "MEILISEARCH_MASTER_KEY=secret"},
+			services: config.Services{Meilisearch: true, MeilisearchMasterKey: "changed"},
+			want:     true,
+		},
+		{
+			name:    "containers with redis change when redis is disabled",
+			current: []string{"REDIS_HOST=redis.service.nitro", "REDIS_PORT=6379"},
+			want:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := servicesChanged(tt.current, tt.services); got != tt.want {
+				t.Errorf("servicesChanged() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_siteEnvs(t *testing.T) {
+	tests := []struct {
+		name string
+		envs []string
+		env  map[string]string
+		want []string
+	}{
+		{
+			name: "no site variables return the variables",
+			envs: []string{"PHP_MEMORY_LIMIT=512M"},
+			want: []string{"PHP_MEMORY_LIMIT=512M"},
+		},
+		{
+			name: "site variables are added in order",
+			envs: []string{"PHP_MEMORY_LIMIT=512M"},
+			env:  map[string]string{"STRIPE_KEY": "sk_test", "FEATURE_FLAG": "on"},
+			want: []string{"PHP_MEMORY_LIMIT=512M", "FEATURE_FLAG=on", "STRIPE_KEY=sk_test"},
+		},
+		{
+			name: "site variables replace existing variables",
+			envs: []string{"PHP_MEMORY_LIMIT=512M", "REDIS_HOST=redis.service.nitro"},
+			env:  map[string]string{"REDIS_HOST": "cache.nitro"},
+			want: []string{"PHP_MEMORY_LIMIT=512M", "REDIS_HOST=cache.nitro"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := siteEnvs(tt.envs, tt.env); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("siteEnvs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_effectiveHash(t *testing.T) {
+	home := t.TempDir()
+	site := config.Site{
+		Hostname: "craft-dev.nitro",
+		Path:     "~/sites/craft-dev",
+		Version:  "8.0",
+		Webroot:  "web",
+	}
+
+	if err := os.MkdirAll(filepath.Join(home, "sites", "craft-dev", "vendor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	base, err := effectiveHash(home, site, &config.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		prepare func(s *config.Site, cfg *config.Config) error
+		changed bool
+	}{
+		{
+			name:    "the same config has the same hash",
+			prepare: func(s *config.Site, cfg *config.Config) error { return nil },
+			changed: false,
+		},
+		{
+			name: "site changes change the hash",
+			prepare: func(s *config.Site, cfg *config.Config) error {
+				s.PHP.MemoryLimit = "2G"
+				return nil
+			},
+			changed: true,
+		},
+		{
+			name: "services change the hash",
+			prepare: func(s *config.Site, cfg *config.Config) error {
+				cfg.Services.Redis = true
+				return nil
+			},
+			changed: true,
+		},
+		{
+			name: "ignore files change the hash",
+			prepare: func(s *config.Site, cfg *config.Config) error {
+				return ioutil.WriteFile(filepath.Join(home, "sites", "craft-dev", config.IgnoreFileName), []byte("vendor\n"), 0644)
+			},
+			changed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, cfg := site, &config.Config{}
+			if err := tt.prepare(&s, cfg); err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(filepath.Join(home, "sites", "craft-dev", config.IgnoreFileName))
+
+			got, err := effectiveHash(home, s, cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if (got != base) != tt.changed {
+				t.Errorf("effectiveHash() = %s, base %s, expected changed %v", got, base, tt.changed)
+			}
+		})
+	}
+}
+
+func Test_agentSocket(t *testing.T) {
+	type args struct {
+		goos string
+		sock string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "linux uses the agent socket from the environment",
+			args: args{goos: "linux", sock: "/tmp/ssh-abc123/agent.1234"},
+			want: "/tmp/ssh-abc123/agent.1234",
+		},
+		{
+			name:    "linux without an agent returns an error",
+			args:    args{goos: "linux"},
+			wantErr: true,
+		},
+		{
+			name: "macOS uses the Docker Desktop socket",
+			args: args{goos: "darwin", sock: "/private/tmp/com.apple.launchd.abc/Listeners"},
+			want: dockerDesktopAgentSocket,
+		},
+		{
+			name:    "windows returns an error",
+			args:    args{goos: "windows", sock: `\\.\pipe\openssh-ssh-agent`},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := agentSocket(tt.args.goos, tt.args.sock)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("agentSocket() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("agentSocket() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_agentChanged(t *testing.T) {
+	app := types.MountPoint{Type: mount.TypeBind, Source: "/home/nitro/dev/craft-dev", Destination: "/app"}
+	agent := types.MountPoint{Type: mount.TypeBind, Source: "/tmp/ssh-abc123/agent.1234", Destination: config.SSHAgentSocket}
+
+	tests := []struct {
+		name   string
+		mounts []types.MountPoint
+		agent  *mount.Mount
+		want   bool
+	}{
+		{
+			name:   "containers without the agent do not change when the agent is disabled",
+			mounts: []types.MountPoint{app},
+		},
+		{
+			name:   "containers without the agent change when the agent is enabled",
+			mounts: []types.MountPoint{app},
+			agent:  &mount.Mount{Source: "/tmp/ssh-abc123/agent.1234"},
+			want:   true,
+		},
+		{
+			name:   "containers with the same socket do not change",
+			mounts: []types.MountPoint{app, agent},
+			agent:  &mount.Mount{Source: "/tmp/ssh-abc123/agent.1234"},
+		},
+		{
+			name:   "containers with a stale socket change",
+			mounts: []types.MountPoint{app, agent},
+			agent:  &mount.Mount{Source: "/tmp/ssh-def456/agent.5678"},
+			want:   true,
+		},
+		{
+			name:   "containers with the agent change when the agent is disabled",
+			mounts: []types.MountPoint{app, agent},
+			want:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := agentChanged(tt.mounts, tt.agent); got != tt.want {
+				t.Errorf("agentChanged() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package sitecontainer
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/mount"
+
+	"github.com/craftcms/nitro/pkg/config"
+)
+
+// dockerDesktopAgentSocket is the socket Docker Desktop for Mac forwards to the ssh agent on the host
+const dockerDesktopAgentSocket = "/run/host-services/ssh-auth.sock"
+
+// agentSocket returns the path of the hosts ssh agent socket for the operating system. Docker
+// Desktop for Mac cannot mount sockets from the host, so it provides its own socket instead.
+func agentSocket(goos, sock string) (string, error) {
+	switch goos {
+	case "darwin":
+		return dockerDesktopAgentSocket, nil
+	case "windows":
+		return "", fmt.Errorf("forwarding the ssh agent is not supported on Windows…\n run nitro from WSL to forward the ssh agent")
+	}
+
+	if sock == "" {
+		return "", fmt.Errorf("SSH_AUTH_SOCK is not set…\n run `eval $(ssh-agent)` and `ssh-add` to start the ssh agent")
+	}
+
+	return sock, nil
+}
+
+// agentMount returns the mount for the hosts ssh agent socket, or nil when the site does not
+// forward the ssh agent.
+func agentMount(site config.Site) (*mount.Mount, error) {
+	if !site.SSHAgent {
+		return nil, nil
+	}
+
+	sock, err := agentSocket(runtime.GOOS, os.Getenv("SSH_AUTH_SOCK"))
+	if err != nil {
+		return nil, fmt.Errorf("unable to forward the ssh agent to %s, %w", site.Hostname, err)
+	}
+
+	// the Docker Desktop socket is in the virtual machine and not on the host
+	if sock != dockerDesktopAgentSocket {
+		if _, err := os.Stat(sock); err != nil {
+			return nil, fmt.Errorf("unable to find the ssh agent socket %s…\n run `ssh-add -l` to check the ssh agent is running", sock)
+		}
+	}
+
+	return &mount.Mount{
+		Type:   mount.TypeBind,
+		Source: sock,
+		Target: config.SSHAgentSocket,
+	}, nil
+}
+
+// agentChanged returns true when the containers mounts do not match the ssh agent mount. The
+// socket on Linux changes each time the agent starts, which would leave a stale socket in the
+// container. A nil agent means the container should not mount the socket.
+func agentChanged(mounts []types.MountPoint, agent *mount.Mount) bool {
+	source := ""
+	if agent != nil {
+		source = agent.Source
+	}
+
+	for _, m := range mounts {
+		if m.Destination == config.SSHAgentSocket {
+			return m.Source != source
+		}
+	}
+
+	return source != ""
+}
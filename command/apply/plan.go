@@ -0,0 +1,324 @@
+package apply
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+
+	"github.com/craftcms/nitro/command/apply/internal/customcontainer"
+	"github.com/craftcms/nitro/command/apply/internal/match"
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/hostedit"
+	"github.com/craftcms/nitro/pkg/resolver"
+	"github.com/craftcms/nitro/pkg/svc/dynamodb"
+	"github.com/craftcms/nitro/pkg/svc/elasticsearch"
+	"github.com/craftcms/nitro/pkg/svc/mailhog"
+	"github.com/craftcms/nitro/pkg/svc/meilisearch"
+	"github.com/craftcms/nitro/pkg/svc/minio"
+	"github.com/craftcms/nitro/pkg/svc/rabbitmq"
+	"github.com/craftcms/nitro/pkg/svc/redis"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+// plan is used to count the changes apply would make during a dry run.
+type plan struct {
+	creates int
+	updates int
+	backups int
+	removed map[string]bool
+}
+
+// planned stores the changes between the run and post run of a dry run
+var planned plan
+
+func (p *plan) create(output terminal.Outputer, name string) {
+	p.creates++
+	output.Info("  would create", name)
+}
+
+func (p *plan) update(output terminal.Outputer, name string) {
+	p.updates++
+	output.Info("  would update", name)
+}
+
+func (p *plan) backup(output terminal.Outputer, name string) {
+	p.backups++
+	output.Info("  would back up", name)
+}
+
+func (p *plan) remove(output terminal.Outputer, name string) {
+	// disabled services are also unknown containers, so only report them once
+	if p.removed[name] {
+		return
+	}
+
+	if p.removed == nil {
+		p.removed = make(map[string]bool)
+	}
+
+	p.removed[name] = true
+	output.Info("  would remove", name)
+}
+
+// summary returns the total number of creates, updates, removals, and backups for the plan.
+func (p *plan) summary() string {
+	return fmt.Sprintf("Dry run complete: %d to create, %d to update, %d to remove, %d to back up", p.creates, p.updates, len(p.removed), p.backups)
+}
+
+// planApply inspects the existing containers and reports what apply would do for the config. It does not
+// create, start, stop, or remove anything. When hostname is not empty, only the site and the proxy are
+// checked, the same as apply with --site.
+func planApply(ctx context.Context, docker client.CommonAPIClient, home string, cfg *config.Config, hostname string, skipHosts bool, output terminal.Outputer) error {
+	planned = plan{}
+
+	output.Info("Dry run, no changes will be made…")
+
+	output.Info("Checking network…")
+
+	// check the network
+	filter := filters.NewArgs()
+	filter.Add("label", containerlabels.Nitro+"=true")
+	filter.Add("name", "nitro-network")
+
+	networks, err := docker.NetworkList(ctx, types.NetworkListOptions{Filters: filter})
+	if err != nil {
+		return fmt.Errorf("unable to list docker networks\n%w", err)
+	}
+
+	network := false
+	for _, n := range networks {
+		if n.Name == "nitro-network" {
+			network = true
+			break
+		}
+	}
+
+	if !network {
+		output.Info("  would run `nitro init` to create the network")
+	}
+
+	output.Info("Checking proxy…")
+
+	// check the proxy without starting it
+	proxies, err := list(ctx, docker, containerlabels.Type+"=proxy")
+	if err != nil {
+		return err
+	}
+
+	if len(proxies) == 0 {
+		output.Info("  would run `nitro init` to create the proxy")
+	}
+
+	// single site applies do not clean up other containers, so the summary is reported here
+	if hostname != "" {
+		site, err := cfg.FindSiteByHostName(hostname)
+		if err != nil {
+			return err
+		}
+
+		output.Info("Checking site…")
+
+		if err := planSite(ctx, docker, home, cfg, *site, output); err != nil {
+			return err
+		}
+
+		output.Info("Checking proxy…")
+		output.Info("  would update the proxy")
+
+		output.Info(planned.summary())
+
+		return nil
+	}
+
+	output.Info("Checking databases…")
+
+	for _, db := range cfg.Databases {
+		hostname, err := db.GetHostname()
+		if err != nil {
+			return err
+		}
+
+		containers, err := list(ctx, docker,
+			containerlabels.DatabaseEngine+"="+db.Engine,
+			containerlabels.DatabaseVersion+"="+db.Version,
+			containerlabels.DatabasePort+"="+db.Port,
+			containerlabels.Type+"=database",
+		)
+		if err != nil {
+			return err
+		}
+
+		if len(containers) == 0 {
+			planned.create(output, hostname)
+		}
+
+		hostnames = append(hostnames, hostname)
+	}
+
+	output.Info("Checking services…")
+
+	services := []struct {
+		enabled bool
+		label   string
+		host    string
+	}{
+		{enabled: cfg.Services.DynamoDB, label: dynamodb.Label, host: dynamodb.Host},
+		{enabled: cfg.Services.Elasticsearch, label: elasticsearch.Label, host: elasticsearch.Host},
+		{enabled: cfg.Services.Mailhog, label: mailhog.Label, host: mailhog.Host},
+		{enabled: cfg.Services.Meilisearch, label: meilisearch.Label, host: meilisearch.Host},
+		{enabled: cfg.Services.Minio, label: minio.Label, host: minio.Host},
+		{enabled: cfg.Services.RabbitMQ, label: rabbitmq.Label, host: rabbitmq.Host},
+		{enabled: cfg.Services.Redis, label: redis.Label, host: redis.Host},
+	}
+
+	for _, s := range services {
+		containers, err := list(ctx, docker, containerlabels.Nitro+"=true", containerlabels.Type+"="+s.label)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case s.enabled && len(containers) == 0:
+			planned.create(output, s.host)
+		case !s.enabled && len(containers) > 0:
+			planned.remove(output, s.host)
+		}
+
+		if s.enabled {
+			hostnames = append(hostnames, s.host)
+		}
+	}
+
+	if len(cfg.Containers) > 0 {
+		output.Info("Checking containers…")
+
+		for _, c := range cfg.Containers {
+			name := c.Name + customcontainer.Suffix
+
+			containers, err := list(ctx, docker, containerlabels.Nitro+"=true", containerlabels.NitroContainer+"="+c.Name)
+			if err != nil {
+				return err
+			}
+
+			if len(containers) == 0 {
+				planned.create(output, name)
+				continue
+			}
+
+			details, err := docker.ContainerInspect(ctx, containers[0].ID)
+			if err != nil {
+				return err
+			}
+
+			if err := match.Container(home, c, details); err != nil {
+				planned.update(output, name)
+			}
+		}
+	}
+
+	if len(cfg.Sites) > 0 {
+		output.Info("Checking sites…")
+
+		for _, site := range cfg.Sites {
+			if err := planSite(ctx, docker, home, cfg, site, output); err != nil {
+				return err
+			}
+		}
+	}
+
+	output.Info("Checking proxy…")
+	output.Info("  would update the proxy")
+
+	// should we check the hosts file?
+	if os.Getenv("NITRO_EDIT_HOSTS") == "false" || skipHosts {
+		return nil
+	}
+
+	for _, s := range cfg.Sites {
+		hostnames = append(hostnames, s.Hostname)
+		hostnames = append(hostnames, s.Aliases...)
+	}
+
+	for _, p := range cfg.HostProxies {
+		hostnames = append(hostnames, p.Hostname)
+	}
+
+	hostnames = append(hostnames, proxyHostnames(cfg)...)
+
+	if cfg.Proxy.DNS {
+		installed, err := resolver.IsInstalled(cfg.GetTLD(), cfg.GetDNSPort())
+		if err == nil {
+			if !installed {
+				output.Info("  would install the resolver for", "."+cfg.GetTLD())
+			}
+
+			hostnames = withoutTLD(hostnames, cfg.GetTLD())
+		}
+	}
+
+	if len(hostnames) == 0 {
+		return nil
+	}
+
+	if runtime.GOOS == "windows" {
+		defaultFile = `C:\Windows\System32\Drivers\etc\hosts`
+	}
+
+	updated, err := hostedit.IsUpdated(defaultFile, "127.0.0.1", hostnames...)
+	if err != nil {
+		return err
+	}
+
+	if !updated {
+		output.Info("  would modify the hosts file")
+	}
+
+	return nil
+}
+
+// planSite reports if apply would create or update the container for the site.
+func planSite(ctx context.Context, docker client.CommonAPIClient, home string, cfg *config.Config, site config.Site, output terminal.Outputer) error {
+	site.PHP = cfg.PHP.Override(site.PHP)
+
+	containers, err := list(ctx, docker, containerlabels.Host+"="+site.Hostname)
+	if err != nil {
+		return err
+	}
+
+	if len(containers) == 0 {
+		planned.create(output, site.Hostname)
+		return nil
+	}
+
+	details, err := docker.ContainerInspect(ctx, containers[0].ID)
+	if err != nil {
+		return err
+	}
+
+	if !match.Site(home, site, details, cfg.Blackfire) {
+		planned.update(output, site.Hostname)
+	}
+
+	return nil
+}
+
+// list returns all of the containers that match the label filters.
+func list(ctx context.Context, docker client.ContainerAPIClient, labels ...string) ([]types.Container, error) {
+	filter := filters.NewArgs()
+	for _, l := range labels {
+		filter.Add("label", l)
+	}
+
+	containers, err := docker.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: filter})
+	if err != nil {
+		return nil, fmt.Errorf("error getting a list of containers")
+	}
+
+	return containers, nil
+}
@@ -0,0 +1,126 @@
+package apply
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/command/apply/internal/sitecontainer"
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/proxycontainer"
+	"github.com/craftcms/nitro/pkg/terminal"
+	"github.com/craftcms/nitro/protob"
+)
+
+// applySite only applies the changes for a single site container and the proxy. The databases,
+// services, and custom containers are not checked, and unknown containers are not removed.
+func applySite(ctx context.Context, cmd *cobra.Command, docker client.CommonAPIClient, nitrod protob.NitroClient, home string, cfg *config.Config, hostname string, rollback *sitecontainer.Rollback, output terminal.Outputer) error {
+	site, err := cfg.FindSiteByHostName(hostname)
+	if err != nil {
+		return err
+	}
+
+	output.Info("Checking network…")
+
+	network, err := findNetwork(ctx, docker)
+	if err != nil {
+		return err
+	}
+
+	var external string
+	if cfg.Network.External != "" {
+		external, err = externalNetwork(ctx, docker, cfg.Network.External)
+		if err != nil {
+			return err
+		}
+	}
+
+	output.Success("network ready")
+
+	output.Info("Checking proxy…")
+
+	// the proxy is only created by a full apply
+	if _, err := proxycontainer.FindAndStart(ctx, docker); err != nil {
+		if errors.Is(err, proxycontainer.ErrNoProxyContainer) {
+			output.Info("unable to find the nitro proxy…\n run `nitro apply` to resolve")
+		}
+
+		return err
+	}
+
+	output.Success("proxy ready")
+
+	output.Info("Checking site…")
+
+	output.Pending("checking", site.Hostname)
+
+	// the sites php settings override the defaults
+	site.PHP = cfg.PHP.Override(site.PHP)
+
+	// wait for the sites dependencies to be ready
+	if err := waitForDependencies(ctx, docker, cfg, *site); err != nil {
+		output.Warning()
+		return err
+	}
+
+	id, created, err := sitecontainer.StartOrCreate(ctx, docker, home, network.ID, *site, cfg, cmd.Flag("preserve-env").Value.String() == "true", rollback)
+	if err != nil {
+		output.Warning()
+		return err
+	}
+
+	if err := connectExternal(ctx, docker, external, id); err != nil {
+		output.Warning()
+		return err
+	}
+
+	output.Done()
+
+	output.Info("Checking proxy…")
+
+	timeout, err := cmd.Flags().GetDuration("proxy-timeout")
+	if err != nil {
+		return err
+	}
+
+	if err := updateProxy(ctx, docker, nitrod, home, cfg, timeout, output); err != nil {
+		return err
+	}
+
+	// report the container if it did not start or is unhealthy
+	report, err := checkHealth(ctx, docker, []string{id}, cmd.Flag("show-logs-on-failure").Value.String() == "true")
+	if err != nil {
+		return err
+	}
+
+	if len(report) > 0 {
+		output.Info("Warning: the site is not healthy…")
+
+		for _, l := range healthSummary(report) {
+			output.Info(l)
+		}
+	}
+
+	if created && len(site.PostStart) > 0 {
+		output.Info("Running post start commands for", site.Hostname+"…")
+
+		if err := runPostStart(ctx, docker, id, *site, cmd.OutOrStdout()); err != nil {
+			return err
+		}
+	}
+
+	if len(cfg.Hooks.PostApply) > 0 {
+		output.Info("Running post apply hooks…")
+
+		if err := runHooks(ctx, cfg.Hooks.PostApply, cmd.OutOrStdout(), cmd.ErrOrStderr()); err != nil {
+			return err
+		}
+	}
+
+	output.Info(fmt.Sprintf("%s is up and running 😃", site.Hostname))
+
+	return nil
+}
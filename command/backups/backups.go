@@ -0,0 +1,44 @@
+package backups
+
+import (
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+const exampleText = `  # show the database backups
+  nitro backups list
+
+  # remove old backups
+  nitro backups prune --keep 10
+
+  # share the backups with the backups.storage bucket
+  nitro backups push
+  nitro backups pull
+
+  # backup all databases now if the backups.schedule is due, nitro apply
+  # starts a container that runs the backups on the schedule
+  nitro backups run`
+
+// NewCommand returns the commands for listing and scheduling database backups.
+func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "backups",
+		Short:   "Manages database backups.",
+		Example: exampleText,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(
+		listCommand(home, output),
+		pruneCommand(home, output),
+		pullCommand(home, output),
+		pushCommand(home, output),
+		runCommand(home, docker, output),
+	)
+
+	return cmd
+}
@@ -0,0 +1,52 @@
+package backups
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/backup"
+	"github.com/craftcms/nitro/pkg/helpers"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+func listCommand(home string, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Shows the database backups.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			backups, err := backup.List(home)
+			if err != nil {
+				return fmt.Errorf("unable to find the backups, %w", err)
+			}
+
+			if len(backups) == 0 {
+				output.Info("There are no backups…")
+
+				return nil
+			}
+
+			container, db := "", ""
+			for _, b := range backups {
+				if b.Container != container {
+					container, db = b.Container, ""
+
+					output.Info(container + ":")
+				}
+
+				if b.Database != db {
+					db = b.Database
+
+					output.Info("  " + db)
+				}
+
+				output.Info(fmt.Sprintf("    %s\t%s\t%s", b.CreatedAt.Format("2006-01-02 15:04:05"), helpers.ByteSize(b.Size), b.Name))
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
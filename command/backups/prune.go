@@ -0,0 +1,83 @@
+package backups
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/backup"
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+var pruneExampleText = `  # remove the backups based on backups.keep and backups.keep_days in the config
+  nitro backups prune
+
+  # keep the newest 5 backups for each database
+  nitro backups prune --keep 5
+
+  # show the backups that would be removed
+  nitro backups prune --keep-days 30 --dry-run`
+
+func pruneCommand(home string, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "prune",
+		Short:   "Removes old database backups.",
+		Example: pruneExampleText,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(home)
+			if err != nil {
+				return err
+			}
+
+			policy := retention(cfg)
+
+			// the flags override the config
+			if cmd.Flag("keep").Changed {
+				policy.Keep, _ = cmd.Flags().GetInt("keep")
+			}
+			if cmd.Flag("keep-days").Changed {
+				policy.KeepDays, _ = cmd.Flags().GetInt("keep-days")
+			}
+
+			if policy.Keep <= 0 && policy.KeepDays <= 0 {
+				return fmt.Errorf("there is no retention policy, set backups.keep or backups.keep_days in the config or use the --keep or --keep-days flags")
+			}
+
+			if cmd.Flag("dry-run").Value.String() == "true" {
+				backups, err := backup.List(home)
+				if err != nil {
+					return err
+				}
+
+				for _, b := range backup.Prune(backups, policy, time.Now()) {
+					output.Info("Would remove", b.Container+"/"+b.Name)
+				}
+
+				return nil
+			}
+
+			removed, err := backup.RemoveOld(home, policy, time.Now())
+			if err != nil {
+				return err
+			}
+
+			output.Info(fmt.Sprintf("Removed %d backups 🧹", removed))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().Int("keep", 0, "the number of backups to keep for each database")
+	cmd.Flags().Int("keep-days", 0, "the number of days to keep backups")
+	cmd.Flags().Bool("dry-run", false, "show the backups to remove without removing them")
+
+	return cmd
+}
+
+// retention returns the retention policy from the config.
+func retention(cfg *config.Config) backup.Retention {
+	return backup.Retention{Keep: cfg.Backups.Keep, KeepDays: cfg.Backups.KeepDays}
+}
@@ -0,0 +1,108 @@
+package backups
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/backup"
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+// ErrNoStorage is returned when pushing or pulling backups without storage in the config
+var ErrNoStorage = errors.New("there is no backup storage, add backups.storage to the config")
+
+func pushCommand(home string, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "push",
+		Short: "Uploads the database backups to the storage.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(home)
+			if err != nil {
+				return err
+			}
+
+			if cfg.Backups.Storage == nil {
+				return ErrNoStorage
+			}
+
+			storage, err := backup.NewStorage(*cfg.Backups.Storage)
+			if err != nil {
+				return err
+			}
+
+			backups, err := backup.List(home)
+			if err != nil {
+				return fmt.Errorf("unable to find the backups, %w", err)
+			}
+
+			output.Pending("uploading backups to", cfg.Backups.Storage.Bucket)
+
+			pushed, err := backup.Push(cmd.Context(), storage, cfg.Backups.Storage.Prefix, backups)
+			if err != nil {
+				output.Warning()
+
+				return err
+			}
+
+			output.Done()
+
+			for _, b := range pushed {
+				output.Info("  uploaded", b.Container+"/"+b.Name)
+			}
+
+			output.Info(fmt.Sprintf("Uploaded %d backups ☁️", len(pushed)))
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func pullCommand(home string, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pull",
+		Short: "Downloads the database backups from the storage.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(home)
+			if err != nil {
+				return err
+			}
+
+			if cfg.Backups.Storage == nil {
+				return ErrNoStorage
+			}
+
+			storage, err := backup.NewStorage(*cfg.Backups.Storage)
+			if err != nil {
+				return err
+			}
+
+			output.Pending("downloading backups from", cfg.Backups.Storage.Bucket)
+
+			pulled, err := backup.Pull(cmd.Context(), storage, cfg.Backups.Storage.Prefix, home)
+			if err != nil {
+				output.Warning()
+
+				return err
+			}
+
+			output.Done()
+
+			for _, p := range pulled {
+				output.Info("  downloaded", p)
+			}
+
+			output.Info(fmt.Sprintf("Downloaded %d backups, use nitro db restore to restore a backup", len(pulled)))
+
+			return nil
+		},
+	}
+
+	return cmd
+}
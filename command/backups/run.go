@@ -0,0 +1,147 @@
+package backups
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/backup"
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/schedule"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+// ErrNoSchedule is returned when running scheduled backups without a schedule in the config
+var ErrNoSchedule = errors.New("there is no backup schedule, add backups.schedule to the config")
+
+// lastRunFile is the file in the backups directory with the time of the last scheduled backup
+const lastRunFile = ".last-scheduled-backup"
+
+func runCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Runs the scheduled database backups.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			force := cmd.Flag("force").Value.String() == "true"
+
+			cfg, err := config.Load(home)
+			if err != nil {
+				return err
+			}
+
+			if cfg.Backups.Schedule == "" {
+				return ErrNoSchedule
+			}
+
+			s, err := schedule.Parse(cfg.Backups.Schedule)
+			if err != nil {
+				return err
+			}
+
+			last, err := lastRun(home)
+			if err != nil {
+				return err
+			}
+
+			if !force && !last.IsZero() && !s.Due(last, time.Now()) {
+				output.Info("The next scheduled backup is at", s.Next(last).Format("2006-01-02 15:04"))
+
+				return nil
+			}
+
+			return run(ctx, docker, home, cfg.Backups, output)
+		},
+	}
+
+	cmd.Flags().Bool("force", false, "backup the databases even if the schedule is not due")
+
+	return cmd
+}
+
+// run backs up every database in the running database containers, removes the backups
+// based on the retention policy, and saves the time of the run.
+func run(ctx context.Context, docker client.CommonAPIClient, home string, settings config.Backups, output terminal.Outputer) error {
+	filter := filters.NewArgs()
+	filter.Add("label", containerlabels.Nitro)
+	filter.Add("label", containerlabels.Type+"=database")
+
+	containers, err := docker.ContainerList(ctx, types.ContainerListOptions{Filters: filter})
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+
+	for _, c := range containers {
+		hostname := strings.TrimLeft(c.Names[0], "/")
+		compatibility := c.Labels[containerlabels.DatabaseCompatibility]
+
+		databases, err := backup.Databases(ctx, docker, c.ID, compatibility)
+		if err != nil {
+			return fmt.Errorf("unable to get the databases for %s, %w", hostname, err)
+		}
+
+		for _, db := range databases {
+			opts := &backup.Options{
+				BackupName:    backup.FileName(compatibility, db, start),
+				ContainerID:   c.ID,
+				ContainerName: hostname,
+				Database:      db,
+				Storage:       settings.Storage,
+			}
+
+			opts.Commands = backup.Commands(compatibility, db)
+
+			output.Pending("backing up", db, "on", hostname)
+
+			if err := backup.ToFile(ctx, docker, home, opts); err != nil {
+				output.Warning()
+
+				return fmt.Errorf("unable to backup %s on %s, %w", db, hostname, err)
+			}
+
+			output.Done()
+		}
+	}
+
+	if _, err := backup.RemoveOld(home, backup.Retention{Keep: settings.Keep, KeepDays: settings.KeepDays}, start); err != nil {
+		return err
+	}
+
+	return saveLastRun(home, start)
+}
+
+// lastRun returns the time of the last scheduled backup or a zero time if the scheduled backups have not run.
+func lastRun(home string) (time.Time, error) {
+	data, err := ioutil.ReadFile(filepath.Join(home, config.DirectoryName, "backups", lastRunFile))
+	if os.IsNotExist(err) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Parse(time.RFC3339, strings.TrimSpace(string(data)))
+}
+
+func saveLastRun(home string, t time.Time) error {
+	dir := filepath.Join(home, config.DirectoryName, "backups")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(dir, lastRunFile), []byte(t.Format(time.RFC3339)), 0644)
+}
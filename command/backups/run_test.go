@@ -0,0 +1,44 @@
+package backups
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_lastRun(t *testing.T) {
+	home, err := ioutil.TempDir("", "nitro-backups")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+
+	last, err := lastRun(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !last.IsZero() {
+		t.Errorf("expected a zero time without a previous run, got %v", last)
+	}
+
+	now := time.Date(2021, 3, 3, 12, 0, 0, 0, time.UTC)
+	if err := saveLastRun(home, now); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(home, ".nitro", "backups", lastRunFile)); err != nil {
+		t.Fatal(err)
+	}
+
+	last, err = lastRun(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !last.Equal(now) {
+		t.Errorf("expected the last run %v, got %v", now, last)
+	}
+}
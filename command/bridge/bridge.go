@@ -125,7 +125,7 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 			case false:
 				for k, v := range options {
 					if site == v {
-						target, err = url.Parse(fmt.Sprintf("http://%s", sites[k].Hostname))
+						target, err = url.Parse(cfg.SiteURL("http", sites[k].Hostname))
 						if err != nil {
 							return err
 						}
@@ -145,7 +145,7 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 					// add the label to get the site
 					filter.Add("label", containerlabels.Host+"="+sites[selected].Hostname)
 
-					target, err = url.Parse(fmt.Sprintf("http://%s", sites[selected].Hostname))
+					target, err = url.Parse(cfg.SiteURL("http", sites[selected].Hostname))
 					if err != nil {
 						return err
 					}
@@ -155,7 +155,7 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 					// add the label to get the site
 					filter.Add("label", containerlabels.Host+"="+sites[0].Hostname)
 
-					target, err = url.Parse(fmt.Sprintf("http://%s", sites[0].Hostname))
+					target, err = url.Parse(cfg.SiteURL("http", sites[0].Hostname))
 					if err != nil {
 						return err
 					}
@@ -169,7 +169,7 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 					// add the label to get the site
 					filter.Add("label", containerlabels.Host+"="+sites[selected].Hostname)
 
-					target, err = url.Parse(fmt.Sprintf("http://%s", sites[selected].Hostname))
+					target, err = url.Parse(cfg.SiteURL("http", sites[selected].Hostname))
 					if err != nil {
 						return err
 					}
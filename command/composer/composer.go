@@ -28,16 +28,23 @@ var (
 	ErrNoComposerFile = fmt.Errorf("no composer.json or composer.lock was found")
 )
 
+// cacheVolume is the volume for the composer cache, which is shared by all projects
+const cacheVolume = "nitro_composer_cache"
+
 const exampleText = `  # run composer install in a current directory using a container
   nitro composer install
 
+  # run composer 1 for a project that does not support composer 2
+  nitro composer install --composer-version 1
+
   # use composer (without local installation) to create a new project
   nitro composer create-project craftcms/craft my-project`
 
 // NewCommand returns a new command that runs composer install or update for a directory.
 // This command allows users to skip installing composer on the host machine and will run
-// all the commands in a disposable docker container.
-func NewCommand(docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+// all the commands in a disposable docker container. The composer version is detected from the
+// project and the users auth.json is mounted, so private packages can be installed.
+func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:                "composer",
 		Short:              "Runs a Composer command.",
@@ -45,8 +52,18 @@ func NewCommand(docker client.CommonAPIClient, output terminal.Outputer) *cobra.
 		DisableFlagParsing: true,
 		Args:               cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			var version string
-			version, args = versionFromArgs(args)
+			var version, composerFlag string
+			version, args = flagFromArgs(args, "php-version")
+			composerFlag, args = flagFromArgs(args, "composer-version")
+
+			// if the version is not set, use the default
+			if version == "" {
+				version = "7.4"
+			}
+
+			if len(args) == 0 {
+				return fmt.Errorf("no composer command was provided")
+			}
 
 			ctx := cmd.Context()
 			if ctx == nil {
@@ -86,6 +103,11 @@ func NewCommand(docker client.CommonAPIClient, output terminal.Outputer) *cobra.
 				output.Done()
 			}
 
+			major, err := composerVersion(composerFlag, path)
+			if err != nil {
+				return err
+			}
+
 			image := fmt.Sprintf("docker.io/craftcms/%s:%s-dev", "cli", version)
 
 			// filter for the image ref
@@ -165,6 +187,43 @@ func NewCommand(docker client.CommonAPIClient, output terminal.Outputer) *cobra.
 				pathVolume = volume
 			}
 
+			// the cache is shared by all projects, so repeat installs are fast
+			cacheFilter := filters.NewArgs()
+			cacheFilter.Add("name", cacheVolume)
+
+			caches, err := docker.VolumeList(ctx, cacheFilter)
+			if err != nil {
+				return fmt.Errorf("unable to list the volumes, %w", err)
+			}
+
+			var cache *types.Volume
+			for _, v := range caches.Volumes {
+				if v.Name == cacheVolume {
+					cache = v
+				}
+			}
+
+			if cache == nil {
+				volume, err := docker.VolumeCreate(ctx, volumetypes.VolumeCreateBody{
+					Driver: "local",
+					Name:   cacheVolume,
+					Labels: map[string]string{
+						containerlabels.Nitro: "true",
+						containerlabels.Type:  "composer-cache",
+					},
+				})
+				if err != nil {
+					return fmt.Errorf("unable to create the cache volume, %w", err)
+				}
+
+				cache = &volume
+			}
+
+			auth := authFile(home)
+			if auth != "" {
+				output.Info("Using credentials from", auth)
+			}
+
 			// build the container options
 			opts := &composer.Options{
 				Image:    image,
@@ -174,8 +233,11 @@ func NewCommand(docker client.CommonAPIClient, output terminal.Outputer) *cobra.
 					containerlabels.Type:  "composer",
 					containerlabels.Path:  path,
 				},
-				Volume: &pathVolume,
-				Path:   path,
+				Volume:   &pathVolume,
+				Path:     path,
+				Version:  major,
+				Cache:    cache,
+				AuthFile: auth,
 				NetworkConfig: &network.NetworkingConfig{
 					EndpointsConfig: map[string]*network.EndpointSettings{
 						"nitro-network": {
@@ -226,35 +288,7 @@ func NewCommand(docker client.CommonAPIClient, output terminal.Outputer) *cobra.
 
 	// set flags for the command
 	cmd.Flags().String("php-version", "7.4", "which php version to use")
+	cmd.Flags().String("composer-version", "", "which composer version to use (1 or 2)")
 
 	return cmd
 }
-
-func versionFromArgs(args []string) (string, []string) {
-	var version string
-	var newArgs []string
-	for i, a := range args {
-		// get the version if using =
-		if strings.Contains(a, "--php-version=") {
-			parts := strings.Split(a, "=")
-			version = parts[len(parts)-1]
-			continue
-		}
-
-		// get the version if using a space
-		if a == "--php-version" {
-			version = args[i+1]
-			continue
-		}
-
-		// append the new args
-		newArgs = append(newArgs, a)
-	}
-
-	// if the version is not set, use the default
-	if version == "" {
-		version = "7.4"
-	}
-
-	return version, newArgs
-}
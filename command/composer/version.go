@@ -0,0 +1,152 @@
+package composer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// DefaultComposerVersion is the composer version used when the flag and the project do not set one
+	DefaultComposerVersion = "2"
+
+	// ErrInvalidComposerVersion is returned when the composer version flag is not 1 or 2
+	ErrInvalidComposerVersion = errors.New("the composer version must be 1 or 2")
+
+	// comparatorRegex matches the comparators in a constraint (e.g. >=1.0 or ^2.0)
+	comparatorRegex = regexp.MustCompile(`(<=|>=|<|>|=|\^|~)?\s*v?([0-9]+)`)
+)
+
+// flagFromArgs returns the value of the flag and the args without the flag. Flag parsing is
+// disabled for the command, so all other flags are passed to composer.
+func flagFromArgs(args []string, name string) (string, []string) {
+	var value string
+	var newArgs []string
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+
+		// get the value if using =
+		if strings.HasPrefix(a, "--"+name+"=") {
+			value = strings.TrimPrefix(a, "--"+name+"=")
+			continue
+		}
+
+		// get the value if using a space
+		if a == "--"+name {
+			if i+1 < len(args) {
+				value = args[i+1]
+				i++
+			}
+			continue
+		}
+
+		// append the new args
+		newArgs = append(newArgs, a)
+	}
+
+	return value, newArgs
+}
+
+// composerVersion returns the major composer version for the path. The flag is used first,
+// then the plugin-api-version in composer.lock and the composer-plugin-api requirement in
+// composer.json.
+func composerVersion(flag, path string) (string, error) {
+	if flag != "" {
+		v := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(flag)), "v")
+		v = strings.SplitN(v, ".", 2)[0]
+		if v != "1" && v != "2" {
+			return "", fmt.Errorf("%w, got %q", ErrInvalidComposerVersion, flag)
+		}
+
+		return v, nil
+	}
+
+	// an invalid lock or composer.json is reported by composer
+	if b, err := ioutil.ReadFile(filepath.Join(path, "composer.lock")); err == nil {
+		var lock struct {
+			PluginAPIVersion string `json:"plugin-api-version"`
+		}
+
+		if err := json.Unmarshal(b, &lock); err == nil {
+			// composer 1 before 1.10 did not add the plugin api version
+			if lock.PluginAPIVersion == "" || strings.HasPrefix(lock.PluginAPIVersion, "1.") {
+				return "1", nil
+			}
+
+			return "2", nil
+		}
+	}
+
+	if b, err := ioutil.ReadFile(filepath.Join(path, "composer.json")); err == nil {
+		var pkg struct {
+			Require    map[string]string `json:"require"`
+			RequireDev map[string]string `json:"require-dev"`
+		}
+
+		if err := json.Unmarshal(b, &pkg); err == nil {
+			constraint := pkg.Require["composer-plugin-api"]
+			if constraint == "" {
+				constraint = pkg.RequireDev["composer-plugin-api"]
+			}
+
+			// use composer 1 when the plugin api does not allow 2
+			if v := highestMajor(constraint); v == "1" {
+				return "1", nil
+			}
+		}
+	}
+
+	return DefaultComposerVersion, nil
+}
+
+// highestMajor returns the highest major version allowed by the constraint (e.g. ^1.0 || ^2.0
+// returns 2), or an empty string when the constraint has no lower bound.
+func highestMajor(constraint string) string {
+	var highest int
+	for _, set := range strings.Split(constraint, "||") {
+		for _, m := range comparatorRegex.FindAllStringSubmatch(set, -1) {
+			// only use the lower bound of the set
+			if m[1] == "<" || m[1] == "<=" {
+				continue
+			}
+
+			if major, err := strconv.Atoi(m[2]); err == nil && major > highest {
+				highest = major
+			}
+
+			break
+		}
+	}
+
+	if highest == 0 {
+		return ""
+	}
+
+	return strconv.Itoa(highest)
+}
+
+// authFile returns the path to the users auth.json, from the COMPOSER_HOME or the default
+// composer home directories. An empty string is returned when there is no auth.json.
+func authFile(home string) string {
+	var dirs []string
+	if dir := os.Getenv("COMPOSER_HOME"); dir != "" {
+		dirs = append(dirs, dir)
+	}
+
+	dirs = append(dirs, filepath.Join(home, ".composer"), filepath.Join(home, ".config", "composer"))
+
+	for _, dir := range dirs {
+		file := filepath.Join(dir, "auth.json")
+		if info, err := os.Stat(file); err == nil && !info.IsDir() {
+			return file
+		}
+	}
+
+	return ""
+}
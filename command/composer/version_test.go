@@ -0,0 +1,202 @@
+package composer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_flagFromArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		flag     string
+		want     string
+		wantArgs []string
+	}{
+		{
+			name:     "flags with an equals sign are removed",
+			args:     []string{"install", "--composer-version=1", "--no-dev"},
+			flag:     "composer-version",
+			want:     "1",
+			wantArgs: []string{"install", "--no-dev"},
+		},
+		{
+			name:     "flags with a space are removed",
+			args:     []string{"--php-version", "8.0", "update"},
+			flag:     "php-version",
+			want:     "8.0",
+			wantArgs: []string{"update"},
+		},
+		{
+			name:     "other flags are kept",
+			args:     []string{"install", "--php-version", "8.0"},
+			flag:     "composer-version",
+			wantArgs: []string{"install", "--php-version", "8.0"},
+		},
+		{
+			name:     "flags without a value are removed",
+			args:     []string{"install", "--composer-version"},
+			flag:     "composer-version",
+			wantArgs: []string{"install"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, args := flagFromArgs(tt.args, tt.flag)
+			if got != tt.want {
+				t.Errorf("flagFromArgs() got = %v, want %v", got, tt.want)
+			}
+
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("flagFromArgs() args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func Test_composerVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		flag     string
+		lock     string
+		composer string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name: "the flag is used first",
+			flag: "1",
+			lock: `{"plugin-api-version": "2.1.0"}`,
+			want: "1",
+		},
+		{
+			name: "the major version of the flag is used",
+			flag: "v2.2",
+			want: "2",
+		},
+		{
+			name:    "invalid flags return an error",
+			flag:    "3",
+			wantErr: true,
+		},
+		{
+			name:     "the lock file is used before composer.json",
+			lock:     `{"plugin-api-version": "2.1.0"}`,
+			composer: `{"require": {"composer-plugin-api": "^1.0"}}`,
+			want:     "2",
+		},
+		{
+			name: "lock files from composer 1 use composer 1",
+			lock: `{"plugin-api-version": "1.1.0"}`,
+			want: "1",
+		},
+		{
+			name: "lock files without a plugin api version use composer 1",
+			lock: `{"packages": []}`,
+			want: "1",
+		},
+		{
+			name:     "the plugin api requirement in composer.json is used",
+			composer: `{"require": {"composer-plugin-api": ">=1.0 <2.0"}}`,
+			want:     "1",
+		},
+		{
+			name:     "requirements that allow composer 2 use composer 2",
+			composer: `{"require-dev": {"composer-plugin-api": "^1.0 || ^2.0"}}`,
+			want:     "2",
+		},
+		{
+			name:     "projects without a version use the default",
+			composer: `{"require": {"craftcms/cms": "^3.7"}}`,
+			want:     DefaultComposerVersion,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			if tt.lock != "" {
+				if err := ioutil.WriteFile(filepath.Join(dir, "composer.lock"), []byte(tt.lock), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if tt.composer != "" {
+				if err := ioutil.WriteFile(filepath.Join(dir, "composer.json"), []byte(tt.composer), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := composerVersion(tt.flag, dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("composerVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("composerVersion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_authFile(t *testing.T) {
+	tests := []struct {
+		name         string
+		composerHome bool
+		files        []string
+		want         string
+	}{
+		{
+			name:  "the composer directory is used",
+			files: []string{".composer/auth.json", ".config/composer/auth.json"},
+			want:  ".composer/auth.json",
+		},
+		{
+			name:  "the config directory is used",
+			files: []string{".config/composer/auth.json"},
+			want:  ".config/composer/auth.json",
+		},
+		{
+			name:         "the composer home is used first",
+			composerHome: true,
+			files:        []string{"custom/auth.json", ".composer/auth.json"},
+			want:         "custom/auth.json",
+		},
+		{
+			name: "missing files return an empty string",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+
+			t.Setenv("COMPOSER_HOME", "")
+			if tt.composerHome {
+				t.Setenv("COMPOSER_HOME", filepath.Join(home, "custom"))
+			}
+
+			for _, f := range tt.files {
+				file := filepath.Join(home, f)
+				if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+					t.Fatal(err)
+				}
+
+				if err := ioutil.WriteFile(file, []byte("{}"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want := tt.want
+			if want != "" {
+				want = filepath.Join(home, want)
+			}
+
+			if got := authFile(home); got != want {
+				t.Errorf("authFile() = %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,345 @@
+package context
+
+import (
+	"archive/zip"
+	stdcontext "context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"gopkg.in/yaml.v3"
+
+	"github.com/craftcms/nitro/command/version"
+	"github.com/craftcms/nitro/pkg/backup"
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+const (
+	// archiveFormat is the version of the archive layout, it is increased
+	// when the layout changes in a way older versions cannot import
+	archiveFormat = 1
+
+	// manifestName is the name of the file with the archive metadata
+	manifestName = "manifest.json"
+
+	// configName is the name of the config file in the archive, the config
+	// for any environment is stored with the default name
+	configName = "nitro.yaml"
+
+	// backupsDir is the directory in the archive and ~/.nitro for backups
+	backupsDir = "backups"
+)
+
+var (
+	// ErrInvalidArchive is returned when the archive is missing files or contains unsafe paths
+	ErrInvalidArchive = errors.New("the file is not a valid nitro context archive")
+
+	// ErrUnsupportedArchive is returned when the archive was created with a newer format
+	ErrUnsupportedArchive = errors.New("the archive format is not supported")
+
+	// ErrConfigExists is returned when importing would replace the config for an existing environment
+	ErrConfigExists = errors.New("the environment already exists")
+)
+
+// manifest is the metadata stored in an archive.
+type manifest struct {
+	Format    int       `json:"format"`
+	Version   string    `json:"nitro_version"`
+	CreatedAt time.Time `json:"created_at"`
+	Backups   []string  `json:"backups,omitempty"`
+}
+
+// exportArchive writes the config and the backups to a zip archive. The blackfire
+// credentials are removed because they are specific to each user.
+func exportArchive(home string, w io.Writer) (*manifest, error) {
+	cfg, err := config.Load(home)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.Blackfire.ServerID = ""
+	cfg.Blackfire.ServerToken = ""
+
+	// keep the environment variable references so the secrets are not in the archive
+	data, err := cfg.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &manifest{
+		Format:    archiveFormat,
+		Version:   version.Version,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	// find all of the backups
+	root := filepath.Join(home, config.DirectoryName, backupsDir)
+	if err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// there are no backups
+			if os.IsNotExist(err) && path == root {
+				return nil
+			}
+
+			return err
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		m.Backups = append(m.Backups, filepath.ToSlash(rel))
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("unable to find the backups, %w", err)
+	}
+
+	sort.Strings(m.Backups)
+
+	meta, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	zw := zip.NewWriter(w)
+
+	for _, f := range []struct {
+		name    string
+		content []byte
+	}{{manifestName, meta}, {configName, data}} {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := fw.Write(f.content); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, b := range m.Backups {
+		if err := addFile(zw, backupsDir+"/"+b, filepath.Join(root, filepath.FromSlash(b))); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, zw.Close()
+}
+
+// importArchive restores the config from an archive as the config for the environment and the
+// backups into the backups directory. The config for an existing environment is only replaced
+// when force is true.
+func importArchive(home, file, env string, force bool) (*manifest, error) {
+	path, err := config.EnvironmentFile(home, env)
+	if err != nil {
+		return nil, err
+	}
+
+	zr, err := zip.OpenReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("%w, %s", ErrInvalidArchive, err)
+	}
+	defer zr.Close()
+
+	files := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		// prevent writing files outside of the nitro directory
+		name := filepath.ToSlash(filepath.Clean(f.Name))
+		if filepath.IsAbs(f.Name) || strings.HasPrefix(name, "../") || name == ".." {
+			return nil, fmt.Errorf("%w, the path %s is not allowed", ErrInvalidArchive, f.Name)
+		}
+
+		files[name] = f
+	}
+
+	// check the manifest
+	if files[manifestName] == nil || files[configName] == nil {
+		return nil, fmt.Errorf("%w, missing %s or %s", ErrInvalidArchive, manifestName, configName)
+	}
+
+	meta, err := readFile(files[manifestName])
+	if err != nil {
+		return nil, err
+	}
+
+	m := &manifest{}
+	if err := json.Unmarshal(meta, m); err != nil {
+		return nil, fmt.Errorf("%w, unable to read the manifest, %s", ErrInvalidArchive, err)
+	}
+
+	if m.Format < 1 || m.Format > archiveFormat {
+		return nil, fmt.Errorf("%w, the archive uses format %d and this version of nitro supports format %d", ErrUnsupportedArchive, m.Format, archiveFormat)
+	}
+
+	// check the config is valid before writing any files
+	data, err := readFile(files[configName])
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg config.Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("%w, unable to read the config, %s", ErrInvalidArchive, err)
+	}
+
+	// check the backups are in the archive
+	for _, b := range m.Backups {
+		if _, ok := files[backupsDir+"/"+filepath.ToSlash(filepath.Clean(b))]; !ok {
+			return nil, fmt.Errorf("%w, missing the backup %s", ErrInvalidArchive, b)
+		}
+	}
+
+	dir := filepath.Join(home, config.DirectoryName)
+	if _, err := os.Stat(path); err == nil && !force {
+		return nil, ErrConfigExists
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		return nil, err
+	}
+
+	// restore the backups
+	for _, b := range m.Backups {
+		content, err := readFile(files[backupsDir+"/"+filepath.ToSlash(filepath.Clean(b))])
+		if err != nil {
+			return nil, err
+		}
+
+		path := filepath.Join(dir, backupsDir, filepath.FromSlash(b))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, err
+		}
+
+		if err := ioutil.WriteFile(path, content, 0644); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// newestBackups returns the newest backup for each database from the archived backups. The
+// backups must be sorted with the newest first, as backup.List returns them.
+func newestBackups(backups []backup.File, archived []string) []backup.File {
+	imported := make(map[string]bool)
+	for _, b := range archived {
+		imported[b] = true
+	}
+
+	seen := make(map[string]bool)
+
+	var newest []backup.File
+	for _, b := range backups {
+		key := b.Container + "/" + b.Database
+		if !imported[b.Container+"/"+b.Name] || seen[key] {
+			continue
+		}
+
+		seen[key] = true
+		newest = append(newest, b)
+	}
+
+	return newest
+}
+
+// restoreBackups restores the newest archived backup for each database into the running database
+// containers and returns the number of restored backups. Backups for database containers that are
+// not running are skipped.
+func restoreBackups(ctx stdcontext.Context, docker client.ContainerAPIClient, home string, archived []string, output terminal.Outputer) (int, error) {
+	backups, err := backup.List(home)
+	if err != nil {
+		return 0, fmt.Errorf("unable to find the backups, %w", err)
+	}
+
+	filter := filters.NewArgs()
+	filter.Add("label", containerlabels.Nitro)
+	filter.Add("label", containerlabels.Type+"=database")
+
+	containers, err := docker.ContainerList(ctx, types.ContainerListOptions{Filters: filter})
+	if err != nil {
+		return 0, fmt.Errorf("unable to list the database containers, %w", err)
+	}
+
+	running := make(map[string]types.Container)
+	for _, c := range containers {
+		running[strings.TrimLeft(c.Names[0], "/")] = c
+	}
+
+	restored := 0
+	for _, b := range newestBackups(backups, archived) {
+		c, ok := running[b.Container]
+		if !ok {
+			output.Info("Warning:", "unable to find a running database container for", b.Container+", skipping", b.Name)
+
+			continue
+		}
+
+		output.Pending("restoring", b.Name, "into", b.Database)
+
+		if _, err := backup.Restore(ctx, docker, c.ID, b.Path, backup.RestoreCommands(c.Labels[containerlabels.DatabaseCompatibility], b.Database, b.Database, "/tmp/"+b.Name)); err != nil {
+			output.Warning()
+
+			return restored, fmt.Errorf("unable to restore %s on %s, %w", b.Name, b.Container, err)
+		}
+
+		// remove the backup from the container
+		if _, err := backup.Exec(ctx, docker, c.ID, []string{"rm", "-f", "/tmp/" + b.Name}); err != nil {
+			output.Info("Warning:", "unable to remove the backup from the container,", err.Error())
+		}
+
+		output.Done()
+
+		restored++
+	}
+
+	return restored, nil
+}
+
+func addFile(zw *zip.Writer, name, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(w, f)
+
+	return err
+}
+
+func readFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return ioutil.ReadAll(rc)
+}
@@ -0,0 +1,234 @@
+package context
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/craftcms/nitro/pkg/backup"
+	"github.com/craftcms/nitro/pkg/config"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nitro-context")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// create the config and a backup to export
+	from := filepath.Join(dir, "from")
+	backup := filepath.Join("mysql-8.0-3306.database.nitro", "nitro.sql")
+	dump := []byte("CREATE TABLE `users` (`id` int);\n")
+
+	if err := os.MkdirAll(filepath.Join(from, ".nitro", "backups", "mysql-8.0-3306.database.nitro"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := []byte(`blackfire:
+  server_id: my-server-id
+  server_token: my-server-token
+databases:
+  - engine: mysql
+    version: "8.0"
+    port: "3306"
+sites:
+  - hostname: tutorial.nitro
+    path: ~/dev/tutorial
+    version: "7.4"
+    webroot: web
+`)
+	if err := ioutil.WriteFile(filepath.Join(from, ".nitro", "nitro.yaml"), cfg, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(from, ".nitro", "backups", backup), dump, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// export the context
+	file := filepath.Join(dir, "context.zip")
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exported, err := exportArchive(from, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	if want := []string{"mysql-8.0-3306.database.nitro/nitro.sql"}; !reflect.DeepEqual(exported.Backups, want) {
+		t.Errorf("expected the backups to be %v, got %v", want, exported.Backups)
+	}
+
+	// import into an environment in a new home directory
+	to := filepath.Join(dir, "to")
+	imported, err := importArchive(to, file, "work", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if imported.Format != archiveFormat {
+		t.Errorf("expected the format to be %d, got %d", archiveFormat, imported.Format)
+	}
+
+	got, err := config.LoadEnvironment(to, "work")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got.Sites) != 1 || got.Sites[0].Hostname != "tutorial.nitro" || got.Sites[0].Path != "~/dev/tutorial" {
+		t.Errorf("expected the sites to be imported, got %v", got.Sites)
+	}
+
+	if len(got.Databases) != 1 || got.Databases[0].Engine != "mysql" {
+		t.Errorf("expected the databases to be imported, got %v", got.Databases)
+	}
+
+	if got.Blackfire.ServerID != "" || got.Blackfire.ServerToken != "" {
+		t.Errorf("expected the blackfire credentials to be removed, got %v", got.Blackfire)
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(to, ".nitro", "backups", backup))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(content, dump) {
+		t.Errorf("expected the backup to be %q, got %q", dump, content)
+	}
+
+	// importing into the same environment replaces the config only with force
+	if _, err := importArchive(to, file, "work", false); !errors.Is(err, ErrConfigExists) {
+		t.Errorf("expected ErrConfigExists, got %v", err)
+	}
+
+	if _, err := importArchive(to, file, "work", true); err != nil {
+		t.Errorf("expected force to replace the config, got %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(to, ".nitro", "nitro.yaml")); !os.IsNotExist(err) {
+		t.Errorf("expected the default config to not be written, got %v", err)
+	}
+
+	if _, err := importArchive(to, file, "../work", false); !errors.Is(err, config.ErrInvalidEnvironment) {
+		t.Errorf("expected ErrInvalidEnvironment, got %v", err)
+	}
+}
+
+func TestImportArchiveValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr error
+	}{
+		{
+			name:    "archives without a manifest are invalid",
+			files:   map[string]string{"nitro.yaml": "sites: []"},
+			wantErr: ErrInvalidArchive,
+		},
+		{
+			name:    "archives without a config are invalid",
+			files:   map[string]string{"manifest.json": `{"format": 1}`},
+			wantErr: ErrInvalidArchive,
+		},
+		{
+			name:    "newer formats are not supported",
+			files:   map[string]string{"manifest.json": `{"format": 2}`, "nitro.yaml": "sites: []"},
+			wantErr: ErrUnsupportedArchive,
+		},
+		{
+			name:    "paths outside of the archive are invalid",
+			files:   map[string]string{"manifest.json": `{"format": 1}`, "nitro.yaml": "sites: []", "../evil.sql": ""},
+			wantErr: ErrInvalidArchive,
+		},
+		{
+			name:    "missing backups are invalid",
+			files:   map[string]string{"manifest.json": `{"format": 1, "backups": ["mysql/nitro.sql"]}`, "nitro.yaml": "sites: []"},
+			wantErr: ErrInvalidArchive,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "nitro-context")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			file := filepath.Join(dir, "context.zip")
+			f, err := os.Create(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			zw := zip.NewWriter(f)
+			for name, content := range tt.files {
+				w, err := zw.Create(name)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if _, err := w.Write([]byte(content)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			zw.Close()
+			f.Close()
+
+			if _, err := importArchive(filepath.Join(dir, "home"), file, "work", false); !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected the error to be %v, got %v", tt.wantErr, err)
+			}
+
+			// nothing should be written for invalid archives
+			if _, err := os.Stat(filepath.Join(dir, "home")); !os.IsNotExist(err) {
+				t.Errorf("expected no files to be written, got %v", err)
+			}
+		})
+	}
+}
+
+func Test_newestBackups(t *testing.T) {
+	backups := []backup.File{
+		{Container: "mysql-8.0-3306", Database: "craft", Name: "craft-2021-03-03-120000.sql.gz"},
+		{Container: "mysql-8.0-3306", Database: "craft", Name: "craft-2021-03-02-120000.sql.gz"},
+		{Container: "mysql-8.0-3306", Database: "other", Name: "other-2021-03-03-120000.sql.gz"},
+		{Container: "postgres-13-5432", Database: "craft", Name: "craft-2021-03-01-120000.sql.gz"},
+	}
+
+	tests := []struct {
+		name     string
+		archived []string
+		want     []backup.File
+	}{
+		{
+			name:     "only the newest backup for each database is restored",
+			archived: []string{"mysql-8.0-3306/craft-2021-03-03-120000.sql.gz", "mysql-8.0-3306/craft-2021-03-02-120000.sql.gz", "postgres-13-5432/craft-2021-03-01-120000.sql.gz"},
+			want:     []backup.File{backups[0], backups[3]},
+		},
+		{
+			name:     "backups that were not in the archive are ignored",
+			archived: []string{"mysql-8.0-3306/craft-2021-03-02-120000.sql.gz"},
+			want:     []backup.File{backups[1]},
+		},
+		{
+			name:     "no archived backups",
+			archived: nil,
+			want:     nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newestBackups(backups, tt.archived); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newestBackups() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
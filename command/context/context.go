@@ -6,7 +6,6 @@ import (
 
 	"github.com/docker/docker/client"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
 
 	"github.com/craftcms/nitro/pkg/config"
 	"github.com/craftcms/nitro/pkg/terminal"
@@ -16,7 +15,16 @@ const exampleText = `  # view all resources for the environment
   nitro context
 
   # show only the config file
-  nitro context --yaml`
+  nitro context --yaml
+
+  # show the environment as json
+  nitro context --output json
+
+  # export the config and backups to share the environment
+  nitro context export nitro-context.zip
+
+  # import an exported environment as the work environment
+  nitro context import nitro-context.zip work`
 
 func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
 	cmd := &cobra.Command{
@@ -30,11 +38,20 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 				return err
 			}
 
+			format := cmd.Flag("output").Value.String()
+			if err := terminal.ValidateFormat(format); err != nil {
+				return err
+			}
+
 			// if they are asking for yaml, show only the yaml
 			if cmd.Flag("yaml").Value.String() == "true" {
 				return yamlFmt(cfg)
 			}
 
+			if format == terminal.FormatJSON {
+				return terminal.JSON(cmd.OutOrStdout(), environment(cfg, cmd.Root().Version))
+			}
+
 			output.Info("Craft Nitro", cmd.Root().Version)
 			output.Info("")
 			output.Info("Configuration:\t", cfg.File)
@@ -43,6 +60,7 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 			output.Info(`Sites:`)
 			for _, site := range cfg.Sites {
 				output.Info("  hostname:\t", site.Hostname)
+				output.Info("  url:\t", cfg.SiteURL("https", site.Hostname))
 				if len(site.Aliases) > 0 {
 					output.Info("  aliases:\t", strings.Join(site.Aliases, ", "))
 				}
@@ -66,10 +84,77 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 	}
 
 	cmd.Flags().Bool("yaml", false, "show the config file")
+	cmd.Flags().StringP("output", "o", terminal.FormatText, "the output format (text or json)")
+
+	cmd.AddCommand(
+		exportCommand(home, output),
+		importCommand(home, docker, output),
+	)
 
 	return cmd
 }
 
+// contextInfo is the environment information for the json output.
+type contextInfo struct {
+	Version       string         `json:"version"`
+	Configuration string         `json:"configuration"`
+	Sites         []siteInfo     `json:"sites"`
+	Databases     []databaseInfo `json:"databases"`
+}
+
+type siteInfo struct {
+	Hostname string   `json:"hostname"`
+	URL      string   `json:"url"`
+	Aliases  []string `json:"aliases,omitempty"`
+	PHP      string   `json:"php"`
+	Webroot  string   `json:"webroot"`
+	Path     string   `json:"path"`
+}
+
+type databaseInfo struct {
+	Engine   string `json:"engine"`
+	Version  string `json:"version"`
+	Hostname string `json:"hostname"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Port     string `json:"port"`
+}
+
+// environment returns the same information as the text output for the json output.
+func environment(cfg *config.Config, version string) contextInfo {
+	info := contextInfo{
+		Version:       version,
+		Configuration: cfg.File,
+		Sites:         []siteInfo{},
+		Databases:     []databaseInfo{},
+	}
+
+	for _, site := range cfg.Sites {
+		info.Sites = append(info.Sites, siteInfo{
+			Hostname: site.Hostname,
+			URL:      cfg.SiteURL("https", site.Hostname),
+			Aliases:  site.Aliases,
+			PHP:      site.Version,
+			Webroot:  site.Webroot,
+			Path:     site.Path,
+		})
+	}
+
+	for _, db := range cfg.Databases {
+		hostname, _ := db.GetHostname()
+		info.Databases = append(info.Databases, databaseInfo{
+			Engine:   db.Engine,
+			Version:  db.Version,
+			Hostname: hostname,
+			Username: "nitro",
+			Password: "nitro",
+			Port:     db.Port,
+		})
+	}
+
+	return info
+}
+
 func yamlFmt(cfg *config.Config) error {
 	// redact blackfire credentials
 	if cfg.Blackfire.ServerID != "" {
@@ -79,8 +164,8 @@ func yamlFmt(cfg *config.Config) error {
 		cfg.Blackfire.ServerToken = "********************************"
 	}
 
-	// marshal into the struct version so we can remove the blackfire credentials
-	data, err := yaml.Marshal(cfg)
+	// marshal with the environment variable references instead of their values
+	data, err := cfg.Marshal()
 	if err != nil {
 		return err
 	}
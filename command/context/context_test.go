@@ -0,0 +1,59 @@
+package context
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/craftcms/nitro/pkg/config"
+)
+
+func Test_environment(t *testing.T) {
+	cfg := &config.Config{
+		File: "/home/user/.nitro/nitro.yaml",
+		Sites: []config.Site{
+			{
+				Hostname: "tutorial.nitro",
+				Aliases:  []string{"alias.nitro"},
+				Path:     "~/dev/tutorial",
+				Version:  "7.4",
+				Webroot:  "web",
+			},
+		},
+		Databases: []config.Database{
+			{
+				Engine:  "mysql",
+				Version: "8.0",
+				Port:    "3306",
+			},
+		},
+	}
+
+	want := contextInfo{
+		Version:       "2.0.0",
+		Configuration: "/home/user/.nitro/nitro.yaml",
+		Sites: []siteInfo{
+			{
+				Hostname: "tutorial.nitro",
+				URL:      "https://tutorial.nitro",
+				Aliases:  []string{"alias.nitro"},
+				PHP:      "7.4",
+				Webroot:  "web",
+				Path:     "~/dev/tutorial",
+			},
+		},
+		Databases: []databaseInfo{
+			{
+				Engine:   "mysql",
+				Version:  "8.0",
+				Hostname: "mysql-8.0-3306.database.nitro",
+				Username: "nitro",
+				Password: "nitro",
+				Port:     "3306",
+			},
+		},
+	}
+
+	if got := environment(cfg, "2.0.0"); !reflect.DeepEqual(got, want) {
+		t.Errorf("environment() = %v, want %v", got, want)
+	}
+}
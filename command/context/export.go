@@ -0,0 +1,104 @@
+package context
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/datetime"
+	"github.com/craftcms/nitro/pkg/prompt"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+func exportCommand(home string, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export [file]",
+		Short: "Exports the config and backups.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file := fmt.Sprintf("nitro-context-%s.zip", datetime.Parse(time.Now()))
+			if len(args) > 0 {
+				file = args[0]
+			}
+
+			f, err := os.Create(file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			output.Pending("exporting to", file)
+
+			m, err := exportArchive(home, f)
+			if err != nil {
+				output.Warning()
+
+				return fmt.Errorf("unable to export the context, %w", err)
+			}
+
+			output.Done()
+
+			output.Info(fmt.Sprintf("Exported the config and %d backups to %s 📦", len(m.Backups), file))
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func importCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import <file> <environment>",
+		Short: "Imports an exported config and backups as an environment.",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, env := args[0], args[1]
+
+			output.Pending("importing", file)
+
+			m, err := importArchive(home, file, env, cmd.Flag("force").Value.String() == "true")
+			if errors.Is(err, ErrConfigExists) {
+				output.Warning()
+
+				return fmt.Errorf("%w, use --force to replace it", err)
+			}
+			if err != nil {
+				output.Warning()
+
+				return fmt.Errorf("unable to import the context, %w", err)
+			}
+
+			output.Done()
+
+			output.Info(fmt.Sprintf("Imported the config and %d backups from Nitro %s as the %s environment", len(m.Backups), m.Version, env))
+
+			// create the database containers for the environment
+			if err := config.UseEnvironment(env); err != nil {
+				return err
+			}
+
+			if err := prompt.RunApply(cmd, nil, true, output); err != nil {
+				return err
+			}
+
+			restored, err := restoreBackups(cmd.Context(), docker, home, m.Backups, output)
+			if err != nil {
+				return err
+			}
+
+			output.Info(fmt.Sprintf("Restored %d databases, use `--env %s` to work with the environment.", restored, env))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("force", false, "replace the config for an existing environment")
+
+	return cmd
+}
@@ -0,0 +1,67 @@
+package cp
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_writeArchive(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "logs")
+	if err := os.MkdirAll(filepath.Join(src, "queue"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(src, "queue", "queue.log"), []byte("job done"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := writeArchive(buf, src, "storage-logs", 1000, 1000); err != nil {
+		t.Fatal(err)
+	}
+
+	// extract the archive to a new name
+	dest := filepath.Join(t.TempDir(), "backup")
+	if err := extractArchive(bytes.NewReader(buf.Bytes()), dest); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dest, "queue", "queue.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(b) != "job done" {
+		t.Errorf("expected the file to be copied, got %q", b)
+	}
+
+	// the owner is set for the container
+	tr := tar.NewReader(bytes.NewReader(buf.Bytes()))
+	header, err := tr.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if header.Name != "storage-logs" || header.Uid != 1000 || header.Gid != 1000 {
+		t.Errorf("expected the root to be storage-logs owned by 1000, got %s owned by %d", header.Name, header.Uid)
+	}
+}
+
+func Test_extractArchive(t *testing.T) {
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	if err := tw.WriteHeader(&tar.Header{Name: "logs/../../../escape.txt", Mode: 0644, Size: 1, Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write([]byte("x")); err != nil {
+		t.Fatal(err)
+	}
+	tw.Close()
+
+	if err := extractArchive(buf, filepath.Join(t.TempDir(), "logs")); err == nil {
+		t.Errorf("expected an error for a path outside of the destination")
+	}
+}
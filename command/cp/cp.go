@@ -0,0 +1,203 @@
+package cp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+var (
+	// ErrNoContainer is returned when neither path is in a container
+	ErrNoContainer = fmt.Errorf("one of the paths must be in a container (e.g. tutorial.nitro:storage)")
+
+	// ErrBothContainers is returned when both paths are in a container
+	ErrBothContainers = fmt.Errorf("copying between containers is not supported, one of the paths must be on the host")
+)
+
+const exampleText = `  # copy a database dump into a site, relative paths are from the project
+  nitro cp ./dump.sql tutorial.nitro:storage/
+
+  # copy the logs from a site into the current directory
+  nitro cp tutorial.nitro:storage/logs .
+
+  # copy a file into a database container
+  nitro cp ./dump.sql mysql-8.0-3306.database.nitro:/tmp/dump.sql`
+
+// NewCommand returns the command to copy files and directories between the host and a container
+// using the sites hostname or the container name.
+func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "cp <src> <dest>",
+		Short:   "Copies files to and from a container.",
+		Example: exampleText,
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			srcContainer, src := splitPath(args[0])
+			destContainer, dest := splitPath(args[1])
+
+			switch {
+			case srcContainer != "" && destContainer != "":
+				return ErrBothContainers
+			case srcContainer == "" && destContainer == "":
+				return ErrNoContainer
+			}
+
+			cfg, err := config.Load(home)
+			if err != nil {
+				return err
+			}
+
+			name := srcContainer
+			if name == "" {
+				name = destContainer
+			}
+
+			c, base, err := findContainer(cmd.Context(), docker, cfg, name)
+			if err != nil {
+				return err
+			}
+
+			output.Pending("copying", args[0], "to", args[1])
+
+			if destContainer != "" {
+				err = copyTo(cmd.Context(), docker, c.ID, src, containerPath(base, dest), strings.HasSuffix(dest, "/"))
+			} else {
+				err = copyFrom(cmd.Context(), docker, c.ID, containerPath(base, src), dest)
+			}
+
+			if err != nil {
+				output.Warning()
+
+				return err
+			}
+
+			output.Done()
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// splitPath returns the container and path from an argument (e.g. tutorial.nitro:storage). Paths
+// that start with a . or /, or a Windows drive, are always on the host.
+func splitPath(arg string) (string, string) {
+	if strings.HasPrefix(arg, ".") || strings.HasPrefix(arg, "/") || filepath.VolumeName(arg) != "" {
+		return "", arg
+	}
+
+	sp := strings.SplitN(arg, ":", 2)
+	if len(sp) != 2 || sp[0] == "" {
+		return "", arg
+	}
+
+	return sp[0], sp[1]
+}
+
+// containerPath returns the absolute path in the container, relative paths are joined with base.
+func containerPath(base, p string) string {
+	if path.IsAbs(p) {
+		return path.Clean(p)
+	}
+
+	return path.Join(base, p)
+}
+
+// findContainer returns the container for a site hostname or container name. The base is the
+// directory that relative paths are from, which is the project for sites.
+func findContainer(ctx context.Context, docker client.ContainerAPIClient, cfg *config.Config, name string) (types.Container, string, error) {
+	filter := filters.NewArgs()
+	filter.Add("label", containerlabels.Nitro)
+
+	containers, err := docker.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: filter})
+	if err != nil {
+		return types.Container{}, "", fmt.Errorf("unable to list the containers, %w", err)
+	}
+
+	for _, c := range containers {
+		if host := c.Labels[containerlabels.Host]; host == name {
+			if _, err := cfg.FindSiteByHostName(host); err == nil {
+				return c, "/app", nil
+			}
+
+			return c, "/", nil
+		}
+
+		for _, n := range c.Names {
+			if strings.TrimLeft(n, "/") == name {
+				return c, "/", nil
+			}
+		}
+	}
+
+	return types.Container{}, "", fmt.Errorf("unable to find a container for %s…\n run `nitro ls` to show the sites and containers", name)
+}
+
+// copyTo copies the file or directory on the host into the container. When dest is a directory,
+// or isDir is true, the file is copied into the directory. Otherwise the file is copied to dest.
+func copyTo(ctx context.Context, docker client.ContainerAPIClient, id, src, dest string, isDir bool) error {
+	if _, err := os.Stat(src); err != nil {
+		return fmt.Errorf("unable to find %s, %w", src, err)
+	}
+
+	dir, name := dest, filepath.Base(src)
+	stat, err := docker.ContainerStatPath(ctx, id, dest)
+	switch {
+	case err == nil && stat.Mode.IsDir():
+		// copy into the existing directory
+	case isDir:
+		return fmt.Errorf("the directory %s does not exist in the container", dest)
+	default:
+		dir, name = path.Dir(dest), path.Base(dest)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writeArchive(pw, src, name, os.Getuid(), os.Getgid()))
+	}()
+
+	if err := docker.CopyToContainer(ctx, id, dir, pr, types.CopyToContainerOptions{CopyUIDGID: true}); err != nil {
+		pr.CloseWithError(err)
+
+		return fmt.Errorf("unable to copy %s to the container, %w", src, err)
+	}
+
+	return nil
+}
+
+// copyFrom copies the file or directory from the container to the host. When dest is an existing
+// directory, the file is copied into the directory.
+func copyFrom(ctx context.Context, docker client.ContainerAPIClient, id, src, dest string) error {
+	rdr, stat, err := docker.CopyFromContainer(ctx, id, src)
+	if err != nil {
+		return fmt.Errorf("unable to copy %s from the container, %w", src, err)
+	}
+	defer rdr.Close()
+
+	if strings.HasSuffix(dest, "/") || strings.HasSuffix(dest, string(os.PathSeparator)) {
+		if err := os.MkdirAll(dest, 0755); err != nil {
+			return err
+		}
+	}
+
+	target := dest
+	if info, err := os.Stat(dest); err == nil && info.IsDir() {
+		target = filepath.Join(dest, stat.Name)
+	}
+
+	return extractArchive(rdr, target)
+}
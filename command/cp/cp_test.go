@@ -0,0 +1,75 @@
+package cp
+
+import "testing"
+
+func Test_splitPath(t *testing.T) {
+	tests := []struct {
+		name          string
+		arg           string
+		wantContainer string
+		wantPath      string
+	}{
+		{
+			name:          "site paths are split",
+			arg:           "tutorial.nitro:storage/logs",
+			wantContainer: "tutorial.nitro",
+			wantPath:      "storage/logs",
+		},
+		{
+			name:          "absolute container paths are split",
+			arg:           "mysql-8.0-3306.database.nitro:/tmp/dump.sql",
+			wantContainer: "mysql-8.0-3306.database.nitro",
+			wantPath:      "/tmp/dump.sql",
+		},
+		{
+			name:     "relative host paths are not split",
+			arg:      "./backups:2021.sql",
+			wantPath: "./backups:2021.sql",
+		},
+		{
+			name:     "paths without a container are on the host",
+			arg:      "dump.sql",
+			wantPath: "dump.sql",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			container, path := splitPath(tt.arg)
+			if container != tt.wantContainer {
+				t.Errorf("splitPath() container = %v, want %v", container, tt.wantContainer)
+			}
+			if path != tt.wantPath {
+				t.Errorf("splitPath() path = %v, want %v", path, tt.wantPath)
+			}
+		})
+	}
+}
+
+func Test_containerPath(t *testing.T) {
+	tests := []struct {
+		name string
+		base string
+		path string
+		want string
+	}{
+		{
+			name: "relative paths are from the base",
+			base: "/app",
+			path: "storage/logs/",
+			want: "/app/storage/logs",
+		},
+		{
+			name: "absolute paths are not changed",
+			base: "/app",
+			path: "/tmp/../var/tmp",
+			want: "/var/tmp",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := containerPath(tt.base, tt.path); got != tt.want {
+				t.Errorf("containerPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
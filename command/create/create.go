@@ -1,6 +1,7 @@
 package create
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/url"
@@ -18,6 +19,7 @@ import (
 	"github.com/craftcms/nitro/pkg/pathexists"
 	"github.com/craftcms/nitro/pkg/prompt"
 	"github.com/craftcms/nitro/pkg/terminal"
+	"github.com/craftcms/nitro/pkg/validate"
 )
 
 const exampleText = `  # create a new default craft project (similar to "composer create-project craftcms/craft my-project")
@@ -27,35 +29,129 @@ const exampleText = `  # create a new default craft project (similar to "compose
   nitro create https://github.com/craftcms/demo my-project
 
   # you can also provide shorthand urls for github
-  nitro create craftcms/demo my-project`
+  nitro create craftcms/demo my-project
+
+  # use a tag or branch of a repo
+  nitro create craftcms/demo@v2.0.0 my-project
+
+  # use a private repo with a github token (or set GITHUB_TOKEN)
+  nitro create my-agency/starter my-project --token ghp_xxx
+
+  # copy a local boilerplate directory
+  nitro create ~/dev/starter my-project
+
+  # create a project and install craft with an admin account
+  nitro create my-project --install --email admin@example.com`
 
 // NewCommand returns the create command to automate the process of setting up a new Craft project.
-// It also allows you to pass an option argument that is a URL to your own github repo.
+// It also allows you to pass an option argument that is a URL to your own github repo, which can
+// be private when a token is provided, or a local directory to copy.
 func NewCommand(home string, docker client.CommonAPIClient, getter downloader.Getter, output terminal.Outputer) *cobra.Command {
+	// installing is the Craft install to run after apply, when the install flag is used
+	var installing *install
+
 	cmd := &cobra.Command{
 		Use:     "create",
 		Short:   "Creates a site from a Composer project.",
 		Example: exampleText,
 		Args:    cobra.MinimumNArgs(1),
 		PostRunE: func(cmd *cobra.Command, args []string) error {
-			return prompt.RunApply(cmd, args, true, output)
+			if err := prompt.RunApply(cmd, args, true, output); err != nil {
+				return err
+			}
+
+			if installing == nil {
+				return nil
+			}
+
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			output.Info("Installing Craft…")
+
+			if err := installCraft(ctx, docker, *installing, cmd.OutOrStdout()); err != nil {
+				return err
+			}
+
+			output.Success("Craft installed at https://" + installing.site.Hostname + "/admin")
+
+			if cmd.Flag("password").Value.String() == "" {
+				output.Info("Log in with the username", installing.username, "and the password", installing.password)
+			}
+
+			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			installing = nil
+
+			// ask for the admin account before downloading the project
+			var admin install
+			withInstall := cmd.Flag("install").Value.String() == "true"
+			if withInstall {
+				admin.email = cmd.Flag("email").Value.String()
+				if admin.email == "" {
+					email, err := output.Ask("Enter the admin email", "", ":", &validate.EmailValidator{})
+					if err != nil {
+						return err
+					}
+
+					admin.email = email
+				} else if err := (&validate.EmailValidator{}).Validate(admin.email); err != nil {
+					return err
+				}
+
+				admin.username = cmd.Flag("username").Value.String()
+
+				admin.password = cmd.Flag("password").Value.String()
+				if admin.password == "" {
+					password, err := generatePassword()
+					if err != nil {
+						return err
+					}
+
+					admin.password = password
+				}
+			}
+
 			// get the url from args or the default
 			var download *url.URL
-			var dir string
+			var template, dir string
+			var opts []downloader.Option
 
 			switch len(args) {
 			case 2:
-				// the directory and url are specified
-				u, err := urlgen.Generate(args[0])
-				if err != nil {
-					return err
+				// the directory and template are specified
+				template = args[0]
+				dir = filepath.Join(args[1])
+
+				// private repositories are downloaded from the api with a token, the
+				// GITHUB_TOKEN is only used for GitHub repositories
+				token := cmd.Flag("token").Value.String()
+				if token == "" && urlgen.IsGitHub(template) {
+					token = os.Getenv("GITHUB_TOKEN")
 				}
 
-				download = u
+				switch {
+				case pathexists.IsDirectory(template):
+					// a local boilerplate is copied
+				case token != "":
+					u, err := urlgen.GenerateAPI(template)
+					if err != nil {
+						return err
+					}
 
-				dir = filepath.Join(args[1])
+					download = u
+					opts = append(opts, downloader.WithToken(token))
+				default:
+					u, err := urlgen.Generate(template)
+					if err != nil {
+						return err
+					}
+
+					download = u
+				}
 			default:
 				// only the directory was provided, download craft to that directory
 				u, err := urlgen.Generate("")
@@ -73,18 +169,31 @@ func NewCommand(home string, docker client.CommonAPIClient, getter downloader.Ge
 				return fmt.Errorf("directory %q already exists", dir)
 			}
 
-			output.Info("Downloading", download.String(), "...")
+			if download == nil {
+				output.Pending("copying", template)
 
-			output.Pending("setting up project")
+				if err := downloader.Copy(template, dir); err != nil {
+					output.Warning()
+					return fmt.Errorf("unable to copy the template, %w", err)
+				}
 
-			// download the file
-			if err := getter.Get(download.String(), dir); err != nil {
-				return err
-			}
+				output.Done()
 
-			output.Done()
+				output.Info("New site copied 🤓")
+			} else {
+				output.Info("Downloading", download.String(), "...")
 
-			output.Info("New site downloaded 🤓")
+				output.Pending("setting up project")
+
+				// download the file
+				if err := getter.Get(download.String(), dir, opts...); err != nil {
+					return err
+				}
+
+				output.Done()
+
+				output.Info("New site downloaded 🤓")
+			}
 
 			// --- done with download
 
@@ -112,7 +221,7 @@ func NewCommand(home string, docker client.CommonAPIClient, getter downloader.Ge
 			}
 
 			// walk the user through the site
-			_, err := prompt.CreateSite(home, dir, output)
+			site, err := prompt.CreateSite(home, dir, output)
 			if err != nil {
 				return err
 			}
@@ -123,14 +232,23 @@ func NewCommand(home string, docker client.CommonAPIClient, getter downloader.Ge
 				return err
 			}
 
+			// craft can only be installed with a database
+			if withInstall && !database {
+				output.Info("Warning:", "Craft will not be installed without a database…\n run `nitro craft install` after adding a database")
+				withInstall = false
+			}
+
 			envFilePath := filepath.Join(dir, ".env")
 
 			// if the wanted a new database edit the env
 			if database && pathexists.IsFile(envFilePath) {
 				// ask the user if we should update the .env?
-				updateEnv, err := output.Confirm("Should we update the env file?", true, "")
-				if err != nil {
-					return err
+				updateEnv := withInstall
+				if !withInstall {
+					updateEnv, err = output.Confirm("Should we update the env file?", true, "")
+					if err != nil {
+						return err
+					}
 				}
 
 				if updateEnv {
@@ -186,14 +304,28 @@ func NewCommand(home string, docker client.CommonAPIClient, getter downloader.Ge
 					// command
 					if err := c.RunE(c, []string{"create-project", "--ignore-platform-reqs"}); err != nil {
 						output.Info(err.Error())
+
+						// craft can not be installed without the dependencies
+						withInstall = false
 						break
 					}
 				}
 			}
 
+			if withInstall {
+				admin.site = *site
+				installing = &admin
+			}
+
 			return nil
 		},
 	}
 
+	cmd.Flags().String("token", "", "the github token to download private repos (default is GITHUB_TOKEN)")
+	cmd.Flags().Bool("install", false, "install craft after creating the site")
+	cmd.Flags().String("email", "", "the email for the admin account when installing craft")
+	cmd.Flags().String("username", "admin", "the username for the admin account when installing craft")
+	cmd.Flags().String("password", "", "the password for the admin account when installing craft (default is generated)")
+
 	return cmd
 }
@@ -0,0 +1,114 @@
+package create
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+)
+
+// install is the admin account for installing Craft, it is set when the install flag is used.
+type install struct {
+	site     config.Site
+	email    string
+	username string
+	password string
+}
+
+// args returns the command to install Craft without prompts, the site url uses the sites hostname.
+func (i install) args() []string {
+	return []string{
+		"php", "craft", "install/craft",
+		"--interactive=0",
+		"--email=" + i.email,
+		"--username=" + i.username,
+		"--password=" + i.password,
+		"--site-name=" + i.site.Hostname,
+		"--site-url=https://" + i.site.Hostname,
+		"--language=en-US",
+	}
+}
+
+// dir returns the directory in the site container with the craft executable.
+func (i install) dir() string {
+	if p := i.site.GetContainerPath(); p != "" {
+		return "/app/" + p
+	}
+
+	return "/app"
+}
+
+// generatePassword returns a random password for the admin account.
+func generatePassword() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("unable to generate a password, %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// installCraft runs the Craft installer in the site container and writes the output to w.
+func installCraft(ctx context.Context, docker client.ContainerAPIClient, i install, w io.Writer) error {
+	filter := filters.NewArgs()
+	filter.Add("label", containerlabels.Nitro)
+	filter.Add("label", containerlabels.Host+"="+i.site.Hostname)
+
+	containers, err := docker.ContainerList(ctx, types.ContainerListOptions{Filters: filter})
+	if err != nil {
+		return fmt.Errorf("unable to list the containers, %w", err)
+	}
+
+	if len(containers) == 0 {
+		return fmt.Errorf("unable to find a running container for %s", i.site.Hostname)
+	}
+
+	created, err := docker.ContainerExecCreate(ctx, containers[0].ID, types.ExecConfig{
+		AttachStdout: true,
+		AttachStderr: true,
+		Tty:          false,
+		WorkingDir:   i.dir(),
+		Cmd:          i.args(),
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := docker.ContainerExecAttach(ctx, created.ID, types.ExecStartCheck{Tty: false})
+	if err != nil {
+		return err
+	}
+	defer resp.Close()
+
+	if err := docker.ContainerExecStart(ctx, created.ID, types.ExecStartCheck{}); err != nil {
+		return fmt.Errorf("unable to start the container exec, %w", err)
+	}
+
+	// show the output, stderr is also kept for errors
+	stderr := new(bytes.Buffer)
+	if _, err := stdcopy.StdCopy(w, io.MultiWriter(w, stderr), resp.Reader); err != nil {
+		return err
+	}
+
+	info, err := docker.ContainerExecInspect(ctx, created.ID)
+	if err != nil {
+		return err
+	}
+
+	if info.ExitCode != 0 {
+		return fmt.Errorf("the Craft install exited with code %d, %s", info.ExitCode, strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
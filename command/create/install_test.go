@@ -0,0 +1,59 @@
+package create
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/craftcms/nitro/pkg/config"
+)
+
+func Test_install_args(t *testing.T) {
+	i := install{
+		site:     config.Site{Hostname: "demo.nitro", Webroot: "web"},
+		email:    "admin@example.com",
+		username: "admin",
+		password: "secret",
+	}
+
+	want := []string{
+		"php", "craft", "install/craft",
+		"--interactive=0",
+		"--email=admin@example.com",
+		"--username=admin",
+		"--password=secret",
+		"--site-name=demo.nitro",
+		"--site-url=https://demo.nitro",
+		"--language=en-US",
+	}
+
+	if got := i.args(); !reflect.DeepEqual(got, want) {
+		t.Errorf("args() = %v, want %v", got, want)
+	}
+}
+
+func Test_install_dir(t *testing.T) {
+	tests := []struct {
+		name    string
+		webroot string
+		want    string
+	}{
+		{
+			name:    "web roots in the project use the app directory",
+			webroot: "web",
+			want:    "/app",
+		},
+		{
+			name:    "nested web roots use the parent directory",
+			webroot: "app/web",
+			want:    "/app/app",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := install{site: config.Site{Webroot: tt.webroot}}
+			if got := i.dir(); got != tt.want {
+				t.Errorf("dir() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -10,32 +10,76 @@ const download = "https://github.com/craftcms/craft/archive/HEAD.zip"
 
 // Generate is a helper that is used to build the Github download link
 // of a repository (e.g. https://github.com/craftcms/craft/archive/HEAD.zip).
-// It supports short hand urls such as `craftcms/craft`. If no address is
-// provided or the case does not match, it defaults to the craft repo.
+// It supports short hand urls such as `craftcms/craft`. A branch or tag can
+// be added with an @ (e.g. `craftcms/craft@1.1.7`) or a tree url. If no
+// address is provided or the case does not match, it defaults to the craft repo.
 func Generate(addr string) (*url.URL, error) {
+	host, owner, repo, ref, ok := parse(addr)
+	if !ok {
+		// setup the default download url
+		return url.Parse(download)
+	}
+
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	return url.Parse(fmt.Sprintf("https://%s/%s/%s/archive/%s.zip", host, owner, repo, ref))
+}
+
+// GenerateAPI returns the GitHub API link to download a repository (e.g.
+// https://api.github.com/repos/craftcms/craft/zipball/1.1.7), which is
+// used with a token to download private repositories.
+func GenerateAPI(addr string) (*url.URL, error) {
+	host, owner, repo, ref, ok := parse(addr)
+	if !ok || host != "github.com" {
+		return nil, fmt.Errorf("unable to use %q as a GitHub repository", addr)
+	}
+
+	path := fmt.Sprintf("https://api.github.com/repos/%s/%s/zipball", owner, repo)
+	if ref != "" {
+		path += "/" + ref
+	}
+
+	return url.Parse(path)
+}
+
+// IsGitHub returns true when the address is a GitHub repository, so a GitHub
+// token is only sent to GitHub.
+func IsGitHub(addr string) bool {
+	host, _, _, _, ok := parse(addr)
+
+	return ok && host == "github.com"
+}
+
+// parse returns the parts of a repository address and false when the
+// address is not a repository.
+func parse(addr string) (host, owner, repo, ref string, ok bool) {
+	// get the branch or tag
+	if i := strings.LastIndex(addr, "@"); i != -1 {
+		addr, ref = addr[:i], addr[i+1:]
+	}
+
+	addr = strings.TrimSuffix(strings.TrimSuffix(addr, "/"), ".git")
+
 	// split the address by /
 	sp := strings.Split(addr, "/")
 
 	// check the length
-	switch len(sp) {
-	case 5:
-		// parse a complete url for github and append the download
-		u, err := url.Parse(fmt.Sprintf("%s/archive/HEAD.zip", addr))
-		if err != nil {
-			return nil, err
-		}
-
-		return u, nil
-	// if this is using the short hand address
-	case 2:
-		u, err := url.Parse(fmt.Sprintf("https://github.com/%s/%s/archive/HEAD.zip", sp[0], sp[1]))
-		if err != nil {
-			return nil, err
+	switch {
+	case len(sp) == 2 && sp[0] != "" && sp[1] != "":
+		// if this is using the short hand address
+		return "github.com", sp[0], sp[1], ref, true
+	case len(sp) >= 5 && strings.HasPrefix(sp[0], "http"):
+		// a complete url, which may be a tree url for a branch or tag
+		if len(sp) > 6 && sp[5] == "tree" && ref == "" {
+			ref = strings.Join(sp[6:], "/")
+		} else if len(sp) != 5 {
+			return "", "", "", "", false
 		}
 
-		return u, nil
+		return sp[2], sp[3], sp[4], ref, true
 	}
 
-	// setup the default download url
-	return url.Parse(download)
+	return "", "", "", "", false
 }
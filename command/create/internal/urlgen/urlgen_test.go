@@ -36,6 +36,36 @@ func TestGenerate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "can get the download URL for a tag",
+			args: args{addr: "craftcms/craft@1.1.7"},
+			want: &url.URL{
+				Scheme: "https",
+				Host:   "github.com",
+				Path:   "/craftcms/craft/archive/1.1.7.zip",
+			},
+			wantErr: false,
+		},
+		{
+			name: "can get the download URL for a tree URL",
+			args: args{addr: "https://github.com/jasonmccallister/my-craft-setup/tree/develop"},
+			want: &url.URL{
+				Scheme: "https",
+				Host:   "github.com",
+				Path:   "/jasonmccallister/my-craft-setup/archive/develop.zip",
+			},
+			wantErr: false,
+		},
+		{
+			name: "can get the download URL for a clone URL",
+			args: args{addr: "https://github.com/jasonmccallister/my-craft-setup.git"},
+			want: &url.URL{
+				Scheme: "https",
+				Host:   "github.com",
+				Path:   "/jasonmccallister/my-craft-setup/archive/HEAD.zip",
+			},
+			wantErr: false,
+		},
 		{
 			name: "the default repository url is returned when nothing is entered",
 			args: args{addr: ""},
@@ -60,3 +90,79 @@ func TestGenerate(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateAPI(t *testing.T) {
+	tests := []struct {
+		name    string
+		addr    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "shorthand urls use the default branch",
+			addr: "agency/starter",
+			want: "https://api.github.com/repos/agency/starter/zipball",
+		},
+		{
+			name: "tags are added to the url",
+			addr: "https://github.com/agency/starter@v2.0.0",
+			want: "https://api.github.com/repos/agency/starter/zipball/v2.0.0",
+		},
+		{
+			name:    "other hosts return an error",
+			addr:    "https://gitlab.com/agency/starter",
+			wantErr: true,
+		},
+		{
+			name:    "empty addresses return an error",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateAPI(tt.addr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GenerateAPI() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err == nil && got.String() != tt.want {
+				t.Errorf("GenerateAPI() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsGitHub(t *testing.T) {
+	tests := []struct {
+		name string
+		addr string
+		want bool
+	}{
+		{
+			name: "shorthand urls are on github",
+			addr: "agency/starter",
+			want: true,
+		},
+		{
+			name: "github urls are on github",
+			addr: "https://github.com/agency/starter@v2.0.0",
+			want: true,
+		},
+		{
+			name: "other hosts are not on github",
+			addr: "https://gitlab.com/agency/starter",
+			want: false,
+		},
+		{
+			name: "empty addresses are not on github",
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsGitHub(tt.addr); got != tt.want {
+				t.Errorf("IsGitHub() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -16,7 +16,6 @@ import (
 	"github.com/craftcms/nitro/pkg/backup"
 	"github.com/craftcms/nitro/pkg/config"
 	"github.com/craftcms/nitro/pkg/containerlabels"
-	"github.com/craftcms/nitro/pkg/datetime"
 	"github.com/craftcms/nitro/pkg/terminal"
 )
 
@@ -66,25 +65,24 @@ func backupCommand(home string, docker client.CommonAPIClient, output terminal.O
 
 			// create the options for the backup
 			opts := &backup.Options{
-				BackupName:    fmt.Sprintf("%s-%s.sql", db, datetime.Parse(time.Now())),
+				BackupName:    backup.FileName(compatibility, db, time.Now()),
 				ContainerID:   containerID,
 				ContainerName: containerName,
 				Database:      db,
-				Home:          home,
 			}
 
-			// create the backup command based on the compatibility type
-			switch compatibility {
-			case "postgres":
-				opts.Commands = []string{"pg_dump", "--username=nitro", db, "-f", "/tmp/" + opts.BackupName}
-			default:
-				opts.Commands = []string{"mysqldump", "--user=nitro", "-pnitro", db, "--result-file=" + "/tmp/" + opts.BackupName}
+			// upload the backup when the config has storage
+			if cfg, err := config.Load(home); err == nil {
+				opts.Storage = cfg.Backups.Storage
 			}
 
+			// create the backup command based on the compatibility type
+			opts.Commands = backup.Commands(compatibility, db)
+
 			output.Pending("creating backup", opts.BackupName)
 
 			// perform the backup
-			if err := backup.Perform(ctx, docker, opts); err != nil {
+			if err := backup.ToFile(ctx, docker, home, opts); err != nil {
 				output.Warning()
 
 				return fmt.Errorf("unable to backup the database, %w", err)
@@ -92,7 +90,7 @@ func backupCommand(home string, docker client.CommonAPIClient, output terminal.O
 
 			output.Done()
 
-			output.Info("Backup saved in", filepath.Join(opts.Home, config.DirectoryName, "backups", opts.ContainerName), "💾")
+			output.Info("Backup saved in", filepath.Join(home, config.DirectoryName, "backups", opts.ContainerName), "💾")
 
 			return nil
 		},
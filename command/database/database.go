@@ -1,6 +1,9 @@
 package database
 
 import (
+	"strings"
+
+	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/client"
 	"github.com/spf13/cobra"
 
@@ -14,8 +17,26 @@ const exampleText = `  # import a database from a backup
   # backup a database
   nitro db backup
 
+  # restore a backup
+  nitro db restore
+
+  # pull a database from a sites remote server
+  nitro db pull mysite.nitro
+
   # add a new database
-  nitro db add`
+  nitro db add
+
+  # open a mysql or psql client
+  nitro db shell
+
+  # empty all of the tables in a database
+  nitro db truncate mysql-8.0-3306 nitro
+
+  # dump a database to stdout
+  nitro db dump mysql-8.0-3306 nitro --stdout
+
+  # export a database to a compressed file
+  nitro db export`
 
 // NewCommand returns the db commands for importing, backing up, and adding databases
 func NewCommand(home string, docker client.CommonAPIClient, nitrod protob.NitroClient, output terminal.Outputer) *cobra.Command {
@@ -34,10 +55,32 @@ func NewCommand(home string, docker client.CommonAPIClient, nitrod protob.NitroC
 		backupCommand(home, docker, output),
 		addCommand(docker, nitrod, output),
 		sshCommand(home, docker, output),
+		shellClientCommand(docker, output),
 		removeCommand(docker, nitrod, output),
 		newCommand(home, docker, output),
 		destroyCommand(home, docker, output),
+		truncateCommand(docker, output),
+		dumpCommand(home, docker, output),
+		exportCommand(home, docker, output),
+		restoreCommand(home, docker, output),
+		pullCommand(home, docker, output),
 	)
 
 	return cmd
 }
+
+// containerSuffix is the suffix of the database container names (e.g. mysql-8.0-3306.database.nitro)
+const containerSuffix = ".database.nitro"
+
+// findContainer returns the database container with the hostname and its name. The hostname is the
+// container name with or without the .database.nitro suffix (e.g. mysql-8.0-3306).
+func findContainer(containers []types.Container, hostname string) (types.Container, string, bool) {
+	for _, c := range containers {
+		name := strings.TrimLeft(c.Names[0], "/")
+		if name == hostname || name == hostname+containerSuffix {
+			return c, name, true
+		}
+	}
+
+	return types.Container{}, "", false
+}
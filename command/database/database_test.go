@@ -0,0 +1,54 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types"
+)
+
+func Test_findContainer(t *testing.T) {
+	containers := []types.Container{
+		{ID: "postgres-id", Names: []string{"/postgres-13-5432.database.nitro"}},
+		{ID: "mysql-id", Names: []string{"/mysql-8.0-3306.database.nitro"}},
+	}
+
+	tests := []struct {
+		name     string
+		hostname string
+		wantID   string
+		wantName string
+		wantOK   bool
+	}{
+		{
+			name:     "the container name matches",
+			hostname: "mysql-8.0-3306.database.nitro",
+			wantID:   "mysql-id",
+			wantName: "mysql-8.0-3306.database.nitro",
+			wantOK:   true,
+		},
+		{
+			name:     "the suffix can be left off",
+			hostname: "mysql-8.0-3306",
+			wantID:   "mysql-id",
+			wantName: "mysql-8.0-3306.database.nitro",
+			wantOK:   true,
+		},
+		{
+			name:     "unknown hostnames are not found",
+			hostname: "mysql-5.7-3306",
+			wantOK:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, name, ok := findContainer(containers, tt.hostname)
+			if ok != tt.wantOK {
+				t.Fatalf("findContainer() ok = %v, want %v", ok, tt.wantOK)
+			}
+
+			if got.ID != tt.wantID || name != tt.wantName {
+				t.Errorf("findContainer() = %v %v, want %v %v", got.ID, name, tt.wantID, tt.wantName)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package database
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/backup"
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+var dumpExampleText = `  # dump a database to the backups directory
+  nitro db dump mysql-8.0-3306 nitro
+
+  # dump a database to stdout and compress it
+  nitro db dump mysql-8.0-3306 nitro --stdout | gzip > nitro.sql.gz`
+
+func dumpCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "dump <hostname> <database>",
+		Short:   "Dumps a database.",
+		Example: dumpExampleText,
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			hostname, db := args[0], args[1]
+			stdout := cmd.Flag("stdout").Value.String() == "true"
+
+			// add filters to show only the database containers
+			filter := filters.NewArgs()
+			filter.Add("label", containerlabels.Nitro)
+			filter.Add("label", containerlabels.Type+"=database")
+
+			containers, err := docker.ContainerList(cmd.Context(), types.ContainerListOptions{Filters: filter})
+			if err != nil {
+				return err
+			}
+
+			// find the container for the hostname, the backups are stored by the container name
+			container, hostname, ok := findContainer(containers, hostname)
+			if !ok {
+				return fmt.Errorf("unable to find a running database container for %s", args[0])
+			}
+
+			compatibility := container.Labels[containerlabels.DatabaseCompatibility]
+			opts := &backup.Options{
+				BackupName:    backup.FileName(compatibility, db, time.Now()),
+				ContainerID:   container.ID,
+				ContainerName: hostname,
+				Database:      db,
+			}
+
+			opts.Commands = backup.Commands(compatibility, db)
+
+			// write the dump to stdout, the output is not shown so it can be piped
+			if stdout {
+				opts.Writer = cmd.OutOrStdout()
+
+				if err := backup.Perform(cmd.Context(), docker, opts); err != nil {
+					return fmt.Errorf("unable to dump the database, %w", err)
+				}
+
+				return nil
+			}
+
+			output.Pending("dumping", db)
+
+			if err := backup.ToFile(cmd.Context(), docker, home, opts); err != nil {
+				output.Warning()
+
+				return fmt.Errorf("unable to dump the database, %w", err)
+			}
+
+			output.Done()
+
+			output.Info("Dump saved in", filepath.Join(home, config.DirectoryName, "backups", hostname), "💾")
+
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("stdout", false, "write the dump to stdout instead of the backups directory")
+
+	return cmd
+}
@@ -0,0 +1,197 @@
+package database
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/backup"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+var exportExampleText = `  # export a database and choose the engine and database
+  nitro db export
+
+  # export a database without prompts
+  nitro db export --hostname mysql-8.0-3306 --database nitro
+
+  # export a database to a specific file
+  nitro db export --output ~/Desktop/nitro.sql.gz`
+
+// exportCommand is the command for exporting a database to a compressed file
+func exportCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "export",
+		Short:   "Exports a database to a compressed file.",
+		Example: exportExampleText,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			hostname := cmd.Flag("hostname").Value.String()
+			db := cmd.Flag("database").Value.String()
+
+			// add filters to show only the database containers
+			filter := filters.NewArgs()
+			filter.Add("label", containerlabels.Nitro)
+			filter.Add("label", containerlabels.Type+"=database")
+
+			containers, err := docker.ContainerList(ctx, types.ContainerListOptions{Filters: filter})
+			if err != nil {
+				return err
+			}
+
+			// sort containers by the name
+			sort.SliceStable(containers, func(i, j int) bool {
+				return containers[i].Names[0] < containers[j].Names[0]
+			})
+
+			var containerID, containerName, compatibility string
+			switch hostname {
+			case "":
+				// generate a list of engines for the prompt
+				var containerList []string
+				for _, c := range containers {
+					containerList = append(containerList, strings.TrimLeft(c.Names[0], "/"))
+				}
+
+				if len(containerList) == 0 {
+					return fmt.Errorf("there are no running database containers")
+				}
+
+				containerID, containerName, compatibility, db, err = backup.Prompt(ctx, os.Stdin, docker, output, containers, containerList)
+				if err != nil {
+					return err
+				}
+
+				containerName = strings.TrimLeft(containerName, "/")
+			default:
+				// find the container for the hostname
+				c, name, ok := findContainer(containers, hostname)
+				if !ok {
+					return fmt.Errorf("unable to find a running database container for %s", hostname)
+				}
+
+				containerID = c.ID
+				containerName = name
+				compatibility = c.Labels[containerlabels.DatabaseCompatibility]
+
+				// ask for the database if it was not provided
+				if db == "" {
+					databases, err := backup.Databases(ctx, docker, containerID, compatibility)
+					if err != nil {
+						return err
+					}
+
+					if len(databases) == 0 {
+						return fmt.Errorf("no databases found")
+					}
+
+					selected, err := output.Select(os.Stdin, "Which database should we export? ", databases)
+					if err != nil {
+						return err
+					}
+
+					db = databases[selected]
+				}
+			}
+
+			opts := &backup.Options{
+				BackupName:    backup.FileName(compatibility, db, time.Now()),
+				ContainerID:   containerID,
+				ContainerName: containerName,
+				Database:      db,
+			}
+
+			opts.Commands = backup.Commands(compatibility, db)
+
+			wd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+
+			file := exportPath(home, wd, cmd.Flag("output").Value.String(), opts.BackupName)
+
+			output.Pending("exporting", db)
+
+			if err := export(ctx, docker, file, opts); err != nil {
+				output.Warning()
+
+				return fmt.Errorf("unable to export the database, %w", err)
+			}
+
+			output.Done()
+
+			output.Info("Export saved to", file, "💾")
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("hostname", "", "the hostname of the database engine, with or without the .database.nitro suffix (e.g. mysql-8.0-3306)")
+	cmd.Flags().String("database", "", "the name of the database to export")
+	cmd.Flags().String("output", "", "the file or directory to write the export to")
+
+	return cmd
+}
+
+// export performs the backup and writes it to the file using gzip compression. If the
+// backup fails, the file is removed.
+func export(ctx context.Context, docker client.CommonAPIClient, file string, opts *backup.Options) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(f)
+	opts.Writer = gz
+
+	if err := backup.Perform(ctx, docker, opts); err != nil {
+		gz.Close()
+		f.Close()
+		os.Remove(file)
+
+		return err
+	}
+
+	if err := gz.Close(); err != nil {
+		f.Close()
+		os.Remove(file)
+
+		return err
+	}
+
+	return f.Close()
+}
+
+// exportPath returns the file to write an export to. When path is empty, the export is written
+// to the working directory and when the path is a directory, the backup name is used as the file.
+func exportPath(home, wd, path, name string) string {
+	if path == "" {
+		return filepath.Join(wd, name)
+	}
+
+	// replace the relative path with the full directory
+	if strings.HasPrefix(path, "~") {
+		path = strings.Replace(path, "~", home, 1)
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(wd, path)
+	}
+
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return filepath.Join(path, name)
+	}
+
+	return path
+}
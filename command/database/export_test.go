@@ -0,0 +1,49 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_exportPath(t *testing.T) {
+	home := filepath.Join(os.TempDir(), "home")
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{
+			name: "empty paths use the working directory",
+			path: "",
+			want: filepath.Join(wd, "nitro-backup.sql.gz"),
+		},
+		{
+			name: "directories use the backup name",
+			path: os.TempDir(),
+			want: filepath.Join(os.TempDir(), "nitro-backup.sql.gz"),
+		},
+		{
+			name: "relative files use the working directory",
+			path: "export.sql.gz",
+			want: filepath.Join(wd, "export.sql.gz"),
+		},
+		{
+			name: "home directories are replaced",
+			path: "~/export.sql.gz",
+			want: filepath.Join(home, "export.sql.gz"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exportPath(home, wd, tt.path, "nitro-backup.sql.gz"); got != tt.want {
+				t.Errorf("exportPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -33,14 +33,17 @@ var importExampleText = `  # import a sql file into a database
   nitro db import ~/Desktop/backup.sql
 
   # use an absolute path
-  nitro db import /Users/oli/Desktop/backup.sql`
+  nitro db import /Users/oli/Desktop/backup.sql
+
+  # import a compressed backup
+  nitro db import backup.sql.gz`
 
 var nameFlag string
 
 // importCommand is the command for creating new development environments
 func importCommand(home string, docker client.CommonAPIClient, nitrod protob.NitroClient, output terminal.Outputer) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "import",
+		Use:   "import <file>",
 		Short: "Imports a database dump.",
 		Args: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
@@ -86,22 +89,24 @@ func importCommand(home string, docker client.CommonAPIClient, nitrod protob.Nit
 				compressionType = kind
 			}
 
-			// detect the type of backup if not compressed
-			detected := ""
-			if !compressed {
-				output.Pending("detecting backup type")
+			output.Pending("detecting backup type")
 
-				// determine the database engine
-				detected, err = database.DetermineEngine(path)
-				if errors.Is(err, database.ErrUnknownDatabaseEngine) {
-					output.Warning()
+			// determine the database engine, compressed backups are checked without extracting the file
+			detected, err := database.DetermineCompressedEngine(path, kind)
+			if err != nil {
+				output.Warning()
 
+				if errors.Is(err, database.ErrUnknownDatabaseEngine) {
 					output.Info(strings.Title(err.Error()))
 				} else {
-					output.Done()
-
-					output.Info("Detected", detected, "backup")
+					output.Info("Unable to detect the backup type,", err.Error())
 				}
+
+				detected = ""
+			} else {
+				output.Done()
+
+				output.Info("Detected", detected, "backup")
 			}
 
 			// add filters to show only the environment and database containers
@@ -293,12 +298,18 @@ func importCommand(home string, docker client.CommonAPIClient, nitrod protob.Nit
 			if err != nil {
 				return err
 			}
+			defer file.Close()
+
+			stat, err := file.Stat()
+			if err != nil {
+				return err
+			}
+
+			output.Info(fmt.Sprintf("Importing database %q into %q…", db, hostname))
 
 			// create a buffer to handle large files more gracefully
 			buffer := make([]byte, 1024*20)
-			reader := bufio.NewReader(file)
-
-			output.Pending(fmt.Sprintf("importing database %q into %q", db, hostname))
+			reader := bufio.NewReader(newProgress(file, cmd.ErrOrStderr(), stat.Size()))
 
 			// stream to backup file to the api
 			for {
@@ -324,6 +335,10 @@ func importCommand(home string, docker client.CommonAPIClient, nitrod protob.Nit
 				}
 			}
 
+			fmt.Fprintln(cmd.ErrOrStderr())
+
+			output.Pending("waiting for the import to finish")
+
 			// handle the response
 			reply, err := stream.CloseAndRecv()
 			if err != nil {
@@ -37,9 +37,9 @@ func newCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 			var options []string
 			switch runtime.GOOS {
 			case "arm64", "arm":
-				options = []string{"mariadb", "postgres"}
+				options = []string{"mariadb", "mongodb", "postgres"}
 			default:
-				options = []string{"mariadb", "mysql", "postgres"}
+				options = []string{"mariadb", "mongodb", "mysql", "postgres"}
 			}
 
 			// prompt for the engine
@@ -62,6 +62,8 @@ func newCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 			switch engine {
 			case "postgres":
 				defaultPort = "5432"
+			case "mongodb":
+				defaultPort = "27017"
 			default:
 				defaultPort = "3306"
 			}
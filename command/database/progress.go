@@ -0,0 +1,40 @@
+package database
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/craftcms/nitro/pkg/helpers"
+)
+
+// progress wraps a reader and writes the percentage of the bytes read to w. It
+// is used to show the transfer progress when importing large backups.
+type progress struct {
+	r       io.Reader
+	w       io.Writer
+	total   int64
+	read    int64
+	percent int64
+}
+
+func newProgress(r io.Reader, w io.Writer, total int64) *progress {
+	return &progress{r: r, w: w, total: total, percent: -1}
+}
+
+func (p *progress) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.read += int64(n)
+
+	// only write the progress when the percentage changes
+	percent := int64(100)
+	if p.total > 0 {
+		percent = p.read * 100 / p.total
+	}
+
+	if percent != p.percent {
+		p.percent = percent
+		fmt.Fprintf(p.w, "\r  … %d%% (%s of %s)", percent, helpers.ByteSize(p.read), helpers.ByteSize(p.total))
+	}
+
+	return n, err
+}
@@ -0,0 +1,48 @@
+package database
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestProgress(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		total int64
+		want  string
+	}{
+		{
+			name:  "writes the percentage and size",
+			data:  "abcd",
+			total: 4,
+			want:  "\r  … 100% (4 B of 4 B)",
+		},
+		{
+			name:  "empty files are complete",
+			data:  "",
+			total: 0,
+			want:  "\r  … 100% (0 B of 0 B)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+
+			got, err := ioutil.ReadAll(newProgress(strings.NewReader(tt.data), w, tt.total))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(got) != tt.data {
+				t.Errorf("expected the data %q, got %q", tt.data, string(got))
+			}
+
+			if w.String() != tt.want {
+				t.Errorf("expected the progress %q, got %q", tt.want, w.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package database
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/backup"
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/remote"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+var pullExampleText = `  # pull the database from the sites remote into a local database engine
+  nitro db pull mysite.nitro
+
+  # pull the database into a specific engine and database
+  nitro db pull mysite.nitro --hostname mysql-8.0-3306 --database mysite`
+
+func pullCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "pull <site>",
+		Short:   "Pulls a database from a sites remote server.",
+		Example: pullExampleText,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			cfg, err := config.Load(home)
+			if err != nil {
+				return err
+			}
+
+			// merge the project files from the sites
+			if _, err := cfg.MergeProjects(home); err != nil {
+				return err
+			}
+
+			site, err := cfg.FindSiteByHostName(args[0])
+			if err != nil {
+				return err
+			}
+
+			if site.Remote == nil {
+				return fmt.Errorf("the site %s does not have a remote, add the remote to the site in the config", site.Hostname)
+			}
+
+			r := *site.Remote
+			engine := remote.Engine(r)
+
+			// find the local engines that are compatible with the remote
+			filter := filters.NewArgs()
+			filter.Add("label", containerlabels.Nitro)
+			filter.Add("label", containerlabels.Type+"=database")
+			filter.Add("label", containerlabels.DatabaseCompatibility+"="+engine)
+
+			containers, err := docker.ContainerList(ctx, types.ContainerListOptions{Filters: filter})
+			if err != nil {
+				return err
+			}
+
+			if len(containers) == 0 {
+				return fmt.Errorf("there are no running %s database containers to pull into", engine)
+			}
+
+			sort.SliceStable(containers, func(i, j int) bool {
+				return containers[i].Names[0] < containers[j].Names[0]
+			})
+
+			var containerList []string
+			for _, c := range containers {
+				containerList = append(containerList, strings.TrimLeft(c.Names[0], "/"))
+			}
+
+			container, containerName := containers[0], containerList[0]
+			switch hostname := cmd.Flag("hostname").Value.String(); {
+			case hostname != "":
+				c, n, ok := findContainer(containers, hostname)
+				if !ok {
+					return fmt.Errorf("unable to find a running %s database container for %s", engine, hostname)
+				}
+
+				container, containerName = c, n
+			case len(containerList) > 1:
+				selected, err := output.Select(cmd.InOrStdin(), "Which database engine? ", containerList)
+				if err != nil {
+					return err
+				}
+
+				container, containerName = containers[selected], containerList[selected]
+			}
+
+			db := cmd.Flag("database").Value.String()
+			if db == "" {
+				db = r.Database
+			}
+
+			// save the dump in a temp file so a failed pull does not change the local database
+			f, err := ioutil.TempFile("", "nitro-pull-*.gz")
+			if err != nil {
+				return err
+			}
+			defer os.Remove(f.Name())
+
+			output.Pending("pulling", r.Database, "from", r.Host)
+
+			gz := gzip.NewWriter(f)
+			if err := remote.Dump(ctx, r, home, gz); err != nil {
+				gz.Close()
+				f.Close()
+				output.Warning()
+
+				return err
+			}
+
+			if err := gz.Close(); err != nil {
+				f.Close()
+				output.Warning()
+
+				return err
+			}
+
+			if err := f.Close(); err != nil {
+				output.Warning()
+
+				return err
+			}
+
+			output.Done()
+
+			output.Pending("importing into", db, "on", containerName)
+
+			name := filepath.Base(f.Name())
+			if _, err := backup.Restore(ctx, docker, container.ID, f.Name(), backup.RestoreCommands(engine, r.Database, db, "/tmp/"+name)); err != nil {
+				output.Warning()
+
+				return fmt.Errorf("unable to import the database, %w", err)
+			}
+
+			// remove the dump from the container
+			if _, err := backup.Exec(ctx, docker, container.ID, []string{"rm", "-f", "/tmp/" + name}); err != nil {
+				output.Info("Warning:", "unable to remove the dump from the container,", err.Error())
+			}
+
+			output.Done()
+
+			output.Info("Pulled", r.Database, "from", r.Host, "into", db, "on", containerName, "🚚")
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("hostname", "", "the hostname of the local database engine, with or without the .database.nitro suffix (e.g. mysql-8.0-3306)")
+	cmd.Flags().String("database", "", "the local database to import into, defaults to the remote database name")
+
+	return cmd
+}
@@ -0,0 +1,157 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/backup"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/terminal"
+	"github.com/craftcms/nitro/pkg/validate"
+)
+
+var restoreExampleText = `  # restore a backup from the backups directory
+  nitro db restore`
+
+// restoreCommand is the command for restoring backups from the backups directory
+func restoreCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "restore",
+		Short:   "Restores a database backup.",
+		Example: restoreExampleText,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			backups, err := backup.List(home)
+			if err != nil {
+				return fmt.Errorf("unable to find the backups, %w", err)
+			}
+
+			if len(backups) == 0 {
+				output.Info("There are no backups to restore…")
+
+				return nil
+			}
+
+			// prompt for the database engine
+			engines := unique(backups, func(f backup.File) string { return f.Container })
+			selected, err := output.Select(os.Stdin, "Which database engine? ", engines)
+			if err != nil {
+				return err
+			}
+
+			hostname := engines[selected]
+			backups = filterBackups(backups, func(f backup.File) bool { return f.Container == hostname })
+
+			// prompt for the database
+			databases := unique(backups, func(f backup.File) string { return f.Database })
+			selected, err = output.Select(os.Stdin, "Which database? ", databases)
+			if err != nil {
+				return err
+			}
+
+			database := databases[selected]
+			backups = filterBackups(backups, func(f backup.File) bool { return f.Database == database })
+
+			// prompt for the backup, the newest backup is first
+			var names []string
+			for _, b := range backups {
+				names = append(names, b.CreatedAt.Format("2006-01-02 15:04:05")+" ("+b.Name+")")
+			}
+
+			selected, err = output.Select(os.Stdin, "Which backup should we restore? ", names)
+			if err != nil {
+				return err
+			}
+
+			file := backups[selected]
+
+			// find the running container for the engine
+			filter := filters.NewArgs()
+			filter.Add("label", containerlabels.Nitro)
+			filter.Add("label", containerlabels.Type+"=database")
+
+			containers, err := docker.ContainerList(ctx, types.ContainerListOptions{Filters: filter})
+			if err != nil {
+				return err
+			}
+
+			var container types.Container
+			for _, c := range containers {
+				if strings.TrimLeft(c.Names[0], "/") == hostname {
+					container = c
+					break
+				}
+			}
+
+			if container.ID == "" {
+				return fmt.Errorf("unable to find a running database container for %s", hostname)
+			}
+
+			// ask for the database to restore into, which can be a new database
+			db, err := output.Ask("Enter the database to restore into", database, ":", &validate.DatabaseName{})
+			if err != nil {
+				return err
+			}
+
+			compatibility := container.Labels[containerlabels.DatabaseCompatibility]
+
+			output.Pending("restoring", file.Name, "into", db)
+
+			if _, err := backup.Restore(ctx, docker, container.ID, file.Path, backup.RestoreCommands(compatibility, file.Database, db, "/tmp/"+file.Name)); err != nil {
+				output.Warning()
+
+				return fmt.Errorf("unable to restore the backup, %w", err)
+			}
+
+			// remove the backup from the container
+			if _, err := backup.Exec(ctx, docker, container.ID, []string{"rm", "-f", "/tmp/" + file.Name}); err != nil {
+				output.Info("Warning:", "unable to remove the backup from the container,", err.Error())
+			}
+
+			output.Done()
+
+			output.Info("Restored", file.Name, "into", db, "on", hostname)
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// unique returns the unique values for the backups in order.
+func unique(backups []backup.File, value func(f backup.File) string) []string {
+	seen := make(map[string]bool)
+
+	var values []string
+	for _, b := range backups {
+		v := value(b)
+		if seen[v] {
+			continue
+		}
+
+		seen[v] = true
+		values = append(values, v)
+	}
+
+	return values
+}
+
+// filterBackups returns the backups that match the keep func.
+func filterBackups(backups []backup.File, keep func(f backup.File) bool) []backup.File {
+	var filtered []backup.File
+	for _, b := range backups {
+		if keep(b) {
+			filtered = append(filtered, b)
+		}
+	}
+
+	return filtered
+}
@@ -0,0 +1,34 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/craftcms/nitro/pkg/backup"
+)
+
+func Test_uniqueAndFilterBackups(t *testing.T) {
+	backups := []backup.File{
+		{Container: "mysql-8.0-3306", Database: "craft", Name: "craft-2021-01-02-080102.sql"},
+		{Container: "mysql-8.0-3306", Database: "nitro", Name: "nitro-2021-03-02-080102.sql"},
+		{Container: "mysql-8.0-3306", Database: "nitro", Name: "nitro-2021-01-02-080102.sql"},
+		{Container: "postgres-13-5432", Database: "nitro", Name: "nitro-2021-01-02-080102.sql"},
+	}
+
+	engines := unique(backups, func(f backup.File) string { return f.Container })
+	if want := []string{"mysql-8.0-3306", "postgres-13-5432"}; !reflect.DeepEqual(engines, want) {
+		t.Errorf("expected the engines %v, got %v", want, engines)
+	}
+
+	filtered := filterBackups(backups, func(f backup.File) bool { return f.Container == "mysql-8.0-3306" })
+
+	databases := unique(filtered, func(f backup.File) string { return f.Database })
+	if want := []string{"craft", "nitro"}; !reflect.DeepEqual(databases, want) {
+		t.Errorf("expected the databases %v, got %v", want, databases)
+	}
+
+	filtered = filterBackups(filtered, func(f backup.File) bool { return f.Database == "nitro" })
+	if len(filtered) != 2 || filtered[0].Name != "nitro-2021-03-02-080102.sql" {
+		t.Errorf("expected the nitro backups newest first, got %v", filtered)
+	}
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+var shellExampleText = `  # open a database client and choose the engine
+  nitro db shell
+
+  # open a database client for an engine and database
+  nitro db shell mysql-8.0-3306 --database nitro`
+
+func shellClientCommand(docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "shell [hostname]",
+		Short:   "Opens a database client in a database container.",
+		Example: shellExampleText,
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// add filters to show only the database containers
+			filter := filters.NewArgs()
+			filter.Add("label", containerlabels.Nitro)
+			filter.Add("label", containerlabels.Type+"=database")
+
+			containers, err := docker.ContainerList(cmd.Context(), types.ContainerListOptions{Filters: filter})
+			if err != nil {
+				return err
+			}
+
+			if len(containers) == 0 {
+				return fmt.Errorf("there are no running database containers")
+			}
+
+			// sort containers by the name
+			sort.SliceStable(containers, func(i, j int) bool {
+				return containers[i].Names[0] < containers[j].Names[0]
+			})
+
+			var containerList []string
+			for _, c := range containers {
+				containerList = append(containerList, strings.TrimLeft(c.Names[0], "/"))
+			}
+
+			var container types.Container
+			var name string
+			switch len(args) {
+			case 1:
+				c, n, ok := findContainer(containers, args[0])
+				if !ok {
+					return fmt.Errorf("unable to find a running database container for %s", args[0])
+				}
+
+				container, name = c, n
+			default:
+				selected, err := output.Select(cmd.InOrStdin(), "Select a database to connect to: ", containerList)
+				if err != nil {
+					return err
+				}
+
+				container, name = containers[selected], containerList[selected]
+			}
+
+			compatibility := container.Labels[containerlabels.DatabaseCompatibility]
+
+			return containerConnect(output, name, clientCommand(compatibility, cmd.Flag("database").Value.String()))
+		},
+	}
+
+	cmd.Flags().String("database", "", "the database to connect to")
+
+	return cmd
+}
+
+// clientCommand returns the command to open the database client for the engine with
+// the nitro credentials. When db is not empty, the client connects to the database.
+func clientCommand(compatibility, db string) []string {
+	switch compatibility {
+	case "postgres":
+		if db == "" {
+			db = "postgres"
+		}
+
+		return []string{"psql", "--username=nitro", "--dbname=" + db}
+	case "mongodb":
+		return []string{"sh", "-c", fmt.Sprintf("exec $(command -v mongosh || command -v mongo) --username nitro --password nitro --authenticationDatabase admin %s", db)}
+	default:
+		command := []string{"mysql", "-h", "127.0.0.1", "-unitro", "-pnitro"}
+		if db != "" {
+			command = append(command, db)
+		}
+
+		return command
+	}
+}
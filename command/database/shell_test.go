@@ -0,0 +1,51 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_clientCommand(t *testing.T) {
+	type args struct {
+		compatibility string
+		db            string
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "mysql opens the mysql client",
+			args: args{compatibility: "mysql"},
+			want: []string{"mysql", "-h", "127.0.0.1", "-unitro", "-pnitro"},
+		},
+		{
+			name: "mysql can connect to a database",
+			args: args{compatibility: "mysql", db: "nitro"},
+			want: []string{"mysql", "-h", "127.0.0.1", "-unitro", "-pnitro", "nitro"},
+		},
+		{
+			name: "postgres connects to the default database",
+			args: args{compatibility: "postgres"},
+			want: []string{"psql", "--username=nitro", "--dbname=postgres"},
+		},
+		{
+			name: "postgres can connect to a database",
+			args: args{compatibility: "postgres", db: "nitro"},
+			want: []string{"psql", "--username=nitro", "--dbname=nitro"},
+		},
+		{
+			name: "mongodb opens the mongo shell",
+			args: args{compatibility: "mongodb", db: "nitro"},
+			want: []string{"sh", "-c", "exec $(command -v mongosh || command -v mongo) --username nitro --password nitro --authenticationDatabase admin nitro"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := clientCommand(tt.args.compatibility, tt.args.db); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("clientCommand() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -14,7 +14,7 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var sshExampleText = `  # ssh into a database container
+var sshExampleText = `  # ssh into a database container, mongodb containers open the mongo shell
   nitro db ssh`
 
 func sshCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
@@ -64,21 +64,32 @@ func sshCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 
 			container := containerList[selected]
 
-			return containerConnect(output, container)
+			return containerConnect(output, container, shellCommand(containers[selected].Labels[containerlabels.DatabaseCompatibility]))
 		},
 	}
 
 	return cmd
 }
 
-func containerConnect(output terminal.Outputer, containerName string) error {
+// shellCommand returns the command to run in the database container, mongodb
+// containers open the mongo shell and other containers open bash.
+func shellCommand(compatibility string) []string {
+	if compatibility == "mongodb" {
+		// newer images only include mongosh
+		return []string{"sh", "-c", "exec $(command -v mongosh || command -v mongo) --username nitro --password nitro --authenticationDatabase admin"}
+	}
+
+	return []string{"bash"}
+}
+
+func containerConnect(output terminal.Outputer, containerName string, shell []string) error {
 	// find the docker executable
 	cli, err := exec.LookPath("docker")
 	if err != nil {
 		return err
 	}
 
-	c := exec.Command(cli, "exec", "-u", "root", "-it", containerName, "bash")
+	c := exec.Command(cli, append([]string{"exec", "-u", "root", "-it", containerName}, shell...)...)
 
 	c.Stdin = os.Stdin
 	c.Stderr = os.Stderr
@@ -0,0 +1,135 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/backup"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+var truncateExampleText = `  # empty all of the tables in a database without dropping it
+  nitro db truncate mysql-8.0-3306 nitro`
+
+func truncateCommand(docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "truncate <hostname> <database>",
+		Short:   "Truncates all tables in a database.",
+		Example: truncateExampleText,
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			hostname, db := args[0], args[1]
+
+			// add filters to show only the database containers
+			filter := filters.NewArgs()
+			filter.Add("label", containerlabels.Nitro)
+			filter.Add("label", containerlabels.Type+"=database")
+
+			containers, err := docker.ContainerList(cmd.Context(), types.ContainerListOptions{Filters: filter})
+			if err != nil {
+				return err
+			}
+
+			// find the container for the hostname
+			container, hostname, ok := findContainer(containers, hostname)
+			if !ok {
+				return fmt.Errorf("unable to find a running database container for %s", args[0])
+			}
+
+			engine := container.Labels[containerlabels.DatabaseCompatibility]
+
+			// get the tables in the database
+			out, err := backup.Exec(cmd.Context(), docker, container.ID, tablesCommand(engine, db))
+			if err != nil {
+				return err
+			}
+
+			tables := parseTables(out)
+			if len(tables) == 0 {
+				output.Info("There are no tables in", db)
+
+				return nil
+			}
+
+			confirm, err := output.Confirm(fmt.Sprintf("Truncate %d tables in %s on %s", len(tables), db, hostname), false, "?")
+			if err != nil {
+				return err
+			}
+
+			if !confirm {
+				output.Info("Skipping truncating", db)
+
+				return nil
+			}
+
+			output.Pending("truncating", db)
+
+			if _, err := backup.Exec(cmd.Context(), docker, container.ID, truncateCommands(engine, db, tables)); err != nil {
+				output.Warning()
+
+				return err
+			}
+
+			output.Done()
+
+			output.Info(fmt.Sprintf("Truncated %d tables in %s 🧹", len(tables), db))
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// tablesCommand returns the engine specific command to list the tables in a database, one per line.
+func tablesCommand(engine, db string) []string {
+	if engine == "postgres" {
+		return []string{"psql", "--username=nitro", "--dbname=" + db, "--tuples-only", "--no-align", "--command", `SELECT tablename FROM pg_tables WHERE schemaname = current_schema();`}
+	}
+
+	return []string{"mysql", "-unitro", "-pnitro", "--skip-column-names", "--batch", "-e", fmt.Sprintf("SELECT table_name FROM information_schema.tables WHERE table_schema = '%s' AND table_type = 'BASE TABLE';", strings.ReplaceAll(db, "'", "''"))}
+}
+
+// parseTables takes the output from the tables command and returns the table names.
+func parseTables(out string) []string {
+	var tables []string
+	for _, l := range strings.Split(out, "\n") {
+		l = strings.TrimSpace(l)
+
+		// ignore empty lines and the mysql password warning
+		if l == "" || strings.Contains(l, "password on the command line") {
+			continue
+		}
+
+		tables = append(tables, l)
+	}
+
+	return tables
+}
+
+// truncateCommands returns the engine specific command to truncate all of the tables. MySQL disables
+// the foreign key checks while truncating and postgres uses cascade so the order of tables does not matter.
+func truncateCommands(engine, db string, tables []string) []string {
+	if engine == "postgres" {
+		var quoted []string
+		for _, t := range tables {
+			quoted = append(quoted, `"`+strings.ReplaceAll(t, `"`, `""`)+`"`)
+		}
+
+		return []string{"psql", "--username=nitro", "--dbname=" + db, "--command", fmt.Sprintf("TRUNCATE TABLE %s CASCADE;", strings.Join(quoted, ", "))}
+	}
+
+	statements := []string{"SET FOREIGN_KEY_CHECKS=0;"}
+	for _, t := range tables {
+		statements = append(statements, fmt.Sprintf("TRUNCATE TABLE `%s`;", strings.ReplaceAll(t, "`", "``")))
+	}
+	statements = append(statements, "SET FOREIGN_KEY_CHECKS=1;")
+
+	return []string{"mysql", "-unitro", "-pnitro", db, "-e", strings.Join(statements, " ")}
+}
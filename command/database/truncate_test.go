@@ -0,0 +1,111 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parseTables(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		want []string
+	}{
+		{
+			name: "mysql output ignores the password warning",
+			out:  "mysql: [Warning] Using a password on the command line interface can be insecure.\ncraft_users\ncraft_entries\n",
+			want: []string{"craft_users", "craft_entries"},
+		},
+		{
+			name: "postgres output returns the tables",
+			out:  "users\nentries\n\n",
+			want: []string{"users", "entries"},
+		},
+		{
+			name: "empty output returns no tables",
+			out:  "\n",
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseTables(tt.out); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTables() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_truncateCommands(t *testing.T) {
+	type args struct {
+		engine string
+		db     string
+		tables []string
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "mysql disables foreign key checks",
+			args: args{
+				engine: "mysql",
+				db:     "nitro",
+				tables: []string{"users", "entries"},
+			},
+			want: []string{"mysql", "-unitro", "-pnitro", "nitro", "-e", "SET FOREIGN_KEY_CHECKS=0; TRUNCATE TABLE `users`; TRUNCATE TABLE `entries`; SET FOREIGN_KEY_CHECKS=1;"},
+		},
+		{
+			name: "postgres truncates with cascade",
+			args: args{
+				engine: "postgres",
+				db:     "nitro",
+				tables: []string{"users", "entries"},
+			},
+			want: []string{"psql", "--username=nitro", "--dbname=nitro", "--command", `TRUNCATE TABLE "users", "entries" CASCADE;`},
+		},
+		{
+			name: "table names are quoted",
+			args: args{
+				engine: "mysql",
+				db:     "nitro",
+				tables: []string{"odd`name"},
+			},
+			want: []string{"mysql", "-unitro", "-pnitro", "nitro", "-e", "SET FOREIGN_KEY_CHECKS=0; TRUNCATE TABLE `odd``name`; SET FOREIGN_KEY_CHECKS=1;"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncateCommands(tt.args.engine, tt.args.db, tt.args.tables); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("truncateCommands() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_tablesCommand(t *testing.T) {
+	tests := []struct {
+		name   string
+		engine string
+		want   string
+	}{
+		{
+			name:   "mysql queries the information schema",
+			engine: "mysql",
+			want:   "mysql",
+		},
+		{
+			name:   "postgres queries the pg tables",
+			engine: "postgres",
+			want:   "psql",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tablesCommand(tt.engine, "nitro"); got[0] != tt.want {
+				t.Errorf("tablesCommand() = %v, want the %s executable", got, tt.want)
+			}
+		})
+	}
+}
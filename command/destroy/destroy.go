@@ -17,7 +17,7 @@ import (
 	"github.com/craftcms/nitro/pkg/backup"
 	"github.com/craftcms/nitro/pkg/config"
 	"github.com/craftcms/nitro/pkg/containerlabels"
-	"github.com/craftcms/nitro/pkg/datetime"
+	"github.com/craftcms/nitro/pkg/resolver"
 	"github.com/craftcms/nitro/pkg/sudo"
 	"github.com/craftcms/nitro/pkg/terminal"
 )
@@ -132,26 +132,21 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 						// backup each database
 						for _, db := range databases {
 							// create the database specific backup options
+							compatibility := c.Labels[containerlabels.DatabaseCompatibility]
 							opts := &backup.Options{
-								BackupName:    fmt.Sprintf("%s-%s.sql", db, datetime.Parse(time.Now())),
+								BackupName:    backup.FileName(compatibility, db, time.Now()),
 								ContainerID:   c.ID,
 								ContainerName: name,
 								Database:      db,
-								Home:          home,
 							}
 
 							// create the backup command based on the compatibility type
-							switch c.Labels[containerlabels.DatabaseCompatibility] {
-							case "postgres":
-								opts.Commands = []string{"pg_dump", "--username=nitro", db, "-f", "/tmp/" + opts.BackupName}
-							default:
-								opts.Commands = []string{"/usr/bin/mysqldump", "-h", "127.0.0.1", "-unitro", "--password=nitro", db, "--result-file=" + "/tmp/" + opts.BackupName}
-							}
+							opts.Commands = backup.Commands(compatibility, db)
 
 							output.Pending("creating backup", opts.BackupName)
 
 							// backup the container
-							if err := backup.Perform(ctx, docker, opts); err != nil {
+							if err := backup.ToFile(ctx, docker, home, opts); err != nil {
 								output.Warning()
 								output.Info("Unable to backup database", db, err.Error())
 
@@ -250,6 +245,32 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 				}
 			}
 
+			// remove the resolver for the dns container
+			if cfg.Proxy.DNS {
+				installed, err := resolver.IsInstalled(cfg.GetTLD(), cfg.GetDNSPort())
+				if err == nil && installed {
+					args := []string{"dns", "remove", "--tld=" + cfg.GetTLD()}
+
+					switch runtime.GOOS {
+					case "windows":
+						c := exec.Command(nitro, args...)
+
+						c.Stdout = os.Stdout
+						c.Stderr = os.Stderr
+
+						if err := c.Run(); err != nil {
+							return err
+						}
+					default:
+						output.Info("Removing the resolver (you might be prompted for your password)")
+
+						if err := sudo.Run(nitro, append([]string{"nitro"}, args...)...); err != nil {
+							return err
+						}
+					}
+				}
+			}
+
 			output.Info("Nitro destroyed ✨")
 
 			return nil
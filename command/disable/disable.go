@@ -42,7 +42,7 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 
 			return nil
 		},
-		ValidArgs: []string{"dynamodb", "mailhog", "minio", "redis"},
+		ValidArgs: []string{"dynamodb", "elasticsearch", "mailhog", "meilisearch", "minio", "rabbitmq", "redis"},
 		Example:   exampleText,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// load the configuration
@@ -55,10 +55,16 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 			switch args[0] {
 			case "dynamodb":
 				cfg.Services.DynamoDB = false
+			case "elasticsearch":
+				cfg.Services.Elasticsearch = false
 			case "mailhog":
 				cfg.Services.Mailhog = false
+			case "meilisearch":
+				cfg.Services.Meilisearch = false
 			case "minio":
 				cfg.Services.Minio = false
+			case "rabbitmq":
+				cfg.Services.RabbitMQ = false
 			case "redis":
 				cfg.Services.Redis = false
 			default:
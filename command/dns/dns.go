@@ -0,0 +1,96 @@
+package dns
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/resolver"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+const exampleText = `  # send queries for the top level domain to the dns container
+  nitro dns install --tld nitro --port 5354
+
+  # remove the resolver for the top level domain
+  nitro dns remove --tld nitro`
+
+// NewCommand returns the command to configure the system resolver for the DNS container. It is
+// run by apply with sudo when the dns option is enabled for the proxy, so the hosts file does
+// not need to be edited for each site.
+func NewCommand(output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "dns",
+		Short:   "Manages the resolver for the DNS container.",
+		Example: exampleText,
+	}
+
+	install := &cobra.Command{
+		Use:   "install",
+		Short: "Installs the resolver for the top level domain.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := verifyRoot(); err != nil {
+				return err
+			}
+
+			tld := cmd.Flag("tld").Value.String()
+
+			output.Pending("installing resolver for", "."+tld)
+
+			if err := resolver.Install(tld, cmd.Flag("port").Value.String()); err != nil {
+				output.Warning()
+				return err
+			}
+
+			output.Done()
+
+			return nil
+		},
+	}
+	install.Flags().String("tld", "nitro", "the top level domain to resolve")
+	install.Flags().String("port", "5354", "the port of the dns container on the host")
+
+	remove := &cobra.Command{
+		Use:   "remove",
+		Short: "Removes the resolver for the top level domain.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := verifyRoot(); err != nil {
+				return err
+			}
+
+			tld := cmd.Flag("tld").Value.String()
+
+			output.Pending("removing resolver for", "."+tld)
+
+			if err := resolver.Remove(tld); err != nil {
+				output.Warning()
+				return err
+			}
+
+			output.Done()
+
+			return nil
+		},
+	}
+	remove.Flags().String("tld", "nitro", "the top level domain to stop resolving")
+
+	cmd.AddCommand(install, remove)
+
+	return cmd
+}
+
+// verifyRoot returns an error when the command is not run as root, windows users are
+// expected to be running as an administrator.
+func verifyRoot() error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	if uid := os.Geteuid(); uid != 0 && uid != -1 {
+		return fmt.Errorf("you do not appear to be running this command as root, so we cannot modify the resolver")
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package doctor
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+)
+
+// minFreeSpace is the disk space in bytes that should be available for containers and volumes
+const minFreeSpace = 2 << 30
+
+// publishedPorts returns the ports on the host that are published by the container.
+func publishedPorts(c types.Container) map[string]bool {
+	ports := map[string]bool{}
+	for _, p := range c.Ports {
+		if p.PublicPort != 0 {
+			ports[strconv.Itoa(int(p.PublicPort))] = true
+		}
+	}
+
+	return ports
+}
+
+// missingHosts returns the hostnames that are not in the nitro section of the hosts file.
+func missingHosts(hosts string, hostnames []string) []string {
+	found := map[string]bool{}
+	inSection := false
+	for _, line := range strings.Split(hosts, "\n") {
+		line = strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(line, "# <nitro>"):
+			inSection = true
+			continue
+		case strings.HasPrefix(line, "# </nitro>"):
+			inSection = false
+			continue
+		}
+
+		if !inSection || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		for _, h := range fields[1:] {
+			found[h] = true
+		}
+	}
+
+	var missing []string
+	for _, h := range hostnames {
+		if !found[h] {
+			missing = append(missing, h)
+		}
+	}
+
+	sort.Strings(missing)
+
+	return missing
+}
+
+// parseCertificate returns the certificate from the PEM encoded file and an error when the
+// certificate has expired.
+func parseCertificate(b []byte, now time.Time) (*x509.Certificate, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("the certificate is not PEM encoded")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the certificate, %w", err)
+	}
+
+	if now.After(cert.NotAfter) {
+		return cert, fmt.Errorf("the certificate expired on %s", cert.NotAfter.Format("2006-01-02"))
+	}
+
+	return cert, nil
+}
+
+// parseAvailable returns the available space in bytes from the output of `df -Pk`.
+func parseAvailable(output string) (int64, error) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) < 2 {
+		return 0, fmt.Errorf("unable to parse the disk space from %q", output)
+	}
+
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) < 4 {
+		return 0, fmt.Errorf("unable to parse the disk space from %q", output)
+	}
+
+	kb, err := strconv.ParseInt(fields[3], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse the disk space, %w", err)
+	}
+
+	return kb * 1024, nil
+}
+
+// humanize returns the bytes in GB with one decimal (e.g. 1.5 GB).
+func humanize(b int64) string {
+	return fmt.Sprintf("%.1f GB", float64(b)/(1<<30))
+}
@@ -0,0 +1,169 @@
+package doctor
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+)
+
+func Test_publishedPorts(t *testing.T) {
+	c := types.Container{
+		Ports: []types.Port{
+			{PrivatePort: 80, PublicPort: 80},
+			{PrivatePort: 443, PublicPort: 443},
+			{PrivatePort: 5000},
+		},
+	}
+
+	want := map[string]bool{"80": true, "443": true}
+	if got := publishedPorts(c); !reflect.DeepEqual(got, want) {
+		t.Errorf("publishedPorts() = %v, want %v", got, want)
+	}
+}
+
+func Test_missingHosts(t *testing.T) {
+	hosts := `127.0.0.1	localhost
+127.0.0.1	outside.nitro
+# <nitro>
+127.0.0.1	tutorial.nitro alias.nitro
+# </nitro>
+`
+
+	type args struct {
+		hosts     string
+		hostnames []string
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "hostnames in the nitro section are found",
+			args: args{
+				hosts:     hosts,
+				hostnames: []string{"tutorial.nitro", "alias.nitro"},
+			},
+		},
+		{
+			name: "hostnames outside of the nitro section are missing",
+			args: args{
+				hosts:     hosts,
+				hostnames: []string{"tutorial.nitro", "outside.nitro", "another.nitro"},
+			},
+			want: []string{"another.nitro", "outside.nitro"},
+		},
+		{
+			name: "hosts files without a nitro section are missing every hostname",
+			args: args{
+				hosts:     "127.0.0.1	localhost\n",
+				hostnames: []string{"tutorial.nitro"},
+			},
+			want: []string{"tutorial.nitro"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := missingHosts(tt.args.hosts, tt.args.hostnames); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("missingHosts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseCertificate(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notAfter := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "Caddy Local Authority"},
+		NotBefore:    time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:     notAfter,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+
+	type args struct {
+		b   []byte
+		now time.Time
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "valid certificates are parsed",
+			args: args{b: cert, now: notAfter.Add(-time.Hour)},
+		},
+		{
+			name:    "expired certificates return an error",
+			args:    args{b: cert, now: notAfter.Add(time.Hour)},
+			wantErr: true,
+		},
+		{
+			name:    "files that are not PEM encoded return an error",
+			args:    args{b: []byte("not a certificate"), now: notAfter},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseCertificate(tt.args.b, tt.args.now); (err != nil) != tt.wantErr {
+				t.Errorf("parseCertificate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_parseAvailable(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    int64
+		wantErr bool
+	}{
+		{
+			name: "available space is returned in bytes",
+			output: `Filesystem           1024-blocks    Used Available Capacity Mounted on
+overlay               61255492  20311812  37802356  35% /
+`,
+			want: 37802356 * 1024,
+		},
+		{
+			name:    "output without the filesystem returns an error",
+			output:  "df: /: No such file or directory\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseAvailable(tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseAvailable() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("parseAvailable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
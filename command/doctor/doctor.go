@@ -0,0 +1,381 @@
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/portavail"
+	"github.com/craftcms/nitro/pkg/terminal"
+	"github.com/craftcms/nitro/protob"
+)
+
+var (
+	// hostsFile is the location of the hosts file
+	hostsFile = "/etc/hosts"
+
+	// pingTimeout is how long to wait for the proxy API to respond
+	pingTimeout = 5 * time.Second
+)
+
+const exampleText = `  # check the environment for common problems
+  nitro doctor`
+
+// problem is a failed check with the steps to fix it.
+type problem struct {
+	message string
+	fix     string
+}
+
+// NewCommand returns the command to check the environment for common problems. Each check
+// prints the steps to fix the problem when it fails.
+func NewCommand(home string, docker client.CommonAPIClient, nitrod protob.NitroClient, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "doctor",
+		Short:   "Checks the environment for problems.",
+		Example: exampleText,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			var problems []problem
+			check := func(name string, fn func() *problem) bool {
+				output.Pending("checking", name)
+
+				p := fn()
+				if p == nil {
+					output.Done()
+
+					return true
+				}
+
+				output.Warning()
+				problems = append(problems, *p)
+
+				return false
+			}
+
+			output.Info("Checking Nitro…")
+
+			dockerRunning := check("docker", func() *problem {
+				if _, err := docker.Ping(ctx); err != nil {
+					return &problem{
+						message: "unable to connect to Docker",
+						fix:     "start Docker and run `nitro doctor` again",
+					}
+				}
+
+				return nil
+			})
+
+			var cfg *config.Config
+			check("config", func() *problem {
+				var err error
+				cfg, err = config.Load(home)
+				if err != nil {
+					return &problem{
+						message: fmt.Sprintf("unable to load the config, %s", err),
+						fix:     "run `nitro init` to create the config",
+					}
+				}
+
+				lint, err := cfg.Lint(home)
+				if err != nil {
+					return &problem{message: err.Error(), fix: "run `nitro edit` to fix the config"}
+				}
+
+				if len(lint) > 0 {
+					var messages []string
+					for _, l := range lint {
+						messages = append(messages, l.String())
+					}
+
+					return &problem{
+						message: fmt.Sprintf("the config has %d problems: %s", len(lint), strings.Join(messages, "; ")),
+						fix:     "run `nitro edit` to fix the config",
+					}
+				}
+
+				return nil
+			})
+
+			// the config is used for the ports and hostnames
+			if cfg == nil {
+				cfg = &config.Config{}
+			}
+
+			var proxy *types.Container
+			if dockerRunning {
+				check("network", func() *problem {
+					return checkNetwork(ctx, docker)
+				})
+
+				check("proxy", func() *problem {
+					var p *problem
+					proxy, p = checkProxy(ctx, docker, nitrod)
+
+					return p
+				})
+
+				check("ports", func() *problem {
+					return checkPorts(cfg, proxy)
+				})
+			} else {
+				output.Info("Skipping the network, proxy, and port checks since Docker is not running")
+			}
+
+			check("hosts file", func() *problem {
+				return checkHosts(cfg)
+			})
+
+			check("certificate", func() *problem {
+				return checkCertificate(home)
+			})
+
+			if proxy != nil && proxy.State == "running" {
+				check("disk space", func() *problem {
+					return checkDiskSpace(ctx, docker, proxy.ID)
+				})
+			}
+
+			if len(problems) == 0 {
+				output.Info("No problems found 🎉")
+
+				return nil
+			}
+
+			output.Info("")
+			output.Info("Problems:")
+			for _, p := range problems {
+				output.Info(" \u2610", p.message)
+				output.Info("   ", p.fix)
+			}
+
+			return fmt.Errorf("found %d problems", len(problems))
+		},
+	}
+
+	return cmd
+}
+
+// checkNetwork verifies the network exists and has the labels used to find it.
+func checkNetwork(ctx context.Context, docker client.NetworkAPIClient) *problem {
+	filter := filters.NewArgs()
+	filter.Add("name", "nitro-network")
+
+	networks, err := docker.NetworkList(ctx, types.NetworkListOptions{Filters: filter})
+	if err != nil {
+		return &problem{message: fmt.Sprintf("unable to list the docker networks, %s", err), fix: "restart Docker"}
+	}
+
+	// the name filter matches partial names
+	for _, n := range networks {
+		if n.Name != "nitro-network" {
+			continue
+		}
+
+		if n.Labels[containerlabels.Nitro] != "true" || n.Labels[containerlabels.Network] != "true" {
+			return &problem{
+				message: "the nitro network is missing its labels",
+				fix:     "run `nitro init` to repair the network",
+			}
+		}
+
+		return nil
+	}
+
+	return &problem{
+		message: "the nitro network does not exist",
+		fix:     "run `nitro init` to create the network",
+	}
+}
+
+// checkProxy verifies the proxy container is running and the API responds. The proxy container
+// is returned when it exists.
+func checkProxy(ctx context.Context, docker client.ContainerAPIClient, nitrod protob.NitroClient) (*types.Container, *problem) {
+	filter := filters.NewArgs()
+	filter.Add("label", containerlabels.Nitro)
+	filter.Add("label", containerlabels.Proxy+"=true")
+
+	containers, err := docker.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: filter})
+	if err != nil {
+		return nil, &problem{message: fmt.Sprintf("unable to list the containers, %s", err), fix: "restart Docker"}
+	}
+
+	if len(containers) == 0 {
+		return nil, &problem{
+			message: "the proxy container does not exist",
+			fix:     "run `nitro init` to create the proxy",
+		}
+	}
+
+	proxy := &containers[0]
+	if proxy.State != "running" {
+		return proxy, &problem{
+			message: "the proxy container is not running",
+			fix:     "run `nitro start` to start the containers",
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	if _, err := nitrod.Ping(ctx, &protob.PingRequest{}); err != nil {
+		return proxy, &problem{
+			message: fmt.Sprintf("the proxy API is not responding, %s", err),
+			fix:     "run `nitro logs nitro-proxy` to show the proxy logs or `nitro init` to recreate the proxy",
+		}
+	}
+
+	return proxy, nil
+}
+
+// checkPorts verifies the ports for the proxy are not used by another application.
+func checkPorts(cfg *config.Config, proxy *types.Container) *problem {
+	ports := []string{cfg.GetHTTPPort(), cfg.GetHTTPSPort()}
+	if cfg.GetAPITransport() == config.APITransportTCP {
+		ports = append(ports, cfg.GetAPIPort())
+	}
+
+	published := map[string]bool{}
+	if proxy != nil && proxy.State == "running" {
+		published = publishedPorts(*proxy)
+	}
+
+	var used []string
+	for _, p := range ports {
+		if published[p] {
+			continue
+		}
+
+		if err := portavail.Check("localhost", p); err != nil {
+			used = append(used, p)
+		}
+	}
+
+	if len(used) == 0 {
+		return nil
+	}
+
+	return &problem{
+		message: fmt.Sprintf("ports %s are in use by another application", strings.Join(used, ", ")),
+		fix:     "stop the application using the ports or set different proxy ports in the config",
+	}
+}
+
+// checkHosts verifies the hosts file has the sites hostnames, unless the hosts file is not
+// managed by nitro.
+func checkHosts(cfg *config.Config) *problem {
+	if os.Getenv("NITRO_EDIT_HOSTS") == "false" || cfg.Proxy.DNS {
+		return nil
+	}
+
+	var hostnames []string
+	for _, s := range cfg.Sites {
+		hostnames = append(hostnames, s.Hostname)
+		hostnames = append(hostnames, s.Aliases...)
+	}
+
+	if len(hostnames) == 0 {
+		return nil
+	}
+
+	if runtime.GOOS == "windows" {
+		hostsFile = `C:\Windows\System32\Drivers\etc\hosts`
+	}
+
+	b, err := ioutil.ReadFile(hostsFile)
+	if err != nil {
+		return &problem{message: fmt.Sprintf("unable to read the hosts file, %s", err), fix: "check the permissions of " + hostsFile}
+	}
+
+	if missing := missingHosts(string(b), hostnames); len(missing) > 0 {
+		return &problem{
+			message: fmt.Sprintf("the hosts file is missing %s", strings.Join(missing, ", ")),
+			fix:     "run `nitro apply` to update the hosts file",
+		}
+	}
+
+	return nil
+}
+
+// checkCertificate verifies the root certificate from the proxy is trusted by the system.
+func checkCertificate(home string) *problem {
+	b, err := ioutil.ReadFile(filepath.Join(home, config.DirectoryName, "nitro.crt"))
+	if err != nil {
+		return &problem{
+			message: "the nitro certificate has not been trusted",
+			fix:     "run `nitro trust` to trust the certificate",
+		}
+	}
+
+	cert, err := parseCertificate(b, time.Now())
+	if err != nil {
+		return &problem{
+			message: err.Error(),
+			fix:     "run `nitro trust renew` to create a new certificate",
+		}
+	}
+
+	// an empty pool uses the system roots
+	if _, err := cert.Verify(x509.VerifyOptions{}); err != nil {
+		return &problem{
+			message: "the nitro certificate is not trusted by the system",
+			fix:     "run `nitro trust` to trust the certificate",
+		}
+	}
+
+	return nil
+}
+
+// checkDiskSpace verifies there is enough space for containers and volumes. The proxy container
+// shares the disk with the volumes, so the available space is checked from the container.
+func checkDiskSpace(ctx context.Context, docker client.ContainerAPIClient, id string) *problem {
+	exec, err := docker.ContainerExecCreate(ctx, id, types.ExecConfig{
+		AttachStdout: true,
+		AttachStderr: true,
+		Cmd:          []string{"df", "-Pk", "/"},
+	})
+	if err != nil {
+		return &problem{message: fmt.Sprintf("unable to check the disk space, %s", err), fix: "run `nitro start` to start the containers"}
+	}
+
+	resp, err := docker.ContainerExecAttach(ctx, exec.ID, types.ExecStartCheck{})
+	if err != nil {
+		return &problem{message: fmt.Sprintf("unable to check the disk space, %s", err), fix: "run `nitro start` to start the containers"}
+	}
+	defer resp.Close()
+
+	buf := &bytes.Buffer{}
+	if _, err := stdcopy.StdCopy(buf, buf, resp.Reader); err != nil {
+		return &problem{message: fmt.Sprintf("unable to check the disk space, %s", err), fix: "run `nitro start` to start the containers"}
+	}
+
+	available, err := parseAvailable(buf.String())
+	if err != nil {
+		return &problem{message: err.Error(), fix: "run `docker system df` to check the disk space"}
+	}
+
+	if available < minFreeSpace {
+		return &problem{
+			message: fmt.Sprintf("only %s of disk space is available for containers and volumes", humanize(available)),
+			fix:     "run `nitro clean` to remove unused containers or increase the disk size for Docker",
+		}
+	}
+
+	return nil
+}
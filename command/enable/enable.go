@@ -28,7 +28,16 @@ const exampleText = `  # enable services
   nitro enable minio
 
   # enable dynamodb for local noSQL
-  nitro enable dynamodb`
+  nitro enable dynamodb
+
+  # enable elasticsearch for local search
+  nitro enable elasticsearch
+
+  # enable meilisearch for local search
+  nitro enable meilisearch
+
+  # enable rabbitmq for local queues
+  nitro enable rabbitmq`
 
 // NewCommand returns the command to enable common nitro services. These services are provided as containers
 // and do not require a user to configure the ports/volumes or images.
@@ -45,7 +54,7 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 
 			return nil
 		},
-		ValidArgs: []string{"dynamodb", "mailhog", "minio", "redis"},
+		ValidArgs: []string{"dynamodb", "elasticsearch", "mailhog", "meilisearch", "minio", "rabbitmq", "redis"},
 		Example:   exampleText,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// load the configuration
@@ -58,10 +67,16 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 			switch args[0] {
 			case "dynamodb":
 				cfg.Services.DynamoDB = true
+			case "elasticsearch":
+				cfg.Services.Elasticsearch = true
 			case "mailhog":
 				cfg.Services.Mailhog = true
+			case "meilisearch":
+				cfg.Services.Meilisearch = true
 			case "minio":
 				cfg.Services.Minio = true
+			case "rabbitmq":
+				cfg.Services.RabbitMQ = true
 			case "redis":
 				cfg.Services.Redis = true
 			default:
@@ -0,0 +1,234 @@
+package env
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/backup"
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/envedit"
+	"github.com/craftcms/nitro/pkg/pathexists"
+	"github.com/craftcms/nitro/pkg/prompt"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+const exampleText = `  # update the .env for the site in the current directory
+  nitro env sync
+
+  # update the .env for a site with a database
+  nitro env sync tutorial.nitro --engine mysql-8.0-3306 --database tutorial`
+
+// NewCommand returns the command to manage the environment variables in a sites .env file.
+func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "env",
+		Short:   "Manages a site’s .env file.",
+		Example: exampleText,
+	}
+
+	cmd.AddCommand(syncCommand(home, docker, output))
+
+	return cmd
+}
+
+func syncCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sync [hostname]",
+		Short: "Sets the database and site URL in a site’s .env.",
+		Long: `Sets the database credentials for a database in nitro and the site URL for the
+hostname in the site’s .env file. The .env is created from the .env.example when
+it does not exist, other variables are not changed.`,
+		Args: cobra.MaximumNArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return prompt.VerifyInit(cmd, args, home, output)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(home)
+			if err != nil {
+				return err
+			}
+
+			// merge the project files from the sites
+			if _, err := cfg.MergeProjects(home); err != nil {
+				return err
+			}
+
+			site, err := prompt.SelectSite(cmd, home, cfg, args, output)
+			if err != nil {
+				return err
+			}
+
+			path, err := site.GetAbsPath(home)
+			if err != nil {
+				return err
+			}
+
+			// add filters to show only the database containers
+			filter := filters.NewArgs()
+			filter.Add("label", containerlabels.Nitro)
+			filter.Add("label", containerlabels.Type+"=database")
+
+			containers, err := docker.ContainerList(cmd.Context(), types.ContainerListOptions{Filters: filter})
+			if err != nil {
+				return err
+			}
+
+			// craft only supports mysql and postgres
+			var engines []types.Container
+			for _, c := range containers {
+				if c.Labels[containerlabels.DatabaseCompatibility] != "mongodb" {
+					engines = append(engines, c)
+				}
+			}
+
+			if len(engines) == 0 {
+				return fmt.Errorf("there are no running database containers…\n run `nitro start` to start the databases")
+			}
+
+			// sort containers by the name
+			sort.SliceStable(engines, func(i, j int) bool {
+				return engines[i].Names[0] < engines[j].Names[0]
+			})
+
+			var engineList []string
+			for _, c := range engines {
+				engineList = append(engineList, strings.TrimLeft(c.Names[0], "/"))
+			}
+
+			selected := -1
+			switch engine := cmd.Flag("engine").Value.String(); {
+			case engine != "":
+				for i, name := range engineList {
+					if name == engine {
+						selected = i
+					}
+				}
+
+				if selected == -1 {
+					return fmt.Errorf("unable to find a running database container for %s", engine)
+				}
+			case len(engineList) == 1:
+				selected = 0
+			default:
+				selected, err = output.Select(cmd.InOrStdin(), "Select the database engine: ", engineList)
+				if err != nil {
+					return err
+				}
+			}
+
+			container := engines[selected]
+			compatibility := container.Labels[containerlabels.DatabaseCompatibility]
+
+			db := cmd.Flag("database").Value.String()
+			if db == "" {
+				databases, err := backup.Databases(cmd.Context(), docker, container.ID, compatibility)
+				if err != nil {
+					return err
+				}
+
+				switch len(databases) {
+				case 0:
+					return fmt.Errorf("there are no databases in %s…\n run `nitro db add` to add a database", engineList[selected])
+				case 1:
+					db = databases[0]
+				default:
+					i, err := output.Select(cmd.InOrStdin(), "Select the database: ", databases)
+					if err != nil {
+						return err
+					}
+
+					db = databases[i]
+				}
+			}
+
+			file := filepath.Join(path, ".env")
+
+			// create the .env from the example
+			if !pathexists.IsFile(file) && pathexists.IsFile(filepath.Join(path, ".env.example")) {
+				b, err := ioutil.ReadFile(filepath.Join(path, ".env.example"))
+				if err != nil {
+					return err
+				}
+
+				if err := ioutil.WriteFile(file, b, 0644); err != nil {
+					return fmt.Errorf("unable to create the .env file, %w", err)
+				}
+			}
+
+			output.Pending("updating", file)
+
+			keys, updates := envUpdates(file, cfg.SiteURL("https", site.Hostname), engineList[selected], compatibility, db)
+
+			if err := envedit.Set(file, keys, updates); err != nil {
+				output.Warning()
+				return fmt.Errorf("unable to update the .env file, %w", err)
+			}
+
+			output.Done()
+
+			output.Info(site.Hostname, "is using the database", db, "on", engineList[selected])
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("engine", "", "the database engine hostname (e.g. mysql-8.0-3306)")
+	cmd.Flags().String("database", "", "the name of the database")
+
+	return cmd
+}
+
+// envUpdates returns the variables to set in the .env file, in the order they are added when
+// missing. Craft 4 projects use the CRAFT_ prefix for the database variables, which is used
+// when the file already has the prefixed variables.
+func envUpdates(file, siteURL, host, compatibility, db string) ([]string, map[string]string) {
+	// the existing file is empty when it does not exist
+	b, _ := ioutil.ReadFile(file)
+	existing := "\n" + string(b)
+
+	prefix := ""
+	if strings.Contains(existing, "\nCRAFT_DB_") {
+		prefix = "CRAFT_"
+	}
+
+	driver, port := "mysql", "3306"
+	if compatibility == "postgres" {
+		driver, port = "pgsql", "5432"
+	}
+
+	updates := map[string]string{
+		prefix + "DB_DRIVER":   driver,
+		prefix + "DB_SERVER":   host,
+		prefix + "DB_PORT":     port,
+		prefix + "DB_DATABASE": db,
+		prefix + "DB_USER":     "nitro",
+		prefix + "DB_PASSWORD": "nitro",
+		"DEFAULT_SITE_URL":     siteURL,
+	}
+
+	keys := []string{
+		prefix + "DB_DRIVER",
+		prefix + "DB_SERVER",
+		prefix + "DB_PORT",
+		prefix + "DB_DATABASE",
+		prefix + "DB_USER",
+		prefix + "DB_PASSWORD",
+		"DEFAULT_SITE_URL",
+	}
+
+	// newer projects use the primary site url instead
+	if strings.Contains(existing, "\nPRIMARY_SITE_URL=") {
+		updates["PRIMARY_SITE_URL"] = siteURL
+	}
+
+	return keys, updates
+}
@@ -0,0 +1,70 @@
+package env
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_envUpdates(t *testing.T) {
+	tests := []struct {
+		name          string
+		existing      string
+		compatibility string
+		host          string
+		wantKeys      []string
+		want          map[string]string
+	}{
+		{
+			name:          "mysql databases use the default variables",
+			existing:      "DB_DRIVER=mysql\nDB_SERVER=\n",
+			compatibility: "mysql",
+			host:          "mysql-8.0-3306",
+			wantKeys:      []string{"DB_DRIVER", "DB_SERVER", "DB_PORT", "DB_DATABASE", "DB_USER", "DB_PASSWORD", "DEFAULT_SITE_URL"},
+			want: map[string]string{
+				"DB_DRIVER":        "mysql",
+				"DB_SERVER":        "mysql-8.0-3306",
+				"DB_PORT":          "3306",
+				"DB_DATABASE":      "craft",
+				"DB_USER":          "nitro",
+				"DB_PASSWORD":      "nitro",
+				"DEFAULT_SITE_URL": "https://tutorial.nitro",
+			},
+		},
+		{
+			name:          "craft 4 projects use the prefixed variables",
+			existing:      "CRAFT_DB_DRIVER=mysql\nPRIMARY_SITE_URL=\n",
+			compatibility: "postgres",
+			host:          "postgres-13-5432",
+			wantKeys:      []string{"CRAFT_DB_DRIVER", "CRAFT_DB_SERVER", "CRAFT_DB_PORT", "CRAFT_DB_DATABASE", "CRAFT_DB_USER", "CRAFT_DB_PASSWORD", "DEFAULT_SITE_URL"},
+			want: map[string]string{
+				"CRAFT_DB_DRIVER":   "pgsql",
+				"CRAFT_DB_SERVER":   "postgres-13-5432",
+				"CRAFT_DB_PORT":     "5432",
+				"CRAFT_DB_DATABASE": "craft",
+				"CRAFT_DB_USER":     "nitro",
+				"CRAFT_DB_PASSWORD": "nitro",
+				"DEFAULT_SITE_URL":  "https://tutorial.nitro",
+				"PRIMARY_SITE_URL":  "https://tutorial.nitro",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), ".env")
+			if err := ioutil.WriteFile(file, []byte(tt.existing), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			keys, got := envUpdates(file, "https://tutorial.nitro", tt.host, tt.compatibility, "craft")
+			if !reflect.DeepEqual(keys, tt.wantKeys) {
+				t.Errorf("envUpdates() keys = %v, want %v", keys, tt.wantKeys)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("envUpdates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,428 @@
+package export
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/proxycontainer"
+	"github.com/craftcms/nitro/pkg/svc/dynamodb"
+	"github.com/craftcms/nitro/pkg/svc/elasticsearch"
+	"github.com/craftcms/nitro/pkg/svc/mailhog"
+	"github.com/craftcms/nitro/pkg/svc/meilisearch"
+	"github.com/craftcms/nitro/pkg/svc/minio"
+	"github.com/craftcms/nitro/pkg/svc/rabbitmq"
+	"github.com/craftcms/nitro/pkg/svc/redis"
+	"github.com/craftcms/nitro/pkg/terminal"
+	"github.com/craftcms/nitro/pkg/volumename"
+)
+
+const (
+	// siteImage is the image apply uses for the site containers, with the PHP version
+	siteImage = "docker.io/craftcms/nginx:%s-dev"
+
+	// networkName is the network the services are added to
+	networkName = "nitro-network"
+
+	// header is added to the top of the compose file
+	header = "# generated by nitro export compose, changes are overwritten the next time the config is exported\n"
+)
+
+type composeFile struct {
+	Services map[string]composeService `yaml:"services"`
+	Networks map[string]composeNetwork `yaml:"networks"`
+	Volumes  map[string]composeVolume  `yaml:"volumes,omitempty"`
+}
+
+type composeService struct {
+	Image       string                    `yaml:"image"`
+	Platform    string                    `yaml:"platform,omitempty"`
+	Command     []string                  `yaml:"command,omitempty"`
+	Environment map[string]string         `yaml:"environment,omitempty"`
+	Ports       []string                  `yaml:"ports,omitempty"`
+	Volumes     []string                  `yaml:"volumes,omitempty"`
+	ExtraHosts  []string                  `yaml:"extra_hosts,omitempty"`
+	DependsOn   []string                  `yaml:"depends_on,omitempty"`
+	Networks    map[string]composeNetwork `yaml:"networks"`
+}
+
+// composeNetwork is used for the networks and a services network, aliases
+// are only set for a service.
+type composeNetwork struct {
+	Aliases []string `yaml:"aliases,omitempty"`
+}
+
+type composeVolume struct{}
+
+func composeCommand(home string, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "compose",
+		Short: "Exports the config to a docker-compose.yml.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(home)
+			if err != nil {
+				return err
+			}
+
+			file := cmd.Flag("output").Value.String()
+
+			// site paths are relative to the compose file so it can be shared
+			dir := ""
+			if file != "-" {
+				file, err = filepath.Abs(file)
+				if err != nil {
+					return err
+				}
+
+				dir = filepath.Dir(file)
+			}
+
+			f, warnings, err := compose(home, dir, cfg)
+			if err != nil {
+				return err
+			}
+
+			data, err := yaml.Marshal(f)
+			if err != nil {
+				return err
+			}
+
+			data = append([]byte(header), data...)
+
+			if file == "-" {
+				_, err := cmd.OutOrStdout().Write(data)
+				return err
+			}
+
+			for _, w := range warnings {
+				output.Info("Warning:", w)
+			}
+
+			if err := ioutil.WriteFile(file, data, 0644); err != nil {
+				return fmt.Errorf("unable to write the compose file, %w", err)
+			}
+
+			output.Info("Exported the config to", file)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("output", "o", "docker-compose.yml", "the file to write, use - to print the file")
+
+	return cmd
+}
+
+// compose returns the compose file for the config and warnings for the settings that cannot
+// be exported. Site paths are relative to dir, or absolute if dir is empty.
+func compose(home, dir string, cfg *config.Config) (*composeFile, []string, error) {
+	f := &composeFile{
+		Services: make(map[string]composeService),
+		Networks: map[string]composeNetwork{networkName: {}},
+		Volumes:  make(map[string]composeVolume),
+	}
+
+	var warnings []string
+
+	// the proxy routes to the sites once nitro configures it with apply
+	f.Volumes["nitro"] = composeVolume{}
+	proxyPorts := []string{
+		fmt.Sprintf("127.0.0.1:%s:80", cfg.GetHTTPPort()),
+		fmt.Sprintf("127.0.0.1:%s:443", cfg.GetHTTPSPort()),
+	}
+
+	// other devices on the network reach the sites through the proxy
+	if cfg.Proxy.LAN {
+		proxyPorts = []string{
+			fmt.Sprintf("%s:80", cfg.GetHTTPPort()),
+			fmt.Sprintf("%s:443", cfg.GetHTTPSPort()),
+		}
+	}
+
+	// the api is reached through docker exec
+	if cfg.GetAPITransport() == config.APITransportTCP {
+		proxyPorts = append(proxyPorts, fmt.Sprintf("127.0.0.1:%s:5000", cfg.GetAPIPort()))
+	}
+
+	f.Services[proxycontainer.ProxyName] = composeService{
+		Image:       proxycontainer.ProxyImage,
+		Environment: map[string]string{"PGPASSWORD": "nitro", "PGUSER": "nitro"},
+		Ports:       append(proxyPorts, ports("3000"), ports("3001")),
+		Volumes:     []string{"nitro:/data"},
+		Networks:    network(),
+		ExtraHosts:  []string{"host.docker.internal:host-gateway"},
+	}
+
+	for _, d := range cfg.Databases {
+		hostname, err := d.GetHostname()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		s := composeService{
+			Image:    fmt.Sprintf("%s:%s", databaseImage(d.Engine), d.Version),
+			Platform: d.Platform,
+			Networks: network(),
+		}
+
+		target, port := "/var/lib/mysql", "3306"
+		switch d.Engine {
+		case "postgres":
+			target, port = "/var/lib/postgresql/data", "5432"
+			s.Environment = map[string]string{"POSTGRES_USER": "nitro", "POSTGRES_DB": "nitro", "POSTGRES_PASSWORD": "nitro"}
+		case "mongodb":
+			target, port = "/data/db", "27017"
+			s.Environment = map[string]string{"MONGO_INITDB_ROOT_USERNAME": "nitro", "MONGO_INITDB_ROOT_PASSWORD": "nitro", "MONGO_INITDB_DATABASE": "nitro"}
+		default:
+			s.Environment = map[string]string{"MYSQL_ROOT_PASSWORD": "nitro", "MYSQL_DATABASE": "nitro", "MYSQL_USER": "nitro", "MYSQL_PASSWORD": "nitro"}
+		}
+
+		if d.Engine == "mysql" {
+			s.Command = []string{"--character-set-server=utf8mb4", "--collation-server=utf8mb4_unicode_ci"}
+		}
+
+		s.Ports = []string{fmt.Sprintf("127.0.0.1:%s:%s", d.Port, port)}
+		s.Volumes = []string{hostname + ":" + target}
+
+		f.Volumes[hostname] = composeVolume{}
+		f.Services[hostname] = s
+	}
+
+	if cfg.Services.DynamoDB {
+		f.Services[dynamodb.Host] = composeService{
+			Image:    dynamodb.Image,
+			Command:  []string{"-jar", "DynamoDBLocal.jar", "-sharedDb", "-dbPath", "."},
+			Ports:    []string{ports("8000")},
+			Networks: network(),
+		}
+	}
+
+	if cfg.Services.Elasticsearch {
+		heapSize := cfg.Services.ElasticsearchHeapSize
+		if heapSize == "" {
+			heapSize = elasticsearch.DefaultHeapSize
+		}
+
+		f.Services[elasticsearch.Host] = composeService{
+			Image: elasticsearch.ImageForVersion(cfg.Services.ElasticsearchVersion),
+			Environment: map[string]string{
+				"discovery.type":         "single-node",
+				"xpack.security.enabled": "false",
+				"ES_JAVA_OPTS":           fmt.Sprintf("-Xms%s -Xmx%s", heapSize, heapSize),
+			},
+			Ports:    []string{ports(elasticsearch.Port)},
+			Networks: network(),
+		}
+	}
+
+	if cfg.Services.Mailhog {
+		f.Services[mailhog.Host] = composeService{
+			Image:    mailhog.Image,
+			Ports:    []string{ports("1025"), ports("8025")},
+			Networks: network(),
+		}
+	}
+
+	if cfg.Services.Meilisearch {
+		env := map[string]string{"MEILI_ENV": "development", "MEILI_NO_ANALYTICS": "true"}
+		if cfg.Services.MeilisearchMasterKey != "" {
+			env["MEILI_MASTER_KEY"] = cfg.Services.MeilisearchMasterKey
+		}
+
+		f.Services[meilisearch.Host] = composeService{
+			Image:       meilisearch.Image,
+			Environment: env,
+			Ports:       []string{ports(meilisearch.Port)},
+			Networks:    network(),
+		}
+	}
+
+	if cfg.Services.Minio {
+		user, password := cfg.Services.MinioUser, cfg.Services.MinioPassword
+		if user == "" {
+			user = minio.DefaultUser
+		}
+
+		if password == "" {
+			password = minio.DefaultPassword
+		}
+
+		f.Volumes[minio.Volume] = composeVolume{}
+		f.Services[minio.Host] = composeService{
+			Image:       minio.Image,
+			Command:     []string{"server", "/data", "--console-address", ":" + minio.ConsolePort},
+			Environment: map[string]string{"MINIO_ROOT_USER": user, "MINIO_ROOT_PASSWORD": password},
+			Ports:       []string{ports(minio.Port), ports(minio.ConsolePort)},
+			Volumes:     []string{minio.Volume + ":/data"},
+			Networks:    network(),
+		}
+	}
+
+	if cfg.Services.RabbitMQ {
+		f.Services[rabbitmq.Host] = composeService{
+			Image:       rabbitmq.Image,
+			Environment: map[string]string{"RABBITMQ_DEFAULT_USER": "nitro", "RABBITMQ_DEFAULT_PASS": "nitro"},
+			Ports:       []string{ports(rabbitmq.Port), ports(fmt.Sprintf("%d", rabbitmq.ManagementPort))},
+			Networks:    network(),
+		}
+	}
+
+	if cfg.Services.Redis {
+		f.Services[redis.Host] = composeService{
+			Image:    redis.ImageForVersion(cfg.Services.RedisVersion),
+			Ports:    []string{ports(redis.Port)},
+			Networks: network(),
+		}
+	}
+
+	for _, site := range cfg.Sites {
+		// the sites php settings override the defaults
+		site.PHP = cfg.PHP.Override(site.PHP)
+
+		path, err := site.GetAbsPath(home)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		source := path
+		if dir != "" {
+			switch rel, err := filepath.Rel(dir, path); {
+			case err != nil:
+			case rel == ".":
+				source = "."
+			default:
+				source = "./" + filepath.ToSlash(rel)
+			}
+		}
+
+		excluded, err := site.GetExcludedDirs(home)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		volumes := []string{source + ":/app:rw"}
+		for _, d := range excluded {
+			name := volumename.FromPath(filepath.Join(site.Hostname, filepath.FromSlash(d)))
+
+			f.Volumes[name] = composeVolume{}
+			volumes = append(volumes, name+":/app/"+d)
+		}
+
+		var depends []string
+		for _, d := range site.DependsOn {
+			name, err := cfg.DependencyContainerName(d)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%w for site %s", err, site.Hostname)
+			}
+
+			depends = append(depends, name)
+		}
+
+		f.Services[site.Hostname] = composeService{
+			Image:       fmt.Sprintf(siteImage, site.Version),
+			Environment: environment(site, cfg),
+			Volumes:     volumes,
+			ExtraHosts:  []string{"host.docker.internal:host-gateway"},
+			DependsOn:   depends,
+			Networks:    map[string]composeNetwork{networkName: {Aliases: site.Aliases}},
+		}
+
+		if site.Webroot != "web" || site.Nginx != "" || site.Limits != nil {
+			warnings = append(warnings, fmt.Sprintf("the web root, nginx directives, and limits for %s are set by apply and are not exported", site.Hostname))
+		}
+
+		if len(site.Extensions) > 0 || len(site.PostStart) > 0 {
+			warnings = append(warnings, fmt.Sprintf("the extensions and post start commands for %s are run by apply and are not exported", site.Hostname))
+		}
+	}
+
+	// remove the dependencies that are not exported, such as custom containers
+	for _, site := range cfg.Sites {
+		s := f.Services[site.Hostname]
+
+		var depends []string
+		for _, d := range s.DependsOn {
+			if _, ok := f.Services[d]; !ok {
+				warnings = append(warnings, fmt.Sprintf("the dependency %s for %s is not exported", d, site.Hostname))
+				continue
+			}
+
+			depends = append(depends, d)
+		}
+
+		s.DependsOn = depends
+		f.Services[site.Hostname] = s
+	}
+
+	if len(cfg.Containers) > 0 {
+		warnings = append(warnings, "custom containers are not exported")
+	}
+
+	return f, warnings, nil
+}
+
+// environment returns the environment variables for a site, the same as apply
+// sets for the site container.
+func environment(site config.Site, cfg *config.Config) map[string]string {
+	env := make(map[string]string)
+	for _, e := range site.AsEnvs("host.docker.internal") {
+		parts := strings.SplitN(e, "=", 2)
+		env[parts[0]] = parts[1]
+	}
+
+	if cfg.Blackfire.ServerID != "" {
+		env["BLACKFIRE_SERVER_ID"] = cfg.Blackfire.ServerID
+	}
+
+	if cfg.Blackfire.ServerToken != "" {
+		env["BLACKFIRE_SERVER_TOKEN"] = cfg.Blackfire.ServerToken
+	}
+
+	// connect to the enabled services
+	if cfg.Services.Elasticsearch {
+		env["ELASTICSEARCH_HOST"], env["ELASTICSEARCH_PORT"] = elasticsearch.Host, elasticsearch.Port
+	}
+
+	if cfg.Services.Meilisearch {
+		env["MEILISEARCH_HOST"], env["MEILISEARCH_PORT"] = meilisearch.Host, meilisearch.Port
+		env["MEILISEARCH_MASTER_KEY"] = cfg.Services.MeilisearchMasterKey
+	}
+
+	if cfg.Services.RabbitMQ {
+		env["RABBITMQ_HOST"], env["RABBITMQ_PORT"] = rabbitmq.Host, rabbitmq.Port
+	}
+
+	if cfg.Services.Redis {
+		env["REDIS_HOST"], env["REDIS_PORT"] = redis.Host, redis.Port
+	}
+
+	for k, v := range site.Env {
+		env[k] = v
+	}
+
+	return env
+}
+
+// databaseImage returns the image name for the database engine.
+func databaseImage(engine string) string {
+	if engine == "mongodb" {
+		return "mongo"
+	}
+
+	return engine
+}
+
+// ports publishes the container port on the same port of the host.
+func ports(port string) string {
+	return fmt.Sprintf("127.0.0.1:%s:%s", port, port)
+}
+
+func network() map[string]composeNetwork {
+	return map[string]composeNetwork{networkName: {}}
+}
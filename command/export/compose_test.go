@@ -0,0 +1,152 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/svc/redis"
+)
+
+func Test_compose(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	home := filepath.Join(wd, "testdata", "home")
+
+	tests := []struct {
+		name         string
+		dir          string
+		cfg          *config.Config
+		wantService  string
+		want         composeService
+		wantWarnings []string
+		wantErr      bool
+	}{
+		{
+			name: "sites mount the path relative to the compose file",
+			dir:  filepath.Join(home, "dev", "craft-dev"),
+			cfg: &config.Config{
+				Databases: []config.Database{{Engine: "mysql", Version: "8.0", Port: "3306"}},
+				Sites: []config.Site{
+					{
+						Hostname:  "craft-dev.nitro",
+						Aliases:   []string{"craft.nitro"},
+						Path:      "~/dev/craft-dev",
+						Version:   "8.0",
+						Webroot:   "web",
+						DependsOn: []string{"mysql-8.0"},
+						Env:       map[string]string{"APP_ENV": "dev"},
+					},
+				},
+			},
+			wantService: "craft-dev.nitro",
+			want: composeService{
+				Image: "docker.io/craftcms/nginx:8.0-dev",
+				Environment: map[string]string{
+					"APP_ENV":                         "dev",
+					"PHP_DISPLAY_ERRORS":              "on",
+					"PHP_MEMORY_LIMIT":                "512M",
+					"PHP_MAX_EXECUTION_TIME":          "5000",
+					"PHP_UPLOAD_MAX_FILESIZE":         "512M",
+					"PHP_MAX_INPUT_VARS":              "5000",
+					"PHP_POST_MAX_SIZE":               "512M",
+					"PHP_OPCACHE_ENABLE":              "0",
+					"PHP_OPCACHE_REVALIDATE_FREQ":     "0",
+					"PHP_OPCACHE_VALIDATE_TIMESTAMPS": "0",
+					"XDEBUG_MODE":                     "off",
+					"XDEBUG_SESSION":                  "PHPSTORM",
+					"PHP_IDE_CONFIG":                  "serverName=craft-dev.nitro",
+					"COMPOSER_HOME":                   "/tmp",
+				},
+				Volumes:    []string{".:/app:rw"},
+				ExtraHosts: []string{"host.docker.internal:host-gateway"},
+				DependsOn:  []string{"mysql-8.0-3306.database.nitro"},
+				Networks:   map[string]composeNetwork{networkName: {Aliases: []string{"craft.nitro"}}},
+			},
+		},
+		{
+			name: "databases use a volume and publish the port",
+			cfg: &config.Config{
+				Databases: []config.Database{{Engine: "postgres", Version: "13", Port: "5433"}},
+			},
+			wantService: "postgres-13-5433.database.nitro",
+			want: composeService{
+				Image:       "postgres:13",
+				Environment: map[string]string{"POSTGRES_USER": "nitro", "POSTGRES_DB": "nitro", "POSTGRES_PASSWORD": "nitro"},
+				Ports:       []string{"127.0.0.1:5433:5432"},
+				Volumes:     []string{"postgres-13-5433.database.nitro:/var/lib/postgresql/data"},
+				Networks:    network(),
+			},
+		},
+		{
+			name: "services use the version from the config",
+			cfg: &config.Config{
+				Services: config.Services{Redis: true, RedisVersion: "6"},
+			},
+			wantService: redis.Host,
+			want: composeService{
+				Image:    "docker.io/library/redis:6",
+				Ports:    []string{"127.0.0.1:6379:6379"},
+				Networks: network(),
+			},
+		},
+		{
+			name: "settings that are not exported return warnings",
+			cfg: &config.Config{
+				Containers: []config.Container{{Name: "mysql-custom", Image: "mysql", Tag: "8.0"}},
+				Sites: []config.Site{
+					{
+						Hostname:   "craft-dev.nitro",
+						Path:       "~/dev/craft-dev",
+						Version:    "8.0",
+						Webroot:    "public",
+						Extensions: []string{"sockets"},
+						DependsOn:  []string{"mysql-custom"},
+					},
+				},
+			},
+			wantWarnings: []string{
+				"the web root, nginx directives, and limits for craft-dev.nitro are set by apply and are not exported",
+				"the extensions and post start commands for craft-dev.nitro are run by apply and are not exported",
+				"the dependency mysql-custom.containers.nitro for craft-dev.nitro is not exported",
+				"custom containers are not exported",
+			},
+		},
+		{
+			name: "databases without a port return an error",
+			cfg: &config.Config{
+				Databases: []config.Database{{Engine: "mysql", Version: "8.0"}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, warnings, err := compose(home, tt.dir, tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("compose() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if !reflect.DeepEqual(warnings, tt.wantWarnings) {
+				t.Errorf("expected the warnings to be %v, got %v", tt.wantWarnings, warnings)
+			}
+
+			if tt.wantService == "" {
+				return
+			}
+
+			if !reflect.DeepEqual(got.Services[tt.wantService], tt.want) {
+				t.Errorf("expected the service %s to be\n%#v\ngot\n%#v", tt.wantService, tt.want, got.Services[tt.wantService])
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package export
+
+import (
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+const exampleText = `  # export the config to a docker-compose.yml in the current directory
+  nitro export compose
+
+  # export the config to a different file
+  nitro export compose --output ~/dev/craft-dev/docker-compose.yml
+
+  # print the docker-compose.yml
+  nitro export compose --output -`
+
+// NewCommand returns the commands for exporting the environment to other tools.
+func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "export",
+		Short:   "Exports the environment.",
+		Example: exampleText,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(
+		composeCommand(home, output),
+	)
+
+	return cmd
+}
@@ -114,40 +114,7 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 			// set the hostname of the site based on the container name
 			hostname := strings.TrimLeft(containers[0].Names[0], "/")
 
-			extensions := []string{
-				"bcmath",
-				"bz2",
-				"calendar",
-				"dba",
-				"enchant",
-				"exif",
-				"gettext",
-				"gmp",
-				"imap",
-				"interbase",
-				"ldap",
-				"mysqli",
-				"oci8",
-				"odbc",
-				"pcntl",
-				"pdo_dblib",
-				"pdo_firebird",
-				"pdo_oci",
-				"pdo_odbc",
-				"pdo_sqlite",
-				"recode",
-				"shmop",
-				"snmp",
-				"sockets",
-				"sysvmsg",
-				"sysvsem",
-				"sysvshm",
-				"tidy",
-				"wddx",
-				"xmlrpc",
-				"xsl",
-				"zend_test",
-			}
+			extensions := config.PHPExtensions
 
 			// which extensions to add
 			selected, err := output.Select(cmd.InOrStdin(), "Which PHP extension would you like to enable for "+hostname+"? ", extensions)
@@ -0,0 +1,30 @@
+package ide
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+const exampleText = `  # configure phpstorm to debug the site in the current directory
+  nitro ide phpstorm
+
+  # configure phpstorm for a site
+  nitro ide phpstorm tutorial.nitro
+
+  # configure vs code to debug and open the site in a dev container
+  nitro ide vscode tutorial.nitro`
+
+// NewCommand returns the command to generate the configuration for an IDE, so debugging
+// a site works without setting up the server and path mappings by hand.
+func NewCommand(home string, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "ide",
+		Short:   "Configures an IDE for a site.",
+		Example: exampleText,
+	}
+
+	cmd.AddCommand(phpstormCommand(home, output), vscodeCommand(home, output))
+
+	return cmd
+}
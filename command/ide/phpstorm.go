@@ -0,0 +1,186 @@
+package ide
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/prompt"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+const (
+	// phpstormDir is the directory in the project for the phpstorm settings
+	phpstormDir = ".idea"
+
+	// containerRoot is where the site is mounted in the site container
+	containerRoot = "/app"
+
+	emptyProject = `<?xml version="1.0" encoding="UTF-8"?>
+<project version="4">
+</project>
+`
+)
+
+var serverIDRegex = regexp.MustCompile(`id="([^"]+)"`)
+
+func phpstormCommand(home string, output terminal.Outputer) *cobra.Command {
+	return &cobra.Command{
+		Use:   "phpstorm",
+		Short: "Configures PhpStorm to debug a site.",
+		Long: `Adds a server with the path mappings for the site to .idea/workspace.xml and sets the
+Xdebug port in .idea/php.xml, other settings in the files are not changed.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return prompt.VerifyInit(cmd, args, home, output)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(home)
+			if err != nil {
+				return err
+			}
+
+			site, err := prompt.SelectSite(cmd, home, cfg, args, output)
+			if err != nil {
+				return err
+			}
+
+			path, err := site.GetAbsPath(home)
+			if err != nil {
+				return err
+			}
+
+			dir := filepath.Join(path, phpstormDir)
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return fmt.Errorf("unable to create the %s directory, %w", phpstormDir, err)
+			}
+
+			output.Pending("configuring", filepath.Join(dir, "workspace.xml"))
+
+			if err := updateFile(filepath.Join(dir, "workspace.xml"), func(doc string) string {
+				return setServer(doc, site.Hostname)
+			}); err != nil {
+				output.Warning()
+				return err
+			}
+
+			output.Done()
+
+			output.Pending("configuring", filepath.Join(dir, "php.xml"))
+
+			if err := updateFile(filepath.Join(dir, "php.xml"), func(doc string) string {
+				return setComponent(doc, "PhpDebugGeneral", fmt.Sprintf(`  <component name="PhpDebugGeneral" xdebug_debug_port="%d" />`, xdebugPort(site.Version)))
+			}); err != nil {
+				output.Warning()
+				return err
+			}
+
+			output.Done()
+
+			output.Info("PhpStorm is configured for", site.Hostname+"…\n run `nitro xon "+site.Hostname+"` and start listening for debug connections")
+
+			return nil
+		},
+	}
+}
+
+// updateFile reads the xml file, or an empty project when the file does not exist, and
+// writes the document returned from the update.
+func updateFile(file string, update func(doc string) string) error {
+	doc := emptyProject
+
+	b, err := ioutil.ReadFile(file)
+	switch {
+	case err == nil:
+		doc = string(b)
+	case !os.IsNotExist(err):
+		return fmt.Errorf("unable to read %s, %w", file, err)
+	}
+
+	if err := ioutil.WriteFile(file, []byte(update(doc)), 0644); err != nil {
+		return fmt.Errorf("unable to write %s, %w", file, err)
+	}
+
+	return nil
+}
+
+// xdebugPort returns the port xdebug connects to for the PHP version, xdebug 2 is used
+// for PHP 7.1 and uses the legacy port.
+func xdebugPort(version string) int {
+	if version == "7.1" || version == "7.0" {
+		return 9000
+	}
+
+	return 9003
+}
+
+// setServer adds the server for the hostname to the PhpServers component, which maps the sites
+// path in the container to the project. The server name matches PHP_IDE_CONFIG in the site
+// container. An existing server for the hostname is replaced and keeps its id.
+func setServer(doc, hostname string) string {
+	existing := regexp.MustCompile(`(?s)\n?[ \t]*<server [^>]*name="` + regexp.QuoteMeta(hostname) + `"[^>]*?(?:/>|>.*?</server>)`)
+
+	id := newID()
+	if match := existing.FindString(doc); match != "" {
+		if m := serverIDRegex.FindStringSubmatch(match); m != nil {
+			id = m[1]
+		}
+
+		doc = existing.ReplaceAllString(doc, "")
+	}
+
+	server := fmt.Sprintf(`      <server host="%s" id="%s" name="%s" use_path_mappings="true">
+        <path_mappings>
+          <mapping local-root="$PROJECT_DIR$" remote-root="%s" />
+        </path_mappings>
+      </server>`, hostname, id, hostname, containerRoot)
+
+	// add the server to the other servers
+	if strings.Contains(doc, `<component name="PhpServers">`) && strings.Contains(doc, "</servers>") {
+		i := strings.Index(doc, "</servers>")
+		j := strings.LastIndex(doc[:i], "\n")
+
+		return doc[:j] + "\n" + server + doc[j:]
+	}
+
+	return setComponent(doc, "PhpServers", `  <component name="PhpServers">
+    <servers>
+`+server+`
+    </servers>
+  </component>`)
+}
+
+// setComponent replaces the component with the name, or adds it to the end of the project.
+func setComponent(doc, name, component string) string {
+	existing := regexp.MustCompile(`(?s)[ \t]*<component name="` + regexp.QuoteMeta(name) + `"[^>]*?(?:/>|>.*?</component>)`)
+	if existing.MatchString(doc) {
+		return existing.ReplaceAllLiteralString(doc, component)
+	}
+
+	i := strings.LastIndex(doc, "</project>")
+	if i == -1 {
+		return doc
+	}
+
+	return doc[:i] + component + "\n" + doc[i:]
+}
+
+// newID returns a random id in the uuid format phpstorm uses for servers.
+func newID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "nitro"
+	}
+
+	// set the version (4) and variant bits
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
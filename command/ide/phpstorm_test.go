@@ -0,0 +1,127 @@
+package ide
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var idRegex = regexp.MustCompile(`id="[^"]+"`)
+
+func Test_setServer(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{
+			name: "adds the component to an empty project",
+			doc:  emptyProject,
+			want: `<?xml version="1.0" encoding="UTF-8"?>
+<project version="4">
+  <component name="PhpServers">
+    <servers>
+      <server host="tutorial.nitro" id="ID" name="tutorial.nitro" use_path_mappings="true">
+        <path_mappings>
+          <mapping local-root="$PROJECT_DIR$" remote-root="/app" />
+        </path_mappings>
+      </server>
+    </servers>
+  </component>
+</project>
+`,
+		},
+		{
+			name: "keeps the other servers and replaces the existing server",
+			doc: `<?xml version="1.0" encoding="UTF-8"?>
+<project version="4">
+  <component name="PhpServers">
+    <servers>
+      <server host="other.test" id="other-id" name="other.test" />
+      <server host="tutorial.nitro" id="existing-id" name="tutorial.nitro" />
+    </servers>
+  </component>
+  <component name="PropertiesComponent" />
+</project>
+`,
+			want: `<?xml version="1.0" encoding="UTF-8"?>
+<project version="4">
+  <component name="PhpServers">
+    <servers>
+      <server host="other.test" id="other-id" name="other.test" />
+      <server host="tutorial.nitro" id="existing-id" name="tutorial.nitro" use_path_mappings="true">
+        <path_mappings>
+          <mapping local-root="$PROJECT_DIR$" remote-root="/app" />
+        </path_mappings>
+      </server>
+    </servers>
+  </component>
+  <component name="PropertiesComponent" />
+</project>
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := setServer(tt.doc, "tutorial.nitro")
+
+			// new servers have a random id
+			if !strings.Contains(tt.doc, "tutorial.nitro") {
+				got = idRegex.ReplaceAllString(got, `id="ID"`)
+			}
+
+			if got != tt.want {
+				t.Errorf("setServer() got \n%s\nwant\n%s", got, tt.want)
+			}
+
+			// running it again does not add another server
+			if again := setServer(setServer(tt.doc, "tutorial.nitro"), "tutorial.nitro"); strings.Count(again, `name="tutorial.nitro"`) != 1 {
+				t.Errorf("expected one server for the site, got\n%s", again)
+			}
+		})
+	}
+}
+
+func Test_setComponent(t *testing.T) {
+	component := `  <component name="PhpDebugGeneral" xdebug_debug_port="9003" />`
+
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{
+			name: "adds the component",
+			doc:  emptyProject,
+			want: `<?xml version="1.0" encoding="UTF-8"?>
+<project version="4">
+  <component name="PhpDebugGeneral" xdebug_debug_port="9003" />
+</project>
+`,
+		},
+		{
+			name: "replaces the existing component",
+			doc: `<?xml version="1.0" encoding="UTF-8"?>
+<project version="4">
+  <component name="PhpDebugGeneral" xdebug_debug_port="9000">
+    <xdebug_debug_ports port="9000" />
+  </component>
+  <component name="PhpProjectSharedConfiguration" php_language_level="7.4" />
+</project>
+`,
+			want: `<?xml version="1.0" encoding="UTF-8"?>
+<project version="4">
+  <component name="PhpDebugGeneral" xdebug_debug_port="9003" />
+  <component name="PhpProjectSharedConfiguration" php_language_level="7.4" />
+</project>
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := setComponent(tt.doc, "PhpDebugGeneral", component); got != tt.want {
+				t.Errorf("setComponent() got \n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
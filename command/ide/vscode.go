@@ -0,0 +1,253 @@
+package ide
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/prompt"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+const (
+	// siteImage is the image apply uses for the site containers, with the PHP version
+	siteImage = "docker.io/craftcms/nginx:%s-dev"
+
+	// launchName is the name of the debug configuration nitro manages in launch.json
+	launchName = "Listen for Xdebug (nitro)"
+)
+
+// ErrInvalidLaunch is returned when the existing launch.json can not be parsed
+var ErrInvalidLaunch = errors.New("unable to parse the existing launch.json")
+
+// devcontainer is the subset of the devcontainer.json format nitro writes.
+type devcontainer struct {
+	Name            string                 `json:"name"`
+	Image           string                 `json:"image"`
+	WorkspaceFolder string                 `json:"workspaceFolder"`
+	WorkspaceMount  string                 `json:"workspaceMount"`
+	RunArgs         []string               `json:"runArgs"`
+	ContainerEnv    map[string]string      `json:"containerEnv"`
+	Customizations  map[string]interface{} `json:"customizations"`
+}
+
+func vscodeCommand(home string, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "vscode",
+		Short: "Configures VS Code to debug a site.",
+		Long: `Adds a debug configuration with the Xdebug port and path mappings for the site to
+.vscode/launch.json, other configurations are not changed. It also writes a
+.devcontainer/devcontainer.json that opens the site in a container with the same
+image, PHP settings, and network as the site container.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return prompt.VerifyInit(cmd, args, home, output)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(home)
+			if err != nil {
+				return err
+			}
+
+			site, err := prompt.SelectSite(cmd, home, cfg, args, output)
+			if err != nil {
+				return err
+			}
+
+			path, err := site.GetAbsPath(home)
+			if err != nil {
+				return err
+			}
+
+			launch := filepath.Join(path, ".vscode", "launch.json")
+
+			output.Pending("configuring", launch)
+
+			if err := writeLaunch(launch, xdebugPort(site.Version)); err != nil {
+				output.Warning()
+				return err
+			}
+
+			output.Done()
+
+			file := filepath.Join(path, ".devcontainer", "devcontainer.json")
+
+			// the devcontainer may be customized, so it is only replaced when forced
+			_, err = os.Stat(file)
+			if err == nil && cmd.Flag("force").Value.String() != "true" {
+				output.Info("Skipping", file, "since it already exists…\n run `nitro ide vscode --force` to replace it")
+			} else {
+				output.Pending("configuring", file)
+
+				if err := writeJSON(file, newDevcontainer(*site, cfg)); err != nil {
+					output.Warning()
+					return err
+				}
+
+				output.Done()
+			}
+
+			output.Info("VS Code is configured for", site.Hostname+"…\n run `nitro xon "+site.Hostname+"` and start the \""+launchName+"\" configuration")
+
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("force", false, "replace an existing devcontainer.json")
+
+	return cmd
+}
+
+// newDevcontainer returns the devcontainer for the site, which uses the sites image and
+// environment variables on the nitro network.
+func newDevcontainer(site config.Site, cfg *config.Config) devcontainer {
+	site.PHP = cfg.PHP.Override(site.PHP)
+
+	env := map[string]string{}
+	for _, e := range site.AsEnvs("host.docker.internal") {
+		parts := strings.SplitN(e, "=", 2)
+		env[parts[0]] = parts[1]
+	}
+
+	for k, v := range site.Env {
+		env[k] = v
+	}
+
+	return devcontainer{
+		Name:            site.Hostname,
+		Image:           fmt.Sprintf(siteImage, site.Version),
+		WorkspaceFolder: containerRoot,
+		WorkspaceMount:  "source=${localWorkspaceFolder},target=" + containerRoot + ",type=bind",
+		RunArgs:         []string{"--network=nitro-network"},
+		ContainerEnv:    env,
+		Customizations: map[string]interface{}{
+			"vscode": map[string]interface{}{
+				"extensions": []string{"xdebug.php-debug", "bmewburn.vscode-intelephense-client"},
+			},
+		},
+	}
+}
+
+// writeLaunch adds the nitro debug configuration to the launch.json file, replacing an
+// existing nitro configuration. Comments in the existing file are not kept.
+func writeLaunch(file string, port int) error {
+	launch := map[string]interface{}{"version": "0.2.0"}
+
+	b, err := ioutil.ReadFile(file)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(stripJSONC(b), &launch); err != nil {
+			return fmt.Errorf("%w, %s", ErrInvalidLaunch, err)
+		}
+	case !os.IsNotExist(err):
+		return fmt.Errorf("unable to read %s, %w", file, err)
+	}
+
+	nitro := map[string]interface{}{
+		"name":    launchName,
+		"type":    "php",
+		"request": "launch",
+		"port":    port,
+		"pathMappings": map[string]string{
+			containerRoot: "${workspaceFolder}",
+		},
+	}
+
+	configurations, _ := launch["configurations"].([]interface{})
+
+	var replaced bool
+	for i, c := range configurations {
+		if m, ok := c.(map[string]interface{}); ok && m["name"] == launchName {
+			configurations[i] = nitro
+			replaced = true
+		}
+	}
+
+	if !replaced {
+		configurations = append(configurations, nitro)
+	}
+
+	launch["configurations"] = configurations
+
+	return writeJSON(file, launch)
+}
+
+func writeJSON(file string, v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("unable to create the directory for %s, %w", file, err)
+	}
+
+	if err := ioutil.WriteFile(file, append(b, '\n'), 0644); err != nil {
+		return fmt.Errorf("unable to write %s, %w", file, err)
+	}
+
+	return nil
+}
+
+// stripJSONC removes the comments and trailing commas VS Code allows in its json files.
+func stripJSONC(b []byte) []byte {
+	var out []byte
+	var inString, escaped bool
+
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+
+		if inString {
+			out = append(out, c)
+
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+
+			continue
+		}
+
+		switch {
+		case c == '"':
+			inString = true
+		case c == '/' && i+1 < len(b) && b[i+1] == '/':
+			for i < len(b) && b[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(b) && b[i+1] == '*':
+			i += 2
+			for i+1 < len(b) && !(b[i] == '*' && b[i+1] == '/') {
+				i++
+			}
+			i++
+
+			continue
+		case c == ']' || c == '}':
+			// remove a trailing comma before the closing bracket
+			j := len(out) - 1
+			for j >= 0 && strings.ContainsRune(" \t\r\n", rune(out[j])) {
+				j--
+			}
+			if j >= 0 && out[j] == ',' {
+				out = append(out[:j], out[j+1:]...)
+			}
+		}
+
+		if i < len(b) {
+			out = append(out, b[i])
+		}
+	}
+
+	return out
+}
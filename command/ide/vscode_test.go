@@ -0,0 +1,120 @@
+package ide
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/craftcms/nitro/pkg/config"
+)
+
+func Test_writeLaunch(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		want     []string
+		wantErr  error
+	}{
+		{
+			name: "creates the launch file",
+			want: []string{launchName},
+		},
+		{
+			name: "keeps other configurations and replaces the nitro configuration",
+			existing: `{
+    // Use IntelliSense to learn about possible attributes.
+    "version": "0.2.0",
+    "configurations": [
+        {"name": "Launch currently open script", "type": "php", "request": "launch", "program": "${file}"},
+        /* replaced */
+        {"name": "Listen for Xdebug (nitro)", "type": "php", "request": "launch", "port": 9000},
+    ]
+}`,
+			want: []string{"Launch currently open script", launchName},
+		},
+		{
+			name:     "invalid files return an error",
+			existing: `{"configurations": [`,
+			wantErr:  ErrInvalidLaunch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), ".vscode", "launch.json")
+			if tt.existing != "" {
+				if err := writeJSON(file, nil); err != nil {
+					t.Fatal(err)
+				}
+				if err := ioutil.WriteFile(file, []byte(tt.existing), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := writeLaunch(file, 9003)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("writeLaunch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			b, err := ioutil.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var launch struct {
+				Configurations []struct {
+					Name         string            `json:"name"`
+					Port         int               `json:"port"`
+					PathMappings map[string]string `json:"pathMappings"`
+				} `json:"configurations"`
+			}
+			if err := json.Unmarshal(b, &launch); err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, c := range launch.Configurations {
+				got = append(got, c.Name)
+
+				if c.Name == launchName && (c.Port != 9003 || c.PathMappings["/app"] != "${workspaceFolder}") {
+					t.Errorf("expected the nitro configuration to use port 9003 and map /app, got %v", c)
+				}
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected the configurations to be %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("expected the configurations to be %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+func Test_newDevcontainer(t *testing.T) {
+	site := config.Site{Hostname: "tutorial.nitro", Version: "8.0", Env: map[string]string{"CRAFT_ENVIRONMENT": "dev"}}
+
+	got := newDevcontainer(site, &config.Config{})
+
+	if got.Image != "docker.io/craftcms/nginx:8.0-dev" {
+		t.Errorf("expected the image to use the sites version, got %s", got.Image)
+	}
+
+	if got.ContainerEnv["PHP_IDE_CONFIG"] != "serverName=tutorial.nitro" {
+		t.Errorf("expected the sites php variables, got %v", got.ContainerEnv)
+	}
+
+	if got.ContainerEnv["CRAFT_ENVIRONMENT"] != "dev" {
+		t.Errorf("expected the sites variables, got %v", got.ContainerEnv)
+	}
+
+	if got.WorkspaceFolder != "/app" {
+		t.Errorf("expected the workspace folder to be /app, got %s", got.WorkspaceFolder)
+	}
+}
@@ -0,0 +1,441 @@
+package importer
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/phpversions"
+	"github.com/craftcms/nitro/pkg/prompt"
+	"github.com/craftcms/nitro/pkg/terminal"
+	"github.com/craftcms/nitro/pkg/webroot"
+)
+
+// siteTargets are the container paths a site is mounted to in common PHP images
+var siteTargets = []string{"/app", "/var/www/html", "/var/www"}
+
+// containerNameRegex matches the characters that are not allowed in a custom container name
+var containerNameRegex = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// composeFile is the part of a docker-compose.yml that can be imported.
+type composeFile struct {
+	Services map[string]composeService `yaml:"services"`
+}
+
+type composeService struct {
+	Image       string          `yaml:"image"`
+	Environment composeEnv      `yaml:"environment"`
+	Ports       []string        `yaml:"ports"`
+	Volumes     []composeVolume `yaml:"volumes"`
+}
+
+// composeEnv is the environment for a service, which can be a list of
+// KEY=value or a map.
+type composeEnv map[string]string
+
+func (e *composeEnv) UnmarshalYAML(value *yaml.Node) error {
+	env := make(map[string]string)
+
+	switch value.Kind {
+	case yaml.SequenceNode:
+		var list []string
+		if err := value.Decode(&list); err != nil {
+			return err
+		}
+
+		for _, l := range list {
+			parts := strings.SplitN(l, "=", 2)
+			if len(parts) == 1 {
+				parts = append(parts, "")
+			}
+
+			env[parts[0]] = parts[1]
+		}
+	default:
+		if err := value.Decode(&env); err != nil {
+			return err
+		}
+	}
+
+	*e = env
+
+	return nil
+}
+
+// composeVolume is a volume for a service in the short (source:target:mode) or long syntax.
+type composeVolume struct {
+	Source string `yaml:"source"`
+	Target string `yaml:"target"`
+}
+
+func (v *composeVolume) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.MappingNode {
+		type long composeVolume
+		return value.Decode((*long)(v))
+	}
+
+	parts := strings.Split(value.Value, ":")
+	switch len(parts) {
+	case 1:
+		v.Target = parts[0]
+	default:
+		v.Source, v.Target = parts[0], parts[1]
+	}
+
+	return nil
+}
+
+// bind returns true if the volume mounts a directory from the host.
+func (v composeVolume) bind() bool {
+	return strings.HasPrefix(v.Source, ".") || strings.HasPrefix(v.Source, "/") || strings.HasPrefix(v.Source, "~")
+}
+
+func composeCommand(home string, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "compose <file>",
+		Short: "Imports the services from a docker-compose.yml.",
+		Args:  cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return prompt.VerifyInit(cmd, args, home, output)
+		},
+		PostRunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.Flag("dry-run").Value.String() == "true" {
+				return nil
+			}
+
+			return prompt.RunApply(cmd, args, false, output)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, err := filepath.Abs(args[0])
+			if err != nil {
+				return err
+			}
+
+			content, err := ioutil.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("unable to read the compose file, %w", err)
+			}
+
+			var f composeFile
+			if err := yaml.Unmarshal(content, &f); err != nil {
+				return fmt.Errorf("unable to parse the compose file, %w", err)
+			}
+
+			cfg, err := config.Load(home)
+			if err != nil {
+				return err
+			}
+
+			output.Info("Importing", file+"…")
+
+			imported, warnings := fromCompose(home, filepath.Dir(file), cfg.GetTLD(), f)
+
+			added, skipped := merge(cfg, imported)
+			for _, a := range added {
+				output.Success(a)
+			}
+
+			for _, w := range append(warnings, skipped...) {
+				output.Info("Warning:", w)
+			}
+
+			if len(added) == 0 {
+				output.Info("There is nothing to import…")
+
+				return nil
+			}
+
+			if cmd.Flag("dry-run").Value.String() == "true" {
+				output.Info("The config was not changed, remove --dry-run to import")
+
+				return nil
+			}
+
+			return cfg.Save()
+		},
+	}
+
+	cmd.Flags().Bool("dry-run", false, "show what would be imported without changing the config")
+
+	return cmd
+}
+
+// fromCompose maps the services in the compose file to sites, databases, services, and custom
+// containers. Services that cannot be imported are returned as warnings. Host paths are
+// relative to dir and sites use the tld for the hostname.
+func fromCompose(home, dir, tld string, f composeFile) (*config.Config, []string) {
+	cfg := &config.Config{}
+
+	var warnings []string
+	for _, name := range sortedServices(f) {
+		s := f.Services[name]
+
+		if s.Image == "" {
+			warnings = append(warnings, fmt.Sprintf("the service %s does not have an image and was not imported", name))
+			continue
+		}
+
+		image, tag := splitImage(s.Image)
+
+		switch base := image[strings.LastIndex(image, "/")+1:]; base {
+		case "nitro-proxy":
+			// the proxy is managed by nitro
+		case "mysql", "mariadb", "postgres", "mongo":
+			engine := base
+			if engine == "mongo" {
+				engine = "mongodb"
+			}
+
+			port := hostPort(s.Ports, map[string]string{"mysql": "3306", "mariadb": "3306", "postgres": "5432", "mongodb": "27017"}[engine])
+
+			cfg.Databases = append(cfg.Databases, config.Database{Engine: engine, Version: tag, Port: port})
+		case "dynamodb-local":
+			cfg.Services.DynamoDB = true
+		case "elasticsearch":
+			cfg.Services.Elasticsearch = true
+			cfg.Services.ElasticsearchVersion = version(tag)
+		case "mailhog":
+			cfg.Services.Mailhog = true
+		case "meilisearch":
+			cfg.Services.Meilisearch = true
+			cfg.Services.MeilisearchMasterKey = s.Environment["MEILI_MASTER_KEY"]
+		case "minio":
+			cfg.Services.Minio = true
+			cfg.Services.MinioUser = s.Environment["MINIO_ROOT_USER"]
+			cfg.Services.MinioPassword = s.Environment["MINIO_ROOT_PASSWORD"]
+		case "rabbitmq":
+			cfg.Services.RabbitMQ = true
+		case "redis":
+			cfg.Services.Redis = true
+			cfg.Services.RedisVersion = version(tag)
+		default:
+			// services that mount a directory as the web app are sites
+			if site, ok := siteFromService(home, dir, tld, name, s, tag); ok {
+				if site.Version == "" {
+					site.Version = phpversions.Versions[0]
+					warnings = append(warnings, fmt.Sprintf("unable to find the PHP version for %s, using PHP %s", site.Hostname, site.Version))
+				}
+
+				cfg.Sites = append(cfg.Sites, site)
+				continue
+			}
+
+			c := config.Container{
+				Name:  strings.Trim(containerNameRegex.ReplaceAllString(strings.ToLower(name), "-"), "-"),
+				Image: image,
+				Tag:   tag,
+				Ports: s.Ports,
+			}
+
+			for _, v := range s.Volumes {
+				if v.bind() {
+					warnings = append(warnings, fmt.Sprintf("the volume %s for %s mounts a host directory and was not imported", v.Source, name))
+					continue
+				}
+
+				c.Volumes = append(c.Volumes, v.Target)
+			}
+
+			if len(s.Environment) > 0 {
+				c.Env = s.Environment
+			}
+
+			cfg.Containers = append(cfg.Containers, c)
+		}
+	}
+
+	return cfg, warnings
+}
+
+// siteFromService returns the site for a service that mounts a host directory to a
+// path PHP images use for the app.
+func siteFromService(home, dir, tld, name string, s composeService, tag string) (config.Site, bool) {
+	for _, v := range s.Volumes {
+		if !v.bind() || !contains(siteTargets, strings.TrimSuffix(v.Target, "/")) {
+			continue
+		}
+
+		path := v.Source
+		switch {
+		case strings.HasPrefix(path, "~"):
+			path = strings.Replace(path, "~", home, 1)
+		case !filepath.IsAbs(path):
+			path = filepath.Join(dir, path)
+		}
+
+		root, _ := webroot.Find(path)
+		if root == "" {
+			root = "web"
+		}
+
+		hostname := name
+		if !strings.Contains(hostname, ".") {
+			hostname = hostname + "." + tld
+		}
+
+		site := config.Site{
+			Hostname: hostname,
+			Path:     strings.Replace(filepath.Clean(path), home, "~", 1),
+			Webroot:  root,
+		}
+
+		// use the PHP version from the image tag (e.g. 8.0-dev or 7.4-fpm)
+		for _, v := range phpversions.Versions {
+			if strings.HasPrefix(tag, v) {
+				site.Version = v
+				break
+			}
+		}
+
+		if len(s.Environment) > 0 {
+			site.Env = s.Environment
+		}
+
+		return site, true
+	}
+
+	return config.Site{}, false
+}
+
+// merge adds the imported config to the config. It returns a message for each
+// item that was added and for each item that already exists.
+func merge(cfg, imported *config.Config) ([]string, []string) {
+	var added, skipped []string
+
+	for _, d := range imported.Databases {
+		exists := false
+		for _, e := range cfg.Databases {
+			if e.Engine == d.Engine && e.Version == d.Version && e.Port == d.Port {
+				exists = true
+			}
+		}
+
+		if exists {
+			skipped = append(skipped, fmt.Sprintf("the database %s %s on port %s already exists", d.Engine, d.Version, d.Port))
+			continue
+		}
+
+		cfg.Databases = append(cfg.Databases, d)
+		added = append(added, fmt.Sprintf("adding database %s %s on port %s", d.Engine, d.Version, d.Port))
+	}
+
+	services := []struct {
+		name     string
+		enabled  bool
+		existing *bool
+		update   func()
+	}{
+		{name: "dynamodb", enabled: imported.Services.DynamoDB, existing: &cfg.Services.DynamoDB},
+		{name: "elasticsearch", enabled: imported.Services.Elasticsearch, existing: &cfg.Services.Elasticsearch, update: func() {
+			cfg.Services.ElasticsearchVersion = imported.Services.ElasticsearchVersion
+		}},
+		{name: "mailhog", enabled: imported.Services.Mailhog, existing: &cfg.Services.Mailhog},
+		{name: "meilisearch", enabled: imported.Services.Meilisearch, existing: &cfg.Services.Meilisearch, update: func() {
+			cfg.Services.MeilisearchMasterKey = imported.Services.MeilisearchMasterKey
+		}},
+		{name: "minio", enabled: imported.Services.Minio, existing: &cfg.Services.Minio, update: func() {
+			cfg.Services.MinioUser, cfg.Services.MinioPassword = imported.Services.MinioUser, imported.Services.MinioPassword
+		}},
+		{name: "rabbitmq", enabled: imported.Services.RabbitMQ, existing: &cfg.Services.RabbitMQ},
+		{name: "redis", enabled: imported.Services.Redis, existing: &cfg.Services.Redis, update: func() {
+			cfg.Services.RedisVersion = imported.Services.RedisVersion
+		}},
+	}
+
+	for _, s := range services {
+		if !s.enabled || *s.existing {
+			continue
+		}
+
+		*s.existing = true
+		if s.update != nil {
+			s.update()
+		}
+
+		added = append(added, "enabling "+s.name)
+	}
+
+	for _, s := range imported.Sites {
+		if err := cfg.AddSite(s); err != nil {
+			skipped = append(skipped, fmt.Sprintf("the site %s already exists", s.Hostname))
+			continue
+		}
+
+		added = append(added, fmt.Sprintf("adding site %s with PHP %s", s.Hostname, s.Version))
+	}
+
+	for _, c := range imported.Containers {
+		if err := cfg.AddContainer(c); err != nil {
+			skipped = append(skipped, fmt.Sprintf("the container %s already exists", c.Name))
+			continue
+		}
+
+		added = append(added, fmt.Sprintf("adding container %s", c.Name))
+	}
+
+	return added, skipped
+}
+
+// splitImage returns the image name and tag, the tag defaults to latest.
+func splitImage(image string) (string, string) {
+	i := strings.LastIndex(image, ":")
+	if i == -1 || strings.Contains(image[i:], "/") {
+		return image, "latest"
+	}
+
+	return image[:i], image[i+1:]
+}
+
+// hostPort returns the host port that is published for the container port, or
+// the container port if it is not published.
+func hostPort(ports []string, container string) string {
+	for _, p := range ports {
+		parts := strings.Split(strings.Split(p, "/")[0], ":")
+		if parts[len(parts)-1] != container {
+			continue
+		}
+
+		if len(parts) > 1 {
+			return parts[len(parts)-2]
+		}
+	}
+
+	return container
+}
+
+// version returns the image tag as a service version, latest is the default for the service.
+func version(tag string) string {
+	if tag == "latest" {
+		return ""
+	}
+
+	return tag
+}
+
+func sortedServices(f composeFile) []string {
+	var names []string
+	for n := range f.Services {
+		names = append(names, n)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+
+	return false
+}
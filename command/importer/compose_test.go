@@ -0,0 +1,151 @@
+package importer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/craftcms/nitro/pkg/config"
+)
+
+func Test_fromCompose(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(wd, "testdata")
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, "docker-compose.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var f composeFile
+	if err := yaml.Unmarshal(content, &f); err != nil {
+		t.Fatal(err)
+	}
+
+	got, warnings := fromCompose(wd, dir, "nitro", f)
+
+	want := &config.Config{
+		Containers: []config.Container{
+			{
+				Name:    "adminer",
+				Image:   "docker.io/library/adminer",
+				Tag:     "4",
+				Ports:   []string{"8080:8080"},
+				Volumes: []string{"/data"},
+				Env:     map[string]string{"ADMINER_DEFAULT_SERVER": "mysql"},
+			},
+		},
+		Databases: []config.Database{
+			{Engine: "mysql", Version: "8.0", Port: "3307"},
+		},
+		Services: config.Services{
+			Mailhog:      true,
+			Redis:        true,
+			RedisVersion: "6",
+		},
+		Sites: []config.Site{
+			{
+				Hostname: "craft-dev.nitro",
+				Path:     "~/testdata/craft-dev",
+				Version:  "8.0",
+				Webroot:  "web",
+				Env:      map[string]string{"DB_SERVER": "mysql", "DB_USER": "nitro"},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fromCompose() = %#v, want %#v", got, want)
+	}
+
+	wantWarnings := []string{
+		"the volume ./plugins for adminer mounts a host directory and was not imported",
+		"the service worker does not have an image and was not imported",
+	}
+
+	if !reflect.DeepEqual(warnings, wantWarnings) {
+		t.Errorf("expected the warnings to be %v, got %v", wantWarnings, warnings)
+	}
+}
+
+func Test_merge(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         *config.Config
+		imported    *config.Config
+		wantAdded   []string
+		wantSkipped []string
+		want        *config.Config
+	}{
+		{
+			name: "new items are added to the config",
+			cfg:  &config.Config{},
+			imported: &config.Config{
+				Databases: []config.Database{{Engine: "mysql", Version: "8.0", Port: "3306"}},
+				Services:  config.Services{Redis: true, RedisVersion: "6"},
+				Sites:     []config.Site{{Hostname: "craft-dev.nitro", Version: "8.0"}},
+			},
+			wantAdded: []string{
+				"adding database mysql 8.0 on port 3306",
+				"enabling redis",
+				"adding site craft-dev.nitro with PHP 8.0",
+			},
+			want: &config.Config{
+				Databases: []config.Database{{Engine: "mysql", Version: "8.0", Port: "3306"}},
+				Services:  config.Services{Redis: true, RedisVersion: "6"},
+				Sites:     []config.Site{{Hostname: "craft-dev.nitro", Version: "8.0"}},
+			},
+		},
+		{
+			name: "existing items are skipped",
+			cfg: &config.Config{
+				Containers: []config.Container{{Name: "adminer", Image: "adminer"}},
+				Databases:  []config.Database{{Engine: "mysql", Version: "8.0", Port: "3306"}},
+				Services:   config.Services{Redis: true},
+				Sites:      []config.Site{{Hostname: "craft-dev.nitro", Version: "7.4"}},
+			},
+			imported: &config.Config{
+				Containers: []config.Container{{Name: "adminer", Image: "adminer", Tag: "4"}},
+				Databases:  []config.Database{{Engine: "mysql", Version: "8.0", Port: "3306"}},
+				Services:   config.Services{Redis: true, RedisVersion: "6"},
+				Sites:      []config.Site{{Hostname: "craft-dev.nitro", Version: "8.0"}},
+			},
+			wantSkipped: []string{
+				"the database mysql 8.0 on port 3306 already exists",
+				"the site craft-dev.nitro already exists",
+				"the container adminer already exists",
+			},
+			want: &config.Config{
+				Containers: []config.Container{{Name: "adminer", Image: "adminer"}},
+				Databases:  []config.Database{{Engine: "mysql", Version: "8.0", Port: "3306"}},
+				Services:   config.Services{Redis: true},
+				Sites:      []config.Site{{Hostname: "craft-dev.nitro", Version: "7.4"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			added, skipped := merge(tt.cfg, tt.imported)
+
+			if !reflect.DeepEqual(added, tt.wantAdded) {
+				t.Errorf("expected added to be %v, got %v", tt.wantAdded, added)
+			}
+
+			if !reflect.DeepEqual(skipped, tt.wantSkipped) {
+				t.Errorf("expected skipped to be %v, got %v", tt.wantSkipped, skipped)
+			}
+
+			if !reflect.DeepEqual(tt.cfg, tt.want) {
+				t.Errorf("expected the config to be %#v, got %#v", tt.want, tt.cfg)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package importer
+
+import (
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+const exampleText = `  # import the services from a docker-compose.yml
+  nitro import compose docker-compose.yml
+
+  # show what would be imported without changing the config
+  nitro import compose docker-compose.yml --dry-run`
+
+// NewCommand returns the commands for importing an environment from other tools.
+func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "import",
+		Short:   "Imports an environment.",
+		Example: exampleText,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(
+		composeCommand(home, output),
+	)
+
+	return cmd
+}
@@ -0,0 +1,219 @@
+package info
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/prompt"
+	"github.com/craftcms/nitro/pkg/svc/mailhog"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+const exampleText = `  # show the details for the site in the current directory
+  nitro info
+
+  # show the details for a site as json
+  nitro info tutorial.nitro --json`
+
+// details are the connection details for a site.
+type details struct {
+	Hostname  string     `json:"hostname"`
+	Aliases   []string   `json:"aliases,omitempty"`
+	Path      string     `json:"path"`
+	Webroot   string     `json:"webroot"`
+	PHP       string     `json:"php"`
+	Xdebug    bool       `json:"xdebug"`
+	Container container  `json:"container"`
+	URLs      []string   `json:"urls"`
+	Databases []database `json:"databases"`
+	Mailhog   *mail      `json:"mailhog,omitempty"`
+}
+
+type container struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+// database has the connection strings from the host and from the site container.
+type database struct {
+	Hostname string `json:"hostname"`
+	Engine   string `json:"engine"`
+	Version  string `json:"version"`
+	Host     string `json:"host"`
+	Site     string `json:"site"`
+}
+
+// mail has the SMTP settings for the site container and the URL for the inbox.
+type mail struct {
+	SMTPHost string `json:"smtp_host"`
+	SMTPPort int    `json:"smtp_port"`
+	URL      string `json:"url"`
+}
+
+// NewCommand returns the command to show the PHP version, container, database connections,
+// mail settings, and URLs for a site.
+func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "info [hostname]",
+		Short:   "Shows the connection details for a site.",
+		Example: exampleText,
+		Args:    cobra.MaximumNArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return prompt.VerifyInit(cmd, args, home, output)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(home)
+			if err != nil {
+				return err
+			}
+
+			// merge the project files from the sites
+			if _, err := cfg.MergeProjects(home); err != nil {
+				return err
+			}
+
+			site, err := prompt.SelectSite(cmd, home, cfg, args, output)
+			if err != nil {
+				return err
+			}
+
+			d := siteDetails(cfg, *site)
+
+			// get the status of the sites container
+			filter := filters.NewArgs()
+			filter.Add("label", containerlabels.Nitro)
+			filter.Add("label", containerlabels.Host+"="+site.Hostname)
+
+			containers, err := docker.ContainerList(cmd.Context(), types.ContainerListOptions{All: true, Filters: filter})
+			if err != nil {
+				return err
+			}
+
+			d.Container.Status = "not created"
+			if len(containers) > 0 {
+				d.Container.Name = strings.TrimLeft(containers[0].Names[0], "/")
+				d.Container.Status = containers[0].State
+			}
+
+			if cmd.Flag("json").Value.String() == "true" {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+
+				return enc.Encode(d)
+			}
+
+			printDetails(cmd.OutOrStdout(), d)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("json", false, "show the details as json")
+
+	return cmd
+}
+
+// siteDetails returns the details for the site from the config. The container status is
+// added from docker.
+func siteDetails(cfg *config.Config, site config.Site) details {
+	d := details{
+		Hostname: site.Hostname,
+		Aliases:  site.Aliases,
+		Path:     site.Path,
+		Webroot:  site.Webroot,
+		PHP:      site.Version,
+		Xdebug:   site.Xdebug,
+		Container: container{
+			Name: site.Hostname,
+		},
+		Databases: []database{},
+	}
+
+	for _, h := range append([]string{site.Hostname}, site.Aliases...) {
+		d.URLs = append(d.URLs, cfg.SiteURL("https", h), cfg.SiteURL("http", h))
+	}
+
+	for _, db := range cfg.Databases {
+		hostname, err := db.GetHostname()
+		if err != nil {
+			continue
+		}
+
+		scheme, port := connection(db.Engine)
+
+		d.Databases = append(d.Databases, database{
+			Hostname: hostname,
+			Engine:   db.Engine,
+			Version:  db.Version,
+			Host:     fmt.Sprintf("%s://nitro:nitro@127.0.0.1:%s", scheme, db.Port),
+			Site:     fmt.Sprintf("%s://nitro:nitro@%s:%s", scheme, hostname, port),
+		})
+	}
+
+	if cfg.Services.Mailhog {
+		d.Mailhog = &mail{
+			SMTPHost: mailhog.Host,
+			SMTPPort: 1025,
+			URL:      cfg.SiteURL("https", cfg.ProxyHostname(mailhog.Host)),
+		}
+	}
+
+	return d
+}
+
+// connection returns the scheme for connection strings and the port in the container for the
+// database engine.
+func connection(engine string) (string, string) {
+	switch engine {
+	case "postgres":
+		return "postgres", "5432"
+	case "mongodb":
+		return "mongodb", "27017"
+	default:
+		return "mysql", "3306"
+	}
+}
+
+// printDetails writes the details in a human readable format.
+func printDetails(w io.Writer, d details) {
+	fmt.Fprintln(w, "Site")
+	fmt.Fprintf(w, "  Hostname:   %s\n", d.Hostname)
+	if len(d.Aliases) > 0 {
+		fmt.Fprintf(w, "  Aliases:    %s\n", strings.Join(d.Aliases, ", "))
+	}
+	fmt.Fprintf(w, "  Path:       %s\n", d.Path)
+	fmt.Fprintf(w, "  Web root:   %s\n", d.Webroot)
+	fmt.Fprintf(w, "  PHP:        %s\n", d.PHP)
+	fmt.Fprintf(w, "  Xdebug:     %t\n", d.Xdebug)
+	fmt.Fprintf(w, "  Container:  %s (%s)\n", d.Container.Name, d.Container.Status)
+
+	fmt.Fprintln(w, "\nURLs")
+	for _, u := range d.URLs {
+		fmt.Fprintf(w, "  %s\n", u)
+	}
+
+	fmt.Fprintln(w, "\nDatabases")
+	if len(d.Databases) == 0 {
+		fmt.Fprintln(w, "  (none)")
+	}
+	for _, db := range d.Databases {
+		fmt.Fprintf(w, "  %s (%s %s)\n", db.Hostname, db.Engine, db.Version)
+		fmt.Fprintf(w, "    From the host:  %s\n", db.Host)
+		fmt.Fprintf(w, "    From the site:  %s\n", db.Site)
+	}
+
+	if d.Mailhog != nil {
+		fmt.Fprintln(w, "\nMail")
+		fmt.Fprintf(w, "  SMTP:   %s:%d\n", d.Mailhog.SMTPHost, d.Mailhog.SMTPPort)
+		fmt.Fprintf(w, "  Inbox:  %s\n", d.Mailhog.URL)
+	}
+}
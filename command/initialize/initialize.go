@@ -11,6 +11,7 @@ import (
 	"github.com/docker/docker/client"
 	"github.com/spf13/cobra"
 
+	"github.com/craftcms/nitro/pkg/apitoken"
 	"github.com/craftcms/nitro/pkg/config"
 	"github.com/craftcms/nitro/pkg/containerlabels"
 	"github.com/craftcms/nitro/pkg/proxycontainer"
@@ -45,12 +46,26 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 			}
 
 			// check if there is a config file
-			_, err := config.Load(home)
+			cfg, err := config.Load(home)
 			if errors.Is(err, config.ErrNoConfigFile) {
 				// walk the user through the first time setup
 				if err := setup.FirstTime(home, cmd.InOrStdin(), output); err != nil {
 					return err
 				}
+
+				cfg, err = config.Load(home)
+			}
+			if err != nil {
+				// the proxy ports fall back to the environment variables or the defaults
+				cfg = &config.Config{}
+			}
+
+			ports := proxycontainer.ConfigPorts(cfg)
+
+			// generate the token for the nitrod API
+			token, err := apitoken.LoadOrCreate(home)
+			if err != nil {
+				return err
 			}
 
 			output.Info("Checking Nitro…")
@@ -71,6 +86,21 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 			var networkID string
 			for _, n := range networks {
 				if n.Name == "nitro-network" || strings.TrimLeft(n.Name, "/") == "nitro-network" {
+					// a network without the labels is not found by apply, so it needs to be recreated
+					if n.Labels[containerlabels.Nitro] != "true" || n.Labels[containerlabels.Network] != "true" {
+						output.Pending("repairing network")
+
+						if err := docker.NetworkRemove(ctx, n.ID); err != nil {
+							output.Warning()
+
+							return fmt.Errorf("unable to remove the network, make sure no containers are using the network, %w", err)
+						}
+
+						output.Done()
+
+						continue
+					}
+
 					skipNetwork = true
 					networkID = n.ID
 				}
@@ -101,8 +131,33 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 				output.Done()
 			}
 
+			// check for an existing proxy that is outdated or missing labels
+			proxy, err := proxycontainer.FindAndStart(ctx, docker)
+			if err != nil && !errors.Is(err, proxycontainer.ErrNoProxyContainer) {
+				return err
+			}
+
+			// remove the proxy so it can be recreated
+			if err == nil && (!proxycontainer.IsCurrent(proxy, ports) || !proxycontainer.HasToken(proxy, token)) {
+				output.Pending("repairing proxy")
+
+				if err := docker.ContainerStop(ctx, proxy.ID, nil); err != nil {
+					output.Warning()
+
+					return fmt.Errorf("unable to stop the proxy container, %w", err)
+				}
+
+				if err := docker.ContainerRemove(ctx, proxy.ID, types.ContainerRemoveOptions{}); err != nil {
+					output.Warning()
+
+					return fmt.Errorf("unable to remove the proxy container, %w", err)
+				}
+
+				output.Done()
+			}
+
 			// create the proxy container
-			if err := proxycontainer.Create(cmd.Context(), docker, output, networkID); err != nil {
+			if err := proxycontainer.Create(cmd.Context(), docker, output, networkID, ports, token); err != nil {
 				return err
 			}
 
@@ -79,12 +79,14 @@ type mockDockerClient struct {
 	containerCreateResponse  container.ContainerCreateCreatedBody
 	containerStartRequests   []types.ContainerStartOptions
 	containerRestartRequests []string
+	containerRemoveRequests  []string
 
 	// network related resources for mocking the calls to the client
 	// for network specific resources
 	networks              []types.NetworkResource
 	networkCreateRequests []types.NetworkCreateRequest
 	networkCreateResponse types.NetworkCreateResponse
+	networkRemoveRequests []string
 
 	// volume related resources
 	volumes              volumetypes.VolumeListOKBody
@@ -156,6 +158,27 @@ func (c *mockDockerClient) ContainerRestart(ctx context.Context, container strin
 	return c.mockError
 }
 
+func (c *mockDockerClient) ContainerRemove(ctx context.Context, containerID string, options types.ContainerRemoveOptions) error {
+	c.containerRemoveRequests = append(c.containerRemoveRequests, containerID)
+
+	// remove the container from the mock storage
+	var containers []types.Container
+	for _, container := range c.containers {
+		if container.ID != containerID {
+			containers = append(containers, container)
+		}
+	}
+	c.containers = containers
+
+	return c.mockError
+}
+
+func (c *mockDockerClient) NetworkRemove(ctx context.Context, networkID string) error {
+	c.networkRemoveRequests = append(c.networkRemoveRequests, networkID)
+
+	return c.mockError
+}
+
 func (c *mockDockerClient) ContainerStop(ctx context.Context, containerID string, timeout *time.Duration) error {
 	c.containerID = containerID
 
@@ -4,9 +4,12 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"runtime"
 	"testing"
 
+	"github.com/craftcms/nitro/pkg/apitoken"
 	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/wsl"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/mount"
@@ -69,8 +72,10 @@ func TestInitFromFreshCreatesNewResources(t *testing.T) {
 				containerlabels.Type:         "proxy",
 				containerlabels.Proxy:        "true",
 				containerlabels.ProxyVersion: "develop",
+				containerlabels.ProxyPorts:   "80,443,5000",
+				containerlabels.ProxyToken:   apitoken.Hash("test-token"),
 			},
-			Env: []string{"PGPASSWORD=nitro", "PGUSER=nitro", "NITRO_VERSION=develop"},
+			Env: []string{"PGPASSWORD=nitro", "PGUSER=nitro", "NITRO_VERSION=develop", "NITRO_API_TOKEN=test-token"},
 		},
 		HostConfig: &container.HostConfig{
 			NetworkMode: "default",
@@ -153,6 +158,11 @@ func TestInitFromFreshCreatesNewResources(t *testing.T) {
 		)
 	}
 
+	// linux resolves the host machine with an extra host
+	if runtime.GOOS == "linux" && !wsl.IsWSL() {
+		containerCreateReq.HostConfig.ExtraHosts = []string{"host.docker.internal:host-gateway"}
+	}
+
 	// make sure the container create matches the expected
 	if !reflect.DeepEqual(mock.containerCreateRequests[0], containerCreateReq) {
 		if !reflect.DeepEqual(mock.containerCreateRequests[0].Config, containerCreateReq.Config) {
@@ -198,3 +208,158 @@ func TestInitFromFreshCreatesNewResources(t *testing.T) {
 		)
 	}
 }
+
+func TestInitWhenAlreadyInitializedDoesNotCreateResources(t *testing.T) {
+	// Arrange
+	networks := []types.NetworkResource{
+		{
+			ID:   "existing-network",
+			Name: "nitro-network",
+			Labels: map[string]string{
+				containerlabels.Nitro:   "true",
+				containerlabels.Network: "true",
+			},
+		},
+	}
+	containers := []types.Container{
+		{
+			ID:    "existing-proxy",
+			Names: []string{"/nitro-proxy"},
+			Image: "craftcms/nitro-proxy:develop",
+			State: "running",
+			Labels: map[string]string{
+				containerlabels.Nitro:        "true",
+				containerlabels.Type:         "proxy",
+				containerlabels.Proxy:        "true",
+				containerlabels.ProxyVersion: "develop",
+				containerlabels.ProxyPorts:   "80,443,5000",
+				containerlabels.ProxyToken:   apitoken.Hash("test-token"),
+			},
+		},
+	}
+	volumes := []*types.Volume{{Name: "nitro"}}
+	mock := newMockDockerClient(networks, containers, volumes)
+	home, _ := os.Getwd()
+	home = filepath.Join(home, "testdata")
+
+	// Act
+	cmd := NewCommand(home, mock, spyOutputer{})
+	err := cmd.RunE(cmd, os.Args)
+
+	// Assert
+	if err != nil {
+		t.Errorf("expected the error to be nil, got %v", err)
+	}
+
+	if len(mock.networkCreateRequests) != 0 {
+		t.Errorf("expected no networks to be created, got %d", len(mock.networkCreateRequests))
+	}
+
+	if len(mock.networkRemoveRequests) != 0 {
+		t.Errorf("expected no networks to be removed, got %d", len(mock.networkRemoveRequests))
+	}
+
+	if len(mock.containerCreateRequests) != 0 {
+		t.Errorf("expected no containers to be created, got %d", len(mock.containerCreateRequests))
+	}
+
+	if len(mock.containerRemoveRequests) != 0 {
+		t.Errorf("expected no containers to be removed, got %d", len(mock.containerRemoveRequests))
+	}
+}
+
+func TestInitWhenPartiallyBrokenRepairsResources(t *testing.T) {
+	tests := []struct {
+		name                   string
+		networks               []types.NetworkResource
+		containers             []types.Container
+		wantNetworkRemoves     []string
+		wantNetworkCreates     int
+		wantContainerRemoves   []string
+		wantContainerCreates   int
+		wantContainerCreateNet string
+	}{
+		{
+			name: "outdated proxy container is recreated",
+			networks: []types.NetworkResource{
+				{
+					ID:   "existing-network",
+					Name: "nitro-network",
+					Labels: map[string]string{
+						containerlabels.Nitro:   "true",
+						containerlabels.Network: "true",
+					},
+				},
+			},
+			containers: []types.Container{
+				{
+					ID:    "outdated-proxy",
+					Names: []string{"/nitro-proxy"},
+					Image: "craftcms/nitro-proxy:2.0.0",
+					State: "running",
+					Labels: map[string]string{
+						containerlabels.Nitro:        "true",
+						containerlabels.Type:         "proxy",
+						containerlabels.Proxy:        "true",
+						containerlabels.ProxyVersion: "2.0.0",
+					},
+				},
+			},
+			wantContainerRemoves:   []string{"outdated-proxy"},
+			wantContainerCreates:   1,
+			wantContainerCreateNet: "existing-network",
+		},
+		{
+			name: "network without labels is recreated",
+			networks: []types.NetworkResource{
+				{
+					ID:   "unlabeled-network",
+					Name: "nitro-network",
+				},
+			},
+			wantNetworkRemoves:     []string{"unlabeled-network"},
+			wantNetworkCreates:     1,
+			wantContainerCreates:   1,
+			wantContainerCreateNet: "new-network",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mock := newMockDockerClient(tt.networks, tt.containers, []*types.Volume{{Name: "nitro"}})
+			mock.networkCreateResponse = types.NetworkCreateResponse{ID: "new-network"}
+			mock.containerCreateResponse = container.ContainerCreateCreatedBody{ID: "new-proxy"}
+			home, _ := os.Getwd()
+			home = filepath.Join(home, "testdata")
+
+			// Act
+			cmd := NewCommand(home, mock, spyOutputer{})
+			err := cmd.RunE(cmd, os.Args)
+
+			// Assert
+			if err != nil {
+				t.Errorf("expected the error to be nil, got %v", err)
+			}
+
+			if !reflect.DeepEqual(mock.networkRemoveRequests, tt.wantNetworkRemoves) {
+				t.Errorf("expected network removes to match\ngot:\n%v\nwant:\n%v", mock.networkRemoveRequests, tt.wantNetworkRemoves)
+			}
+
+			if len(mock.networkCreateRequests) != tt.wantNetworkCreates {
+				t.Errorf("expected %d networks to be created, got %d", tt.wantNetworkCreates, len(mock.networkCreateRequests))
+			}
+
+			if !reflect.DeepEqual(mock.containerRemoveRequests, tt.wantContainerRemoves) {
+				t.Errorf("expected container removes to match\ngot:\n%v\nwant:\n%v", mock.containerRemoveRequests, tt.wantContainerRemoves)
+			}
+
+			if len(mock.containerCreateRequests) != tt.wantContainerCreates {
+				t.Fatalf("expected %d containers to be created, got %d", tt.wantContainerCreates, len(mock.containerCreateRequests))
+			}
+
+			if got := mock.containerCreateRequests[0].NetworkingConfig.EndpointsConfig["nitro-network"].NetworkID; got != tt.wantContainerCreateNet {
+				t.Errorf("expected the proxy to use network %q, got %q", tt.wantContainerCreateNet, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package lan
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/mdns"
+	"github.com/craftcms/nitro/pkg/prompt"
+	"github.com/craftcms/nitro/pkg/proxycontainer"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+const exampleText = `  # advertise the sites to devices on the local network
+  nitro lan
+
+  # advertise the sites with a specific address
+  nitro lan --ip 192.168.1.20`
+
+var (
+	// ErrLANDisabled is returned when the proxy is not bound on the local network
+	ErrLANDisabled = errors.New("lan access is not enabled for the proxy")
+
+	// ErrProxyNotUpdated is returned when the proxy was not recreated after enabling lan access
+	ErrProxyNotUpdated = errors.New("the proxy is not bound on the local network")
+)
+
+// NewCommand returns the command to advertise the site hostnames over mDNS, so phones and other
+// devices on the local network can reach the sites without editing their hosts files.
+func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lan",
+		Short: "Advertises the sites on the local network.",
+		Long: `Advertises the hostnames and aliases of the sites over mDNS with the address of this
+machine, the proxy must be bound on the local network by setting "lan: true" under
+"proxy" in the config and running apply.
+
+Some devices only use mDNS for .local hostnames, add a .local alias to the site
+(e.g. mysite.local) to reach the site from those devices.`,
+		Example: exampleText,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return prompt.VerifyInit(cmd, args, home, output)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			cfg, err := config.Load(home)
+			if err != nil {
+				return err
+			}
+
+			if !cfg.Proxy.LAN {
+				output.Info("LAN access is not enabled…\n set `lan: true` under `proxy` in the config and run `nitro apply`")
+
+				return ErrLANDisabled
+			}
+
+			proxy, err := proxycontainer.FindAndStart(ctx, docker)
+			if err != nil {
+				if errors.Is(err, proxycontainer.ErrNoProxyContainer) {
+					output.Info("unable to find the nitro proxy…\n run `nitro apply` to resolve")
+				}
+
+				return err
+			}
+
+			if !proxycontainer.HasPorts(proxy, proxycontainer.ConfigPorts(cfg)) {
+				output.Info("The proxy is not bound on the local network…\n run `nitro apply` to update the proxy")
+
+				return ErrProxyNotUpdated
+			}
+
+			var ip net.IP
+			if flag := cmd.Flag("ip").Value.String(); flag != "" {
+				if ip = net.ParseIP(flag).To4(); ip == nil {
+					return fmt.Errorf("unable to use %s as the address, only IPv4 addresses are supported", flag)
+				}
+			} else {
+				ip, err = mdns.LocalIP()
+				if err != nil {
+					output.Info("Unable to find the address for this machine…\n run `nitro lan --ip <address>` to set the address")
+
+					return err
+				}
+			}
+
+			var hostnames []string
+			for _, s := range cfg.Sites {
+				hostnames = append(hostnames, s.Hostname)
+				hostnames = append(hostnames, s.Aliases...)
+			}
+
+			if len(hostnames) == 0 {
+				output.Info("There are no sites to advertise…\n run `nitro add` to add a site")
+
+				return nil
+			}
+
+			output.Info("Advertising sites at", ip.String()+"…")
+
+			for _, h := range hostnames {
+				output.Info("  " + cfg.SiteURL("http", h))
+			}
+
+			output.Info("Press ctrl+c to stop advertising…")
+
+			ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+			defer stop()
+
+			return mdns.Serve(ctx, mdns.NewRecords(ip, hostnames...))
+		},
+	}
+
+	cmd.Flags().String("ip", "", "the address on the local network to advertise, defaults to the address of this machine")
+
+	return cmd
+}
@@ -0,0 +1,85 @@
+package logs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// missingLogCode is the exit code of the tail command when none of the error logs exist
+const missingLogCode = 3
+
+// errorLogs are the error logs in the site container for the --nginx and --php flags, in the
+// order they are shown.
+var errorLogs = []struct {
+	Flag string
+	File string
+}{
+	{Flag: "nginx", File: "/var/log/nginx/error.log"},
+	{Flag: "php", File: "/var/log/php-fpm.log"},
+}
+
+// tailCommand returns the command to tail the error logs in the site container. The logs that do
+// not exist are skipped and the command exits with missingLogCode when none of the logs exist.
+func tailCommand(files []string, tail string, follow bool) ([]string, error) {
+	lines := "+1"
+	if tail != "all" {
+		n, err := strconv.Atoi(tail)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("the tail must be a positive number or all")
+		}
+
+		lines = strconv.Itoa(n)
+	}
+
+	args := "-n " + lines
+	if follow {
+		args += " -F"
+	}
+
+	script := fmt.Sprintf(`files=""; for f in %s; do if [ -f "$f" ]; then files="$files $f"; fi; done; if [ -z "$files" ]; then exit %d; fi; exec tail %s $files`, strings.Join(files, " "), missingLogCode, args)
+
+	return []string{"sh", "-c", script}, nil
+}
+
+// tailErrorLogs runs the tail command in the container and writes the error logs to w.
+func tailErrorLogs(ctx context.Context, docker client.ContainerAPIClient, id string, command []string, w io.Writer) error {
+	exec, err := docker.ContainerExecCreate(ctx, id, types.ExecConfig{
+		AttachStderr: true,
+		AttachStdout: true,
+		Cmd:          command,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to create the exec, %w", err)
+	}
+
+	resp, err := docker.ContainerExecAttach(ctx, exec.ID, types.ExecStartCheck{})
+	if err != nil {
+		return fmt.Errorf("unable to attach to the exec, %w", err)
+	}
+	defer resp.Close()
+
+	if _, err := stdcopy.StdCopy(w, w, resp.Reader); err != nil {
+		return err
+	}
+
+	exit, err := docker.ContainerExecInspect(ctx, exec.ID)
+	if err != nil {
+		return err
+	}
+
+	switch exit.ExitCode {
+	case 0:
+		return nil
+	case missingLogCode:
+		return fmt.Errorf("unable to find the error logs in the container…\n run `nitro logs` to show the container logs")
+	default:
+		return fmt.Errorf("unable to read the error logs, the command exited with %d", exit.ExitCode)
+	}
+}
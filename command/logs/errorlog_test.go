@@ -0,0 +1,58 @@
+package logs
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_tailCommand(t *testing.T) {
+	type args struct {
+		files  []string
+		tail   string
+		follow bool
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "all lines are shown from the start of the log",
+			args: args{
+				files: []string{"/var/log/nginx/error.log"},
+				tail:  "all",
+			},
+			want: []string{"sh", "-c", `files=""; for f in /var/log/nginx/error.log; do if [ -f "$f" ]; then files="$files $f"; fi; done; if [ -z "$files" ]; then exit 3; fi; exec tail -n +1 $files`},
+		},
+		{
+			name: "multiple logs can be followed",
+			args: args{
+				files:  []string{"/var/log/nginx/error.log", "/var/log/php-fpm.log"},
+				tail:   "100",
+				follow: true,
+			},
+			want: []string{"sh", "-c", `files=""; for f in /var/log/nginx/error.log /var/log/php-fpm.log; do if [ -f "$f" ]; then files="$files $f"; fi; done; if [ -z "$files" ]; then exit 3; fi; exec tail -n 100 -F $files`},
+		},
+		{
+			name: "invalid tails return an error",
+			args: args{
+				files: []string{"/var/log/nginx/error.log"},
+				tail:  "ten",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tailCommand(tt.args.files, tt.args.tail, tt.args.follow)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("tailCommand() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tailCommand() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
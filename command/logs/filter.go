@@ -0,0 +1,91 @@
+package logs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// ring is a fixed size buffer that keeps the most recent lines.
+type ring struct {
+	lines []string
+	start int
+	size  int
+}
+
+func newRing(n int) *ring {
+	return &ring{lines: make([]string, n)}
+}
+
+// push adds a line to the buffer, replacing the oldest line when the buffer is full.
+func (r *ring) push(line string) {
+	if len(r.lines) == 0 {
+		return
+	}
+
+	if r.size < len(r.lines) {
+		r.lines[(r.start+r.size)%len(r.lines)] = line
+		r.size++
+		return
+	}
+
+	r.lines[r.start] = line
+	r.start = (r.start + 1) % len(r.lines)
+}
+
+// drain returns the buffered lines, oldest first, and empties the buffer.
+func (r *ring) drain() []string {
+	lines := make([]string, 0, r.size)
+	for i := 0; i < r.size; i++ {
+		lines = append(lines, r.lines[(r.start+i)%len(r.lines)])
+	}
+
+	r.start = 0
+	r.size = 0
+
+	return lines
+}
+
+// filterLines reads the log line by line and writes each line that matches, along with
+// the number of context lines before and after the match. When using context lines,
+// groups of lines that are not next to each other are separated by "--", the same as grep.
+func filterLines(r io.Reader, w io.Writer, match func(string) bool, context int) error {
+	before := newRing(context)
+	after := 0
+	printed := false
+	skipped := false
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case match(line):
+			// separate this group from the previous one
+			lines := before.drain()
+			if context > 0 && printed && skipped {
+				fmt.Fprintln(w, "--")
+			}
+
+			for _, l := range append(lines, line) {
+				fmt.Fprintln(w, l)
+			}
+
+			printed = true
+			skipped = false
+			after = context
+		case after > 0:
+			fmt.Fprintln(w, line)
+			after--
+		default:
+			// track if a line was dropped between groups
+			if before.size == len(before.lines) {
+				skipped = true
+			}
+
+			before.push(line)
+		}
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,101 @@
+package logs
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func Test_filterLines(t *testing.T) {
+	log := strings.Join([]string{
+		"one",
+		"two",
+		"three error",
+		"four",
+		"five",
+		"six",
+		"seven",
+		"eight error",
+		"nine",
+	}, "\n")
+
+	type args struct {
+		log     string
+		context int
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "no context lines only returns the matches",
+			args: args{
+				log:     log,
+				context: 0,
+			},
+			want: "three error\neight error\n",
+		},
+		{
+			name: "context lines are returned before and after the matches",
+			args: args{
+				log:     log,
+				context: 1,
+			},
+			want: "two\nthree error\nfour\n--\nseven\neight error\nnine\n",
+		},
+		{
+			name: "overlapping context lines are not repeated or separated",
+			args: args{
+				log:     log,
+				context: 2,
+			},
+			want: "one\ntwo\nthree error\nfour\nfive\nsix\nseven\neight error\nnine\n",
+		},
+		{
+			name: "context lines at the start and end of the log are limited to the log",
+			args: args{
+				log:     "error\nafter",
+				context: 5,
+			},
+			want: "error\nafter\n",
+		},
+		{
+			name: "logs without matches return nothing",
+			args: args{
+				log:     "one\ntwo",
+				context: 1,
+			},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			match := func(s string) bool { return strings.Contains(s, "error") }
+
+			if err := filterLines(strings.NewReader(tt.args.log), w, match, tt.args.context); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := w.String(); got != tt.want {
+				t.Errorf("filterLines() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ring(t *testing.T) {
+	r := newRing(2)
+	for _, l := range []string{"one", "two", "three"} {
+		r.push(l)
+	}
+
+	if got := strings.Join(r.drain(), ","); got != "two,three" {
+		t.Errorf("ring.drain() = %q, want %q", got, "two,three")
+	}
+
+	if got := r.drain(); len(got) != 0 {
+		t.Errorf("expected the ring to be empty after draining, got %v", got)
+	}
+}
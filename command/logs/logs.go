@@ -1,8 +1,13 @@
 package logs
 
 import (
+	"fmt"
+	"io"
 	"os"
+	"regexp"
 	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
@@ -22,7 +27,22 @@ const exampleText = `  # show logs from a site
   nitro logs --since 5m
 
   # show logs but don't follow
-  nitro logs --follow=false`
+  nitro logs --follow=false
+
+  # show only lines matching a pattern with 3 lines before and after
+  nitro logs --grep "PHP Fatal error" --context-lines 3
+
+  # show the last 100 lines for a site and the databases
+  nitro logs tutorial.nitro --databases --tail 100
+
+  # show logs for multiple containers
+  nitro logs tutorial.nitro mysql-8.0-3306.database.nitro
+
+  # show the nginx and PHP-FPM error logs for a site
+  nitro logs tutorial.nitro --nginx --php
+
+  # show each line as json for scripts
+  nitro logs --follow=false --output json`
 
 // NewCommand returns the command to show a containers logs. It will check if the current working
 // directory is a known site and default to that container or provide the user with a list of sites
@@ -30,7 +50,7 @@ const exampleText = `  # show logs from a site
 // the docker logs API flags.
 func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "logs",
+		Use:     "logs [container...]",
 		Short:   "Displays container logs.",
 		Example: exampleText,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -46,44 +66,90 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 				return err
 			}
 
+			format := cmd.Flag("output").Value.String()
+			if err := terminal.ValidateFormat(format); err != nil {
+				return err
+			}
+
 			// create a filter for the environment
 			filter := filters.NewArgs()
 			filter.Add("label", containerlabels.Nitro)
 
-			// get a context aware list of sites
-			sites := cfg.ListOfSitesByDirectory(home, wd)
+			var containers []types.Container
+			switch {
+			case cmd.Flag("all").Value.String() == "true":
+				// show the logs for every running container
+				containers, err = docker.ContainerList(cmd.Context(), types.ContainerListOptions{Filters: filter})
+				if err != nil {
+					return err
+				}
+			case len(args) > 0:
+				// find the containers by the container name or site hostname
+				running, err := docker.ContainerList(cmd.Context(), types.ContainerListOptions{Filters: filter})
+				if err != nil {
+					return err
+				}
 
-			// create the options for the sites
-			var options []string
-			for _, s := range sites {
-				options = append(options, s.Hostname)
-			}
+				for _, name := range args {
+					c, ok := findContainer(running, name)
+					if !ok {
+						return fmt.Errorf("unable to find a running container for %s", name)
+					}
+
+					containers = append(containers, c)
+				}
+			default:
+				// get a context aware list of sites
+				sites := cfg.ListOfSitesByDirectory(home, wd)
+
+				// create the options for the sites
+				var options []string
+				for _, s := range sites {
+					options = append(options, s.Hostname)
+				}
+
+				siteFilter := filters.NewArgs()
+				siteFilter.Add("label", containerlabels.Nitro)
+
+				switch len(sites) {
+				case 1:
+					if format == terminal.FormatText {
+						output.Info("show logs for", sites[0].Hostname)
+					}
 
-			switch len(sites) {
-			case 0:
-				selected, err := output.Select(cmd.InOrStdin(), "Select a site: ", options)
+					siteFilter.Add("label", containerlabels.Host+"="+sites[0].Hostname)
+				default:
+					selected, err := output.Select(cmd.InOrStdin(), "Select a site: ", options)
+					if err != nil {
+						return err
+					}
+
+					siteFilter.Add("label", containerlabels.Host+"="+sites[selected].Hostname)
+				}
+
+				// find all of the containers, there should only be one if we are in a known directory
+				containers, err = docker.ContainerList(cmd.Context(), types.ContainerListOptions{Filters: siteFilter})
 				if err != nil {
 					return err
 				}
+			}
 
-				filter.Add("label", containerlabels.Host+"="+sites[selected].Hostname)
-			case 1:
-				output.Info("show logs for", sites[0].Hostname)
+			// add the database containers to the site
+			if cmd.Flag("databases").Value.String() == "true" && cmd.Flag("all").Value.String() != "true" {
+				databaseFilter := filters.NewArgs()
+				databaseFilter.Add("label", containerlabels.Nitro)
+				databaseFilter.Add("label", containerlabels.Type+"=database")
 
-				filter.Add("label", containerlabels.Host+"="+sites[0].Hostname)
-			default:
-				selected, err := output.Select(cmd.InOrStdin(), "Select a site: ", options)
+				databases, err := docker.ContainerList(cmd.Context(), types.ContainerListOptions{Filters: databaseFilter})
 				if err != nil {
 					return err
 				}
 
-				filter.Add("label", containerlabels.Host+"="+sites[selected].Hostname)
+				containers = append(containers, databases...)
 			}
 
-			// find all of the containers, there should only be one if we are in a known directory
-			containers, err := docker.ContainerList(cmd.Context(), types.ContainerListOptions{Filters: filter})
-			if err != nil {
-				return err
+			if len(containers) == 0 {
+				return fmt.Errorf("unable to find a running container…\n run `nitro start` to start the containers")
 			}
 
 			// set the options for logging based on the command flags
@@ -109,16 +175,100 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 				opts.Since = cmd.Flag("since").Value.String()
 			}
 
+			opts.Tail = cmd.Flag("tail").Value.String()
+
+			contextLines, err := strconv.Atoi(cmd.Flag("context-lines").Value.String())
+			if err != nil || contextLines < 0 {
+				return fmt.Errorf("context lines must be a positive number")
+			}
+
+			grep := cmd.Flag("grep").Value.String()
+			match := func(string) bool { return true }
+			if grep != "" {
+				re, err := regexp.Compile(grep)
+				if err != nil {
+					return fmt.Errorf("unable to parse the grep pattern, %w", err)
+				}
+
+				match = re.MatchString
+			}
+
+			// show the error logs from inside the site container
+			var files []string
+			for _, l := range errorLogs {
+				if cmd.Flag(l.Flag).Value.String() == "true" {
+					files = append(files, l.File)
+				}
+			}
+
+			if len(files) > 0 {
+				if len(containers) != 1 || containers[0].Labels[containerlabels.Host] == "" {
+					return fmt.Errorf("the --nginx and --php flags can only show the logs for one site")
+				}
+
+				command, err := tailCommand(files, opts.Tail, follow)
+				if err != nil {
+					return err
+				}
+
+				var w io.Writer = cmd.OutOrStdout()
+				if format == terminal.FormatJSON {
+					w = &lineWriter{mu: &sync.Mutex{}, w: w, format: asJSON(containerNames(containers)[0], false)}
+				}
+
+				if grep == "" {
+					return tailErrorLogs(cmd.Context(), docker, containers[0].ID, command, w)
+				}
+
+				r, pw := io.Pipe()
+				go func() {
+					pw.CloseWithError(tailErrorLogs(cmd.Context(), docker, containers[0].ID, command, pw))
+				}()
+
+				return filterLines(r, w, match, contextLines)
+			}
+
+			// show the logs for each container with a prefix or as json
+			if len(containers) > 1 || format == terminal.FormatJSON {
+				names := containerNames(containers)
+
+				var formats []lineFormat
+				switch format {
+				case terminal.FormatJSON:
+					for _, n := range names {
+						formats = append(formats, asJSON(n, timestamps))
+					}
+				default:
+					color := terminal.IsTerminal(os.Stdout) && cmd.Flag("no-color").Value.String() != "true"
+					for _, p := range prefixes(names, color) {
+						formats = append(formats, withPrefix(p))
+					}
+				}
+
+				return multiplex(cmd.Context(), docker, containers, formats, opts, cmd.OutOrStdout(), match, contextLines)
+			}
+
 			// get the containers logs
 			out, err := docker.ContainerLogs(cmd.Context(), containers[0].ID, opts)
 			if err != nil {
 				return err
 			}
 
-			// show the output
-			stdcopy.StdCopy(cmd.OutOrStdout(), cmd.ErrOrStderr(), out)
+			// show the output if we are not filtering
+			if grep == "" {
+				stdcopy.StdCopy(cmd.OutOrStdout(), cmd.ErrOrStderr(), out)
+
+				return nil
+			}
+
+			// combine stdout and stderr so the lines can be filtered in order
+			r, w := io.Pipe()
+			go func() {
+				_, err := stdcopy.StdCopy(w, w, out)
+				w.CloseWithError(err)
+			}()
 
-			return nil
+			return filterLines(r, cmd.OutOrStdout(), match, contextLines)
 		},
 	}
 
@@ -126,6 +276,32 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 	cmd.Flags().Bool("follow", true, "follow log output")
 	cmd.Flags().Bool("timestamps", false, "show timestamps")
 	cmd.Flags().String("since", "", "Show logs since timestamp (e.g. 2013-01-02T13:23:37Z) or relative (e.g. 42m for 42 minutes)")
+	cmd.Flags().String("grep", "", "only show lines matching the pattern")
+	cmd.Flags().Int("context-lines", 0, "number of lines to show before and after each matching line")
+	cmd.Flags().String("tail", "all", "number of lines to show from the end of the logs")
+	cmd.Flags().Bool("databases", false, "also show the logs for the databases")
+	cmd.Flags().Bool("all", false, "show the logs for all running containers")
+	cmd.Flags().Bool("no-color", false, "do not color the container names")
+	cmd.Flags().Bool("nginx", false, "show the nginx error log from the site container")
+	cmd.Flags().Bool("php", false, "show the PHP-FPM error log from the site container")
+	cmd.Flags().StringP("output", "o", terminal.FormatText, "the output format (text or json)")
 
 	return cmd
 }
+
+// findContainer returns the container with the name or the site container for the hostname.
+func findContainer(containers []types.Container, name string) (types.Container, bool) {
+	for _, c := range containers {
+		if c.Labels[containerlabels.Host] == name {
+			return c, true
+		}
+
+		for _, n := range c.Names {
+			if strings.TrimLeft(n, "/") == name {
+				return c, true
+			}
+		}
+	}
+
+	return types.Container{}, false
+}
@@ -0,0 +1,175 @@
+package logs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// colors are the ANSI colors for the container prefixes (cyan, yellow, green, magenta, blue, and red)
+var colors = []string{"36", "33", "32", "35", "34", "31"}
+
+// prefixes returns the prefix for each name, padded to the longest name so the logs line up,
+// the same as docker-compose logs.
+func prefixes(names []string, color bool) []string {
+	width := 0
+	for _, n := range names {
+		if len(n) > width {
+			width = len(n)
+		}
+	}
+
+	var p []string
+	for i, n := range names {
+		prefix := fmt.Sprintf("%-*s |", width, n)
+		if color {
+			prefix = fmt.Sprintf("\033[%sm%s\033[0m", colors[i%len(colors)], prefix)
+		}
+
+		p = append(p, prefix+" ")
+	}
+
+	return p
+}
+
+// lineFormat formats a single line of the logs, the line includes the new line.
+type lineFormat func(line string) string
+
+// withPrefix returns a format that adds the prefix to each line.
+func withPrefix(prefix string) lineFormat {
+	return func(line string) string {
+		return prefix + line
+	}
+}
+
+// logLine is a line of the logs in the json output.
+type logLine struct {
+	Container string `json:"container"`
+	Time      string `json:"time,omitempty"`
+	Message   string `json:"message"`
+}
+
+// asJSON returns a format that writes each line as a json object for the container. When the
+// logs have timestamps, the timestamp is moved from the message into the time.
+func asJSON(name string, timestamps bool) lineFormat {
+	return func(line string) string {
+		l := logLine{Container: name, Message: strings.TrimRight(line, "\r\n")}
+
+		if timestamps {
+			if sp := strings.SplitN(l.Message, " ", 2); len(sp) == 2 {
+				if _, err := time.Parse(time.RFC3339Nano, sp[0]); err == nil {
+					l.Time, l.Message = sp[0], sp[1]
+				}
+			}
+		}
+
+		b, err := json.Marshal(l)
+		if err != nil {
+			return ""
+		}
+
+		return string(b) + "\n"
+	}
+}
+
+// lineWriter writes complete lines using the format. The mutex is shared by the writers for
+// each container so lines from different containers are not mixed.
+type lineWriter struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	format lineFormat
+	buf    []byte
+}
+
+func (l *lineWriter) Write(b []byte) (int, error) {
+	l.buf = append(l.buf, b...)
+
+	for {
+		i := bytes.IndexByte(l.buf, '\n')
+		if i == -1 {
+			return len(b), nil
+		}
+
+		if err := l.writeLine(string(l.buf[:i+1])); err != nil {
+			return 0, err
+		}
+
+		l.buf = l.buf[i+1:]
+	}
+}
+
+func (l *lineWriter) writeLine(line string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, err := io.WriteString(l.w, l.format(line))
+
+	return err
+}
+
+// containerNames returns the name of each container without the leading slash.
+func containerNames(containers []types.Container) []string {
+	var names []string
+	for _, c := range containers {
+		names = append(names, strings.TrimLeft(c.Names[0], "/"))
+	}
+
+	return names
+}
+
+// multiplex writes the logs for each container to w using the format for the container (e.g. the
+// containers name as a prefix on each line). Only the lines that match are written, with the
+// number of context lines before and after.
+func multiplex(ctx context.Context, docker client.ContainerAPIClient, containers []types.Container, formats []lineFormat, opts types.ContainerLogsOptions, w io.Writer, match func(string) bool, contextLines int) error {
+	names := containerNames(containers)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	errs := make([]error, len(containers))
+
+	for i, format := range formats {
+		out, err := docker.ContainerLogs(ctx, containers[i].ID, opts)
+		if err != nil {
+			return fmt.Errorf("unable to get the logs for %s, %w", names[i], err)
+		}
+
+		wg.Add(1)
+		go func(i int, format lineFormat, out io.ReadCloser) {
+			defer wg.Done()
+			defer out.Close()
+
+			lw := &lineWriter{mu: &mu, w: w, format: format}
+
+			// combine stdout and stderr so the lines can be filtered in order
+			r, pipe := io.Pipe()
+			go func() {
+				_, err := stdcopy.StdCopy(pipe, pipe, out)
+				pipe.CloseWithError(err)
+			}()
+
+			// the filtered lines always end with a new line, so nothing is left in the buffer
+			if err := filterLines(r, lw, match, contextLines); err != nil {
+				errs[i] = fmt.Errorf("unable to read the logs for %s, %w", names[i], err)
+			}
+		}(i, format, out)
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
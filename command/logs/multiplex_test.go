@@ -0,0 +1,176 @@
+package logs
+
+import (
+	"bytes"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+)
+
+func Test_prefixes(t *testing.T) {
+	type args struct {
+		names []string
+		color bool
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "prefixes are padded to the longest name",
+			args: args{
+				names: []string{"tutorial.nitro", "mysql-8.0-3306.database.nitro"},
+			},
+			want: []string{
+				"tutorial.nitro                | ",
+				"mysql-8.0-3306.database.nitro | ",
+			},
+		},
+		{
+			name: "prefixes are colored in order",
+			args: args{
+				names: []string{"a", "b"},
+				color: true,
+			},
+			want: []string{
+				"\033[36ma |\033[0m ",
+				"\033[33mb |\033[0m ",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := prefixes(tt.args.names, tt.args.color); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("prefixes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_lineWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		writes []string
+		want   string
+	}{
+		{
+			name:   "complete lines are prefixed",
+			writes: []string{"one\ntwo\n"},
+			want:   "site | one\nsite | two\n",
+		},
+		{
+			name:   "partial lines are written once complete",
+			writes: []string{"o", "ne\ntw", "o\nthree"},
+			want:   "site | one\nsite | two\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			w := &lineWriter{mu: &sync.Mutex{}, w: buf, format: withPrefix("site | ")}
+
+			for _, s := range tt.writes {
+				if _, err := w.Write([]byte(s)); err != nil {
+					t.Fatalf("Write() error = %v", err)
+				}
+			}
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_asJSON(t *testing.T) {
+	type args struct {
+		name       string
+		timestamps bool
+		line       string
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "lines are written as json",
+			args: args{
+				name: "tutorial.nitro",
+				line: "GET /index.php \"200\"\n",
+			},
+			want: `{"container":"tutorial.nitro","message":"GET /index.php \"200\""}` + "\n",
+		},
+		{
+			name: "timestamps are moved to the time",
+			args: args{
+				name:       "tutorial.nitro",
+				timestamps: true,
+				line:       "2021-03-04T15:04:05.123456789Z started\n",
+			},
+			want: `{"container":"tutorial.nitro","time":"2021-03-04T15:04:05.123456789Z","message":"started"}` + "\n",
+		},
+		{
+			name: "lines without a timestamp keep the message",
+			args: args{
+				name:       "tutorial.nitro",
+				timestamps: true,
+				line:       "no timestamp\n",
+			},
+			want: `{"container":"tutorial.nitro","message":"no timestamp"}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := asJSON(tt.args.name, tt.args.timestamps)(tt.args.line); got != tt.want {
+				t.Errorf("asJSON() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_findContainer(t *testing.T) {
+	containers := []types.Container{
+		{ID: "site", Names: []string{"/tutorial.nitro"}, Labels: map[string]string{"com.craftcms.nitro.host": "tutorial.nitro"}},
+		{ID: "db", Names: []string{"/mysql-8.0-3306.database.nitro"}},
+	}
+
+	tests := []struct {
+		name   string
+		search string
+		want   string
+		found  bool
+	}{
+		{
+			name:   "sites are found by the hostname",
+			search: "tutorial.nitro",
+			want:   "site",
+			found:  true,
+		},
+		{
+			name:   "containers are found by the name",
+			search: "mysql-8.0-3306.database.nitro",
+			want:   "db",
+			found:  true,
+		},
+		{
+			name:   "unknown names are not found",
+			search: "unknown.nitro",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := findContainer(containers, tt.search)
+			if found != tt.found {
+				t.Fatalf("findContainer() found = %v, want %v", found, tt.found)
+			}
+
+			if got.ID != tt.want {
+				t.Errorf("findContainer() = %v, want %v", got.ID, tt.want)
+			}
+		})
+	}
+}
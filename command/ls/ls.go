@@ -22,7 +22,10 @@ const exampleText = `  # view information about your nitro environment
   nitro ls --databases
 
   # show only sites
-  nitro ls --sites`
+  nitro ls --sites
+
+  # show only containers created by a specific apply
+  nitro ls --run-id 3f2a9c1e`
 
 var (
 	flagCustom, flagDatabases, flagProxy, flagServices, flagSites bool
@@ -39,6 +42,11 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 			filter := filters.NewArgs()
 			filter.Add("label", containerlabels.Nitro)
 
+			// show only the containers from a specific apply
+			if id := cmd.Flag("run-id").Value.String(); id != "" {
+				filter.Add("label", containerlabels.RunID+"="+id)
+			}
+
 			// get a list of all the databases
 			containers, err := docker.ContainerList(cmd.Context(), types.ContainerListOptions{All: true, Filters: filter})
 			if err != nil {
@@ -74,7 +82,7 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 				}
 
 				if cmd.Flag("services").Value.String() == "true" {
-					if c.Labels[containerlabels.Type] != "dynamodb" && c.Labels[containerlabels.Type] != "mailhog" && c.Labels[containerlabels.Type] != "redis" {
+					if c.Labels[containerlabels.Type] != "dynamodb" && c.Labels[containerlabels.Type] != "elasticsearch" && c.Labels[containerlabels.Type] != "mailhog" && c.Labels[containerlabels.Type] != "meilisearch" && c.Labels[containerlabels.Type] != "rabbitmq" && c.Labels[containerlabels.Type] != "redis" {
 						continue
 					}
 				}
@@ -141,6 +149,7 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 	cmd.Flags().BoolVarP(&flagServices, "services", "v", false, "show only services")
 	cmd.Flags().BoolVarP(&flagCustom, "custom", "c", false, "show only custom containers")
 	cmd.Flags().BoolVarP(&flagProxy, "proxy", "p", false, "show only proxy container")
+	cmd.Flags().String("run-id", "", "show only containers created by an apply run")
 
 	return cmd
 }
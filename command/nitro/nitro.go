@@ -1,30 +1,42 @@
 package nitro
 
 import (
+	stdcontext "context"
 	"log"
+	"net"
 	"os"
 
 	nitroclient "github.com/craftcms/nitro/client"
 	"github.com/craftcms/nitro/command/add"
 	"github.com/craftcms/nitro/command/alias"
 	"github.com/craftcms/nitro/command/apply"
+	"github.com/craftcms/nitro/command/backups"
 	"github.com/craftcms/nitro/command/bridge"
 	"github.com/craftcms/nitro/command/clean"
 	"github.com/craftcms/nitro/command/completion"
 	"github.com/craftcms/nitro/command/composer"
 	"github.com/craftcms/nitro/command/container"
 	"github.com/craftcms/nitro/command/context"
+	"github.com/craftcms/nitro/command/cp"
 	"github.com/craftcms/nitro/command/craft"
 	"github.com/craftcms/nitro/command/create"
 	"github.com/craftcms/nitro/command/database"
 	"github.com/craftcms/nitro/command/destroy"
 	"github.com/craftcms/nitro/command/disable"
+	"github.com/craftcms/nitro/command/dns"
+	"github.com/craftcms/nitro/command/doctor"
 	"github.com/craftcms/nitro/command/edit"
 	"github.com/craftcms/nitro/command/enable"
+	"github.com/craftcms/nitro/command/env"
+	"github.com/craftcms/nitro/command/export"
 	"github.com/craftcms/nitro/command/extensions"
 	"github.com/craftcms/nitro/command/hosts"
+	"github.com/craftcms/nitro/command/ide"
+	"github.com/craftcms/nitro/command/importer"
+	"github.com/craftcms/nitro/command/info"
 	"github.com/craftcms/nitro/command/iniset"
 	"github.com/craftcms/nitro/command/initialize"
+	"github.com/craftcms/nitro/command/lan"
 	"github.com/craftcms/nitro/command/logs"
 	"github.com/craftcms/nitro/command/ls"
 	"github.com/craftcms/nitro/command/npm"
@@ -33,10 +45,12 @@ import (
 	"github.com/craftcms/nitro/command/queue"
 	"github.com/craftcms/nitro/command/remove"
 	"github.com/craftcms/nitro/command/restart"
+	"github.com/craftcms/nitro/command/selfdiagnose"
 	"github.com/craftcms/nitro/command/selfupdate"
 	"github.com/craftcms/nitro/command/share"
 	"github.com/craftcms/nitro/command/ssh"
 	"github.com/craftcms/nitro/command/start"
+	"github.com/craftcms/nitro/command/status"
 	"github.com/craftcms/nitro/command/stop"
 	"github.com/craftcms/nitro/command/trust"
 	"github.com/craftcms/nitro/command/update"
@@ -44,7 +58,10 @@ import (
 	"github.com/craftcms/nitro/command/version"
 	"github.com/craftcms/nitro/command/xoff"
 	"github.com/craftcms/nitro/command/xon"
+	"github.com/craftcms/nitro/command/xprofile"
+	"github.com/craftcms/nitro/pkg/config"
 	"github.com/craftcms/nitro/pkg/downloader"
+	"github.com/craftcms/nitro/pkg/proxycontainer"
 	"github.com/craftcms/nitro/pkg/terminal"
 	"github.com/docker/docker/client"
 	"github.com/mitchellh/go-homedir"
@@ -60,12 +77,38 @@ Version: ` + version.Version,
 	RunE:         rootMain,
 	SilenceUsage: true,
 	Version:      version.Version,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// select the environment from the flag or the environment variable
+		env := cmd.Flag("env").Value.String()
+		if env == "" {
+			env = os.Getenv(config.EnvironmentVariable)
+		}
+
+		return config.UseEnvironment(env)
+	},
 }
 
 func rootMain(command *cobra.Command, _ []string) error {
 	return command.Help()
 }
 
+// apiDialer returns a dialer for the nitrod API using the transport and port from the config, or
+// from the environment variables or the defaults when there is no config.
+func apiDialer(home string, docker client.ContainerAPIClient) nitroclient.Dialer {
+	return func(ctx stdcontext.Context) (net.Conn, error) {
+		cfg, err := config.Load(home)
+		if err != nil {
+			cfg = &config.Config{}
+		}
+
+		if cfg.GetAPITransport() == config.APITransportExec {
+			return nitroclient.DialExec(ctx, docker, proxycontainer.ProxyName)
+		}
+
+		return nitroclient.DialTCP(ctx, "127.0.0.1", cfg.GetAPIPort())
+	}
+}
+
 func NewCommand() *cobra.Command {
 	// get the users home directory
 	home, err := homedir.Dir()
@@ -79,14 +122,10 @@ func NewCommand() *cobra.Command {
 		log.Fatal(err)
 	}
 
-	// get the port for the nitrod API
-	apiPort := "5000"
-	if os.Getenv("NITRO_API_PORT") != "" {
-		apiPort = os.Getenv("NITRO_API_PORT")
-	}
-
-	// create the nitrod gRPC API
-	nitrod, err := nitroclient.NewClient("127.0.0.1", apiPort)
+	// create the nitrod gRPC API, the transport and port are looked up when
+	// connecting since the environment is selected after the commands are created,
+	// and the token created by init is sent with each request
+	nitrod, err := nitroclient.NewClientWithDialer(apiDialer(home, docker), nitroclient.WithToken(home))
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -102,44 +141,62 @@ func NewCommand() *cobra.Command {
 		add.NewCommand(home, docker, term),
 		alias.NewCommand(home, docker, term),
 		apply.NewCommand(home, docker, nitrod, term),
+		backups.NewCommand(home, docker, term),
 		bridge.NewCommand(home, docker, term),
 		clean.NewCommand(home, docker, term),
 		completion.NewCommand(),
-		composer.NewCommand(docker, term),
+		composer.NewCommand(home, docker, term),
 		container.NewCommand(home, docker, term),
 		context.NewCommand(home, docker, term),
+		cp.NewCommand(home, docker, term),
 		craft.NewCommand(home, docker, term),
 		create.NewCommand(home, docker, downloader, term),
 		database.NewCommand(home, docker, nitrod, term),
 		destroy.NewCommand(home, docker, term),
 		disable.NewCommand(home, docker, term),
+		dns.NewCommand(term),
+		doctor.NewCommand(home, docker, nitrod, term),
 		enable.NewCommand(home, docker, term),
 		edit.NewCommand(home, docker, term),
+		env.NewCommand(home, docker, term),
+		export.NewCommand(home, docker, term),
 		extensions.NewCommand(home, docker, term),
 		hosts.NewCommand(home, term),
+		ide.NewCommand(home, term),
+		importer.NewCommand(home, docker, term),
+		info.NewCommand(home, docker, term),
 		iniset.NewCommand(home, docker, term),
 		initialize.NewCommand(home, docker, term),
+		lan.NewCommand(home, docker, term),
 		logs.NewCommand(home, docker, term),
 		ls.NewCommand(home, docker, term),
 		npm.NewCommand(docker, term),
+		npm.NewPnpmCommand(docker, term),
 		php.NewCommand(home, docker, term),
 		portcheck.NewCommand(term),
 		queue.NewCommand(home, docker, term),
 		remove.NewCommand(home, docker, term),
 		restart.NewCommand(home, docker, term),
+		selfdiagnose.NewCommand(home, docker, term),
 		selfupdate.NewCommand(term),
 		share.NewCommand(home, docker, term),
 		ssh.NewCommand(home, docker, term),
 		start.NewCommand(home, docker, term),
+		status.NewCommand(home, docker, nitrod, term),
 		stop.NewCommand(home, docker, term),
 		trust.NewCommand(home, docker, term),
 		update.NewCommand(home, docker, term),
 		validate.NewCommand(home, docker, term),
 		version.NewCommand(home, docker, nitrod, term),
 		xon.NewCommand(home, docker, term),
+		xprofile.NewCommand(home, docker, term),
 		xoff.NewCommand(home, docker, term),
+		npm.NewYarnCommand(docker, term),
 	}
 
+	// select a named environment with its own config file, sites, and custom containers
+	rootCommand.PersistentFlags().String("env", "", "the named environment for the config, sites, and custom containers (e.g. work), defaults to the "+config.EnvironmentVariable+" environment variable")
+
 	// add the commands
 	rootCommand.AddCommand(commands...)
 
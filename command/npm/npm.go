@@ -39,12 +39,40 @@ const exampleText = `  # run npm install in a current directory
   # run a script
   nitro npm run dev`
 
+const yarnExampleText = `  # run yarn install in a current directory
+  nitro yarn install
+
+  # run a script with a specific node version
+  nitro yarn run dev --node-version 16`
+
+const pnpmExampleText = `  # run pnpm install in a current directory
+  nitro pnpm install
+
+  # run a script
+  nitro pnpm run dev`
+
 // NewCommand is the command used to run npm commands in a container.
 func NewCommand(docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	return newCommand(docker, output, "npm", "Runs an npm command.", exampleText)
+}
+
+// NewYarnCommand is the command used to run yarn commands in a container.
+func NewYarnCommand(docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	return newCommand(docker, output, "yarn", "Runs a yarn command.", yarnExampleText)
+}
+
+// NewPnpmCommand is the command used to run pnpm commands in a container.
+func NewPnpmCommand(docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	return newCommand(docker, output, "pnpm", "Runs a pnpm command.", pnpmExampleText)
+}
+
+// newCommand returns the command to run the package manager in a node container. The node
+// version is selected from the flags, the .nvmrc file, or the engines in package.json.
+func newCommand(docker client.CommonAPIClient, output terminal.Outputer, tool, short, example string) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "npm",
-		Short:   "Runs an npm command.",
-		Example: exampleText,
+		Use:     tool,
+		Short:   short,
+		Example: example,
 		Args:    cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
@@ -54,8 +82,6 @@ func NewCommand(docker client.CommonAPIClient, output terminal.Outputer) *cobra.
 				// context just in case.
 				ctx = context.Background()
 			}
-			version := cmd.Flag("version").Value.String()
-
 			var path string
 			wd, err := os.Getwd()
 			if err != nil {
@@ -84,6 +110,11 @@ func NewCommand(docker client.CommonAPIClient, output terminal.Outputer) *cobra.
 
 			output.Done()
 
+			version, err := nodeVersion(cmd, path)
+			if err != nil {
+				return err
+			}
+
 			// find the network
 			networkFilter := filters.NewArgs()
 			networkFilter.Add("name", "nitro-network")
@@ -143,8 +174,7 @@ func NewCommand(docker client.CommonAPIClient, output terminal.Outputer) *cobra.
 			}
 
 			// set the volume name
-			// TODO(jasonmccallister) remove this hardcoded version
-			volumeName := volumename.FromPath(strings.Join([]string{path, "14"}, string(os.PathSeparator)))
+			volumeName := volumename.FromPath(strings.Join([]string{path, version}, string(os.PathSeparator)))
 
 			var pathVolume types.Volume
 			switch len(volumes.Volumes) {
@@ -167,7 +197,7 @@ func NewCommand(docker client.CommonAPIClient, output terminal.Outputer) *cobra.
 				pathVolume = volume
 			}
 
-			commands := append([]string{"npm"}, args...)
+			commands := append(toolCommand(tool), args...)
 
 			networkConfig := &network.NetworkingConfig{}
 			if networkID != "" {
@@ -215,7 +245,7 @@ func NewCommand(docker client.CommonAPIClient, output terminal.Outputer) *cobra.
 				return fmt.Errorf("unable to create container\n%w", err)
 			}
 
-			output.Info("Running npm", action)
+			output.Info("Running", tool, action, "with node", version)
 
 			// attach to the container
 			stream, err := docker.ContainerAttach(ctx, resp.ID, types.ContainerAttachOptions{
@@ -239,7 +269,7 @@ func NewCommand(docker client.CommonAPIClient, output terminal.Outputer) *cobra.
 				return fmt.Errorf("unable to copy the output of the container logs, %w", err)
 			}
 
-			output.Info("npm", action, "complete 🤘")
+			output.Info(tool, action, "complete 🤘")
 
 			if err := docker.ContainerRemove(ctx, resp.ID, types.ContainerRemoveOptions{}); err != nil {
 				return err
@@ -250,7 +280,9 @@ func NewCommand(docker client.CommonAPIClient, output terminal.Outputer) *cobra.
 	}
 
 	// set flags for the command
-	cmd.Flags().String("version", "14", "which node version to use")
+	cmd.Flags().String("node-version", "", "which node version to use, defaults to the .nvmrc file or the engines in package.json")
+	cmd.Flags().String("version", "", "which node version to use")
+	cmd.Flags().MarkDeprecated("version", "use --node-version instead")
 
 	return cmd
 }
@@ -0,0 +1,137 @@
+package npm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// DefaultNodeVersion is the node version used when the flags and the project do not set one
+var DefaultNodeVersion = "14"
+
+// ltsVersions maps the nvm lts names to the major node version
+var ltsVersions = map[string]string{
+	"argon":    "4",
+	"boron":    "6",
+	"carbon":   "8",
+	"dubnium":  "10",
+	"erbium":   "12",
+	"fermium":  "14",
+	"gallium":  "16",
+	"hydrogen": "18",
+	"iron":     "20",
+}
+
+var (
+	// versionRegex matches versions that have a node image tag (e.g. 16, 16.13, or 16.13.0)
+	versionRegex = regexp.MustCompile(`^[0-9]+(\.[0-9]+){0,2}$`)
+
+	// comparatorRegex matches the comparators in an engines range (e.g. >=14.0.0 or ^16)
+	comparatorRegex = regexp.MustCompile(`(<=|>=|<|>|=|\^|~)?\s*v?([0-9]+)`)
+)
+
+// toolCommand returns the command to run the package manager in the node image. The node
+// images include npm and yarn, pnpm is run with npx.
+func toolCommand(tool string) []string {
+	if tool == "pnpm" {
+		return []string{"npx", "pnpm"}
+	}
+
+	return []string{tool}
+}
+
+// nodeVersion returns the node version to use for the path. The --node-version flag is used
+// first, then the deprecated --version flag, the .nvmrc file, and the engines in package.json.
+func nodeVersion(cmd *cobra.Command, path string) (string, error) {
+	for _, flag := range []string{"node-version", "version"} {
+		if v := cmd.Flag(flag).Value.String(); v != "" {
+			return normalizeVersion(v)
+		}
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(path, ".nvmrc"))
+	switch {
+	case err == nil:
+		return normalizeVersion(string(b))
+	case !os.IsNotExist(err):
+		return "", fmt.Errorf("unable to read the .nvmrc file, %w", err)
+	}
+
+	b, err = ioutil.ReadFile(filepath.Join(path, "package.json"))
+	if err != nil {
+		return DefaultNodeVersion, nil
+	}
+
+	var pkg struct {
+		Engines struct {
+			Node string `json:"node"`
+		} `json:"engines"`
+	}
+
+	// an invalid package.json is reported by the package manager
+	if err := json.Unmarshal(b, &pkg); err != nil || pkg.Engines.Node == "" {
+		return DefaultNodeVersion, nil
+	}
+
+	if v := engineVersion(pkg.Engines.Node); v != "" {
+		return v, nil
+	}
+
+	return DefaultNodeVersion, nil
+}
+
+// normalizeVersion converts a version from a flag or .nvmrc file (e.g. v16.13.0, lts/gallium,
+// or node) to a node image tag.
+func normalizeVersion(v string) (string, error) {
+	v = strings.ToLower(strings.TrimSpace(v))
+	v = strings.TrimPrefix(v, "v")
+
+	switch {
+	case v == "node" || v == "stable" || v == "current":
+		return "current", nil
+	case v == "lts/*" || v == "lts":
+		return "lts", nil
+	case strings.HasPrefix(v, "lts/"):
+		if major, ok := ltsVersions[strings.TrimPrefix(v, "lts/")]; ok {
+			return major, nil
+		}
+	case versionRegex.MatchString(v):
+		return v, nil
+	}
+
+	return "", fmt.Errorf("unable to use %q as the node version", v)
+}
+
+// engineVersion returns the major node version for the engines range in package.json
+// (e.g. >=14 returns 14 and ^14 || ^16 returns 16). Ranges with only an upper bound
+// return an empty string.
+func engineVersion(r string) string {
+	var highest int
+	for _, set := range strings.Split(r, "||") {
+		for _, m := range comparatorRegex.FindAllStringSubmatch(set, -1) {
+			// only use the lower bound of the set
+			if m[1] == "<" || m[1] == "<=" {
+				continue
+			}
+
+			if major, err := strconv.Atoi(m[2]); err == nil && major > highest {
+				highest = major
+			}
+
+			break
+		}
+	}
+
+	if highest == 0 {
+		return ""
+	}
+
+	return strconv.Itoa(highest)
+}
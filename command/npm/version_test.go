@@ -0,0 +1,98 @@
+package npm
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func Test_nodeVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		flag    string
+		nvmrc   string
+		pkg     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "the flag is used first",
+			flag:  "v16",
+			nvmrc: "12",
+			want:  "16",
+		},
+		{
+			name:  "the nvmrc file is used before package.json",
+			nvmrc: "v16.13.0\n",
+			pkg:   `{"engines": {"node": ">=14"}}`,
+			want:  "16.13.0",
+		},
+		{
+			name:  "lts names in the nvmrc file use the major version",
+			nvmrc: "lts/gallium",
+			want:  "16",
+		},
+		{
+			name:  "the latest lts in the nvmrc file uses the lts image",
+			nvmrc: "lts/*",
+			want:  "lts",
+		},
+		{
+			name: "the engines in package.json are used",
+			pkg:  `{"engines": {"node": ">=14.0.0 <17"}}`,
+			want: "14",
+		},
+		{
+			name: "the highest version in the engines is used",
+			pkg:  `{"engines": {"node": "^14.17.0 || ^16.13.0"}}`,
+			want: "16",
+		},
+		{
+			name: "engines with only an upper bound use the default",
+			pkg:  `{"engines": {"node": "<16"}}`,
+			want: DefaultNodeVersion,
+		},
+		{
+			name: "projects without a version use the default",
+			pkg:  `{"name": "example"}`,
+			want: DefaultNodeVersion,
+		},
+		{
+			name:    "invalid versions return an error",
+			nvmrc:   "lts/unknown",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			if tt.nvmrc != "" {
+				if err := ioutil.WriteFile(filepath.Join(dir, ".nvmrc"), []byte(tt.nvmrc), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if tt.pkg != "" {
+				if err := ioutil.WriteFile(filepath.Join(dir, "package.json"), []byte(tt.pkg), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			cmd := &cobra.Command{}
+			cmd.Flags().String("node-version", tt.flag, "")
+			cmd.Flags().String("version", "", "")
+
+			got, err := nodeVersion(cmd, dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("nodeVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("nodeVersion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
@@ -15,14 +17,18 @@ import (
 	"github.com/craftcms/nitro/pkg/terminal"
 )
 
-const exampleText = `  # run php version
-  nitro php -v
+const exampleText = `  # start an interactive php shell in the site container
+  nitro php
+
+  # run a php script in the current directory
+  nitro php scripts/import.php --dry-run
 
   # view php info
   nitro php -i`
 
 // NewCommand returns the php command which allows users to pass php specific commands to a sites
 // container. Its context aware and will prompt the user for the site if its not in a directory.
+// Without args, it starts the interactive shell (php -a).
 func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:                "php",
@@ -47,7 +53,7 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 			filter.Add("label", containerlabels.Nitro)
 
 			// get a context aware list of sites
-			sites := cfg.ListOfSitesByDirectory(home, wd)
+			sites := sitesForDirectory(cfg, home, wd)
 
 			// create the options for the sites
 			var options []string
@@ -58,27 +64,14 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 			var site config.Site
 			switch len(sites) {
 			case 0:
-				// prompt for the site
-				selected, err := output.Select(cmd.InOrStdin(), "Select a site: ", options)
-				if err != nil {
-					return err
-				}
-
-				// set the site we selected
-				site = sites[selected]
-
-				// add the label to get the site
-				filter.Add("label", containerlabels.Host+"="+sites[selected].Hostname)
+				return fmt.Errorf("there are no sites")
 			case 1:
 				output.Info("connecting to", sites[0].Hostname)
 
 				// set the site we selected
 				site = sites[0]
-
-				// add the label to get the site
-				filter.Add("label", containerlabels.Host+"="+sites[0].Hostname)
 			default:
-				// prompt for the site to ssh into
+				// prompt for the site to run php in
 				selected, err := output.Select(cmd.InOrStdin(), "Select a site: ", options)
 				if err != nil {
 					return err
@@ -86,11 +79,11 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 
 				// set the site we selected
 				site = sites[selected]
-
-				// add the label to get the site
-				filter.Add("label", containerlabels.Host+"="+sites[selected].Hostname)
 			}
 
+			// add the label to get the site
+			filter.Add("label", containerlabels.Host+"="+site.Hostname)
+
 			// find the containers but limited to the site label
 			containers, err := docker.ContainerList(cmd.Context(), types.ContainerListOptions{Filters: filter, All: true})
 			if err != nil {
@@ -113,23 +106,11 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 				}
 			}
 
-			// create the command for running the craft console
-			cmds := []string{"exec", "-it", containers[0].ID}
+			// run php from the matching directory in the container, so relative script paths work
+			dir := workingDir(home, site, wd)
 
-			// get the container path
-			path := site.GetContainerPath()
-			if path != "" {
-				cmds = append(cmds, fmt.Sprintf("%s/%s", path, "php"))
-			} else {
-				cmds = append(cmds, "php")
-			}
-
-			// append the provided args to the command
-			if len(args) == 0 {
-				cmds = append(cmds, "-v")
-			} else {
-				cmds = append(cmds, args...)
-			}
+			// allocate a tty only when there is one, so scripts can be piped
+			cmds := execArgs(containers[0].ID, dir, terminal.IsTerminal(os.Stdin), args)
 
 			// find the docker executable
 			cli, err := exec.LookPath("docker")
@@ -154,3 +135,71 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 
 	return cmd
 }
+
+// sitesForDirectory returns the sites with the current directory in the sites path, so scripts can
+// be run from any directory in a site. Otherwise the context aware list of sites is returned.
+func sitesForDirectory(cfg *config.Config, home, wd string) []config.Site {
+	var sites []config.Site
+	for _, s := range cfg.Sites {
+		if _, ok := relPath(home, s, wd); ok {
+			sites = append(sites, s)
+		}
+	}
+
+	if len(sites) > 0 {
+		return sites
+	}
+
+	return cfg.ListOfSitesByDirectory(home, wd)
+}
+
+// relPath returns the path of the directory relative to the site and true when the directory
+// is in the site.
+func relPath(home string, site config.Site, dir string) (string, bool) {
+	path, err := site.GetAbsPath(home)
+	if err != nil {
+		return "", false
+	}
+
+	rel, err := filepath.Rel(path, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+		return "", false
+	}
+
+	return rel, true
+}
+
+// workingDir returns the directory in the site container that matches the current directory. When
+// the current directory is not in the site, the directory with the craft executable is used.
+func workingDir(home string, site config.Site, wd string) string {
+	rel, ok := relPath(home, site, wd)
+	switch {
+	case !ok:
+		if p := site.GetContainerPath(); p != "" {
+			return "/app/" + p
+		}
+
+		return "/app"
+	case rel == ".":
+		return "/app"
+	}
+
+	return "/app/" + filepath.ToSlash(rel)
+}
+
+// execArgs returns the docker args to run php in the container from the directory. The
+// interactive shell is started when there are no args.
+func execArgs(id, dir string, tty bool, args []string) []string {
+	cmds := []string{"exec", "-i"}
+	if tty {
+		cmds = append(cmds, "-t")
+	}
+
+	cmds = append(cmds, "-w", dir, id, "php")
+
+	if len(args) == 0 {
+		return append(cmds, "-a")
+	}
+
+	return append(cmds, args...)
+}
@@ -0,0 +1,125 @@
+package php
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/craftcms/nitro/pkg/config"
+)
+
+func Test_sitesForDirectory(t *testing.T) {
+	cfg := &config.Config{
+		Sites: []config.Site{
+			{Hostname: "one.nitro", Path: "~/dev/one", Webroot: "web"},
+			{Hostname: "two.nitro", Path: "~/dev/two", Webroot: "web"},
+		},
+	}
+
+	tests := []struct {
+		name string
+		wd   string
+		want []string
+	}{
+		{
+			name: "directories in a site return the site",
+			wd:   "/home/test/dev/one/scripts",
+			want: []string{"one.nitro"},
+		},
+		{
+			name: "the sites directory returns the site",
+			wd:   "/home/test/dev/two",
+			want: []string{"two.nitro"},
+		},
+		{
+			name: "parent directories return the sites in the directory",
+			wd:   "/home/test/dev",
+			want: []string{"one.nitro", "two.nitro"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, s := range sitesForDirectory(cfg, "/home/test", tt.wd) {
+				got = append(got, s.Hostname)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sitesForDirectory() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_workingDir(t *testing.T) {
+	tests := []struct {
+		name string
+		site config.Site
+		wd   string
+		want string
+	}{
+		{
+			name: "the sites directory uses the app directory",
+			site: config.Site{Path: "~/dev/site", Webroot: "web"},
+			wd:   "/home/test/dev/site",
+			want: "/app",
+		},
+		{
+			name: "directories in the site use the same directory in the container",
+			site: config.Site{Path: "~/dev/site", Webroot: "web"},
+			wd:   "/home/test/dev/site/scripts/import",
+			want: "/app/scripts/import",
+		},
+		{
+			name: "directories outside of the site use the directory with the craft executable",
+			site: config.Site{Path: "~/dev/site", Webroot: "app/web"},
+			wd:   "/home/test/dev",
+			want: "/app/app",
+		},
+		{
+			name: "sibling directories are not in the site",
+			site: config.Site{Path: "~/dev/site", Webroot: "web"},
+			wd:   "/home/test/dev/site-two",
+			want: "/app",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := workingDir("/home/test", tt.site, tt.wd); got != tt.want {
+				t.Errorf("workingDir() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_execArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		tty  bool
+		args []string
+		want []string
+	}{
+		{
+			name: "no args start the interactive shell",
+			tty:  true,
+			want: []string{"exec", "-i", "-t", "-w", "/app", "abc", "php", "-a"},
+		},
+		{
+			name: "args are passed to php",
+			tty:  true,
+			args: []string{"scripts/import.php", "--dry-run"},
+			want: []string{"exec", "-i", "-t", "-w", "/app", "abc", "php", "scripts/import.php", "--dry-run"},
+		},
+		{
+			name: "a tty is not allocated without a terminal",
+			args: []string{"-r", "echo 1;"},
+			want: []string{"exec", "-i", "-w", "/app", "abc", "php", "-r", "echo 1;"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := execArgs("abc", "/app", tt.tty, tt.args); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("execArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
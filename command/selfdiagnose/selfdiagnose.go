@@ -0,0 +1,377 @@
+package selfdiagnose
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/craftcms/nitro/command/apply"
+	"github.com/craftcms/nitro/command/version"
+	"github.com/craftcms/nitro/pkg/backup"
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/containerlogs"
+	"github.com/craftcms/nitro/pkg/datetime"
+	"github.com/craftcms/nitro/pkg/helpers"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+var (
+	// ErrNoStorage is returned when the bundle is uploaded without storage for the backups in the config
+	ErrNoStorage = errors.New("uploading the bundle requires backups.storage in the config")
+
+	// hostsFile is the location of the hosts file
+	hostsFile = "/etc/hosts"
+
+	// logLines is the number of lines to include from each containers logs
+	logLines = "200"
+
+	// redacted replaces any secret values in the bundle
+	redacted = "REDACTED"
+
+	// secrets matches credentials in logs (e.g. password=secret or DB_PASSWORD: secret)
+	secrets = regexp.MustCompile(`(?i)((?:password|passwd|_pass\b|secret|token|api_key|apikey|master_key)[a-z_]*\s*[=:]\s*)\S+`)
+
+	// secretKeys matches the names of environment variables with credentials (e.g. BLACKFIRE_SERVER_TOKEN)
+	secretKeys = regexp.MustCompile(`(?i)password|passwd|_pass\b|secret|token|api_?key|access_key|master_key|server_id`)
+)
+
+const exampleText = `  # create a diagnostic bundle to attach to a bug report
+  nitro self-diagnose
+
+  # upload the bundle to the backups storage and show the location to share
+  nitro self-diagnose --upload`
+
+// NewCommand returns the command to create a diagnostic bundle. The bundle is a zip file
+// that includes the CLI version, docker info, nitro containers and their inspect output,
+// recent logs, the output from the last apply, the config with secrets redacted, and the
+// nitro section of the hosts file. With --upload, the bundle is uploaded to the backups
+// storage in the config so the location can be shared.
+func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "self-diagnose",
+		Aliases: []string{"report"},
+		Short:   "Creates a diagnostic bundle for bug reports.",
+		Example: exampleText,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			files := make(map[string][]byte)
+			upload := cmd.Flag("upload").Value.String() == "true"
+
+			output.Info("Collecting diagnostics…")
+
+			// get the cli version
+			files["version.txt"] = []byte(fmt.Sprintf("Nitro CLI: %s\nOS: %s\nArch: %s\n", version.Version, runtime.GOOS, runtime.GOARCH))
+
+			// get the docker info
+			output.Pending("collecting docker info")
+			info, err := docker.Info(ctx)
+			if err != nil {
+				files["docker.txt"] = []byte(fmt.Sprintf("unable to get docker info, %s\n", err))
+			} else {
+				files["docker.json"], _ = json.MarshalIndent(info, "", "  ")
+			}
+			output.Done()
+
+			// get the containers and their logs
+			output.Pending("collecting containers, inspect output, and logs")
+
+			filter := filters.NewArgs()
+			filter.Add("label", containerlabels.Nitro)
+
+			containers, err := docker.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: filter})
+			if err != nil {
+				output.Warning()
+				return err
+			}
+
+			files["containers.json"], _ = json.MarshalIndent(containers, "", "  ")
+
+			for _, c := range containers {
+				name := strings.TrimLeft(c.Names[0], "/")
+
+				// get the inspect output with the credentials removed from the environment
+				details, err := docker.ContainerInspect(ctx, c.ID)
+				if err != nil {
+					files["inspect/"+name+".txt"] = []byte(fmt.Sprintf("unable to inspect the container, %s\n", err))
+				} else {
+					if details.Config != nil {
+						details.Config.Env = RedactEnv(details.Config.Env)
+					}
+
+					files["inspect/"+name+".json"], _ = json.MarshalIndent(details, "", "  ")
+				}
+
+				// only get the logs for the proxy and sites
+				if c.Labels[containerlabels.Proxy] == "" && c.Labels[containerlabels.Host] == "" {
+					continue
+				}
+
+				logs, err := containerlogs.Tail(ctx, docker, c.ID, logLines)
+				if err != nil {
+					files["logs/"+name+".log"] = []byte(fmt.Sprintf("unable to get logs, %s\n", err))
+					continue
+				}
+
+				files["logs/"+name+".log"] = RedactLogs(logs)
+			}
+			output.Done()
+
+			// get the config with secrets removed
+			output.Pending("collecting config")
+			cfg, err := config.Load(home)
+			if err != nil {
+				if upload {
+					output.Warning()
+					return fmt.Errorf("unable to load the backups storage to upload the bundle, %w", err)
+				}
+
+				files["nitro.yaml"] = []byte(fmt.Sprintf("unable to load the config, %s\n", err))
+			} else {
+				files["nitro.yaml"], err = yaml.Marshal(RedactConfig(*cfg))
+				if err != nil {
+					output.Warning()
+					return err
+				}
+			}
+			output.Done()
+
+			// get the output from the last apply
+			if b, err := ioutil.ReadFile(filepath.Join(home, config.DirectoryName, apply.LogFile)); err == nil {
+				files["apply.log"] = RedactLogs(b)
+			} else {
+				files["apply.log"] = []byte("there is no output from apply, run `nitro apply` to recreate the issue\n")
+			}
+
+			// get the nitro section of the hosts file
+			if runtime.GOOS == "windows" {
+				hostsFile = `C:\Windows\System32\Drivers\etc\hosts`
+			}
+
+			hosts, err := ioutil.ReadFile(hostsFile)
+			if err != nil {
+				files["hosts.txt"] = []byte(fmt.Sprintf("unable to read the hosts file, %s\n", err))
+			} else {
+				files["hosts.txt"] = []byte(hostsSection(string(hosts)))
+			}
+
+			// create the bundle in the diagnostics directory
+			dir := filepath.Join(home, config.DirectoryName, "diagnostics")
+			if err := helpers.MkdirIfNotExists(dir); err != nil {
+				return err
+			}
+
+			file := filepath.Join(dir, fmt.Sprintf("nitro-diagnostics-%s.zip", datetime.Parse(time.Now())))
+
+			f, err := os.Create(file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			if err := Bundle(f, files); err != nil {
+				return fmt.Errorf("unable to create the diagnostic bundle, %w", err)
+			}
+
+			if err := f.Close(); err != nil {
+				return fmt.Errorf("unable to create the diagnostic bundle, %w", err)
+			}
+
+			output.Info("Diagnostics saved to", file, "📦")
+
+			if !upload {
+				output.Info("Review the bundle before attaching it to an issue at https://github.com/craftcms/nitro/issues")
+
+				return nil
+			}
+
+			if cfg.Backups.Storage == nil {
+				return ErrNoStorage
+			}
+
+			storage, err := backup.NewStorage(*cfg.Backups.Storage)
+			if err != nil {
+				return err
+			}
+
+			output.Pending("uploading", filepath.Base(file))
+
+			key, err := Upload(ctx, storage, cfg.Backups.Storage.Prefix, file)
+			if err != nil {
+				output.Warning()
+				return err
+			}
+
+			output.Done()
+
+			output.Info("Diagnostics uploaded to", storage.URL(key), "🔗")
+			output.Info("Share the location with anyone that has access to the bucket")
+
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("upload", false, "upload the bundle to the backups storage and show the location to share")
+
+	return cmd
+}
+
+// Storage is the bucket the bundle is uploaded to.
+type Storage interface {
+	Put(ctx context.Context, key string, content io.ReadSeeker) error
+}
+
+// Upload uploads the bundle to the diagnostics folder in the storage (e.g.
+// team/diagnostics/nitro-diagnostics-2021-03-04-103000.zip) and returns the key.
+func Upload(ctx context.Context, storage Storage, prefix, file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	key := path.Join(strings.Trim(prefix, "/"), "diagnostics", filepath.Base(file))
+	if err := storage.Put(ctx, key, f); err != nil {
+		return "", fmt.Errorf("unable to upload the diagnostic bundle, %w", err)
+	}
+
+	return key, nil
+}
+
+// Bundle writes the files to a zip archive in a consistent order.
+func Bundle(w io.Writer, files map[string][]byte) error {
+	var names []string
+	for n := range files {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	zw := zip.NewWriter(w)
+	for _, n := range names {
+		f, err := zw.Create(n)
+		if err != nil {
+			return err
+		}
+
+		if _, err := f.Write(files[n]); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// RedactConfig returns a copy of the config with the credentials replaced. The values of the
+// site and container environment variables are replaced since any of them could be a secret.
+func RedactConfig(cfg config.Config) config.Config {
+	redact(&cfg.Blackfire.ServerID)
+	redact(&cfg.Blackfire.ServerToken)
+	redact(&cfg.Services.MinioPassword)
+	redact(&cfg.Services.MeilisearchMasterKey)
+
+	if cfg.Backups.Storage != nil {
+		storage := *cfg.Backups.Storage
+		redact(&storage.AccessKey)
+		redact(&storage.SecretKey)
+		cfg.Backups.Storage = &storage
+	}
+
+	sites := make([]config.Site, len(cfg.Sites))
+	for i, s := range cfg.Sites {
+		if s.BasicAuth != nil {
+			auth := *s.BasicAuth
+			redact(&auth.Password)
+			s.BasicAuth = &auth
+		}
+
+		if s.Remote != nil {
+			remote := *s.Remote
+			redact(&remote.DatabasePassword)
+			s.Remote = &remote
+		}
+
+		s.Env = redactMap(s.Env)
+		sites[i] = s
+	}
+	cfg.Sites = sites
+
+	containers := make([]config.Container, len(cfg.Containers))
+	for i, c := range cfg.Containers {
+		c.Env = redactMap(c.Env)
+		containers[i] = c
+	}
+	cfg.Containers = containers
+
+	return cfg
+}
+
+// redact replaces the value with redacted, empty values are not changed.
+func redact(v *string) {
+	if *v != "" {
+		*v = redacted
+	}
+}
+
+// redactMap returns a copy of the map with every value redacted.
+func redactMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+
+	r := make(map[string]string, len(m))
+	for k, v := range m {
+		redact(&v)
+		r[k] = v
+	}
+
+	return r
+}
+
+// RedactEnv returns a copy of the environment variables with the values of credentials replaced.
+func RedactEnv(env []string) []string {
+	var r []string
+	for _, e := range env {
+		if sp := strings.SplitN(e, "=", 2); len(sp) == 2 && secretKeys.MatchString(sp[0]) {
+			e = sp[0] + "=" + redacted
+		}
+
+		r = append(r, e)
+	}
+
+	return r
+}
+
+// RedactLogs replaces credentials in log output.
+func RedactLogs(logs []byte) []byte {
+	return secrets.ReplaceAll(logs, []byte("${1}"+redacted))
+}
+
+// hostsSection returns the nitro section of the hosts file.
+func hostsSection(hosts string) string {
+	start := strings.Index(hosts, "# <nitro>")
+	end := strings.Index(hosts, "# </nitro>")
+
+	if start == -1 || end == -1 || end < start {
+		return "there is no nitro section in the hosts file\n"
+	}
+
+	return hosts[start:end+len("# </nitro>")] + "\n"
+}
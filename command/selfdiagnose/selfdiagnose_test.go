@@ -0,0 +1,281 @@
+package selfdiagnose
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/craftcms/nitro/pkg/config"
+)
+
+func TestBundle(t *testing.T) {
+	files := map[string][]byte{
+		"version.txt":             []byte("Nitro CLI: 2.0.0"),
+		"logs/tutorial.nitro.log": []byte("log"),
+		"hosts.txt":               []byte("# <nitro>\n# </nitro>"),
+	}
+
+	buf := &bytes.Buffer{}
+	if err := Bundle(buf, files); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, f := range r.File {
+		names = append(names, f.Name)
+
+		rdr, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		content, err := ioutil.ReadAll(rdr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rdr.Close()
+
+		if !bytes.Equal(content, files[f.Name]) {
+			t.Errorf("expected the content of %s to be %q, got %q", f.Name, files[f.Name], content)
+		}
+	}
+
+	if want := []string{"hosts.txt", "logs/tutorial.nitro.log", "version.txt"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("expected the files to be %v, got %v", want, names)
+	}
+}
+
+func TestRedactConfig(t *testing.T) {
+	cfg := config.Config{
+		Blackfire: config.Blackfire{
+			ServerID:    "my-server-id",
+			ServerToken: "my-server-token",
+		},
+		Services: config.Services{
+			MinioPassword:        "minio-password",
+			MeilisearchMasterKey: "master-key",
+		},
+		Backups: config.Backups{
+			Storage: &config.Storage{Bucket: "backups", AccessKey: "access-key", SecretKey: "secret-key"},
+		},
+		Sites: []config.Site{{
+			Hostname:  "tutorial.nitro",
+			BasicAuth: &config.BasicAuth{User: "nitro", Password: "basic-password"},
+			Remote:    &config.Remote{Host: "example.com", Database: "craft", DatabasePassword: "remote-password"},
+			Env:       map[string]string{"STRIPE_KEY": "sk_test"},
+		}},
+		Containers: []config.Container{{Name: "mailpit", Env: map[string]string{"API_TOKEN": "container-token"}}},
+	}
+
+	got := RedactConfig(cfg)
+
+	if got.Blackfire.ServerID != "REDACTED" || got.Blackfire.ServerToken != "REDACTED" {
+		t.Errorf("expected the blackfire credentials to be redacted, got %v", got.Blackfire)
+	}
+
+	if cfg.Blackfire.ServerID != "my-server-id" {
+		t.Errorf("expected the original config to not be modified, got %v", cfg.Blackfire)
+	}
+
+	if got.Sites[0].Hostname != "tutorial.nitro" {
+		t.Errorf("expected the sites to be kept, got %v", got.Sites)
+	}
+
+	secrets := map[string]string{
+		"minio password":           got.Services.MinioPassword,
+		"meilisearch master key":   got.Services.MeilisearchMasterKey,
+		"storage access key":       got.Backups.Storage.AccessKey,
+		"storage secret key":       got.Backups.Storage.SecretKey,
+		"basic auth password":      got.Sites[0].BasicAuth.Password,
+		"remote database password": got.Sites[0].Remote.DatabasePassword,
+		"site env value":           got.Sites[0].Env["STRIPE_KEY"],
+		"container env value":      got.Containers[0].Env["API_TOKEN"],
+	}
+	for name, v := range secrets {
+		if v != "REDACTED" {
+			t.Errorf("expected the %s to be redacted, got %q", name, v)
+		}
+	}
+
+	if got.Sites[0].BasicAuth.User != "nitro" || got.Backups.Storage.Bucket != "backups" {
+		t.Errorf("expected the settings that are not credentials to be kept, got %v and %v", got.Sites[0].BasicAuth, got.Backups.Storage)
+	}
+
+	if cfg.Sites[0].BasicAuth.Password != "basic-password" || cfg.Sites[0].Env["STRIPE_KEY"] != "sk_test" || cfg.Backups.Storage.SecretKey != "secret-key" {
+		t.Errorf("expected the original sites and storage to not be modified")
+	}
+
+	// empty credentials are not added
+	if got := RedactConfig(config.Config{}); got.Blackfire.ServerID != "" || got.Blackfire.ServerToken != "" {
+		t.Errorf("expected empty credentials to stay empty, got %v", got.Blackfire)
+	}
+}
+
+func TestRedactLogs(t *testing.T) {
+	tests := []struct {
+		name string
+		logs string
+		want string
+	}{
+		{
+			name: "env vars are redacted",
+			logs: "CRAFT_DB_PASSWORD=nitro\nCRAFT_DB_USER=nitro",
+			want: "CRAFT_DB_PASSWORD=REDACTED\nCRAFT_DB_USER=nitro",
+		},
+		{
+			name: "key value pairs are redacted",
+			logs: "connecting with password: hunter2 to mysql",
+			want: "connecting with password: REDACTED to mysql",
+		},
+		{
+			name: "tokens and secrets are redacted regardless of case",
+			logs: "API_KEY=abc SECRET_KEY=def Token=ghi",
+			want: "API_KEY=REDACTED SECRET_KEY=REDACTED Token=REDACTED",
+		},
+		{
+			name: "lines without credentials are not changed",
+			logs: "[notice] ready to handle connections",
+			want: "[notice] ready to handle connections",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(RedactLogs([]byte(tt.logs))); got != tt.want {
+				t.Errorf("RedactLogs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactEnv(t *testing.T) {
+	env := []string{
+		"PHP_MEMORY_LIMIT=256M",
+		"MYSQL_ROOT_PASSWORD=nitro",
+		"BLACKFIRE_SERVER_ID=my-server-id",
+		"BLACKFIRE_SERVER_TOKEN=my server token",
+		"MINIO_ROOT_USER=nitro",
+		"MEILI_MASTER_KEY=master-key",
+		"MEILISEARCH_MASTER_KEY=master-key",
+		"RABBITMQ_DEFAULT_PASS=guest",
+		"RABBITMQ_DEFAULT_USER=guest",
+	}
+
+	want := []string{
+		"PHP_MEMORY_LIMIT=256M",
+		"MYSQL_ROOT_PASSWORD=REDACTED",
+		"BLACKFIRE_SERVER_ID=REDACTED",
+		"BLACKFIRE_SERVER_TOKEN=REDACTED",
+		"MINIO_ROOT_USER=nitro",
+		"MEILI_MASTER_KEY=REDACTED",
+		"MEILISEARCH_MASTER_KEY=REDACTED",
+		"RABBITMQ_DEFAULT_PASS=REDACTED",
+		"RABBITMQ_DEFAULT_USER=guest",
+	}
+
+	if got := RedactEnv(env); !reflect.DeepEqual(got, want) {
+		t.Errorf("RedactEnv() = %v, want %v", got, want)
+	}
+
+	if env[1] != "MYSQL_ROOT_PASSWORD=nitro" {
+		t.Errorf("expected the original environment to not be modified, got %v", env)
+	}
+}
+
+func Test_hostsSection(t *testing.T) {
+	tests := []struct {
+		name  string
+		hosts string
+		want  string
+	}{
+		{
+			name:  "returns only the nitro section",
+			hosts: "127.0.0.1 localhost\n# <nitro>\n127.0.0.1\ttutorial.nitro\n# </nitro>\n::1 localhost\n",
+			want:  "# <nitro>\n127.0.0.1\ttutorial.nitro\n# </nitro>\n",
+		},
+		{
+			name:  "missing sections are reported",
+			hosts: "127.0.0.1 localhost\n",
+			want:  "there is no nitro section in the hosts file\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hostsSection(tt.hosts); got != tt.want {
+				t.Errorf("hostsSection() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+type spyStorage struct {
+	puts map[string][]byte
+}
+
+func (s *spyStorage) Put(ctx context.Context, key string, content io.ReadSeeker) error {
+	b, err := ioutil.ReadAll(content)
+	if err != nil {
+		return err
+	}
+
+	s.puts[key] = b
+
+	return nil
+}
+
+func TestUpload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nitro-diagnostics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "nitro-diagnostics-2021-03-04-103000.zip")
+	if err := ioutil.WriteFile(file, []byte("bundle"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		prefix string
+		want   string
+	}{
+		{
+			name: "bundles are uploaded to the diagnostics folder",
+			want: "diagnostics/nitro-diagnostics-2021-03-04-103000.zip",
+		},
+		{
+			name:   "the storage prefix is used",
+			prefix: "/team/",
+			want:   "team/diagnostics/nitro-diagnostics-2021-03-04-103000.zip",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := &spyStorage{puts: make(map[string][]byte)}
+
+			key, err := Upload(context.Background(), storage, tt.prefix, file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if key != tt.want {
+				t.Errorf("expected the key to be %q, got %q", tt.want, key)
+			}
+
+			if string(storage.puts[tt.want]) != "bundle" {
+				t.Errorf("expected the bundle to be uploaded to %q, got %v", tt.want, storage.puts)
+			}
+		})
+	}
+}
@@ -3,11 +3,15 @@ package selfupdate
 import (
 	"archive/tar"
 	"archive/zip"
+	"bytes"
 	"compress/gzip"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
+	"os/exec"
 	"runtime"
+	"strings"
 
 	"github.com/minio/selfupdate"
 	"github.com/spf13/cobra"
@@ -28,8 +32,11 @@ var (
 	ReleasesURL = "https://api.github.com/repos/craftcms/nitro/releases"
 )
 
-const exampleText = `  # update to the latest version of the nitro CLI
-  nitro self-update`
+const exampleText = `  # update to the latest version of the nitro CLI and images
+  nitro self-update
+
+  # update only the nitro CLI
+  nitro self-update --skip-images`
 
 // NewCommand is used to help update a nitro cli using the latest version.
 func NewCommand(output terminal.Outputer) *cobra.Command {
@@ -51,121 +58,74 @@ func NewCommand(output terminal.Outputer) *cobra.Command {
 				return err
 			}
 
-			// make sure the versions do not match
-			if release.Version == version.Version {
+			// make sure the versions do not match, the tags have a v prefix
+			if strings.TrimPrefix(release.Version, "v") == strings.TrimPrefix(version.Version, "v") {
 				output.Info("up to date!")
 				return nil
 			}
 
-			output.Pending("found version", release.Version, "updating")
+			output.Pending("downloading version", release.Version)
 
 			// create a temp file to save the release into
 			file, err := ioutil.TempFile(os.TempDir(), "nitro-release-download-")
 			if err != nil {
+				output.Warning()
 				return err
 			}
+			defer os.Remove(file.Name())
 			defer file.Close()
 
 			// download the release
 			if err := releases.NewDownloader().Download(release.URL, file.Name()); err != nil {
+				output.Warning()
+				return fmt.Errorf("unable to download the release, %w", err)
+			}
+
+			output.Done()
+
+			output.Pending("verifying checksum")
+
+			if err := verify(release, file.Name()); err != nil {
+				output.Warning()
 				return err
 			}
 
-			switch release.ContentType {
-			case "application/gzip":
-				file, err := os.Open(file.Name())
-				if err != nil {
-					return err
-				}
-				defer file.Close()
-
-				gz, err := gzip.NewReader(file)
-				if err != nil {
-					return err
-				}
-				defer gz.Close()
-
-				// untar the zip
-				tr := tar.NewReader(gz)
-
-				i := 0
-				for {
-					header, err := tr.Next()
-
-					if err == io.EOF {
-						break
-					}
-
-					if err != nil {
-						return err
-					}
-
-					switch header.Typeflag {
-					case tar.TypeDir:
-						continue
-					case tar.TypeReg:
-						name := header.Name
-
-						switch release.OperatingSystem {
-						case "windows":
-							if name == "nitro.exe" {
-								output.Done()
-
-								output.Info("Updating to Nitro", release.Version+"!")
-
-								// self update
-								if err := selfupdate.Apply(tr, selfupdate.Options{}); err != nil {
-									return err
-								}
-
-								break
-							}
-						default:
-							if name == "nitro" {
-								output.Done()
-
-								output.Info("Updating to Nitro", release.Version+"!")
-
-								// self update
-								if err := selfupdate.Apply(tr, selfupdate.Options{}); err != nil {
-									return err
-								}
-
-								break
-							}
-						}
-					}
-
-					i++
-				}
-			case "application/zip":
-				// unzip
-				zr, err := zip.OpenReader(file.Name())
-				if err != nil {
-					return err
-				}
-
-				for _, file := range zr.Reader.File {
-					switch release.OperatingSystem {
-					case "windows":
-						if file.Name == "nitro.exe" {
-							output.Done()
-
-							output.Info("Updated to Nitro", release.Version+"!")
-
-							// read the file
-							f, err := os.Open(file.FileInfo().Name())
-							if err != nil {
-								return err
-							}
-
-							// self update
-							if err := selfupdate.Apply(f, selfupdate.Options{}); err != nil {
-								return err
-							}
-						}
-					}
-				}
+			output.Done()
+
+			binary := "nitro"
+			if release.OperatingSystem == "windows" {
+				binary = "nitro.exe"
+			}
+
+			b, err := extract(file.Name(), release.Name, binary)
+			if err != nil {
+				return err
+			}
+
+			output.Info("Updating to Nitro", release.Version+"!")
+
+			// replace the running executable
+			if err := selfupdate.Apply(bytes.NewReader(b), selfupdate.Options{}); err != nil {
+				return fmt.Errorf("unable to replace the nitro executable, %w", err)
+			}
+
+			if cmd.Flag("skip-images").Value.String() == "true" {
+				return nil
+			}
+
+			// the new version updates the images so they match the new version
+			exe, err := os.Executable()
+			if err != nil {
+				return err
+			}
+
+			update := exec.Command(exe, "update")
+			update.Stdin = os.Stdin
+			update.Stdout = cmd.OutOrStdout()
+			update.Stderr = cmd.ErrOrStderr()
+
+			if err := update.Run(); err != nil {
+				output.Info("Warning: unable to update the images…\n run `nitro update` to update the images")
 			}
 
 			return nil
@@ -173,6 +133,93 @@ func NewCommand(output terminal.Outputer) *cobra.Command {
 	}
 
 	cmd.Flags().BoolVar(&DevRelease, "dev", false, "install the latest development release")
+	cmd.Flags().Bool("skip-images", false, "skip updating the images after updating")
 
 	return cmd
 }
+
+// verify downloads the checksums for the release and verifies the downloaded file.
+func verify(release *releases.Release, file string) error {
+	if release.ChecksumURL == "" {
+		return fmt.Errorf("unable to find the checksums for %s", release.Version)
+	}
+
+	sums, err := ioutil.TempFile(os.TempDir(), "nitro-release-checksums-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(sums.Name())
+	defer sums.Close()
+
+	if err := releases.NewDownloader().Download(release.ChecksumURL, sums.Name()); err != nil {
+		return fmt.Errorf("unable to download the checksums, %w", err)
+	}
+
+	b, err := ioutil.ReadFile(sums.Name())
+	if err != nil {
+		return err
+	}
+
+	checksum, err := releases.Checksum(b, release.Name)
+	if err != nil {
+		return err
+	}
+
+	return releases.Verify(file, checksum)
+}
+
+// extract returns the binary from the release archive, the archive is a tar.gz or zip file.
+func extract(file, name, binary string) ([]byte, error) {
+	if strings.HasSuffix(name, ".zip") {
+		zr, err := zip.OpenReader(file)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+
+		for _, f := range zr.File {
+			if f.Name != binary {
+				continue
+			}
+
+			rc, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer rc.Close()
+
+			return ioutil.ReadAll(rc)
+		}
+
+		return nil, fmt.Errorf("unable to find %s in the release", binary)
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if header.Typeflag == tar.TypeReg && header.Name == binary {
+			return ioutil.ReadAll(tr)
+		}
+	}
+
+	return nil, fmt.Errorf("unable to find %s in the release", binary)
+}
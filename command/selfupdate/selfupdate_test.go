@@ -0,0 +1,93 @@
+package selfupdate
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_extract(t *testing.T) {
+	dir := t.TempDir()
+
+	// create a tar.gz release
+	tgz := filepath.Join(dir, "nitro_linux_x86_64.tar.gz")
+	f, err := os.Create(tgz)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for name, content := range map[string]string{"README.md": "readme", "nitro": "binary"} {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tw.Close()
+	gz.Close()
+	f.Close()
+
+	// create a zip release
+	zipFile := filepath.Join(dir, "nitro_windows_x86_64.zip")
+	z, err := os.Create(zipFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zw := zip.NewWriter(z)
+	w, err := zw.Create("nitro.exe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("windows binary")); err != nil {
+		t.Fatal(err)
+	}
+	zw.Close()
+	z.Close()
+
+	type args struct {
+		file   string
+		name   string
+		binary string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "binaries are extracted from tar.gz releases",
+			args: args{file: tgz, name: "nitro_linux_x86_64.tar.gz", binary: "nitro"},
+			want: "binary",
+		},
+		{
+			name: "binaries are extracted from zip releases",
+			args: args{file: zipFile, name: "nitro_windows_x86_64.zip", binary: "nitro.exe"},
+			want: "windows binary",
+		},
+		{
+			name:    "missing binaries return an error",
+			args:    args{file: tgz, name: "nitro_linux_x86_64.tar.gz", binary: "nitro.exe"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extract(tt.args.file, tt.args.name, tt.args.binary)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("extract() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if string(got) != tt.want {
+				t.Errorf("extract() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,9 +1,11 @@
 package share
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
+	"os/signal"
 	"strings"
 
 	"github.com/docker/docker/api/types"
@@ -21,16 +23,19 @@ var (
 	execName = "ngrok"
 )
 
-const exampleText = `  # share a local site with ngrok
-  nitro share`
+const exampleText = `  # share a local site with a public url
+  nitro share tutorial.nitro
 
-// NewCommand is used to destroy all resources for an environment. It will prompt for
-// user verification and defaults to no. Part of the destroy process is to
-// perform a backup for all databases in each container database.
+  # share a local site with ngrok
+  nitro share --ngrok`
+
+// NewCommand is used to share a local site with a public URL. By default a tunnel
+// container is started on the nitro network that forwards requests through the
+// proxy, the --ngrok flag uses a local ngrok executable instead.
 func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "share",
-		Short:   "Shares a local site.",
+		Use:     "share SITE",
+		Short:   "Shares a local site with a public URL.",
 		Example: exampleText,
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 			cfg, err := config.Load(home)
@@ -48,19 +53,6 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 
-			// find ngrok
-			ngrok, err := exec.LookPath(execName)
-			if err != nil {
-				return err
-			}
-
-			// if ngrok is missing, return the error
-			if ngrok == "" {
-				output.Info("Ngrok is required to share sites, download ngrok from https://ngrok.com")
-
-				return nil
-			}
-
 			// get the current working directory
 			wd, err := os.Getwd()
 			if err != nil {
@@ -158,44 +150,93 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 				}
 			}
 
-			// parse the flags
-
-			ngrokArgs := []string{"http"}
-
-			// set the main hostname
-			ngrokArgs = append(ngrokArgs, "--host-header="+site.Hostname)
-
-			// append the aliases
-			for _, a := range site.Aliases {
-				ngrokArgs = append(ngrokArgs, "--host-header="+a)
+			if cmd.Flag("ngrok").Value.String() == "true" {
+				return shareWithNgrok(cmd, site, output)
 			}
 
-			// set the region
-			region, err := cmd.Flags().GetString("region")
+			output.Pending("starting tunnel for", site.Hostname)
+
+			id, err := startTunnel(ctx, docker, output, site)
 			if err != nil {
-				region = "us"
+				output.Warning()
+				return err
 			}
-			ngrokArgs = append(ngrokArgs, "--region="+region)
 
-			// set the port
-			port, err := cmd.Flags().GetString("port")
+			// always remove the tunnel, even if the url is never reported
+			defer func() {
+				if err := docker.ContainerRemove(context.Background(), id, types.ContainerRemoveOptions{Force: true}); err != nil {
+					output.Info("Warning:", "unable to remove the tunnel container,", err.Error())
+				}
+			}()
+
+			url, err := tunnelURL(ctx, docker, id)
 			if err != nil {
-				port = "80"
+				output.Warning()
+				return err
 			}
-			ngrokArgs = append(ngrokArgs, port)
 
-			c := exec.Command(ngrok, ngrokArgs...)
+			output.Done()
+
+			output.Info(fmt.Sprintf("%s is shared at %s 🚀", site.Hostname, url))
+			output.Info("Press ctrl+c to stop sharing…")
 
-			c.Stderr = cmd.ErrOrStderr()
-			c.Stdout = cmd.OutOrStdout()
+			ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+			defer stop()
 
-			return c.Run()
+			<-ctx.Done()
+
+			output.Info("Stopping the tunnel for", site.Hostname+"…")
+
+			return nil
 		},
 	}
 
 	// add flags to the command
+	cmd.Flags().Bool("ngrok", false, "share the site with a local ngrok executable")
 	cmd.Flags().String("region", "us", "which ngrok region to use for sharing")
 	cmd.Flags().String("port", "80", "which port to use for ngrok")
 
 	return cmd
 }
+
+// shareWithNgrok shares the site using a local ngrok executable.
+func shareWithNgrok(cmd *cobra.Command, site config.Site, output terminal.Outputer) error {
+	// find ngrok
+	ngrok, err := exec.LookPath(execName)
+	if err != nil {
+		output.Info("Ngrok is required to share sites, download ngrok from https://ngrok.com")
+
+		return err
+	}
+
+	ngrokArgs := []string{"http"}
+
+	// set the main hostname
+	ngrokArgs = append(ngrokArgs, "--host-header="+site.Hostname)
+
+	// append the aliases
+	for _, a := range site.Aliases {
+		ngrokArgs = append(ngrokArgs, "--host-header="+a)
+	}
+
+	// set the region
+	region, err := cmd.Flags().GetString("region")
+	if err != nil {
+		region = "us"
+	}
+	ngrokArgs = append(ngrokArgs, "--region="+region)
+
+	// set the port
+	port, err := cmd.Flags().GetString("port")
+	if err != nil {
+		port = "80"
+	}
+	ngrokArgs = append(ngrokArgs, port)
+
+	c := exec.Command(ngrok, ngrokArgs...)
+
+	c.Stderr = cmd.ErrOrStderr()
+	c.Stdout = cmd.OutOrStdout()
+
+	return c.Run()
+}
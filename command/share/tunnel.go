@@ -0,0 +1,183 @@
+package share
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/proxycontainer"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+var (
+	// TunnelImage is the image used for the tunnel container. Quick tunnels
+	// do not require an account.
+	TunnelImage = "docker.io/cloudflare/cloudflared:2021.9.2"
+
+	// ErrNoTunnelURL is returned when the tunnel container does not report
+	// a public URL before the timeout.
+	ErrNoTunnelURL = errors.New("unable to find the public url for the tunnel")
+
+	// tunnelTimeout is how long to wait for the tunnel to report a public URL
+	tunnelTimeout = 30 * time.Second
+
+	// tunnelInterval is how often the tunnel logs are checked for a public URL
+	tunnelInterval = 500 * time.Millisecond
+
+	tunnelURLRegex = regexp.MustCompile(`https://[a-z0-9-]+\.trycloudflare\.com`)
+)
+
+// tunnelName returns the name of the tunnel container for a site.
+func tunnelName(site config.Site) string {
+	return "share-" + site.Hostname
+}
+
+// startTunnel creates and starts a tunnel container on the nitro network that
+// forwards requests to the proxy with the sites hostname. It returns the ID of
+// the tunnel container.
+func startTunnel(ctx context.Context, docker client.CommonAPIClient, output terminal.Outputer, site config.Site) (string, error) {
+	networkFilter := filters.NewArgs()
+	networkFilter.Add("name", "nitro-network")
+
+	networks, err := docker.NetworkList(ctx, types.NetworkListOptions{Filters: networkFilter})
+	if err != nil {
+		return "", fmt.Errorf("unable to list the docker networks, %w", err)
+	}
+
+	var networkID string
+	for _, n := range networks {
+		if n.Name == "nitro-network" || strings.TrimLeft(n.Name, "/") == "nitro-network" {
+			networkID = n.ID
+		}
+	}
+
+	if networkID == "" {
+		output.Info("unable to find the nitro network…\n run `nitro apply` to resolve")
+
+		return "", fmt.Errorf("unable to find the nitro network")
+	}
+
+	filter := filters.NewArgs()
+	filter.Add("reference", TunnelImage)
+
+	images, err := docker.ImageList(ctx, types.ImageListOptions{Filters: filter})
+	if err != nil {
+		return "", fmt.Errorf("unable to get a list of images, %w", err)
+	}
+
+	// if we don't have the image, pull it
+	if len(images) == 0 {
+		output.Pending("pulling", TunnelImage)
+
+		rdr, err := docker.ImagePull(ctx, TunnelImage, types.ImagePullOptions{All: false})
+		if err != nil {
+			output.Warning()
+			return "", fmt.Errorf("unable to pull docker image, %w", err)
+		}
+
+		buf := &bytes.Buffer{}
+		if _, err := buf.ReadFrom(rdr); err != nil {
+			output.Warning()
+			return "", fmt.Errorf("unable to read the output from pulling the image, %w", err)
+		}
+
+		output.Done()
+	}
+
+	// remove a tunnel left behind by a previous share
+	shareFilter := filters.NewArgs()
+	shareFilter.Add("label", containerlabels.Share+"="+site.Hostname)
+
+	containers, err := docker.ContainerList(ctx, types.ContainerListOptions{Filters: shareFilter, All: true})
+	if err != nil {
+		return "", fmt.Errorf("unable to list the containers, %w", err)
+	}
+
+	for _, c := range containers {
+		if err := docker.ContainerRemove(ctx, c.ID, types.ContainerRemoveOptions{Force: true}); err != nil {
+			return "", fmt.Errorf("unable to remove the previous tunnel container, %w", err)
+		}
+	}
+
+	resp, err := docker.ContainerCreate(ctx,
+		&container.Config{
+			Image: TunnelImage,
+			Cmd: []string{
+				"tunnel",
+				"--no-autoupdate",
+				"--url", "http://" + proxycontainer.ProxyName + ":80",
+				"--http-host-header", site.Hostname,
+			},
+			Labels: map[string]string{
+				containerlabels.Nitro: "true",
+				containerlabels.Type:  "share",
+				containerlabels.Share: site.Hostname,
+			},
+		},
+		&container.HostConfig{},
+		&network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{
+				"nitro-network": {
+					NetworkID: networkID,
+				},
+			},
+		},
+		nil,
+		tunnelName(site))
+	if err != nil {
+		return "", fmt.Errorf("unable to create the tunnel container, %w", err)
+	}
+
+	if err := docker.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
+		return "", fmt.Errorf("unable to start the tunnel container, %w", err)
+	}
+
+	return resp.ID, nil
+}
+
+// tunnelURL checks the logs of the tunnel container until the public URL is
+// reported or the timeout is reached.
+func tunnelURL(ctx context.Context, docker client.ContainerAPIClient, id string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, tunnelTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(tunnelInterval)
+	defer ticker.Stop()
+
+	for {
+		rdr, err := docker.ContainerLogs(ctx, id, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true})
+		if err != nil {
+			return "", fmt.Errorf("unable to get the tunnel container logs, %w", err)
+		}
+
+		buf := &bytes.Buffer{}
+		_, err = stdcopy.StdCopy(buf, buf, rdr)
+		rdr.Close()
+		if err != nil {
+			return "", fmt.Errorf("unable to read the tunnel container logs, %w", err)
+		}
+
+		if url := tunnelURLRegex.FindString(buf.String()); url != "" {
+			return url, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ErrNoTunnelURL
+		case <-ticker.C:
+		}
+	}
+}
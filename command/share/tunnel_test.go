@@ -0,0 +1,146 @@
+package share
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+type mockDockerClient struct {
+	client.CommonAPIClient
+
+	logs       []string
+	containers []types.Container
+
+	removed         []string
+	containerConfig *container.Config
+	name            string
+}
+
+func (m *mockDockerClient) NetworkList(ctx context.Context, options types.NetworkListOptions) ([]types.NetworkResource, error) {
+	return []types.NetworkResource{{ID: "network-id", Name: "nitro-network"}}, nil
+}
+
+func (m *mockDockerClient) ImageList(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error) {
+	return []types.ImageSummary{{ID: "image-id"}}, nil
+}
+
+func (m *mockDockerClient) ContainerList(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error) {
+	return m.containers, nil
+}
+
+func (m *mockDockerClient) ContainerRemove(ctx context.Context, container string, options types.ContainerRemoveOptions) error {
+	m.removed = append(m.removed, container)
+	return nil
+}
+
+func (m *mockDockerClient) ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.ContainerCreateCreatedBody, error) {
+	m.containerConfig = config
+	m.name = containerName
+	return container.ContainerCreateCreatedBody{ID: "tunnel-id"}, nil
+}
+
+func (m *mockDockerClient) ContainerStart(ctx context.Context, container string, options types.ContainerStartOptions) error {
+	return nil
+}
+
+func (m *mockDockerClient) ContainerLogs(ctx context.Context, container string, options types.ContainerLogsOptions) (io.ReadCloser, error) {
+	buf := &bytes.Buffer{}
+	w := stdcopy.NewStdWriter(buf, stdcopy.Stderr)
+
+	// each call returns one more line of the logs
+	if len(m.logs) > 0 {
+		if _, err := w.Write([]byte(m.logs[0] + "\n")); err != nil {
+			return nil, err
+		}
+		m.logs = m.logs[1:]
+	}
+
+	return io.NopCloser(buf), nil
+}
+
+func Test_tunnelURL(t *testing.T) {
+	tunnelTimeout = 100 * time.Millisecond
+	tunnelInterval = time.Millisecond
+
+	tests := []struct {
+		name    string
+		logs    []string
+		want    string
+		wantErr error
+	}{
+		{
+			name: "returns the url once it is in the logs",
+			logs: []string{
+				"INF Requesting new quick Tunnel on trycloudflare.com...",
+				"INF |  https://plain-river-example.trycloudflare.com  |",
+			},
+			want: "https://plain-river-example.trycloudflare.com",
+		},
+		{
+			name:    "returns an error when the url is never reported",
+			logs:    []string{"ERR failed to request quick Tunnel"},
+			wantErr: ErrNoTunnelURL,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockDockerClient{logs: tt.logs}
+
+			got, err := tunnelURL(context.Background(), mock, "tunnel-id")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("tunnelURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("tunnelURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_startTunnel(t *testing.T) {
+	mock := &mockDockerClient{containers: []types.Container{{ID: "stale-id"}}}
+	output := terminal.NewRenderer(&bytes.Buffer{}, false).Worker()
+
+	id, err := startTunnel(context.Background(), mock, output, config.Site{Hostname: "tutorial.nitro"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id != "tunnel-id" {
+		t.Errorf("expected the id to be tunnel-id, got %s", id)
+	}
+
+	if !reflect.DeepEqual(mock.removed, []string{"stale-id"}) {
+		t.Errorf("expected the stale tunnel to be removed, got %v", mock.removed)
+	}
+
+	if mock.name != "share-tutorial.nitro" {
+		t.Errorf("expected the container name to be share-tutorial.nitro, got %s", mock.name)
+	}
+
+	wantCmd := []string{"tunnel", "--no-autoupdate", "--url", "http://nitro-proxy:80", "--http-host-header", "tutorial.nitro"}
+	if !reflect.DeepEqual([]string(mock.containerConfig.Cmd), wantCmd) {
+		t.Errorf("expected the command to be %v, got %v", wantCmd, mock.containerConfig.Cmd)
+	}
+
+	if mock.containerConfig.Labels[containerlabels.Share] != "tutorial.nitro" {
+		t.Errorf("expected the share label to be tutorial.nitro, got %v", mock.containerConfig.Labels)
+	}
+}
@@ -1,11 +1,44 @@
 package ssh
 
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/svc/dynamodb"
+	"github.com/craftcms/nitro/pkg/svc/elasticsearch"
+	"github.com/craftcms/nitro/pkg/svc/mailhog"
+	"github.com/craftcms/nitro/pkg/svc/meilisearch"
+	"github.com/craftcms/nitro/pkg/svc/minio"
+	"github.com/craftcms/nitro/pkg/svc/rabbitmq"
+	"github.com/craftcms/nitro/pkg/svc/redis"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
 var (
 	// RootUser is used to tell the container to run as root and not the default user www-data
 	RootUser bool
 
 	// ProxyContainer is used to ssh into the proxy container and is mostly used for troubleshooting
 	ProxyContainer bool
+
+	// DatabaseContainer is used to ssh into a database container
+	DatabaseContainer bool
+
+	// ServiceContainer is used to ssh into a service container (e.g. mailhog) or a custom container
+	ServiceContainer bool
+
+	// services are the type labels of the service containers
+	services = []string{dynamodb.Label, elasticsearch.Label, mailhog.Label, meilisearch.Label, minio.Label, rabbitmq.Label, redis.Label}
+
+	// suffixes are removed from the container names so the short name can be used (e.g. mysql-8.0-3306)
+	suffixes = []string{".database.nitro", ".service.nitro", ".containers.nitro"}
 )
 
 const exampleText = `  # ssh into a container - assuming its the current working directory
@@ -18,4 +51,104 @@ const exampleText = `  # ssh into a container - assuming its the current working
   nitro ssh --root
 
   # ssh into the proxy container
-  nitro ssh --proxy`
+  nitro ssh --proxy
+
+  # ssh into a database container, or select from the databases
+  nitro ssh --database mysql-8.0-3306
+
+  # ssh into a service or custom container
+  nitro ssh --service mailhog`
+
+// selectContainer returns the database or service container with the name. When the name is
+// empty and there is more than one container, the user is prompted to select a container.
+func selectContainer(cmd *cobra.Command, docker client.ContainerAPIClient, output terminal.Outputer, database bool, name string) (types.Container, error) {
+	filter := filters.NewArgs()
+	filter.Add("label", containerlabels.Nitro)
+	if database {
+		filter.Add("label", containerlabels.Type+"=database")
+	}
+
+	containers, err := docker.ContainerList(cmd.Context(), types.ContainerListOptions{Filters: filter, All: true})
+	if err != nil {
+		return types.Container{}, err
+	}
+
+	kind := "database"
+	if !database {
+		kind = "service"
+		containers = serviceContainers(containers)
+	}
+
+	if name != "" {
+		c, ok := matchContainer(containers, name)
+		if !ok {
+			return types.Container{}, fmt.Errorf("unable to find a %s container for %s", kind, name)
+		}
+
+		return c, nil
+	}
+
+	switch len(containers) {
+	case 0:
+		return types.Container{}, fmt.Errorf("there are no %s containers", kind)
+	case 1:
+		output.Info("connecting to", strings.TrimLeft(containers[0].Names[0], "/"))
+
+		return containers[0], nil
+	}
+
+	sort.SliceStable(containers, func(i, j int) bool {
+		return containers[i].Names[0] < containers[j].Names[0]
+	})
+
+	var options []string
+	for _, c := range containers {
+		options = append(options, strings.TrimLeft(c.Names[0], "/"))
+	}
+
+	selected, err := output.Select(cmd.InOrStdin(), fmt.Sprintf("Select a %s: ", kind), options)
+	if err != nil {
+		return types.Container{}, err
+	}
+
+	return containers[selected], nil
+}
+
+// serviceContainers returns the service containers and custom containers from the config.
+func serviceContainers(containers []types.Container) []types.Container {
+	var found []types.Container
+	for _, c := range containers {
+		if c.Labels[containerlabels.NitroContainer] != "" {
+			found = append(found, c)
+			continue
+		}
+
+		for _, s := range services {
+			if c.Labels[containerlabels.Type] == s {
+				found = append(found, c)
+				break
+			}
+		}
+	}
+
+	return found
+}
+
+// matchContainer returns the container by the name, the name without the suffix (e.g. mailhog),
+// or the type of service.
+func matchContainer(containers []types.Container, name string) (types.Container, bool) {
+	for _, c := range containers {
+		n := strings.TrimLeft(c.Names[0], "/")
+		if n == name || c.Labels[containerlabels.Type] == name {
+			return c, true
+		}
+
+		for _, suffix := range suffixes {
+			if strings.TrimSuffix(n, suffix) == name {
+				return c, true
+			}
+		}
+	}
+
+	return types.Container{}, false
+}
@@ -60,6 +60,11 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 				return err
 			}
 
+			// merge the project files from the sites
+			if _, err := cfg.MergeProjects(home); err != nil {
+				return err
+			}
+
 			var site string
 			if len(args) > 0 {
 				site = strings.TrimSpace(args[0])
@@ -70,8 +75,19 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 			filter.Add("label", containerlabels.Nitro)
 
 			var containerID string
-			switch ProxyContainer {
-			case true:
+			switch {
+			case DatabaseContainer || ServiceContainer:
+				c, err := selectContainer(cmd, docker, output, DatabaseContainer, site)
+				if err != nil {
+					return err
+				}
+
+				if c.State != "running" {
+					return fmt.Errorf("%s is not running…\n run `nitro start` to start the containers", strings.TrimLeft(c.Names[0], "/"))
+				}
+
+				containerID = c.ID
+			case ProxyContainer:
 				// file by the container name
 				filter.Add("name", proxycontainer.ProxyName)
 
@@ -110,13 +126,14 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 				// did they ask for a specific site?
 				switch site != "" {
 				case true:
-					for k, v := range options {
-						if site == v {
-							// add the label to get the site
-							filter.Add("label", containerlabels.Host+"="+sites[k].Hostname)
-							break
-						}
+					// the site may not be in the current directory, so look in all of the sites
+					s, err := cfg.FindSiteByHostName(site)
+					if err != nil {
+						return fmt.Errorf("unable to find the site %s…\n run `nitro ls` to show the sites", site)
 					}
+
+					// add the label to get the site, otherwise the first container would be used
+					filter.Add("label", containerlabels.Host+"="+s.Hostname)
 				default:
 					// if there are found sites we want to show or connect to the first one, otherwise prompt for which site to connect to.
 					switch len(sites) {
@@ -179,7 +196,7 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 
 			// check if the root user should be used
 			containerUser := "www-data"
-			if RootUser || ProxyContainer {
+			if RootUser || ProxyContainer || DatabaseContainer || ServiceContainer {
 				containerUser = "root"
 			}
 
@@ -198,8 +215,10 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 		},
 	}
 
-	cmd.Flags().BoolVar(&RootUser, "root", false, "connect as root user")
+	cmd.Flags().BoolVar(&RootUser, "root", false, "connect as the root user to install packages or debug permissions")
 	cmd.Flags().BoolVar(&ProxyContainer, "proxy", false, "connect to proxy container")
+	cmd.Flags().BoolVar(&DatabaseContainer, "database", false, "connect to a database container")
+	cmd.Flags().BoolVar(&ServiceContainer, "service", false, "connect to a service or custom container")
 
 	return cmd
 }
@@ -0,0 +1,77 @@
+package ssh
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types"
+
+	"github.com/craftcms/nitro/pkg/containerlabels"
+)
+
+var containers = []types.Container{
+	{ID: "site", Names: []string{"/tutorial.nitro"}, Labels: map[string]string{containerlabels.Host: "tutorial.nitro"}},
+	{ID: "mysql", Names: []string{"/mysql-8.0-3306.database.nitro"}, Labels: map[string]string{containerlabels.Type: "database"}},
+	{ID: "mailhog", Names: []string{"/mailhog.service.nitro"}, Labels: map[string]string{containerlabels.Type: "mailhog"}},
+	{ID: "custom", Names: []string{"/typesense.containers.nitro"}, Labels: map[string]string{containerlabels.NitroContainer: "typesense"}},
+}
+
+func Test_serviceContainers(t *testing.T) {
+	var got []string
+	for _, c := range serviceContainers(containers) {
+		got = append(got, c.ID)
+	}
+
+	if len(got) != 2 || got[0] != "mailhog" || got[1] != "custom" {
+		t.Errorf("expected the mailhog and custom containers, got %v", got)
+	}
+}
+
+func Test_matchContainer(t *testing.T) {
+	tests := []struct {
+		name   string
+		search string
+		want   string
+		found  bool
+	}{
+		{
+			name:   "containers are found by the name",
+			search: "mysql-8.0-3306.database.nitro",
+			want:   "mysql",
+			found:  true,
+		},
+		{
+			name:   "databases are found without the suffix",
+			search: "mysql-8.0-3306",
+			want:   "mysql",
+			found:  true,
+		},
+		{
+			name:   "services are found by the type",
+			search: "mailhog",
+			want:   "mailhog",
+			found:  true,
+		},
+		{
+			name:   "custom containers are found without the suffix",
+			search: "typesense",
+			want:   "custom",
+			found:  true,
+		},
+		{
+			name:   "unknown containers are not found",
+			search: "postgres-13-5432",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := matchContainer(containers, tt.search)
+			if found != tt.found {
+				t.Fatalf("matchContainer() found = %v, want %v", found, tt.found)
+			}
+
+			if got.ID != tt.want {
+				t.Errorf("matchContainer() = %v, want %v", got.ID, tt.want)
+			}
+		})
+	}
+}
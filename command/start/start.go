@@ -82,6 +82,11 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 					continue
 				}
 
+				// don't start sites and containers for other environments
+				if containerlabels.OtherEnvironment(c) {
+					continue
+				}
+
 				// identify the type of container
 				containerType := containerlabels.Identify(c)
 
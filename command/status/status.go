@@ -0,0 +1,221 @@
+package status
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+
+	nitroclient "github.com/craftcms/nitro/client"
+	"github.com/craftcms/nitro/pkg/api"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/prompt"
+	"github.com/craftcms/nitro/pkg/terminal"
+	"github.com/craftcms/nitro/protob"
+)
+
+const exampleText = `  # show the routes, certificates, and site health from the proxy
+  nitro status
+
+  # show only the sites and containers created by an apply run
+  nitro status --run-id 3f2a9c1e`
+
+// ErrOutdatedProxy is returned when the proxy does not support the Status RPC
+var ErrOutdatedProxy = errors.New("the proxy needs to be updated to show the status")
+
+// expiringSoon is how long before a certificate expires that it is shown as expiring
+const expiringSoon = 7 * 24 * time.Hour
+
+// NewCommand returns the command to show the status of the proxy, so users can debug sites
+// that are not routing without exec'ing into the proxy container.
+func NewCommand(home string, docker client.CommonAPIClient, nitrod protob.NitroClient, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "status",
+		Short:   "Shows the status of the proxy.",
+		Example: exampleText,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return prompt.VerifyInit(cmd, args, home, output)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, capabilities, err := nitroclient.Capabilities(cmd.Context(), nitrod)
+			if err != nil {
+				return err
+			}
+
+			if !capabilities[api.CapabilityStatus] {
+				output.Info("The proxy does not support the status…\n run `nitro update` to update the proxy")
+
+				return ErrOutdatedProxy
+			}
+
+			resp, err := nitrod.Status(cmd.Context(), &protob.StatusRequest{})
+			if err != nil {
+				return fmt.Errorf("unable to get the status from the proxy, %w", err)
+			}
+
+			output.Info("Nitro proxy:", resp.GetVersion())
+
+			// show only the routes for the containers from a specific apply
+			if id := cmd.Flag("run-id").Value.String(); id != "" {
+				hosts, err := runHosts(cmd.Context(), docker, id)
+				if err != nil {
+					return err
+				}
+
+				if len(hosts) == 0 {
+					output.Info("There are no containers for the run ID", id)
+
+					return nil
+				}
+
+				resp = filterStatus(resp, hosts)
+			}
+
+			if len(resp.GetRoutes()) == 0 {
+				output.Info("The proxy does not have any routes…\n run `nitro apply` to add the sites")
+
+				return nil
+			}
+
+			output.Info("")
+
+			routes := table.New("Hosts", "Upstream", "Type").WithWriter(cmd.OutOrStdout()).WithPadding(2)
+			for _, r := range resp.GetRoutes() {
+				kind := "site"
+				if r.GetWebsocket() {
+					kind = "websocket"
+				}
+
+				routes.AddRow(strings.Join(r.GetHosts(), ", "), r.GetUpstream(), kind)
+			}
+			routes.Print()
+
+			output.Info("")
+
+			var unhealthy int
+			sites := table.New("Hostname", "Upstream", "Health").WithWriter(cmd.OutOrStdout()).WithPadding(2)
+			for _, s := range resp.GetSites() {
+				health := "reachable"
+				if !s.GetHealthy() {
+					health = "unreachable: " + s.GetMessage()
+					unhealthy++
+				}
+
+				sites.AddRow(s.GetHostname(), s.GetUpstream(), health)
+			}
+			sites.Print()
+
+			if len(resp.GetCertificates()) > 0 {
+				output.Info("")
+
+				now := time.Now()
+				certificates := table.New("Names", "Issuer", "Expires").WithWriter(cmd.OutOrStdout()).WithPadding(2)
+				for _, c := range resp.GetCertificates() {
+					certificates.AddRow(strings.Join(c.GetNames(), ", "), c.GetIssuer(), expiration(c.GetNotAfter(), now))
+				}
+				certificates.Print()
+			}
+
+			if unhealthy > 0 {
+				output.Info("")
+				output.Info(fmt.Sprintf("Warning: the proxy is unable to reach %d site(s)…\n check the site with `nitro logs`", unhealthy))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("run-id", "", "show only the sites and containers created by an apply run")
+
+	return cmd
+}
+
+// runHosts returns the hostnames of the containers that were created by the apply run.
+func runHosts(ctx context.Context, docker client.ContainerAPIClient, id string) (map[string]bool, error) {
+	filter := filters.NewArgs()
+	filter.Add("label", containerlabels.Nitro)
+	filter.Add("label", containerlabels.RunID+"="+id)
+
+	containers, err := docker.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: filter})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the containers, %w", err)
+	}
+
+	hosts := make(map[string]bool)
+	for _, c := range containers {
+		hosts[strings.TrimLeft(c.Names[0], "/")] = true
+
+		if h := c.Labels[containerlabels.Host]; h != "" {
+			hosts[h] = true
+		}
+	}
+
+	return hosts, nil
+}
+
+// filterStatus returns the status with only the routes, sites, and certificates for the hosts.
+// Routes and sites are matched by their upstream, so aliases and custom domains are included.
+func filterStatus(resp *protob.StatusResponse, hosts map[string]bool) *protob.StatusResponse {
+	filtered := &protob.StatusResponse{Version: resp.GetVersion()}
+
+	names := make(map[string]bool)
+	for _, r := range resp.GetRoutes() {
+		if !hosts[upstreamHost(r.GetUpstream())] {
+			continue
+		}
+
+		filtered.Routes = append(filtered.Routes, r)
+
+		for _, h := range r.GetHosts() {
+			names[h] = true
+		}
+	}
+
+	for _, s := range resp.GetSites() {
+		if hosts[upstreamHost(s.GetUpstream())] {
+			filtered.Sites = append(filtered.Sites, s)
+		}
+	}
+
+	for _, c := range resp.GetCertificates() {
+		for _, n := range c.GetNames() {
+			if names[n] {
+				filtered.Certificates = append(filtered.Certificates, c)
+				break
+			}
+		}
+	}
+
+	return filtered
+}
+
+// upstreamHost returns the host of an upstream without the port (e.g. craft-dev.nitro:8080).
+func upstreamHost(upstream string) string {
+	if i := strings.LastIndex(upstream, ":"); i != -1 {
+		return upstream[:i]
+	}
+
+	return upstream
+}
+
+// expiration returns the date the certificate expires and if it has or will soon expire.
+func expiration(notAfter int64, now time.Time) string {
+	expires := time.Unix(notAfter, 0)
+	date := expires.Format("2006-01-02")
+
+	switch {
+	case now.After(expires):
+		return date + " (expired)"
+	case expires.Sub(now) < expiringSoon:
+		return date + " (expiring soon)"
+	}
+
+	return date
+}
@@ -0,0 +1,202 @@
+package status
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/craftcms/nitro/pkg/api"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/terminal"
+	"github.com/craftcms/nitro/protob"
+)
+
+type statusMock struct {
+	protob.NitroClient
+
+	resp     *protob.StatusResponse
+	outdated bool
+}
+
+func (m *statusMock) Capabilities(ctx context.Context, in *protob.CapabilitiesRequest, opts ...grpc.CallOption) (*protob.CapabilitiesResponse, error) {
+	if m.outdated {
+		return nil, status.Error(codes.Unimplemented, "unknown method Capabilities")
+	}
+
+	return &protob.CapabilitiesResponse{Capabilities: []string{api.CapabilityStatus}}, nil
+}
+
+func (m *statusMock) Status(ctx context.Context, in *protob.StatusRequest, opts ...grpc.CallOption) (*protob.StatusResponse, error) {
+	return m.resp, nil
+}
+
+type dockerMock struct {
+	client.CommonAPIClient
+
+	containers []types.Container
+}
+
+func (m *dockerMock) ContainerList(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error) {
+	var containers []types.Container
+	for _, c := range m.containers {
+		if options.Filters.ExactMatch("label", containerlabels.RunID+"="+c.Labels[containerlabels.RunID]) {
+			containers = append(containers, c)
+		}
+	}
+
+	return containers, nil
+}
+
+func TestStatusCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		resp     *protob.StatusResponse
+		outdated bool
+		runID    string
+		want     []string
+		notWant  []string
+		wantErr  error
+	}{
+		{
+			name:     "outdated proxies suggest running update",
+			outdated: true,
+			want:     []string{"run `nitro update`"},
+			wantErr:  ErrOutdatedProxy,
+		},
+		{
+			name: "proxies without routes suggest running apply",
+			resp: &protob.StatusResponse{Version: "2.0.0"},
+			want: []string{"Nitro proxy: 2.0.0", "run `nitro apply`"},
+		},
+		{
+			name: "routes, sites, and certificates are shown",
+			resp: &protob.StatusResponse{
+				Version: "2.0.0",
+				Routes: []*protob.Route{
+					{Hosts: []string{"craft-dev.nitro", "alias.nitro"}, Upstream: "craft-dev.nitro:8080"},
+					{Hosts: []string{"craft-dev.nitro"}, Upstream: "craft-dev.nitro:6001", Websocket: true},
+				},
+				Sites: []*protob.SiteHealth{
+					{Hostname: "craft-dev.nitro", Upstream: "craft-dev.nitro:8080", Message: "connection refused"},
+				},
+				Certificates: []*protob.Certificate{
+					{Names: []string{"craft-dev.nitro"}, Issuer: "Caddy Local Authority", NotAfter: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC).Unix()},
+				},
+			},
+			want: []string{
+				"craft-dev.nitro, alias.nitro",
+				"websocket",
+				"unreachable: connection refused",
+				"Caddy Local Authority",
+				"(expired)",
+				"unable to reach 1 site(s)",
+			},
+		},
+		{
+			name:  "the run id shows only the containers from the apply",
+			runID: "3f2a9c1e",
+			resp: &protob.StatusResponse{
+				Version: "2.0.0",
+				Routes: []*protob.Route{
+					{Hosts: []string{"craft-dev.nitro", "alias.nitro"}, Upstream: "craft-dev.nitro:8080"},
+					{Hosts: []string{"other.nitro"}, Upstream: "other.nitro:8080"},
+				},
+				Sites: []*protob.SiteHealth{
+					{Hostname: "craft-dev.nitro", Upstream: "craft-dev.nitro:8080", Healthy: true},
+					{Hostname: "other.nitro", Upstream: "other.nitro:8080", Healthy: true},
+				},
+				Certificates: []*protob.Certificate{
+					{Names: []string{"craft-dev.nitro"}, Issuer: "Caddy Local Authority"},
+					{Names: []string{"other.nitro"}, Issuer: "Other Authority"},
+				},
+			},
+			want:    []string{"craft-dev.nitro, alias.nitro", "Caddy Local Authority"},
+			notWant: []string{"other.nitro", "Other Authority"},
+		},
+		{
+			name:  "unknown run ids do not show the routes",
+			runID: "missing",
+			resp: &protob.StatusResponse{
+				Version: "2.0.0",
+				Routes:  []*protob.Route{{Hosts: []string{"craft-dev.nitro"}, Upstream: "craft-dev.nitro:8080"}},
+			},
+			want:    []string{"There are no containers for the run ID missing"},
+			notWant: []string{"craft-dev.nitro"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			output := terminal.NewRenderer(buf, false).Worker()
+
+			docker := &dockerMock{
+				containers: []types.Container{
+					{Names: []string{"/craft-dev.nitro"}, Labels: map[string]string{containerlabels.Host: "craft-dev.nitro", containerlabels.RunID: "3f2a9c1e"}},
+					{Names: []string{"/other.nitro"}, Labels: map[string]string{containerlabels.Host: "other.nitro", containerlabels.RunID: "a1b2c3d4"}},
+				},
+			}
+
+			cmd := NewCommand("", docker, &statusMock{resp: tt.resp, outdated: tt.outdated}, output)
+			cmd.SetOut(buf)
+			cmd.Flags().Set("run-id", tt.runID)
+
+			if err := cmd.RunE(cmd, nil); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected the error to be %v, got %v", tt.wantErr, err)
+			}
+
+			for _, w := range tt.want {
+				if !strings.Contains(buf.String(), w) {
+					t.Errorf("expected the output to contain %q, got:\n%s", w, buf.String())
+				}
+			}
+
+			for _, w := range tt.notWant {
+				if strings.Contains(buf.String(), w) {
+					t.Errorf("expected the output to not contain %q, got:\n%s", w, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func Test_expiration(t *testing.T) {
+	now := time.Date(2021, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		notAfter time.Time
+		want     string
+	}{
+		{
+			name:     "expired certificates",
+			notAfter: time.Date(2021, 1, 9, 12, 0, 0, 0, time.UTC),
+			want:     " (expired)",
+		},
+		{
+			name:     "certificates expiring within a week",
+			notAfter: time.Date(2021, 1, 12, 12, 0, 0, 0, time.UTC),
+			want:     " (expiring soon)",
+		},
+		{
+			name:     "valid certificates only show the date",
+			notAfter: time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := time.Unix(tt.notAfter.Unix(), 0).Format("2006-01-02") + tt.want
+			if got := expiration(tt.notAfter.Unix(), now); got != want {
+				t.Errorf("expiration() = %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package trust
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/prompt"
+	"github.com/craftcms/nitro/pkg/proxycontainer"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+const renewExampleText = `  # regenerate the root and site certificates and trust the new root certificate
+  nitro trust renew`
+
+// renewPaths are the directories in the proxy container with the root certificate
+// authority and the site certificates it issued
+var renewPaths = []string{
+	"/data/caddy/pki/authorities/local",
+	"/data/caddy/certificates/local",
+}
+
+// renewCommand returns the command to regenerate the proxy's root certificate and the site
+// certificates, for when the certificates expire or the root certificate is lost.
+func renewCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "renew",
+		Short:   "Regenerates Nitro certificates.",
+		Example: renewExampleText,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			output.Info("Renewing certificates…")
+
+			proxy, err := proxycontainer.FindAndStart(ctx, docker)
+			if err != nil {
+				return err
+			}
+
+			output.Pending("removing the certificates")
+
+			if err := renew(ctx, docker, proxy.ID); err != nil {
+				output.Warning()
+				return err
+			}
+
+			output.Done()
+
+			// apply configures the restarted proxy, which issues the site certificates
+			if err := prompt.RunApply(cmd, nil, true, output); err != nil {
+				return err
+			}
+
+			// trust the new root certificate
+			return cmd.Parent().RunE(cmd.Parent(), nil)
+		},
+	}
+
+	return cmd
+}
+
+// renew removes the root and site certificates from the proxy container and restarts
+// the proxy, which creates a new root certificate when it starts.
+func renew(ctx context.Context, docker client.ContainerAPIClient, id string) error {
+	exec, err := docker.ContainerExecCreate(ctx, id, types.ExecConfig{
+		AttachStdout: true,
+		AttachStderr: true,
+		Tty:          false,
+		Cmd:          append([]string{"rm", "-rf"}, renewPaths...),
+	})
+	if err != nil {
+		return fmt.Errorf("unable to create the exec, %w", err)
+	}
+
+	resp, err := docker.ContainerExecAttach(ctx, exec.ID, types.ExecStartCheck{Tty: false})
+	if err != nil {
+		return fmt.Errorf("unable to attach to the proxy container, %w", err)
+	}
+
+	if err := docker.ContainerExecStart(ctx, exec.ID, types.ExecStartCheck{}); err != nil {
+		resp.Close()
+		return fmt.Errorf("unable to start the exec, %w", err)
+	}
+
+	// wait for the command to finish, stderr is kept for errors
+	stderr := new(bytes.Buffer)
+	_, err = stdcopy.StdCopy(ioutil.Discard, stderr, resp.Reader)
+	resp.Close()
+	if err != nil {
+		return err
+	}
+
+	info, err := docker.ContainerExecInspect(ctx, exec.ID)
+	if err != nil {
+		return err
+	}
+
+	if info.ExitCode != 0 {
+		return fmt.Errorf("unable to remove the certificates, %s", strings.TrimSpace(stderr.String()))
+	}
+
+	if err := docker.ContainerRestart(ctx, id, nil); err != nil {
+		return fmt.Errorf("unable to restart the proxy container, %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package trust
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+type renewMock struct {
+	client.ContainerAPIClient
+
+	exitCode int
+	calls    []string
+}
+
+func (m *renewMock) ContainerExecCreate(ctx context.Context, container string, config types.ExecConfig) (types.IDResponse, error) {
+	m.calls = append(m.calls, "exec "+container+" "+strings.Join(config.Cmd, " "))
+	return types.IDResponse{ID: "exec-id"}, nil
+}
+
+func (m *renewMock) ContainerExecAttach(ctx context.Context, execID string, config types.ExecStartCheck) (types.HijackedResponse, error) {
+	out := &bytes.Buffer{}
+	if m.exitCode != 0 {
+		stdcopy.NewStdWriter(out, stdcopy.Stderr).Write([]byte("permission denied"))
+	}
+
+	conn, _ := net.Pipe()
+
+	return types.HijackedResponse{Conn: conn, Reader: bufio.NewReader(out)}, nil
+}
+
+func (m *renewMock) ContainerExecStart(ctx context.Context, execID string, config types.ExecStartCheck) error {
+	return nil
+}
+
+func (m *renewMock) ContainerExecInspect(ctx context.Context, execID string) (types.ContainerExecInspect, error) {
+	return types.ContainerExecInspect{ExitCode: m.exitCode}, nil
+}
+
+func (m *renewMock) ContainerRestart(ctx context.Context, container string, timeout *time.Duration) error {
+	m.calls = append(m.calls, "restart "+container)
+	return nil
+}
+
+func TestRenew(t *testing.T) {
+	tests := []struct {
+		name     string
+		exitCode int
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name: "removes the certificates and restarts the proxy",
+			want: []string{
+				"exec proxy-id rm -rf /data/caddy/pki/authorities/local /data/caddy/certificates/local",
+				"restart proxy-id",
+			},
+		},
+		{
+			name:     "does not restart the proxy when the certificates are not removed",
+			exitCode: 1,
+			want: []string{
+				"exec proxy-id rm -rf /data/caddy/pki/authorities/local /data/caddy/certificates/local",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &renewMock{exitCode: tt.exitCode}
+
+			err := renew(context.Background(), mock, "proxy-id")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("renew() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(mock.calls, tt.want) {
+				t.Errorf("expected the calls to be %v, got %v", tt.want, mock.calls)
+			}
+		})
+	}
+}
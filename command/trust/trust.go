@@ -29,7 +29,10 @@ var (
 const (
 	certificatePath = "/data/caddy/pki/authorities/local/root.crt"
 	exampleText     = `  # get the root certificate for the proxy
-  nitro trust`
+  nitro trust
+
+  # regenerate the certificates when they expire or are lost
+  nitro trust renew`
 )
 
 // NewCommand returns `trust` to retrieve the certificates from the nitro proxy and install on the
@@ -131,7 +134,8 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 			output.Done()
 
 			// copy the certificate into the nitro dir
-			cert, err := os.Create(filepath.Join(home, config.DirectoryName, "nitro.crt"))
+			certFile := filepath.Join(home, config.DirectoryName, "nitro.crt")
+			cert, err := os.Create(certFile)
 			if err != nil {
 				return err
 			}
@@ -149,6 +153,18 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 				return err
 			}
 
+			// firefox and thunderbird do not use the system certificates
+			if databases := certinstall.NSSDatabases(home, runtime.GOOS); len(databases) > 0 && cmd.Flag("skip-nss").Value.String() != "true" {
+				output.Pending("trusting the certificate in Firefox and Thunderbird")
+
+				if err := certinstall.InstallNSS(certFile, databases); err != nil {
+					output.Warning()
+					output.Info("Warning:", err.Error())
+				} else {
+					output.Done()
+				}
+			}
+
 			output.Info("Nitro certificates are now trusted 🔒")
 
 			return nil
@@ -156,6 +172,9 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 	}
 
 	cmd.Flags().Bool("output-only", false, "show the certificate without importing")
+	cmd.Flags().Bool("skip-nss", false, "skip trusting the certificate in Firefox and Thunderbird")
+
+	cmd.AddCommand(renewCommand(home, docker, output))
 
 	return cmd
 }
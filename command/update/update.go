@@ -121,7 +121,7 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 			// check all of the containers
 			for _, container := range containers {
 				// is this a database, service, composer, or node container?
-				if container.Labels[containerlabels.Type] == "dynamodb" || container.Labels[containerlabels.Type] == "mailhog" || container.Labels[containerlabels.Type] == "minio" || container.Labels[containerlabels.Type] == "redis" || container.Labels[containerlabels.Type] == "database" {
+				if container.Labels[containerlabels.Type] == "dynamodb" || container.Labels[containerlabels.Type] == "elasticsearch" || container.Labels[containerlabels.Type] == "mailhog" || container.Labels[containerlabels.Type] == "meilisearch" || container.Labels[containerlabels.Type] == "minio" || container.Labels[containerlabels.Type] == "rabbitmq" || container.Labels[containerlabels.Type] == "redis" || container.Labels[containerlabels.Type] == "database" {
 					continue
 				}
 
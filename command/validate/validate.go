@@ -2,18 +2,30 @@ package validate
 
 import (
 	"fmt"
-	"os"
+	"strings"
 
 	"github.com/docker/docker/client"
 	"github.com/spf13/cobra"
 
 	"github.com/craftcms/nitro/pkg/config"
 	"github.com/craftcms/nitro/pkg/terminal"
-	"github.com/craftcms/nitro/pkg/validate"
 )
 
 const exampleText = `  # validate a config file
-  nitro validate`
+  nitro validate
+
+  # return an error for unknown config fields
+  nitro validate --strict
+
+  # show the problems as json
+  nitro validate --output json`
+
+// result is the result of validating the config for the json output.
+type result struct {
+	Valid    bool             `json:"valid"`
+	Unknown  []string         `json:"unknown"`
+	Problems []config.Problem `json:"problems"`
+}
 
 func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
 	cmd := &cobra.Command{
@@ -22,71 +34,101 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 		Short:      "Validates the Nitro config file.",
 		Example:    exampleText,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			format := cmd.Flag("output").Value.String()
+			if err := terminal.ValidateFormat(format); err != nil {
+				return err
+			}
+
 			cfg, err := config.Load(home)
 			if err != nil {
 				return err
 			}
 
+			if format == terminal.FormatJSON {
+				return validateJSON(cmd, home, cfg)
+			}
+
 			output.Info("Validating…")
 
-			// set errors
-			var siteErrs, dbErrs []error
+			// check for unknown fields, which are usually typos
+			unknown, err := cfg.UnknownFields()
+			if err != nil {
+				return err
+			}
 
-			dbs := cfg.Databases
-			sites := cfg.Sites
+			if len(unknown) > 0 && cmd.Flag("strict").Value.String() == "true" {
+				return fmt.Errorf("%s", strings.Join(unknown, "\n"))
+			}
 
-			if len(dbs) > 0 {
-				output.Pending("validating databases")
+			for _, u := range unknown {
+				output.Info("Warning:", u)
+			}
 
-				for _, d := range dbs {
-					if d.Port == "" {
-						dbErrs = append(dbErrs, fmt.Errorf("port is not assigned"))
-					}
-				}
+			output.Pending("validating config")
 
-				output.Done()
+			// check for all of the problems instead of the first one
+			problems, err := cfg.Lint(home)
+			if err != nil {
+				output.Warning()
+				return err
 			}
 
-			// check the site paths
-			if len(sites) > 0 {
-				output.Pending("validating sites")
-
-				for _, s := range sites {
-					// check the site path
-					p, err := s.GetAbsPath(home)
-					if err != nil {
-						siteErrs = append(siteErrs, err)
-					}
-
-					if _, err := os.Stat(p); os.IsNotExist(err) {
-						siteErrs = append(siteErrs, fmt.Errorf("unable to locate site path %s", p))
-					}
-
-					// validate the php version
-					phpvalidator := validate.PHPVersionValidator{}
-					if err := phpvalidator.Validate(s.Version); err != nil {
-						siteErrs = append(siteErrs, fmt.Errorf("invalid php version %s", s.Version))
-					}
-				}
-
-				if len(siteErrs) > 0 {
-					output.Warning()
-				} else {
-					output.Done()
-				}
+			if len(problems) == 0 {
+				output.Done()
+
+				return nil
 			}
 
-			// show any errors
-			if len(siteErrs) > 0 {
-				output.Info("Site Errors:")
-				for _, e := range siteErrs {
-					output.Info(" \u2610", e.Error())
-				}
+			output.Warning()
+
+			output.Info("Errors:")
+			for _, p := range problems {
+				output.Info(" \u2610", p.String())
 			}
 
-			return nil
+			return fmt.Errorf("the config has %d problems", len(problems))
 		},
 	}
 
+	cmd.Flags().Bool("strict", false, "return an error for unknown config fields")
+	cmd.Flags().StringP("output", "o", terminal.FormatText, "the output format (text or json)")
+
 	return cmd
 }
+
+// validateJSON writes the unknown fields and problems with the config as json. An error is
+// still returned when the config is not valid so scripts can check the exit code.
+func validateJSON(cmd *cobra.Command, home string, cfg *config.Config) error {
+	unknown, err := cfg.UnknownFields()
+	if err != nil {
+		return err
+	}
+
+	problems, err := cfg.Lint(home)
+	if err != nil {
+		return err
+	}
+
+	strict := cmd.Flag("strict").Value.String() == "true"
+
+	r := result{
+		Valid:    len(problems) == 0 && (len(unknown) == 0 || !strict),
+		Unknown:  append([]string{}, unknown...),
+		Problems: append([]config.Problem{}, problems...),
+	}
+
+	if err := terminal.JSON(cmd.OutOrStdout(), r); err != nil {
+		return err
+	}
+
+	if !r.Valid {
+		count := len(problems)
+		if strict {
+			count += len(unknown)
+		}
+
+		return fmt.Errorf("the config has %d problems", count)
+	}
+
+	return nil
+}
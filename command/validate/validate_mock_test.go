@@ -0,0 +1,36 @@
+package validate
+
+import (
+	"io"
+	"strings"
+
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+type spyOutputer struct {
+	infos []string
+}
+
+func (spy *spyOutputer) Ask(message, fallback, sep string, validator terminal.Validator) (string, error) {
+	return fallback, nil
+}
+
+func (spy *spyOutputer) Confirm(message string, fallback bool, sep string) (bool, error) {
+	return fallback, nil
+}
+
+func (spy *spyOutputer) Info(s ...string) {
+	spy.infos = append(spy.infos, strings.Join(s, " "))
+}
+
+func (spy *spyOutputer) Select(r io.Reader, msg string, opts []string) (int, error) {
+	return 0, nil
+}
+
+func (spy *spyOutputer) Warning() {}
+
+func (spy *spyOutputer) Success(s ...string) {}
+
+func (spy *spyOutputer) Pending(s ...string) {}
+
+func (spy *spyOutputer) Done() {}
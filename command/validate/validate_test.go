@@ -0,0 +1,97 @@
+package validate
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestValidateUnknownFields(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		strict      bool
+		wantErr     bool
+		wantWarning string
+	}{
+		{
+			name:        "unknown fields are a warning by default",
+			strict:      false,
+			wantErr:     false,
+			wantWarning: "Warning: unknown config field 'memoryLimt' (line 7)",
+		},
+		{
+			name:    "unknown fields are an error when strict",
+			strict:  true,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spy := &spyOutputer{}
+
+			cmd := NewCommand(filepath.Join(wd, "testdata"), nil, spy)
+			cmd.Flags().Set("strict", strconv.FormatBool(tt.strict))
+
+			if err := cmd.RunE(cmd, []string{}); (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+
+			if tt.wantWarning == "" {
+				return
+			}
+
+			found := false
+			for _, i := range spy.infos {
+				if i == tt.wantWarning {
+					found = true
+				}
+			}
+
+			if !found {
+				t.Errorf("expected the warning %q, got %v", tt.wantWarning, spy.infos)
+			}
+		})
+	}
+}
+
+func TestValidateProblems(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	home := filepath.Join(wd, "testdata", "problems")
+	spy := &spyOutputer{}
+
+	cmd := NewCommand(home, nil, spy)
+
+	if err := cmd.RunE(cmd, []string{}); err == nil {
+		t.Fatal("expected an error for the problems")
+	}
+
+	want := []string{
+		"Errors:",
+		" ☐ line 3: the path " + filepath.Join(home, "missing") + " for site example.nitro does not exist",
+		" ☐ line 6: the hostname example.nitro is used by more than one site",
+		" ☐ line 8: the PHP version \"5.6\" is not valid for site example.nitro",
+	}
+
+	for _, w := range want {
+		found := false
+		for _, i := range spy.infos {
+			if i == w {
+				found = true
+			}
+		}
+
+		if !found {
+			t.Errorf("expected the output %q, got %v", w, spy.infos)
+		}
+	}
+}
@@ -17,7 +17,22 @@ import (
 // container to use to verify the gRPC API is in sync.
 var Version = "develop"
 
-var exampleText = `nitro version`
+var exampleText = `  # show the versions
+  nitro version
+
+  # show the versions as json
+  nitro version --output json`
+
+// versions are the versions for the json output.
+type versions struct {
+	CLI            string `json:"cli"`
+	GRPC           string `json:"grpc"`
+	DockerAPI      string `json:"docker_api"`
+	DockerMinAPI   string `json:"docker_min_api"`
+	DockerClient   string `json:"docker_client"`
+	Changelog      string `json:"changelog"`
+	UpdateRequired bool   `json:"update_required"`
+}
 
 // NewCommand is used to show the cli and gRPC API client version
 func NewCommand(home string, client client.CommonAPIClient, nitrod protob.NitroClient, output terminal.Outputer) *cobra.Command {
@@ -29,6 +44,11 @@ func NewCommand(home string, client client.CommonAPIClient, nitrod protob.NitroC
 			return prompt.VerifyInit(cmd, args, home, output)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			format := cmd.Flag("output").Value.String()
+			if err := terminal.ValidateFormat(format); err != nil {
+				return err
+			}
+
 			var vers string
 			nitro, err := nitrod.Version(cmd.Context(), &protob.VersionRequest{})
 			if err != nil {
@@ -53,7 +73,21 @@ func NewCommand(home string, client client.CommonAPIClient, nitrod protob.NitroC
 				return fmt.Errorf("unable to get docker server version, %w", err)
 			}
 
-			output.Info(fmt.Sprintf("View the changelog at https://github.com/craftcms/nitro/blob/%s/CHANGELOG.md\n", Version))
+			changelog := fmt.Sprintf("https://github.com/craftcms/nitro/blob/%s/CHANGELOG.md", Version)
+
+			if format == terminal.FormatJSON {
+				return terminal.JSON(cmd.OutOrStdout(), versions{
+					CLI:            Version,
+					GRPC:           vers,
+					DockerAPI:      ver.APIVersion,
+					DockerMinAPI:   ver.MinAPIVersion,
+					DockerClient:   client.ClientVersion(),
+					Changelog:      changelog,
+					UpdateRequired: Version != vers,
+				})
+			}
+
+			output.Info(fmt.Sprintf("View the changelog at %s\n", changelog))
 
 			output.Info("Nitro CLI: \t", Version)
 			output.Info("Nitro gRPC: \t", vers)
@@ -71,5 +105,7 @@ func NewCommand(home string, client client.CommonAPIClient, nitrod protob.NitroC
 		},
 	}
 
+	cmd.Flags().StringP("output", "o", terminal.FormatText, "the output format (text or json)")
+
 	return cmd
 }
@@ -13,12 +13,18 @@ import (
 	"github.com/craftcms/nitro/pkg/terminal"
 )
 
-const exampleText = `  # example command
-  nitro xon`
+const exampleText = `  # enable xdebug for the site in the current directory
+  nitro xon
+
+  # enable xdebug for only one site
+  nitro xon tutorial.nitro`
 
 // NewCommand returns the command that is used to enable xdebug for a specific site. It will first check
 // if the current working directory or prompt the user for a site.
 func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	// hostname is the selected site, which is the only site applied
+	var hostname string
+
 	cmd := &cobra.Command{
 		Use:     "xon",
 		Short:   "Enables Xdebug for a site.",
@@ -37,7 +43,8 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 			return options, cobra.ShellCompDirectiveDefault
 		},
 		PostRunE: func(cmd *cobra.Command, args []string) error {
-			return prompt.RunApply(cmd, args, false, output)
+			// only the changed site container needs to be recreated
+			return prompt.RunApplySite(cmd, hostname, false, output)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// load the config
@@ -114,11 +121,18 @@ func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outp
 				return err
 			}
 
+			// profiling and tracing replace debugging
+			if err := cfg.SetXdebugProfile(site.Hostname, ""); err != nil {
+				return err
+			}
+
 			// save the config
 			if err := cfg.Save(); err != nil {
 				return err
 			}
 
+			hostname = site.Hostname
+
 			return nil
 		},
 	}
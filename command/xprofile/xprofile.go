@@ -0,0 +1,146 @@
+package xprofile
+
+import (
+	"fmt"
+
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/prompt"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+const exampleText = `  # write a cachegrind profile for each request to a site
+  nitro xprofile on tutorial.nitro
+
+  # write a function trace for each request to a site
+  nitro xprofile on tutorial.nitro --trace
+
+  # stop profiling a site
+  nitro xprofile off tutorial.nitro`
+
+// NewCommand returns the command that is used to switch xdebug into profile or trace mode for a
+// site. The files are written to a directory on the host, so they can be opened with tools
+// such as qcachegrind or PhpStorm.
+func NewCommand(home string, docker client.CommonAPIClient, output terminal.Outputer) *cobra.Command {
+	// hostname is the selected site, which is the only site applied
+	var hostname string
+
+	cmd := &cobra.Command{
+		Use:     "xprofile",
+		Short:   "Profiles or traces a site with Xdebug.",
+		Example: exampleText,
+	}
+
+	on := &cobra.Command{
+		Use:               "on",
+		Short:             "Enables Xdebug profiling for a site.",
+		ValidArgsFunction: sitesCompletion(home),
+		PostRunE: func(cmd *cobra.Command, args []string) error {
+			return prompt.RunApplySite(cmd, hostname, false, output)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(home)
+			if err != nil {
+				return err
+			}
+
+			site, err := prompt.SelectSite(cmd, home, cfg, args, output)
+			if err != nil {
+				return err
+			}
+
+			// php 7.0 does not support xdebug
+			if site.Version == "7.0" {
+				return fmt.Errorf("Xdebug with PHP 7.0 is not supported")
+			}
+
+			profile := config.XdebugProfile
+			if cmd.Flag("trace").Value.String() == "true" {
+				profile = config.XdebugTrace
+			}
+
+			// blackfire and xdebug can not profile at the same time
+			if site.Blackfire {
+				if err := cfg.DisableBlackfire(site.Hostname); err != nil {
+					return err
+				}
+			}
+
+			if err := cfg.SetXdebugProfile(site.Hostname, profile); err != nil {
+				return err
+			}
+
+			if err := cfg.Save(); err != nil {
+				return err
+			}
+
+			kind := "Profiles"
+			if profile == config.XdebugTrace {
+				kind = "Traces"
+			}
+
+			output.Info(kind, "for", site.Hostname, "will be written to", site.XdebugDir(home))
+
+			hostname = site.Hostname
+
+			return nil
+		},
+	}
+	on.Flags().Bool("trace", false, "write function traces instead of cachegrind profiles")
+
+	off := &cobra.Command{
+		Use:               "off",
+		Short:             "Disables Xdebug profiling for a site.",
+		ValidArgsFunction: sitesCompletion(home),
+		PostRunE: func(cmd *cobra.Command, args []string) error {
+			return prompt.RunApplySite(cmd, hostname, false, output)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(home)
+			if err != nil {
+				return err
+			}
+
+			site, err := prompt.SelectSite(cmd, home, cfg, args, output)
+			if err != nil {
+				return err
+			}
+
+			if err := cfg.SetXdebugProfile(site.Hostname, ""); err != nil {
+				return err
+			}
+
+			if err := cfg.Save(); err != nil {
+				return err
+			}
+
+			output.Info("Existing files for", site.Hostname, "are in", site.XdebugDir(home))
+
+			hostname = site.Hostname
+
+			return nil
+		},
+	}
+
+	cmd.AddCommand(on, off)
+
+	return cmd
+}
+
+func sitesCompletion(home string) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		cfg, err := config.Load(home)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+
+		var options []string
+		for _, s := range cfg.Sites {
+			options = append(options, s.Hostname)
+		}
+
+		return options, cobra.ShellCompDirectiveNoFileComp
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,6 +14,7 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"sort"
 	"strings"
 	"syscall"
 
@@ -20,12 +22,54 @@ import (
 	"github.com/craftcms/nitro/pkg/database"
 	"github.com/craftcms/nitro/pkg/portavail"
 	"github.com/craftcms/nitro/protob"
+	"golang.org/x/crypto/bcrypt"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
 var Version string
 
+const (
+	// EventSite is sent by ApplyStream when the routes for a site are added
+	EventSite = "site"
+
+	// EventCertificate is sent by ApplyStream when a sites certificate is loaded
+	EventCertificate = "certificate"
+
+	// EventRoute is sent by ApplyStream when caddy is updated with the routes
+	EventRoute = "route"
+
+	// EventDone is the last event sent by ApplyStream
+	EventDone = "done"
+)
+
+const (
+	// CapabilityUpstream is the support for sites with an upstream (e.g. host proxies)
+	CapabilityUpstream = "upstream"
+
+	// CapabilityWebSockets is the support for forwarding websockets to the websockets port of a site
+	CapabilityWebSockets = "websockets"
+
+	// CapabilityCertificates is the support for sites with their own certificate and key
+	CapabilityCertificates = "certificates"
+
+	// CapabilityApplyStream is the support for the ApplyStream RPC
+	CapabilityApplyStream = "apply-stream"
+
+	// CapabilityStatus is the support for the Status RPC
+	CapabilityStatus = "status"
+)
+
+// Capabilities are the features of the API the CLI checks for before using, since the
+// proxy can be older than the CLI. New features of the API should be added to the list.
+var Capabilities = []string{
+	CapabilityUpstream,
+	CapabilityWebSockets,
+	CapabilityCertificates,
+	CapabilityApplyStream,
+	CapabilityStatus,
+}
+
 // NewService takes the address to the Caddy API and returns an API struct that
 // implements the gRPC API used in the proxy container. The gRPC API is used to
 // handle making changes to the Caddy Server via its local API. If no addr is
@@ -102,6 +146,21 @@ func (svc *Service) AddDatabase(ctx context.Context, req *protob.AddDatabaseRequ
 // port for the service. The NGINX container type uses port 8080 and the PHP-FPM container type
 // uses port 9000.
 func (svc *Service) Apply(ctx context.Context, request *protob.ApplyRequest) (*protob.ApplyResponse, error) {
+	return svc.apply(request, func(*protob.ApplyEvent) error { return nil })
+}
+
+// ApplyStream applies the sites like Apply and sends an event as each site, certificate, and the
+// routes are configured. The last event is EventDone with the result of the apply.
+func (svc *Service) ApplyStream(request *protob.ApplyRequest, stream protob.Nitro_ApplyStreamServer) error {
+	resp, err := svc.apply(request, stream.Send)
+	if err != nil {
+		return err
+	}
+
+	return stream.Send(&protob.ApplyEvent{Type: EventDone, Message: resp.GetMessage(), Error: resp.GetError()})
+}
+
+func (svc *Service) apply(request *protob.ApplyRequest, emit func(*protob.ApplyEvent) error) (*protob.ApplyResponse, error) {
 	// if there is no client, use the default
 	if svc.HTTP == nil {
 		svc.HTTP = http.DefaultClient
@@ -114,25 +173,81 @@ func (svc *Service) Apply(ctx context.Context, request *protob.ApplyRequest) (*p
 
 	// convert each of the sites into a route
 	var siteRoutes, nodeRoutes, nodeAltRoutes []caddy.ServerRoute
-	for k, site := range request.GetSites() {
+	var certificates caddy.Certificates
+
+	// sort the sites so the events are in the same order
+	var keys []string
+	for k := range request.GetSites() {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		site := request.GetSites()[k]
+
+		// sites with their own certificate do not use the nitro CA
+		if site.GetCertificate() != "" {
+			certificates.LoadPEM = append(certificates.LoadPEM, caddy.LoadPEM{
+				Certificate: site.GetCertificate(),
+				Key:         site.GetKey(),
+				Tags:        []string{site.GetHostname()},
+			})
+		}
+
 		// get all of the host names for the site
 		hosts := []string{site.GetHostname()}
 		if site.GetAliases() != "" {
 			hosts = append(hosts, strings.Split(site.GetAliases(), ",")...)
 		}
 
-		// create the route for each of the sites
-		siteRoutes = append(siteRoutes, caddy.ServerRoute{
-			Handle: []caddy.RouteHandle{
-				{
+		// check the credentials before proxying to the site
+		var auth []caddy.RouteHandle
+		if site.GetBasicAuthUser() != "" {
+			h, err := basicAuth(site.GetBasicAuthUser(), site.GetBasicAuthPassword())
+			if err != nil {
+				return nil, err
+			}
+
+			auth = append(auth, h)
+		}
+
+		// sites with an upstream are dialed on that host (e.g. a dev server on the host machine)
+		upstream := k
+		if site.GetUpstream() != "" {
+			upstream = site.GetUpstream()
+		}
+
+		// forward websocket upgrades to the sites websocket server before the site
+		if site.GetWebsocketsPort() != 0 {
+			siteRoutes = append(siteRoutes, caddy.ServerRoute{
+				Handle: append(auth, caddy.RouteHandle{
 					Handler: "reverse_proxy",
 					Upstreams: []caddy.Upstream{
 						{
-							Dial: fmt.Sprintf("%s:%d", k, site.GetPort()),
+							Dial: fmt.Sprintf("%s:%d", upstream, site.GetWebsocketsPort()),
 						},
 					},
+				}),
+				Match: []caddy.Match{
+					{
+						Host:   hosts,
+						Header: map[string][]string{"Upgrade": {"websocket"}},
+					},
 				},
-			},
+				Terminal: true,
+			})
+		}
+
+		// create the route for each of the sites
+		siteRoutes = append(siteRoutes, caddy.ServerRoute{
+			Handle: append(auth, caddy.RouteHandle{
+				Handler: "reverse_proxy",
+				Upstreams: []caddy.Upstream{
+					{
+						Dial: fmt.Sprintf("%s:%d", upstream, site.GetPort()),
+					},
+				},
+			}),
 			Match: []caddy.Match{
 				{
 					Host: hosts,
@@ -141,18 +256,25 @@ func (svc *Service) Apply(ctx context.Context, request *protob.ApplyRequest) (*p
 			Terminal: true,
 		})
 
+		if err := emit(&protob.ApplyEvent{Type: EventSite, Hostname: site.GetHostname(), Message: "added the routes for " + strings.Join(hosts, ", ")}); err != nil {
+			return nil, err
+		}
+
+		// the node ports are only proxied to containers
+		if site.GetUpstream() != "" {
+			continue
+		}
+
 		// add the node routes
 		nodeRoutes = append(nodeRoutes, caddy.ServerRoute{
-			Handle: []caddy.RouteHandle{
-				{
-					Handler: "reverse_proxy",
-					Upstreams: []caddy.Upstream{
-						{
-							Dial: fmt.Sprintf("%s:%d", k, 3000),
-						},
+			Handle: append(auth, caddy.RouteHandle{
+				Handler: "reverse_proxy",
+				Upstreams: []caddy.Upstream{
+					{
+						Dial: fmt.Sprintf("%s:%d", k, 3000),
 					},
 				},
-			},
+			}),
 			Match: []caddy.Match{
 				{
 					Host: hosts,
@@ -162,16 +284,14 @@ func (svc *Service) Apply(ctx context.Context, request *protob.ApplyRequest) (*p
 		})
 
 		nodeAltRoutes = append(nodeAltRoutes, caddy.ServerRoute{
-			Handle: []caddy.RouteHandle{
-				{
-					Handler: "reverse_proxy",
-					Upstreams: []caddy.Upstream{
-						{
-							Dial: fmt.Sprintf("%s:%d", k, 3001),
-						},
+			Handle: append(auth, caddy.RouteHandle{
+				Handler: "reverse_proxy",
+				Upstreams: []caddy.Upstream{
+					{
+						Dial: fmt.Sprintf("%s:%d", k, 3001),
 					},
 				},
-			},
+			}),
 			Match: []caddy.Match{
 				{
 					Host: hosts,
@@ -231,6 +351,20 @@ func (svc *Service) Apply(ctx context.Context, request *protob.ApplyRequest) (*p
 		Routes: siteRoutes,
 	}
 
+	// load the certificates before the servers use them
+	if err := svc.loadCertificates(certificates); err != nil {
+		return &protob.ApplyResponse{
+			Message: fmt.Sprintf("Error loading the certificates, err: %s", err.Error()),
+			Error:   true,
+		}, err
+	}
+
+	for _, c := range certificates.LoadPEM {
+		if err := emit(&protob.ApplyEvent{Type: EventCertificate, Hostname: c.Tags[0], Message: "loaded the certificate"}); err != nil {
+			return nil, err
+		}
+	}
+
 	content, err := json.Marshal(&update)
 	if err != nil {
 		return nil, err
@@ -253,6 +387,10 @@ func (svc *Service) Apply(ctx context.Context, request *protob.ApplyRequest) (*p
 		}, nil
 	}
 
+	if err := emit(&protob.ApplyEvent{Type: EventRoute, Message: fmt.Sprintf("updated the routes for %d sites", len(keys))}); err != nil {
+		return nil, err
+	}
+
 	// set the message and error to false
 	return &protob.ApplyResponse{
 		Message: fmt.Sprintf("Successfully applied changes, sites: %d", len(request.GetSites())),
@@ -260,6 +398,67 @@ func (svc *Service) Apply(ctx context.Context, request *protob.ApplyRequest) (*p
 	}, nil
 }
 
+// loadCertificates replaces the certificates caddy loads for the sites with their own certificate.
+// When there are no certificates, any previously loaded certificates are removed.
+func (svc *Service) loadCertificates(certificates caddy.Certificates) error {
+	if len(certificates.LoadPEM) == 0 {
+		req, err := http.NewRequest(http.MethodDelete, svc.Addr+"/config/apps/tls/certificates", nil)
+		if err != nil {
+			return err
+		}
+
+		// the certificates may not have been loaded
+		res, err := svc.HTTP.Do(req)
+		if err != nil {
+			return err
+		}
+		res.Body.Close()
+
+		return nil
+	}
+
+	content, err := json.Marshal(&certificates)
+	if err != nil {
+		return err
+	}
+
+	res, err := svc.HTTP.Post(svc.Addr+"/config/apps/tls/certificates", "application/json", bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("received %d response from Caddy API", res.StatusCode)
+	}
+
+	return nil
+}
+
+// basicAuth returns the handler that requires the user and password for a site. Caddy
+// expects the password to be a base64 encoded bcrypt hash.
+func basicAuth(user, password string) (caddy.RouteHandle, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return caddy.RouteHandle{}, fmt.Errorf("unable to hash the basic auth password, %w", err)
+	}
+
+	return caddy.RouteHandle{
+		Handler: "authentication",
+		Providers: &caddy.Providers{
+			HTTPBasic: caddy.HTTPBasic{
+				Hash: caddy.Hash{Algorithm: "bcrypt"},
+				Accounts: []caddy.Account{
+					{
+						Username: user,
+						Password: base64.StdEncoding.EncodeToString(hash),
+					},
+				},
+			},
+		},
+	}, nil
+}
+
 // ImportDatabase is used to handle streaming requests from the client and import a
 // database from a backup into the remote database container.
 func (svc *Service) ImportDatabase(stream protob.Nitro_ImportDatabaseServer) error {
@@ -471,6 +670,11 @@ func (svc *Service) Version(ctx context.Context, request *protob.VersionRequest)
 	return &protob.VersionResponse{Version: Version}, nil
 }
 
+// Capabilities returns the version and the features the API supports.
+func (svc *Service) Capabilities(ctx context.Context, request *protob.CapabilitiesRequest) (*protob.CapabilitiesResponse, error) {
+	return &protob.CapabilitiesResponse{Version: Version, Capabilities: Capabilities}, nil
+}
+
 func (svc *Service) exec(tool string, commands []string) error {
 	c := exec.Command(tool, commands...)
 
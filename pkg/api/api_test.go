@@ -2,10 +2,17 @@ package api
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
 
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc"
+
+	"github.com/craftcms/nitro/pkg/caddy"
 	"github.com/craftcms/nitro/protob"
 )
 
@@ -92,3 +99,308 @@ func TestService_Version(t *testing.T) {
 		})
 	}
 }
+
+func TestService_ApplyBasicAuth(t *testing.T) {
+	tests := []struct {
+		name     string
+		site     *protob.Site
+		wantAuth bool
+	}{
+		{
+			name: "sites without basic auth only use the proxy",
+			site: &protob.Site{Hostname: "craft-dev.nitro", Port: 8080},
+		},
+		{
+			name:     "sites with basic auth check the credentials first",
+			site:     &protob.Site{Hostname: "craft-dev.nitro", Port: 8080, BasicAuthUser: "nitro", BasicAuthPassword: "secret"},
+			wantAuth: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var update caddy.UpdateRequest
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/config/apps/http/servers" {
+					return
+				}
+
+				if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+					t.Error(err)
+				}
+			}))
+			defer srv.Close()
+
+			svc := &Service{HTTP: srv.Client(), Addr: srv.URL}
+			if _, err := svc.Apply(context.Background(), &protob.ApplyRequest{Sites: map[string]*protob.Site{tt.site.Hostname: tt.site}}); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, s := range []caddy.Server{update.HTTPS, update.Node, update.NodeAlt} {
+				handle := s.Routes[0].Handle
+
+				if !tt.wantAuth {
+					if len(handle) != 1 || handle[0].Handler != "reverse_proxy" {
+						t.Errorf("expected only the reverse proxy handler, got %v", handle)
+					}
+
+					continue
+				}
+
+				if len(handle) != 2 || handle[0].Handler != "authentication" || handle[0].Providers == nil {
+					t.Fatalf("expected the authentication handler first, got %v", handle)
+				}
+
+				account := handle[0].Providers.HTTPBasic.Accounts[0]
+				if account.Username != "nitro" {
+					t.Errorf("expected the username to be nitro, got %s", account.Username)
+				}
+
+				hash, err := base64.StdEncoding.DecodeString(account.Password)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if err := bcrypt.CompareHashAndPassword(hash, []byte("secret")); err != nil {
+					t.Errorf("expected the password hash to match, %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestService_ApplyUpstream(t *testing.T) {
+	tests := []struct {
+		name      string
+		site      *protob.Site
+		wantDial  string
+		wantNodes int
+	}{
+		{
+			name:      "sites are dialed by their key",
+			site:      &protob.Site{Hostname: "craft-dev.nitro", Port: 8080},
+			wantDial:  "craft-dev.nitro:8080",
+			wantNodes: 1,
+		},
+		{
+			name:     "sites with an upstream are dialed on the upstream without node routes",
+			site:     &protob.Site{Hostname: "vite.craft-dev.nitro", Port: 5173, Upstream: "host.docker.internal"},
+			wantDial: "host.docker.internal:5173",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var update caddy.UpdateRequest
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/config/apps/http/servers" {
+					return
+				}
+
+				if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+					t.Error(err)
+				}
+			}))
+			defer srv.Close()
+
+			svc := &Service{HTTP: srv.Client(), Addr: srv.URL}
+			if _, err := svc.Apply(context.Background(), &protob.ApplyRequest{Sites: map[string]*protob.Site{tt.site.Hostname: tt.site}}); err != nil {
+				t.Fatal(err)
+			}
+
+			if dial := update.HTTPS.Routes[0].Handle[0].Upstreams[0].Dial; dial != tt.wantDial {
+				t.Errorf("expected the dial to be %s, got %s", tt.wantDial, dial)
+			}
+
+			if len(update.Node.Routes) != tt.wantNodes || len(update.NodeAlt.Routes) != tt.wantNodes {
+				t.Errorf("expected %d node routes, got %d and %d", tt.wantNodes, len(update.Node.Routes), len(update.NodeAlt.Routes))
+			}
+		})
+	}
+}
+
+func TestService_ApplyWebSockets(t *testing.T) {
+	tests := []struct {
+		name       string
+		site       *protob.Site
+		wantRoutes int
+		wantDial   string
+	}{
+		{
+			name:       "sites without websockets only have the site route",
+			site:       &protob.Site{Hostname: "craft-dev.nitro", Port: 8080},
+			wantRoutes: 1,
+		},
+		{
+			name:       "sites with websockets forward upgrades before the site route",
+			site:       &protob.Site{Hostname: "craft-dev.nitro", Port: 8080, WebsocketsPort: 6001},
+			wantRoutes: 2,
+			wantDial:   "craft-dev.nitro:6001",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var update caddy.UpdateRequest
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/config/apps/http/servers" {
+					return
+				}
+
+				if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+					t.Error(err)
+				}
+			}))
+			defer srv.Close()
+
+			svc := &Service{HTTP: srv.Client(), Addr: srv.URL}
+			if _, err := svc.Apply(context.Background(), &protob.ApplyRequest{Sites: map[string]*protob.Site{tt.site.Hostname: tt.site}}); err != nil {
+				t.Fatal(err)
+			}
+
+			routes := update.HTTPS.Routes
+			if len(routes) != tt.wantRoutes {
+				t.Fatalf("expected %d routes, got %d", tt.wantRoutes, len(routes))
+			}
+
+			if tt.wantDial == "" {
+				return
+			}
+
+			if upgrade := routes[0].Match[0].Header["Upgrade"]; len(upgrade) != 1 || upgrade[0] != "websocket" {
+				t.Errorf("expected the first route to match websocket upgrades, got %v", routes[0].Match[0].Header)
+			}
+
+			if dial := routes[0].Handle[0].Upstreams[0].Dial; dial != tt.wantDial {
+				t.Errorf("expected the dial to be %s, got %s", tt.wantDial, dial)
+			}
+
+			if dial := routes[1].Handle[0].Upstreams[0].Dial; dial != "craft-dev.nitro:8080" {
+				t.Errorf("expected the site route to dial craft-dev.nitro:8080, got %s", dial)
+			}
+		})
+	}
+}
+
+func TestService_ApplyCertificates(t *testing.T) {
+	tests := []struct {
+		name      string
+		sites     map[string]*protob.Site
+		want      []string
+		wantCerts []caddy.LoadPEM
+	}{
+		{
+			name:  "sites without certificates remove the loaded certificates",
+			sites: map[string]*protob.Site{"craft-dev.nitro": {Hostname: "craft-dev.nitro", Port: 8080}},
+			want:  []string{"DELETE /config/apps/tls/certificates", "POST /config/apps/http/servers"},
+		},
+		{
+			name:  "sites with certificates load the certificates before the servers",
+			sites: map[string]*protob.Site{"craft-dev.nitro": {Hostname: "craft-dev.nitro", Port: 8080, Certificate: "cert", Key: "key"}},
+			want:  []string{"POST /config/apps/tls/certificates", "POST /config/apps/http/servers"},
+			wantCerts: []caddy.LoadPEM{
+				{Certificate: "cert", Key: "key", Tags: []string{"craft-dev.nitro"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+			var certificates caddy.Certificates
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.Method+" "+r.URL.Path)
+
+				if r.Method == http.MethodPost && r.URL.Path == "/config/apps/tls/certificates" {
+					if err := json.NewDecoder(r.Body).Decode(&certificates); err != nil {
+						t.Error(err)
+					}
+				}
+			}))
+			defer srv.Close()
+
+			svc := &Service{HTTP: srv.Client(), Addr: srv.URL}
+			if _, err := svc.Apply(context.Background(), &protob.ApplyRequest{Sites: tt.sites}); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(requests, tt.want) {
+				t.Errorf("expected the requests to be %v, got %v", tt.want, requests)
+			}
+
+			if !reflect.DeepEqual(certificates.LoadPEM, tt.wantCerts) {
+				t.Errorf("expected the certificates to be %v, got %v", tt.wantCerts, certificates.LoadPEM)
+			}
+		})
+	}
+}
+
+// mockApplyStreamServer keeps the events sent by ApplyStream
+type mockApplyStreamServer struct {
+	grpc.ServerStream
+
+	events []string
+}
+
+func (m *mockApplyStreamServer) Send(event *protob.ApplyEvent) error {
+	m.events = append(m.events, event.GetType()+" "+event.GetHostname())
+	return nil
+}
+
+func TestService_ApplyStream(t *testing.T) {
+	tests := []struct {
+		name   string
+		sites  map[string]*protob.Site
+		status int
+		want   []string
+	}{
+		{
+			name: "events are sent for each site, certificate, and the routes",
+			sites: map[string]*protob.Site{
+				"site-b.nitro": {Hostname: "site-b.nitro", Port: 8080},
+				"site-a.nitro": {Hostname: "site-a.nitro", Port: 8080, Certificate: "cert", Key: "key"},
+			},
+			status: http.StatusOK,
+			want:   []string{"site site-a.nitro", "site site-b.nitro", "certificate site-a.nitro", "route ", "done "},
+		},
+		{
+			name:   "failed updates only send the done event after the sites",
+			sites:  map[string]*protob.Site{"site-a.nitro": {Hostname: "site-a.nitro", Port: 8080}},
+			status: http.StatusInternalServerError,
+			want:   []string{"site site-a.nitro", "done "},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/config/apps/http/servers" {
+					w.WriteHeader(tt.status)
+				}
+			}))
+			defer srv.Close()
+
+			svc := &Service{HTTP: srv.Client(), Addr: srv.URL}
+			stream := &mockApplyStreamServer{}
+			if err := svc.ApplyStream(&protob.ApplyRequest{Sites: tt.sites}, stream); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(stream.events, tt.want) {
+				t.Errorf("expected the events to be %v, got %v", tt.want, stream.events)
+			}
+		})
+	}
+}
+
+func TestService_Capabilities(t *testing.T) {
+	Version = "2.1.0"
+
+	got, err := (&Service{}).Capabilities(context.Background(), &protob.CapabilitiesRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.GetVersion() != "2.1.0" {
+		t.Errorf("expected the version to be 2.1.0, got %s", got.GetVersion())
+	}
+
+	if !reflect.DeepEqual(got.GetCapabilities(), Capabilities) {
+		t.Errorf("expected the capabilities to be %v, got %v", Capabilities, got.GetCapabilities())
+	}
+}
@@ -0,0 +1,223 @@
+package api
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/craftcms/nitro/pkg/caddy"
+	"github.com/craftcms/nitro/protob"
+)
+
+var (
+	// CertificatesDir is where caddy stores the certificates it issues
+	CertificatesDir = "/data/caddy/certificates"
+
+	// HealthTimeout is how long the proxy waits when connecting to the upstream of a site
+	HealthTimeout = 2 * time.Second
+)
+
+// caddyConfig is the part of the caddy config used to show the status
+type caddyConfig struct {
+	Apps struct {
+		HTTP struct {
+			Servers map[string]caddy.Server `json:"servers"`
+		} `json:"http"`
+		TLS struct {
+			Certificates caddy.Certificates `json:"certificates"`
+		} `json:"tls"`
+	} `json:"apps"`
+}
+
+// Status returns the version of the proxy, the routes configured in caddy, the certificates caddy
+// uses, and if the proxy is able to connect to each of the sites.
+func (svc *Service) Status(ctx context.Context, request *protob.StatusRequest) (*protob.StatusResponse, error) {
+	// if there is no client, use the default
+	if svc.HTTP == nil {
+		svc.HTTP = http.DefaultClient
+	}
+
+	// set the addr if not provided
+	if svc.Addr == "" {
+		svc.Addr = "http://127.0.0.1:2019"
+	}
+
+	res, err := svc.HTTP.Get(svc.Addr + "/config/")
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the caddy config, %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("received %d response from Caddy API", res.StatusCode)
+	}
+
+	// the config is null until the first apply
+	var cfg caddyConfig
+	if err := json.NewDecoder(res.Body).Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("unable to read the caddy config, %w", err)
+	}
+
+	resp := &protob.StatusResponse{Version: Version}
+
+	for _, r := range cfg.Apps.HTTP.Servers["https"].Routes {
+		route := statusRoute(r)
+		if route == nil {
+			continue
+		}
+
+		resp.Routes = append(resp.Routes, route)
+	}
+
+	resp.Sites = checkSites(resp.Routes)
+
+	// sites with their own certificate are loaded into the config
+	for _, p := range cfg.Apps.TLS.Certificates.LoadPEM {
+		c, err := parseCertificate([]byte(p.Certificate))
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse the certificate for %s, %w", strings.Join(p.Tags, ", "), err)
+		}
+
+		resp.Certificates = append(resp.Certificates, c)
+	}
+
+	issued, err := issuedCertificates(CertificatesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Certificates = append(resp.Certificates, issued...)
+
+	return resp, nil
+}
+
+// statusRoute returns the hosts and upstream of a route or nil if the route is not proxied
+// to a site (e.g. the welcome page).
+func statusRoute(r caddy.ServerRoute) *protob.Route {
+	if len(r.Match) == 0 {
+		return nil
+	}
+
+	route := &protob.Route{Hosts: r.Match[0].Host}
+	if _, ok := r.Match[0].Header["Upgrade"]; ok {
+		route.Websocket = true
+	}
+
+	// the basic auth handler is before the reverse proxy
+	for _, h := range r.Handle {
+		if h.Handler == "reverse_proxy" && len(h.Upstreams) > 0 {
+			route.Upstream = h.Upstreams[0].Dial
+		}
+	}
+
+	if route.Upstream == "" {
+		return nil
+	}
+
+	return route
+}
+
+// checkSites connects to the upstream of each route, other than the websocket routes, to
+// check if the site is reachable from the proxy.
+func checkSites(routes []*protob.Route) []*protob.SiteHealth {
+	var sites []*protob.SiteHealth
+	for _, r := range routes {
+		if r.GetWebsocket() || len(r.GetHosts()) == 0 {
+			continue
+		}
+
+		sites = append(sites, &protob.SiteHealth{Hostname: r.GetHosts()[0], Upstream: r.GetUpstream()})
+	}
+
+	var wg sync.WaitGroup
+	for _, s := range sites {
+		wg.Add(1)
+
+		go func(s *protob.SiteHealth) {
+			defer wg.Done()
+
+			conn, err := net.DialTimeout("tcp", s.Upstream, HealthTimeout)
+			if err != nil {
+				s.Message = err.Error()
+				return
+			}
+			conn.Close()
+
+			s.Healthy = true
+		}(s)
+	}
+
+	wg.Wait()
+
+	return sites
+}
+
+// issuedCertificates returns the certificates caddy has issued for the sites in the dir.
+func issuedCertificates(dir string) ([]*protob.Certificate, error) {
+	var certificates []*protob.Certificate
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() || filepath.Ext(path) != ".crt" {
+			return nil
+		}
+
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		c, err := parseCertificate(content)
+		if err != nil {
+			return fmt.Errorf("unable to parse the certificate %s, %w", path, err)
+		}
+
+		certificates = append(certificates, c)
+
+		return nil
+	})
+
+	// caddy has not issued any certificates
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	return certificates, err
+}
+
+// parseCertificate returns the names, issuer, and expiration of the first certificate in the PEM.
+func parseCertificate(content []byte) (*protob.Certificate, error) {
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	c, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	names := c.DNSNames
+	if len(names) == 0 {
+		names = []string{c.Subject.CommonName}
+	}
+
+	return &protob.Certificate{
+		Names:    names,
+		Issuer:   c.Issuer.CommonName,
+		NotAfter: c.NotAfter.Unix(),
+	}, nil
+}
@@ -0,0 +1,196 @@
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/craftcms/nitro/pkg/caddy"
+	"github.com/craftcms/nitro/protob"
+)
+
+func testCertificate(t *testing.T, name string, notAfter time.Time) string {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		Issuer:       pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    notAfter.Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func TestService_Status(t *testing.T) {
+	// the upstream for the healthy site
+	upstream, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer upstream.Close()
+
+	// the upstream for the unhealthy site is closed
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed.Close()
+
+	expires := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+
+	dir, err := ioutil.TempDir("", "nitro-status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := os.MkdirAll(filepath.Join(dir, "local", "issued.nitro"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "local", "issued.nitro", "issued.nitro.crt"), []byte(testCertificate(t, "issued.nitro", expires)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	certs := CertificatesDir
+	CertificatesDir = dir
+	defer func() { CertificatesDir = certs }()
+
+	var cfg caddyConfig
+	cfg.Apps.HTTP.Servers = map[string]caddy.Server{
+		"https": {
+			Routes: []caddy.ServerRoute{
+				{
+					Handle: []caddy.RouteHandle{{Handler: "reverse_proxy", Upstreams: []caddy.Upstream{{Dial: upstream.Addr().String()}}}},
+					Match:  []caddy.Match{{Host: []string{"healthy.nitro"}, Header: map[string][]string{"Upgrade": {"websocket"}}}},
+				},
+				{
+					Handle: []caddy.RouteHandle{{Handler: "authentication"}, {Handler: "reverse_proxy", Upstreams: []caddy.Upstream{{Dial: upstream.Addr().String()}}}},
+					Match:  []caddy.Match{{Host: []string{"healthy.nitro", "alias.nitro"}}},
+				},
+				{
+					Handle: []caddy.RouteHandle{{Handler: "reverse_proxy", Upstreams: []caddy.Upstream{{Dial: closed.Addr().String()}}}},
+					Match:  []caddy.Match{{Host: []string{"unhealthy.nitro"}}},
+				},
+				{
+					Handle: []caddy.RouteHandle{{Handler: "file_server", Root: "/var/www/html"}},
+				},
+			},
+		},
+	}
+	cfg.Apps.TLS.Certificates.LoadPEM = []caddy.LoadPEM{
+		{Certificate: testCertificate(t, "loaded.nitro", expires), Tags: []string{"loaded.nitro"}},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/config/" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(&cfg); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer srv.Close()
+
+	svc := &Service{HTTP: srv.Client(), Addr: srv.URL}
+	got, err := svc.Status(context.Background(), &protob.StatusRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.GetVersion() != Version {
+		t.Errorf("expected the version to be %q, got %q", Version, got.GetVersion())
+	}
+
+	var routes []string
+	for _, r := range got.GetRoutes() {
+		routes = append(routes, r.String())
+	}
+
+	wantRoutes := []string{
+		(&protob.Route{Hosts: []string{"healthy.nitro"}, Upstream: upstream.Addr().String(), Websocket: true}).String(),
+		(&protob.Route{Hosts: []string{"healthy.nitro", "alias.nitro"}, Upstream: upstream.Addr().String()}).String(),
+		(&protob.Route{Hosts: []string{"unhealthy.nitro"}, Upstream: closed.Addr().String()}).String(),
+	}
+	if !reflect.DeepEqual(routes, wantRoutes) {
+		t.Errorf("expected the routes to be %v, got %v", wantRoutes, routes)
+	}
+
+	var health []string
+	for _, s := range got.GetSites() {
+		health = append(health, s.GetHostname()+" "+s.GetUpstream())
+
+		if want := s.GetHostname() == "healthy.nitro"; s.GetHealthy() != want {
+			t.Errorf("expected %s healthy to be %v, got %v (%s)", s.GetHostname(), want, s.GetHealthy(), s.GetMessage())
+		}
+	}
+
+	wantHealth := []string{"healthy.nitro " + upstream.Addr().String(), "unhealthy.nitro " + closed.Addr().String()}
+	if !reflect.DeepEqual(health, wantHealth) {
+		t.Errorf("expected the sites to be %v, got %v", wantHealth, health)
+	}
+
+	var certificates []string
+	for _, c := range got.GetCertificates() {
+		certificates = append(certificates, c.String())
+	}
+
+	wantCertificates := []string{
+		(&protob.Certificate{Names: []string{"loaded.nitro"}, Issuer: "loaded.nitro", NotAfter: expires.Unix()}).String(),
+		(&protob.Certificate{Names: []string{"issued.nitro"}, Issuer: "issued.nitro", NotAfter: expires.Unix()}).String(),
+	}
+	if !reflect.DeepEqual(certificates, wantCertificates) {
+		t.Errorf("expected the certificates to be %v, got %v", wantCertificates, certificates)
+	}
+}
+
+func TestService_StatusWithoutConfig(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("null\n"))
+	}))
+	defer srv.Close()
+
+	certs := CertificatesDir
+	CertificatesDir = filepath.Join(os.TempDir(), "nitro-status-does-not-exist")
+	defer func() { CertificatesDir = certs }()
+
+	svc := &Service{HTTP: srv.Client(), Addr: srv.URL}
+	got, err := svc.Status(context.Background(), &protob.StatusRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got.GetRoutes()) != 0 || len(got.GetSites()) != 0 || len(got.GetCertificates()) != 0 {
+		t.Errorf("expected no routes, sites, or certificates, got %v", got)
+	}
+}
@@ -0,0 +1,150 @@
+package apitoken
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/craftcms/nitro/pkg/config"
+)
+
+const (
+	// EnvironmentVariable is used to pass the token to nitrod in the proxy container
+	EnvironmentVariable = "NITRO_API_TOKEN"
+
+	// FileName is the name of the file in the nitro directory the token is stored in
+	FileName = "nitrod.token"
+
+	// metadataKey is the gRPC metadata the client sends the token in
+	metadataKey = "authorization"
+)
+
+// ErrUnauthenticated is returned by nitrod when a request does not have the token
+var ErrUnauthenticated = status.Error(codes.Unauthenticated, "the request does not have a valid token for the nitrod API, run `nitro init` to resolve")
+
+// Path returns the path to the token file in the users home directory.
+func Path(home string) string {
+	return filepath.Join(home, config.DirectoryName, FileName)
+}
+
+// Load returns the token from the users home directory or an empty token if the file does not exist.
+func Load(home string) (string, error) {
+	content, err := ioutil.ReadFile(Path(home))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to read the token for the nitrod API, %w", err)
+	}
+
+	return strings.TrimSpace(string(content)), nil
+}
+
+// LoadOrCreate returns the token from the users home directory and generates a new token, which
+// only the user can read, when there is not one.
+func LoadOrCreate(home string) (string, error) {
+	token, err := Load(home)
+	if err != nil || token != "" {
+		return token, err
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("unable to generate a token for the nitrod API, %w", err)
+	}
+
+	token = hex.EncodeToString(b)
+
+	if err := os.MkdirAll(filepath.Dir(Path(home)), 0755); err != nil {
+		return "", fmt.Errorf("unable to create the nitro directory, %w", err)
+	}
+
+	if err := ioutil.WriteFile(Path(home), []byte(token+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("unable to save the token for the nitrod API, %w", err)
+	}
+
+	return token, nil
+}
+
+// Hash returns a short hash of the token, which is used to label the proxy container
+// so the proxy is recreated when the token changes without exposing the token.
+func Hash(token string) string {
+	if token == "" {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// Credentials sends the token from the users home directory with each request to nitrod. The
+// token is read for each request since it can be created after the client.
+type Credentials struct {
+	Home string
+}
+
+// GetRequestMetadata returns the token as a bearer token, no metadata is sent without a token.
+func (c Credentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	token, err := Load(c.Home)
+	if err != nil || token == "" {
+		return nil, err
+	}
+
+	return map[string]string{metadataKey: "Bearer " + token}, nil
+}
+
+// RequireTransportSecurity is false since the API is only reached on the loopback
+// interface or through docker exec.
+func (c Credentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// UnaryServerInterceptor rejects unary requests that do not have the token.
+func UnaryServerInterceptor(token string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := authorize(ctx, token); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor rejects streaming requests that do not have the token.
+func StreamServerInterceptor(token string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := authorize(ss.Context(), token); err != nil {
+			return err
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+func authorize(ctx context.Context, token string) error {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+
+	for _, v := range md.Get(metadataKey) {
+		if subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(v, "Bearer ")), []byte(token)) == 1 {
+			return nil
+		}
+	}
+
+	return ErrUnauthenticated
+}
@@ -0,0 +1,127 @@
+package apitoken
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestLoadOrCreate(t *testing.T) {
+	home := t.TempDir()
+
+	// there is no token until one is created
+	if token, err := Load(home); err != nil || token != "" {
+		t.Fatalf("expected no token, got %q, %v", token, err)
+	}
+
+	token, err := LoadOrCreate(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(token) != 64 {
+		t.Errorf("expected the token to be 64 characters, got %d", len(token))
+	}
+
+	info, err := os.Stat(Path(home))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Errorf("expected the token to only be readable by the user, got %v", info.Mode().Perm())
+	}
+
+	// the existing token is used
+	again, err := LoadOrCreate(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if again != token {
+		t.Errorf("expected the token to be %q, got %q", token, again)
+	}
+}
+
+func TestCredentials_GetRequestMetadata(t *testing.T) {
+	home := t.TempDir()
+
+	md, err := Credentials{Home: home}.GetRequestMetadata(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(md) != 0 {
+		t.Errorf("expected no metadata without a token, got %v", md)
+	}
+
+	token, err := LoadOrCreate(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	md, err = Credentials{Home: home}.GetRequestMetadata(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if md[metadataKey] != "Bearer "+token {
+		t.Errorf("expected the token to be sent, got %v", md)
+	}
+}
+
+func Test_authorize(t *testing.T) {
+	tests := []struct {
+		name    string
+		md      metadata.MD
+		wantErr bool
+	}{
+		{
+			name: "requests with the token are authorized",
+			md:   metadata.Pairs(metadataKey, "Bearer secret"),
+		},
+		{
+			name:    "requests with another token are unauthenticated",
+			md:      metadata.Pairs(metadataKey, "Bearer other"),
+			wantErr: true,
+		},
+		{
+			name:    "requests without a token are unauthenticated",
+			md:      metadata.MD{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+
+			err := authorize(ctx, "secret")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("authorize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr && status.Code(err) != codes.Unauthenticated {
+				t.Errorf("expected the error code to be unauthenticated, got %v", status.Code(err))
+			}
+		})
+	}
+}
+
+func TestHash(t *testing.T) {
+	if Hash("") != "" {
+		t.Error("expected an empty token to have an empty hash")
+	}
+
+	if Hash("secret") == Hash("other") {
+		t.Error("expected the hashes of different tokens to be different")
+	}
+
+	if len(Hash("secret")) != 12 {
+		t.Errorf("expected the hash to be 12 characters, got %d", len(Hash("secret")))
+	}
+}
@@ -1,35 +1,44 @@
 package backup
 
 import (
-	"archive/tar"
 	"bytes"
+	"compress/gzip"
 	"context"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
 
 	"github.com/craftcms/nitro/pkg/config"
 	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/datetime"
 	"github.com/craftcms/nitro/pkg/helpers"
 	"github.com/craftcms/nitro/pkg/terminal"
 )
 
 // Options are used to pass options to a database backup func.
-// The options contain information such as the container, home
-// directory, and database to backup.
+// The options contain information such as the container, database
+// to backup, and where to write the backup.
 type Options struct {
-	Home          string
 	ContainerID   string
 	ContainerName string
 	Database      string
 	BackupName    string
 	Commands      []string
+
+	// Writer is where the backup is written, such as a file or stdout.
+	// Use ToFile to save the backup in the backups directory.
+	Writer io.Writer
+
+	// Storage is the bucket ToFile uploads the backup to when the
+	// storage upload setting is true.
+	Storage *config.Storage
 }
 
 func (o *Options) Validate() error {
@@ -53,13 +62,38 @@ func (o *Options) Validate() error {
 	if o.Database == "" {
 		return fmt.Errorf("invalid database")
 	}
-	if o.Home == "" {
-		return fmt.Errorf("invalid home path")
+	if o.Writer == nil {
+		return fmt.Errorf("invalid writer")
 	}
 
 	return nil
 }
 
+// Commands returns the commands to backup a database to stdout based on the
+// compatibility of the engine (e.g. mysql, postgres, or mongodb).
+func Commands(compatibility, db string) []string {
+	switch compatibility {
+	case "postgres":
+		return []string{"pg_dump", "--username=nitro", db}
+	case "mongodb":
+		return []string{"mongodump", "--username=nitro", "--password=nitro", "--authenticationDatabase=admin", "--db=" + db, "--archive"}
+	default:
+		return []string{"/usr/bin/mysqldump", "-h", "127.0.0.1", "-unitro", "--password=nitro", db}
+	}
+}
+
+// FileName returns the name for a backup of the database, mongodb backups
+// use the mongodump archive format and sql databases use a sql file. The
+// backups are compressed with gzip.
+func FileName(compatibility, db string, t time.Time) string {
+	ext := "sql"
+	if compatibility == "mongodb" {
+		ext = "archive"
+	}
+
+	return fmt.Sprintf("%s-%s.%s.gz", db, datetime.Parse(t), ext)
+}
+
 // Prompt is used to ask a user for input and walk them through selecting a database engine (container) and a database. It will return the container ID
 // as the first string, the database name, and the last return is an error.
 func Prompt(ctx context.Context, reader io.Reader, docker client.ContainerAPIClient, output terminal.Outputer, containers []types.Container, containerList []string) (string, string, string, string, error) {
@@ -101,52 +135,34 @@ func Prompt(ctx context.Context, reader io.Reader, docker client.ContainerAPICli
 	return id, name, compatibility, db, nil
 }
 
+// DatabasesCommands returns the commands to list the databases based on the
+// compatibility of the engine (e.g. mysql, postgres, or mongodb).
+func DatabasesCommands(compatibility string) []string {
+	switch compatibility {
+	case "mysql":
+		return []string{"mysql", "-unitro", "-pnitro", "-e", `SHOW DATABASES;`}
+	case "mongodb":
+		// newer images only include mongosh
+		return []string{"sh", "-c", `$(command -v mongosh || command -v mongo) --quiet --username nitro --password nitro --authenticationDatabase admin --eval "db.adminCommand('listDatabases').databases.forEach(function (d) { print(d.name) })"`}
+	default:
+		return []string{"psql", "--username=nitro", "--command", `SELECT datname FROM pg_database WHERE datistemplate = false;`}
+	}
+}
+
 // Databases is used to get a list of all the databases for a specific engine. It is returned as a slice of strings using the
 // containers hostname (e.g. mysql-8.0-3306) so it can be presented to the user as a list.
 func Databases(ctx context.Context, docker client.ContainerAPIClient, containerID, compatibility string) ([]string, error) {
-	// get a list of the databases from the container
-	var commands []string
-	if compatibility == "mysql" {
-		// get a list of the mysql databases
-		commands = []string{"mysql", "-unitro", "-pnitro", "-e", `SHOW DATABASES;`}
-	} else {
-		commands = []string{"psql", "--username=nitro", "--command", `SELECT datname FROM pg_database WHERE datistemplate = false;`}
-	}
-
-	// create the command and pass to exec
-	exec, err := docker.ContainerExecCreate(ctx, containerID, types.ExecConfig{
-		AttachStdout: true,
-		AttachStderr: true,
-		Tty:          false,
-		Cmd:          commands,
-	})
+	// run the commands in the container
+	out, err := Exec(ctx, docker, containerID, DatabasesCommands(compatibility))
 	if err != nil {
 		return nil, err
 	}
 
-	// attach to the container
-	resp, err := docker.ContainerExecAttach(ctx, exec.ID, types.ExecStartCheck{Tty: false})
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Close()
-
-	// start the exec
-	if err := docker.ContainerExecStart(ctx, exec.ID, types.ExecStartCheck{}); err != nil {
-		return nil, fmt.Errorf("unable to start the container, %w", err)
-	}
-
-	// get the output
-	buf := new(bytes.Buffer)
-	if _, err := io.Copy(buf, resp.Reader); err != nil {
-		return nil, err
-	}
-
 	var databases []string
 	switch compatibility {
 	case "mysql":
 		// get all the databases from the mysql engine
-		for _, d := range strings.Split(buf.String(), "\n") {
+		for _, d := range strings.Split(out, "\n") {
 			// ignore the system defaults
 			if d == "Database" || strings.Contains(d, `"Database`) || strings.Contains(d, `?Database`) || strings.Contains(d, `[Database`) || d == "information_schema" || d == "performance_schema" || d == "sys" || strings.Contains(d, "password on the command line") || d == "mysql" || d == "" {
 				continue
@@ -154,9 +170,21 @@ func Databases(ctx context.Context, docker client.ContainerAPIClient, containerI
 
 			databases = append(databases, strings.TrimSpace(d))
 		}
+	case "mongodb":
+		// get all the databases from the mongodb engine
+		for _, d := range strings.Split(out, "\n") {
+			d = strings.TrimSpace(d)
+
+			// ignore the system databases
+			if d == "" || d == "admin" || d == "config" || d == "local" {
+				continue
+			}
+
+			databases = append(databases, d)
+		}
 	default:
 		// get all the databases from the postgres engine
-		sp := strings.Split(buf.String(), "\n")
+		sp := strings.Split(out, "\n")
 		for i, d := range sp {
 			// remove the first, second, last, rows, and empty lines
 			if i == 0 || i == 1 || i == len(sp) || strings.Contains(d, "rows)") || d == "" {
@@ -170,15 +198,62 @@ func Databases(ctx context.Context, docker client.ContainerAPIClient, containerI
 	return databases, nil
 }
 
+// Exec runs the commands in a container and returns the output from the commands. It is used
+// to run database commands, such as listing databases, in the database containers. When the
+// commands exit with an error, the error output is returned as the error.
+func Exec(ctx context.Context, docker client.ContainerAPIClient, containerID string, commands []string) (string, error) {
+	// create the command and pass to exec
+	exec, err := docker.ContainerExecCreate(ctx, containerID, types.ExecConfig{
+		AttachStdout: true,
+		AttachStderr: true,
+		Tty:          false,
+		Cmd:          commands,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// attach to the container
+	resp, err := docker.ContainerExecAttach(ctx, exec.ID, types.ExecStartCheck{Tty: false})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Close()
+
+	// start the exec
+	if err := docker.ContainerExecStart(ctx, exec.ID, types.ExecStartCheck{}); err != nil {
+		return "", fmt.Errorf("unable to start the container, %w", err)
+	}
+
+	// get the output without the stream headers
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	if _, err := stdcopy.StdCopy(stdout, stderr, resp.Reader); err != nil {
+		return "", err
+	}
+
+	// reading the output waits for the exec to complete, so check if the commands failed
+	info, err := docker.ContainerExecInspect(ctx, exec.ID)
+	if err != nil {
+		return "", err
+	}
+
+	if info.ExitCode != 0 {
+		return "", fmt.Errorf("the command exited with code %d, %s", info.ExitCode, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
+
 // Perform is used to perform a backup for a database container, it does not prompt the user as it assumed the Prompt func above
 // is used to determine the engine (container) and the specific database to backup. Perform accepts the backup commands and is
-// agnostic to the database engine for the requested backup.
+// agnostic to the database engine for the requested backup. The output of the commands is streamed to the writer, so the backup
+// is not written to disk in the container.
 func Perform(ctx context.Context, docker client.ContainerAPIClient, opts *Options) error {
 	if err := opts.Validate(); err != nil {
 		return err
 	}
 
-	// create the backup in the container
+	// run the backup in the container
 	exec, err := docker.ContainerExecCreate(ctx, opts.ContainerID, types.ExecConfig{
 		AttachStdout: true,
 		AttachStderr: true,
@@ -201,45 +276,31 @@ func Perform(ctx context.Context, docker client.ContainerAPIClient, opts *Option
 		return fmt.Errorf("unable to start the container exec, %w", err)
 	}
 
-	// wait for the container exec to complete
-	waiting := true
-	for waiting {
-		resp, err := docker.ContainerExecInspect(ctx, exec.ID)
-		if err != nil {
-			return err
-		}
-
-		waiting = resp.Running
+	// stream the backup from stdout, stderr is kept for errors
+	stderr := new(bytes.Buffer)
+	if _, err := stdcopy.StdCopy(opts.Writer, stderr, resp.Reader); err != nil {
+		return err
 	}
 
-	// copy the backup from the container into the host machine
-	rdr, stat, err := docker.CopyFromContainer(ctx, opts.ContainerID, "/tmp/"+opts.BackupName)
-	if err != nil || !stat.Mode.IsRegular() {
+	// reading the output waits for the exec to complete, so check if the backup failed
+	info, err := docker.ContainerExecInspect(ctx, exec.ID)
+	if err != nil {
 		return err
 	}
-	defer rdr.Close()
-
-	// read the content of the file, the file is in a tar format
-	buf := new(bytes.Buffer)
-	tr := tar.NewReader(rdr)
 
-	for {
-		_, err := tr.Next()
-		// if end of tar archive
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return err
-		}
-
-		if _, err := buf.ReadFrom(tr); err != nil {
-			return err
-		}
+	if info.ExitCode != 0 {
+		return fmt.Errorf("the backup exited with code %d, %s", info.ExitCode, strings.TrimSpace(stderr.String()))
 	}
 
+	return nil
+}
+
+// ToFile performs the backup and saves it in the backups directory for the container
+// (e.g. ~/.nitro/backups/mysql-8.0-3306.database.nitro) using gzip compression. If the
+// backup fails, the file is removed.
+func ToFile(ctx context.Context, docker client.ContainerAPIClient, home string, opts *Options) error {
 	// verify the backup dir exists
-	backupDir := filepath.Join(opts.Home, config.DirectoryName, "backups")
+	backupDir := filepath.Join(home, config.DirectoryName, "backups")
 	if err := helpers.MkdirIfNotExists(backupDir); err != nil {
 		return err
 	}
@@ -250,10 +311,47 @@ func Perform(ctx context.Context, docker client.ContainerAPIClient, opts *Option
 		return err
 	}
 
-	// write the file to the backups dir
-	if err := ioutil.WriteFile(filepath.Join(dir, opts.BackupName), buf.Bytes(), 0644); err != nil {
+	file := filepath.Join(dir, opts.BackupName)
+
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(f)
+	opts.Writer = gz
+
+	if err := Perform(ctx, docker, opts); err != nil {
+		gz.Close()
+		f.Close()
+		os.Remove(file)
+
 		return err
 	}
 
+	if err := gz.Close(); err != nil {
+		f.Close()
+		os.Remove(file)
+
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if opts.Storage == nil || !opts.Storage.Upload {
+		return nil
+	}
+
+	storage, err := NewStorage(*opts.Storage)
+	if err != nil {
+		return err
+	}
+
+	if err := upload(ctx, storage, Key(opts.Storage.Prefix, File{Container: opts.ContainerName, Name: opts.BackupName}), file); err != nil {
+		return fmt.Errorf("the backup was saved but could not be uploaded, %w", err)
+	}
+
 	return nil
 }
@@ -0,0 +1,131 @@
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+type mockDockerClient struct {
+	client.ContainerAPIClient
+
+	// execCommands are the commands passed to exec create
+	execCommands [][]string
+
+	// execOutput is written to stdout of the exec
+	execOutput string
+
+	// execError is written to stderr of the exec
+	execError string
+
+	// execExitCode is the exit code returned when inspecting the exec
+	execExitCode int
+
+	// copiedFiles are the files copied to the container, by name
+	copiedFiles map[string][]byte
+
+	// mockError allows us to override any func to return a method, we do not
+	// set the error by default.
+	mockError error
+}
+
+func (c *mockDockerClient) ContainerExecCreate(ctx context.Context, container string, config types.ExecConfig) (types.IDResponse, error) {
+	c.execCommands = append(c.execCommands, config.Cmd)
+
+	return types.IDResponse{ID: "exec-id"}, c.mockError
+}
+
+func (c *mockDockerClient) ContainerExecAttach(ctx context.Context, execID string, config types.ExecStartCheck) (types.HijackedResponse, error) {
+	conn, _ := net.Pipe()
+
+	// the output is multiplexed like the docker api
+	out := &bytes.Buffer{}
+	if c.execOutput != "" {
+		stdcopy.NewStdWriter(out, stdcopy.Stdout).Write([]byte(c.execOutput))
+	}
+
+	if c.execError != "" {
+		stdcopy.NewStdWriter(out, stdcopy.Stderr).Write([]byte(c.execError))
+	}
+
+	return types.HijackedResponse{Conn: conn, Reader: bufio.NewReader(out)}, c.mockError
+}
+
+func (c *mockDockerClient) ContainerExecStart(ctx context.Context, execID string, config types.ExecStartCheck) error {
+	return c.mockError
+}
+
+func (c *mockDockerClient) ContainerExecInspect(ctx context.Context, execID string) (types.ContainerExecInspect, error) {
+	return types.ContainerExecInspect{Running: false, ExitCode: c.execExitCode}, c.mockError
+}
+
+func (c *mockDockerClient) CopyToContainer(ctx context.Context, container, path string, content io.Reader, options types.CopyToContainerOptions) error {
+	if c.copiedFiles == nil {
+		c.copiedFiles = make(map[string][]byte)
+	}
+
+	tr := tar.NewReader(content)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+
+		c.copiedFiles[path+"/"+hdr.Name] = data
+	}
+
+	return c.mockError
+}
+
+type mockStorage struct {
+	// objects are the objects in the bucket by key
+	objects map[string][]byte
+}
+
+func (s *mockStorage) Put(ctx context.Context, key string, content io.ReadSeeker) error {
+	data, err := ioutil.ReadAll(content)
+	if err != nil {
+		return err
+	}
+
+	s.objects[key] = data
+
+	return nil
+}
+
+func (s *mockStorage) Get(ctx context.Context, key string, w io.Writer) error {
+	_, err := w.Write(s.objects[key])
+
+	return err
+}
+
+func (s *mockStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	for k := range s.objects {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys, nil
+}
@@ -0,0 +1,275 @@
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPerform(t *testing.T) {
+	dump := []byte("CREATE TABLE `users` (`id` int);\n")
+
+	t.Run("backups are written to an in-memory buffer", func(t *testing.T) {
+		mock := &mockDockerClient{execOutput: string(dump)}
+		buf := &bytes.Buffer{}
+
+		opts := &Options{
+			BackupName:    "nitro.sql",
+			ContainerID:   "database-id",
+			ContainerName: "mysql-8.0-3306.database.nitro",
+			Database:      "nitro",
+			Commands:      []string{"mysqldump", "nitro"},
+			Writer:        buf,
+		}
+
+		if err := Perform(context.Background(), mock, opts); err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(buf.Bytes(), dump) {
+			t.Errorf("expected the writer to contain %q, got %q", dump, buf.String())
+		}
+
+		if !reflect.DeepEqual(mock.execCommands, [][]string{opts.Commands}) {
+			t.Errorf("expected the commands to be %v, got %v", opts.Commands, mock.execCommands)
+		}
+	})
+
+	t.Run("failed backups return an error", func(t *testing.T) {
+		mock := &mockDockerClient{execExitCode: 2}
+
+		opts := &Options{
+			BackupName:    "nitro.sql",
+			ContainerID:   "database-id",
+			ContainerName: "mysql-8.0-3306.database.nitro",
+			Database:      "nitro",
+			Commands:      []string{"mysqldump", "nitro"},
+			Writer:        &bytes.Buffer{},
+		}
+
+		if err := Perform(context.Background(), mock, opts); err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+
+	t.Run("backups are saved in the backups directory as a compressed file", func(t *testing.T) {
+		home, err := ioutil.TempDir("", "nitro-backup")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(home)
+
+		// the nitro directory is created by nitro init
+		if err := os.Mkdir(filepath.Join(home, ".nitro"), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		mock := &mockDockerClient{execOutput: string(dump)}
+
+		opts := &Options{
+			BackupName:    "nitro.sql.gz",
+			ContainerID:   "database-id",
+			ContainerName: "mysql-8.0-3306.database.nitro",
+			Database:      "nitro",
+			Commands:      []string{"mysqldump", "nitro"},
+		}
+
+		if err := ToFile(context.Background(), mock, home, opts); err != nil {
+			t.Fatal(err)
+		}
+
+		f, err := os.Open(filepath.Join(home, ".nitro", "backups", "mysql-8.0-3306.database.nitro", "nitro.sql.gz"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		content, err := ioutil.ReadAll(gz)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(content, dump) {
+			t.Errorf("expected the backup to contain %q, got %q", dump, content)
+		}
+	})
+}
+
+func TestOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    *Options
+		wantErr bool
+	}{
+		{
+			name:    "nil options return an error",
+			wantErr: true,
+		},
+		{
+			name: "options with a writer are valid",
+			opts: &Options{
+				BackupName:    "nitro.sql",
+				ContainerID:   "database-id",
+				ContainerName: "mysql-8.0-3306.database.nitro",
+				Database:      "nitro",
+				Commands:      []string{"mysqldump"},
+				Writer:        &bytes.Buffer{},
+			},
+		},
+		{
+			name: "a writer is required",
+			opts: &Options{
+				BackupName:    "nitro.sql",
+				ContainerID:   "database-id",
+				ContainerName: "mysql-8.0-3306.database.nitro",
+				Database:      "nitro",
+				Commands:      []string{"mysqldump"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestToFileRemovesFailedBackups(t *testing.T) {
+	home, err := ioutil.TempDir("", "nitro-backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+
+	if err := os.Mkdir(filepath.Join(home, ".nitro"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	mock := &mockDockerClient{mockError: errors.New("exec failed")}
+
+	opts := &Options{
+		BackupName:    "nitro.sql",
+		ContainerID:   "database-id",
+		ContainerName: "mysql-8.0-3306.database.nitro",
+		Database:      "nitro",
+		Commands:      []string{"mysqldump", "nitro"},
+	}
+
+	if err := ToFile(context.Background(), mock, home, opts); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if _, err := os.Stat(filepath.Join(home, ".nitro", "backups", "mysql-8.0-3306.database.nitro", "nitro.sql")); !os.IsNotExist(err) {
+		t.Errorf("expected the failed backup to be removed, got %v", err)
+	}
+}
+
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		name          string
+		compatibility string
+		want          []string
+	}{
+		{
+			name:          "mysql databases use mysqldump",
+			compatibility: "mysql",
+			want:          []string{"/usr/bin/mysqldump", "-h", "127.0.0.1", "-unitro", "--password=nitro", "nitro"},
+		},
+		{
+			name:          "postgres databases use pg_dump",
+			compatibility: "postgres",
+			want:          []string{"pg_dump", "--username=nitro", "nitro"},
+		},
+		{
+			name:          "mongodb databases use mongodump",
+			compatibility: "mongodb",
+			want:          []string{"mongodump", "--username=nitro", "--password=nitro", "--authenticationDatabase=admin", "--db=nitro", "--archive"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Commands(tt.compatibility, "nitro"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Commands() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileName(t *testing.T) {
+	now := time.Date(2021, time.March, 4, 10, 30, 0, 0, time.UTC)
+
+	if got := FileName("mysql", "nitro", now); got != "nitro-2021-03-04-103000.sql.gz" {
+		t.Errorf("expected mysql backups to use .sql.gz, got %s", got)
+	}
+
+	if got := FileName("mongodb", "nitro", now); got != "nitro-2021-03-04-103000.archive.gz" {
+		t.Errorf("expected mongodb backups to use .archive.gz, got %s", got)
+	}
+}
+
+func TestDatabasesMongoDB(t *testing.T) {
+	mock := &mockDockerClient{execOutput: "admin\nconfig\nlocal\nnitro\ncraft\n"}
+
+	got, err := Databases(context.Background(), mock, "database-id", "mongodb")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"nitro", "craft"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Databases() = %v, want %v", got, want)
+	}
+}
+
+func TestExec(t *testing.T) {
+	tests := []struct {
+		name    string
+		mock    *mockDockerClient
+		want    string
+		wantErr string
+	}{
+		{
+			name: "the output is returned when the commands succeed",
+			mock: &mockDockerClient{execOutput: "users\nentries\n", execError: "mysql: [Warning] Using a password on the command line interface can be insecure.\n"},
+			want: "users\nentries\n",
+		},
+		{
+			name:    "the error output is returned when the commands fail",
+			mock:    &mockDockerClient{execError: "ERROR 1049 (42000): Unknown database 'missing'\n", execExitCode: 1},
+			wantErr: "the command exited with code 1, ERROR 1049 (42000): Unknown database 'missing'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Exec(context.Background(), tt.mock, "database-id", []string{"mysql"})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("Exec() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tt.want {
+				t.Errorf("Exec() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
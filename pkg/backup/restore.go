@@ -0,0 +1,261 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+
+	"github.com/craftcms/nitro/pkg/config"
+)
+
+// nameRegex matches the backup names created with FileName
+var nameRegex = regexp.MustCompile(`^(.+)-(\d{4}-\d{2}-\d{2}-\d{6})\.(sql|archive)(\.gz)?$`)
+
+// File is a backup in the backups directory.
+type File struct {
+	// Container is the hostname of the database container (e.g. mysql-8.0-3306)
+	Container string
+
+	// Database is the name of the database that was backed up
+	Database string
+
+	// Name is the name of the backup file
+	Name string
+
+	// Path is the full path to the backup file
+	Path string
+
+	// Size is the size of the backup file in bytes
+	Size int64
+
+	// CreatedAt is when the backup was created, based on the file name
+	CreatedAt time.Time
+}
+
+// List returns the backups in the backups directory for each container, newest first. Files
+// that were not created by nitro are ignored.
+func List(home string) ([]File, error) {
+	dir := filepath.Join(home, config.DirectoryName, "backups")
+
+	containers, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []File
+	for _, c := range containers {
+		if !c.IsDir() {
+			continue
+		}
+
+		files, err := ioutil.ReadDir(filepath.Join(dir, c.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, f := range files {
+			matches := nameRegex.FindStringSubmatch(f.Name())
+			if f.IsDir() || matches == nil {
+				continue
+			}
+
+			created, err := time.ParseInLocation("2006-01-02-150405", matches[2], time.Local)
+			if err != nil {
+				continue
+			}
+
+			backups = append(backups, File{
+				Container: c.Name(),
+				Database:  matches[1],
+				Name:      f.Name(),
+				Path:      filepath.Join(dir, c.Name(), f.Name()),
+				Size:      f.Size(),
+				CreatedAt: created,
+			})
+		}
+	}
+
+	sort.SliceStable(backups, func(i, j int) bool {
+		if backups[i].Container != backups[j].Container {
+			return backups[i].Container < backups[j].Container
+		}
+
+		if backups[i].Database != backups[j].Database {
+			return backups[i].Database < backups[j].Database
+		}
+
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+
+	return backups, nil
+}
+
+// RestoreCommands returns the commands to restore a backup file in the container into the
+// database based on the compatibility of the engine. The database is created if it does not
+// exist and compressed backups are decompressed in the container. For mongodb, from is the
+// name of the database in the backup archive.
+func RestoreCommands(compatibility, from, db, file string) []string {
+	input := fmt.Sprintf("cat %q", file)
+	if strings.HasSuffix(file, ".gz") {
+		input = fmt.Sprintf("gunzip -c %q", file)
+	}
+
+	switch compatibility {
+	case "postgres":
+		return []string{"sh", "-c", fmt.Sprintf(`createdb --username=nitro %q 2>/dev/null; %s | psql --username=nitro --dbname=%q --quiet`, db, input, db)}
+	case "mongodb":
+		return []string{"sh", "-c", fmt.Sprintf(`%s | mongorestore --username=nitro --password=nitro --authenticationDatabase=admin --drop --archive --nsFrom=%q --nsTo=%q`, input, from+".*", db+".*")}
+	default:
+		return []string{"sh", "-c", fmt.Sprintf("mysql -h 127.0.0.1 -unitro -pnitro -e 'CREATE DATABASE IF NOT EXISTS `%s`' && %s | mysql -h 127.0.0.1 -unitro -pnitro %q", db, input, db)}
+	}
+}
+
+// Restore copies the backup file into the containers /tmp directory and runs the commands
+// to restore the backup. The file is streamed to the container so large backups are not
+// read into memory.
+func Restore(ctx context.Context, docker client.ContainerAPIClient, containerID, file string, commands []string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	// write the file as a tar archive for the docker api
+	pr, pw := io.Pipe()
+	go func() {
+		tw := tar.NewWriter(pw)
+
+		if err := tw.WriteHeader(&tar.Header{Name: filepath.Base(file), Mode: 0644, Size: stat.Size(), ModTime: stat.ModTime()}); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+
+		if _, err := io.Copy(tw, f); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+
+		pw.CloseWithError(tw.Close())
+	}()
+
+	if err := docker.CopyToContainer(ctx, containerID, "/tmp", pr, types.CopyToContainerOptions{}); err != nil {
+		pr.CloseWithError(err)
+
+		return "", fmt.Errorf("unable to copy the backup to the container, %w", err)
+	}
+
+	exec, err := docker.ContainerExecCreate(ctx, containerID, types.ExecConfig{
+		AttachStdout: true,
+		AttachStderr: true,
+		Cmd:          commands,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := docker.ContainerExecAttach(ctx, exec.ID, types.ExecStartCheck{})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Close()
+
+	if err := docker.ContainerExecStart(ctx, exec.ID, types.ExecStartCheck{}); err != nil {
+		return "", fmt.Errorf("unable to start the container exec, %w", err)
+	}
+
+	// reading the output waits for the commands to complete
+	buf := new(bytes.Buffer)
+	if _, err := stdcopy.StdCopy(buf, buf, resp.Reader); err != nil {
+		return "", err
+	}
+
+	// unlike Exec, a failed restore is returned as an error
+	info, err := docker.ContainerExecInspect(ctx, exec.ID)
+	if err != nil {
+		return "", err
+	}
+
+	if info.ExitCode != 0 {
+		return buf.String(), fmt.Errorf("the restore exited with code %d, %s", info.ExitCode, strings.TrimSpace(buf.String()))
+	}
+
+	return buf.String(), nil
+}
+
+// Retention is the policy for which backups to keep. Keep is the number of backups to keep for
+// each database and KeepDays is the number of days to keep backups, zero values are ignored.
+type Retention struct {
+	Keep     int
+	KeepDays int
+}
+
+// Prune returns the backups to remove based on the retention policy. The backups must be sorted
+// by container and database with the newest first, as List returns them. The newest backup for
+// each database is always kept.
+func Prune(backups []File, policy Retention, now time.Time) []File {
+	var remove []File
+
+	count := make(map[string]int)
+	for _, b := range backups {
+		key := b.Container + "/" + b.Database
+
+		count[key]++
+		if count[key] == 1 {
+			continue
+		}
+
+		switch {
+		case policy.Keep > 0 && count[key] > policy.Keep:
+			remove = append(remove, b)
+		case policy.KeepDays > 0 && b.CreatedAt.Before(now.AddDate(0, 0, -policy.KeepDays)):
+			remove = append(remove, b)
+		}
+	}
+
+	return remove
+}
+
+// RemoveOld removes the backups in the backups directory based on the retention policy
+// and returns the number of removed backups.
+func RemoveOld(home string, policy Retention, now time.Time) (int, error) {
+	if policy.Keep <= 0 && policy.KeepDays <= 0 {
+		return 0, nil
+	}
+
+	backups, err := List(home)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, b := range Prune(backups, policy, now) {
+		if err := os.Remove(b.Path); err != nil {
+			return removed, fmt.Errorf("unable to remove the backup %s, %w", b.Name, err)
+		}
+
+		removed++
+	}
+
+	return removed, nil
+}
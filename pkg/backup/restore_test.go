@@ -0,0 +1,255 @@
+package backup
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestList(t *testing.T) {
+	home, err := ioutil.TempDir("", "nitro-backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+
+	// no backups directory returns no backups
+	backups, err := List(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(backups) != 0 {
+		t.Fatalf("expected no backups, got %v", backups)
+	}
+
+	files := map[string][]string{
+		"mysql-8.0-3306": {"nitro-2021-01-02-080102.sql", "nitro-2021-03-02-080102.sql.gz", "craft-2021-01-02-080102.sql", "notes.txt"},
+		"mongo-5-27017":  {"nitro-2021-01-02-080102.archive"},
+	}
+
+	for dir, names := range files {
+		if err := os.MkdirAll(filepath.Join(home, ".nitro", "backups", dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, n := range names {
+			if err := ioutil.WriteFile(filepath.Join(home, ".nitro", "backups", dir, n), []byte("backup"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	backups, err = List(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, b := range backups {
+		got = append(got, b.Container+"/"+b.Database+"/"+b.Name)
+	}
+
+	want := []string{
+		"mongo-5-27017/nitro/nitro-2021-01-02-080102.archive",
+		"mysql-8.0-3306/craft/craft-2021-01-02-080102.sql",
+		"mysql-8.0-3306/nitro/nitro-2021-03-02-080102.sql.gz",
+		"mysql-8.0-3306/nitro/nitro-2021-01-02-080102.sql",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the backups\n%v\ngot\n%v", want, got)
+	}
+
+	if backups[0].Path != filepath.Join(home, ".nitro", "backups", "mongo-5-27017", "nitro-2021-01-02-080102.archive") {
+		t.Errorf("unexpected path %s", backups[0].Path)
+	}
+}
+
+func TestRestoreCommands(t *testing.T) {
+	tests := []struct {
+		name          string
+		compatibility string
+		file          string
+		want          []string
+	}{
+		{
+			name:          "mysql creates the database and imports the file",
+			compatibility: "mysql",
+			file:          "/tmp/nitro.sql",
+			want:          []string{"sh", "-c", "mysql -h 127.0.0.1 -unitro -pnitro -e 'CREATE DATABASE IF NOT EXISTS `restored`' && cat \"/tmp/nitro.sql\" | mysql -h 127.0.0.1 -unitro -pnitro \"restored\""},
+		},
+		{
+			name:          "compressed backups are decompressed",
+			compatibility: "mysql",
+			file:          "/tmp/nitro.sql.gz",
+			want:          []string{"sh", "-c", "mysql -h 127.0.0.1 -unitro -pnitro -e 'CREATE DATABASE IF NOT EXISTS `restored`' && gunzip -c \"/tmp/nitro.sql.gz\" | mysql -h 127.0.0.1 -unitro -pnitro \"restored\""},
+		},
+		{
+			name:          "postgres creates the database and imports the file",
+			compatibility: "postgres",
+			file:          "/tmp/nitro.sql.gz",
+			want:          []string{"sh", "-c", `createdb --username=nitro "restored" 2>/dev/null; gunzip -c "/tmp/nitro.sql.gz" | psql --username=nitro --dbname="restored" --quiet`},
+		},
+		{
+			name:          "mongodb renames the database in the archive",
+			compatibility: "mongodb",
+			file:          "/tmp/nitro.archive.gz",
+			want:          []string{"sh", "-c", `gunzip -c "/tmp/nitro.archive.gz" | mongorestore --username=nitro --password=nitro --authenticationDatabase=admin --drop --archive --nsFrom="nitro.*" --nsTo="restored.*"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RestoreCommands(tt.compatibility, "nitro", "restored", tt.file); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RestoreCommands() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRestore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nitro-backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "nitro-2021-01-02-080102.sql")
+	if err := ioutil.WriteFile(file, []byte("CREATE TABLE users;"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		exitCode int
+		wantErr  bool
+	}{
+		{
+			name:     "copies the file and runs the commands",
+			exitCode: 0,
+			wantErr:  false,
+		},
+		{
+			name:     "failed commands return an error",
+			exitCode: 1,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockDockerClient{execExitCode: tt.exitCode, execOutput: "ERROR 1064"}
+			commands := []string{"mysql", "nitro"}
+
+			if _, err := Restore(context.Background(), mock, "database-id", file, commands); (err != nil) != tt.wantErr {
+				t.Fatalf("Restore() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if string(mock.copiedFiles["/tmp/nitro-2021-01-02-080102.sql"]) != "CREATE TABLE users;" {
+				t.Errorf("expected the backup to be copied, got %v", mock.copiedFiles)
+			}
+
+			if !reflect.DeepEqual(mock.execCommands, [][]string{commands}) {
+				t.Errorf("expected the commands %v, got %v", commands, mock.execCommands)
+			}
+		})
+	}
+}
+
+func TestPrune(t *testing.T) {
+	now := time.Date(2021, 3, 31, 12, 0, 0, 0, time.UTC)
+	days := func(n int) time.Time { return now.AddDate(0, 0, -n) }
+
+	backups := []File{
+		{Container: "mysql-8.0-3306", Database: "craft", Name: "craft-1", CreatedAt: days(90)},
+		{Container: "mysql-8.0-3306", Database: "nitro", Name: "nitro-3", CreatedAt: days(1)},
+		{Container: "mysql-8.0-3306", Database: "nitro", Name: "nitro-2", CreatedAt: days(10)},
+		{Container: "mysql-8.0-3306", Database: "nitro", Name: "nitro-1", CreatedAt: days(60)},
+		{Container: "postgres-13-5432", Database: "nitro", Name: "nitro-2", CreatedAt: days(40)},
+		{Container: "postgres-13-5432", Database: "nitro", Name: "nitro-1", CreatedAt: days(50)},
+	}
+
+	tests := []struct {
+		name   string
+		policy Retention
+		want   []string
+	}{
+		{
+			name:   "keeps the newest backup for each database",
+			policy: Retention{Keep: 1},
+			want:   []string{"mysql-8.0-3306/nitro-2", "mysql-8.0-3306/nitro-1", "postgres-13-5432/nitro-1"},
+		},
+		{
+			name:   "keeps the newest backups for each database",
+			policy: Retention{Keep: 2},
+			want:   []string{"mysql-8.0-3306/nitro-1"},
+		},
+		{
+			name:   "removes backups older than the days but keeps the newest",
+			policy: Retention{KeepDays: 30},
+			want:   []string{"mysql-8.0-3306/nitro-1", "postgres-13-5432/nitro-1"},
+		},
+		{
+			name:   "uses both the keep and days",
+			policy: Retention{Keep: 2, KeepDays: 5},
+			want:   []string{"mysql-8.0-3306/nitro-2", "mysql-8.0-3306/nitro-1", "postgres-13-5432/nitro-1"},
+		},
+		{
+			name:   "empty policies remove nothing",
+			policy: Retention{},
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, b := range Prune(backups, tt.policy, now) {
+				got = append(got, b.Container+"/"+b.Name)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Prune() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoveOld(t *testing.T) {
+	home, err := ioutil.TempDir("", "nitro-backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+
+	dir := filepath.Join(home, ".nitro", "backups", "mysql-8.0-3306")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, n := range []string{"nitro-2021-01-02-080102.sql", "nitro-2021-02-02-080102.sql", "nitro-2021-03-02-080102.sql"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, n), []byte("backup"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := RemoveOld(home, Retention{Keep: 1}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if removed != 2 {
+		t.Errorf("expected 2 backups to be removed, got %d", removed)
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(files) != 1 || files[0].Name() != "nitro-2021-03-02-080102.sql" {
+		t.Errorf("expected only the newest backup to remain, got %v", files)
+	}
+}
@@ -0,0 +1,135 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/s3"
+)
+
+// Storage is the bucket that backups are pushed to and pulled from.
+type Storage interface {
+	Put(ctx context.Context, key string, content io.ReadSeeker) error
+	Get(ctx context.Context, key string, w io.Writer) error
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// NewStorage returns the client for the storage in the config.
+func NewStorage(s config.Storage) (*s3.Client, error) {
+	return s3.New(s.Endpoint, s.Region, s.Bucket, s.AccessKey, s.SecretKey)
+}
+
+// Key returns the key in the storage for a backup (e.g. team/mysql-8.0-3306/nitro-2021-03-04-103000.sql.gz).
+func Key(prefix string, f File) string {
+	return path.Join(prefix, f.Container, f.Name)
+}
+
+// Push uploads the backups that are not in the storage and returns the uploaded backups.
+func Push(ctx context.Context, storage Storage, prefix string, backups []File) ([]File, error) {
+	existing, err := storage.List(ctx, listPrefix(prefix))
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the backups in the storage, %w", err)
+	}
+
+	exists := make(map[string]bool)
+	for _, k := range existing {
+		exists[k] = true
+	}
+
+	var pushed []File
+	for _, b := range backups {
+		key := Key(prefix, b)
+		if exists[key] {
+			continue
+		}
+
+		if err := upload(ctx, storage, key, b.Path); err != nil {
+			return pushed, fmt.Errorf("unable to upload %s, %w", b.Name, err)
+		}
+
+		pushed = append(pushed, b)
+	}
+
+	return pushed, nil
+}
+
+// Pull downloads the backups in the storage that are not in the backups directory and
+// returns the names of the downloaded backups.
+func Pull(ctx context.Context, storage Storage, prefix, home string) ([]string, error) {
+	keys, err := storage.List(ctx, listPrefix(prefix))
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the backups in the storage, %w", err)
+	}
+
+	dir := filepath.Join(home, config.DirectoryName, "backups")
+
+	var pulled []string
+	for _, k := range keys {
+		// keys are in the format prefix/container/name
+		rel := strings.TrimPrefix(k, listPrefix(prefix))
+		parts := strings.Split(rel, "/")
+		if len(parts) != 2 || !nameRegex.MatchString(parts[1]) || parts[0] == "" || parts[0] == "." || parts[0] == ".." {
+			continue
+		}
+
+		file := filepath.Join(dir, parts[0], parts[1])
+		if _, err := os.Stat(file); err == nil {
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return pulled, err
+		}
+
+		if err := download(ctx, storage, k, file); err != nil {
+			return pulled, fmt.Errorf("unable to download %s, %w", k, err)
+		}
+
+		pulled = append(pulled, rel)
+	}
+
+	return pulled, nil
+}
+
+// listPrefix returns the prefix with a trailing slash so only the keys in the prefix are listed.
+func listPrefix(prefix string) string {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return ""
+	}
+
+	return prefix + "/"
+}
+
+func upload(ctx context.Context, storage Storage, key, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return storage.Put(ctx, key, f)
+}
+
+// download writes the object to the file and removes the file if the download fails.
+func download(ctx context.Context, storage Storage, key, file string) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+
+	if err := storage.Get(ctx, key, f); err != nil {
+		f.Close()
+		os.Remove(file)
+
+		return err
+	}
+
+	return f.Close()
+}
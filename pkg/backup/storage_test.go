@@ -0,0 +1,112 @@
+package backup
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPush(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nitro-backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var backups []File
+	for _, n := range []string{"nitro-2021-01-02-080102.sql.gz", "nitro-2021-03-02-080102.sql.gz"} {
+		file := filepath.Join(dir, n)
+		if err := ioutil.WriteFile(file, []byte(n), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		backups = append(backups, File{Container: "mysql-8.0-3306", Database: "nitro", Name: n, Path: file})
+	}
+
+	storage := &mockStorage{objects: map[string][]byte{
+		"team/mysql-8.0-3306/nitro-2021-01-02-080102.sql.gz": []byte("existing"),
+	}}
+
+	pushed, err := Push(context.Background(), storage, "team", backups)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pushed) != 1 || pushed[0].Name != "nitro-2021-03-02-080102.sql.gz" {
+		t.Errorf("expected only the missing backup to be pushed, got %v", pushed)
+	}
+
+	if string(storage.objects["team/mysql-8.0-3306/nitro-2021-01-02-080102.sql.gz"]) != "existing" {
+		t.Error("expected the existing backup to not be replaced")
+	}
+
+	if string(storage.objects["team/mysql-8.0-3306/nitro-2021-03-02-080102.sql.gz"]) != "nitro-2021-03-02-080102.sql.gz" {
+		t.Errorf("expected the backup to be uploaded, got %v", storage.objects)
+	}
+}
+
+func TestPull(t *testing.T) {
+	home, err := ioutil.TempDir("", "nitro-backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+
+	dir := filepath.Join(home, ".nitro", "backups", "mysql-8.0-3306")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "nitro-2021-01-02-080102.sql.gz"), []byte("local"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	storage := &mockStorage{objects: map[string][]byte{
+		"team/mysql-8.0-3306/nitro-2021-01-02-080102.sql.gz":   []byte("remote"),
+		"team/postgres-13-5432/craft-2021-03-02-080102.sql.gz": []byte("craft"),
+		"team/../escape-2021-03-02-080102.sql.gz":              []byte("unsafe"),
+		"team/notes.txt": []byte("ignored"),
+	}}
+
+	pulled, err := Pull(context.Background(), storage, "team", home)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"postgres-13-5432/craft-2021-03-02-080102.sql.gz"}; !reflect.DeepEqual(pulled, want) {
+		t.Errorf("expected the pulled backups %v, got %v", want, pulled)
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, "nitro-2021-01-02-080102.sql.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "local" {
+		t.Error("expected the local backup to not be replaced")
+	}
+
+	content, err = ioutil.ReadFile(filepath.Join(home, ".nitro", "backups", "postgres-13-5432", "craft-2021-03-02-080102.sql.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "craft" {
+		t.Errorf("expected the pulled backup content, got %q", content)
+	}
+}
+
+func TestKey(t *testing.T) {
+	f := File{Container: "mysql-8.0-3306", Name: "nitro-2021-01-02-080102.sql.gz"}
+
+	if got := Key("", f); got != "mysql-8.0-3306/nitro-2021-01-02-080102.sql.gz" {
+		t.Errorf("unexpected key %s", got)
+	}
+
+	if got := Key("team/", f); got != "team/mysql-8.0-3306/nitro-2021-01-02-080102.sql.gz" {
+		t.Errorf("unexpected key %s", got)
+	}
+}
@@ -0,0 +1,301 @@
+package backupcontainer
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/client"
+
+	"github.com/craftcms/nitro/pkg/backup"
+	"github.com/craftcms/nitro/pkg/config"
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+var (
+	// Image is the docker cli image used for the backups container, it includes busybox crond
+	Image = "docker.io/library/docker:20.10-cli"
+
+	// Name is the name of the backups container (e.g. nitro-backups)
+	Name = "nitro-backups"
+)
+
+// placeholder is replaced with the database variable in the backup script
+const placeholder = "__NITRO_DATABASE__"
+
+// entrypoint installs the backup script and the schedule, then runs crond in the foreground
+// so the backups keep running without nitro.
+const entrypoint = `printf '%s\n' "$NITRO_BACKUP_SCRIPT" > /usr/local/bin/nitro-backup && ` +
+	`chmod +x /usr/local/bin/nitro-backup && ` +
+	`echo "$SCHEDULE /usr/local/bin/nitro-backup > /proc/1/fd/1 2>&1" > /etc/crontabs/root && ` +
+	`exec crond -f -l 8`
+
+// script backs up every database in the running database containers and removes the old backups
+// the same way as `nitro backups run`. The backups are named with the date and time in TZ, which
+// matches the local time on the host.
+const script = `#!/bin/sh
+set -o pipefail
+
+now=$(date +%Y-%m-%d-%H%M%S)
+cutoff=0
+if [ "$KEEP_DAYS" -gt 0 ]; then
+	cutoff=$(date -d "@$(($(date +%s) - KEEP_DAYS * 86400))" +%Y%m%d%H%M%S)
+fi
+
+for name in $(docker ps --filter label={{nitro-label}} --filter label={{type-label}}=database --format '{{.Names}}'); do
+	compatibility=$(docker inspect --format '{{index .Config.Labels "{{compatibility-label}}"}}' "$name")
+
+	case "$compatibility" in
+	mysql)
+		databases=$(docker exec "$name" {{mysql-databases}} 2>/dev/null | grep -v -x -E 'Database|information_schema|performance_schema|sys|mysql')
+		;;
+	mongodb)
+		databases=$(docker exec "$name" {{mongodb-databases}} | grep -v -x -E 'admin|config|local')
+		;;
+	*)
+		databases=$(docker exec "$name" {{postgres-databases}} | tail -n +3 | grep -v -E '^\([0-9]+ rows?\)$' | tr -d ' ')
+		;;
+	esac
+
+	mkdir -p "/backups/$name"
+
+	for db in $databases; do
+		ext=sql
+		if [ "$compatibility" = "mongodb" ]; then
+			ext=archive
+		fi
+
+		file="/backups/$name/$db-$now.$ext.gz"
+		case "$compatibility" in
+		mysql)
+			docker exec "$name" {{mysql-dump}} | gzip > "$file.tmp"
+			;;
+		mongodb)
+			docker exec "$name" {{mongodb-dump}} | gzip > "$file.tmp"
+			;;
+		*)
+			docker exec "$name" {{postgres-dump}} | gzip > "$file.tmp"
+			;;
+		esac
+
+		if [ $? -ne 0 ]; then
+			rm -f "$file.tmp"
+			echo "unable to backup $db on $name"
+			continue
+		fi
+
+		mv "$file.tmp" "$file"
+		if [ -n "$OWNER" ]; then
+			chown "$OWNER" "/backups/$name" "$file"
+		fi
+
+		# the newest backup for each database is always kept
+		n=1
+		for old in $(ls -1 "/backups/$name/$db"-[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9]-[0-9][0-9][0-9][0-9][0-9][0-9].*.gz | sort -r | tail -n +2); do
+			n=$((n + 1))
+			created=$(basename "$old" | sed "s/^.*-\([0-9-]\{17\}\)\..*$/\1/" | tr -d -)
+			if [ "$KEEP" -gt 0 ] && [ "$n" -gt "$KEEP" ]; then
+				rm -f "$old"
+			elif [ "$KEEP_DAYS" -gt 0 ] && [ "$created" -lt "$cutoff" ]; then
+				rm -f "$old"
+			fi
+		done
+	done
+done
+
+date -u +%Y-%m-%dT%H:%M:%SZ > /backups/.last-scheduled-backup
+if [ -n "$OWNER" ]; then
+	chown "$OWNER" /backups/.last-scheduled-backup
+fi
+`
+
+// Script returns the shell script the backups container runs on the schedule. The commands to
+// list and backup the databases are the same commands nitro uses.
+func Script() string {
+	return strings.NewReplacer(
+		"{{nitro-label}}", containerlabels.Nitro,
+		"{{type-label}}", containerlabels.Type,
+		"{{compatibility-label}}", containerlabels.DatabaseCompatibility,
+		"{{mysql-databases}}", shellCommand(backup.DatabasesCommands("mysql")),
+		"{{mongodb-databases}}", shellCommand(backup.DatabasesCommands("mongodb")),
+		"{{postgres-databases}}", shellCommand(backup.DatabasesCommands("postgres")),
+		"{{mysql-dump}}", shellCommand(backup.Commands("mysql", placeholder)),
+		"{{mongodb-dump}}", shellCommand(backup.Commands("mongodb", placeholder)),
+		"{{postgres-dump}}", shellCommand(backup.Commands("postgres", placeholder)),
+	).Replace(script)
+}
+
+// shellCommand quotes the arguments for the shell and replaces the placeholder with
+// the database variable.
+func shellCommand(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'"'"'`) + "'"
+	}
+
+	return strings.ReplaceAll(strings.Join(quoted, " "), placeholder, `'"$db"'`)
+}
+
+// Timezone returns a POSIX TZ value with the offset of the time, so the container names the
+// backups and runs the schedule in the local time of the host. The container is recreated
+// by apply when the offset changes (e.g. daylight saving time).
+func Timezone(t time.Time) string {
+	_, offset := t.Zone()
+
+	// POSIX offsets are the time to add to get to UTC
+	sign := "-"
+	if offset < 0 {
+		sign = "+"
+		offset = -offset
+	}
+
+	return fmt.Sprintf("NITRO%s%02d:%02d", sign, offset/3600, offset%3600/60)
+}
+
+// Env returns the environment variables for the backups container.
+func Env(settings config.Backups, tz, owner string) []string {
+	return []string{
+		"SCHEDULE=" + settings.Schedule,
+		"KEEP=" + strconv.Itoa(settings.Keep),
+		"KEEP_DAYS=" + strconv.Itoa(settings.KeepDays),
+		"TZ=" + tz,
+		"OWNER=" + owner,
+		"NITRO_BACKUP_SCRIPT=" + Script(),
+	}
+}
+
+// Hash returns the value for the config hash label, which is used to check if the
+// container needs to be recreated when the schedule or retention changes.
+func Hash(dir string, env []string) string {
+	h := sha256.New()
+
+	fmt.Fprintln(h, Image)
+	fmt.Fprintln(h, dir)
+	for _, e := range env {
+		fmt.Fprintln(h, e)
+	}
+
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// owner returns the user and group for the backups, so the files in the backups directory
+// are not owned by root. It is empty on Windows, where the files do not have an owner.
+func owner() string {
+	if os.Getuid() < 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid())
+}
+
+// StartOrCreate makes sure the backups container is running with the schedule and retention
+// from the backup settings. Containers created for other settings are replaced.
+func StartOrCreate(ctx context.Context, docker client.CommonAPIClient, output terminal.Outputer, home string, settings config.Backups) error {
+	dir := filepath.Join(home, config.DirectoryName, "backups")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("unable to create the backups directory, %w", err)
+	}
+
+	env := Env(settings, Timezone(time.Now()), owner())
+	hash := Hash(dir, env)
+
+	filter := filters.NewArgs()
+	filter.Add("label", containerlabels.Type+"=backups")
+
+	containers, err := docker.ContainerList(ctx, types.ContainerListOptions{Filters: filter, All: true})
+	if err != nil {
+		return fmt.Errorf("unable to list the containers, %w", err)
+	}
+
+	for _, c := range containers {
+		if c.Labels[containerlabels.ConfigHash] == hash {
+			if c.State != "running" {
+				if err := docker.ContainerStart(ctx, c.ID, types.ContainerStartOptions{}); err != nil {
+					return fmt.Errorf("unable to start the backups container, %w", err)
+				}
+			}
+
+			return nil
+		}
+
+		// the schedule or retention changed
+		if err := docker.ContainerRemove(ctx, c.ID, types.ContainerRemoveOptions{Force: true}); err != nil {
+			return fmt.Errorf("unable to remove the backups container, %w", err)
+		}
+	}
+
+	imageFilter := filters.NewArgs()
+	imageFilter.Add("reference", Image)
+
+	images, err := docker.ImageList(ctx, types.ImageListOptions{Filters: imageFilter})
+	if err != nil {
+		return fmt.Errorf("unable to get a list of images, %w", err)
+	}
+
+	// if we don't have the image, pull it
+	if len(images) == 0 {
+		output.Pending("pulling", Image)
+
+		rdr, err := docker.ImagePull(ctx, Image, types.ImagePullOptions{All: false})
+		if err != nil {
+			return fmt.Errorf("unable to pull docker image, %w", err)
+		}
+
+		buf := &bytes.Buffer{}
+		if _, err := buf.ReadFrom(rdr); err != nil {
+			return fmt.Errorf("unable to read the output from pulling the image, %w", err)
+		}
+
+		output.Done()
+	}
+
+	resp, err := docker.ContainerCreate(ctx,
+		&container.Config{
+			Image:      Image,
+			Entrypoint: []string{"sh", "-c", entrypoint},
+			Env:        env,
+			Labels: containerlabels.StampRunID(ctx, map[string]string{
+				containerlabels.Nitro:      "true",
+				containerlabels.Type:       "backups",
+				containerlabels.ConfigHash: hash,
+			}),
+		},
+		&container.HostConfig{
+			Mounts: []mount.Mount{
+				{
+					Type:   mount.TypeBind,
+					Source: "/var/run/docker.sock",
+					Target: "/var/run/docker.sock",
+				},
+				{
+					Type:   mount.TypeBind,
+					Source: dir,
+					Target: "/backups",
+				},
+			},
+		},
+		nil,
+		nil,
+		Name,
+	)
+	if err != nil {
+		return fmt.Errorf("unable to create the backups container, %w", err)
+	}
+
+	if err := docker.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
+		return fmt.Errorf("unable to start the backups container, %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package backupcontainer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/craftcms/nitro/pkg/config"
+)
+
+func TestTimezone(t *testing.T) {
+	tests := []struct {
+		name string
+		t    time.Time
+		want string
+	}{
+		{
+			name: "utc",
+			t:    time.Date(2021, 3, 3, 12, 0, 0, 0, time.UTC),
+			want: "NITRO-00:00",
+		},
+		{
+			name: "east of utc uses a negative offset",
+			t:    time.Date(2021, 3, 3, 12, 0, 0, 0, time.FixedZone("IST", 5*3600+30*60)),
+			want: "NITRO-05:30",
+		},
+		{
+			name: "west of utc uses a positive offset",
+			t:    time.Date(2021, 3, 3, 12, 0, 0, 0, time.FixedZone("EST", -5*3600)),
+			want: "NITRO+05:00",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Timezone(tt.t); got != tt.want {
+				t.Errorf("Timezone() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_shellCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "database is replaced with the variable",
+			args: []string{"pg_dump", "--username=nitro", placeholder},
+			want: `'pg_dump' '--username=nitro' ''"$db"''`,
+		},
+		{
+			name: "database in an argument is replaced with the variable",
+			args: []string{"mongodump", "--db=" + placeholder},
+			want: `'mongodump' '--db='"$db"''`,
+		},
+		{
+			name: "single quotes are escaped",
+			args: []string{"sh", "-c", `echo 'hello'`},
+			want: `'sh' '-c' 'echo '"'"'hello'"'"''`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shellCommand(tt.args); got != tt.want {
+				t.Errorf("shellCommand() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScript(t *testing.T) {
+	script := Script()
+
+	if strings.Contains(script, "{{mysql") || strings.Contains(script, "{{postgres") || strings.Contains(script, "{{mongodb") || strings.Contains(script, "-label}}") {
+		t.Errorf("expected the script to replace all of the commands, got:\n%s", script)
+	}
+
+	if strings.Contains(script, placeholder) {
+		t.Errorf("expected the script to replace the database placeholder, got:\n%s", script)
+	}
+}
+
+func TestHash(t *testing.T) {
+	settings := config.Backups{Schedule: "0 2 * * *", Keep: 5}
+	hash := Hash("/home/nitro/.nitro/backups", Env(settings, "NITRO-00:00", "1000:1000"))
+
+	if got := Hash("/home/nitro/.nitro/backups", Env(settings, "NITRO-00:00", "1000:1000")); got != hash {
+		t.Errorf("expected the same settings to have the same hash, got %v and %v", got, hash)
+	}
+
+	changed := []struct {
+		name string
+		dir  string
+		env  []string
+	}{
+		{name: "schedule", dir: "/home/nitro/.nitro/backups", env: Env(config.Backups{Schedule: "0 3 * * *", Keep: 5}, "NITRO-00:00", "1000:1000")},
+		{name: "keep", dir: "/home/nitro/.nitro/backups", env: Env(config.Backups{Schedule: "0 2 * * *", Keep: 6}, "NITRO-00:00", "1000:1000")},
+		{name: "timezone", dir: "/home/nitro/.nitro/backups", env: Env(settings, "NITRO-01:00", "1000:1000")},
+		{name: "directory", dir: "/home/other/.nitro/backups", env: Env(settings, "NITRO-00:00", "1000:1000")},
+	}
+	for _, tt := range changed {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Hash(tt.dir, tt.env); got == hash {
+				t.Errorf("expected a different hash when the %s changes", tt.name)
+			}
+		})
+	}
+}
@@ -29,12 +29,42 @@ type RouteHandle struct {
 	Root      string     `json:"root,omitempty"`
 	Upstreams []Upstream `json:"upstreams,omitempty"`
 	Hide      []string   `json:"hide,omitempty"`
+	Providers *Providers `json:"providers,omitempty"`
+}
+
+type Providers struct {
+	HTTPBasic HTTPBasic `json:"http_basic"`
+}
+
+type HTTPBasic struct {
+	Hash     Hash      `json:"hash"`
+	Accounts []Account `json:"accounts"`
+}
+
+type Hash struct {
+	Algorithm string `json:"algorithm"`
+}
+
+type Account struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
 }
 
 type Match struct {
-	Host []string `json:"host"`
+	Host   []string            `json:"host"`
+	Header map[string][]string `json:"header,omitempty"`
 }
 
 type Upstream struct {
 	Dial string `json:"dial,omitempty"`
 }
+
+type Certificates struct {
+	LoadPEM []LoadPEM `json:"load_pem,omitempty"`
+}
+
+type LoadPEM struct {
+	Certificate string   `json:"certificate"`
+	Key         string   `json:"key"`
+	Tags        []string `json:"tags,omitempty"`
+}
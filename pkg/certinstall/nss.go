@@ -0,0 +1,126 @@
+package certinstall
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// NSSNickname is the name of the nitro certificate in the NSS databases
+const NSSNickname = "Nitro Root CA"
+
+var (
+	// ErrNoCertutil is returned when the NSS certutil tool is not installed
+	ErrNoCertutil = fmt.Errorf("unable to find certutil, install nss (e.g. apt install libnss3-tools or brew install nss) to trust the certificate in Firefox")
+
+	// certutil runs the certutil tool with the args, it is replaced in tests
+	certutil = func(path string, args ...string) error {
+		out, err := exec.Command(path, args...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("%s, %w", strings.TrimSpace(string(out)), err)
+		}
+
+		return nil
+	}
+)
+
+// NSSDatabases returns the NSS databases for the Firefox and Thunderbird profiles, and on
+// Linux the shared database used by Chrome, for the system (runtime.GOOS).
+func NSSDatabases(home, system string) []string {
+	var profiles []string
+	switch system {
+	case "darwin":
+		profiles = []string{
+			filepath.Join(home, "Library", "Application Support", "Firefox", "Profiles", "*"),
+			filepath.Join(home, "Library", "Thunderbird", "Profiles", "*"),
+		}
+	case "windows":
+		appData := os.Getenv("APPDATA")
+		if appData == "" {
+			appData = filepath.Join(home, "AppData", "Roaming")
+		}
+
+		profiles = []string{
+			filepath.Join(appData, "Mozilla", "Firefox", "Profiles", "*"),
+			filepath.Join(appData, "Thunderbird", "Profiles", "*"),
+		}
+	default:
+		profiles = []string{
+			filepath.Join(home, ".pki", "nssdb"),
+			filepath.Join(home, ".mozilla", "firefox", "*"),
+			filepath.Join(home, "snap", "firefox", "common", ".mozilla", "firefox", "*"),
+			filepath.Join(home, ".thunderbird", "*"),
+		}
+	}
+
+	var databases []string
+	for _, p := range profiles {
+		dirs, err := filepath.Glob(p)
+		if err != nil {
+			continue
+		}
+
+		for _, dir := range dirs {
+			// cert9.db is the sql format, cert8.db is the legacy format
+			if _, err := os.Stat(filepath.Join(dir, "cert9.db")); err == nil {
+				databases = append(databases, "sql:"+dir)
+				continue
+			}
+
+			if _, err := os.Stat(filepath.Join(dir, "cert8.db")); err == nil {
+				databases = append(databases, "dbm:"+dir)
+			}
+		}
+	}
+
+	return databases
+}
+
+// InstallNSS adds the root certificate to each of the NSS databases with the certutil tool. Any
+// previous nitro certificate is replaced, since the proxy creates a new root certificate when
+// its volume is removed.
+func InstallNSS(file string, databases []string) error {
+	path, err := certutilPath()
+	if err != nil {
+		return err
+	}
+
+	for _, db := range databases {
+		// the certificate may not be in the database yet
+		_ = certutil(path, "-D", "-d", db, "-n", NSSNickname)
+
+		if err := certutil(path, "-A", "-d", db, "-t", "C,,", "-n", NSSNickname, "-i", file); err != nil {
+			return fmt.Errorf("unable to add the certificate to %s, %w", db, err)
+		}
+	}
+
+	return nil
+}
+
+// certutilPath returns the path to the NSS certutil tool. On Windows, the certutil in the
+// system directory is a different tool and is skipped.
+func certutilPath() (string, error) {
+	system := strings.ToLower(os.Getenv("SystemRoot"))
+	if system == "" {
+		path, err := exec.LookPath("certutil")
+		if err != nil {
+			return "", ErrNoCertutil
+		}
+
+		return path, nil
+	}
+
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if strings.HasPrefix(strings.ToLower(dir), system) {
+			continue
+		}
+
+		if path, err := exec.LookPath(filepath.Join(dir, "certutil.exe")); err == nil {
+			return path, nil
+		}
+	}
+
+	return "", ErrNoCertutil
+}
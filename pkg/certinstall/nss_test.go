@@ -0,0 +1,101 @@
+package certinstall
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNSSDatabases(t *testing.T) {
+	tests := []struct {
+		name   string
+		system string
+		files  []string
+		want   []string
+	}{
+		{
+			name:   "linux finds the firefox, thunderbird, and shared databases",
+			system: "linux",
+			files: []string{
+				".pki/nssdb/cert9.db",
+				".mozilla/firefox/abc.default/cert9.db",
+				".thunderbird/def.default/cert8.db",
+				".mozilla/firefox/empty.default/prefs.js",
+			},
+			want: []string{
+				"sql:.pki/nssdb",
+				"sql:.mozilla/firefox/abc.default",
+				"dbm:.thunderbird/def.default",
+			},
+		},
+		{
+			name:   "macos finds the firefox profiles",
+			system: "darwin",
+			files: []string{
+				"Library/Application Support/Firefox/Profiles/abc.default/cert9.db",
+				".mozilla/firefox/abc.default/cert9.db",
+			},
+			want: []string{
+				"sql:Library/Application Support/Firefox/Profiles/abc.default",
+			},
+		},
+		{
+			name:   "no profiles returns no databases",
+			system: "linux",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			for _, f := range tt.files {
+				path := filepath.Join(home, f)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+
+				if err := os.WriteFile(path, nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var want []string
+			for _, w := range tt.want {
+				want = append(want, w[:4]+filepath.Join(home, w[4:]))
+			}
+
+			if got := NSSDatabases(home, tt.system); !reflect.DeepEqual(got, want) {
+				t.Errorf("NSSDatabases() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestInstallNSS(t *testing.T) {
+	// use a fake certutil on the path
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "certutil"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+	t.Setenv("SystemRoot", "")
+
+	var calls [][]string
+	defer func(c func(string, ...string) error) { certutil = c }(certutil)
+	certutil = func(path string, args ...string) error {
+		calls = append(calls, args)
+		return nil
+	}
+
+	if err := InstallNSS("nitro.crt", []string{"sql:/profile"}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{
+		{"-D", "-d", "sql:/profile", "-n", NSSNickname},
+		{"-A", "-d", "sql:/profile", "-t", "C,,", "-n", NSSNickname, "-i", "nitro.crt"},
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("expected the calls to be %v, got %v", want, calls)
+	}
+}
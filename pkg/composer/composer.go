@@ -13,6 +13,21 @@ import (
 	"github.com/docker/docker/client"
 )
 
+const (
+	// CacheDir is where the shared cache volume is mounted. The directory is world writable in
+	// the image, so the volume is writable when the container runs as the host user.
+	CacheDir = "/var/tmp"
+
+	// HomeDir is the COMPOSER_HOME in the container, the auth.json file is mounted here
+	HomeDir = "/tmp/composer"
+
+	// phar1 is the path to composer 1 in the cache volume, the images only include composer 2
+	phar1 = CacheDir + "/composer-1.phar"
+
+	// install1 downloads composer 1 into the cache volume, if it is missing, and runs it with the args
+	install1 = `[ -f ` + phar1 + ` ] || php -r "copy('https://getcomposer.org/download/latest-1.x/composer.phar', '` + phar1 + `');" && php ` + phar1 + ` "$@"`
+)
+
 // Options are used to pass container specific details to the create func
 type Options struct {
 	Image         string
@@ -21,6 +36,15 @@ type Options struct {
 	Volume        *types.Volume
 	Path          string
 	NetworkConfig *network.NetworkingConfig
+
+	// Version is the major version of composer (1 or 2), composer 2 is used by default
+	Version string
+
+	// Cache is the volume shared by all projects for the composer cache
+	Cache *types.Volume
+
+	// AuthFile is the path to the users auth.json on the host, which is mounted read only
+	AuthFile string
 }
 
 // CreateContainer will create a new container for running composer with a local path and volume for caching downloads.
@@ -38,6 +62,35 @@ func CreateContainer(ctx context.Context, docker client.CommonAPIClient, opts *O
 		containerUser = fmt.Sprintf("%s:%s", user.Uid, user.Gid)
 	}
 
+	entrypoint := []string{"/usr/bin/composer"}
+	if opts.Version == "1" {
+		// the last arg is $0 for the script
+		entrypoint = []string{"sh", "-c", install1, "composer"}
+	}
+
+	env := []string{"COMPOSER_HOME=" + HomeDir}
+	binds := []string{fmt.Sprintf("%s:/app:rw", opts.Path)}
+	mounts := []mount.Mount{
+		{
+			Type:   mount.TypeVolume,
+			Source: opts.Volume.Name,
+			Target: "/tmp",
+		},
+	}
+
+	if opts.Cache != nil {
+		env = append(env, "COMPOSER_CACHE_DIR="+CacheDir+"/cache")
+		mounts = append(mounts, mount.Mount{
+			Type:   mount.TypeVolume,
+			Source: opts.Cache.Name,
+			Target: CacheDir,
+		})
+	}
+
+	if opts.AuthFile != "" {
+		binds = append(binds, fmt.Sprintf("%s:%s/auth.json:ro", opts.AuthFile, HomeDir))
+	}
+
 	return docker.ContainerCreate(
 		ctx,
 		&container.Config{
@@ -45,18 +98,13 @@ func CreateContainer(ctx context.Context, docker client.CommonAPIClient, opts *O
 			Cmd:        opts.Commands,
 			Tty:        false,
 			Labels:     opts.Labels,
-			Entrypoint: []string{"/usr/bin/composer"},
+			Entrypoint: entrypoint,
 			User:       containerUser,
+			Env:        env,
 		},
 		&container.HostConfig{
-			Binds: []string{fmt.Sprintf("%s:/app:rw", opts.Path)},
-			Mounts: []mount.Mount{
-				{
-					Type:   mount.TypeVolume,
-					Source: opts.Volume.Name,
-					Target: "/tmp",
-				},
-			},
+			Binds:  binds,
+			Mounts: mounts,
 		},
 		opts.NetworkConfig,
 		nil,
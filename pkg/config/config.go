@@ -1,14 +1,30 @@
 package config
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
+	"runtime"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
+
+	// embed the timezone database so timezones can be validated on every OS
+	_ "time/tzdata"
 
 	"github.com/craftcms/nitro/pkg/helpers"
+	"github.com/craftcms/nitro/pkg/platform"
+	"github.com/craftcms/nitro/pkg/schedule"
+	"github.com/craftcms/nitro/pkg/validate"
 
 	"gopkg.in/yaml.v3"
 )
@@ -23,9 +39,128 @@ var (
 	// ErrEmptyfile is returned when a config file is empty
 	ErrEmptyfile = fmt.Errorf("the config file appears to be empty")
 
+	// ErrConflictingSites is returned when sites share a path but have conflicting settings
+	ErrConflictingSites = fmt.Errorf("sites share a path but have conflicting settings")
+
+	// ErrDependencyCycle is returned when sites depend on each other
+	ErrDependencyCycle = fmt.Errorf("sites have a dependency cycle")
+
+	// ErrInvalidTimezone is returned when a timezone is not in the tz database
+	ErrInvalidTimezone = fmt.Errorf("invalid timezone")
+
+	// ErrUnknownProfile is returned when a services profile is not in the config
+	ErrUnknownProfile = fmt.Errorf("unknown services profile")
+
+	// ErrInvalidMinioPassword is returned when the minio password is too short
+	ErrInvalidMinioPassword = fmt.Errorf("the minio password must be at least 8 characters")
+
+	// ErrInvalidRemote is returned when a sites remote is missing the host or database, or uses an unknown engine
+	ErrInvalidRemote = fmt.Errorf("the remote must have a host, database, and an engine of mysql, postgres, or mongodb")
+
+	// ErrInvalidStorage is returned when the backups storage is missing the endpoint or bucket
+	ErrInvalidStorage = fmt.Errorf("the backups storage must have an endpoint and bucket")
+
+	// ErrInvalidEnv is returned when a sites environment variable name is empty or contains an equal sign or space
+	ErrInvalidEnv = fmt.Errorf("invalid environment variable name")
+
+	// ErrInvalidRetention is returned when the backups keep settings are negative
+	ErrInvalidRetention = fmt.Errorf("the backups keep and keep_days must not be negative")
+
+	// ErrUnknownService is returned when a services profile has a service nitro does not support
+	ErrUnknownService = fmt.Errorf("unknown service")
+
+	// ErrInvalidEnvironment is returned when an environment name is not lowercase letters, numbers, and dashes
+	ErrInvalidEnvironment = fmt.Errorf("the environment name must only contain lowercase letters, numbers, and dashes")
+
+	// ErrInvalidConfig is returned when the config file has values of the wrong type
+	ErrInvalidConfig = fmt.Errorf("the config file has invalid values")
+
+	// ErrInvalidBasicAuth is returned when a sites basic auth is missing the user or password
+	ErrInvalidBasicAuth = fmt.Errorf("the basic auth must have a user and password")
+
+	// ErrInvalidTLD is returned when the tld is not lowercase letters, numbers, and dashes
+	ErrInvalidTLD = fmt.Errorf("the tld must only contain lowercase letters, numbers, and dashes")
+
+	// ErrInvalidPort is returned when a proxy port is not a number between 1 and 65535
+	ErrInvalidPort = fmt.Errorf("the proxy ports must be numbers between 1 and 65535")
+
+	// ErrDuplicatePort is returned when the proxy ports are not unique
+	ErrDuplicatePort = fmt.Errorf("the proxy ports must be unique")
+
+	// ErrInvalidAPITransport is returned when the proxy api transport is not tcp or exec
+	ErrInvalidAPITransport = fmt.Errorf("the proxy api transport must be tcp or exec")
+
+	// APITransportTCP connects to the nitrod API using the API port on the host
+	APITransportTCP = "tcp"
+
+	// APITransportExec connects to the nitrod API through docker exec, so the API port is not bound on the host
+	APITransportExec = "exec"
+
+	// ErrInvalidHostProxy is returned when a host proxy is missing the hostname or the port is not between 1 and 65535
+	ErrInvalidHostProxy = fmt.Errorf("the host proxy must have a hostname and a port between 1 and 65535")
+
+	// ErrHostProxyConflict is returned when a host proxy uses the hostname or alias of a site or another host proxy
+	ErrHostProxyConflict = fmt.Errorf("the host proxy hostname is already used")
+
+	// ErrInvalidLimits is returned when a sites max body size is not a size (e.g. 512M) or a timeout is not a duration (e.g. 10m)
+	ErrInvalidLimits = fmt.Errorf("the limits must have a max body size like 512M and timeouts like 10m")
+
+	// ErrInvalidWebSocketsPort is returned when a sites websockets port is not between 1 and 65535
+	ErrInvalidWebSocketsPort = fmt.Errorf("the websockets port must be between 1 and 65535")
+
+	// DefaultWebSocketsPort is the port websocket connections are forwarded to when a site does not set one
+	DefaultWebSocketsPort = 6001
+
+	// ErrInvalidCertificate is returned when a site has a certificate without a key or a key without a certificate
+	ErrInvalidCertificate = fmt.Errorf("the certificate and key must both be set")
+
+	// XdebugProfile is the xdebug profile setting that writes a cachegrind file for each request
+	XdebugProfile = "profile"
+
+	// XdebugTrace is the xdebug profile setting that writes a function trace for each request
+	XdebugTrace = "trace"
+
+	// XdebugOutputDir is the directory in the site container xdebug writes profiles and traces to
+	XdebugOutputDir = "/var/www/xdebug"
+
+	// SSHAgentSocket is the path in the site container the hosts ssh agent socket is mounted to
+	SSHAgentSocket = "/run/ssh-agent.sock"
+
+	// ErrInvalidXdebugProfile is returned when a sites xdebug profile is not profile or trace
+	ErrInvalidXdebugProfile = fmt.Errorf("the xdebug profile must be profile or trace")
+
+	// ErrInvalidResources is returned when the cpus are negative or the memory is not a size of at least 6M (e.g. 2G)
+	ErrInvalidResources = fmt.Errorf("the cpus must be a number like 1.5 and the memory a size of at least 6M like 2G")
+
+	// DefaultTLD is the top level domain used when the config does not set one
+	DefaultTLD = "nitro"
+
+	// EnvironmentVariable is the environment variable used to select a named environment
+	EnvironmentVariable = "NITRO_ENV"
+
+	// environment is the name of the selected environment, it is empty for the default environment
+	environment string
+
+	// sizeRegex is used to validate nginx sizes (e.g. 512M)
+	sizeRegex = regexp.MustCompile(`^[0-9]+[kKmMgG]?$`)
+
+	// environmentRegex is used to validate environment names
+	environmentRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+	// variableRegex matches the ${VAR} references to environment variables in the config
+	variableRegex = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
 	// FileName is the default name for the yaml file
 	FileName = "nitro.yaml"
 
+	// IgnoreFileName is the name of the file in a site’s path that lists
+	// patterns to exclude from the site’s mount
+	IgnoreFileName = ".nitroignore"
+
+	// ProjectFileName is the name of the file in a site’s path with the site
+	// settings for the project, it can be committed to share the settings
+	ProjectFileName = ".nitro.yaml"
+
 	// DefaultEnvs is used to map a config to a known environment variable that is used
 	// on the container instances to their default values
 	DefaultEnvs = map[string]string{
@@ -43,6 +178,7 @@ var (
 		"XDEBUG_CONFIG":                   "",
 		"BLACKFIRE_SERVER_ID":             "",
 		"BLACKFIRE_SERVER_TOKEN":          "",
+		"TZ":                              "UTC",
 	}
 )
 
@@ -51,10 +187,43 @@ type Config struct {
 	Containers []Container `json:"containers,omitempty" yaml:"containers,omitempty"`
 	Blackfire  Blackfire   `json:"blackfire,omitempty" yaml:"blackfire,omitempty"`
 	Databases  []Database  `json:"databases,omitempty" yaml:"databases,omitempty"`
+	Network    Network     `json:"network,omitempty" yaml:"network,omitempty"`
 	Services   Services    `json:"services" yaml:"services"`
 	Sites      []Site      `json:"sites,omitempty" yaml:"sites,omitempty"`
+	Timezone   string      `json:"timezone,omitempty" yaml:"timezone,omitempty"`
 	File       string      `json:"-" yaml:"-"`
 
+	// Profiles are named lists of services that can be selected when
+	// applying (e.g. nitro apply --profile full) instead of Services.
+	Profiles map[string][]string `json:"profiles,omitempty" yaml:"profiles,omitempty"`
+
+	// Backups configures the scheduled database backups that are
+	// created with nitro backups run.
+	Backups Backups `json:"backups,omitempty" yaml:"backups,omitempty"`
+
+	// PHP are the default php settings for all sites, settings in
+	// a sites php block override the defaults.
+	PHP PHP `json:"php,omitempty" yaml:"php,omitempty"`
+
+	// Hooks are commands that run on the host before and after apply.
+	Hooks Hooks `json:"hooks,omitempty" yaml:"hooks,omitempty"`
+
+	// TLD is the top level domain (e.g. test) for new sites and the
+	// services nitro proxies, it defaults to nitro.
+	TLD string `json:"tld,omitempty" yaml:"tld,omitempty"`
+
+	// Proxy sets the ports on the host for the proxy, for machines where
+	// another web server is already using ports 80 and 443.
+	Proxy Proxy `json:"proxy,omitempty" yaml:"proxy,omitempty"`
+
+	// HostProxies route a hostname through the proxy to a port on the host
+	// machine, such as a dev server (e.g. vite) running outside of docker.
+	HostProxies []HostProxy `json:"host_proxies,omitempty" yaml:"host_proxies,omitempty"`
+
+	// interpolated are the values in the config file with ${VAR} references,
+	// keyed by their path, so Save writes the references and not the values.
+	interpolated map[string]string
+
 	// rw sync.RWMutex
 }
 
@@ -137,119 +306,1418 @@ func (c *Config) ListOfSitesByDirectory(home, wd string) []Site {
 			}
 		}
 
-		// Return sites with container path(s) matching the working directory.
-		if len(exactMatches) > 0 {
-			return exactMatches
+		// Return sites with container path(s) matching the working directory.
+		if len(exactMatches) > 0 {
+			return exactMatches
+		}
+
+		// Return the single, most specific found item if we have one.
+		if bestMatches == 1 {
+			return []Site{b}
+		}
+
+		return found
+	}
+
+	// Otherwise just return all the sites.
+	return c.Sites
+}
+
+// Validate takes the user’s home directory and checks the config for
+// problems. Unknown fields are returned as warnings. Sites that share
+// a path are allowed, since that may be intentional for multi-domain
+// setups, and are also returned as warnings.
+// Sites that share a path but have different PHP versions or web
+// roots return an error.
+func (c *Config) Validate(home string) ([]string, error) {
+	var warnings []string
+
+	// check the file for unknown fields
+	if c.File != "" {
+		unknown, err := c.UnknownFields()
+		if err != nil {
+			return nil, err
+		}
+
+		warnings = append(warnings, unknown...)
+	}
+
+	if !environmentRegex.MatchString(c.GetTLD()) {
+		return nil, ErrInvalidTLD
+	}
+
+	transport := c.GetAPITransport()
+	if transport != APITransportTCP && transport != APITransportExec {
+		return nil, fmt.Errorf("%w, got %q", ErrInvalidAPITransport, transport)
+	}
+
+	// the api port is not bound when using exec
+	check := []string{c.GetHTTPPort(), c.GetHTTPSPort()}
+	if transport == APITransportTCP {
+		check = append(check, c.GetAPIPort())
+	}
+
+	if c.Proxy.DNS {
+		check = append(check, c.GetDNSPort())
+	}
+
+	// check the proxy ports are valid and do not collide
+	ports := map[string]bool{}
+	for _, p := range check {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 1 || n > 65535 {
+			return nil, fmt.Errorf("%w, got %q", ErrInvalidPort, p)
+		}
+
+		if ports[p] {
+			return nil, fmt.Errorf("%w, %s is used more than once", ErrDuplicatePort, p)
+		}
+
+		ports[p] = true
+	}
+
+	// check the timezones are in the tz database
+	if err := ValidateTimezone(c.Timezone); err != nil {
+		return nil, err
+	}
+
+	for _, s := range c.Sites {
+		if err := ValidateTimezone(s.Timezone); err != nil {
+			return nil, fmt.Errorf("%w for site %s", err, s.Hostname)
+		}
+	}
+
+	// check the sites environment variable names
+	for _, s := range c.Sites {
+		for k := range s.Env {
+			if k == "" || strings.ContainsAny(k, "= \t\n") {
+				return nil, fmt.Errorf("%w %q for site %s", ErrInvalidEnv, k, s.Hostname)
+			}
+		}
+	}
+
+	// check the remotes have the settings to connect
+	for _, s := range c.Sites {
+		if s.Remote == nil {
+			continue
+		}
+
+		switch s.Remote.Engine {
+		case "", "mysql", "postgres", "mongodb":
+		default:
+			return nil, fmt.Errorf("%w for site %s", ErrInvalidRemote, s.Hostname)
+		}
+
+		if s.Remote.Host == "" || s.Remote.Database == "" {
+			return nil, fmt.Errorf("%w for site %s", ErrInvalidRemote, s.Hostname)
+		}
+	}
+
+	// check the basic auth has credentials
+	for _, s := range c.Sites {
+		if s.BasicAuth != nil && (s.BasicAuth.User == "" || s.BasicAuth.Password == "") {
+			return nil, fmt.Errorf("%w for site %s", ErrInvalidBasicAuth, s.Hostname)
+		}
+
+		if s.XdebugProfile != "" && s.XdebugProfile != XdebugProfile && s.XdebugProfile != XdebugTrace {
+			return nil, fmt.Errorf("%w for site %s, got %q", ErrInvalidXdebugProfile, s.Hostname, s.XdebugProfile)
+		}
+	}
+
+	// check the host proxies do not use the hostnames of sites
+	hostnames := map[string]bool{}
+	for _, s := range c.Sites {
+		hostnames[s.Hostname] = true
+		for _, a := range s.Aliases {
+			hostnames[a] = true
+		}
+	}
+
+	for _, p := range c.HostProxies {
+		if p.Hostname == "" || p.Port < 1 || p.Port > 65535 {
+			return nil, fmt.Errorf("%w for host proxy %s", ErrInvalidHostProxy, p.Hostname)
+		}
+
+		if hostnames[p.Hostname] {
+			return nil, fmt.Errorf("%w, %s", ErrHostProxyConflict, p.Hostname)
+		}
+
+		hostnames[p.Hostname] = true
+	}
+
+	// check the certificates have a key
+	for _, s := range c.Sites {
+		if (s.Certificate == "") != (s.Key == "") {
+			return nil, fmt.Errorf("%w for site %s", ErrInvalidCertificate, s.Hostname)
+		}
+	}
+
+	// check the websockets ports
+	for _, s := range c.Sites {
+		if s.WebSocketsPort < 0 || s.WebSocketsPort > 65535 {
+			return nil, fmt.Errorf("%w for site %s", ErrInvalidWebSocketsPort, s.Hostname)
+		}
+	}
+
+	// check the limits are sizes and durations
+	for _, s := range c.Sites {
+		if s.Limits == nil {
+			continue
+		}
+
+		if s.Limits.MaxBodySize != "" && !sizeRegex.MatchString(s.Limits.MaxBodySize) {
+			return nil, fmt.Errorf("%w for site %s", ErrInvalidLimits, s.Hostname)
+		}
+
+		for _, t := range []string{s.Limits.ReadTimeout, s.Limits.SendTimeout} {
+			if t == "" {
+				continue
+			}
+
+			if d, err := time.ParseDuration(t); err != nil || d < time.Second {
+				return nil, fmt.Errorf("%w for site %s", ErrInvalidLimits, s.Hostname)
+			}
+		}
+	}
+
+	// check the site dependencies
+	for _, s := range c.Sites {
+		for _, d := range s.DependsOn {
+			if _, err := c.DependencyContainerName(d); err != nil {
+				return nil, fmt.Errorf("%w for site %s", err, s.Hostname)
+			}
+		}
+	}
+
+	if _, err := c.SitesInOrder(); err != nil {
+		return nil, err
+	}
+
+	// check the resource limits
+	for _, s := range c.Sites {
+		if err := validateResources(s.CPUs, s.Memory); err != nil {
+			return nil, fmt.Errorf("%w for site %s", err, s.Hostname)
+		}
+	}
+
+	for _, d := range c.Databases {
+		if err := validateResources(d.CPUs, d.Memory); err != nil {
+			return nil, fmt.Errorf("%w for database %s-%s-%s", err, d.Engine, d.Version, d.Port)
+		}
+	}
+
+	// check the image platforms
+	for _, d := range c.Databases {
+		if _, err := platform.Parse(d.Platform); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, ct := range c.Containers {
+		if _, err := platform.Parse(ct.Platform); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.Backups.Keep < 0 || c.Backups.KeepDays < 0 {
+		return nil, ErrInvalidRetention
+	}
+
+	if c.Backups.Storage != nil && (c.Backups.Storage.Endpoint == "" || c.Backups.Storage.Bucket == "") {
+		return nil, ErrInvalidStorage
+	}
+
+	// check the backup schedule
+	if c.Backups.Schedule != "" {
+		if _, err := schedule.Parse(c.Backups.Schedule); err != nil {
+			return nil, fmt.Errorf("%w for backups", err)
+		}
+	}
+
+	// minio will not start with a short password
+	if c.Services.MinioPassword != "" && len(c.Services.MinioPassword) < 8 {
+		return nil, ErrInvalidMinioPassword
+	}
+
+	// check the services in each profile
+	var profiles []string
+	for p := range c.Profiles {
+		profiles = append(profiles, p)
+	}
+	sort.Strings(profiles)
+
+	for _, p := range profiles {
+		if _, err := c.ProfileServices(p); err != nil {
+			return nil, err
+		}
+	}
+
+	// track the first site for each path
+	paths := make(map[string]Site)
+	for _, s := range c.Sites {
+		p, err := s.GetAbsPath(home)
+		if err != nil {
+			return nil, err
+		}
+
+		e, ok := paths[p]
+		if !ok {
+			paths[p] = s
+			continue
+		}
+
+		if e.Version != s.Version {
+			return warnings, fmt.Errorf("%w, %s and %s use PHP %s and %s for %s", ErrConflictingSites, e.Hostname, s.Hostname, e.Version, s.Version, p)
+		}
+
+		if e.Webroot != s.Webroot {
+			return warnings, fmt.Errorf("%w, %s and %s use the web roots %s and %s for %s", ErrConflictingSites, e.Hostname, s.Hostname, e.Webroot, s.Webroot, p)
+		}
+
+		warnings = append(warnings, fmt.Sprintf("sites %s and %s share the path %s", e.Hostname, s.Hostname, p))
+	}
+
+	// check for sites in folders that are synced to the cloud, which are slow to mount
+	for _, s := range c.Sites {
+		p, err := s.GetAbsPath(home)
+		if err != nil {
+			return nil, err
+		}
+
+		// follow symlinks to the real location, missing paths are reported by apply
+		resolved, err := filepath.EvalSymlinks(p)
+		if err != nil {
+			continue
+		}
+
+		service := syncedFolder(resolved)
+		if service == "" {
+			continue
+		}
+
+		if resolved != p {
+			warnings = append(warnings, fmt.Sprintf("site %s path %s is a symlink to %s, which is synced by %s and will be slow to mount", s.Hostname, p, resolved, service))
+			continue
+		}
+
+		warnings = append(warnings, fmt.Sprintf("site %s path %s is synced by %s and will be slow to mount", s.Hostname, p, service))
+	}
+
+	return warnings, nil
+}
+
+// GetTLD returns the top level domain for the config. If the config does not set
+// one, the NITRO_DEFAULT_TLD environment variable or the default is used.
+func (c *Config) GetTLD() string {
+	if c.TLD != "" {
+		return strings.TrimPrefix(c.TLD, ".")
+	}
+
+	if tld := os.Getenv("NITRO_DEFAULT_TLD"); tld != "" {
+		return tld
+	}
+
+	return DefaultTLD
+}
+
+// ProxyHostname returns the hostname with the nitro top level domain replaced by
+// the configs top level domain (e.g. mailhog.service.nitro to mailhog.service.test).
+func (c *Config) ProxyHostname(hostname string) string {
+	return strings.TrimSuffix(hostname, "."+DefaultTLD) + "." + c.GetTLD()
+}
+
+// GetHTTPPort returns the port on the host for HTTP requests to the proxy. If the config
+// does not set one, the NITRO_HTTP_PORT environment variable or 80 is used.
+func (c *Config) GetHTTPPort() string {
+	return proxySetting(c.Proxy.HTTPPort, "NITRO_HTTP_PORT", "80")
+}
+
+// GetHTTPSPort returns the port on the host for HTTPS requests to the proxy. If the config
+// does not set one, the NITRO_HTTPS_PORT environment variable or 443 is used.
+func (c *Config) GetHTTPSPort() string {
+	return proxySetting(c.Proxy.HTTPSPort, "NITRO_HTTPS_PORT", "443")
+}
+
+// GetAPIPort returns the port on the host for the nitrod API. If the config does not
+// set one, the NITRO_API_PORT environment variable or 5000 is used.
+func (c *Config) GetAPIPort() string {
+	return proxySetting(c.Proxy.APIPort, "NITRO_API_PORT", "5000")
+}
+
+// GetDNSPort returns the port on the host for the DNS container. If the config does not set
+// one, the NITRO_DNS_PORT environment variable or the default is used. Windows does not support
+// resolving a domain with a port other than 53, so it is the default on windows.
+func (c *Config) GetDNSPort() string {
+	def := "5354"
+	if runtime.GOOS == "windows" {
+		def = "53"
+	}
+
+	return proxySetting(c.Proxy.DNSPort, "NITRO_DNS_PORT", def)
+}
+
+// GetAPITransport returns how the CLI connects to the nitrod API, either tcp or exec. If the
+// config does not set the transport, the NITRO_API_TRANSPORT environment variable or tcp is used.
+func (c *Config) GetAPITransport() string {
+	return proxySetting(c.Proxy.APITransport, "NITRO_API_TRANSPORT", APITransportTCP)
+}
+
+// SiteURL returns the URL for the hostname with the scheme (http or https) and only
+// includes the port when the proxy does not use the default port for the scheme.
+func (c *Config) SiteURL(scheme, hostname string) string {
+	port, def := c.GetHTTPSPort(), "443"
+	if scheme == "http" {
+		port, def = c.GetHTTPPort(), "80"
+	}
+
+	if port == def {
+		return fmt.Sprintf("%s://%s", scheme, hostname)
+	}
+
+	return fmt.Sprintf("%s://%s:%s", scheme, hostname, port)
+}
+
+func proxySetting(value, env, def string) string {
+	if value != "" {
+		return value
+	}
+
+	if p := os.Getenv(env); p != "" {
+		return p
+	}
+
+	return def
+}
+
+// Problem is an issue found in the config file. Line is the line in the
+// config file, or 0 if the line is not known.
+type Problem struct {
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	if p.Line == 0 {
+		return p.Message
+	}
+
+	return fmt.Sprintf("line %d: %s", p.Line, p.Message)
+}
+
+// Lint checks the config for problems and returns all of the problems, instead of stopping
+// at the first one like Validate. Unknown fields are reported by UnknownFields.
+func (c *Config) Lint(home string) ([]Problem, error) {
+	// find the line for each value
+	lines := make(map[string]int)
+	if c.File != "" {
+		data, err := ioutil.ReadFile(c.File)
+		if err != nil {
+			return nil, err
+		}
+
+		var node yaml.Node
+		if err := yaml.Unmarshal(data, &node); err != nil {
+			return nil, err
+		}
+
+		walkScalars(&node, "", func(path string, n *yaml.Node) {
+			lines[path] = n.Line
+		})
+	}
+
+	var problems []Problem
+	add := func(path string, format string, a ...interface{}) {
+		problems = append(problems, Problem{Line: lines[path], Message: fmt.Sprintf(format, a...)})
+	}
+
+	for i, d := range c.Databases {
+		if d.Port == "" {
+			add(fmt.Sprintf("databases.%d.engine", i), "database %s %s does not have a port", d.Engine, d.Version)
+		}
+	}
+
+	// track the hostnames and aliases that are used
+	names := make(map[string]string)
+	for i, s := range c.Sites {
+		field := func(f string) string {
+			return fmt.Sprintf("sites.%d.%s", i, f)
+		}
+
+		if s.Hostname == "" {
+			add(field("path"), "site %s does not have a hostname", s.Path)
+		} else if e, ok := names[s.Hostname]; ok && e == s.Hostname {
+			add(field("hostname"), "the hostname %s is used by more than one site", s.Hostname)
+		} else if ok {
+			add(field("hostname"), "the hostname %s is already an alias for %s", s.Hostname, e)
+		} else {
+			names[s.Hostname] = s.Hostname
+		}
+
+		for j, a := range s.Aliases {
+			if e, ok := names[a]; ok {
+				add(fmt.Sprintf("%s.%d", field("aliases"), j), "the alias %s for site %s is already used by %s", a, s.Hostname, e)
+				continue
+			}
+
+			names[a] = s.Hostname
+		}
+
+		v := validate.PHPVersionValidator{}
+		if err := v.Validate(s.Version); err != nil {
+			add(field("version"), "%s for site %s", err, s.Hostname)
+		}
+
+		p, err := s.GetAbsPath(home)
+		if err != nil {
+			add(field("path"), "%s for site %s", err, s.Hostname)
+			continue
+		}
+
+		if _, err := os.Stat(p); os.IsNotExist(err) {
+			add(field("path"), "the path %s for site %s does not exist", p, s.Hostname)
+			continue
+		}
+
+		if _, err := os.Stat(filepath.Join(p, s.Webroot)); os.IsNotExist(err) {
+			add(field("webroot"), "the web root %s for site %s does not exist", filepath.Join(p, s.Webroot), s.Hostname)
+		}
+
+		if _, err := s.GetExcludes(home); err != nil {
+			add(field("exclude"), "%s for site %s", err, s.Hostname)
+		}
+	}
+
+	// include the other checks, which stop at the first problem
+	if _, err := c.Validate(home); err != nil {
+		problems = append(problems, Problem{Message: err.Error()})
+	}
+
+	return problems, nil
+}
+
+// syncedFolders are the directories used by cloud storage services. Mounting
+// directories that are synced causes poor performance and file watching issues.
+var syncedFolders = []struct {
+	service string
+	dir     string
+}{
+	{service: "iCloud", dir: "Library/Mobile Documents"},
+	{service: "iCloud", dir: "iCloud Drive"},
+	{service: "Dropbox", dir: "Dropbox"},
+	{service: "Google Drive", dir: "Google Drive"},
+	{service: "Google Drive", dir: "GoogleDrive"},
+	{service: "OneDrive", dir: "OneDrive"},
+}
+
+// syncedFolder returns the name of the service that syncs the path, or an
+// empty string if the path is not in a known synced folder. Folders can
+// have a suffix for the account (e.g. "Dropbox (Personal)" or
+// "~/Library/CloudStorage/GoogleDrive-name@example.com").
+func syncedFolder(path string) string {
+	p := filepath.ToSlash(path)
+
+	for _, f := range syncedFolders {
+		if strings.Contains(f.dir, "/") {
+			if strings.Contains(p+"/", "/"+f.dir+"/") {
+				return f.service
+			}
+
+			continue
+		}
+
+		for _, part := range strings.Split(p, "/") {
+			if part == f.dir || strings.HasPrefix(part, f.dir+" ") || strings.HasPrefix(part, f.dir+"-") {
+				return f.service
+			}
+		}
+	}
+
+	// other providers use the cloud storage folder on macOS
+	if strings.Contains(p+"/", "/Library/CloudStorage/") {
+		return "a cloud storage provider"
+	}
+
+	return ""
+}
+
+// DependencyContainerName takes the name of a site dependency and
+// returns the container name for it. A dependency can be a site
+// hostname, custom container name, database hostname or engine and
+// version (e.g. mysql-8.0-3306 or mysql-8.0), or an enabled service
+// (e.g. redis).
+func (c *Config) DependencyContainerName(name string) (string, error) {
+	for _, s := range c.Sites {
+		if s.Hostname == name {
+			return s.Hostname, nil
+		}
+	}
+
+	for _, ct := range c.Containers {
+		if ct.Name == name {
+			return ct.Name + ".containers.nitro", nil
+		}
+	}
+
+	for _, d := range c.Databases {
+		h, err := d.GetHostname()
+		if err != nil {
+			continue
+		}
+
+		if h == name || fmt.Sprintf("%s-%s-%s", d.Engine, d.Version, d.Port) == name || fmt.Sprintf("%s-%s", d.Engine, d.Version) == name {
+			return h, nil
+		}
+	}
+
+	services := map[string]bool{
+		"dynamodb":      c.Services.DynamoDB,
+		"elasticsearch": c.Services.Elasticsearch,
+		"mailhog":       c.Services.Mailhog,
+		"meilisearch":   c.Services.Meilisearch,
+		"minio":         c.Services.Minio,
+		"rabbitmq":      c.Services.RabbitMQ,
+		"redis":         c.Services.Redis,
+	}
+	if services[name] {
+		return name + ".service.nitro", nil
+	}
+
+	return "", fmt.Errorf("unknown dependency %q", name)
+}
+
+// SitesInOrder returns the sites ordered so each site comes after the
+// sites it depends on, otherwise the order of the config is kept. It
+// returns an error if there is a dependency cycle.
+func (c *Config) SitesInOrder() ([]Site, error) {
+	sites := make(map[string]Site)
+	for _, s := range c.Sites {
+		sites[s.Hostname] = s
+	}
+
+	var ordered []Site
+	visited := make(map[string]bool)
+	visiting := make(map[string]bool)
+
+	var visit func(s Site, path []string) error
+	visit = func(s Site, path []string) error {
+		if visited[s.Hostname] {
+			return nil
+		}
+
+		path = append(path, s.Hostname)
+		if visiting[s.Hostname] {
+			return fmt.Errorf("%w, %s", ErrDependencyCycle, strings.Join(path, " -> "))
+		}
+
+		visiting[s.Hostname] = true
+
+		// only other sites need to be ordered, everything else is started first
+		for _, d := range s.DependsOn {
+			if dep, ok := sites[d]; ok {
+				if err := visit(dep, path); err != nil {
+					return err
+				}
+			}
+		}
+
+		visiting[s.Hostname] = false
+		visited[s.Hostname] = true
+		ordered = append(ordered, s)
+
+		return nil
+	}
+
+	for _, s := range c.Sites {
+		if err := visit(s, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return ordered, nil
+}
+
+// ValidateTimezone checks that the timezone is a name from the tz
+// database (e.g. America/Chicago). An empty timezone is valid and
+// will use the containers default of UTC.
+func ValidateTimezone(tz string) error {
+	if tz == "" {
+		return nil
+	}
+
+	// local is valid for LoadLocation but means nothing in the container
+	if tz == "Local" {
+		return fmt.Errorf("%w %q", ErrInvalidTimezone, tz)
+	}
+
+	if _, err := time.LoadLocation(tz); err != nil {
+		return fmt.Errorf("%w %q", ErrInvalidTimezone, tz)
+	}
+
+	return nil
+}
+
+// Backups are the settings for scheduled database backups and the retention
+// policy. The schedule is a cron expression (e.g. "0 */6 * * *"), keep is the
+// number of backups to keep for each database, and keep days is the number of
+// days to keep backups. Zero values keep all of the backups.
+type Backups struct {
+	Schedule string `json:"schedule,omitempty" yaml:"schedule,omitempty"`
+	Keep     int    `json:"keep,omitempty" yaml:"keep,omitempty"`
+	KeepDays int    `json:"keep_days,omitempty" yaml:"keep_days,omitempty"`
+
+	// Storage is the bucket to share backups with nitro backups push and pull.
+	Storage *Storage `json:"storage,omitempty" yaml:"storage,omitempty"`
+}
+
+// Hooks are commands that run on the host with the shell during apply (e.g.
+// composer install). Pre apply commands run before any containers are changed
+// and post apply commands run after the proxy is updated.
+type Hooks struct {
+	PreApply  []string `json:"pre_apply,omitempty" yaml:"pre_apply,omitempty"`
+	PostApply []string `json:"post_apply,omitempty" yaml:"post_apply,omitempty"`
+}
+
+// BasicAuth is the user and password for http basic authentication.
+type BasicAuth struct {
+	User     string `json:"user" yaml:"user"`
+	Password string `json:"password" yaml:"password"`
+}
+
+// Limits are the nginx limits for a site. MaxBodySize is a size (e.g. 512M)
+// and the timeouts are durations (e.g. 10m), empty values use the defaults.
+type Limits struct {
+	MaxBodySize string `json:"max_body_size,omitempty" yaml:"max_body_size,omitempty"`
+	ReadTimeout string `json:"read_timeout,omitempty" yaml:"read_timeout,omitempty"`
+	SendTimeout string `json:"send_timeout,omitempty" yaml:"send_timeout,omitempty"`
+}
+
+// Storage is an S3 compatible bucket (e.g. AWS S3 or MinIO) for sharing backups
+// with a team. When upload is true, backups are uploaded after they are created.
+type Storage struct {
+	Endpoint  string `json:"endpoint" yaml:"endpoint"`
+	Region    string `json:"region,omitempty" yaml:"region,omitempty"`
+	Bucket    string `json:"bucket" yaml:"bucket"`
+	Prefix    string `json:"prefix,omitempty" yaml:"prefix,omitempty"`
+	AccessKey string `json:"access_key,omitempty" yaml:"access_key,omitempty"`
+	SecretKey string `json:"secret_key,omitempty" yaml:"secret_key,omitempty"`
+	Upload    bool   `json:"upload,omitempty" yaml:"upload,omitempty"`
+}
+
+// Blackfire allows users to setup their containers to use blackfire locally.
+type Blackfire struct {
+	ServerID    string `json:"server_id,omitempty" yaml:"server_id,omitempty"`
+	ServerToken string `json:"server_token,omitempty" yaml:"server_token,omitempty"`
+}
+
+// Container represents a custom container to add to nitro. Containers can be
+// publicly hosted on Docker Hub.
+type Container struct {
+	// Name is a uniq name, with no spaces or special characters and is used to generate the hostname
+	Name string `json:"name" yaml:"name"`
+
+	// Image the is canonical name of the image to use for the container `docker.elastic.co/elasticsearch/elasticsearch`
+	Image string `json:"image" yaml:"image"`
+
+	// Tag tells Nitro which docker image tag to use, it defaults to latest.
+	Tag string `json:"tag" yaml:"tag"`
+
+	// Ports is used to expose ports on the host machine to the
+	// containers port in the <host>:<container> syntax
+	Ports []string `json:"ports,omitempty" yaml:"ports,omitempty"`
+
+	// Volume stores the volumes we should create and maintain for the container (e.g. <name>_container_<vol>_nitro_volume)
+	Volumes []string `json:"volumes,omitempty" yaml:"volumes,omitempty"`
+
+	WebGui  int    `json:"web_gui,omitempty" yaml:"web_gui,omitempty"`
+	EnvFile string `json:"env_file,omitempty" yaml:"env_file,omitempty"`
+
+	// Platform is the image platform to use (e.g. linux/amd64), it defaults to the host platform.
+	Platform string `json:"platform,omitempty" yaml:"platform,omitempty"`
+
+	// Env are environment variables for the container, values in the EnvFile
+	// are added after these variables.
+	Env map[string]string `json:"env,omitempty" yaml:"env,omitempty"`
+}
+
+// Hash returns a hash of the containers config, which is used to label the
+// container and recreate it when the image, ports, volumes, or env change.
+func (c Container) Hash() string {
+	return hash(c)
+}
+
+// AddContainer adds a new container config to an config. It will validate there are no other
+// container names to avoid colision or duplicate ports.
+func (c *Config) AddContainer(container Container) error {
+	for _, e := range c.Containers {
+		// check the containers name
+		if e.Name == container.Name {
+			return fmt.Errorf("an existing container %q already exists", e.Name)
+		}
+
+		// TODO(jasonmccallister) check is a port already is in use
+	}
+
+	c.Containers = append(c.Containers, container)
+
+	sort.SliceStable(c.Containers, func(i, j int) bool {
+		return c.Containers[i].Name < c.Containers[j].Name
+	})
+
+	return nil
+}
+
+// Database is the struct used to represent a database engine
+// that is a combination of a engine (e.g. mariadb, mysql, or
+// postgres), the version number, and the port. The engine
+// and version are directly related to the official docker
+// images on the docker hub. The platform can be set for
+// images that do not support the host architecture.
+type Database struct {
+	Engine   string `json:"engine" yaml:"engine"`
+	Version  string `json:"version" yaml:"version"`
+	Port     string `json:"port" yaml:"port"`
+	Platform string `json:"platform,omitempty" yaml:"platform,omitempty"`
+
+	// SkipBackup disables the automatic backup when the database is removed from
+	// the config. The databases will be lost when the container is removed.
+	SkipBackup bool `json:"skip_backup,omitempty" yaml:"skip_backup,omitempty"`
+
+	// CPUs and Memory limit the resources for the database container (e.g.
+	// 1.5 and 2G), empty values do not limit the container.
+	CPUs   float64 `json:"cpus,omitempty" yaml:"cpus,omitempty"`
+	Memory string  `json:"memory,omitempty" yaml:"memory,omitempty"`
+}
+
+// GetHostname returns a friendly and predictable name for a database
+// container. It is used for accessing a database by hostname. For
+// example, mysql-8.0-3306 would be the hostname to use in the .env
+// for DB_HOST.
+func (d *Database) GetHostname() (string, error) {
+	if d.Engine == "" || d.Version == "" || d.Port == "" {
+		return "", fmt.Errorf("the engine, version, and port must be defined for the database")
+	}
+
+	return fmt.Sprintf("%s-%s-%s.database.nitro", d.Engine, d.Version, d.Port), nil
+}
+
+// Hash returns a hash of the database settings. It is stored as a label
+// on the container to detect when the config has changed since the
+// container was created. The resource limits are ignored because they
+// are updated without recreating the container, and skip backup is
+// ignored because it is stored in its own label.
+func (d Database) Hash() string {
+	d.CPUs = 0
+	d.Memory = ""
+	d.SkipBackup = false
+
+	return hash(d)
+}
+
+// Network is the docker network settings for the environment.
+type Network struct {
+	// External is the name of an existing network that site and service
+	// containers will join in addition to the nitro network. Nitro does
+	// not create or remove the external network.
+	External string `json:"external,omitempty" yaml:"external,omitempty"`
+}
+
+// Proxy is the ports on the host the nitro proxy binds to, empty ports
+// use the environment variables or the defaults.
+type Proxy struct {
+	HTTPPort  string `json:"http_port,omitempty" yaml:"http_port,omitempty"`
+	HTTPSPort string `json:"https_port,omitempty" yaml:"https_port,omitempty"`
+	APIPort   string `json:"api_port,omitempty" yaml:"api_port,omitempty"`
+
+	// APITransport is how the CLI connects to the nitrod API, tcp uses the api
+	// port and exec tunnels through docker exec without binding a port.
+	APITransport string `json:"api_transport,omitempty" yaml:"api_transport,omitempty"`
+
+	// LAN binds the HTTP and HTTPS ports on all interfaces so other devices on the
+	// network can reach the sites, the API port is always bound to localhost.
+	LAN bool `json:"lan,omitempty" yaml:"lan,omitempty"`
+
+	// DNS runs a DNS container that resolves the top level domain to localhost,
+	// so the hosts file does not need to be edited for the sites.
+	DNS     bool   `json:"dns,omitempty" yaml:"dns,omitempty"`
+	DNSPort string `json:"dns_port,omitempty" yaml:"dns_port,omitempty"`
+}
+
+// HostProxy routes the hostname through the proxy, with a trusted
+// certificate, to the port on the host machine.
+type HostProxy struct {
+	Hostname string `json:"hostname" yaml:"hostname"`
+	Port     int    `json:"port" yaml:"port"`
+}
+
+// Services define common tools for development that should run as containers. We don't expose the volumes, ports, and
+// networking options for these types of services. We plan to support "custom" container options to make local users
+// development even better.
+type Services struct {
+	DynamoDB bool `json:"dynamodb"`
+	Mailhog  bool `json:"mailhog"`
+	Minio    bool `json:"minio"`
+	Redis    bool `json:"redis"`
+
+	// RedisVersion is the version of the redis image (e.g. 6.2), the latest
+	// version is used when it is not set.
+	RedisVersion string `json:"redis_version,omitempty" yaml:"redis_version,omitempty"`
+
+	// Elasticsearch enables a single node elasticsearch service, the version
+	// and java heap size (e.g. 1g) use the defaults when they are not set.
+	Elasticsearch         bool   `json:"elasticsearch,omitempty" yaml:"elasticsearch,omitempty"`
+	ElasticsearchVersion  string `json:"elasticsearch_version,omitempty" yaml:"elasticsearch_version,omitempty"`
+	ElasticsearchHeapSize string `json:"elasticsearch_heap_size,omitempty" yaml:"elasticsearch_heap_size,omitempty"`
+
+	// MinioUser and MinioPassword are the root credentials for minio, the
+	// defaults are nitro and nitropassword when they are not set.
+	MinioUser     string `json:"minio_user,omitempty" yaml:"minio_user,omitempty"`
+	MinioPassword string `json:"minio_password,omitempty" yaml:"minio_password,omitempty"`
+
+	// RabbitMQ enables a rabbitmq service with the management ui.
+	RabbitMQ bool `json:"rabbitmq,omitempty" yaml:"rabbitmq,omitempty"`
+
+	// Meilisearch enables a meilisearch service, the master key is passed to
+	// sites as MEILISEARCH_MASTER_KEY and authentication is disabled without it.
+	Meilisearch          bool   `json:"meilisearch,omitempty" yaml:"meilisearch,omitempty"`
+	MeilisearchMasterKey string `json:"meilisearch_master_key,omitempty" yaml:"meilisearch_master_key,omitempty"`
+}
+
+// ServiceNames are the names of the services that can be used in a profile.
+var ServiceNames = []string{"dynamodb", "elasticsearch", "mailhog", "meilisearch", "minio", "rabbitmq", "redis"}
+
+// PHPExtensions are the extensions that can be added to a site with the extensions
+// command, they are installed with docker-php-ext-install when apply creates the site.
+var PHPExtensions = []string{
+	"bcmath",
+	"bz2",
+	"calendar",
+	"dba",
+	"enchant",
+	"exif",
+	"gettext",
+	"gmp",
+	"imap",
+	"interbase",
+	"ldap",
+	"mysqli",
+	"oci8",
+	"odbc",
+	"pcntl",
+	"pdo_dblib",
+	"pdo_firebird",
+	"pdo_oci",
+	"pdo_odbc",
+	"pdo_sqlite",
+	"recode",
+	"shmop",
+	"snmp",
+	"sockets",
+	"sysvmsg",
+	"sysvsem",
+	"sysvshm",
+	"tidy",
+	"wddx",
+	"xmlrpc",
+	"xsl",
+	"zend_test",
+}
+
+// Enable enables a service by name.
+func (s *Services) Enable(name string) error {
+	switch name {
+	case "dynamodb":
+		s.DynamoDB = true
+	case "elasticsearch":
+		s.Elasticsearch = true
+	case "mailhog":
+		s.Mailhog = true
+	case "meilisearch":
+		s.Meilisearch = true
+	case "minio":
+		s.Minio = true
+	case "rabbitmq":
+		s.RabbitMQ = true
+	case "redis":
+		s.Redis = true
+	default:
+		return fmt.Errorf("%w %q, the services are %s", ErrUnknownService, name, strings.Join(ServiceNames, ", "))
+	}
+
+	return nil
+}
+
+// ProfileServices returns the services for a profile. Services that are
+// not in the profile are disabled, even if they are enabled in Services.
+// The service settings (e.g. the redis version) are kept from Services.
+func (c *Config) ProfileServices(profile string) (Services, error) {
+	names, ok := c.Profiles[profile]
+	if !ok {
+		var profiles []string
+		for p := range c.Profiles {
+			profiles = append(profiles, p)
+		}
+		sort.Strings(profiles)
+
+		if len(profiles) == 0 {
+			return Services{}, fmt.Errorf("%w %q, there are no profiles in the config", ErrUnknownProfile, profile)
+		}
+
+		return Services{}, fmt.Errorf("%w %q, the profiles are %s", ErrUnknownProfile, profile, strings.Join(profiles, ", "))
+	}
+
+	// only the enabled services change, the settings are kept
+	services := c.Services
+	services.DynamoDB = false
+	services.Elasticsearch = false
+	services.Mailhog = false
+	services.Meilisearch = false
+	services.Minio = false
+	services.RabbitMQ = false
+	services.Redis = false
+
+	for _, n := range names {
+		if err := services.Enable(n); err != nil {
+			return Services{}, fmt.Errorf("%w in profile %s", err, profile)
+		}
+	}
+
+	return services, nil
+}
+
+// UseProfile replaces the enabled services with the services from the
+// profile. An empty profile keeps the services from the config.
+func (c *Config) UseProfile(profile string) error {
+	if profile == "" {
+		return nil
+	}
+
+	services, err := c.ProfileServices(profile)
+	if err != nil {
+		return err
+	}
+
+	c.Services = services
+
+	return nil
+}
+
+// Site represents a web application. It has a hostname, aliases (which
+// are alternate domains), the local path to the site, additional mounts
+// to add to the container, and the directory the index.php is located.
+type Site struct {
+	Hostname   string   `json:"hostname" yaml:"hostname"`
+	Aliases    []string `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+	Path       string   `json:"path" yaml:"path"`
+	Version    string   `json:"version" yaml:"version"`
+	PHP        PHP      `json:"php,omitempty" yaml:"php,omitempty"`
+	Extensions []string `json:"extensions,omitempty" yaml:"extensions,omitempty"`
+	Webroot    string   `json:"webroot" yaml:"webroot"`
+	Xdebug     bool     `json:"xdebug" yaml:"xdebug"`
+	Blackfire  bool     `json:"blackfire" yaml:"blackfire"`
+	Exclude    []string `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+	Timezone   string   `json:"timezone,omitempty" yaml:"timezone,omitempty"`
+	DependsOn  []string `json:"depends_on,omitempty" yaml:"depends_on,omitempty"`
+
+	// Remote is the server for the site (e.g. production) that
+	// databases are pulled from with nitro db pull.
+	Remote *Remote `json:"remote,omitempty" yaml:"remote,omitempty"`
+
+	// Env are environment variables for the sites container, they
+	// replace the PHP and service variables with the same name.
+	Env map[string]string `json:"env,omitempty" yaml:"env,omitempty"`
+
+	// Nginx are directives for the sites nginx server block (e.g. redirects,
+	// headers, or locations) or a path to a file with the directives.
+	Nginx string `json:"nginx,omitempty" yaml:"nginx,omitempty"`
+
+	// PostStart are commands that run in the sites container when apply
+	// creates or starts the container (e.g. php craft migrate/all).
+	PostStart []string `json:"post_start,omitempty" yaml:"post_start,omitempty"`
+
+	// BasicAuth requires a user and password for the site, the proxy
+	// checks the credentials before any requests reach the site.
+	BasicAuth *BasicAuth `json:"basic_auth,omitempty" yaml:"basic_auth,omitempty"`
+
+	// Limits sets the largest request body and the timeouts for the
+	// site, so large uploads and long requests do not return 413 or 504.
+	Limits *Limits `json:"limits,omitempty" yaml:"limits,omitempty"`
+
+	// WebSockets forwards websocket connections for the site to a server
+	// in the sites container (e.g. a Pusher compatible server) on the
+	// WebSocketsPort, which defaults to 6001.
+	WebSockets     bool `json:"websockets,omitempty" yaml:"websockets,omitempty"`
+	WebSocketsPort int  `json:"websockets_port,omitempty" yaml:"websockets_port,omitempty"`
+
+	// Certificate and Key are the paths to a PEM certificate and key (e.g.
+	// from mkcert) the proxy uses for the site instead of the nitro CA.
+	// Relative paths are in the sites path.
+	Certificate string `json:"certificate,omitempty" yaml:"certificate,omitempty"`
+	Key         string `json:"key,omitempty" yaml:"key,omitempty"`
+
+	// XdebugProfile switches xdebug into profile or trace mode, the files
+	// are written to the xdebug directory for the site in the nitro home.
+	XdebugProfile string `json:"xdebug_profile,omitempty" yaml:"xdebug_profile,omitempty"`
+
+	// SSHAgent forwards the ssh agent on the host into the sites container,
+	// so composer and git can use the hosts keys for private repositories.
+	SSHAgent bool `json:"ssh_agent,omitempty" yaml:"ssh_agent,omitempty"`
+
+	// CPUs and Memory limit the resources for the sites container (e.g. 1.5
+	// and 2G), so a runaway process cannot use all of the resources for Docker.
+	CPUs   float64 `json:"cpus,omitempty" yaml:"cpus,omitempty"`
+	Memory string  `json:"memory,omitempty" yaml:"memory,omitempty"`
+}
+
+// XdebugDir returns the directory on the host that xdebug profiles and traces
+// for the site are written to (e.g. ~/.nitro/xdebug/tutorial.nitro).
+func (s *Site) XdebugDir(home string) string {
+	return filepath.Join(home, DirectoryName, "xdebug", s.Hostname)
+}
+
+// Remote is a server that is connected to over SSH to dump a database. The
+// database settings are used on the remote server, the database host defaults
+// to the remote server (127.0.0.1) and the engine defaults to mysql.
+type Remote struct {
+	Host             string `json:"host" yaml:"host"`
+	User             string `json:"user,omitempty" yaml:"user,omitempty"`
+	Port             int    `json:"port,omitempty" yaml:"port,omitempty"`
+	IdentityFile     string `json:"identity_file,omitempty" yaml:"identity_file,omitempty"`
+	Engine           string `json:"engine,omitempty" yaml:"engine,omitempty"`
+	Database         string `json:"database" yaml:"database"`
+	DatabaseHost     string `json:"database_host,omitempty" yaml:"database_host,omitempty"`
+	DatabasePort     int    `json:"database_port,omitempty" yaml:"database_port,omitempty"`
+	DatabaseUser     string `json:"database_user,omitempty" yaml:"database_user,omitempty"`
+	DatabasePassword string `json:"database_password,omitempty" yaml:"database_password,omitempty"`
+}
+
+// GetNginx returns the nginx directives for the site’s server block. The
+// nginx setting can be the directives or a path to a file that contains the
+// directives, relative paths are relative to the site’s path.
+func (s *Site) GetNginx(home string) (string, error) {
+	n := strings.TrimSpace(s.Nginx)
+	if n == "" {
+		return "", nil
+	}
+
+	// directives end with a semicolon or use a block
+	if strings.ContainsAny(n, ";{}\n") {
+		return n, nil
+	}
+
+	file, err := s.sitePath(home, n)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("unable to read the nginx file for site %s, %w", s.Hostname, err)
+	}
+
+	return strings.TrimSpace(string(content)), nil
+}
+
+// GetCertificate reads the sites certificate and key files. If the site does not
+// set a certificate, it returns nil.
+func (s *Site) GetCertificate(home string) ([]byte, []byte, error) {
+	if s.Certificate == "" && s.Key == "" {
+		return nil, nil, nil
+	}
+
+	var files [][]byte
+	for _, p := range []string{s.Certificate, s.Key} {
+		file, err := s.sitePath(home, p)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to read the certificate for site %s, %w", s.Hostname, err)
+		}
+
+		files = append(files, content)
+	}
+
+	return files[0], files[1], nil
+}
+
+// sitePath returns the absolute path for a file, relative paths are in the sites path.
+func (s *Site) sitePath(home, path string) (string, error) {
+	if !filepath.IsAbs(path) && !strings.HasPrefix(path, "~") {
+		dir, err := s.GetAbsPath(home)
+		if err != nil {
+			return "", err
+		}
+
+		path = filepath.Join(dir, path)
+	}
+
+	return cleanPath(home, path)
+}
+
+// LoadProject reads the project file in the directory. The project file has the
+// same settings as a site in the config. If there is no project file, it returns nil.
+func LoadProject(dir string) (*Site, error) {
+	content, err := ioutil.ReadFile(filepath.Join(dir, ProjectFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	project := &Site{}
+	if err := yaml.Unmarshal(content, project); err != nil {
+		return nil, fmt.Errorf("unable to read the project file %s, %w", filepath.Join(dir, ProjectFileName), err)
+	}
+
+	return project, nil
+}
+
+// Merge returns the site with the settings from a project file. The project settings replace
+// the sites settings, except for the hostname and path, and bool settings are enabled if either
+// enables them. The environment variables are combined with the project variables replacing the
+// sites variables.
+func (s Site) Merge(project Site) Site {
+	if len(project.Aliases) > 0 {
+		s.Aliases = project.Aliases
+	}
+
+	if project.Version != "" {
+		s.Version = project.Version
+	}
+
+	s.PHP = s.PHP.Override(project.PHP)
+
+	if len(project.Extensions) > 0 {
+		s.Extensions = project.Extensions
+	}
+
+	if project.Webroot != "" {
+		s.Webroot = project.Webroot
+	}
+
+	s.Xdebug = s.Xdebug || project.Xdebug
+	s.Blackfire = s.Blackfire || project.Blackfire
+	s.WebSockets = s.WebSockets || project.WebSockets
+	s.SSHAgent = s.SSHAgent || project.SSHAgent
+
+	if len(project.Exclude) > 0 {
+		s.Exclude = project.Exclude
+	}
+
+	if project.Timezone != "" {
+		s.Timezone = project.Timezone
+	}
+
+	if len(project.DependsOn) > 0 {
+		s.DependsOn = project.DependsOn
+	}
+
+	if project.Remote != nil {
+		s.Remote = project.Remote
+	}
+
+	if len(project.Env) > 0 {
+		env := make(map[string]string)
+		for k, v := range s.Env {
+			env[k] = v
+		}
+
+		for k, v := range project.Env {
+			env[k] = v
+		}
+
+		s.Env = env
+	}
+
+	if project.Nginx != "" {
+		s.Nginx = project.Nginx
+	}
+
+	if len(project.PostStart) > 0 {
+		s.PostStart = project.PostStart
+	}
+
+	if project.BasicAuth != nil {
+		s.BasicAuth = project.BasicAuth
+	}
+
+	if project.Limits != nil {
+		s.Limits = project.Limits
+	}
+
+	if project.WebSocketsPort != 0 {
+		s.WebSocketsPort = project.WebSocketsPort
+	}
+
+	if project.Certificate != "" {
+		s.Certificate = project.Certificate
+		s.Key = project.Key
+	}
+
+	if project.CPUs != 0 {
+		s.CPUs = project.CPUs
+	}
+
+	if project.Memory != "" {
+		s.Memory = project.Memory
+	}
+
+	return s
+}
+
+// MergeProjects merges the project file in each site’s path into the config. It
+// returns the hostnames of the sites that have a project file.
+func (c *Config) MergeProjects(home string) ([]string, error) {
+	var merged []string
+	for i, s := range c.Sites {
+		path, err := s.GetAbsPath(home)
+		if err != nil {
+			return nil, err
+		}
+
+		project, err := LoadProject(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if project == nil {
+			continue
+		}
+
+		c.Sites[i] = s.Merge(*project)
+
+		merged = append(merged, s.Hostname)
+	}
+
+	return merged, nil
+}
+
+// GetExcludes returns the glob patterns that should be excluded from
+// the site’s mount. It combines the exclude list from the config with
+// any patterns in the site’s .nitroignore file and returns an error if
+// a pattern is not valid.
+func (s *Site) GetExcludes(home string) ([]string, error) {
+	patterns := append([]string{}, s.Exclude...)
+
+	path, err := s.GetAbsPath(home)
+	if err != nil {
+		return nil, err
+	}
+
+	// check for an ignore file in the site’s path
+	content, err := ioutil.ReadFile(filepath.Join(path, IgnoreFileName))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	for _, l := range strings.Split(string(content), "\n") {
+		l = strings.TrimSpace(l)
+
+		// skip empty lines and comments
+		if l == "" || strings.HasPrefix(l, "#") {
+			continue
+		}
+
+		patterns = append(patterns, l)
+	}
+
+	// remove duplicates and validate the patterns
+	seen := make(map[string]bool)
+	var excludes []string
+	for _, p := range patterns {
+		p = strings.Trim(filepath.ToSlash(p), "/")
+		if p == "" || seen[p] {
+			continue
 		}
 
-		// Return the single, most specific found item if we have one.
-		if bestMatches == 1 {
-			return []Site{b}
+		if _, err := filepath.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern %q for site %s: %w", p, s.Hostname, err)
 		}
 
-		return found
+		seen[p] = true
+		excludes = append(excludes, p)
 	}
 
-	// Otherwise just return all the sites.
-	return c.Sites
-}
-
-// Blackfire allows users to setup their containers to use blackfire locally.
-type Blackfire struct {
-	ServerID    string `json:"server_id,omitempty" yaml:"server_id,omitempty"`
-	ServerToken string `json:"server_token,omitempty" yaml:"server_token,omitempty"`
+	return excludes, nil
 }
 
-// Container represents a custom container to add to nitro. Containers can be
-// publicly hosted on Docker Hub.
-type Container struct {
-	// Name is a uniq name, with no spaces or special characters and is used to generate the hostname
-	Name string `json:"name" yaml:"name"`
-
-	// Image the is canonical name of the image to use for the container `docker.elastic.co/elasticsearch/elasticsearch`
-	Image string `json:"image" yaml:"image"`
+// GetExcludedDirs returns the directories, relative to the site’s path
+// and using forward slashes, that match the site’s exclude patterns.
+func (s *Site) GetExcludedDirs(home string) ([]string, error) {
+	excludes, err := s.GetExcludes(home)
+	if err != nil {
+		return nil, err
+	}
 
-	// Tag tells Nitro which docker image tag to use, it defaults to latest.
-	Tag string `json:"tag" yaml:"tag"`
+	path, err := s.GetAbsPath(home)
+	if err != nil {
+		return nil, err
+	}
 
-	// Ports is used to expose ports on the host machine to the
-	// containers port in the <host>:<container> syntax
-	Ports []string `json:"ports,omitempty" yaml:"ports,omitempty"`
+	seen := make(map[string]bool)
+	var dirs []string
+	for _, e := range excludes {
+		matches, err := filepath.Glob(filepath.Join(path, filepath.FromSlash(e)))
+		if err != nil {
+			return nil, err
+		}
 
-	// Volume stores the volumes we should create and maintain for the container (e.g. <name>_container_<vol>_nitro_volume)
-	Volumes []string `json:"volumes,omitempty" yaml:"volumes,omitempty"`
+		for _, m := range matches {
+			// only directories can be excluded from the mount
+			if info, err := os.Stat(m); err != nil || !info.IsDir() {
+				continue
+			}
 
-	WebGui  int    `json:"web_gui,omitempty" yaml:"web_gui,omitempty"`
-	EnvFile string `json:"env_file,omitempty" yaml:"env_file,omitempty"`
-}
+			rel, err := filepath.Rel(path, m)
+			if err != nil {
+				return nil, err
+			}
 
-// AddContainer adds a new container config to an config. It will validate there are no other
-// container names to avoid colision or duplicate ports.
-func (c *Config) AddContainer(container Container) error {
-	for _, e := range c.Containers {
-		// check the containers name
-		if e.Name == container.Name {
-			return fmt.Errorf("an existing container %q already exists", e.Name)
+			rel = filepath.ToSlash(rel)
+			if !seen[rel] {
+				seen[rel] = true
+				dirs = append(dirs, rel)
+			}
 		}
-
-		// TODO(jasonmccallister) check is a port already is in use
 	}
 
-	c.Containers = append(c.Containers, container)
-
-	sort.SliceStable(c.Containers, func(i, j int) bool {
-		return c.Containers[i].Name < c.Containers[j].Name
-	})
+	sort.Strings(dirs)
 
-	return nil
+	return dirs, nil
 }
 
-// Database is the struct used to represent a database engine
-// that is a combination of a engine (e.g. mariadb, mysql, or
-// postgres), the version number, and the port. The engine
-// and version are directly related to the official docker
-// images on the docker hub.
-type Database struct {
-	Engine  string `json:"engine" yaml:"engine"`
-	Version string `json:"version" yaml:"version"`
-	Port    string `json:"port" yaml:"port"`
+// Hash returns a hash of the site settings. It is stored as a label on
+// the container to detect when the config has changed since the container
+// was created. The dependencies, remote, post start commands, and basic
+// auth are ignored because they do not change the container.
+func (s Site) Hash() string {
+	s.DependsOn = nil
+	s.Remote = nil
+	s.PostStart = nil
+	s.BasicAuth = nil
+	s.WebSockets = false
+	s.WebSocketsPort = 0
+	s.Certificate = ""
+	s.Key = ""
+
+	return hash(s)
 }
 
-// GetHostname returns a friendly and predictable name for a database
-// container. It is used for accessing a database by hostname. For
-// example, mysql-8.0-3306 would be the hostname to use in the .env
-// for DB_HOST.
-func (d *Database) GetHostname() (string, error) {
-	if d.Engine == "" || d.Version == "" || d.Port == "" {
-		return "", fmt.Errorf("the engine, version, and port must be defined for the database")
+// GetWebSocketsPort returns the port websocket connections are forwarded to,
+// or 0 when websockets are not enabled for the site.
+func (s *Site) GetWebSocketsPort() int {
+	if !s.WebSockets {
+		return 0
 	}
 
-	return fmt.Sprintf("%s-%s-%s.database.nitro", d.Engine, d.Version, d.Port), nil
-}
-
-// Services define common tools for development that should run as containers. We don't expose the volumes, ports, and
-// networking options for these types of services. We plan to support "custom" container options to make local users
-// development even better.
-type Services struct {
-	DynamoDB bool `json:"dynamodb"`
-	Mailhog  bool `json:"mailhog"`
-	Minio    bool `json:"minio"`
-	Redis    bool `json:"redis"`
-}
+	if s.WebSocketsPort == 0 {
+		return DefaultWebSocketsPort
+	}
 
-// Site represents a web application. It has a hostname, aliases (which
-// are alternate domains), the local path to the site, additional mounts
-// to add to the container, and the directory the index.php is located.
-type Site struct {
-	Hostname   string   `json:"hostname" yaml:"hostname"`
-	Aliases    []string `json:"aliases,omitempty" yaml:"aliases,omitempty"`
-	Path       string   `json:"path" yaml:"path"`
-	Version    string   `json:"version" yaml:"version"`
-	PHP        PHP      `json:"php,omitempty" yaml:"php,omitempty"`
-	Extensions []string `json:"extensions,omitempty" yaml:"extensions,omitempty"`
-	Webroot    string   `json:"webroot" yaml:"webroot"`
-	Xdebug     bool     `json:"xdebug" yaml:"xdebug"`
-	Blackfire  bool     `json:"blackfire" yaml:"blackfire"`
+	return s.WebSocketsPort
 }
 
 // GetAbsPath gets the directory for a site.Path,
@@ -295,7 +1763,17 @@ func (s *Site) AsEnvs(addr string) []string {
 	// set the php vars
 	envs = append(envs, phpVars(s.PHP, s.Version)...)
 
-	return append(envs, xdebugVars(s.PHP, s.Xdebug, s.Version, s.Hostname, addr)...)
+	// set the timezone, the container defaults to UTC
+	if s.Timezone != "" {
+		envs = append(envs, "TZ="+s.Timezone)
+	}
+
+	// point ssh and git to the forwarded agent
+	if s.SSHAgent {
+		envs = append(envs, "SSH_AUTH_SOCK="+SSHAgentSocket)
+	}
+
+	return append(envs, xdebugVars(s.PHP, s.Xdebug, s.XdebugProfile, s.Version, s.Hostname, addr)...)
 }
 
 // SetPHPBoolSetting is used to set php settings that are bool. It will look
@@ -469,6 +1947,51 @@ type PHP struct {
 	UploadMaxFileSize         string `json:"upload_max_file_size,omitempty" yaml:"upload_max_file_size,omitempty"`
 }
 
+// Override returns the php settings with the sites settings replacing the
+// defaults. Settings the site does not set use the defaults, bool settings
+// are enabled if either the defaults or the site enables them.
+func (p PHP) Override(site PHP) PHP {
+	merged := p
+
+	merged.DisplayErrors = p.DisplayErrors || site.DisplayErrors
+	merged.OpcacheEnable = p.OpcacheEnable || site.OpcacheEnable
+	merged.OpcacheValidateTimestamps = p.OpcacheValidateTimestamps || site.OpcacheValidateTimestamps
+
+	if site.MaxExecutionTime != 0 {
+		merged.MaxExecutionTime = site.MaxExecutionTime
+	}
+
+	if site.MaxInputVars != 0 {
+		merged.MaxInputVars = site.MaxInputVars
+	}
+
+	if site.MaxInputTime != 0 {
+		merged.MaxInputTime = site.MaxInputTime
+	}
+
+	if site.MaxFileUpload != "" {
+		merged.MaxFileUpload = site.MaxFileUpload
+	}
+
+	if site.MemoryLimit != "" {
+		merged.MemoryLimit = site.MemoryLimit
+	}
+
+	if site.OpcacheRevalidateFreq != 0 {
+		merged.OpcacheRevalidateFreq = site.OpcacheRevalidateFreq
+	}
+
+	if site.PostMaxSize != "" {
+		merged.PostMaxSize = site.PostMaxSize
+	}
+
+	if site.UploadMaxFileSize != "" {
+		merged.UploadMaxFileSize = site.UploadMaxFileSize
+	}
+
+	return merged
+}
+
 // Load is used to return the unmarshalled config, and
 // returns an error when trying to get the users home directory or
 // while marshalling the config.
@@ -478,6 +2001,60 @@ func Load(home string) (*Config, error) {
 		return nil, err
 	}
 
+	return load(file)
+}
+
+// LoadEnvironment loads the config file for a named environment without selecting
+// the environment, so the configs for other environments can be compared.
+func LoadEnvironment(home, name string) (*Config, error) {
+	file, err := EnvironmentFile(home, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return load(file)
+}
+
+// EnvironmentFile returns the path to the config file for a named environment, an
+// empty name or "default" returns the default config file.
+func EnvironmentFile(home, name string) (string, error) {
+	if name == "" || name == "default" {
+		return filepath.Join(home, DirectoryName, "nitro.yaml"), nil
+	}
+
+	if !environmentRegex.MatchString(name) {
+		return "", fmt.Errorf("%w, %q", ErrInvalidEnvironment, name)
+	}
+
+	return filepath.Join(home, DirectoryName, name+".yaml"), nil
+}
+
+// Environments returns the names of the environments that have a config file in
+// the nitro directory, the default environment is named default.
+func Environments(home string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(home, DirectoryName, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, f := range files {
+		name := strings.TrimSuffix(filepath.Base(f), ".yaml")
+		switch {
+		case name == "nitro":
+			names = append(names, "default")
+		case environmentRegex.MatchString(name):
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// load reads and decodes the config file.
+func load(file string) (*Config, error) {
 	// create the config
 	c := &Config{
 		File: file,
@@ -490,14 +2067,149 @@ func Load(home string) (*Config, error) {
 	}
 
 	// unmarshal
-	if err := yaml.Unmarshal(data, &c); err != nil {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+
+	// empty files have no content
+	if len(node.Content) == 0 {
+		return c, nil
+	}
+
+	// replace the environment variables in the values
+	interpolated := map[string]string{}
+	walkScalars(&node, "", func(path string, n *yaml.Node) {
+		if !variableRegex.MatchString(n.Value) {
+			return
+		}
+
+		interpolated[path] = n.Value
+		n.Value = interpolate(n.Value)
+	})
+
+	if err := node.Decode(c); err != nil {
+		// report every invalid value and not only the first
+		var terr *yaml.TypeError
+		if errors.As(err, &terr) {
+			return nil, fmt.Errorf("%w in %s:\n  %s", ErrInvalidConfig, file, strings.Join(terr.Errors, "\n  "))
+		}
+
 		return nil, err
 	}
 
+	if len(interpolated) > 0 {
+		c.interpolated = interpolated
+	}
+
 	// return the config
 	return c, nil
 }
 
+// interpolate replaces the ${VAR} references in the value with the environment
+// variables, variables that are not set are replaced with an empty string.
+func interpolate(value string) string {
+	return variableRegex.ReplaceAllStringFunc(value, func(m string) string {
+		return os.Getenv(variableRegex.FindStringSubmatch(m)[1])
+	})
+}
+
+// walkScalars calls fn for each scalar value in the node with the path
+// of the value, e.g. "sites.0.hostname".
+func walkScalars(n *yaml.Node, path string, fn func(path string, n *yaml.Node)) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for _, c := range n.Content {
+			walkScalars(c, path, fn)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			walkScalars(n.Content[i+1], strings.TrimPrefix(path+"."+n.Content[i].Value, "."), fn)
+		}
+	case yaml.SequenceNode:
+		for i, c := range n.Content {
+			walkScalars(c, strings.TrimPrefix(fmt.Sprintf("%s.%d", path, i), "."), fn)
+		}
+	case yaml.ScalarNode:
+		fn(path, n)
+	}
+}
+
+// UnknownFields reads the config file and returns a message for each
+// field that is not part of the config, such as a typo in a PHP
+// setting, which would otherwise be silently ignored.
+func (c *Config) UnknownFields() ([]string, error) {
+	data, err := ioutil.ReadFile(c.File)
+	if err != nil {
+		return nil, err
+	}
+
+	// decode into a new config and error on any unknown fields
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	err = dec.Decode(&Config{})
+
+	var terr *yaml.TypeError
+	if !errors.As(err, &terr) {
+		// empty files return an EOF
+		if err == io.EOF {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var unknown []string
+	for _, e := range terr.Errors {
+		// errors are in the format "line 7: field memoryLimt not found in type config.PHP"
+		if !strings.Contains(e, " not found in type ") {
+			return nil, terr
+		}
+
+		line, msg := "", e
+		if parts := strings.SplitN(e, ": ", 2); len(parts) == 2 {
+			line, msg = parts[0], parts[1]
+		}
+
+		field := strings.TrimPrefix(strings.Split(msg, " not found in type ")[0], "field ")
+
+		unknown = append(unknown, fmt.Sprintf("unknown config field '%s' (%s)", field, line))
+	}
+
+	return unknown, nil
+}
+
+// UseEnvironment selects a named environment (e.g. work), each environment has
+// its own config file in the nitro directory (e.g. ~/.nitro/work.yaml). An empty
+// name or "default" selects the default environment and config file. Only the
+// sites and custom containers belong to an environment, the network, proxy,
+// databases, and services publish the same ports on the host so they are shared
+// by all of the environments.
+func UseEnvironment(name string) error {
+	if name == "" || name == "default" {
+		environment = ""
+		FileName = "nitro.yaml"
+
+		return nil
+	}
+
+	if !environmentRegex.MatchString(name) {
+		return fmt.Errorf("%w, %q", ErrInvalidEnvironment, name)
+	}
+
+	environment = name
+	FileName = name + ".yaml"
+
+	return nil
+}
+
+// Environment returns the name of the selected environment, or an empty
+// string for the default environment.
+func Environment() string {
+	return environment
+}
+
 // IsEmpty is used to check if the config file is empty
 func IsEmpty(home string) (string, error) {
 	// verify the file exists
@@ -647,6 +2359,25 @@ func (c *Config) EnableXdebug(site string) error {
 	return fmt.Errorf("unknown site, %s", site)
 }
 
+// SetXdebugProfile takes a sites hostname and sets the xdebug profile to profile,
+// trace, or an empty string to turn it off. If the site cannot be found, it
+// returns an error.
+func (c *Config) SetXdebugProfile(site, profile string) error {
+	if profile != "" && profile != XdebugProfile && profile != XdebugTrace {
+		return fmt.Errorf("%w, got %q", ErrInvalidXdebugProfile, profile)
+	}
+
+	for i, s := range c.Sites {
+		if s.Hostname == site {
+			c.Sites[i].XdebugProfile = profile
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown site, %s", site)
+}
+
 // Save takes a file path and marshals the config into a file.
 func (c *Config) Save() error {
 	// make sure the file exists
@@ -664,8 +2395,7 @@ func (c *Config) Save() error {
 		return err
 	}
 
-	// unmarshal
-	data, err := yaml.Marshal(&c)
+	data, err := c.Marshal()
 	if err != nil {
 		return err
 	}
@@ -678,6 +2408,26 @@ func (c *Config) Save() error {
 	return f.Close()
 }
 
+// Marshal returns the config as yaml with the ${VAR} references from the config
+// file instead of their values, so secrets in environment variables are not
+// written to files or shown. Values that changed since loading are kept.
+func (c *Config) Marshal() ([]byte, error) {
+	var node yaml.Node
+	if err := node.Encode(c); err != nil {
+		return nil, err
+	}
+
+	// keep the environment variable references for values that did not change
+	walkScalars(&node, "", func(path string, n *yaml.Node) {
+		if raw, ok := c.interpolated[path]; ok && n.Value == interpolate(raw) {
+			n.Value = raw
+			n.Style = 0
+		}
+	})
+
+	return yaml.Marshal(&node)
+}
+
 func (c *Config) createFile(dir string) error {
 	// create the .nitro directory if it does not exist
 	if err := helpers.MkdirIfNotExists(dir); err != nil {
@@ -768,7 +2518,7 @@ func phpVars(php PHP, version string) []string {
 	return envs
 }
 
-func xdebugVars(php PHP, xdebug bool, version, hostname, addr string) []string {
+func xdebugVars(php PHP, xdebug bool, profile, version, hostname, addr string) []string {
 	envs := []string{}
 
 	// always set the session
@@ -777,6 +2527,25 @@ func xdebugVars(php PHP, xdebug bool, version, hostname, addr string) []string {
 	// set the site name for xdebug clients
 	envs = append(envs, fmt.Sprintf("PHP_IDE_CONFIG=serverName=%s", hostname))
 
+	// profiling and tracing replace debugging for every request
+	if profile != "" {
+		switch version {
+		case "7.1", "7.0":
+			setting := "profiler_enable=1 profiler_output_dir"
+			if profile == XdebugTrace {
+				setting = "auto_trace=1 trace_output_dir"
+			}
+
+			envs = append(envs, fmt.Sprintf("XDEBUG_CONFIG=%s=%s", setting, XdebugOutputDir))
+			envs = append(envs, "XDEBUG_MODE=xdebug2")
+		default:
+			envs = append(envs, fmt.Sprintf("XDEBUG_CONFIG=output_dir=%s start_with_request=yes", XdebugOutputDir))
+			envs = append(envs, "XDEBUG_MODE="+profile)
+		}
+
+		return envs
+	}
+
 	// if xdebug is not enabled
 	if !xdebug {
 		return append(envs, "XDEBUG_MODE=off")
@@ -808,3 +2577,68 @@ func cleanPath(home, path string) (string, error) {
 
 	return filepath.Clean(abs), nil
 }
+
+// NanoCPUs returns the cpus in billionths of a cpu, which docker uses for
+// cpu limits (e.g. 1.5 is 1500000000).
+func NanoCPUs(cpus float64) int64 {
+	return int64(cpus * 1e9)
+}
+
+// MemoryBytes returns the bytes for a memory size (e.g. 512M or 2G), sizes
+// without a unit are bytes and an empty size returns 0.
+func MemoryBytes(memory string) (int64, error) {
+	if memory == "" {
+		return 0, nil
+	}
+
+	if !sizeRegex.MatchString(memory) {
+		return 0, fmt.Errorf("%w, got %q", ErrInvalidResources, memory)
+	}
+
+	var unit int64 = 1
+	switch memory[len(memory)-1] {
+	case 'k', 'K':
+		unit = 1 << 10
+	case 'm', 'M':
+		unit = 1 << 20
+	case 'g', 'G':
+		unit = 1 << 30
+	}
+
+	n, err := strconv.ParseInt(strings.TrimRight(memory, "kKmMgG"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w, got %q", ErrInvalidResources, memory)
+	}
+
+	return n * unit, nil
+}
+
+// validateResources checks the cpus are not negative and the memory is at
+// least the 6M docker requires.
+func validateResources(cpus float64, memory string) error {
+	if cpus < 0 {
+		return ErrInvalidResources
+	}
+
+	b, err := MemoryBytes(memory)
+	if err != nil {
+		return ErrInvalidResources
+	}
+
+	if memory != "" && b < 6<<20 {
+		return ErrInvalidResources
+	}
+
+	return nil
+}
+
+// hash returns a short sha256 of the JSON encoding of v. The JSON encoding
+// is stable because struct fields are always encoded in the same order.
+func hash(v interface{}) string {
+	// the config types only contain values that can be encoded
+	b, _ := json.Marshal(v)
+
+	sum := sha256.Sum256(b)
+
+	return hex.EncodeToString(sum[:])[:16]
+}
@@ -1,10 +1,18 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"reflect"
+	"runtime"
+	"strings"
 	"testing"
+
+	"github.com/craftcms/nitro/pkg/schedule"
+	"gopkg.in/yaml.v3"
 )
 
 func TestSite_AsEnvs(t *testing.T) {
@@ -16,6 +24,8 @@ func TestSite_AsEnvs(t *testing.T) {
 		PHP      PHP
 		Webroot  string
 		Xdebug   bool
+		Timezone string
+		Profile  string
 	}
 	type args struct {
 		addr string
@@ -155,6 +165,78 @@ func TestSite_AsEnvs(t *testing.T) {
 				"XDEBUG_MODE=off",
 			},
 		},
+		{
+			name: "xdebug 3 profile mode is set if enabled",
+			fields: fields{
+				Hostname: "somewebsite.nitro",
+				Version:  "7.4",
+				Profile:  XdebugTrace,
+			},
+			want: []string{
+				"COMPOSER_HOME=/tmp",
+				"PHP_DISPLAY_ERRORS=on",
+				"PHP_MEMORY_LIMIT=512M",
+				"PHP_MAX_EXECUTION_TIME=5000",
+				"PHP_UPLOAD_MAX_FILESIZE=512M",
+				"PHP_MAX_INPUT_VARS=5000",
+				"PHP_POST_MAX_SIZE=512M",
+				"PHP_OPCACHE_ENABLE=0",
+				"PHP_OPCACHE_REVALIDATE_FREQ=0",
+				"PHP_OPCACHE_VALIDATE_TIMESTAMPS=0",
+				"XDEBUG_SESSION=PHPSTORM",
+				"PHP_IDE_CONFIG=serverName=somewebsite.nitro",
+				"XDEBUG_CONFIG=output_dir=/var/www/xdebug start_with_request=yes",
+				"XDEBUG_MODE=trace",
+			},
+		},
+		{
+			name: "xdebug 2 profile mode is set if enabled",
+			fields: fields{
+				Hostname: "somewebsite.nitro",
+				Version:  "7.1",
+				Xdebug:   true,
+				Profile:  XdebugProfile,
+			},
+			want: []string{
+				"COMPOSER_HOME=/tmp",
+				"PHP_DISPLAY_ERRORS=on",
+				"PHP_MEMORY_LIMIT=512M",
+				"PHP_MAX_EXECUTION_TIME=5000",
+				"PHP_UPLOAD_MAX_FILESIZE=512M",
+				"PHP_MAX_INPUT_VARS=5000",
+				"PHP_POST_MAX_SIZE=512M",
+				"PHP_OPCACHE_ENABLE=0",
+				"PHP_OPCACHE_REVALIDATE_FREQ=0",
+				"PHP_OPCACHE_VALIDATE_TIMESTAMPS=0",
+				"XDEBUG_SESSION=PHPSTORM",
+				"PHP_IDE_CONFIG=serverName=somewebsite.nitro",
+				"XDEBUG_CONFIG=profiler_enable=1 profiler_output_dir=/var/www/xdebug",
+				"XDEBUG_MODE=xdebug2",
+			},
+		},
+		{
+			name: "timezone is set if defined",
+			fields: fields{
+				Hostname: "somewebsite.nitro",
+				Timezone: "America/Chicago",
+			},
+			want: []string{
+				"COMPOSER_HOME=/tmp",
+				"PHP_DISPLAY_ERRORS=on",
+				"PHP_MEMORY_LIMIT=512M",
+				"PHP_MAX_EXECUTION_TIME=5000",
+				"PHP_UPLOAD_MAX_FILESIZE=512M",
+				"PHP_MAX_INPUT_VARS=5000",
+				"PHP_POST_MAX_SIZE=512M",
+				"PHP_OPCACHE_ENABLE=0",
+				"PHP_OPCACHE_REVALIDATE_FREQ=0",
+				"PHP_OPCACHE_VALIDATE_TIMESTAMPS=0",
+				"TZ=America/Chicago",
+				"XDEBUG_SESSION=PHPSTORM",
+				"PHP_IDE_CONFIG=serverName=somewebsite.nitro",
+				"XDEBUG_MODE=off",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -166,6 +248,8 @@ func TestSite_AsEnvs(t *testing.T) {
 				PHP:      tt.fields.PHP,
 				Webroot:  tt.fields.Webroot,
 				Xdebug:   tt.fields.Xdebug,
+				XdebugProfile: tt.fields.Profile,
+				Timezone: tt.fields.Timezone,
 			}
 			if got := s.AsEnvs(tt.args.addr); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Site.AsEnvs() = \ngot:\n%v, \nwant:\n%v", got, tt.want)
@@ -475,6 +559,45 @@ func TestConfig_DisableXdebug(t *testing.T) {
 	}
 }
 
+func TestConfig_SetXdebugProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		site    string
+		profile string
+		wantErr error
+	}{
+		{
+			name:    "can set the profile for a site",
+			site:    "somesite",
+			profile: XdebugTrace,
+		},
+		{
+			name: "can turn off the profile for a site",
+			site: "somesite",
+		},
+		{
+			name:    "unknown profiles return an error",
+			site:    "somesite",
+			profile: "coverage",
+			wantErr: ErrInvalidXdebugProfile,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{Sites: []Site{{Hostname: "somesite", XdebugProfile: XdebugProfile}}}
+
+			err := c.SetXdebugProfile(tt.site, tt.profile)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Config.SetXdebugProfile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && c.Sites[0].XdebugProfile != tt.profile {
+				t.Errorf("expected the profile to be %q, got %q", tt.profile, c.Sites[0].XdebugProfile)
+			}
+		})
+	}
+}
+
 func TestConfig_AddSite(t *testing.T) {
 	type fields struct {
 		Blackfire Blackfire
@@ -583,273 +706,471 @@ func TestSite_GetAbsPath(t *testing.T) {
 	}
 }
 
-func TestConfig_SetPHPStrSetting(t *testing.T) {
+func TestSite_GetExcludes(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	type fields struct {
-		Sites []Site
+		Hostname string
+		Path     string
+		Exclude  []string
 	}
 	type args struct {
-		hostname string
-		setting  string
-		value    string
+		home string
 	}
 	tests := []struct {
 		name    string
 		fields  fields
 		args    args
-		want    Config
+		want    []string
 		wantErr bool
 	}{
-		// upload_max_file_size
 		{
-			name: "can change a sites php upload_max_file_size setting",
+			name: "sites without excludes or an ignore file return nothing",
 			fields: fields{
-				Sites: []Site{
-					{
-						Hostname: "siteone.nitro",
-					},
-				},
+				Path: "~/sites/apple",
 			},
 			args: args{
-				hostname: "siteone.nitro",
-				setting:  "upload_max_file_size",
-				value:    "24M",
+				home: filepath.Join(wd, "testdata", "home"),
 			},
+			want:    nil,
 			wantErr: false,
 		},
 		{
-			name: "can change a sites php post max size setting",
+			name: "excludes from the config are combined with the ignore file",
 			fields: fields{
-				Sites: []Site{
-					{
-						Hostname: "siteone.nitro",
-					},
-				},
+				Path:    "~/sites/elderberry",
+				Exclude: []string{"storage", "vendor"},
 			},
 			args: args{
-				hostname: "siteone.nitro",
-				setting:  "post_max_size",
-				value:    "1024M",
+				home: filepath.Join(wd, "testdata", "home"),
 			},
+			want:    []string{"storage", "vendor", "node_modules"},
 			wantErr: false,
 		},
 		{
-			name: "can change a sites php max file upload setting",
+			name: "invalid patterns return an error",
 			fields: fields{
-				Sites: []Site{
-					{
-						Hostname: "siteone.nitro",
-					},
-				},
+				Path:    "~/sites/apple",
+				Exclude: []string{"[vendor"},
 			},
 			args: args{
-				hostname: "siteone.nitro",
-				setting:  "max_file_upload",
-				value:    "1024M",
+				home: filepath.Join(wd, "testdata", "home"),
 			},
-			wantErr: false,
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Site{
+				Hostname: tt.fields.Hostname,
+				Path:     tt.fields.Path,
+				Exclude:  tt.fields.Exclude,
+			}
+			got, err := s.GetExcludes(tt.args.home)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Site.GetExcludes() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Site.GetExcludes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSite_GetNginx(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	home := filepath.Join(wd, "testdata", "home")
+
+	tests := []struct {
+		name    string
+		nginx   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "sites without directives return nothing",
+			want: "",
 		},
 		{
-			name: "can change a sites php memory limit setting",
-			fields: fields{
-				Sites: []Site{
-					{
-						Hostname: "siteone.nitro",
-					},
-				},
-			},
-			args: args{
-				hostname: "siteone.nitro",
-				setting:  "memory_limit",
-				value:    "1024M",
-			},
-			wantErr: false,
+			name:  "inline directives are returned",
+			nginx: "add_header X-Frame-Options SAMEORIGIN;\n",
+			want:  "add_header X-Frame-Options SAMEORIGIN;",
 		},
 		{
-			name: "unknown settings return an error",
-			fields: fields{
-				Sites: []Site{
-					{
-						Hostname: "siteone.nitro",
-					},
-				},
-			},
-			args: args{
-				hostname: "siteone.nitro",
-				setting:  "new_setting_who_dis",
-				value:    "1024M",
-			},
+			name:  "relative files are read from the sites path",
+			nginx: "redirects.conf",
+			want:  "location /old {\n    return 301 /new;\n}",
+		},
+		{
+			name:  "files in the home directory are read",
+			nginx: "~/sites/cherry/redirects.conf",
+			want:  "location /old {\n    return 301 /new;\n}",
+		},
+		{
+			name:    "missing files return an error",
+			nginx:   "missing.conf",
 			wantErr: true,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := &Config{
-				Sites: tt.fields.Sites,
-			}
-
-			if err := c.SetPHPStrSetting(tt.args.hostname, tt.args.setting, tt.args.value); (err != nil) != tt.wantErr {
-				t.Errorf("Config.SetPHPStrSetting() error = %v, wantErr %v", err, tt.wantErr)
+			s := &Site{
+				Hostname: "cherry.nitro",
+				Path:     "~/sites/cherry",
+				Nginx:    tt.nginx,
 			}
-
-			// find the site
-			var site Site
-			for _, s := range c.Sites {
-				if s.Hostname == tt.args.hostname {
-					site = s
-				}
+			got, err := s.GetNginx(home)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Site.GetNginx() error = %v, wantErr %v", err, tt.wantErr)
+				return
 			}
-
-			switch tt.args.setting {
-			case "memory_limit":
-				if site.PHP.MemoryLimit != tt.args.value {
-					t.Errorf("expected the setting to be %s, got %s", tt.args.value, site.PHP.MemoryLimit)
-				}
-			case "post_max_size":
-				if site.PHP.PostMaxSize != tt.args.value {
-					t.Errorf("expected the setting to be %s, got %s", tt.args.value, site.PHP.PostMaxSize)
-				}
-			case "max_file_upload":
-				if site.PHP.MaxFileUpload != tt.args.value {
-					t.Errorf("expected the setting to be %s, got %s", tt.args.value, site.PHP.MaxFileUpload)
-				}
+			if got != tt.want {
+				t.Errorf("Site.GetNginx() = %q, want %q", got, tt.want)
 			}
 		})
 	}
 }
 
-func TestConfig_SetPHPBoolSetting(t *testing.T) {
+func TestSite_GetExcludedDirs(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	type fields struct {
-		Sites []Site
+		Path    string
+		Exclude []string
 	}
 	type args struct {
-		hostname string
-		setting  string
-		value    bool
+		home string
 	}
 	tests := []struct {
 		name    string
 		fields  fields
 		args    args
+		want    []string
 		wantErr bool
 	}{
 		{
-			name: "can change a sites php opcache enable setting",
+			name: "matching directories are returned in order",
 			fields: fields{
-				Sites: []Site{
-					{
-						Hostname: "siteone.nitro",
-					},
-				},
+				Path:    "~/sites/elderberry",
+				Exclude: []string{"storage"},
 			},
 			args: args{
-				hostname: "siteone.nitro",
-				setting:  "opcache_enable",
-				value:    true,
+				home: filepath.Join(wd, "testdata", "home"),
 			},
+			want:    []string{"node_modules", "storage", "vendor"},
 			wantErr: false,
 		},
 		{
-			name: "can change a sites php post max size setting",
+			name: "patterns that match files are ignored",
 			fields: fields{
-				Sites: []Site{
-					{
-						Hostname: "siteone.nitro",
-					},
-				},
+				Path:    "~/sites/elderberry",
+				Exclude: []string{"craft", "missing"},
 			},
 			args: args{
-				hostname: "siteone.nitro",
-				setting:  "display_errors",
-				value:    false,
+				home: filepath.Join(wd, "testdata", "home"),
 			},
+			want:    []string{"node_modules", "vendor"},
 			wantErr: false,
 		},
 		{
-			name: "unknown settings return an error",
+			name: "invalid patterns return an error",
 			fields: fields{
-				Sites: []Site{
-					{
-						Hostname: "siteone.nitro",
-					},
-				},
+				Path:    "~/sites/elderberry",
+				Exclude: []string{"[vendor"},
 			},
 			args: args{
-				hostname: "siteone.nitro",
-				setting:  "new_setting_who_dis",
-				value:    false,
+				home: filepath.Join(wd, "testdata", "home"),
 			},
+			want:    nil,
 			wantErr: true,
 		},
-		{
-			name: "missing site returns an error",
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Site{
+				Path:    tt.fields.Path,
+				Exclude: tt.fields.Exclude,
+			}
+			got, err := s.GetExcludedDirs(tt.args.home)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Site.GetExcludedDirs() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Site.GetExcludedDirs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type fields struct {
+		Sites    []Site
+		Services Services
+		Timezone string
+		Backups  Backups
+	}
+	type args struct {
+		home string
+	}
+	tests := []struct {
+		name         string
+		fields       fields
+		args         args
+		wantWarnings int
+		wantErr      error
+	}{
+		{
+			name: "sites with distinct paths are valid",
 			fields: fields{
 				Sites: []Site{
-					{
-						Hostname: "siteone.nitro",
-					},
+					{Hostname: "apple.nitro", Path: "~/sites/apple", Version: "8.0", Webroot: "web"},
+					{Hostname: "banana.nitro", Path: "~/sites/banana", Version: "7.4", Webroot: "public"},
 				},
 			},
 			args: args{
-				hostname: "newsite.nitro",
-				setting:  "display_errors",
-				value:    false,
+				home: filepath.Join(wd, "testdata", "home"),
 			},
-			wantErr: true,
+			wantWarnings: 0,
+			wantErr:      nil,
+		},
+		{
+			name: "sites with the same path and config return a warning",
+			fields: fields{
+				Sites: []Site{
+					{Hostname: "apple.nitro", Path: "~/sites/apple", Version: "8.0", Webroot: "web"},
+					{Hostname: "apple-two.nitro", Path: "~/sites/apple/", Version: "8.0", Webroot: "web"},
+				},
+			},
+			args: args{
+				home: filepath.Join(wd, "testdata", "home"),
+			},
+			wantWarnings: 1,
+			wantErr:      nil,
+		},
+		{
+			name: "sites with the same path and different php versions return an error",
+			fields: fields{
+				Sites: []Site{
+					{Hostname: "apple.nitro", Path: "~/sites/apple", Version: "8.0", Webroot: "web"},
+					{Hostname: "apple-two.nitro", Path: "~/sites/apple", Version: "7.4", Webroot: "web"},
+				},
+			},
+			args: args{
+				home: filepath.Join(wd, "testdata", "home"),
+			},
+			wantErr: ErrConflictingSites,
+		},
+		{
+			name: "sites with the same path and different web roots return an error",
+			fields: fields{
+				Sites: []Site{
+					{Hostname: "apple.nitro", Path: "~/sites/apple", Version: "8.0", Webroot: "web"},
+					{Hostname: "apple-two.nitro", Path: "~/sites/apple", Version: "8.0", Webroot: "public"},
+				},
+			},
+			args: args{
+				home: filepath.Join(wd, "testdata", "home"),
+			},
+			wantErr: ErrConflictingSites,
+		},
+		{
+			name: "invalid global timezones return an error",
+			fields: fields{
+				Timezone: "Mars/Olympus_Mons",
+			},
+			args: args{
+				home: filepath.Join(wd, "testdata", "home"),
+			},
+			wantErr: ErrInvalidTimezone,
+		},
+		{
+			name: "invalid site timezones return an error",
+			fields: fields{
+				Timezone: "America/Chicago",
+				Sites: []Site{
+					{Hostname: "apple.nitro", Path: "~/sites/apple", Version: "8.0", Webroot: "web", Timezone: "Central"},
+				},
+			},
+			args: args{
+				home: filepath.Join(wd, "testdata", "home"),
+			},
+			wantErr: ErrInvalidTimezone,
+		},
+		{
+			name: "short minio passwords return an error",
+			fields: fields{
+				Services: Services{Minio: true, MinioPassword: "nitro"},
+			},
+			args: args{
+				home: filepath.Join(wd, "testdata", "home"),
+			},
+			wantErr: ErrInvalidMinioPassword,
+		},
+		{
+			name: "invalid backup schedules return an error",
+			fields: fields{
+				Backups: Backups{Schedule: "every six hours"},
+			},
+			args: args{
+				home: filepath.Join(wd, "testdata", "home"),
+			},
+			wantErr: schedule.ErrInvalidSchedule,
+		},
+		{
+			name: "negative backup retention returns an error",
+			fields: fields{
+				Backups: Backups{KeepDays: -1},
+			},
+			args: args{
+				home: filepath.Join(wd, "testdata", "home"),
+			},
+			wantErr: ErrInvalidRetention,
+		},
+		{
+			name: "invalid site environment variable names return an error",
+			fields: fields{
+				Sites: []Site{
+					{Hostname: "apple.nitro", Path: "~/sites/apple", Version: "8.0", Webroot: "web", Env: map[string]string{"API KEY": "secret"}},
+				},
+			},
+			args: args{
+				home: filepath.Join(wd, "testdata", "home"),
+			},
+			wantErr: ErrInvalidEnv,
+		},
+		{
+			name: "storage without a bucket returns an error",
+			fields: fields{
+				Backups: Backups{Storage: &Storage{Endpoint: "http://localhost:9000"}},
+			},
+			args: args{
+				home: filepath.Join(wd, "testdata", "home"),
+			},
+			wantErr: ErrInvalidStorage,
+		},
+		{
+			name: "remotes without a database return an error",
+			fields: fields{
+				Sites: []Site{
+					{Hostname: "apple.nitro", Path: "~/sites/apple", Version: "8.0", Webroot: "web", Remote: &Remote{Host: "apple.com"}},
+				},
+			},
+			args: args{
+				home: filepath.Join(wd, "testdata", "home"),
+			},
+			wantErr: ErrInvalidRemote,
+		},
+		{
+			name: "remotes with unknown engines return an error",
+			fields: fields{
+				Sites: []Site{
+					{Hostname: "apple.nitro", Path: "~/sites/apple", Version: "8.0", Webroot: "web", Remote: &Remote{Host: "apple.com", Database: "craft", Engine: "sqlite"}},
+				},
+			},
+			args: args{
+				home: filepath.Join(wd, "testdata", "home"),
+			},
+			wantErr: ErrInvalidRemote,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			c := &Config{
-				Sites: tt.fields.Sites,
+				Sites:    tt.fields.Sites,
+				Services: tt.fields.Services,
+				Timezone: tt.fields.Timezone,
+				Backups:  tt.fields.Backups,
 			}
-
-			if err := c.SetPHPBoolSetting(tt.args.hostname, tt.args.setting, tt.args.value); (err != nil) != tt.wantErr {
-				t.Errorf("Config.SetPHPBoolSetting() error = %v, wantErr %v", err, tt.wantErr)
+			warnings, err := c.Validate(tt.args.home)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Config.Validate() error = %v, wantErr %v", err, tt.wantErr)
+				return
 			}
-
-			// find the site
-			var site Site
-			for _, s := range c.Sites {
-				if s.Hostname == tt.args.hostname {
-					site = s
-				}
+			if len(warnings) != tt.wantWarnings {
+				t.Errorf("Config.Validate() warnings = %v, want %d", warnings, tt.wantWarnings)
 			}
+		})
+	}
+}
 
-			switch tt.args.setting {
-			case "display_errors":
-				if site.PHP.DisplayErrors != tt.args.value {
-					t.Errorf("expected the setting to be %v, got %v", tt.args.value, site.PHP.DisplayErrors)
-				}
-			case "opcache_enable":
-				if site.PHP.OpcacheEnable != tt.args.value {
-					t.Errorf("expected the setting to be %v, got %v", tt.args.value, site.PHP.OpcacheEnable)
-				}
-			case "opcache_validate_timestamps":
-				if site.PHP.OpcacheValidateTimestamps != tt.args.value {
-					t.Errorf("expected the setting to be %v, got %v", tt.args.value, site.PHP.OpcacheValidateTimestamps)
-				}
+func TestValidateTimezone(t *testing.T) {
+	tests := []struct {
+		name    string
+		tz      string
+		wantErr bool
+	}{
+		{
+			name:    "empty timezones are valid",
+			tz:      "",
+			wantErr: false,
+		},
+		{
+			name:    "tz database names are valid",
+			tz:      "Europe/Berlin",
+			wantErr: false,
+		},
+		{
+			name:    "UTC is valid",
+			tz:      "UTC",
+			wantErr: false,
+		},
+		{
+			name:    "local is not valid",
+			tz:      "Local",
+			wantErr: true,
+		},
+		{
+			name:    "unknown names are not valid",
+			tz:      "Europe/Atlantis",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateTimezone(tt.tz); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateTimezone() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
 
-func TestConfig_SetPHPIntSetting(t *testing.T) {
+func TestConfig_SetPHPStrSetting(t *testing.T) {
 	type fields struct {
 		Sites []Site
 	}
 	type args struct {
 		hostname string
 		setting  string
-		value    int
+		value    string
 	}
 	tests := []struct {
 		name    string
 		fields  fields
 		args    args
+		want    Config
 		wantErr bool
 	}{
+		// upload_max_file_size
 		{
-			name: "can change a sites php max_execution_time setting",
+			name: "can change a sites php upload_max_file_size setting",
 			fields: fields{
 				Sites: []Site{
 					{
@@ -859,13 +1180,13 @@ func TestConfig_SetPHPIntSetting(t *testing.T) {
 			},
 			args: args{
 				hostname: "siteone.nitro",
-				setting:  "max_execution_time",
-				value:    7000,
+				setting:  "upload_max_file_size",
+				value:    "24M",
 			},
 			wantErr: false,
 		},
 		{
-			name: "can change a sites php max_input_vars setting",
+			name: "can change a sites php post max size setting",
 			fields: fields{
 				Sites: []Site{
 					{
@@ -875,13 +1196,13 @@ func TestConfig_SetPHPIntSetting(t *testing.T) {
 			},
 			args: args{
 				hostname: "siteone.nitro",
-				setting:  "max_input_vars",
-				value:    13000,
+				setting:  "post_max_size",
+				value:    "1024M",
 			},
 			wantErr: false,
 		},
 		{
-			name: "can change a sites php max_input_time setting",
+			name: "can change a sites php max file upload setting",
 			fields: fields{
 				Sites: []Site{
 					{
@@ -891,13 +1212,13 @@ func TestConfig_SetPHPIntSetting(t *testing.T) {
 			},
 			args: args{
 				hostname: "siteone.nitro",
-				setting:  "max_input_time",
-				value:    13000,
+				setting:  "max_file_upload",
+				value:    "1024M",
 			},
 			wantErr: false,
 		},
 		{
-			name: "can change a sites php opcache_revalidate_freq setting",
+			name: "can change a sites php memory limit setting",
 			fields: fields{
 				Sites: []Site{
 					{
@@ -907,8 +1228,8 @@ func TestConfig_SetPHPIntSetting(t *testing.T) {
 			},
 			args: args{
 				hostname: "siteone.nitro",
-				setting:  "opcache_revalidate_freq",
-				value:    30,
+				setting:  "memory_limit",
+				value:    "1024M",
 			},
 			wantErr: false,
 		},
@@ -924,23 +1245,7 @@ func TestConfig_SetPHPIntSetting(t *testing.T) {
 			args: args{
 				hostname: "siteone.nitro",
 				setting:  "new_setting_who_dis",
-				value:    0,
-			},
-			wantErr: true,
-		},
-		{
-			name: "missing site returns an error",
-			fields: fields{
-				Sites: []Site{
-					{
-						Hostname: "siteone.nitro",
-					},
-				},
-			},
-			args: args{
-				hostname: "newsite.nitro",
-				setting:  "display_errors",
-				value:    0,
+				value:    "1024M",
 			},
 			wantErr: true,
 		},
@@ -951,8 +1256,8 @@ func TestConfig_SetPHPIntSetting(t *testing.T) {
 				Sites: tt.fields.Sites,
 			}
 
-			if err := c.SetPHPIntSetting(tt.args.hostname, tt.args.setting, tt.args.value); (err != nil) != tt.wantErr {
-				t.Errorf("Config.SetPHPIntSetting() error = %v, wantErr %v", err, tt.wantErr)
+			if err := c.SetPHPStrSetting(tt.args.hostname, tt.args.setting, tt.args.value); (err != nil) != tt.wantErr {
+				t.Errorf("Config.SetPHPStrSetting() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
 			// find the site
@@ -964,237 +1269,379 @@ func TestConfig_SetPHPIntSetting(t *testing.T) {
 			}
 
 			switch tt.args.setting {
-			case "max_execution_time":
-				if site.PHP.MaxExecutionTime != tt.args.value {
-					t.Errorf("expected the setting to be %v, got %v", tt.args.value, site.PHP.MaxExecutionTime)
-				}
-			case "max_input_vars":
-				if site.PHP.MaxInputVars != tt.args.value {
-					t.Errorf("expected the setting to be %v, got %v", tt.args.value, site.PHP.MaxInputVars)
+			case "memory_limit":
+				if site.PHP.MemoryLimit != tt.args.value {
+					t.Errorf("expected the setting to be %s, got %s", tt.args.value, site.PHP.MemoryLimit)
 				}
-			case "max_input_time":
-				if site.PHP.MaxInputTime != tt.args.value {
-					t.Errorf("expected the setting to be %v, got %v", tt.args.value, site.PHP.MaxInputTime)
+			case "post_max_size":
+				if site.PHP.PostMaxSize != tt.args.value {
+					t.Errorf("expected the setting to be %s, got %s", tt.args.value, site.PHP.PostMaxSize)
 				}
-			case "opcache_revalidate_freq":
-				if site.PHP.OpcacheRevalidateFreq != tt.args.value {
-					t.Errorf("expected the setting to be %v, got %v", tt.args.value, site.PHP.OpcacheRevalidateFreq)
+			case "max_file_upload":
+				if site.PHP.MaxFileUpload != tt.args.value {
+					t.Errorf("expected the setting to be %s, got %s", tt.args.value, site.PHP.MaxFileUpload)
 				}
 			}
 		})
 	}
 }
 
-func TestSite_GetContainerPath(t *testing.T) {
+func TestConfig_SetPHPBoolSetting(t *testing.T) {
 	type fields struct {
-		Webroot string
+		Sites []Site
+	}
+	type args struct {
+		hostname string
+		setting  string
+		value    bool
 	}
 	tests := []struct {
-		name   string
-		fields fields
-		want   string
+		name    string
+		fields  fields
+		args    args
+		wantErr bool
 	}{
 		{
-			name: "handles more than one level of nesting",
+			name: "can change a sites php opcache enable setting",
 			fields: fields{
-				Webroot: "another-dir/another-site/web",
+				Sites: []Site{
+					{
+						Hostname: "siteone.nitro",
+					},
+				},
 			},
-			want: "another-dir/another-site",
-		},
-		{
-			name: "returns the correct directory",
-			fields: fields{
-				Webroot: "another-site/web",
+			args: args{
+				hostname: "siteone.nitro",
+				setting:  "opcache_enable",
+				value:    true,
 			},
-			want: "another-site",
+			wantErr: false,
 		},
 		{
-			name: "returns the correct directory for a nest site that has a trailing slash",
+			name: "can change a sites php post max size setting",
 			fields: fields{
-				Webroot: "another-site/web/",
+				Sites: []Site{
+					{
+						Hostname: "siteone.nitro",
+					},
+				},
 			},
-			want: "another-site",
+			args: args{
+				hostname: "siteone.nitro",
+				setting:  "display_errors",
+				value:    false,
+			},
+			wantErr: false,
 		},
 		{
-			name: "default web roots with a trailing slash return the correct value",
+			name: "unknown settings return an error",
 			fields: fields{
-				Webroot: "web/",
+				Sites: []Site{
+					{
+						Hostname: "siteone.nitro",
+					},
+				},
 			},
-			want: "",
+			args: args{
+				hostname: "siteone.nitro",
+				setting:  "new_setting_who_dis",
+				value:    false,
+			},
+			wantErr: true,
 		},
 		{
-			name: "defaults returns an empty string",
+			name: "missing site returns an error",
 			fields: fields{
-				Webroot: "web",
+				Sites: []Site{
+					{
+						Hostname: "siteone.nitro",
+					},
+				},
 			},
-			want: "",
+			args: args{
+				hostname: "newsite.nitro",
+				setting:  "display_errors",
+				value:    false,
+			},
+			wantErr: true,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			s := &Site{
-				Webroot: tt.fields.Webroot,
+			c := &Config{
+				Sites: tt.fields.Sites,
 			}
 
-			if got := s.GetContainerPath(); got != tt.want {
-				t.Errorf("Site.GetContainerPath() = %v, want %v", got, tt.want)
+			if err := c.SetPHPBoolSetting(tt.args.hostname, tt.args.setting, tt.args.value); (err != nil) != tt.wantErr {
+				t.Errorf("Config.SetPHPBoolSetting() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			// find the site
+			var site Site
+			for _, s := range c.Sites {
+				if s.Hostname == tt.args.hostname {
+					site = s
+				}
+			}
+
+			switch tt.args.setting {
+			case "display_errors":
+				if site.PHP.DisplayErrors != tt.args.value {
+					t.Errorf("expected the setting to be %v, got %v", tt.args.value, site.PHP.DisplayErrors)
+				}
+			case "opcache_enable":
+				if site.PHP.OpcacheEnable != tt.args.value {
+					t.Errorf("expected the setting to be %v, got %v", tt.args.value, site.PHP.OpcacheEnable)
+				}
+			case "opcache_validate_timestamps":
+				if site.PHP.OpcacheValidateTimestamps != tt.args.value {
+					t.Errorf("expected the setting to be %v, got %v", tt.args.value, site.PHP.OpcacheValidateTimestamps)
+				}
 			}
 		})
 	}
 }
 
-func TestConfig_ListOfSitesByDirectory(t *testing.T) {
-	wd, err := os.Getwd()
-	if err != nil {
-		t.Fatal(err)
-	}
-
+func TestConfig_SetPHPIntSetting(t *testing.T) {
 	type fields struct {
 		Sites []Site
 	}
 	type args struct {
-		home string
-		wd   string
+		hostname string
+		setting  string
+		value    int
 	}
 	tests := []struct {
-		name   string
-		fields fields
-		args   args
-		want   []Site
+		name    string
+		fields  fields
+		args    args
+		wantErr bool
 	}{
 		{
-			name: "all sites suggested in lieu of match",
-			args: args{
-				home: filepath.Join(wd),
-				// we don’t have a site for `home/sites/broccoli`
-				wd: filepath.Join(wd, "testdata", "home", "sites", "broccoli"),
-			},
+			name: "can change a sites php max_execution_time setting",
 			fields: fields{
 				Sites: []Site{
 					{
-						Webroot: "web",
-						Path:    filepath.Join(wd, "testdata", "home", "sites", "apple"),
-					},
-					{
-						Webroot: "public",
-						Path:    filepath.Join(wd, "testdata", "home", "sites", "banana"),
-					},
-					{
-						Webroot: "web",
-						Path:    filepath.Join(wd, "testdata", "home", "sites", "cherry"),
-					},
-					{
-						Webroot: "web",
-						Path:    filepath.Join(wd, "testdata", "home", "sites", "cherry", "dragonfruit"),
-					},
-					{
-						Webroot: "",
-						Path:    filepath.Join(wd, "testdata", "home", "plugins", "thinginator"),
+						Hostname: "siteone.nitro",
 					},
 				},
 			},
-			want: []Site{
-				{
-					Webroot: "web",
-					Path:    filepath.Join(wd, "testdata", "home", "sites", "apple"),
-				},
-				{
-					Webroot: "public",
-					Path:    filepath.Join(wd, "testdata", "home", "sites", "banana"),
-				},
-				{
-					Webroot: "web",
-					Path:    filepath.Join(wd, "testdata", "home", "sites", "cherry"),
-				},
-				{
-					Webroot: "web",
-					Path:    filepath.Join(wd, "testdata", "home", "sites", "cherry", "dragonfruit"),
-				},
-				{
-					Webroot: "",
-					Path:    filepath.Join(wd, "testdata", "home", "plugins", "thinginator"),
-				},
+			args: args{
+				hostname: "siteone.nitro",
+				setting:  "max_execution_time",
+				value:    7000,
 			},
+			wantErr: false,
 		},
 		{
-			name: "multiple suggestions when working directory is top-level path",
-			args: args{
-				home: filepath.Join(wd),
-				wd:   filepath.Join(wd, "testdata", "home", "sites"),
-			},
+			name: "can change a sites php max_input_vars setting",
 			fields: fields{
 				Sites: []Site{
 					{
-						Webroot: "web",
-						Path:    filepath.Join(wd, "testdata", "home", "sites", "apple"),
-					},
-					{
-						Webroot: "public",
-						Path:    filepath.Join(wd, "testdata", "home", "sites", "banana"),
-					},
-					// this site is an exact path match, but our working directory is too vague
-					// for it to be the only suggestion
-					{
-						Webroot: "cherry/web",
-						Path:    filepath.Join(wd, "testdata", "home", "sites"),
-					},
-					// this site is in a different top-level directory and should not be a suggestion
-					{
-						Webroot: "",
-						Path:    filepath.Join(wd, "testdata", "home", "plugins"),
+						Hostname: "siteone.nitro",
 					},
 				},
 			},
-			want: []Site{
-				{
-					Webroot: "web",
-					Path:    filepath.Join(wd, "testdata", "home", "sites", "apple"),
-				},
-				{
-					Webroot: "public",
-					Path:    filepath.Join(wd, "testdata", "home", "sites", "banana"),
-				},
-				{
-					Webroot: "cherry/web",
-					Path:    filepath.Join(wd, "testdata", "home", "sites"),
-				},
+			args: args{
+				hostname: "siteone.nitro",
+				setting:  "max_input_vars",
+				value:    13000,
 			},
+			wantErr: false,
 		},
 		{
-			name: "single suggestion when working directory is site path",
-			args: args{
-				home: filepath.Join(wd),
-				wd:   filepath.Join(wd, "testdata", "home", "sites", "banana"),
-			},
+			name: "can change a sites php max_input_time setting",
 			fields: fields{
 				Sites: []Site{
 					{
-						Webroot: "web",
-						Path:    filepath.Join(wd, "testdata", "home", "sites", "apple"),
-					},
-					{
-						Webroot: "public",
-						Path:    filepath.Join(wd, "testdata", "home", "sites", "banana"),
+						Hostname: "siteone.nitro",
 					},
 				},
 			},
-			want: []Site{
-				{
-					Path:    filepath.Join(wd, "testdata", "home", "sites", "banana"),
-					Webroot: "public",
-				},
+			args: args{
+				hostname: "siteone.nitro",
+				setting:  "max_input_time",
+				value:    13000,
 			},
+			wantErr: false,
 		},
 		{
-			name: "single suggestion when working directory is single exact match to site container path",
+			name: "can change a sites php opcache_revalidate_freq setting",
+			fields: fields{
+				Sites: []Site{
+					{
+						Hostname: "siteone.nitro",
+					},
+				},
+			},
 			args: args{
-				home: filepath.Join(wd),
-				wd:   filepath.Join(wd, "testdata", "home", "sites", "cherry"),
+				hostname: "siteone.nitro",
+				setting:  "opcache_revalidate_freq",
+				value:    30,
 			},
+			wantErr: false,
+		},
+		{
+			name: "unknown settings return an error",
 			fields: fields{
 				Sites: []Site{
 					{
-						Webroot: "web",
+						Hostname: "siteone.nitro",
+					},
+				},
+			},
+			args: args{
+				hostname: "siteone.nitro",
+				setting:  "new_setting_who_dis",
+				value:    0,
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing site returns an error",
+			fields: fields{
+				Sites: []Site{
+					{
+						Hostname: "siteone.nitro",
+					},
+				},
+			},
+			args: args{
+				hostname: "newsite.nitro",
+				setting:  "display_errors",
+				value:    0,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{
+				Sites: tt.fields.Sites,
+			}
+
+			if err := c.SetPHPIntSetting(tt.args.hostname, tt.args.setting, tt.args.value); (err != nil) != tt.wantErr {
+				t.Errorf("Config.SetPHPIntSetting() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			// find the site
+			var site Site
+			for _, s := range c.Sites {
+				if s.Hostname == tt.args.hostname {
+					site = s
+				}
+			}
+
+			switch tt.args.setting {
+			case "max_execution_time":
+				if site.PHP.MaxExecutionTime != tt.args.value {
+					t.Errorf("expected the setting to be %v, got %v", tt.args.value, site.PHP.MaxExecutionTime)
+				}
+			case "max_input_vars":
+				if site.PHP.MaxInputVars != tt.args.value {
+					t.Errorf("expected the setting to be %v, got %v", tt.args.value, site.PHP.MaxInputVars)
+				}
+			case "max_input_time":
+				if site.PHP.MaxInputTime != tt.args.value {
+					t.Errorf("expected the setting to be %v, got %v", tt.args.value, site.PHP.MaxInputTime)
+				}
+			case "opcache_revalidate_freq":
+				if site.PHP.OpcacheRevalidateFreq != tt.args.value {
+					t.Errorf("expected the setting to be %v, got %v", tt.args.value, site.PHP.OpcacheRevalidateFreq)
+				}
+			}
+		})
+	}
+}
+
+func TestSite_GetContainerPath(t *testing.T) {
+	type fields struct {
+		Webroot string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{
+			name: "handles more than one level of nesting",
+			fields: fields{
+				Webroot: "another-dir/another-site/web",
+			},
+			want: "another-dir/another-site",
+		},
+		{
+			name: "returns the correct directory",
+			fields: fields{
+				Webroot: "another-site/web",
+			},
+			want: "another-site",
+		},
+		{
+			name: "returns the correct directory for a nest site that has a trailing slash",
+			fields: fields{
+				Webroot: "another-site/web/",
+			},
+			want: "another-site",
+		},
+		{
+			name: "default web roots with a trailing slash return the correct value",
+			fields: fields{
+				Webroot: "web/",
+			},
+			want: "",
+		},
+		{
+			name: "defaults returns an empty string",
+			fields: fields{
+				Webroot: "web",
+			},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Site{
+				Webroot: tt.fields.Webroot,
+			}
+
+			if got := s.GetContainerPath(); got != tt.want {
+				t.Errorf("Site.GetContainerPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_ListOfSitesByDirectory(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type fields struct {
+		Sites []Site
+	}
+	type args struct {
+		home string
+		wd   string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		want   []Site
+	}{
+		{
+			name: "all sites suggested in lieu of match",
+			args: args{
+				home: filepath.Join(wd),
+				// we don’t have a site for `home/sites/broccoli`
+				wd: filepath.Join(wd, "testdata", "home", "sites", "broccoli"),
+			},
+			fields: fields{
+				Sites: []Site{
+					{
+						Webroot: "web",
 						Path:    filepath.Join(wd, "testdata", "home", "sites", "apple"),
 					},
 					{
@@ -1202,101 +1649,223 @@ func TestConfig_ListOfSitesByDirectory(t *testing.T) {
 						Path:    filepath.Join(wd, "testdata", "home", "sites", "banana"),
 					},
 					{
-						Webroot: "cherry/web",
-						Path:    filepath.Join(wd, "testdata", "home", "sites"),
+						Webroot: "web",
+						Path:    filepath.Join(wd, "testdata", "home", "sites", "cherry"),
 					},
 					{
-						Webroot: "cherry/dragonfruit/web",
-						Path:    filepath.Join(wd, "testdata", "home", "sites"),
+						Webroot: "web",
+						Path:    filepath.Join(wd, "testdata", "home", "sites", "cherry", "dragonfruit"),
+					},
+					{
+						Webroot: "",
+						Path:    filepath.Join(wd, "testdata", "home", "plugins", "thinginator"),
 					},
 				},
 			},
 			want: []Site{
 				{
-					Webroot: "cherry/web",
-					Path:    filepath.Join(wd, "testdata", "home", "sites"),
+					Webroot: "web",
+					Path:    filepath.Join(wd, "testdata", "home", "sites", "apple"),
+				},
+				{
+					Webroot: "public",
+					Path:    filepath.Join(wd, "testdata", "home", "sites", "banana"),
+				},
+				{
+					Webroot: "web",
+					Path:    filepath.Join(wd, "testdata", "home", "sites", "cherry"),
+				},
+				{
+					Webroot: "web",
+					Path:    filepath.Join(wd, "testdata", "home", "sites", "cherry", "dragonfruit"),
+				},
+				{
+					Webroot: "",
+					Path:    filepath.Join(wd, "testdata", "home", "plugins", "thinginator"),
 				},
 			},
 		},
 		{
-			name: "single suggestion when path values match but container directory is unique",
+			name: "multiple suggestions when working directory is top-level path",
 			args: args{
 				home: filepath.Join(wd),
-				wd:   filepath.Join(wd, "testdata", "home", "sites", "cherry"),
+				wd:   filepath.Join(wd, "testdata", "home", "sites"),
 			},
 			fields: fields{
 				Sites: []Site{
 					{
-						Webroot: "apple/web",
-						Path:    filepath.Join(wd, "testdata", "home", "sites"),
+						Webroot: "web",
+						Path:    filepath.Join(wd, "testdata", "home", "sites", "apple"),
 					},
 					{
-						Webroot: "banana/public",
-						Path:    filepath.Join(wd, "testdata", "home", "sites"),
+						Webroot: "public",
+						Path:    filepath.Join(wd, "testdata", "home", "sites", "banana"),
 					},
+					// this site is an exact path match, but our working directory is too vague
+					// for it to be the only suggestion
 					{
 						Webroot: "cherry/web",
 						Path:    filepath.Join(wd, "testdata", "home", "sites"),
 					},
+					// this site is in a different top-level directory and should not be a suggestion
 					{
-						Webroot: "cherry/dragonfruit/web",
-						Path:    filepath.Join(wd, "testdata", "home", "sites"),
+						Webroot: "",
+						Path:    filepath.Join(wd, "testdata", "home", "plugins"),
 					},
 				},
 			},
 			want: []Site{
 				{
-					Path:    filepath.Join(wd, "testdata", "home", "sites"),
+					Webroot: "web",
+					Path:    filepath.Join(wd, "testdata", "home", "sites", "apple"),
+				},
+				{
+					Webroot: "public",
+					Path:    filepath.Join(wd, "testdata", "home", "sites", "banana"),
+				},
+				{
 					Webroot: "cherry/web",
+					Path:    filepath.Join(wd, "testdata", "home", "sites"),
 				},
 			},
 		},
 		{
-			name: "multiple suggestions when sites use same directory",
+			name: "single suggestion when working directory is site path",
 			args: args{
 				home: filepath.Join(wd),
-				wd:   filepath.Join(wd, "testdata", "home", "sites", "cherry"),
+				wd:   filepath.Join(wd, "testdata", "home", "sites", "banana"),
 			},
 			fields: fields{
 				Sites: []Site{
 					{
-						Webroot:  "apple/web",
-						Path:     filepath.Join(wd, "testdata", "home", "sites"),
-						Hostname: "apple.nitro",
-					},
-					{
-						Webroot:  "banana/public",
-						Path:     filepath.Join(wd, "testdata", "home", "sites"),
-						Hostname: "banana.nitro",
-					},
-					{
-						Webroot:  "cherry/web",
-						Path:     filepath.Join(wd, "testdata", "home", "sites"),
-						Hostname: "cherry.nitro",
-					},
-					{
-						Webroot:  "cherry/dragonfruit/web",
-						Path:     filepath.Join(wd, "testdata", "home", "sites"),
-						Hostname: "dragonfruit.nitro",
+						Webroot: "web",
+						Path:    filepath.Join(wd, "testdata", "home", "sites", "apple"),
 					},
-					// this site uses the exact same path as cherry.nitro
 					{
-						Webroot:  "cherry/web",
-						Path:     filepath.Join(wd, "testdata", "home", "sites"),
-						Hostname: "doppelganger.nitro",
+						Webroot: "public",
+						Path:    filepath.Join(wd, "testdata", "home", "sites", "banana"),
 					},
 				},
 			},
 			want: []Site{
 				{
-					Webroot:  "cherry/web",
-					Path:     filepath.Join(wd, "testdata", "home", "sites"),
-					Hostname: "cherry.nitro",
+					Path:    filepath.Join(wd, "testdata", "home", "sites", "banana"),
+					Webroot: "public",
 				},
-				{
-					Webroot:  "cherry/web",
-					Path:     filepath.Join(wd, "testdata", "home", "sites"),
-					Hostname: "doppelganger.nitro",
+			},
+		},
+		{
+			name: "single suggestion when working directory is single exact match to site container path",
+			args: args{
+				home: filepath.Join(wd),
+				wd:   filepath.Join(wd, "testdata", "home", "sites", "cherry"),
+			},
+			fields: fields{
+				Sites: []Site{
+					{
+						Webroot: "web",
+						Path:    filepath.Join(wd, "testdata", "home", "sites", "apple"),
+					},
+					{
+						Webroot: "public",
+						Path:    filepath.Join(wd, "testdata", "home", "sites", "banana"),
+					},
+					{
+						Webroot: "cherry/web",
+						Path:    filepath.Join(wd, "testdata", "home", "sites"),
+					},
+					{
+						Webroot: "cherry/dragonfruit/web",
+						Path:    filepath.Join(wd, "testdata", "home", "sites"),
+					},
+				},
+			},
+			want: []Site{
+				{
+					Webroot: "cherry/web",
+					Path:    filepath.Join(wd, "testdata", "home", "sites"),
+				},
+			},
+		},
+		{
+			name: "single suggestion when path values match but container directory is unique",
+			args: args{
+				home: filepath.Join(wd),
+				wd:   filepath.Join(wd, "testdata", "home", "sites", "cherry"),
+			},
+			fields: fields{
+				Sites: []Site{
+					{
+						Webroot: "apple/web",
+						Path:    filepath.Join(wd, "testdata", "home", "sites"),
+					},
+					{
+						Webroot: "banana/public",
+						Path:    filepath.Join(wd, "testdata", "home", "sites"),
+					},
+					{
+						Webroot: "cherry/web",
+						Path:    filepath.Join(wd, "testdata", "home", "sites"),
+					},
+					{
+						Webroot: "cherry/dragonfruit/web",
+						Path:    filepath.Join(wd, "testdata", "home", "sites"),
+					},
+				},
+			},
+			want: []Site{
+				{
+					Path:    filepath.Join(wd, "testdata", "home", "sites"),
+					Webroot: "cherry/web",
+				},
+			},
+		},
+		{
+			name: "multiple suggestions when sites use same directory",
+			args: args{
+				home: filepath.Join(wd),
+				wd:   filepath.Join(wd, "testdata", "home", "sites", "cherry"),
+			},
+			fields: fields{
+				Sites: []Site{
+					{
+						Webroot:  "apple/web",
+						Path:     filepath.Join(wd, "testdata", "home", "sites"),
+						Hostname: "apple.nitro",
+					},
+					{
+						Webroot:  "banana/public",
+						Path:     filepath.Join(wd, "testdata", "home", "sites"),
+						Hostname: "banana.nitro",
+					},
+					{
+						Webroot:  "cherry/web",
+						Path:     filepath.Join(wd, "testdata", "home", "sites"),
+						Hostname: "cherry.nitro",
+					},
+					{
+						Webroot:  "cherry/dragonfruit/web",
+						Path:     filepath.Join(wd, "testdata", "home", "sites"),
+						Hostname: "dragonfruit.nitro",
+					},
+					// this site uses the exact same path as cherry.nitro
+					{
+						Webroot:  "cherry/web",
+						Path:     filepath.Join(wd, "testdata", "home", "sites"),
+						Hostname: "doppelganger.nitro",
+					},
+				},
+			},
+			want: []Site{
+				{
+					Webroot:  "cherry/web",
+					Path:     filepath.Join(wd, "testdata", "home", "sites"),
+					Hostname: "cherry.nitro",
+				},
+				{
+					Webroot:  "cherry/web",
+					Path:     filepath.Join(wd, "testdata", "home", "sites"),
+					Hostname: "doppelganger.nitro",
 				},
 			},
 		},
@@ -1423,3 +1992,1282 @@ func TestConfig_IsEmpty(t *testing.T) {
 		})
 	}
 }
+
+func TestConfig_UnknownFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "valid configs have no unknown fields",
+			file: filepath.Join("testdata", DirectoryName, "nitro.yaml"),
+			want: nil,
+		},
+		{
+			name: "unknown fields are returned with the line",
+			file: filepath.Join("testdata", DirectoryName, "unknown.yaml"),
+			want: []string{
+				"unknown config field 'memoryLimt' (line 11)",
+				"unknown config field 'servics' (line 12)",
+			},
+		},
+		{
+			name:    "missing files return an error",
+			file:    filepath.Join("testdata", DirectoryName, "missing.yaml"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{File: tt.file}
+
+			got, err := c.UnknownFields()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Config.UnknownFields() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Config.UnknownFields() = %v, want %v", got, tt.want)
+			}
+
+			// unknown fields are warnings when validating
+			if !tt.wantErr {
+				warnings, err := c.Validate("testdata")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if len(warnings) != len(tt.want) {
+					t.Errorf("expected %d warnings, got %v", len(tt.want), warnings)
+				}
+			}
+		})
+	}
+}
+
+func TestConfig_SitesInOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		sites   []Site
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "sites without dependencies keep the config order",
+			sites: []Site{
+				{Hostname: "apple.nitro"},
+				{Hostname: "banana.nitro"},
+			},
+			want: []string{"apple.nitro", "banana.nitro"},
+		},
+		{
+			name: "sites are ordered after their dependencies",
+			sites: []Site{
+				{Hostname: "apple.nitro", DependsOn: []string{"cherry.nitro", "redis"}},
+				{Hostname: "banana.nitro", DependsOn: []string{"apple.nitro"}},
+				{Hostname: "cherry.nitro"},
+			},
+			want: []string{"cherry.nitro", "apple.nitro", "banana.nitro"},
+		},
+		{
+			name: "dependency cycles return an error",
+			sites: []Site{
+				{Hostname: "apple.nitro", DependsOn: []string{"banana.nitro"}},
+				{Hostname: "banana.nitro", DependsOn: []string{"cherry.nitro"}},
+				{Hostname: "cherry.nitro", DependsOn: []string{"apple.nitro"}},
+			},
+			wantErr: ErrDependencyCycle,
+		},
+		{
+			name: "sites that depend on themselves return an error",
+			sites: []Site{
+				{Hostname: "apple.nitro", DependsOn: []string{"apple.nitro"}},
+			},
+			wantErr: ErrDependencyCycle,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{Sites: tt.sites}
+
+			got, err := c.SitesInOrder()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Config.SitesInOrder() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var hostnames []string
+			for _, s := range got {
+				hostnames = append(hostnames, s.Hostname)
+			}
+
+			if !reflect.DeepEqual(hostnames, tt.want) {
+				t.Errorf("Config.SitesInOrder() = %v, want %v", hostnames, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_DependencyContainerName(t *testing.T) {
+	c := &Config{
+		Containers: []Container{{Name: "elasticsearch"}},
+		Databases:  []Database{{Engine: "mysql", Version: "8.0", Port: "3306"}},
+		Services:   Services{Redis: true},
+		Sites:      []Site{{Hostname: "apple.nitro"}},
+	}
+
+	tests := []struct {
+		name    string
+		dep     string
+		want    string
+		wantErr bool
+	}{
+		{name: "sites use the hostname", dep: "apple.nitro", want: "apple.nitro"},
+		{name: "custom containers use the container suffix", dep: "elasticsearch", want: "elasticsearch.containers.nitro"},
+		{name: "databases can use the hostname", dep: "mysql-8.0-3306.database.nitro", want: "mysql-8.0-3306.database.nitro"},
+		{name: "databases can use the engine, version, and port", dep: "mysql-8.0-3306", want: "mysql-8.0-3306.database.nitro"},
+		{name: "databases can use the engine and version", dep: "mysql-8.0", want: "mysql-8.0-3306.database.nitro"},
+		{name: "enabled services use the service hostname", dep: "redis", want: "redis.service.nitro"},
+		{name: "disabled services return an error", dep: "minio", wantErr: true},
+		{name: "unknown names return an error", dep: "banana.nitro", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.DependencyContainerName(tt.dep)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Config.DependencyContainerName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Config.DependencyContainerName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSite_Hash(t *testing.T) {
+	site := Site{
+		Hostname: "tutorial.nitro",
+		Path:     "~/dev/tutorial",
+		Version:  "7.4",
+		Webroot:  "web",
+		PHP:      PHP{MemoryLimit: "512M"},
+	}
+
+	if site.Hash() != site.Hash() {
+		t.Error("expected the hash to be stable")
+	}
+
+	if len(site.Hash()) != 16 {
+		t.Errorf("expected the hash to be 16 characters, got %q", site.Hash())
+	}
+
+	same := site
+	if same.Hash() != site.Hash() {
+		t.Error("expected sites with the same settings to have the same hash")
+	}
+
+	// dependencies do not change the container
+	dependent := site
+	dependent.DependsOn = []string{"mysql-8.0-3306"}
+	if dependent.Hash() != site.Hash() {
+		t.Error("expected the dependencies to be ignored")
+	}
+
+	changed := site
+	changed.PHP.MemoryLimit = "1G"
+	if changed.Hash() == site.Hash() {
+		t.Error("expected changed settings to change the hash")
+	}
+}
+
+func TestDatabase_Hash(t *testing.T) {
+	db := Database{Engine: "mysql", Version: "8.0", Port: "3306"}
+
+	if db.Hash() != (Database{Engine: "mysql", Version: "8.0", Port: "3306"}).Hash() {
+		t.Error("expected databases with the same settings to have the same hash")
+	}
+
+	platform := db
+	platform.Platform = "linux/amd64"
+	if platform.Hash() == db.Hash() {
+		t.Error("expected changed settings to change the hash")
+	}
+
+	skip := db
+	skip.SkipBackup = true
+	if skip.Hash() != db.Hash() {
+		t.Error("expected skip backup to not change the hash")
+	}
+}
+
+func TestConfig_ValidateSyncedFolders(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symlinks requires elevated permissions on windows")
+	}
+
+	home, err := ioutil.TempDir("", "nitro-home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+
+	// resolve the temp dir, which is a symlink on macOS
+	home, err = filepath.EvalSymlinks(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// create a site in dropbox and a symlink to it
+	if err := os.MkdirAll(filepath.Join(home, "Dropbox", "synced"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(home, "dev", "local"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Symlink(filepath.Join(home, "Dropbox", "synced"), filepath.Join(home, "dev", "linked")); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := Config{
+		Sites: []Site{
+			{Hostname: "local.nitro", Path: "~/dev/local", Version: "8.0", Webroot: "web"},
+			{Hostname: "synced.nitro", Path: "~/Dropbox/synced", Version: "8.0", Webroot: "web"},
+			{Hostname: "linked.nitro", Path: "~/dev/linked", Version: "8.0", Webroot: "web"},
+			{Hostname: "missing.nitro", Path: "~/dev/missing", Version: "8.0", Webroot: "web"},
+		},
+	}
+
+	warnings, err := cfg.Validate(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		fmt.Sprintf("site synced.nitro path %s is synced by Dropbox and will be slow to mount", filepath.Join(home, "Dropbox", "synced")),
+		fmt.Sprintf("site linked.nitro path %s is a symlink to %s, which is synced by Dropbox and will be slow to mount", filepath.Join(home, "dev", "linked"), filepath.Join(home, "Dropbox", "synced")),
+	}
+
+	if !reflect.DeepEqual(warnings, want) {
+		t.Errorf("expected the warnings to be\n%v\ngot\n%v", want, warnings)
+	}
+}
+
+func Test_syncedFolder(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/Users/nitro/dev/site", want: ""},
+		{path: "/Users/nitro/Library/Mobile Documents/com~apple~CloudDocs/site", want: "iCloud"},
+		{path: "/Users/nitro/Dropbox/site", want: "Dropbox"},
+		{path: "/Users/nitro/Dropbox (Personal)/site", want: "Dropbox"},
+		{path: "/Users/nitro/Library/CloudStorage/GoogleDrive-nitro@example.com/My Drive/site", want: "Google Drive"},
+		{path: "/Users/nitro/Library/CloudStorage/Box-Box/site", want: "a cloud storage provider"},
+		{path: "/Users/nitro/OneDrive - Craft/site", want: "OneDrive"},
+		{path: "/Users/nitro/dev/dropbox-clone", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := syncedFolder(tt.path); got != tt.want {
+				t.Errorf("syncedFolder() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_UseProfile(t *testing.T) {
+	profiles := map[string][]string{
+		"minimal": {"mailhog"},
+		"full":    {"mailhog", "redis", "minio"},
+		"broken":  {"mailhog", "solr"},
+	}
+
+	tests := []struct {
+		name     string
+		services Services
+		profile  string
+		want     Services
+		wantErr  error
+	}{
+		{
+			name:     "no profile keeps the services from the config",
+			services: Services{Redis: true, DynamoDB: true},
+			want:     Services{Redis: true, DynamoDB: true},
+		},
+		{
+			name:     "profiles replace the services from the config",
+			services: Services{Redis: true, DynamoDB: true},
+			profile:  "minimal",
+			want:     Services{Mailhog: true},
+		},
+		{
+			name:    "profiles enable all of the listed services",
+			profile: "full",
+			want:    Services{Mailhog: true, Redis: true, Minio: true},
+		},
+		{
+			name:     "profiles keep the service settings from the config",
+			services: Services{Redis: true, RedisVersion: "6.2", MinioUser: "admin", MinioPassword: "secret", ElasticsearchHeapSize: "1g", MeilisearchMasterKey: "key"},
+			profile:  "full",
+			want:     Services{Mailhog: true, Redis: true, RedisVersion: "6.2", Minio: true, MinioUser: "admin", MinioPassword: "secret", ElasticsearchHeapSize: "1g", MeilisearchMasterKey: "key"},
+		},
+		{
+			name:     "unknown profiles return an error",
+			services: Services{Redis: true},
+			profile:  "missing",
+			want:     Services{Redis: true},
+			wantErr:  ErrUnknownProfile,
+		},
+		{
+			name:     "profiles with unknown services return an error",
+			services: Services{Redis: true},
+			profile:  "broken",
+			want:     Services{Redis: true},
+			wantErr:  ErrUnknownService,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{Services: tt.services, Profiles: profiles}
+
+			if err := c.UseProfile(tt.profile); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UseProfile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(c.Services, tt.want) {
+				t.Errorf("expected the services to be %v, got %v", tt.want, c.Services)
+			}
+		})
+	}
+}
+
+func TestPHP_Override(t *testing.T) {
+	tests := []struct {
+		name     string
+		defaults PHP
+		site     PHP
+		want     PHP
+	}{
+		{
+			name: "sites without settings use the defaults",
+			defaults: PHP{
+				MemoryLimit:      "512M",
+				MaxExecutionTime: 300,
+				OpcacheEnable:    true,
+			},
+			want: PHP{
+				MemoryLimit:      "512M",
+				MaxExecutionTime: 300,
+				OpcacheEnable:    true,
+			},
+		},
+		{
+			name: "site settings replace the defaults",
+			defaults: PHP{
+				MemoryLimit:       "512M",
+				MaxExecutionTime:  300,
+				UploadMaxFileSize: "128M",
+			},
+			site: PHP{
+				MemoryLimit:       "2G",
+				UploadMaxFileSize: "1024M",
+			},
+			want: PHP{
+				MemoryLimit:       "2G",
+				MaxExecutionTime:  300,
+				UploadMaxFileSize: "1024M",
+			},
+		},
+		{
+			name:     "bool settings are enabled by the site or the defaults",
+			defaults: PHP{OpcacheEnable: true},
+			site:     PHP{OpcacheValidateTimestamps: true},
+			want:     PHP{OpcacheEnable: true, OpcacheValidateTimestamps: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.defaults.Override(tt.site); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Override() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUseEnvironment(t *testing.T) {
+	defer UseEnvironment("")
+
+	tests := []struct {
+		name     string
+		env      string
+		wantFile string
+		wantEnv  string
+		wantErr  error
+	}{
+		{
+			name:     "empty names use the default environment",
+			wantFile: "nitro.yaml",
+		},
+		{
+			name:     "default uses the default environment",
+			env:      "default",
+			wantFile: "nitro.yaml",
+		},
+		{
+			name:     "named environments use their own config file",
+			env:      "work",
+			wantFile: "work.yaml",
+			wantEnv:  "work",
+		},
+		{
+			name:     "invalid names return an error",
+			env:      "../work",
+			wantFile: "nitro.yaml",
+			wantErr:  ErrInvalidEnvironment,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			UseEnvironment("")
+
+			if err := UseEnvironment(tt.env); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UseEnvironment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if FileName != tt.wantFile {
+				t.Errorf("expected the file name to be %s, got %s", tt.wantFile, FileName)
+			}
+
+			if Environment() != tt.wantEnv {
+				t.Errorf("expected the environment to be %q, got %q", tt.wantEnv, Environment())
+			}
+		})
+	}
+}
+
+func TestSite_Merge(t *testing.T) {
+	tests := []struct {
+		name    string
+		site    Site
+		project Site
+		want    Site
+	}{
+		{
+			name: "project settings replace the site settings",
+			site: Site{
+				Hostname: "craft-dev.nitro",
+				Path:     "~/dev/craft-dev",
+				Version:  "7.4",
+				Webroot:  "web",
+				Exclude:  []string{"node_modules"},
+			},
+			project: Site{
+				Hostname: "project.nitro",
+				Path:     "~/dev/project",
+				Version:  "8.0",
+				Webroot:  "public",
+				Xdebug:   true,
+			},
+			want: Site{
+				Hostname: "craft-dev.nitro",
+				Path:     "~/dev/craft-dev",
+				Version:  "8.0",
+				Webroot:  "public",
+				Xdebug:   true,
+				Exclude:  []string{"node_modules"},
+			},
+		},
+		{
+			name: "environment variables are combined",
+			site: Site{
+				Hostname: "craft-dev.nitro",
+				Env:      map[string]string{"APP_ENV": "production", "APP_ID": "craft"},
+			},
+			project: Site{
+				Env: map[string]string{"APP_ENV": "dev"},
+			},
+			want: Site{
+				Hostname: "craft-dev.nitro",
+				Env:      map[string]string{"APP_ENV": "dev", "APP_ID": "craft"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.site.Merge(tt.project); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Site.Merge() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_MergeProjects(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	home := filepath.Join(wd, "testdata", "home")
+
+	cfg := &Config{
+		Sites: []Site{
+			{
+				Hostname: "apple.nitro",
+				Path:     "~/sites/apple",
+				Version:  "7.4",
+				Webroot:  "web",
+			},
+			{
+				Hostname: "banana.nitro",
+				Path:     "~/sites/banana",
+				Version:  "7.4",
+				Webroot:  "web",
+				Env:      map[string]string{"APP_ID": "banana"},
+			},
+		},
+	}
+
+	merged, err := cfg.MergeProjects(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"banana.nitro"}; !reflect.DeepEqual(merged, want) {
+		t.Errorf("expected the merged sites to be %v, got %v", want, merged)
+	}
+
+	want := []Site{
+		{
+			Hostname: "apple.nitro",
+			Path:     "~/sites/apple",
+			Version:  "7.4",
+			Webroot:  "web",
+		},
+		{
+			Hostname:  "banana.nitro",
+			Path:      "~/sites/banana",
+			Version:   "8.0",
+			Webroot:   "public",
+			Env:       map[string]string{"APP_ID": "banana", "APP_ENV": "dev"},
+			PostStart: []string{"composer install"},
+		},
+	}
+
+	if !reflect.DeepEqual(cfg.Sites, want) {
+		t.Errorf("expected the sites to be %v, got %v", want, cfg.Sites)
+	}
+}
+
+func TestLoad_Interpolation(t *testing.T) {
+	t.Setenv("NITRO_TEST_SERVER_ID", "my-id")
+	t.Setenv("NITRO_TEST_SERVER_TOKEN", "my-token")
+
+	home := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(home, DirectoryName), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	content := "blackfire:\n    server_id: ${NITRO_TEST_SERVER_ID}\n    server_token: token-${NITRO_TEST_SERVER_TOKEN}\nsites:\n    - hostname: craft-dev.nitro\n      path: ~/dev/craft-dev\n      version: \"8.0\"\n      php:\n        memory_limit: ${NITRO_TEST_MISSING}\n      webroot: web\n"
+	file := filepath.Join(home, DirectoryName, FileName)
+	if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Blackfire{ServerID: "my-id", ServerToken: "token-my-token"}
+	if cfg.Blackfire != want {
+		t.Errorf("expected the blackfire settings to be %v, got %v", want, cfg.Blackfire)
+	}
+
+	if cfg.Sites[0].PHP.MemoryLimit != "" {
+		t.Errorf("expected the memory limit to be empty, got %q", cfg.Sites[0].PHP.MemoryLimit)
+	}
+
+	// changed values are saved, unchanged values keep the references
+	cfg.Sites[0].Version = "7.4"
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{"server_id: ${NITRO_TEST_SERVER_ID}", "server_token: token-${NITRO_TEST_SERVER_TOKEN}", "version: \"7.4\""} {
+		if !strings.Contains(string(saved), s) {
+			t.Errorf("expected the saved config to contain %q, got:\n%s", s, saved)
+		}
+	}
+
+	if strings.Contains(string(saved), "my-token") {
+		t.Errorf("expected the saved config to not contain the token, got:\n%s", saved)
+	}
+}
+
+func TestConfig_Lint(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	home := filepath.Join(wd, "testdata", "home")
+
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "valid configs have no problems",
+			content: "sites:\n  - hostname: apple.nitro\n    path: ~/sites/apple\n    version: \"8.0\"\n    webroot: web\n",
+		},
+		{
+			name:    "all of the problems are returned with the line",
+			content: "databases:\n  - engine: mysql\n    version: \"8.0\"\nsites:\n  - hostname: apple.nitro\n    path: ~/sites/apple\n    version: \"5.6\"\n    webroot: public\n  - hostname: apple.nitro\n    aliases:\n      - banana.nitro\n    path: ~/sites/missing\n    version: \"8.0\"\n    webroot: web\n  - hostname: banana.nitro\n    path: ~/sites/banana\n    version: \"8.0\"\n    webroot: public\n",
+			want: []string{
+				"line 2: database mysql 8.0 does not have a port",
+				`line 7: the PHP version "5.6" is not valid for site apple.nitro`,
+				"line 8: the web root " + filepath.Join(home, "sites", "apple", "public") + " for site apple.nitro does not exist",
+				"line 9: the hostname apple.nitro is used by more than one site",
+				"line 12: the path " + filepath.Join(home, "sites", "missing") + " for site apple.nitro does not exist",
+				"line 15: the hostname banana.nitro is already an alias for apple.nitro",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), FileName)
+			if err := ioutil.WriteFile(file, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg := &Config{File: file}
+			if err := yaml.Unmarshal([]byte(tt.content), cfg); err != nil {
+				t.Fatal(err)
+			}
+
+			problems, err := cfg.Lint(home)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, p := range problems {
+				got = append(got, p.String())
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Config.Lint() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_ProxyHostname(t *testing.T) {
+	tests := []struct {
+		name     string
+		tld      string
+		env      string
+		hostname string
+		want     string
+	}{
+		{
+			name:     "the default tld is nitro",
+			hostname: "mailhog.service.nitro",
+			want:     "mailhog.service.nitro",
+		},
+		{
+			name:     "the config tld replaces nitro",
+			tld:      "test",
+			hostname: "mailhog.service.nitro",
+			want:     "mailhog.service.test",
+		},
+		{
+			name:     "the leading dot is removed from the tld",
+			tld:      ".localdev",
+			hostname: "adminer.containers.nitro",
+			want:     "adminer.containers.localdev",
+		},
+		{
+			name:     "the environment variable is used when the config does not set a tld",
+			env:      "test",
+			hostname: "minio.service.nitro",
+			want:     "minio.service.test",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NITRO_DEFAULT_TLD", tt.env)
+
+			c := &Config{TLD: tt.tld}
+			if got := c.ProxyHostname(tt.hostname); got != tt.want {
+				t.Errorf("Config.ProxyHostname() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateTLD(t *testing.T) {
+	tests := []struct {
+		name    string
+		tld     string
+		wantErr error
+	}{
+		{
+			name: "the default tld is valid",
+		},
+		{
+			name: "custom tlds are valid",
+			tld:  "test",
+		},
+		{
+			name:    "tlds with dots are invalid",
+			tld:     "local.dev",
+			wantErr: ErrInvalidTLD,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{TLD: tt.tld}
+			if _, err := c.Validate(t.TempDir()); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Config.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateBasicAuth(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		basicAuth *BasicAuth
+		wantErr   error
+	}{
+		{
+			name: "sites without basic auth are valid",
+		},
+		{
+			name:      "basic auth with a user and password is valid",
+			basicAuth: &BasicAuth{User: "nitro", Password: "secret"},
+		},
+		{
+			name:      "basic auth without a password is invalid",
+			basicAuth: &BasicAuth{User: "nitro"},
+			wantErr:   ErrInvalidBasicAuth,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{
+				Sites: []Site{{Hostname: "apple.nitro", Path: "~/sites/apple", Version: "8.0", Webroot: "web", BasicAuth: tt.basicAuth}},
+			}
+
+			if _, err := c.Validate(filepath.Join(wd, "testdata", "home")); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Config.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateProxyPorts(t *testing.T) {
+	tests := []struct {
+		name    string
+		proxy   Proxy
+		wantErr error
+	}{
+		{
+			name: "the default ports are valid",
+		},
+		{
+			name:  "custom ports are valid",
+			proxy: Proxy{HTTPPort: "8080", HTTPSPort: "8443", APIPort: "5001"},
+		},
+		{
+			name:    "ports that are not numbers are invalid",
+			proxy:   Proxy{HTTPPort: "http"},
+			wantErr: ErrInvalidPort,
+		},
+		{
+			name:    "ports out of range are invalid",
+			proxy:   Proxy{HTTPSPort: "70000"},
+			wantErr: ErrInvalidPort,
+		},
+		{
+			name:    "ports used more than once are invalid",
+			proxy:   Proxy{HTTPPort: "8080", HTTPSPort: "8080"},
+			wantErr: ErrDuplicatePort,
+		},
+		{
+			name:  "the api port is not checked when using exec",
+			proxy: Proxy{HTTPPort: "5000", APITransport: APITransportExec},
+		},
+		{
+			name:    "unknown api transports are invalid",
+			proxy:   Proxy{APITransport: "unix"},
+			wantErr: ErrInvalidAPITransport,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{Proxy: tt.proxy}
+			if _, err := c.Validate(t.TempDir()); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Config.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_SiteURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		proxy  Proxy
+		env    map[string]string
+		scheme string
+		want   string
+	}{
+		{
+			name:   "default https port is not included",
+			scheme: "https",
+			want:   "https://craft-dev.nitro",
+		},
+		{
+			name:   "default http port is not included",
+			scheme: "http",
+			want:   "http://craft-dev.nitro",
+		},
+		{
+			name:   "custom https port is included",
+			proxy:  Proxy{HTTPSPort: "8443"},
+			scheme: "https",
+			want:   "https://craft-dev.nitro:8443",
+		},
+		{
+			name:   "custom http port is included",
+			proxy:  Proxy{HTTPPort: "8080"},
+			scheme: "http",
+			want:   "http://craft-dev.nitro:8080",
+		},
+		{
+			name:   "environment variable is used when the config does not set a port",
+			env:    map[string]string{"NITRO_HTTPS_PORT": "9443"},
+			scheme: "https",
+			want:   "https://craft-dev.nitro:9443",
+		},
+		{
+			name:   "config overrides the environment variable",
+			proxy:  Proxy{HTTPSPort: "8443"},
+			env:    map[string]string{"NITRO_HTTPS_PORT": "9443"},
+			scheme: "https",
+			want:   "https://craft-dev.nitro:8443",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			c := &Config{Proxy: tt.proxy}
+			if got := c.SiteURL(tt.scheme, "craft-dev.nitro"); got != tt.want {
+				t.Errorf("Config.SiteURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateHostProxies(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		hostProxies []HostProxy
+		wantErr     error
+	}{
+		{
+			name:        "host proxies with a hostname and port are valid",
+			hostProxies: []HostProxy{{Hostname: "vite.craft-dev.nitro", Port: 5173}},
+		},
+		{
+			name:        "host proxies without a hostname are invalid",
+			hostProxies: []HostProxy{{Port: 5173}},
+			wantErr:     ErrInvalidHostProxy,
+		},
+		{
+			name:        "host proxies without a port are invalid",
+			hostProxies: []HostProxy{{Hostname: "vite.craft-dev.nitro"}},
+			wantErr:     ErrInvalidHostProxy,
+		},
+		{
+			name:        "host proxies using a site hostname are invalid",
+			hostProxies: []HostProxy{{Hostname: "craft-dev.nitro", Port: 5173}},
+			wantErr:     ErrHostProxyConflict,
+		},
+		{
+			name:        "host proxies using a site alias are invalid",
+			hostProxies: []HostProxy{{Hostname: "alias.nitro", Port: 5173}},
+			wantErr:     ErrHostProxyConflict,
+		},
+		{
+			name:        "host proxies using the same hostname are invalid",
+			hostProxies: []HostProxy{{Hostname: "vite.craft-dev.nitro", Port: 5173}, {Hostname: "vite.craft-dev.nitro", Port: 5174}},
+			wantErr:     ErrHostProxyConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{
+				Sites:       []Site{{Hostname: "craft-dev.nitro", Aliases: []string{"alias.nitro"}, Path: wd}},
+				HostProxies: tt.hostProxies,
+			}
+			if _, err := c.Validate(t.TempDir()); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Config.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateLimits(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		limits  *Limits
+		wantErr error
+	}{
+		{
+			name: "sites without limits are valid",
+		},
+		{
+			name:   "sizes and durations are valid",
+			limits: &Limits{MaxBodySize: "512M", ReadTimeout: "10m", SendTimeout: "90s"},
+		},
+		{
+			name:    "sizes with unknown units are invalid",
+			limits:  &Limits{MaxBodySize: "512MB"},
+			wantErr: ErrInvalidLimits,
+		},
+		{
+			name:    "timeouts that are not durations are invalid",
+			limits:  &Limits{ReadTimeout: "600"},
+			wantErr: ErrInvalidLimits,
+		},
+		{
+			name:    "timeouts under a second are invalid",
+			limits:  &Limits{SendTimeout: "500ms"},
+			wantErr: ErrInvalidLimits,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{Sites: []Site{{Hostname: "craft-dev.nitro", Path: wd, Limits: tt.limits}}}
+			if _, err := c.Validate(t.TempDir()); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Config.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSite_GetWebSocketsPort(t *testing.T) {
+	tests := []struct {
+		name string
+		site Site
+		want int
+	}{
+		{
+			name: "disabled websockets return zero",
+			site: Site{WebSocketsPort: 6002},
+		},
+		{
+			name: "enabled websockets use the default port",
+			site: Site{WebSockets: true},
+			want: DefaultWebSocketsPort,
+		},
+		{
+			name: "enabled websockets use the sites port",
+			site: Site{WebSockets: true, WebSocketsPort: 6002},
+			want: 6002,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.site.GetWebSocketsPort(); got != tt.want {
+				t.Errorf("Site.GetWebSocketsPort() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSite_GetCertificate(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "site.pem"), []byte("cert"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "site-key.pem"), []byte("key"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		site     Site
+		wantCert string
+		wantKey  string
+		wantErr  bool
+	}{
+		{
+			name: "sites without a certificate return nothing",
+			site: Site{Hostname: "craft-dev.nitro", Path: dir},
+		},
+		{
+			name:     "relative paths are in the sites path",
+			site:     Site{Hostname: "craft-dev.nitro", Path: dir, Certificate: "site.pem", Key: "site-key.pem"},
+			wantCert: "cert",
+			wantKey:  "key",
+		},
+		{
+			name:     "absolute paths are used",
+			site:     Site{Hostname: "craft-dev.nitro", Path: t.TempDir(), Certificate: filepath.Join(dir, "site.pem"), Key: filepath.Join(dir, "site-key.pem")},
+			wantCert: "cert",
+			wantKey:  "key",
+		},
+		{
+			name:    "missing files return an error",
+			site:    Site{Hostname: "craft-dev.nitro", Path: dir, Certificate: "missing.pem", Key: "site-key.pem"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert, key, err := tt.site.GetCertificate(dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Site.GetCertificate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if string(cert) != tt.wantCert || string(key) != tt.wantKey {
+				t.Errorf("Site.GetCertificate() = %q, %q, want %q, %q", cert, key, tt.wantCert, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateCertificate(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		certificate string
+		key         string
+		wantErr     error
+	}{
+		{
+			name: "sites without a certificate are valid",
+		},
+		{
+			name:        "sites with a certificate and key are valid",
+			certificate: "site.pem",
+			key:         "site-key.pem",
+		},
+		{
+			name:        "sites with a certificate and no key are invalid",
+			certificate: "site.pem",
+			wantErr:     ErrInvalidCertificate,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{Sites: []Site{{Hostname: "craft-dev.nitro", Path: wd, Certificate: tt.certificate, Key: tt.key}}}
+			if _, err := c.Validate(t.TempDir()); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Config.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateResources(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		site    Site
+		db      *Database
+		wantErr error
+	}{
+		{
+			name: "sites without limits are valid",
+			site: Site{Hostname: "craft-dev.nitro", Path: wd},
+		},
+		{
+			name: "cpus and memory sizes are valid",
+			site: Site{Hostname: "craft-dev.nitro", Path: wd, CPUs: 1.5, Memory: "2G"},
+			db:   &Database{Engine: "mysql", Version: "8.0", Port: "3306", CPUs: 0.5, Memory: "512m"},
+		},
+		{
+			name:    "negative cpus are invalid",
+			site:    Site{Hostname: "craft-dev.nitro", Path: wd, CPUs: -1},
+			wantErr: ErrInvalidResources,
+		},
+		{
+			name:    "memory with unknown units is invalid",
+			site:    Site{Hostname: "craft-dev.nitro", Path: wd, Memory: "2GB"},
+			wantErr: ErrInvalidResources,
+		},
+		{
+			name:    "memory under 6M is invalid for databases",
+			site:    Site{Hostname: "craft-dev.nitro", Path: wd},
+			db:      &Database{Engine: "mysql", Version: "8.0", Port: "3306", Memory: "4M"},
+			wantErr: ErrInvalidResources,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{Sites: []Site{tt.site}}
+			if tt.db != nil {
+				c.Databases = []Database{*tt.db}
+			}
+
+			if _, err := c.Validate(t.TempDir()); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Config.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMemoryBytes(t *testing.T) {
+	tests := []struct {
+		name    string
+		memory  string
+		want    int64
+		wantErr bool
+	}{
+		{
+			name: "empty sizes are not limited",
+		},
+		{
+			name:   "sizes without a unit are bytes",
+			memory: "1048576",
+			want:   1 << 20,
+		},
+		{
+			name:   "kilobytes are converted",
+			memory: "512k",
+			want:   512 << 10,
+		},
+		{
+			name:   "megabytes are converted",
+			memory: "512M",
+			want:   512 << 20,
+		},
+		{
+			name:   "gigabytes are converted",
+			memory: "2G",
+			want:   2 << 30,
+		},
+		{
+			name:    "unknown units return an error",
+			memory:  "2GB",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MemoryBytes(tt.memory)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MemoryBytes() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("MemoryBytes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvironments(t *testing.T) {
+	home := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(home, DirectoryName), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range []string{"nitro.yaml", "work.yaml", "personal.yaml", "Invalid Name.yaml", "nitrod.token"} {
+		if err := ioutil.WriteFile(filepath.Join(home, DirectoryName, f), []byte("sites: []\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := Environments(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"default", "personal", "work"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Environments() = %v, want %v", got, want)
+	}
+
+	if _, err := LoadEnvironment(home, "work"); err != nil {
+		t.Errorf("LoadEnvironment() error = %v", err)
+	}
+
+	if _, err := LoadEnvironment(home, "missing"); err == nil {
+		t.Errorf("expected an error for an environment without a config file")
+	}
+
+	if _, err := LoadEnvironment(home, "../work"); !errors.Is(err, ErrInvalidEnvironment) {
+		t.Errorf("expected ErrInvalidEnvironment for an invalid name, got %v", err)
+	}
+}
+
+func TestConfig_Marshal(t *testing.T) {
+	t.Setenv("NITRO_TEST_MINIO_PASSWORD", "secret-password")
+
+	home := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(home, DirectoryName), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	content := "services:\n    minio: true\n    minio_password: ${NITRO_TEST_MINIO_PASSWORD}\n"
+	if err := ioutil.WriteFile(filepath.Join(home, DirectoryName, FileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := cfg.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(string(data), "secret-password") {
+		t.Errorf("expected the value of the environment variable to not be marshaled, got:\n%s", data)
+	}
+
+	if !strings.Contains(string(data), "minio_password: ${NITRO_TEST_MINIO_PASSWORD}") {
+		t.Errorf("expected the reference to be marshaled, got:\n%s", data)
+	}
+}
@@ -1,6 +1,7 @@
 package containerlabels
 
 import (
+	"context"
 	"strings"
 
 	"github.com/craftcms/nitro/pkg/config"
@@ -17,6 +18,12 @@ const (
 	// NitroContainerPort is used to identify a custom containers port in the config
 	NitroContainerPort = "com.craftcms.nitro.container-port"
 
+	// ConfigHash is a hash of the config that was used to create the container
+	ConfigHash = "com.craftcms.nitro.config-hash"
+
+	// DNS is used to label the DNS container with the top level domain and port it resolves
+	DNS = "com.craftcms.nitro.dns"
+
 	// DatabaseCompatibility is the compatibility of the database (e.g. mariadb and mysql are compatible)
 	DatabaseCompatibility = "com.craftcms.nitro.database-compatibility"
 
@@ -26,15 +33,32 @@ const (
 	// DatabasePort is used to identify the port that is being used for a database container (e.g. mysql, postgres)
 	DatabasePort = "com.craftcms.nitro.database-port"
 
+	// DatabaseSkipBackup is used to skip the backup of a database container when it is removed
+	DatabaseSkipBackup = "com.craftcms.nitro.database-skip-backup"
+
 	// DatabaseVersion is the version of the database the container is running (e.g. 11, 12, 5.7)
 	DatabaseVersion = "com.craftcms.nitro.database-version"
 
+	// ElasticsearchHeapSize is the java heap size for the elasticsearch service container (e.g. 512m)
+	ElasticsearchHeapSize = "com.craftcms.nitro.elasticsearch-heap-size"
+
+	// EffectiveHash is a hash of the effective config for a site container, including
+	// settings that are not in the config file such as ignore files
+	EffectiveHash = "com.craftcms.nitro.effective-hash"
+
+	// Environment is the named environment a site or custom container was created for,
+	// containers for the default environment do not have the label
+	Environment = "com.craftcms.nitro.environment"
+
 	// Extensions is used for a list of comma seperated extensions for a site
 	Extensions = "com.craftcms.nitro.extensions"
 
 	// Host is used to identify a web application by the hostname of the site (e.g demo.nitro)
 	Host = "com.craftcms.nitro.host"
 
+	// Nginx is a hash of the custom nginx directives for a site
+	Nginx = "com.craftcms.nitro.nginx"
+
 	// PAth is used for containers that mount specific paths such as composer and npm
 	Path = "com.craftcms.nitro.path"
 
@@ -50,6 +74,18 @@ const (
 	// ProxyVersion is used to label a proxy container with a specific version
 	ProxyVersion = "com.craftcms.nitro.proxy-version"
 
+	// ProxyPorts is used to label a proxy container with the host ports it binds to
+	ProxyPorts = "com.craftcms.nitro.proxy-ports"
+
+	// ProxyToken is used to label a proxy container with a hash of the token for the nitrod API
+	ProxyToken = "com.craftcms.nitro.proxy-token"
+
+	// RunID is used to identify the apply invocation that created a container
+	RunID = "com.craftcms.nitro.run-id"
+
+	// Share is used to label a tunnel container with the hostname of the site it shares
+	Share = "com.craftcms.nitro.share"
+
 	// Type is used to identity the type of container
 	Type = "com.craftcms.nitro.type"
 
@@ -57,12 +93,49 @@ const (
 	Webroot = "com.craftcms.nitro.webroot"
 )
 
+type runIDKey struct{}
+
+// WithRunID returns a copy of the context that carries the run ID for
+// an apply. Containers created with the context will be labeled with
+// the run ID.
+func WithRunID(ctx context.Context, id string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return context.WithValue(ctx, runIDKey{}, id)
+}
+
+// RunIDFromContext returns the run ID from the context, if there is
+// no run ID it will return an empty string.
+func RunIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	id, _ := ctx.Value(runIDKey{}).(string)
+
+	return id
+}
+
+// StampRunID adds the run ID label from the context to the labels for
+// a new container. If the context does not have a run ID, the labels
+// are returned unchanged.
+func StampRunID(ctx context.Context, labels map[string]string) map[string]string {
+	if id := RunIDFromContext(ctx); id != "" {
+		labels[RunID] = id
+	}
+
+	return labels
+}
+
 // ForSite takes a site and returns labels to use on the sites container.
 func ForSite(s config.Site) map[string]string {
 	labels := map[string]string{
-		Nitro:   "true",
-		Host:    s.Hostname,
-		Webroot: s.Webroot,
+		Nitro:      "true",
+		Host:       s.Hostname,
+		Webroot:    s.Webroot,
+		ConfigHash: s.Hash(),
 	}
 
 	// if there are extensions, add them as comma separated
@@ -70,17 +143,39 @@ func ForSite(s config.Site) map[string]string {
 		labels[Extensions] = strings.Join(s.Extensions, ",")
 	}
 
+	if env := config.Environment(); env != "" {
+		labels[Environment] = env
+	}
+
 	return labels
 }
 
 // ForCustomContainer takes a custom container configuration and
 // applies the labels for the container.
 func ForCustomContainer(c config.Container) map[string]string {
-	return map[string]string{
+	labels := map[string]string{
 		Nitro:          "true",
 		Type:           "custom",
 		NitroContainer: c.Name,
+		ConfigHash:     c.Hash(),
+	}
+
+	if env := config.Environment(); env != "" {
+		labels[Environment] = env
 	}
+
+	return labels
+}
+
+// OtherEnvironment returns true when a site or custom container was created for
+// an environment other than the selected environment. The network, proxy,
+// databases, and services are shared by all of the environments.
+func OtherEnvironment(c types.Container) bool {
+	if c.Labels[Host] == "" && c.Labels[NitroContainer] == "" {
+		return false
+	}
+
+	return c.Labels[Environment] != config.Environment()
 }
 
 // Identify takes an existing container and examines the
@@ -0,0 +1,112 @@
+package containerlabels
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+
+	"github.com/craftcms/nitro/pkg/config"
+)
+
+func TestStampRunID(t *testing.T) {
+	type args struct {
+		ctx    context.Context
+		labels map[string]string
+	}
+	tests := []struct {
+		name string
+		args args
+		want map[string]string
+	}{
+		{
+			name: "context with a run id adds the label",
+			args: args{
+				ctx:    WithRunID(context.Background(), "abc123"),
+				labels: map[string]string{Nitro: "true"},
+			},
+			want: map[string]string{Nitro: "true", RunID: "abc123"},
+		},
+		{
+			name: "context without a run id does not add the label",
+			args: args{
+				ctx:    context.Background(),
+				labels: map[string]string{Nitro: "true"},
+			},
+			want: map[string]string{Nitro: "true"},
+		},
+		{
+			name: "nil context does not add the label",
+			args: args{
+				labels: map[string]string{Nitro: "true"},
+			},
+			want: map[string]string{Nitro: "true"},
+		},
+		{
+			name: "nil context can be given a run id",
+			args: args{
+				ctx:    WithRunID(nil, "abc123"),
+				labels: map[string]string{Nitro: "true"},
+			},
+			want: map[string]string{Nitro: "true", RunID: "abc123"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StampRunID(tt.args.ctx, tt.args.labels); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("StampRunID() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOtherEnvironment(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    string
+		labels map[string]string
+		want   bool
+	}{
+		{
+			name:   "default sites are in the default environment",
+			labels: map[string]string{Host: "craft-dev.nitro"},
+			want:   false,
+		},
+		{
+			name:   "sites for a named environment are in another environment",
+			labels: map[string]string{Host: "craft-dev.nitro", Environment: "work"},
+			want:   true,
+		},
+		{
+			name:   "default sites are in another environment for a named environment",
+			env:    "work",
+			labels: map[string]string{Host: "craft-dev.nitro"},
+			want:   true,
+		},
+		{
+			name:   "custom containers for the environment are not in another environment",
+			env:    "work",
+			labels: map[string]string{NitroContainer: "mysql", Environment: "work"},
+			want:   false,
+		},
+		{
+			name:   "services are shared by the environments",
+			env:    "work",
+			labels: map[string]string{Type: "redis"},
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := config.UseEnvironment(tt.env); err != nil {
+				t.Fatal(err)
+			}
+			defer config.UseEnvironment("")
+
+			if got := OtherEnvironment(types.Container{Labels: tt.labels}); got != tt.want {
+				t.Errorf("OtherEnvironment() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
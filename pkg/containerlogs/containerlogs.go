@@ -0,0 +1,27 @@
+package containerlogs
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// Tail returns the last lines of the containers logs with stdout and stderr combined, it is
+// used to show why a container failed (e.g. in apply and self-diagnose).
+func Tail(ctx context.Context, docker client.ContainerAPIClient, id, lines string) ([]byte, error) {
+	rdr, err := docker.ContainerLogs(ctx, id, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true, Tail: lines})
+	if err != nil {
+		return nil, err
+	}
+	defer rdr.Close()
+
+	buf := &bytes.Buffer{}
+	if _, err := stdcopy.StdCopy(buf, buf, rdr); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,52 @@
+package containerlogs
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+type mockDockerClient struct {
+	client.ContainerAPIClient
+
+	stdout, stderr string
+	options        types.ContainerLogsOptions
+}
+
+func (c *mockDockerClient) ContainerLogs(ctx context.Context, container string, options types.ContainerLogsOptions) (io.ReadCloser, error) {
+	c.options = options
+
+	// multiplex the logs the same as the docker api
+	buf := &bytes.Buffer{}
+	if _, err := stdcopy.NewStdWriter(buf, stdcopy.Stdout).Write([]byte(c.stdout)); err != nil {
+		return nil, err
+	}
+	if _, err := stdcopy.NewStdWriter(buf, stdcopy.Stderr).Write([]byte(c.stderr)); err != nil {
+		return nil, err
+	}
+
+	return ioutil.NopCloser(buf), nil
+}
+
+func TestTail(t *testing.T) {
+	mock := &mockDockerClient{stdout: "starting\n", stderr: "failed\n"}
+
+	got, err := Tail(context.Background(), mock, "container-id", "10")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "starting\nfailed\n"; string(got) != want {
+		t.Errorf("Tail() = %q, want %q", got, want)
+	}
+
+	if mock.options.Tail != "10" || !mock.options.ShowStdout || !mock.options.ShowStderr {
+		t.Errorf("expected the last 10 lines of stdout and stderr, got %+v", mock.options)
+	}
+}
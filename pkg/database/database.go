@@ -32,10 +32,60 @@ func DetermineEngine(file string) (string, error) {
 	}
 	defer f.Close()
 
+	return determineEngine(f)
+}
+
+// DetermineCompressedEngine is like DetermineEngine for compressed backups, the kind is
+// the type from filetype.Determine (e.g. zip or tar). Only the start of the backup is
+// decompressed, so large backups are not extracted to check the engine.
+func DetermineCompressedEngine(file, kind string) (string, error) {
+	switch kind {
+	case "zip":
+		r, err := zip.OpenReader(file)
+		if err != nil {
+			return "", err
+		}
+		defer r.Close()
+
+		for _, f := range r.File {
+			if !strings.HasSuffix(f.Name, ".sql") {
+				continue
+			}
+
+			rc, err := f.Open()
+			if err != nil {
+				return "", err
+			}
+			defer rc.Close()
+
+			return determineEngine(rc)
+		}
+
+		return "", fmt.Errorf("unable to find a .sql file in the zip")
+	case "tar":
+		f, err := os.Open(file)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+
+		r, err := gzip.NewReader(f)
+		if err != nil {
+			return "", err
+		}
+		defer r.Close()
+
+		return determineEngine(r)
+	}
+
+	return DetermineEngine(file)
+}
+
+func determineEngine(r io.Reader) (string, error) {
 	engine := ""
 	line := 1
 
-	s := bufio.NewScanner(f)
+	s := bufio.NewScanner(r)
 	for s.Scan() {
 		txt := s.Text()
 
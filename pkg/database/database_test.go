@@ -53,6 +53,56 @@ func TestDetermineEngine(t *testing.T) {
 	}
 }
 
+func TestDetermineCompressedEngine(t *testing.T) {
+	type args struct {
+		file string
+		kind string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "can detect gzipped mysql database backup files",
+			args:    args{file: "./testdata/mysql-backup.sql.gz", kind: "tar"},
+			want:    "mysql",
+			wantErr: false,
+		},
+		{
+			name:    "can detect zipped postgres database backup files",
+			args:    args{file: "./testdata/postgres-backup.zip", kind: "zip"},
+			want:    "postgres",
+			wantErr: false,
+		},
+		{
+			name:    "uncompressed files use the file",
+			args:    args{file: "./testdata/postgres-backup.sql", kind: "text"},
+			want:    "postgres",
+			wantErr: false,
+		},
+		{
+			name:    "invalid compressed files return an error",
+			args:    args{file: "./testdata/random.txt", kind: "tar"},
+			want:    "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DetermineCompressedEngine(tt.args.file, tt.args.kind)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DetermineCompressedEngine() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("DetermineCompressedEngine() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestHasCreateStatement(t *testing.T) {
 	type args struct {
 		file string
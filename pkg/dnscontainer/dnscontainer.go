@@ -0,0 +1,138 @@
+package dnscontainer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/terminal"
+)
+
+var (
+	// Image is the dnsmasq image used for the DNS container
+	Image = "docker.io/4km3/dnsmasq:2.85-r2"
+
+	// Name is the name of the DNS container (e.g. nitro-dns)
+	Name = "nitro-dns"
+)
+
+// Settings returns the value for the DNS label, which is used to check if the
+// container needs to be recreated when the top level domain or port changes.
+func Settings(tld, port string) string {
+	return tld + ":" + port
+}
+
+// Command returns the arguments for dnsmasq to resolve the top level domain, and
+// all of its subdomains, to localhost.
+func Command(tld string) []string {
+	return []string{
+		"dnsmasq",
+		"--keep-in-foreground",
+		"--no-resolv",
+		"--no-hosts",
+		"--log-facility=-",
+		fmt.Sprintf("--address=/%s/127.0.0.1", tld),
+	}
+}
+
+// StartOrCreate makes sure the DNS container for the top level domain is running and bound to
+// the port on the host. Containers created for another domain or port are replaced.
+func StartOrCreate(ctx context.Context, docker client.CommonAPIClient, output terminal.Outputer, tld, port string) error {
+	filter := filters.NewArgs()
+	filter.Add("label", containerlabels.DNS)
+
+	containers, err := docker.ContainerList(ctx, types.ContainerListOptions{Filters: filter, All: true})
+	if err != nil {
+		return fmt.Errorf("unable to list the containers, %w", err)
+	}
+
+	for _, c := range containers {
+		if c.Labels[containerlabels.DNS] == Settings(tld, port) {
+			if c.State != "running" {
+				if err := docker.ContainerStart(ctx, c.ID, types.ContainerStartOptions{}); err != nil {
+					return fmt.Errorf("unable to start the dns container, %w", err)
+				}
+			}
+
+			return nil
+		}
+
+		// the domain or port changed
+		if err := docker.ContainerRemove(ctx, c.ID, types.ContainerRemoveOptions{Force: true}); err != nil {
+			return fmt.Errorf("unable to remove the dns container, %w", err)
+		}
+	}
+
+	imageFilter := filters.NewArgs()
+	imageFilter.Add("reference", Image)
+
+	images, err := docker.ImageList(ctx, types.ImageListOptions{Filters: imageFilter})
+	if err != nil {
+		return fmt.Errorf("unable to get a list of images, %w", err)
+	}
+
+	// if we don't have the image, pull it
+	if len(images) == 0 {
+		output.Pending("pulling", Image)
+
+		rdr, err := docker.ImagePull(ctx, Image, types.ImagePullOptions{All: false})
+		if err != nil {
+			return fmt.Errorf("unable to pull docker image, %w", err)
+		}
+
+		buf := &bytes.Buffer{}
+		if _, err := buf.ReadFrom(rdr); err != nil {
+			return fmt.Errorf("unable to read the output from pulling the image, %w", err)
+		}
+
+		output.Done()
+	}
+
+	udp, err := nat.NewPort("udp", "53")
+	if err != nil {
+		return fmt.Errorf("unable to set the udp port, %w", err)
+	}
+
+	tcp, err := nat.NewPort("tcp", "53")
+	if err != nil {
+		return fmt.Errorf("unable to set the tcp port, %w", err)
+	}
+
+	resp, err := docker.ContainerCreate(ctx,
+		&container.Config{
+			Image:        Image,
+			Entrypoint:   Command(tld),
+			ExposedPorts: nat.PortSet{udp: struct{}{}, tcp: struct{}{}},
+			Labels: containerlabels.StampRunID(ctx, map[string]string{
+				containerlabels.Nitro: "true",
+				containerlabels.Type:  "dns",
+				containerlabels.DNS:   Settings(tld, port),
+			}),
+		},
+		&container.HostConfig{
+			PortBindings: map[nat.Port][]nat.PortBinding{
+				udp: {{HostIP: "127.0.0.1", HostPort: port}},
+				tcp: {{HostIP: "127.0.0.1", HostPort: port}},
+			},
+		},
+		nil,
+		nil,
+		Name,
+	)
+	if err != nil {
+		return fmt.Errorf("unable to create the dns container, %w", err)
+	}
+
+	if err := docker.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
+		return fmt.Errorf("unable to start the dns container, %w", err)
+	}
+
+	return nil
+}
@@ -14,7 +14,20 @@ import (
 // Getter is an interface for getting the contents of a url
 // and a directory to unzip the contents into a directory.
 type Getter interface {
-	Get(url, dir string) error
+	Get(url, dir string, opts ...Option) error
+}
+
+// Option is used to change the request for a download, such as adding
+// credentials for a private repository.
+type Option func(req *http.Request)
+
+// WithToken returns an option that uses the token to authenticate with
+// the GitHub API, which is required to download private repositories.
+func WithToken(token string) Option {
+	return func(req *http.Request) {
+		req.Header.Set("Authorization", "token "+token)
+		req.Header.Set("Accept", "application/vnd.github.v3+json")
+	}
 }
 
 // Downloader wraps the HTTP client to make get requests to a url
@@ -33,9 +46,18 @@ func NewDownloader() *Downloader {
 
 // Get takes a url and a directory where the contents should be
 // unzipped into.
-func (d *Downloader) Get(url, dir string) error {
+func (d *Downloader) Get(url, dir string, opts ...Option) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, opt := range opts {
+		opt(req)
+	}
+
 	// download the zip
-	resp, err := d.client.Get(url)
+	resp, err := d.client.Do(req)
 	if err != nil {
 		return err
 	}
@@ -77,12 +99,13 @@ func unzip(file *os.File, dir string) error {
 
 	for _, f := range r.File {
 		// github archives has a nested folder, so we need to trim the first directory
-		p := strings.Split(f.Name, string(os.PathSeparator))
-		fpath := filepath.Join(dir, strings.Join(p[1:], string(os.PathSeparator)))
+		p := strings.Split(f.Name, "/")
+		fpath := filepath.Join(dir, filepath.FromSlash(strings.Join(p[1:], "/")))
 
-		// if !strings.HasPrefix(fpath, filepath.Clean(dir)+string(os.PathSeparator)) {
-		// 	return fmt.Errorf("%s: illegal file path", fpath)
-		// }
+		// make sure the file is in the directory
+		if fpath != filepath.Clean(dir) && !strings.HasPrefix(fpath, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf("%s: illegal file path", fpath)
+		}
 
 		if f.FileInfo().IsDir() {
 			if err := os.MkdirAll(fpath, os.ModePerm); err != nil {
@@ -118,3 +141,51 @@ func unzip(file *os.File, dir string) error {
 
 	return nil
 }
+
+// Copy copies the files in the src directory into the directory, which is
+// used to create projects from a local boilerplate. The .git directory is
+// not copied.
+func Copy(src, dir string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() && info.Name() == ".git" {
+			return filepath.SkipDir
+		}
+
+		target := filepath.Join(dir, rel)
+
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, os.ModePerm)
+		case !info.Mode().IsRegular():
+			// symlinks and other special files are not copied
+			return nil
+		}
+
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+
+		out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode())
+		if err != nil {
+			return err
+		}
+
+		if _, err := io.Copy(out, in); err != nil {
+			out.Close()
+			return fmt.Errorf("unable to copy %s, %w", rel, err)
+		}
+
+		return out.Close()
+	})
+}
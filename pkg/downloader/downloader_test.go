@@ -0,0 +1,114 @@
+package downloader
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDownloader_Get(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		opts     []Option
+		wantAuth string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:  "the nested folder is removed",
+			files: map[string]string{"craft-HEAD/composer.json": "{}", "craft-HEAD/web/index.php": "<?php"},
+			want:  []string{"composer.json", "web/index.php"},
+		},
+		{
+			name:     "the token is sent with the request",
+			files:    map[string]string{"agency-starter-abc123/composer.json": "{}"},
+			opts:     []Option{WithToken("secret")},
+			wantAuth: "token secret",
+			want:     []string{"composer.json"},
+		},
+		{
+			name:    "files outside of the directory return an error",
+			files:   map[string]string{"craft-HEAD/../../evil.php": "<?php"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var auth string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				auth = r.Header.Get("Authorization")
+
+				zw := zip.NewWriter(w)
+				for name, content := range tt.files {
+					f, err := zw.Create(name)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					if _, err := f.Write([]byte(content)); err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				if err := zw.Close(); err != nil {
+					t.Fatal(err)
+				}
+			}))
+			defer srv.Close()
+
+			dir := filepath.Join(t.TempDir(), "project")
+
+			err := NewDownloader().Get(srv.URL, dir, tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if auth != tt.wantAuth {
+				t.Errorf("Get() authorization = %q, want %q", auth, tt.wantAuth)
+			}
+
+			for _, f := range tt.want {
+				if _, err := os.Stat(filepath.Join(dir, f)); err != nil {
+					t.Errorf("expected the file %s to exist, %v", f, err)
+				}
+			}
+		})
+	}
+}
+
+func TestCopy(t *testing.T) {
+	src := t.TempDir()
+	for _, f := range []string{"composer.json", "config/general.php", ".git/HEAD"} {
+		file := filepath.Join(src, f)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := ioutil.WriteFile(file, []byte(f), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dir := filepath.Join(t.TempDir(), "project")
+	if err := Copy(src, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "config", "general.php"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(b) != "config/general.php" {
+		t.Errorf("expected the file to be copied, got %q", b)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, ".git")); !os.IsNotExist(err) {
+		t.Errorf("expected the .git directory to not be copied, got %v", err)
+	}
+}
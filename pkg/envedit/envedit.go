@@ -71,3 +71,61 @@ func EnvExists(file, key string) bool {
 
 	return false
 }
+
+// Get returns the value of the environment variable in the file, surrounding quotes are removed.
+// If the file or variable does not exist, it returns an empty string.
+func Get(file, key string) string {
+	f, err := ioutil.ReadFile(file)
+	if err != nil {
+		return ""
+	}
+
+	for _, txt := range strings.Split(string(f), "\n") {
+		sp := strings.SplitN(strings.TrimSpace(txt), "=", 2)
+		if len(sp) == 2 && sp[0] == key {
+			return strings.Trim(sp[1], `"'`)
+		}
+	}
+
+	return ""
+}
+
+// Set updates the environment variables in the file and appends the variables that are not
+// defined, in the order of the keys. If the file does not exist, it is created.
+func Set(file string, keys []string, updates map[string]string) error {
+	var lines []string
+
+	f, err := ioutil.ReadFile(file)
+	switch {
+	case err == nil:
+		lines = strings.Split(strings.TrimRight(string(f), "\n"), "\n")
+	case !os.IsNotExist(err):
+		return err
+	}
+
+	set := map[string]bool{}
+	for line, txt := range lines {
+		sp := strings.SplitN(txt, "=", 2)
+		if v, ok := updates[sp[0]]; ok && len(sp) == 2 {
+			lines[line] = sp[0] + "=" + quote(v)
+			set[sp[0]] = true
+		}
+	}
+
+	for _, k := range keys {
+		if v, ok := updates[k]; ok && !set[k] {
+			lines = append(lines, k+"="+quote(v))
+		}
+	}
+
+	return ioutil.WriteFile(file, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
+// quote adds quotes to values with spaces or comments.
+func quote(v string) string {
+	if strings.ContainsAny(v, " #") {
+		return `"` + v + `"`
+	}
+
+	return v
+}
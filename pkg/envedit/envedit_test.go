@@ -1,6 +1,8 @@
 package envedit
 
 import (
+	"io/ioutil"
+	"path/filepath"
 	"testing"
 )
 
@@ -118,3 +120,70 @@ func TestEnvExists(t *testing.T) {
 		})
 	}
 }
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		keys     []string
+		updates  map[string]string
+		want     string
+	}{
+		{
+			name:     "existing variables are updated in place",
+			existing: "# the server\nDB_SERVER=127.0.0.1\nDB_USER=root\nDB_PASSWORD=\n",
+			keys:     []string{"DB_SERVER", "DB_PASSWORD"},
+			updates:  map[string]string{"DB_SERVER": "mysql-8.0-3306.database.nitro", "DB_PASSWORD": "nitro"},
+			want:     "# the server\nDB_SERVER=mysql-8.0-3306.database.nitro\nDB_USER=root\nDB_PASSWORD=nitro\n",
+		},
+		{
+			name:     "missing variables are appended in order",
+			existing: "ENVIRONMENT=dev",
+			keys:     []string{"DB_SERVER", "DB_DATABASE"},
+			updates:  map[string]string{"DB_DATABASE": "craft", "DB_SERVER": "postgres"},
+			want:     "ENVIRONMENT=dev\nDB_SERVER=postgres\nDB_DATABASE=craft\n",
+		},
+		{
+			name:    "missing files are created",
+			keys:    []string{"DEFAULT_SITE_URL"},
+			updates: map[string]string{"DEFAULT_SITE_URL": "https://site.nitro"},
+			want:    "DEFAULT_SITE_URL=https://site.nitro\n",
+		},
+		{
+			name:     "values with spaces are quoted",
+			existing: "SITE_NAME=",
+			keys:     []string{"SITE_NAME"},
+			updates:  map[string]string{"SITE_NAME": "My Site"},
+			want:     "SITE_NAME=\"My Site\"\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), ".env")
+			if tt.existing != "" {
+				if err := ioutil.WriteFile(file, []byte(tt.existing), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := Set(file, tt.keys, tt.updates); err != nil {
+				t.Fatal(err)
+			}
+
+			b, err := ioutil.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(b) != tt.want {
+				t.Errorf("Set() = %q, want %q", b, tt.want)
+			}
+
+			for k, v := range tt.updates {
+				if got := Get(file, k); got != v {
+					t.Errorf("Get(%s) = %q, want %q", k, got, v)
+				}
+			}
+		})
+	}
+}
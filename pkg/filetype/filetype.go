@@ -2,7 +2,7 @@ package filetype
 
 import (
 	"fmt"
-	"io/ioutil"
+	"io"
 	"net/http"
 	"os"
 )
@@ -23,14 +23,22 @@ func Determine(file string) (string, error) {
 		return "", fmt.Errorf("file provided is a directory")
 	}
 
-	// read the contents
-	data, err := ioutil.ReadFile(file)
+	f, err := os.Open(file)
 	if err != nil {
 		return "", err
 	}
+	defer f.Close()
+
+	// only the start of the file is needed to detect the type, so large
+	// backups are not read into memory
+	data := make([]byte, 512)
+	n, err := io.ReadFull(f, data)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
 
 	// detect the type
-	kind := http.DetectContentType(data)
+	kind := http.DetectContentType(data[:n])
 
 	switch kind {
 	case "text/plain; charset=utf-8":
@@ -0,0 +1,22 @@
+package helpers
+
+import "testing"
+
+func TestByteSize(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{n: 512, want: "512 B"},
+		{n: 1536, want: "1.5 KB"},
+		{n: 5 * 1024 * 1024, want: "5.0 MB"},
+		{n: 3 * 1024 * 1024 * 1024, want: "3.0 GB"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := ByteSize(tt.n); got != tt.want {
+				t.Errorf("ByteSize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+// Package mdns is a minimal multicast DNS responder that answers queries for the site
+// hostnames with the address of the host, so devices on the local network can
+// resolve the sites without editing their hosts files.
+package mdns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	// Port is the port mDNS queries and responses are sent to.
+	Port = 5353
+
+	// TTL is the number of seconds devices cache the address for a hostname.
+	TTL = 120
+)
+
+var (
+	// Group is the IPv4 multicast address for mDNS.
+	Group = net.IPv4(224, 0, 0, 251)
+
+	// ErrNoAddress is returned when the address on the local network can not be determined.
+	ErrNoAddress = errors.New("unable to find an address on the local network")
+)
+
+// Records maps the hostnames to the address returned for them.
+type Records map[string]net.IP
+
+// NewRecords returns records for the hostnames that all resolve to the ip.
+func NewRecords(ip net.IP, hostnames ...string) Records {
+	records := Records{}
+	for _, h := range hostnames {
+		records[canonical(h)] = ip
+	}
+
+	return records
+}
+
+// Answer parses the query and returns the response for the questions that have a
+// record. If none of the questions have a record, the response is nil.
+func (r Records) Answer(query []byte, unicast bool) ([]byte, error) {
+	var msg dnsmessage.Message
+	if err := msg.Unpack(query); err != nil {
+		return nil, fmt.Errorf("unable to parse the query, %w", err)
+	}
+
+	// ignore responses from other responders
+	if msg.Header.Response {
+		return nil, nil
+	}
+
+	var answers []dnsmessage.Resource
+	for _, q := range msg.Questions {
+		if q.Type != dnsmessage.TypeA && q.Type != dnsmessage.TypeALL {
+			continue
+		}
+
+		ip, ok := r[canonical(q.Name.String())]
+		if !ok {
+			continue
+		}
+
+		answer, err := resource(q.Name.String(), ip, !unicast)
+		if err != nil {
+			return nil, err
+		}
+
+		answers = append(answers, answer)
+	}
+
+	if len(answers) == 0 {
+		return nil, nil
+	}
+
+	resp := dnsmessage.Message{
+		Header:  dnsmessage.Header{Response: true, Authoritative: true},
+		Answers: answers,
+	}
+
+	// legacy unicast queries expect the id and questions in the response
+	if unicast {
+		resp.Header.ID = msg.Header.ID
+		resp.Questions = msg.Questions
+	}
+
+	return resp.Pack()
+}
+
+// Announcement returns an unsolicited response with all of the records, which is
+// sent when the responder starts so devices update their caches.
+func (r Records) Announcement() ([]byte, error) {
+	msg := dnsmessage.Message{Header: dnsmessage.Header{Response: true, Authoritative: true}}
+
+	for name, ip := range r {
+		answer, err := resource(name, ip, true)
+		if err != nil {
+			return nil, err
+		}
+
+		msg.Answers = append(msg.Answers, answer)
+	}
+
+	return msg.Pack()
+}
+
+// Serve listens for mDNS queries and answers them with the records until the context is canceled.
+func Serve(ctx context.Context, records Records) error {
+	group := &net.UDPAddr{IP: Group, Port: Port}
+
+	conn, err := net.ListenMulticastUDP("udp4", nil, group)
+	if err != nil {
+		return fmt.Errorf("unable to listen for mdns queries, %w", err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	announcement, err := records.Announcement()
+	if err != nil {
+		return err
+	}
+
+	if _, err := conn.WriteToUDP(announcement, group); err != nil {
+		return fmt.Errorf("unable to announce the hostnames, %w", err)
+	}
+
+	buf := make([]byte, 9000)
+	for {
+		n, from, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return fmt.Errorf("unable to read the mdns query, %w", err)
+		}
+
+		// queries that are not sent from the mdns port expect a unicast response
+		unicast := from.Port != Port
+
+		resp, err := records.Answer(buf[:n], unicast)
+		if err != nil || resp == nil {
+			continue
+		}
+
+		to := group
+		if unicast {
+			to = from
+		}
+
+		if _, err := conn.WriteToUDP(resp, to); err != nil && ctx.Err() == nil {
+			return fmt.Errorf("unable to send the mdns response, %w", err)
+		}
+	}
+}
+
+// LocalIP returns the address of the host on the local network. No packets are sent to
+// determine the address.
+func LocalIP() (net.IP, error) {
+	conn, err := net.Dial("udp4", (&net.UDPAddr{IP: Group, Port: Port}).String())
+	if err != nil {
+		return nil, fmt.Errorf("%w, %s", ErrNoAddress, err)
+	}
+	defer conn.Close()
+
+	addr, ok := conn.LocalAddr().(*net.UDPAddr)
+	if !ok || addr.IP.IsLoopback() || addr.IP.IsUnspecified() {
+		return nil, ErrNoAddress
+	}
+
+	return addr.IP, nil
+}
+
+func resource(name string, ip net.IP, flush bool) (dnsmessage.Resource, error) {
+	n, err := dnsmessage.NewName(canonical(name) + ".")
+	if err != nil {
+		return dnsmessage.Resource{}, fmt.Errorf("unable to use %s as a hostname, %w", name, err)
+	}
+
+	var a [4]byte
+	copy(a[:], ip.To4())
+
+	// the top bit of the class tells devices to flush their cache for the name
+	class := dnsmessage.ClassINET
+	if flush {
+		class |= 1 << 15
+	}
+
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{
+			Name:  n,
+			Type:  dnsmessage.TypeA,
+			Class: class,
+			TTL:   TTL,
+		},
+		Body: &dnsmessage.AResource{A: a},
+	}, nil
+}
+
+func canonical(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
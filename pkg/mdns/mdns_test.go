@@ -0,0 +1,109 @@
+package mdns
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func query(t *testing.T, id uint16, qtype dnsmessage.Type, names ...string) []byte {
+	t.Helper()
+
+	msg := dnsmessage.Message{Header: dnsmessage.Header{ID: id}}
+	for _, n := range names {
+		msg.Questions = append(msg.Questions, dnsmessage.Question{
+			Name:  dnsmessage.MustNewName(n),
+			Type:  qtype,
+			Class: dnsmessage.ClassINET,
+		})
+	}
+
+	b, err := msg.Pack()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return b
+}
+
+func TestRecords_Answer(t *testing.T) {
+	records := NewRecords(net.IPv4(192, 168, 1, 20), "tutorial.nitro", "Tutorial.local")
+
+	tests := []struct {
+		name    string
+		query   []byte
+		unicast bool
+		want    []string
+		wantID  uint16
+	}{
+		{
+			name:  "answers the questions for known hostnames",
+			query: query(t, 0, dnsmessage.TypeA, "tutorial.nitro.", "tutorial.local.", "unknown.nitro."),
+			want:  []string{"tutorial.nitro.", "tutorial.local."},
+		},
+		{
+			name:  "hostnames are case insensitive",
+			query: query(t, 0, dnsmessage.TypeA, "TUTORIAL.nitro."),
+			want:  []string{"tutorial.nitro."},
+		},
+		{
+			name:  "unknown hostnames are not answered",
+			query: query(t, 0, dnsmessage.TypeA, "unknown.nitro."),
+		},
+		{
+			name:  "only address questions are answered",
+			query: query(t, 0, dnsmessage.TypeAAAA, "tutorial.nitro."),
+		},
+		{
+			name:    "unicast queries keep the id",
+			query:   query(t, 42, dnsmessage.TypeA, "tutorial.nitro."),
+			unicast: true,
+			want:    []string{"tutorial.nitro."},
+			wantID:  42,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := records.Answer(tt.query, tt.unicast)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.want == nil {
+				if b != nil {
+					t.Fatalf("expected no response, got %v", b)
+				}
+
+				return
+			}
+
+			var resp dnsmessage.Message
+			if err := resp.Unpack(b); err != nil {
+				t.Fatal(err)
+			}
+
+			if !resp.Header.Response || !resp.Header.Authoritative {
+				t.Errorf("expected an authoritative response, got %v", resp.Header)
+			}
+
+			if resp.Header.ID != tt.wantID {
+				t.Errorf("expected the id to be %d, got %d", tt.wantID, resp.Header.ID)
+			}
+
+			var got []string
+			for _, a := range resp.Answers {
+				got = append(got, a.Header.Name.String())
+
+				if ip := a.Body.(*dnsmessage.AResource).A; ip != [4]byte{192, 168, 1, 20} {
+					t.Errorf("expected the address to be 192.168.1.20, got %v", ip)
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected the answers to be %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package platform
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Parse takes a platform string (e.g. linux/amd64 or linux/arm64/v8) and returns the platform
+// to use when creating containers. An empty string returns nil so the host platform is used.
+func Parse(s string) (*v1.Platform, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(s, "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("invalid platform %q, expected os/arch (e.g. linux/amd64)", s)
+	}
+
+	p := &v1.Platform{
+		OS:           parts[0],
+		Architecture: parts[1],
+	}
+
+	if len(parts) == 3 {
+		p.Variant = parts[2]
+	}
+
+	return p, nil
+}
+
+// IsEmulated returns true when the platform architecture does not match the host, which
+// means the container will run using emulation.
+func IsEmulated(p *v1.Platform) bool {
+	return p != nil && p.Architecture != runtime.GOARCH
+}
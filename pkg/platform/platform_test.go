@@ -0,0 +1,95 @@
+package platform
+
+import (
+	"reflect"
+	"runtime"
+	"testing"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestParse(t *testing.T) {
+	type args struct {
+		s string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *v1.Platform
+		wantErr bool
+	}{
+		{
+			name:    "empty platforms use the host",
+			args:    args{s: ""},
+			want:    nil,
+			wantErr: false,
+		},
+		{
+			name:    "os and architecture are parsed",
+			args:    args{s: "linux/amd64"},
+			want:    &v1.Platform{OS: "linux", Architecture: "amd64"},
+			wantErr: false,
+		},
+		{
+			name:    "variants are parsed",
+			args:    args{s: "linux/arm64/v8"},
+			want:    &v1.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"},
+			wantErr: false,
+		},
+		{
+			name:    "missing architectures return an error",
+			args:    args{s: "linux"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "too many parts return an error",
+			args:    args{s: "linux/arm64/v8/extra"},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.args.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsEmulated(t *testing.T) {
+	tests := []struct {
+		name string
+		p    *v1.Platform
+		want bool
+	}{
+		{
+			name: "host platforms are not emulated",
+			p:    nil,
+			want: false,
+		},
+		{
+			name: "matching architectures are not emulated",
+			p:    &v1.Platform{OS: "linux", Architecture: runtime.GOARCH},
+			want: false,
+		},
+		{
+			name: "other architectures are emulated",
+			p:    &v1.Platform{OS: "linux", Architecture: "s390x"},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsEmulated(tt.p); got != tt.want {
+				t.Errorf("IsEmulated() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
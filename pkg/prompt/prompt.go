@@ -210,15 +210,25 @@ func CreateSite(home, dir string, output terminal.Outputer) (*config.Site, error
 	sp := strings.Split(filepath.Join(dir), string(os.PathSeparator))
 	site.Hostname = sp[len(sp)-1]
 
-	// append the test domain if there are no periods
+	// load the config
+	cfg, err := config.Load(home)
+	if err != nil {
+		return nil, err
+	}
+
+	// append the configs domain if there are no periods
 	if !strings.Contains(site.Hostname, ".") {
-		// set the default tld
-		tld := "nitro"
-		if os.Getenv("NITRO_DEFAULT_TLD") != "" {
-			tld = os.Getenv("NITRO_DEFAULT_TLD")
-		}
+		site.Hostname = fmt.Sprintf("%s.%s", site.Hostname, cfg.GetTLD())
+	}
+
+	// use the project file settings as the defaults
+	project, err := config.LoadProject(dir)
+	if err != nil {
+		return nil, err
+	}
 
-		site.Hostname = fmt.Sprintf("%s.%s", site.Hostname, tld)
+	if project != nil && project.Hostname != "" {
+		site.Hostname = project.Hostname
 	}
 
 	// prompt for the hostname
@@ -252,6 +262,10 @@ func CreateSite(home, dir string, output terminal.Outputer) (*config.Site, error
 	// set the web root
 	site.Webroot = found
 
+	if project != nil && project.Webroot != "" {
+		site.Webroot = project.Webroot
+	}
+
 	// prompt for the web root
 	root, err := output.Ask("Enter the web root for the site", site.Webroot, ":", nil)
 	if err != nil {
@@ -262,22 +276,27 @@ func CreateSite(home, dir string, output terminal.Outputer) (*config.Site, error
 
 	output.Success("using web root", site.Webroot)
 
-	// prompt for the php version
-	versions := phpversions.Versions
-	selected, err := output.Select(os.Stdin, "Choose a PHP version: ", versions)
-	if err != nil {
-		return nil, err
-	}
+	if project != nil && project.Version != "" {
+		// the project file sets the version of php
+		site.Version = project.Version
+	} else {
+		// prompt for the php version
+		versions := phpversions.Versions
+		selected, err := output.Select(os.Stdin, "Choose a PHP version: ", versions)
+		if err != nil {
+			return nil, err
+		}
 
-	// set the version of php
-	site.Version = versions[selected]
+		// set the version of php
+		site.Version = versions[selected]
+	}
 
 	output.Success("setting PHP version", site.Version)
 
-	// load the config
-	cfg, err := config.Load(home)
-	if err != nil {
-		return nil, err
+	// add the rest of the project settings
+	if project != nil {
+		project.Webroot, project.Version = site.Webroot, site.Version
+		site = site.Merge(*project)
 	}
 
 	// add the site to the config
@@ -293,6 +312,43 @@ func CreateSite(home, dir string, output terminal.Outputer) (*config.Site, error
 	return &site, nil
 }
 
+// ErrNoSites is returned when selecting a site and the config does not have any sites
+var ErrNoSites = errors.New("there are no sites in the config…\n run `nitro add` to add a site")
+
+// SelectSite returns the site from the argument, or the site for the current directory. When
+// there is more than one site, the user is prompted to select a site.
+func SelectSite(cmd *cobra.Command, home string, cfg *config.Config, args []string, output terminal.Outputer) (*config.Site, error) {
+	if len(args) > 0 {
+		return cfg.FindSiteByHostName(strings.TrimSpace(args[0]))
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	// get a context aware list of sites
+	sites := cfg.ListOfSitesByDirectory(home, wd)
+	switch len(sites) {
+	case 0:
+		return nil, ErrNoSites
+	case 1:
+		return &sites[0], nil
+	}
+
+	var options []string
+	for _, s := range sites {
+		options = append(options, s.Hostname)
+	}
+
+	selected, err := output.Select(cmd.InOrStdin(), "Select a site: ", options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sites[selected], nil
+}
+
 // RunApply will prompt a user to run the apply command. It optionally accepts a "force"
 // option that will not prompt the user and run apply regardless.
 func RunApply(cmd *cobra.Command, args []string, force bool, output terminal.Outputer) error {
@@ -326,6 +382,22 @@ func RunApply(cmd *cobra.Command, args []string, force bool, output terminal.Out
 	return nil
 }
 
+// RunApplySite will prompt a user to run the apply command for a single site, so only the
+// sites container is changed. If the hostname is empty, the apply command runs for all sites.
+func RunApplySite(cmd *cobra.Command, hostname string, force bool, output terminal.Outputer) error {
+	if hostname != "" {
+		for _, c := range cmd.Root().Commands() {
+			if c.Use == "apply" {
+				if err := c.Flags().Set("site", hostname); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return RunApply(cmd, nil, force, output)
+}
+
 // VerifyInit is used to verify the init command has been run by checking if a config file exists.
 func VerifyInit(cmd *cobra.Command, args []string, home string, output terminal.Outputer) error {
 	// verify the config exists
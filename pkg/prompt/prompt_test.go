@@ -0,0 +1,97 @@
+package prompt
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/craftcms/nitro/pkg/config"
+)
+
+func TestRunApplySite(t *testing.T) {
+	tests := []struct {
+		name     string
+		hostname string
+		want     string
+	}{
+		{
+			name:     "only the site is applied",
+			hostname: "tutorial.nitro",
+			want:     "tutorial.nitro",
+		},
+		{
+			name: "all sites are applied without a hostname",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+
+			apply := &cobra.Command{
+				Use: "apply",
+				RunE: func(cmd *cobra.Command, args []string) error {
+					got = cmd.Flag("site").Value.String()
+					return nil
+				},
+				PostRunE: func(cmd *cobra.Command, args []string) error {
+					return nil
+				},
+			}
+			apply.Flags().String("site", "", "")
+
+			xon := &cobra.Command{Use: "xon"}
+
+			root := &cobra.Command{Use: "nitro"}
+			root.AddCommand(apply, xon)
+
+			if err := RunApplySite(xon, tt.hostname, true, nil); err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tt.want {
+				t.Errorf("expected the site flag to be %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSelectSite(t *testing.T) {
+	tests := []struct {
+		name    string
+		sites   []config.Site
+		args    []string
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "the site from the argument is returned",
+			sites: []config.Site{{Hostname: "tutorial.nitro", Path: "~/dev/tutorial"}, {Hostname: "demo.nitro", Path: "~/dev/demo"}},
+			args:  []string{"demo.nitro"},
+			want:  "demo.nitro",
+		},
+		{
+			name:  "a single site is returned without a prompt",
+			sites: []config.Site{{Hostname: "tutorial.nitro", Path: "~/dev/tutorial"}},
+			want:  "tutorial.nitro",
+		},
+		{
+			name:    "no sites returns an error",
+			wantErr: ErrNoSites,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Sites: tt.sites}
+
+			got, err := SelectSite(&cobra.Command{}, t.TempDir(), cfg, tt.args, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected the error to be %v, got %v", tt.wantErr, err)
+			}
+
+			if err == nil && got.Hostname != tt.want {
+				t.Errorf("expected the site to be %q, got %q", tt.want, got.Hostname)
+			}
+		})
+	}
+}
@@ -5,12 +5,17 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"runtime"
+	"strings"
 
 	volumetypes "github.com/docker/docker/api/types/volume"
 
 	"github.com/craftcms/nitro/command/version"
+	"github.com/craftcms/nitro/pkg/apitoken"
+	"github.com/craftcms/nitro/pkg/config"
 	"github.com/craftcms/nitro/pkg/containerlabels"
 	"github.com/craftcms/nitro/pkg/terminal"
+	"github.com/craftcms/nitro/pkg/wsl"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
@@ -31,8 +36,56 @@ var (
 	ErrNoProxyContainer = fmt.Errorf("unable to locate the proxy container")
 )
 
-// Create is used to create a new proxy container for the nitro development environment.
-func Create(ctx context.Context, docker client.CommonAPIClient, output terminal.Outputer, networkID string) error {
+// Ports are the ports on the host the proxy container binds to for HTTP, HTTPS, and the nitrod API.
+// The API port is empty when the API is not bound on the host.
+// When LAN is true, the HTTP, HTTPS, and node ports are bound on all interfaces instead of localhost.
+type Ports struct {
+	HTTP  string
+	HTTPS string
+	API   string
+	LAN   bool
+}
+
+// String returns the ports as a comma separated list, which is used to label the proxy container.
+func (p Ports) String() string {
+	s := strings.Join([]string{p.HTTP, p.HTTPS, p.API}, ",")
+	if p.LAN {
+		s += ",lan"
+	}
+
+	return s
+}
+
+// hostIP returns the address on the host to bind the HTTP, HTTPS, and node ports to.
+func (p Ports) hostIP() string {
+	if p.LAN {
+		return "0.0.0.0"
+	}
+
+	return "127.0.0.1"
+}
+
+// ConfigPorts returns the ports from the config, which uses the environment
+// variables or the defaults for ports the config does not set.
+func ConfigPorts(cfg *config.Config) Ports {
+	ports := Ports{
+		HTTP:  cfg.GetHTTPPort(),
+		HTTPS: cfg.GetHTTPSPort(),
+		API:   cfg.GetAPIPort(),
+		LAN:   cfg.Proxy.LAN,
+	}
+
+	// the api is reached through docker exec
+	if cfg.GetAPITransport() == config.APITransportExec {
+		ports.API = ""
+	}
+
+	return ports
+}
+
+// Create is used to create a new proxy container for the nitro development environment. When
+// the token is not empty, nitrod requires the token for requests to the API.
+func Create(ctx context.Context, docker client.CommonAPIClient, output terminal.Outputer, networkID string, ports Ports, token string) error {
 	if ctx == nil {
 		ctx = context.Background()
 	}
@@ -139,24 +192,6 @@ func Create(ctx context.Context, docker client.CommonAPIClient, output terminal.
 	// if we do not have a proxy, it needs to be create
 	output.Pending("creating proxy")
 
-	// check for a custom HTTP port
-	httpPort := "80"
-	if _, defined := os.LookupEnv("NITRO_HTTP_PORT"); defined {
-		httpPort = os.Getenv("NITRO_HTTP_PORT")
-	}
-
-	// check for a custom HTTPS port
-	httpsPort := "443"
-	if _, defined := os.LookupEnv("NITRO_HTTPS_PORT"); defined {
-		httpsPort = os.Getenv("NITRO_HTTPS_PORT")
-	}
-
-	// check for a custom API port
-	apiPort := "5000"
-	if _, defined := os.LookupEnv("NITRO_API_PORT"); defined {
-		apiPort = os.Getenv("NITRO_API_PORT")
-	}
-
 	// check the first node port
 	nodePort := "3000"
 	if _, defined := os.LookupEnv("NITRO_NODE_PORT"); defined {
@@ -194,6 +229,54 @@ func Create(ctx context.Context, docker client.CommonAPIClient, output terminal.
 		return fmt.Errorf("unable to set the second node port, %w", err)
 	}
 
+	// linux does not resolve the host machine, which host proxies are routed to
+	var extraHosts []string
+	if runtime.GOOS == "linux" && !wsl.IsWSL() {
+		extraHosts = append(extraHosts, fmt.Sprintf("%s:%s", "host.docker.internal", "host-gateway"))
+	}
+
+	bindings := map[nat.Port][]nat.PortBinding{
+		httpPortNat: {
+			{
+				HostIP:   ports.hostIP(),
+				HostPort: ports.HTTP,
+			},
+		},
+		httpsPortNat: {
+			{
+				HostIP:   ports.hostIP(),
+				HostPort: ports.HTTPS,
+			},
+		},
+		nodePortNat: {
+			{
+				HostIP:   ports.hostIP(),
+				HostPort: nodePort,
+			},
+		},
+		altNodePortNat: {
+			{
+				HostIP:   ports.hostIP(),
+				HostPort: altNodePort,
+			},
+		},
+	}
+
+	// the api is only bound on the host when it is not reached through docker exec
+	if ports.API != "" {
+		bindings[apiPortNat] = []nat.PortBinding{
+			{
+				HostIP:   "127.0.0.1",
+				HostPort: ports.API,
+			},
+		}
+	}
+
+	env := []string{"PGPASSWORD=nitro", "PGUSER=nitro", "NITRO_VERSION=" + version.Version}
+	if token != "" {
+		env = append(env, apitoken.EnvironmentVariable+"="+token)
+	}
+
 	// create a container
 	resp, err := docker.ContainerCreate(ctx,
 		&container.Config{
@@ -205,16 +288,19 @@ func Create(ctx context.Context, docker client.CommonAPIClient, output terminal.
 				nodePortNat:    struct{}{},
 				altNodePortNat: struct{}{},
 			},
-			Labels: map[string]string{
+			Labels: containerlabels.StampRunID(ctx, map[string]string{
 				containerlabels.Nitro:        "true",
 				containerlabels.Type:         "proxy",
 				containerlabels.Proxy:        "true",
 				containerlabels.ProxyVersion: version.Version,
-			},
-			Env: []string{"PGPASSWORD=nitro", "PGUSER=nitro", "NITRO_VERSION=" + version.Version},
+				containerlabels.ProxyPorts:   ports.String(),
+				containerlabels.ProxyToken:   apitoken.Hash(token),
+			}),
+			Env: env,
 		},
 		&container.HostConfig{
 			NetworkMode: "default",
+			ExtraHosts:  extraHosts,
 			Mounts: []mount.Mount{
 				{
 					Type:   mount.TypeVolume,
@@ -222,38 +308,7 @@ func Create(ctx context.Context, docker client.CommonAPIClient, output terminal.
 					Target: "/data",
 				},
 			},
-			PortBindings: map[nat.Port][]nat.PortBinding{
-				httpPortNat: {
-					{
-						HostIP:   "127.0.0.1",
-						HostPort: httpPort,
-					},
-				},
-				httpsPortNat: {
-					{
-						HostIP:   "127.0.0.1",
-						HostPort: httpsPort,
-					},
-				},
-				apiPortNat: {
-					{
-						HostIP:   "127.0.0.1",
-						HostPort: apiPort,
-					},
-				},
-				nodePortNat: {
-					{
-						HostIP:   "127.0.0.1",
-						HostPort: nodePort,
-					},
-				},
-				altNodePortNat: {
-					{
-						HostIP:   "127.0.0.1",
-						HostPort: altNodePort,
-					},
-				},
-			},
+			PortBindings: bindings,
 		},
 		&network.NetworkingConfig{
 			EndpointsConfig: map[string]*network.EndpointSettings{
@@ -278,6 +333,40 @@ func Create(ctx context.Context, docker client.CommonAPIClient, output terminal.
 	return nil
 }
 
+// IsCurrent checks an existing proxy container to verify it has the expected labels, is using the
+// image for the current version of the CLI, and binds to the ports. Containers that are not current
+// should be recreated.
+func IsCurrent(c types.Container, ports Ports) bool {
+	if c.Labels[containerlabels.Nitro] != "true" || c.Labels[containerlabels.Proxy] != "true" {
+		return false
+	}
+
+	if c.Labels[containerlabels.ProxyVersion] != version.Version {
+		return false
+	}
+
+	if !HasPorts(c, ports) {
+		return false
+	}
+
+	// the image is not always returned from the list, so only check when present
+	if c.Image != "" && c.Image != ProxyImage {
+		return false
+	}
+
+	return true
+}
+
+// HasPorts checks if an existing proxy container binds to the ports on the host.
+func HasPorts(c types.Container, ports Ports) bool {
+	return c.Labels[containerlabels.ProxyPorts] == ports.String()
+}
+
+// HasToken checks if an existing proxy container was created with the token for the nitrod API.
+func HasToken(c types.Container, token string) bool {
+	return c.Labels[containerlabels.ProxyToken] == apitoken.Hash(token)
+}
+
 // FindAndStart will look for the proxy container and verify the container is started. It will return the
 // ErrNoProxyContainer error if it is unable to locate the proxy container. It is NOT responsible for
 // creating the proxy container as that is handled in the initialize package.
@@ -1,6 +1,9 @@
 package releases
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -25,9 +28,13 @@ type Downloader interface {
 // Release represents the release asset
 type Release struct {
 	URL             string
+	Name            string
 	ContentType     string
 	OperatingSystem string
 	Version         string
+
+	// ChecksumURL is the URL to the checksums for the release assets
+	ChecksumURL string
 }
 
 type githubReleases struct {
@@ -135,21 +142,7 @@ func (r *githubReleaseFinder) Find(url, system, arch string) (*Release, error) {
 			return nil, err
 		}
 
-		version := found.TagName
-
-		// find the asset from the download
-		for _, asset := range found.Assets {
-			if strings.Contains(asset.Name, system) && strings.Contains(asset.Name, arch) {
-				return &Release{
-					URL:             asset.BrowserDownloadURL,
-					ContentType:     asset.ContentType,
-					OperatingSystem: system,
-					Version:         version,
-				}, nil
-			}
-		}
-
-		return nil, fmt.Errorf("unable to find the release")
+		return findAsset(found, system, arch)
 	}
 
 	releases := []githubReleases{}
@@ -157,23 +150,86 @@ func (r *githubReleaseFinder) Find(url, system, arch string) (*Release, error) {
 		return nil, err
 	}
 
-	release := releases[0]
+	if len(releases) == 0 {
+		return nil, fmt.Errorf("unable to find a release")
+	}
 
-	version := release.TagName
+	return findAsset(releases[0], system, arch)
+}
 
-	// find the asset from the download
+// findAsset returns the archive for the system and arch from the release. Other assets, such
+// as the linux packages, are skipped.
+func findAsset(release githubReleases, system, arch string) (*Release, error) {
+	var found *Release
+	var checksums string
 	for _, asset := range release.Assets {
-		if strings.Contains(asset.Name, system) && strings.Contains(asset.Name, arch) {
-			return &Release{
+		if strings.HasSuffix(asset.Name, "checksums.txt") {
+			checksums = asset.BrowserDownloadURL
+			continue
+		}
+
+		if !strings.HasSuffix(asset.Name, ".tar.gz") && !strings.HasSuffix(asset.Name, ".zip") {
+			continue
+		}
+
+		if found == nil && strings.Contains(asset.Name, system) && strings.Contains(asset.Name, arch) {
+			found = &Release{
 				URL:             asset.BrowserDownloadURL,
+				Name:            asset.Name,
 				ContentType:     asset.ContentType,
 				OperatingSystem: system,
-				Version:         version,
-			}, nil
+				Version:         release.TagName,
+			}
 		}
 	}
 
-	return nil, fmt.Errorf("unable to find a release")
+	if found == nil {
+		return nil, fmt.Errorf("unable to find a release for %s %s", system, arch)
+	}
+
+	found.ChecksumURL = checksums
+
+	return found, nil
+}
+
+// Checksum returns the SHA-256 checksum for the file name from the checksums file, which has
+// a line with the hex encoded checksum and the name for each asset.
+func Checksum(checksums []byte, name string) ([]byte, error) {
+	for _, line := range strings.Split(string(checksums), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || strings.TrimPrefix(fields[1], "*") != name {
+			continue
+		}
+
+		sum, err := hex.DecodeString(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode the checksum for %s, %w", name, err)
+		}
+
+		return sum, nil
+	}
+
+	return nil, fmt.Errorf("unable to find the checksum for %s", name)
+}
+
+// Verify returns an error if the SHA-256 checksum of the file does not match.
+func Verify(file string, checksum []byte) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+
+	if sum := h.Sum(nil); !bytes.Equal(sum, checksum) {
+		return fmt.Errorf("the checksum %x does not match the expected checksum %x", sum, checksum)
+	}
+
+	return nil
 }
 
 // NewFinder returns a new github release finder with the default HTTP client.
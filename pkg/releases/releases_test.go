@@ -0,0 +1,120 @@
+package releases
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_findAsset(t *testing.T) {
+	release := githubReleases{}
+	if err := json.Unmarshal([]byte(`{
+		"tag_name": "v2.0.0",
+		"assets": [
+			{"name": "nitro_2.0.0_checksums.txt", "browser_download_url": "https://example.com/checksums.txt", "content_type": "text/plain"},
+			{"name": "nitro_2.0.0_linux_x86_64.deb", "browser_download_url": "https://example.com/nitro.deb", "content_type": "application/octet-stream"},
+			{"name": "nitro_linux_x86_64.tar.gz", "browser_download_url": "https://example.com/nitro_linux_x86_64.tar.gz", "content_type": "application/gzip"},
+			{"name": "nitro_darwin_arm64.tar.gz", "browser_download_url": "https://example.com/nitro_darwin_arm64.tar.gz", "content_type": "application/gzip"}
+		]
+	}`), &release); err != nil {
+		t.Fatal(err)
+	}
+
+	type args struct {
+		system string
+		arch   string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *Release
+		wantErr bool
+	}{
+		{
+			name: "archives are found instead of packages",
+			args: args{system: "linux", arch: "x86_64"},
+			want: &Release{
+				URL:             "https://example.com/nitro_linux_x86_64.tar.gz",
+				Name:            "nitro_linux_x86_64.tar.gz",
+				ContentType:     "application/gzip",
+				OperatingSystem: "linux",
+				Version:         "v2.0.0",
+				ChecksumURL:     "https://example.com/checksums.txt",
+			},
+		},
+		{
+			name:    "missing systems return an error",
+			args:    args{system: "windows", arch: "x86_64"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := findAsset(release, tt.args.system, tt.args.arch)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("findAsset() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findAsset() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	checksums := []byte(`4b8ce4b1d1b6e8a0e0cf8f3e0d3b4f3b8d0e1f2a3b4c5d6e7f8091a2b3c4d5e6  nitro_darwin_arm64.tar.gz
+00112233445566778899aabbccddeeff00112233445566778899aabbccddeeff  nitro_linux_x86_64.tar.gz
+`)
+
+	tests := []struct {
+		name    string
+		file    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "checksums are found by the file name",
+			file: "nitro_linux_x86_64.tar.gz",
+			want: "00112233445566778899aabbccddeeff00112233445566778899aabbccddeeff",
+		},
+		{
+			name:    "missing files return an error",
+			file:    "nitro_windows_x86_64.zip",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Checksum(checksums, tt.file)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Checksum() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if fmt.Sprintf("%x", got) != tt.want {
+				t.Errorf("Checksum() = %x, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "nitro.tar.gz")
+	if err := ioutil.WriteFile(file, []byte("release"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sum := sha256.Sum256([]byte("release"))
+
+	if err := Verify(file, sum[:]); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+
+	if err := Verify(file, []byte("wrong")); err == nil {
+		t.Errorf("Verify() expected an error for the wrong checksum")
+	}
+}
@@ -0,0 +1,140 @@
+// Package remote dumps databases from remote servers over SSH so they
+// can be imported into the local database engines.
+package remote
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/craftcms/nitro/pkg/config"
+)
+
+// Engine returns the database engine for the remote, which defaults to mysql.
+func Engine(r config.Remote) string {
+	if r.Engine == "" {
+		return "mysql"
+	}
+
+	return r.Engine
+}
+
+// SSHArgs returns the arguments for the ssh executable to run the command on the
+// remote server. Batch mode is used so ssh fails instead of prompting for passwords.
+func SSHArgs(r config.Remote, home, command string) []string {
+	args := []string{"-o", "BatchMode=yes"}
+
+	if r.Port != 0 {
+		args = append(args, "-p", strconv.Itoa(r.Port))
+	}
+
+	if r.IdentityFile != "" {
+		file := r.IdentityFile
+		if strings.HasPrefix(file, "~") {
+			file = strings.Replace(file, "~", home, 1)
+		}
+
+		args = append(args, "-i", file)
+	}
+
+	host := r.Host
+	if r.User != "" {
+		host = r.User + "@" + r.Host
+	}
+
+	// stop parsing options so the host cannot be read as an option
+	return append(args, "--", host, command)
+}
+
+// DumpCommand returns the shell command to run on the remote server to write a dump
+// of the database to stdout. The password is passed as an environment variable, or a
+// config file for mongodump, so it is not shown in the process list on the remote server.
+func DumpCommand(r config.Remote) string {
+	host := r.DatabaseHost
+	if host == "" {
+		host = "127.0.0.1"
+	}
+
+	switch Engine(r) {
+	case "postgres":
+		cmd := []string{"pg_dump", "--no-owner", "--host=" + quote(host)}
+		if r.DatabasePort != 0 {
+			cmd = append(cmd, "--port="+strconv.Itoa(r.DatabasePort))
+		}
+		if r.DatabaseUser != "" {
+			cmd = append(cmd, "--username="+quote(r.DatabaseUser))
+		}
+
+		cmd = append(cmd, quote(r.Database))
+
+		if r.DatabasePassword != "" {
+			cmd = append([]string{"PGPASSWORD=" + quote(r.DatabasePassword)}, cmd...)
+		}
+
+		return strings.Join(cmd, " ")
+	case "mongodb":
+		cmd := []string{"mongodump", "--host=" + quote(host), "--db=" + quote(r.Database), "--archive"}
+		if r.DatabasePort != 0 {
+			cmd = append(cmd, "--port="+strconv.Itoa(r.DatabasePort))
+		}
+		if r.DatabaseUser != "" {
+			cmd = append(cmd, "--username="+quote(r.DatabaseUser), "--authenticationDatabase=admin")
+		}
+		if r.DatabasePassword != "" {
+			// mongodump does not read the password from the environment, so the printf builtin
+			// writes it to a config file only the user can read, which is removed after the dump
+			password := quote("password: '" + strings.ReplaceAll(r.DatabasePassword, "'", "''") + "'")
+			cmd = append(cmd, `--config="$f"`)
+
+			return `umask 077 && f=$(mktemp) && trap 'rm -f "$f"' EXIT && printf '%s\n' ` + password + ` > "$f" && ` + strings.Join(cmd, " ")
+		}
+
+		return strings.Join(cmd, " ")
+	default:
+		cmd := []string{"mysqldump", "--single-transaction", "--no-tablespaces", "--host=" + quote(host)}
+		if r.DatabasePort != 0 {
+			cmd = append(cmd, "--port="+strconv.Itoa(r.DatabasePort))
+		}
+		if r.DatabaseUser != "" {
+			cmd = append(cmd, "--user="+quote(r.DatabaseUser))
+		}
+
+		cmd = append(cmd, quote(r.Database))
+
+		if r.DatabasePassword != "" {
+			cmd = append([]string{"MYSQL_PWD=" + quote(r.DatabasePassword)}, cmd...)
+		}
+
+		return strings.Join(cmd, " ")
+	}
+}
+
+// Dump connects to the remote server using the ssh executable and writes the dump of
+// the database to w.
+func Dump(ctx context.Context, r config.Remote, home string, w io.Writer) error {
+	ssh, err := exec.LookPath("ssh")
+	if err != nil {
+		return fmt.Errorf("unable to find the ssh executable, %w", err)
+	}
+
+	stderr := new(bytes.Buffer)
+
+	c := exec.CommandContext(ctx, ssh, SSHArgs(r, home, DumpCommand(r))...)
+	c.Stdout = w
+	c.Stderr = stderr
+
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("unable to dump the remote database, %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
+
+// quote returns the value quoted for a posix shell.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,75 @@
+package remote
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/craftcms/nitro/pkg/config"
+)
+
+func TestSSHArgs(t *testing.T) {
+	tests := []struct {
+		name   string
+		remote config.Remote
+		want   []string
+	}{
+		{
+			name:   "uses the host",
+			remote: config.Remote{Host: "example.com"},
+			want:   []string{"-o", "BatchMode=yes", "--", "example.com", "ls"},
+		},
+		{
+			name:   "uses the user, port, and identity file",
+			remote: config.Remote{Host: "example.com", User: "forge", Port: 2222, IdentityFile: "~/.ssh/id_forge"},
+			want:   []string{"-o", "BatchMode=yes", "-p", "2222", "-i", "/home/oli/.ssh/id_forge", "--", "forge@example.com", "ls"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SSHArgs(tt.remote, "/home/oli", "ls"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SSHArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDumpCommand(t *testing.T) {
+	tests := []struct {
+		name   string
+		remote config.Remote
+		want   string
+	}{
+		{
+			name:   "mysql is the default engine",
+			remote: config.Remote{Host: "example.com", Database: "craft"},
+			want:   "mysqldump --single-transaction --no-tablespaces --host='127.0.0.1' 'craft'",
+		},
+		{
+			name:   "mysql passwords use the environment",
+			remote: config.Remote{Host: "example.com", Database: "craft", DatabaseHost: "db.internal", DatabasePort: 3307, DatabaseUser: "forge", DatabasePassword: "it's-secret"},
+			want:   `MYSQL_PWD='it'\''s-secret' mysqldump --single-transaction --no-tablespaces --host='db.internal' --port=3307 --user='forge' 'craft'`,
+		},
+		{
+			name:   "postgres uses pg_dump",
+			remote: config.Remote{Host: "example.com", Engine: "postgres", Database: "craft", DatabaseUser: "forge", DatabasePassword: "secret"},
+			want:   "PGPASSWORD='secret' pg_dump --no-owner --host='127.0.0.1' --username='forge' 'craft'",
+		},
+		{
+			name:   "mongodb uses mongodump",
+			remote: config.Remote{Host: "example.com", Engine: "mongodb", Database: "craft", DatabaseUser: "forge"},
+			want:   "mongodump --host='127.0.0.1' --db='craft' --archive --username='forge' --authenticationDatabase=admin",
+		},
+		{
+			name:   "mongodb passwords use a config file",
+			remote: config.Remote{Host: "example.com", Engine: "mongodb", Database: "craft", DatabaseUser: "forge", DatabasePassword: "it's-secret"},
+			want:   `umask 077 && f=$(mktemp) && trap 'rm -f "$f"' EXIT && printf '%s\n' 'password: '\''it'\'''\''s-secret'\''' > "$f" && mongodump --host='127.0.0.1' --db='craft' --archive --username='forge' --authenticationDatabase=admin --config="$f"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DumpCommand(tt.remote); got != tt.want {
+				t.Errorf("DumpCommand() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
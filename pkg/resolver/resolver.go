@@ -0,0 +1,174 @@
+// Package resolver configures the operating system to send DNS queries for the top level
+// domain to the nitro DNS container. macOS uses a file in /etc/resolver, linux uses a
+// systemd-resolved drop-in, and windows uses a name resolution policy table (NRPT) rule.
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/craftcms/nitro/pkg/wsl"
+)
+
+var (
+	// ErrUnsupported is returned when the resolver can not be configured on the system
+	ErrUnsupported = errors.New("configuring a resolver is not supported on this system")
+
+	// ErrUnsupportedPort is returned on windows when the DNS port is not 53
+	ErrUnsupportedPort = errors.New("windows only supports resolvers on port 53")
+
+	// goos is the operating system, it is a variable to change during tests
+	goos = runtime.GOOS
+
+	// root is the prefix for the resolver files, it is a variable to change during tests
+	root = "/"
+
+	// systemdStub is the file that exists when systemd-resolved manages DNS
+	systemdStub = "/run/systemd/resolve/stub-resolv.conf"
+)
+
+// File returns the path to the resolver file for the top level domain.
+func File(tld string) (string, error) {
+	switch goos {
+	case "darwin":
+		return filepath.Join(root, "etc", "resolver", tld), nil
+	case "linux":
+		if wsl.IsWSL() {
+			return "", ErrUnsupported
+		}
+
+		if _, err := os.Stat(filepath.Join(root, systemdStub)); err != nil {
+			return "", ErrUnsupported
+		}
+
+		return filepath.Join(root, "etc", "systemd", "resolved.conf.d", "nitro-"+tld+".conf"), nil
+	}
+
+	return "", ErrUnsupported
+}
+
+// Content returns the content of the resolver file for the top level domain.
+func Content(tld, port string) string {
+	if goos == "linux" {
+		return fmt.Sprintf("# managed by nitro\n[Resolve]\nDNS=127.0.0.1:%s\nDomains=~%s\n", port, tld)
+	}
+
+	return fmt.Sprintf("# managed by nitro\nnameserver 127.0.0.1\nport %s\n", port)
+}
+
+// IsInstalled checks if the resolver for the top level domain uses the port.
+func IsInstalled(tld, port string) (bool, error) {
+	if goos == "windows" {
+		if port != "53" {
+			return false, ErrUnsupportedPort
+		}
+
+		out, err := powershell(fmt.Sprintf("(Get-DnsClientNrptRule | Where-Object Namespace -eq '.%s' | Measure-Object).Count", tld))
+		if err != nil {
+			return false, err
+		}
+
+		return strings.TrimSpace(out) != "0", nil
+	}
+
+	file, err := File(tld)
+	if err != nil {
+		return false, err
+	}
+
+	b, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("unable to read the resolver file, %w", err)
+	}
+
+	return string(b) == Content(tld, port), nil
+}
+
+// Install configures the resolver for the top level domain to use the DNS container on the port.
+// It requires root on macOS and linux, and an administrator on windows.
+func Install(tld, port string) error {
+	if goos == "windows" {
+		if port != "53" {
+			return ErrUnsupportedPort
+		}
+
+		if err := Remove(tld); err != nil {
+			return err
+		}
+
+		_, err := powershell(fmt.Sprintf("Add-DnsClientNrptRule -Namespace '.%s' -NameServers '127.0.0.1' -Comment 'nitro'", tld))
+
+		return err
+	}
+
+	file, err := File(tld)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("unable to create the resolver directory, %w", err)
+	}
+
+	if err := ioutil.WriteFile(file, []byte(Content(tld, port)), 0644); err != nil {
+		return fmt.Errorf("unable to write the resolver file, %w", err)
+	}
+
+	return reload()
+}
+
+// Remove removes the resolver for the top level domain, if there is no resolver it does nothing.
+func Remove(tld string) error {
+	if goos == "windows" {
+		_, err := powershell(fmt.Sprintf("Get-DnsClientNrptRule | Where-Object Namespace -eq '.%s' | Remove-DnsClientNrptRule -Force", tld))
+
+		return err
+	}
+
+	file, err := File(tld)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(file); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return fmt.Errorf("unable to remove the resolver file, %w", err)
+	}
+
+	return reload()
+}
+
+// reload tells systemd-resolved to use the changed drop-in, macOS reads the resolver files
+// for each query.
+func reload() error {
+	if goos != "linux" || root != "/" {
+		return nil
+	}
+
+	if out, err := exec.Command("systemctl", "restart", "systemd-resolved").CombinedOutput(); err != nil {
+		return fmt.Errorf("unable to restart systemd-resolved, %w\n%s", err, out)
+	}
+
+	return nil
+}
+
+func powershell(command string) (string, error) {
+	out, err := exec.Command("powershell", "-NoProfile", "-Command", command).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("unable to configure the name resolution policy, %w\n%s", err, out)
+	}
+
+	return string(out), nil
+}
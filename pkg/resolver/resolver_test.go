@@ -0,0 +1,92 @@
+package resolver
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInstall(t *testing.T) {
+	tests := []struct {
+		name     string
+		goos     string
+		systemd  bool
+		wantFile string
+		wantErr  error
+	}{
+		{
+			name:     "macOS uses a resolver file for the domain",
+			goos:     "darwin",
+			wantFile: "etc/resolver/nitro",
+		},
+		{
+			name:     "linux uses a systemd-resolved drop in",
+			goos:     "linux",
+			systemd:  true,
+			wantFile: "etc/systemd/resolved.conf.d/nitro-nitro.conf",
+		},
+		{
+			name:    "linux without systemd-resolved is not supported",
+			goos:    "linux",
+			wantErr: ErrUnsupported,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			defaultGOOS, defaultRoot := goos, root
+			goos, root = tt.goos, dir
+			defer func() {
+				goos, root = defaultGOOS, defaultRoot
+			}()
+
+			if tt.systemd {
+				stub := filepath.Join(dir, systemdStub)
+				if err := os.MkdirAll(filepath.Dir(stub), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := ioutil.WriteFile(stub, nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := Install("nitro", "5354")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Install() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			b, err := ioutil.ReadFile(filepath.Join(dir, tt.wantFile))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(b) != Content("nitro", "5354") {
+				t.Errorf("expected the resolver file to be %q, got %q", Content("nitro", "5354"), string(b))
+			}
+
+			installed, err := IsInstalled("nitro", "5354")
+			if err != nil || !installed {
+				t.Errorf("expected the resolver to be installed, got %v %v", installed, err)
+			}
+
+			// a different port needs to be installed again
+			if installed, _ := IsInstalled("nitro", "53"); installed {
+				t.Error("expected the resolver for another port to not be installed")
+			}
+
+			if err := Remove("nitro"); err != nil {
+				t.Fatal(err)
+			}
+
+			if installed, _ := IsInstalled("nitro", "5354"); installed {
+				t.Error("expected the resolver to be removed")
+			}
+		})
+	}
+}
@@ -0,0 +1,328 @@
+// Package s3 is a small client for S3 compatible storage, such as AWS S3 or MinIO,
+// that supports uploading, downloading, and listing objects in a bucket. Requests
+// are signed with AWS signature version 4.
+package s3
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when the object does not exist in the bucket
+var ErrNotFound = errors.New("the object does not exist")
+
+// emptyHash is the sha256 of an empty payload
+const emptyHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// Client is used to make requests to a bucket.
+type Client struct {
+	Endpoint  *url.URL
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+
+	// HTTPClient is the client to make requests, it defaults to http.DefaultClient
+	HTTPClient *http.Client
+
+	now func() time.Time
+}
+
+// New returns a client for the bucket. The endpoint is the URL of the storage (e.g.
+// https://s3.amazonaws.com or http://localhost:9000) and the region defaults to
+// us-east-1, which is also the default for MinIO.
+func New(endpoint, region, bucket, accessKey, secretKey string) (*Client, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the endpoint, %w", err)
+	}
+
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("the endpoint %q must include the scheme and host", endpoint)
+	}
+
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	return &Client{
+		Endpoint:   u,
+		Region:     region,
+		Bucket:     bucket,
+		AccessKey:  accessKey,
+		SecretKey:  secretKey,
+		HTTPClient: http.DefaultClient,
+		now:        time.Now,
+	}, nil
+}
+
+// Put uploads the content to the key in the bucket. The content is read twice, once
+// to sign the request and once to upload it.
+func (c *Client) Put(ctx context.Context, key string, content io.ReadSeeker) error {
+	h := sha256.New()
+	size, err := io.Copy(h, content)
+	if err != nil {
+		return err
+	}
+
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, c.url(key, nil), ioutil.NopCloser(content))
+	if err != nil {
+		return err
+	}
+
+	req.ContentLength = size
+
+	resp, err := c.do(req, hex.EncodeToString(h.Sum(nil)))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+// Get downloads the object for the key and writes it to w.
+func (c *Client) Get(ctx context.Context, key string, w io.Writer) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url(key, nil), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(req, emptyHash)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, err = io.Copy(w, resp.Body)
+
+	return err
+}
+
+// List returns the keys in the bucket that start with the prefix.
+func (c *Client) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url("", query), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := c.do(req, emptyHash)
+		if err != nil {
+			return nil, err
+		}
+
+		var result struct {
+			Contents []struct {
+				Key string `xml:"Key"`
+			} `xml:"Contents"`
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+		}
+
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the bucket list, %w", err)
+		}
+
+		for _, o := range result.Contents {
+			keys = append(keys, o.Key)
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return keys, nil
+		}
+
+		token = result.NextContinuationToken
+	}
+}
+
+// URL returns the url of the object for the key, which can be shared with anyone
+// that has access to the bucket.
+func (c *Client) URL(key string) string {
+	return c.url(key, nil)
+}
+
+// url returns the url for the key, AWS uses virtual hosted buckets and other
+// storage, such as MinIO, uses the bucket in the path.
+func (c *Client) url(key string, query url.Values) string {
+	u := *c.Endpoint
+
+	if strings.HasSuffix(u.Hostname(), "amazonaws.com") {
+		u.Host = c.Bucket + "." + u.Host
+		u.Path = "/" + key
+	} else {
+		u.Path = "/" + c.Bucket + "/" + key
+	}
+
+	u.RawPath = encodePath(u.Path)
+	u.RawQuery = canonicalQuery(query)
+
+	return u.String()
+}
+
+func (c *Client) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	c.sign(req, payloadHash)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, ErrNotFound
+		}
+
+		var e struct {
+			Code    string `xml:"Code"`
+			Message string `xml:"Message"`
+		}
+
+		body, _ := ioutil.ReadAll(resp.Body)
+		if err := xml.Unmarshal(body, &e); err != nil || e.Code == "" {
+			return nil, fmt.Errorf("the storage returned %s", resp.Status)
+		}
+
+		return nil, fmt.Errorf("the storage returned %s, %s: %s", resp.Status, e.Code, e.Message)
+	}
+
+	return resp, nil
+}
+
+// sign adds the signature version 4 headers to the request.
+func (c *Client) sign(req *http.Request, payloadHash string) {
+	now := c.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+
+	// the headers to sign
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if strings.HasPrefix(name, "x-amz-") || name == "range" || name == "content-type" || name == "content-md5" {
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+
+	var names []string
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		encodePath(req.URL.Path),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + c.Region + "/s3/aws4_request"
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+c.SecretKey), date)
+	key = hmacSHA256(key, c.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", c.AccessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+
+	return h.Sum(nil)
+}
+
+// canonicalQuery returns the query sorted by key with the values encoded.
+func canonicalQuery(query url.Values) string {
+	var keys []string
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var params []string
+	for _, k := range keys {
+		values := append([]string{}, query[k]...)
+		sort.Strings(values)
+
+		for _, v := range values {
+			params = append(params, encode(k, true)+"="+encode(v, true))
+		}
+	}
+
+	return strings.Join(params, "&")
+}
+
+// encodePath encodes each segment of the path.
+func encodePath(path string) string {
+	return encode(path, false)
+}
+
+// encode uri encodes the string as required for signing, only unreserved characters
+// are not encoded and the slash is encoded when encodeSlash is true.
+func encode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for _, c := range []byte(s) {
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,169 @@
+// Package schedule parses cron style schedules, such as "0 */6 * * *", for
+// running tasks like database backups.
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSchedule is returned when the schedule is not a valid cron expression
+var ErrInvalidSchedule = errors.New("the schedule is not a valid cron expression")
+
+// Schedule is a parsed cron expression with the minute, hour, day of month,
+// month, and day of week fields.
+type Schedule struct {
+	minute, hour, dom, month, dow map[int]bool
+
+	// domAny and dowAny are used to match days the same way as cron, when both
+	// day fields are restricted either field can match
+	domAny, dowAny bool
+}
+
+var bounds = []struct{ min, max int }{
+	{0, 59}, // minute
+	{0, 23}, // hour
+	{1, 31}, // day of month
+	{1, 12}, // month
+	{0, 7},  // day of week
+}
+
+// Parse takes a cron expression with five fields and returns the schedule. Each field
+// supports *, numbers, ranges (1-5), steps (*/15 or 0-30/10), and lists (1,15).
+func Parse(spec string) (*Schedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w, expected 5 fields and found %d", ErrInvalidSchedule, len(fields))
+	}
+
+	var parsed []map[int]bool
+	for i, f := range fields {
+		values, err := parseField(f, bounds[i].min, bounds[i].max)
+		if err != nil {
+			return nil, fmt.Errorf("%w, %s", ErrInvalidSchedule, err)
+		}
+
+		parsed = append(parsed, values)
+	}
+
+	// sunday can be 0 or 7
+	if parsed[4][7] {
+		parsed[4][0] = true
+	}
+
+	s := &Schedule{
+		minute: parsed[0],
+		hour:   parsed[1],
+		dom:    parsed[2],
+		month:  parsed[3],
+		dow:    parsed[4],
+		domAny: fields[2] == "*",
+		dowAny: fields[4] == "*",
+	}
+
+	// days that do not exist, such as february 31, never run
+	if s.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("%w, the schedule never runs", ErrInvalidSchedule)
+	}
+
+	return s, nil
+}
+
+// Next returns the next time after t that matches the schedule, or a zero time
+// when the schedule never runs. Parse rejects schedules that never run.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+
+	// every schedule matches at least once in a few years
+	end := t.AddDate(5, 0, 0)
+	for t.Before(end) {
+		switch {
+		case !s.month[int(t.Month())]:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.day(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case !s.hour[t.Hour()]:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case !s.minute[t.Minute()]:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// Due returns true if the schedule had a run between last and now.
+func (s *Schedule) Due(last, now time.Time) bool {
+	next := s.Next(last)
+
+	return !next.IsZero() && !next.After(now)
+}
+
+func (s *Schedule) day(t time.Time) bool {
+	dom, dow := s.dom[t.Day()], s.dow[int(t.Weekday())]
+
+	switch {
+	case s.domAny && s.dowAny:
+		return true
+	case s.domAny:
+		return dow
+	case s.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+func parseField(field string, min, max int) (map[int]bool, error) {
+	values := make(map[int]bool)
+
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			s, err := strconv.Atoi(part[i+1:])
+			if err != nil || s < 1 {
+				return nil, fmt.Errorf("invalid step in %q", part)
+			}
+
+			step = s
+			part = part[:i]
+		}
+
+		start, end := min, max
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			r := strings.SplitN(part, "-", 2)
+
+			var err error
+			if start, err = strconv.Atoi(r[0]); err != nil {
+				return nil, fmt.Errorf("invalid range %q", part)
+			}
+			if end, err = strconv.Atoi(r[1]); err != nil {
+				return nil, fmt.Errorf("invalid range %q", part)
+			}
+		default:
+			v, err := strconv.Atoi(part)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q", part)
+			}
+
+			start, end = v, v
+		}
+
+		if start < min || end > max || start > end {
+			return nil, fmt.Errorf("%q must be between %d and %d", part, min, max)
+		}
+
+		for v := start; v <= end; v += step {
+			values[v] = true
+		}
+	}
+
+	return values, nil
+}
@@ -0,0 +1,115 @@
+package schedule
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr bool
+	}{
+		{name: "every minute", spec: "* * * * *"},
+		{name: "every six hours", spec: "0 */6 * * *"},
+		{name: "lists and ranges", spec: "0,30 9-17 * * 1-5"},
+		{name: "sunday as seven", spec: "0 0 * * 7"},
+		{name: "too few fields", spec: "0 */6 * *", wantErr: true},
+		{name: "out of range", spec: "60 * * * *", wantErr: true},
+		{name: "invalid step", spec: "*/0 * * * *", wantErr: true},
+		{name: "invalid value", spec: "a * * * *", wantErr: true},
+		{name: "reversed range", spec: "* 5-1 * * *", wantErr: true},
+		{name: "days that do not exist", spec: "0 0 31 2 *", wantErr: true},
+		{name: "leap days", spec: "0 0 29 2 *"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil && !errors.Is(err, ErrInvalidSchedule) {
+				t.Errorf("expected ErrInvalidSchedule, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSchedule_Next(t *testing.T) {
+	// a wednesday
+	from := time.Date(2021, 3, 3, 7, 15, 30, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		spec string
+		want time.Time
+	}{
+		{
+			name: "every minute",
+			spec: "* * * * *",
+			want: time.Date(2021, 3, 3, 7, 16, 0, 0, time.UTC),
+		},
+		{
+			name: "every six hours",
+			spec: "0 */6 * * *",
+			want: time.Date(2021, 3, 3, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "daily at midnight",
+			spec: "0 0 * * *",
+			want: time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "sundays",
+			spec: "30 2 * * 0",
+			want: time.Date(2021, 3, 7, 2, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "first of the month",
+			spec: "0 0 1 * *",
+			want: time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "day of month or week",
+			spec: "0 0 15 * 5",
+			want: time.Date(2021, 3, 5, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "next year",
+			spec: "0 0 1 1 *",
+			want: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := s.Next(from); !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchedule_Due(t *testing.T) {
+	s, err := Parse("0 */6 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	last := time.Date(2021, 3, 3, 6, 0, 0, 0, time.UTC)
+
+	if s.Due(last, time.Date(2021, 3, 3, 11, 59, 0, 0, time.UTC)) {
+		t.Error("expected the schedule to not be due before noon")
+	}
+
+	if !s.Due(last, time.Date(2021, 3, 3, 12, 0, 0, 0, time.UTC)) {
+		t.Error("expected the schedule to be due at noon")
+	}
+}
@@ -0,0 +1,207 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/terminal"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+)
+
+const (
+	// Image is the image to use for the elasticsearch container, with the version
+	Image = "docker.elastic.co/elasticsearch/elasticsearch:%s"
+
+	// DefaultVersion is the elasticsearch version used when the config does not set one
+	DefaultVersion = "7.10.1"
+
+	// DefaultHeapSize is the java heap size used when the config does not set one
+	DefaultHeapSize = "512m"
+
+	// Host is the hostname for the elasticsearch container
+	Host = "elasticsearch.service.nitro"
+
+	// Label is the label value used to mark a container as an "elasticsearch" service
+	Label = "elasticsearch"
+
+	// Port is the http port elasticsearch listens on in the container
+	Port = "9200"
+)
+
+// VerifyCreated will verify that the elasticsearch service container exists for the version and heap size and is
+// started. If the container uses a different version or heap size, it is removed and a new container is created.
+func VerifyCreated(ctx context.Context, cli client.CommonAPIClient, networkID, version, heapSize string, output terminal.Outputer) (string, string, error) {
+	image := ImageForVersion(version)
+	if heapSize == "" {
+		heapSize = DefaultHeapSize
+	}
+
+	// add the filter
+	filter := filters.NewArgs()
+	filter.Add("label", containerlabels.Nitro+"=true")
+	filter.Add("label", containerlabels.Type+"="+Label)
+
+	// get a list of containers
+	containers, err := cli.ContainerList(ctx, types.ContainerListOptions{
+		All:     true,
+		Filters: filter,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	// if there is not a container, create one
+	if len(containers) == 0 {
+		return create(ctx, cli, networkID, image, heapSize)
+	}
+
+	// if the version or heap size changed, replace the container
+	if containers[0].Image != image || containers[0].Labels[containerlabels.ElasticsearchHeapSize] != heapSize {
+		if err := VerifyRemoved(ctx, cli, output); err != nil {
+			return "", "", err
+		}
+
+		return create(ctx, cli, networkID, image, heapSize)
+	}
+
+	// start the container if its not running
+	if containers[0].State != "running" {
+		if err := cli.ContainerStart(ctx, containers[0].ID, types.ContainerStartOptions{}); err != nil {
+			return "", "", fmt.Errorf("unable to start the container, %w", err)
+		}
+	}
+
+	return containers[0].ID, Host, nil
+}
+
+// ImageForVersion returns the image for the elasticsearch version, an empty version uses the DefaultVersion.
+func ImageForVersion(version string) string {
+	if version == "" {
+		version = DefaultVersion
+	}
+
+	return fmt.Sprintf(Image, version)
+}
+
+func create(ctx context.Context, cli client.CommonAPIClient, networkID, image, heapSize string) (string, string, error) {
+	// pull the image
+	r, err := cli.ImagePull(ctx, image, types.ImagePullOptions{})
+	if err != nil {
+		return "", "", err
+	}
+
+	// read from the buffer to pull the image
+	buf := &bytes.Buffer{}
+	if _, err := buf.ReadFrom(r); err != nil {
+		return "", "", fmt.Errorf("unable to read output while pulling image, %w", err)
+	}
+
+	// set the nitro env overrides
+	httpPort := Port
+	if os.Getenv("NITRO_ELASTICSEARCH_PORT") != "" {
+		httpPort = os.Getenv("NITRO_ELASTICSEARCH_PORT")
+	}
+
+	httpPortNat, err := nat.NewPort("tcp", Port)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to create the port, %w", err)
+	}
+
+	containerConfig := &container.Config{
+		Image: image,
+		Labels: containerlabels.StampRunID(ctx, map[string]string{
+			containerlabels.Nitro:                 "true",
+			containerlabels.Type:                  Label,
+			containerlabels.ElasticsearchHeapSize: heapSize,
+		}),
+		ExposedPorts: nat.PortSet{
+			httpPortNat: struct{}{},
+		},
+		// run a single node without security for local development
+		Env: []string{
+			"discovery.type=single-node",
+			"xpack.security.enabled=false",
+			fmt.Sprintf("ES_JAVA_OPTS=-Xms%s -Xmx%s", heapSize, heapSize),
+		},
+	}
+
+	hostconfig := &container.HostConfig{
+		PortBindings: map[nat.Port][]nat.PortBinding{
+			httpPortNat: {
+				{
+					HostIP:   "127.0.0.1",
+					HostPort: httpPort,
+				},
+			},
+		},
+	}
+
+	networkConfig := &network.NetworkingConfig{
+		EndpointsConfig: map[string]*network.EndpointSettings{
+			"nitro-network": {
+				NetworkID: networkID,
+			},
+		},
+	}
+
+	// create the container
+	resp, err := cli.ContainerCreate(ctx, containerConfig, hostconfig, networkConfig, nil, Host)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to create the container, %w", err)
+	}
+
+	// start the container
+	if err := cli.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
+		return "", "", fmt.Errorf("unable to start the container, %w", err)
+	}
+
+	return resp.ID, Host, nil
+}
+
+// VerifyRemoved will verify the container is not created for the elasticsearch service. If we find any
+// containers they are stopped and removed.
+func VerifyRemoved(ctx context.Context, cli client.CommonAPIClient, output terminal.Outputer) error {
+	// add the filter
+	filter := filters.NewArgs()
+	filter.Add("label", containerlabels.Nitro+"=true")
+	filter.Add("label", containerlabels.Type+"="+Label)
+
+	// get a list of containers
+	containers, err := cli.ContainerList(ctx, types.ContainerListOptions{
+		All:     true,
+		Filters: filter,
+	})
+	if err != nil {
+		return err
+	}
+
+	timeout := time.Duration(time.Second * 30)
+
+	// remove all of the containers
+	for _, c := range containers {
+		// stop the container if its running
+		if c.State == "running" {
+			if err := cli.ContainerStop(ctx, c.ID, &timeout); err != nil {
+				return err
+			}
+		}
+
+		// remove the container
+		if err := cli.ContainerRemove(ctx, c.ID, types.ContainerRemoveOptions{
+			RemoveVolumes: true,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
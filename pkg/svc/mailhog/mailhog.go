@@ -82,10 +82,10 @@ func VerifyCreated(ctx context.Context, cli client.CommonAPIClient, networkID st
 
 		containerConfig := &container.Config{
 			Image: Image,
-			Labels: map[string]string{
+			Labels: containerlabels.StampRunID(ctx, map[string]string{
 				containerlabels.Nitro: "true",
 				containerlabels.Type:  Label,
-			},
+			}),
 			ExposedPorts: nat.PortSet{
 				smtpPortNat: struct{}{},
 				httpPortNat: struct{}{},
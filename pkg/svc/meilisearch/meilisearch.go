@@ -0,0 +1,198 @@
+package meilisearch
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/terminal"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+)
+
+const (
+	// Image is the image to use for the meilisearch container
+	Image = "docker.io/getmeili/meilisearch:latest"
+
+	// Host is the hostname for the meilisearch container
+	Host = "meilisearch.service.nitro"
+
+	// Label is the label value used to mark a container as a "meilisearch" service
+	Label = "meilisearch"
+
+	// Port is the http port for the meilisearch container
+	Port = "7700"
+)
+
+// VerifyCreated will verify that the meilisearch service container exists with the master key and is started.
+// If the master key changed, the container is removed and a new container is created.
+func VerifyCreated(ctx context.Context, cli client.CommonAPIClient, networkID, masterKey string, output terminal.Outputer) (string, string, error) {
+	// add the filter
+	filter := filters.NewArgs()
+	filter.Add("label", containerlabels.Nitro+"=true")
+	filter.Add("label", containerlabels.Type+"="+Label)
+
+	// get a list of containers
+	containers, err := cli.ContainerList(ctx, types.ContainerListOptions{
+		All:     true,
+		Filters: filter,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	// if there is not a container, create one
+	if len(containers) == 0 {
+		return create(ctx, cli, networkID, masterKey)
+	}
+
+	// if the master key changed, replace the container
+	if containers[0].Labels[containerlabels.ConfigHash] != keyHash(masterKey) {
+		if err := VerifyRemoved(ctx, cli, output); err != nil {
+			return "", "", err
+		}
+
+		return create(ctx, cli, networkID, masterKey)
+	}
+
+	// start the container if its not running
+	if containers[0].State != "running" {
+		if err := cli.ContainerStart(ctx, containers[0].ID, types.ContainerStartOptions{}); err != nil {
+			return "", "", fmt.Errorf("unable to start the container, %w", err)
+		}
+	}
+
+	return containers[0].ID, Host, nil
+}
+
+// keyHash is used to label the container so changes to the master key can
+// be found without storing the key in a label.
+func keyHash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+func create(ctx context.Context, cli client.CommonAPIClient, networkID, masterKey string) (string, string, error) {
+	// pull the image
+	r, err := cli.ImagePull(ctx, Image, types.ImagePullOptions{})
+	if err != nil {
+		return "", "", err
+	}
+
+	// read from the buffer to pull the image
+	buf := &bytes.Buffer{}
+	if _, err := buf.ReadFrom(r); err != nil {
+		return "", "", fmt.Errorf("unable to read output while pulling image, %w", err)
+	}
+
+	// set the nitro env overrides
+	httpPort := Port
+	if os.Getenv("NITRO_MEILISEARCH_PORT") != "" {
+		httpPort = os.Getenv("NITRO_MEILISEARCH_PORT")
+	}
+
+	httpPortNat, err := nat.NewPort("tcp", Port)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to create the port, %w", err)
+	}
+
+	// without a master key, meilisearch does not require authentication
+	envs := []string{"MEILI_ENV=development", "MEILI_NO_ANALYTICS=true"}
+	if masterKey != "" {
+		envs = append(envs, "MEILI_MASTER_KEY="+masterKey)
+	}
+
+	containerConfig := &container.Config{
+		Image: Image,
+		Labels: containerlabels.StampRunID(ctx, map[string]string{
+			containerlabels.Nitro:      "true",
+			containerlabels.Type:       Label,
+			containerlabels.ConfigHash: keyHash(masterKey),
+		}),
+		ExposedPorts: nat.PortSet{
+			httpPortNat: struct{}{},
+		},
+		Env: envs,
+	}
+
+	hostconfig := &container.HostConfig{
+		PortBindings: map[nat.Port][]nat.PortBinding{
+			httpPortNat: {
+				{
+					HostIP:   "127.0.0.1",
+					HostPort: httpPort,
+				},
+			},
+		},
+	}
+
+	networkConfig := &network.NetworkingConfig{
+		EndpointsConfig: map[string]*network.EndpointSettings{
+			"nitro-network": {
+				NetworkID: networkID,
+			},
+		},
+	}
+
+	// create the container
+	resp, err := cli.ContainerCreate(ctx, containerConfig, hostconfig, networkConfig, nil, Host)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to create the container, %w", err)
+	}
+
+	// start the container
+	if err := cli.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
+		return "", "", fmt.Errorf("unable to start the container, %w", err)
+	}
+
+	return resp.ID, Host, nil
+}
+
+// VerifyRemoved will verify the container is not created for the meilisearch service. If we find any
+// containers they are stopped and removed.
+func VerifyRemoved(ctx context.Context, cli client.CommonAPIClient, output terminal.Outputer) error {
+	// add the filter
+	filter := filters.NewArgs()
+	filter.Add("label", containerlabels.Nitro+"=true")
+	filter.Add("label", containerlabels.Type+"="+Label)
+
+	// get a list of containers
+	containers, err := cli.ContainerList(ctx, types.ContainerListOptions{
+		All:     true,
+		Filters: filter,
+	})
+	if err != nil {
+		return err
+	}
+
+	timeout := time.Duration(time.Second * 30)
+
+	// remove all of the containers
+	for _, c := range containers {
+		// stop the container if its running
+		if c.State == "running" {
+			if err := cli.ContainerStop(ctx, c.ID, &timeout); err != nil {
+				return err
+			}
+		}
+
+		// remove the container
+		if err := cli.ContainerRemove(ctx, c.ID, types.ContainerRemoveOptions{
+			RemoveVolumes: true,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
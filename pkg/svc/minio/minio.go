@@ -3,6 +3,8 @@ package minio
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"time"
@@ -12,7 +14,9 @@ import (
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/api/types/network"
+	volumetypes "github.com/docker/docker/api/types/volume"
 	"github.com/docker/docker/client"
 	"github.com/docker/go-connections/nat"
 )
@@ -26,10 +30,34 @@ const (
 
 	// Label is the label value used to mark a container as a "minio" service
 	Label = "minio"
+
+	// Volume is the name of the volume that stores the minio data
+	Volume = "minio.service.nitro"
+
+	// Port is the port for the s3 api
+	Port = "9000"
+
+	// ConsolePort is the port for the web console, which is exposed through the proxy
+	ConsolePort = "9001"
+
+	// DefaultUser is the root user when the config does not set one
+	DefaultUser = "nitro"
+
+	// DefaultPassword is the root password when the config does not set one
+	DefaultPassword = "nitropassword"
 )
 
-// VerifyCreated will verify that the minio service container exists and is started
-func VerifyCreated(ctx context.Context, cli client.CommonAPIClient, networkID string, output terminal.Outputer) (string, string, error) {
+// VerifyCreated will verify that the minio service container exists with the credentials and is started. If the
+// credentials changed, the container is replaced and the data volume is kept.
+func VerifyCreated(ctx context.Context, cli client.CommonAPIClient, networkID, user, password string, output terminal.Outputer) (string, string, error) {
+	if user == "" {
+		user = DefaultUser
+	}
+
+	if password == "" {
+		password = DefaultPassword
+	}
+
 	// add the filter
 	filter := filters.NewArgs()
 	filter.Add("label", containerlabels.Nitro+"=true")
@@ -46,86 +74,128 @@ func VerifyCreated(ctx context.Context, cli client.CommonAPIClient, networkID st
 
 	// if there is not a container, create one
 	if len(containers) == 0 {
-		// pull the image
-		r, err := cli.ImagePull(ctx, Image, types.ImagePullOptions{})
-		if err != nil {
+		return create(ctx, cli, networkID, user, password)
+	}
+
+	// if the credentials changed, replace the container
+	if containers[0].Labels[containerlabels.ConfigHash] != credentialsHash(user, password) {
+		if err := VerifyRemoved(ctx, cli, output); err != nil {
 			return "", "", err
 		}
 
-		// read from the buffer to pull the image
-		buf := &bytes.Buffer{}
-		if _, err := buf.ReadFrom(r); err != nil {
-			return "", "", fmt.Errorf("unable to read output while pulling image, %w", err)
-		}
+		return create(ctx, cli, networkID, user, password)
+	}
 
-		// set the nitro env overrides
-		httpPort := "9000"
-		if os.Getenv("NITRO_MINIO_PORT") != "" {
-			httpPort = os.Getenv("NITRO_MINIO_PORT")
+	// start each of the containers, there should only be one so the final return is an error
+	for _, c := range containers {
+		// start the container
+		if c.Status != "running" {
+			if err := cli.ContainerStart(ctx, c.ID, types.ContainerStartOptions{}); err != nil {
+				return "", "", fmt.Errorf("unable to start the container, %w", err)
+			}
 		}
+	}
 
-		httpPortNat, err := nat.NewPort("tcp", "9000")
-		if err != nil {
-			return "", "", fmt.Errorf("unable to create the port, %w", err)
-		}
+	return containers[0].ID, Host, nil
+}
 
-		containerConfig := &container.Config{
-			Image: Image,
-			Labels: map[string]string{
-				containerlabels.Nitro: "true",
-				containerlabels.Type:  Label,
-			},
-			ExposedPorts: nat.PortSet{
-				httpPortNat: struct{}{},
-			},
-			Cmd: []string{"server", "/data"},
-			Env: []string{"MINIO_ROOT_USER=nitro", "MINIO_ROOT_PASSWORD=nitropassword"},
-		}
+// credentialsHash is used to label the container so changes to the credentials
+// can be found without storing the password in a label.
+func credentialsHash(user, password string) string {
+	sum := sha256.Sum256([]byte(user + ":" + password))
 
-		hostconfig := &container.HostConfig{
-			PortBindings: map[nat.Port][]nat.PortBinding{
-				httpPortNat: {
-					{
-						HostIP:   "127.0.0.1",
-						HostPort: httpPort,
-					},
-				},
-			},
-		}
+	return hex.EncodeToString(sum[:])[:16]
+}
 
-		networkConfig := &network.NetworkingConfig{
-			EndpointsConfig: map[string]*network.EndpointSettings{
-				"nitro-network": {
-					NetworkID: networkID,
+func create(ctx context.Context, cli client.CommonAPIClient, networkID, user, password string) (string, string, error) {
+	// pull the image
+	r, err := cli.ImagePull(ctx, Image, types.ImagePullOptions{})
+	if err != nil {
+		return "", "", err
+	}
+
+	// read from the buffer to pull the image
+	buf := &bytes.Buffer{}
+	if _, err := buf.ReadFrom(r); err != nil {
+		return "", "", fmt.Errorf("unable to read output while pulling image, %w", err)
+	}
+
+	// create the volume so the data is kept when the container is replaced
+	volume, err := cli.VolumeCreate(ctx, volumetypes.VolumeCreateBody{
+		Driver: "local",
+		Name:   Volume,
+		Labels: map[string]string{
+			containerlabels.Nitro:  "true",
+			containerlabels.Volume: Volume,
+		},
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("unable to create the volume, %w", err)
+	}
+
+	// set the nitro env overrides
+	httpPort := Port
+	if os.Getenv("NITRO_MINIO_PORT") != "" {
+		httpPort = os.Getenv("NITRO_MINIO_PORT")
+	}
+
+	httpPortNat, err := nat.NewPort("tcp", Port)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to create the port, %w", err)
+	}
+
+	containerConfig := &container.Config{
+		Image: Image,
+		Labels: containerlabels.StampRunID(ctx, map[string]string{
+			containerlabels.Nitro:      "true",
+			containerlabels.Type:       Label,
+			containerlabels.ConfigHash: credentialsHash(user, password),
+		}),
+		ExposedPorts: nat.PortSet{
+			httpPortNat: struct{}{},
+		},
+		Cmd: []string{"server", "/data", "--console-address", ":" + ConsolePort},
+		Env: []string{"MINIO_ROOT_USER=" + user, "MINIO_ROOT_PASSWORD=" + password},
+	}
+
+	hostconfig := &container.HostConfig{
+		Mounts: []mount.Mount{
+			{
+				Type:   mount.TypeVolume,
+				Source: volume.Name,
+				Target: "/data",
+			},
+		},
+		PortBindings: map[nat.Port][]nat.PortBinding{
+			httpPortNat: {
+				{
+					HostIP:   "127.0.0.1",
+					HostPort: httpPort,
 				},
 			},
-		}
-
-		// create the container
-		resp, err := cli.ContainerCreate(ctx, containerConfig, hostconfig, networkConfig, nil, Host)
-		if err != nil {
-			return "", "", fmt.Errorf("unable to create the container, %w", err)
-		}
+		},
+	}
 
-		// start the container
-		if err := cli.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
-			return "", "", fmt.Errorf("unable to start the container, %w", err)
-		}
+	networkConfig := &network.NetworkingConfig{
+		EndpointsConfig: map[string]*network.EndpointSettings{
+			"nitro-network": {
+				NetworkID: networkID,
+			},
+		},
+	}
 
-		return resp.ID, Host, nil
+	// create the container
+	resp, err := cli.ContainerCreate(ctx, containerConfig, hostconfig, networkConfig, nil, Host)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to create the container, %w", err)
 	}
 
-	// start each of the containers, there should only be one so the final return is an error
-	for _, c := range containers {
-		// start the container
-		if c.Status != "running" {
-			if err := cli.ContainerStart(ctx, c.ID, types.ContainerStartOptions{}); err != nil {
-				return "", "", fmt.Errorf("unable to start the container, %w", err)
-			}
-		}
+	// start the container
+	if err := cli.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
+		return "", "", fmt.Errorf("unable to start the container, %w", err)
 	}
 
-	return containers[0].ID, Host, nil
+	return resp.ID, Host, nil
 }
 
 // VerifyRemoved will try verify the container is not created for the minio service. If we find any containers that are
@@ -16,7 +16,9 @@ import (
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/api/types/network"
+	volumetypes "github.com/docker/docker/api/types/volume"
 	"github.com/docker/docker/client"
 	"github.com/docker/go-connections/nat"
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
@@ -27,6 +29,8 @@ func TestVerifyCreated(t *testing.T) {
 		ctx       context.Context
 		spy       *mockClient
 		networkID string
+		user      string
+		password  string
 		output    terminal.Outputer
 	}
 	tests := []struct {
@@ -74,16 +78,24 @@ func TestVerifyCreated(t *testing.T) {
 				Config: &container.Config{
 					Image: "docker.io/minio/minio:latest",
 					Labels: map[string]string{
-						containerlabels.Nitro: "true",
-						containerlabels.Type:  "minio",
+						containerlabels.Nitro:      "true",
+						containerlabels.Type:       "minio",
+						containerlabels.ConfigHash: credentialsHash("nitro", "nitropassword"),
 					},
 					ExposedPorts: nat.PortSet{
 						"9000/tcp": struct{}{},
 					},
-					Cmd: []string{"server", "/data"},
+					Cmd: []string{"server", "/data", "--console-address", ":9001"},
 					Env: []string{"MINIO_ROOT_USER=nitro", "MINIO_ROOT_PASSWORD=nitropassword"},
 				},
 				HostConfig: &container.HostConfig{
+					Mounts: []mount.Mount{
+						{
+							Type:   mount.TypeVolume,
+							Source: "minio.service.nitro",
+							Target: "/data",
+						},
+					},
 					PortBindings: map[nat.Port][]nat.PortBinding{
 						"9000/tcp": {
 							{
@@ -133,16 +145,24 @@ func TestVerifyCreated(t *testing.T) {
 				Config: &container.Config{
 					Image: "docker.io/minio/minio:latest",
 					Labels: map[string]string{
-						containerlabels.Nitro: "true",
-						containerlabels.Type:  "minio",
+						containerlabels.Nitro:      "true",
+						containerlabels.Type:       "minio",
+						containerlabels.ConfigHash: credentialsHash("nitro", "nitropassword"),
 					},
 					ExposedPorts: nat.PortSet{
 						"9000/tcp": struct{}{},
 					},
-					Cmd: []string{"server", "/data"},
+					Cmd: []string{"server", "/data", "--console-address", ":9001"},
 					Env: []string{"MINIO_ROOT_USER=nitro", "MINIO_ROOT_PASSWORD=nitropassword"},
 				},
 				HostConfig: &container.HostConfig{
+					Mounts: []mount.Mount{
+						{
+							Type:   mount.TypeVolume,
+							Source: "minio.service.nitro",
+							Target: "/data",
+						},
+					},
 					PortBindings: map[nat.Port][]nat.PortBinding{
 						"9000/tcp": {
 							{
@@ -172,8 +192,9 @@ func TestVerifyCreated(t *testing.T) {
 				spy: &mockClient{
 					containers: []types.Container{
 						{
-							ID:    "existing-container-id",
-							State: "not-running",
+							ID:     "existing-container-id",
+							Labels: map[string]string{containerlabels.ConfigHash: credentialsHash("nitro", "nitropassword")},
+							State:  "not-running",
 						},
 					},
 				},
@@ -219,7 +240,7 @@ func TestVerifyCreated(t *testing.T) {
 		}
 
 		t.Run(tt.name, func(t *testing.T) {
-			id, hostname, err := VerifyCreated(tt.args.ctx, tt.args.spy, tt.args.networkID, tt.args.output)
+			id, hostname, err := VerifyCreated(tt.args.ctx, tt.args.spy, tt.args.networkID, tt.args.user, tt.args.password, tt.args.output)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("VerifyCreated() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -257,6 +278,43 @@ func TestVerifyCreated(t *testing.T) {
 	}
 }
 
+func TestVerifyCreatedReplacesChangedCredentials(t *testing.T) {
+	spy := &mockClient{
+		containers: []types.Container{
+			{
+				ID:     "existing-container-id",
+				Labels: map[string]string{containerlabels.ConfigHash: credentialsHash("nitro", "nitropassword")},
+				State:  "running",
+			},
+		},
+		containerCreateResponse: container.ContainerCreateCreatedBody{
+			ID: "new-container-id",
+		},
+	}
+
+	id, _, err := VerifyCreated(context.Background(), spy, "some-network-id", "craft", "supersecret", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id != "new-container-id" {
+		t.Errorf("expected the id to be new-container-id, got %s", id)
+	}
+
+	if spy.containerRemoveID != "existing-container-id" {
+		t.Errorf("expected the existing container to be removed, got %q", spy.containerRemoveID)
+	}
+
+	if spy.volumeCreateOptions.Name != "minio.service.nitro" {
+		t.Errorf("expected the data volume to be reused, got %q", spy.volumeCreateOptions.Name)
+	}
+
+	want := []string{"MINIO_ROOT_USER=craft", "MINIO_ROOT_PASSWORD=supersecret"}
+	if !reflect.DeepEqual(spy.containerCreateConfig.Config.Env, want) {
+		t.Errorf("expected the envs to be %v, got %v", want, spy.containerCreateConfig.Config.Env)
+	}
+}
+
 func TestVerifyRemoved(t *testing.T) {
 	type args struct {
 		ctx    context.Context
@@ -407,6 +465,9 @@ type mockClient struct {
 	containerCreateResponse container.ContainerCreateCreatedBody
 	containerCreateError    error
 
+	// volume create
+	volumeCreateOptions volumetypes.VolumeCreateBody
+
 	// mock start
 	containerStartID      string
 	containerStartOptions types.ContainerStartOptions
@@ -453,6 +514,12 @@ func (c *mockClient) ContainerCreate(ctx context.Context, config *container.Conf
 	return c.containerCreateResponse, c.containerCreateError
 }
 
+func (c *mockClient) VolumeCreate(ctx context.Context, options volumetypes.VolumeCreateBody) (types.Volume, error) {
+	c.volumeCreateOptions = options
+
+	return types.Volume{Name: options.Name}, nil
+}
+
 func (c *mockClient) ContainerStart(ctx context.Context, container string, options types.ContainerStartOptions) error {
 	c.containerStartID = container
 	c.containerStartOptions = options
@@ -0,0 +1,170 @@
+package rabbitmq
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/craftcms/nitro/pkg/containerlabels"
+	"github.com/craftcms/nitro/pkg/terminal"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+)
+
+const (
+	// Image is the image to use for the rabbitmq container, it includes the management ui
+	Image = "docker.io/library/rabbitmq:3-management"
+
+	// Host is the hostname for the rabbitmq container
+	Host = "rabbitmq.service.nitro"
+
+	// Label is the label value used to mark a container as a "rabbitmq" service
+	Label = "rabbitmq"
+
+	// Port is the amqp port for the rabbitmq container
+	Port = "5672"
+
+	// ManagementPort is the port for the management ui, which is exposed through the proxy
+	ManagementPort = 15672
+)
+
+// VerifyCreated will verify that the rabbitmq service container exists and is started
+func VerifyCreated(ctx context.Context, cli client.CommonAPIClient, networkID string, output terminal.Outputer) (string, string, error) {
+	// add the filter
+	filter := filters.NewArgs()
+	filter.Add("label", containerlabels.Nitro+"=true")
+	filter.Add("label", containerlabels.Type+"="+Label)
+
+	// get a list of containers
+	containers, err := cli.ContainerList(ctx, types.ContainerListOptions{
+		All:     true,
+		Filters: filter,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	// if there is not a container, create one
+	if len(containers) == 0 {
+		// pull the image
+		r, err := cli.ImagePull(ctx, Image, types.ImagePullOptions{})
+		if err != nil {
+			return "", "", err
+		}
+
+		// read from the buffer to pull the image
+		buf := &bytes.Buffer{}
+		if _, err := buf.ReadFrom(r); err != nil {
+			return "", "", fmt.Errorf("unable to read output while pulling image, %w", err)
+		}
+
+		// set the nitro env overrides
+		amqpPort := Port
+		if os.Getenv("NITRO_RABBITMQ_PORT") != "" {
+			amqpPort = os.Getenv("NITRO_RABBITMQ_PORT")
+		}
+
+		amqpPortNat, err := nat.NewPort("tcp", Port)
+		if err != nil {
+			return "", "", fmt.Errorf("unable to create the port, %w", err)
+		}
+
+		containerConfig := &container.Config{
+			Image: Image,
+			Labels: containerlabels.StampRunID(ctx, map[string]string{
+				containerlabels.Nitro: "true",
+				containerlabels.Type:  Label,
+			}),
+			ExposedPorts: nat.PortSet{
+				amqpPortNat: struct{}{},
+			},
+			Env: []string{"RABBITMQ_DEFAULT_USER=nitro", "RABBITMQ_DEFAULT_PASS=nitro"},
+		}
+
+		hostconfig := &container.HostConfig{
+			PortBindings: map[nat.Port][]nat.PortBinding{
+				amqpPortNat: {
+					{
+						HostIP:   "127.0.0.1",
+						HostPort: amqpPort,
+					},
+				},
+			},
+		}
+
+		networkConfig := &network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{
+				"nitro-network": {
+					NetworkID: networkID,
+				},
+			},
+		}
+
+		// create the container
+		resp, err := cli.ContainerCreate(ctx, containerConfig, hostconfig, networkConfig, nil, Host)
+		if err != nil {
+			return "", "", fmt.Errorf("unable to create the container, %w", err)
+		}
+
+		// start the container
+		if err := cli.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
+			return "", "", fmt.Errorf("unable to start the container, %w", err)
+		}
+
+		return resp.ID, Host, nil
+	}
+
+	// start the container if its not running
+	if containers[0].State != "running" {
+		if err := cli.ContainerStart(ctx, containers[0].ID, types.ContainerStartOptions{}); err != nil {
+			return "", "", fmt.Errorf("unable to start the container, %w", err)
+		}
+	}
+
+	return containers[0].ID, Host, nil
+}
+
+// VerifyRemoved will verify the container is not created for the rabbitmq service. If we find any
+// containers they are stopped and removed.
+func VerifyRemoved(ctx context.Context, cli client.CommonAPIClient, output terminal.Outputer) error {
+	// add the filter
+	filter := filters.NewArgs()
+	filter.Add("label", containerlabels.Nitro+"=true")
+	filter.Add("label", containerlabels.Type+"="+Label)
+
+	// get a list of containers
+	containers, err := cli.ContainerList(ctx, types.ContainerListOptions{
+		All:     true,
+		Filters: filter,
+	})
+	if err != nil {
+		return err
+	}
+
+	timeout := time.Duration(time.Second * 30)
+
+	// remove all of the containers
+	for _, c := range containers {
+		// stop the container if its running
+		if c.State == "running" {
+			if err := cli.ContainerStop(ctx, c.ID, &timeout); err != nil {
+				return err
+			}
+		}
+
+		// remove the container
+		if err := cli.ContainerRemove(ctx, c.ID, types.ContainerRemoveOptions{
+			RemoveVolumes: true,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
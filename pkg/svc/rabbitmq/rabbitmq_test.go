@@ -0,0 +1,183 @@
+package rabbitmq
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestVerifyCreated(t *testing.T) {
+	tests := []struct {
+		name string
+		spy  *mockClient
+
+		wantSpyImagePullImage   string
+		wantSpyContainerEnv     []string
+		wantSpyContainerStartID string
+		wantID                  string
+	}{
+		{
+			name: "container is created when it does not exist",
+			spy: &mockClient{
+				containerCreateResponse: container.ContainerCreateCreatedBody{ID: "someid"},
+			},
+			wantSpyImagePullImage:   "docker.io/library/rabbitmq:3-management",
+			wantSpyContainerEnv:     []string{"RABBITMQ_DEFAULT_USER=nitro", "RABBITMQ_DEFAULT_PASS=nitro"},
+			wantSpyContainerStartID: "someid",
+			wantID:                  "someid",
+		},
+		{
+			name: "containers that are already created are started",
+			spy: &mockClient{
+				containers: []types.Container{
+					{
+						ID:    "existing-container-id",
+						State: "exited",
+					},
+				},
+			},
+			wantSpyContainerStartID: "existing-container-id",
+			wantID:                  "existing-container-id",
+		},
+		{
+			name: "running containers are not started",
+			spy: &mockClient{
+				containers: []types.Container{
+					{
+						ID:    "existing-container-id",
+						State: "running",
+					},
+				},
+			},
+			wantID: "existing-container-id",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, hostname, err := VerifyCreated(context.Background(), tt.spy, "some-network-id", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if id != tt.wantID {
+				t.Errorf("expected the id to be %q, got %q", tt.wantID, id)
+			}
+
+			if hostname != "rabbitmq.service.nitro" {
+				t.Errorf("expected the hostname to be rabbitmq.service.nitro, got %q", hostname)
+			}
+
+			if tt.spy.imagePullImage != tt.wantSpyImagePullImage {
+				t.Errorf("expected the image pull images to match, got %s want %s", tt.spy.imagePullImage, tt.wantSpyImagePullImage)
+			}
+
+			var env []string
+			if tt.spy.containerCreateConfig.Config != nil {
+				env = tt.spy.containerCreateConfig.Config.Env
+
+				if tt.spy.containerCreateConfig.Name != "rabbitmq.service.nitro" {
+					t.Errorf("expected the container name to be rabbitmq.service.nitro, got %q", tt.spy.containerCreateConfig.Name)
+				}
+			}
+
+			if !reflect.DeepEqual(env, tt.wantSpyContainerEnv) {
+				t.Errorf("expected the container envs to match, got %v want %v", env, tt.wantSpyContainerEnv)
+			}
+
+			if tt.spy.containerStartID != tt.wantSpyContainerStartID {
+				t.Errorf("expected the container start ids to match, got %s want %s", tt.spy.containerStartID, tt.wantSpyContainerStartID)
+			}
+		})
+	}
+}
+
+func TestVerifyRemoved(t *testing.T) {
+	spy := &mockClient{
+		containers: []types.Container{
+			{
+				ID:    "existing-container-id",
+				State: "running",
+			},
+		},
+	}
+
+	if err := VerifyRemoved(context.Background(), spy, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if spy.containerStopID != "existing-container-id" {
+		t.Errorf("expected the container to be stopped, got %q", spy.containerStopID)
+	}
+
+	if spy.containerRemoveID != "existing-container-id" {
+		t.Errorf("expected the container to be removed, got %q", spy.containerRemoveID)
+	}
+}
+
+type mockClient struct {
+	client.CommonAPIClient
+
+	// mock storage
+	containers []types.Container
+
+	// container create
+	containerCreateConfig   types.ContainerCreateConfig
+	containerCreateResponse container.ContainerCreateCreatedBody
+
+	// mock start, stop, and remove
+	containerStartID  string
+	containerStopID   string
+	containerRemoveID string
+
+	// image pull
+	imagePullImage string
+}
+
+func (c *mockClient) ContainerList(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error) {
+	return c.containers, nil
+}
+
+func (c *mockClient) ContainerRemove(ctx context.Context, containerID string, opts types.ContainerRemoveOptions) error {
+	c.containerRemoveID = containerID
+
+	return nil
+}
+
+func (c *mockClient) ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *v1.Platform, containerName string) (container.ContainerCreateCreatedBody, error) {
+	c.containerCreateConfig = types.ContainerCreateConfig{
+		Name:             containerName,
+		Config:           config,
+		HostConfig:       hostConfig,
+		NetworkingConfig: networkingConfig,
+	}
+
+	return c.containerCreateResponse, nil
+}
+
+func (c *mockClient) ContainerStart(ctx context.Context, container string, options types.ContainerStartOptions) error {
+	c.containerStartID = container
+
+	return nil
+}
+
+func (c *mockClient) ContainerStop(ctx context.Context, containerID string, timeout *time.Duration) error {
+	c.containerStopID = containerID
+
+	return nil
+}
+
+func (c *mockClient) ImagePull(ctx context.Context, image string, opts types.ImagePullOptions) (io.ReadCloser, error) {
+	c.imagePullImage = image
+
+	return ioutil.NopCloser(strings.NewReader("")), nil
+}
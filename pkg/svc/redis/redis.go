@@ -18,8 +18,14 @@ import (
 )
 
 const (
-	// Image is the image to use for the redis container
-	Image = "docker.io/library/redis:latest"
+	// Image is the image to use for the redis container, with the version
+	Image = "docker.io/library/redis:%s"
+
+	// DefaultVersion is the redis version used when the config does not set one
+	DefaultVersion = "latest"
+
+	// Port is the port redis listens on in the container
+	Port = "6379"
 
 	// Host is the hostname for the redis container
 	Host = "redis.service.nitro"
@@ -28,8 +34,11 @@ const (
 	Label = "redis"
 )
 
-// VerifyCreated will verify that the redis service container exists and is started
-func VerifyCreated(ctx context.Context, cli client.CommonAPIClient, networkID string, output terminal.Outputer) (string, string, error) {
+// VerifyCreated will verify that the redis service container exists for the version and is started. If the
+// container uses a different version, it is removed and a new container is created.
+func VerifyCreated(ctx context.Context, cli client.CommonAPIClient, networkID, version string, output terminal.Outputer) (string, string, error) {
+	image := ImageForVersion(version)
+
 	// add the filter
 	filter := filters.NewArgs()
 	filter.Add("label", containerlabels.Nitro+"=true")
@@ -46,84 +55,106 @@ func VerifyCreated(ctx context.Context, cli client.CommonAPIClient, networkID st
 
 	// if there is not a container, create one
 	if len(containers) == 0 {
-		// pull the image
-		r, err := cli.ImagePull(ctx, Image, types.ImagePullOptions{})
-		if err != nil {
+		return create(ctx, cli, networkID, image)
+	}
+
+	// if the version changed, replace the container
+	if containers[0].Image != image {
+		if err := VerifyRemoved(ctx, cli, output); err != nil {
 			return "", "", err
 		}
 
-		// read from the buffer to pull the image
-		buf := &bytes.Buffer{}
-		if _, err := buf.ReadFrom(r); err != nil {
-			return "", "", fmt.Errorf("unable to read output while pulling image, %w", err)
-		}
+		return create(ctx, cli, networkID, image)
+	}
 
-		// set the nitro env overrides
-		httpPort := "6379"
-		if os.Getenv("NITRO_REDIS_PORT") != "" {
-			httpPort = os.Getenv("NITRO_REDIS_PORT")
+	// start each of the containers, there should only be one so the final return is an error
+	for _, c := range containers {
+		// start the container
+		if c.Status != "running" {
+			if err := cli.ContainerStart(ctx, c.ID, types.ContainerStartOptions{}); err != nil {
+				return "", "", fmt.Errorf("unable to start the container, %w", err)
+			}
 		}
+	}
 
-		httpPortNat, err := nat.NewPort("tcp", "6379")
-		if err != nil {
-			return "", "", fmt.Errorf("unable to create the port, %w", err)
-		}
+	return containers[0].ID, Host, nil
+}
 
-		containerConfig := &container.Config{
-			Image: Image,
-			Labels: map[string]string{
-				containerlabels.Nitro: "true",
-				containerlabels.Type:  Label,
-			},
-			ExposedPorts: nat.PortSet{
-				httpPortNat: struct{}{},
-			},
-		}
+// ImageForVersion returns the image for the redis version, an empty version uses the DefaultVersion.
+func ImageForVersion(version string) string {
+	if version == "" {
+		version = DefaultVersion
+	}
 
-		hostconfig := &container.HostConfig{
-			PortBindings: map[nat.Port][]nat.PortBinding{
-				httpPortNat: {
-					{
-						HostIP:   "127.0.0.1",
-						HostPort: httpPort,
-					},
-				},
-			},
-		}
+	return fmt.Sprintf(Image, version)
+}
 
-		networkConfig := &network.NetworkingConfig{
-			EndpointsConfig: map[string]*network.EndpointSettings{
-				"nitro-network": {
-					NetworkID: networkID,
+func create(ctx context.Context, cli client.CommonAPIClient, networkID, image string) (string, string, error) {
+	// pull the image
+	r, err := cli.ImagePull(ctx, image, types.ImagePullOptions{})
+	if err != nil {
+		return "", "", err
+	}
+
+	// read from the buffer to pull the image
+	buf := &bytes.Buffer{}
+	if _, err := buf.ReadFrom(r); err != nil {
+		return "", "", fmt.Errorf("unable to read output while pulling image, %w", err)
+	}
+
+	// set the nitro env overrides
+	httpPort := Port
+	if os.Getenv("NITRO_REDIS_PORT") != "" {
+		httpPort = os.Getenv("NITRO_REDIS_PORT")
+	}
+
+	httpPortNat, err := nat.NewPort("tcp", Port)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to create the port, %w", err)
+	}
+
+	containerConfig := &container.Config{
+		Image: image,
+		Labels: containerlabels.StampRunID(ctx, map[string]string{
+			containerlabels.Nitro: "true",
+			containerlabels.Type:  Label,
+		}),
+		ExposedPorts: nat.PortSet{
+			httpPortNat: struct{}{},
+		},
+	}
+
+	hostconfig := &container.HostConfig{
+		PortBindings: map[nat.Port][]nat.PortBinding{
+			httpPortNat: {
+				{
+					HostIP:   "127.0.0.1",
+					HostPort: httpPort,
 				},
 			},
-		}
-
-		// create the container
-		resp, err := cli.ContainerCreate(ctx, containerConfig, hostconfig, networkConfig, nil, Host)
-		if err != nil {
-			return "", "", fmt.Errorf("unable to create the container, %w", err)
-		}
+		},
+	}
 
-		// start the container
-		if err := cli.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
-			return "", "", fmt.Errorf("unable to start the container, %w", err)
-		}
+	networkConfig := &network.NetworkingConfig{
+		EndpointsConfig: map[string]*network.EndpointSettings{
+			"nitro-network": {
+				NetworkID: networkID,
+			},
+		},
+	}
 
-		return resp.ID, Host, nil
+	// create the container
+	resp, err := cli.ContainerCreate(ctx, containerConfig, hostconfig, networkConfig, nil, Host)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to create the container, %w", err)
 	}
 
-	// start each of the containers, there should only be one so the final return is an error
-	for _, c := range containers {
-		// start the container
-		if c.Status != "running" {
-			if err := cli.ContainerStart(ctx, c.ID, types.ContainerStartOptions{}); err != nil {
-				return "", "", fmt.Errorf("unable to start the container, %w", err)
-			}
-		}
+	// start the container
+	if err := cli.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
+		return "", "", fmt.Errorf("unable to start the container, %w", err)
 	}
 
-	return containers[0].ID, Host, nil
+	return resp.ID, Host, nil
 }
 
 // VerifyRemoved will try verify the container is not created for the minio service. If we find any containers that are
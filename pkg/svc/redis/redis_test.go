@@ -27,6 +27,7 @@ func TestVerifyCreated(t *testing.T) {
 		ctx       context.Context
 		spy       *mockClient
 		networkID string
+		version   string
 		output    terminal.Outputer
 	}
 	tests := []struct {
@@ -103,6 +104,116 @@ func TestVerifyCreated(t *testing.T) {
 			wantHostname:            "redis.service.nitro",
 			wantErr:                 false,
 		},
+		{
+			name: "containers are labeled with the run id from the context",
+			args: args{
+				ctx: containerlabels.WithRunID(context.Background(), "abc123"),
+				spy: &mockClient{
+					containerCreateResponse: container.ContainerCreateCreatedBody{
+						ID: "someid",
+					},
+				},
+				networkID: "some-network-id",
+			},
+			wantSpyContainerListOptions: types.ContainerListOptions{
+				All: true,
+				Filters: filters.NewArgs(
+					filters.KeyValuePair{Key: "label", Value: containerlabels.Nitro + "=true"},
+					filters.KeyValuePair{Key: "label", Value: containerlabels.Type + "=redis"},
+				),
+			},
+			wantSpyImagePullImage: "docker.io/library/redis:latest",
+			wantSpyContainerCreateConfig: types.ContainerCreateConfig{
+				Name: "redis.service.nitro",
+				Config: &container.Config{
+					Image: "docker.io/library/redis:latest",
+					Labels: map[string]string{
+						containerlabels.Nitro: "true",
+						containerlabels.Type:  "redis",
+						containerlabels.RunID: "abc123",
+					},
+					ExposedPorts: nat.PortSet{
+						"6379/tcp": struct{}{},
+					},
+				},
+				HostConfig: &container.HostConfig{
+					PortBindings: map[nat.Port][]nat.PortBinding{
+						"6379/tcp": {
+							{
+								HostIP:   "127.0.0.1",
+								HostPort: "6379",
+							},
+						},
+					},
+				},
+				NetworkingConfig: &network.NetworkingConfig{
+					EndpointsConfig: map[string]*network.EndpointSettings{
+						"nitro-network": {
+							NetworkID: "some-network-id",
+						},
+					},
+				},
+			},
+			wantSpyContainerStartID: "someid",
+			wantID:                  "someid",
+			wantHostname:            "redis.service.nitro",
+			wantErr:                 false,
+		},
+		{
+			name: "the version from the config is used for the image",
+			args: args{
+				ctx: context.Background(),
+				spy: &mockClient{
+					containerCreateResponse: container.ContainerCreateCreatedBody{
+						ID: "someid",
+					},
+				},
+				networkID: "some-network-id",
+				version:   "6.2",
+			},
+			wantSpyContainerListOptions: types.ContainerListOptions{
+				All: true,
+				Filters: filters.NewArgs(
+					filters.KeyValuePair{Key: "label", Value: containerlabels.Nitro + "=true"},
+					filters.KeyValuePair{Key: "label", Value: containerlabels.Type + "=redis"},
+				),
+			},
+			wantSpyImagePullImage: "docker.io/library/redis:6.2",
+			wantSpyContainerCreateConfig: types.ContainerCreateConfig{
+				Name: "redis.service.nitro",
+				Config: &container.Config{
+					Image: "docker.io/library/redis:6.2",
+					Labels: map[string]string{
+						containerlabels.Nitro: "true",
+						containerlabels.Type:  "redis",
+					},
+					ExposedPorts: nat.PortSet{
+						"6379/tcp": struct{}{},
+					},
+				},
+				HostConfig: &container.HostConfig{
+					PortBindings: map[nat.Port][]nat.PortBinding{
+						"6379/tcp": {
+							{
+								HostIP:   "127.0.0.1",
+								HostPort: "6379",
+							},
+						},
+					},
+				},
+				NetworkingConfig: &network.NetworkingConfig{
+					EndpointsConfig: map[string]*network.EndpointSettings{
+						"nitro-network": {
+							NetworkID: "some-network-id",
+						},
+					},
+				},
+			},
+			wantSpyContainerStartID: "someid",
+			wantID:                  "someid",
+			wantHostname:            "redis.service.nitro",
+			wantErr:                 false,
+		},
 		{
 			name: "custom ports are used when the environment variables are set",
 			args: args{
@@ -168,6 +279,7 @@ func TestVerifyCreated(t *testing.T) {
 					containers: []types.Container{
 						{
 							ID:    "existing-container-id",
+							Image: "docker.io/library/redis:latest",
 							State: "not-running",
 						},
 					},
@@ -214,7 +326,7 @@ func TestVerifyCreated(t *testing.T) {
 		}
 
 		t.Run(tt.name, func(t *testing.T) {
-			id, hostname, err := VerifyCreated(tt.args.ctx, tt.args.spy, tt.args.networkID, tt.args.output)
+			id, hostname, err := VerifyCreated(tt.args.ctx, tt.args.spy, tt.args.networkID, tt.args.version, tt.args.output)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("VerifyCreated() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -252,6 +364,38 @@ func TestVerifyCreated(t *testing.T) {
 	}
 }
 
+func TestVerifyCreatedReplacesOtherVersions(t *testing.T) {
+	spy := &mockClient{
+		containers: []types.Container{
+			{
+				ID:    "existing-container-id",
+				Image: "docker.io/library/redis:latest",
+				State: "running",
+			},
+		},
+		containerCreateResponse: container.ContainerCreateCreatedBody{
+			ID: "new-container-id",
+		},
+	}
+
+	id, _, err := VerifyCreated(context.Background(), spy, "some-network-id", "6.2", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id != "new-container-id" {
+		t.Errorf("expected the id to be new-container-id, got %s", id)
+	}
+
+	if spy.containerStopID != "existing-container-id" || spy.containerRemoveID != "existing-container-id" {
+		t.Errorf("expected the existing container to be stopped and removed, got stop %q and remove %q", spy.containerStopID, spy.containerRemoveID)
+	}
+
+	if spy.imagePullImage != "docker.io/library/redis:6.2" {
+		t.Errorf("expected the image to be docker.io/library/redis:6.2, got %s", spy.imagePullImage)
+	}
+}
+
 func TestVerifyRemoved(t *testing.T) {
 	type args struct {
 		ctx    context.Context
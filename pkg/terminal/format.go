@@ -0,0 +1,33 @@
+package terminal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const (
+	// FormatText is the default output format for commands.
+	FormatText = "text"
+
+	// FormatJSON is used by editor integrations and scripts to read the output of a command.
+	FormatJSON = "json"
+)
+
+// ValidateFormat returns an error if the format for the --output flag is not supported.
+func ValidateFormat(format string) error {
+	switch format {
+	case FormatText, FormatJSON:
+		return nil
+	}
+
+	return fmt.Errorf("unknown output format %q, the format must be %s or %s", format, FormatText, FormatJSON)
+}
+
+// JSON writes the value to w as indented JSON.
+func JSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(v)
+}
@@ -0,0 +1,200 @@
+package terminal
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+const (
+	// cursorUp moves the cursor up a number of lines
+	cursorUp = "\033[%dA"
+
+	// clearDown clears the line the cursor is on and every line below it
+	clearDown = "\033[J"
+)
+
+// Renderer serializes the output from multiple workers that run at the same time, such as
+// containers being created in parallel. Each worker gets its own Outputer and every write
+// to the terminal is done while holding a lock so lines are never interleaved. When the
+// renderer is multiline, the pending line for each worker is redrawn in place at the bottom
+// of the output using cursor movement. Otherwise, pending lines are written when they are
+// done so the output is safe to write to files and CI logs.
+type Renderer struct {
+	mu        sync.Mutex
+	w         io.Writer
+	multiline bool
+
+	// workers are the workers in the order they were created
+	workers []*worker
+
+	// drawn is the number of pending lines currently drawn in multiline mode
+	drawn int
+}
+
+// NewRenderer returns a renderer that writes to w. Multiline rendering should only be used
+// when w is a terminal, see IsTerminal.
+func NewRenderer(w io.Writer, multiline bool) *Renderer {
+	return &Renderer{w: w, multiline: multiline}
+}
+
+// NewOutputRenderer returns a renderer that writes each line to the output, so the workers
+// output is also written where the output goes (e.g. the apply log with Tee). Pending lines
+// are written when they are done, since the output may not be a terminal.
+func NewOutputRenderer(output Outputer) *Renderer {
+	return NewRenderer(&lineWriter{output: output}, false)
+}
+
+// IsTerminal returns true if the file is a terminal (e.g. os.Stdout is not redirected to a file).
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// Worker returns an Outputer for a single worker. The Outputer should not be shared
+// between goroutines.
+func (r *Renderer) Worker() Outputer {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	w := &worker{renderer: r}
+	r.workers = append(r.workers, w)
+
+	return w
+}
+
+// write writes the complete lines and redraws the pending lines.
+func (r *Renderer) write(lines string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.multiline {
+		fmt.Fprint(r.w, lines)
+		return
+	}
+
+	// remove the pending lines so the output is written above them
+	if r.drawn > 0 {
+		fmt.Fprintf(r.w, cursorUp, r.drawn)
+		fmt.Fprint(r.w, clearDown)
+	}
+
+	fmt.Fprint(r.w, lines)
+
+	r.drawn = 0
+	for _, w := range r.workers {
+		if w.pending == "" {
+			continue
+		}
+
+		fmt.Fprintln(r.w, w.pending)
+		r.drawn++
+	}
+}
+
+// worker is the Outputer for a single worker of a renderer.
+type worker struct {
+	renderer *Renderer
+	pending  string
+}
+
+// Ask is not supported while running concurrently, so the prompt is shown with the
+// lock held to prevent other workers from writing.
+func (w *worker) Ask(message, fallback, sep string, validator Validator) (string, error) {
+	w.renderer.mu.Lock()
+	defer w.renderer.mu.Unlock()
+
+	return New().Ask(message, fallback, sep, validator)
+}
+
+// Confirm shows the prompt with the lock held to prevent other workers from writing.
+func (w *worker) Confirm(message string, fallback bool, sep string) (bool, error) {
+	w.renderer.mu.Lock()
+	defer w.renderer.mu.Unlock()
+
+	return New().Confirm(message, fallback, sep)
+}
+
+// Select shows the options with the lock held to prevent other workers from writing.
+func (w *worker) Select(r io.Reader, msg string, opts []string) (int, error) {
+	w.renderer.mu.Lock()
+	defer w.renderer.mu.Unlock()
+
+	return New().Select(r, msg, opts)
+}
+
+func (w *worker) Info(s ...string) {
+	w.renderer.write(strings.Join(s, " ") + "\n")
+}
+
+func (w *worker) Success(s ...string) {
+	w.renderer.write(fmt.Sprintf("  ✓ %s\n", strings.Join(s, " ")))
+}
+
+func (w *worker) Pending(s ...string) {
+	w.setPending(fmt.Sprintf("  … %s", strings.Join(s, " ")))
+
+	// only redraw when the pending line is shown
+	if w.renderer.multiline {
+		w.renderer.write("")
+	}
+}
+
+func (w *worker) Done() {
+	w.finish("✓")
+}
+
+func (w *worker) Warning() {
+	w.finish("✗")
+}
+
+// finish writes the pending line with the status and removes it from the pending lines.
+func (w *worker) finish(status string) {
+	line := w.setPending("")
+	if line == "" {
+		w.renderer.write(status + "\n")
+		return
+	}
+
+	w.renderer.write(line + " " + status + "\n")
+}
+
+// setPending sets the pending line and returns the previous one.
+func (w *worker) setPending(line string) string {
+	w.renderer.mu.Lock()
+	defer w.renderer.mu.Unlock()
+
+	previous := w.pending
+	w.pending = line
+
+	return previous
+}
+
+// lineWriter writes each complete line to an Outputer.
+type lineWriter struct {
+	output Outputer
+	buf    []byte
+}
+
+func (l *lineWriter) Write(p []byte) (int, error) {
+	l.buf = append(l.buf, p...)
+
+	for {
+		i := bytes.IndexByte(l.buf, '\n')
+		if i == -1 {
+			break
+		}
+
+		l.output.Info(string(l.buf[:i]))
+		l.buf = l.buf[i+1:]
+	}
+
+	return len(p), nil
+}
@@ -0,0 +1,126 @@
+package terminal
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRendererConcurrentWrites(t *testing.T) {
+	buf := &bytes.Buffer{}
+	r := NewRenderer(buf, false)
+
+	workers, steps := 10, 50
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		output := r.Worker()
+
+		wg.Add(1)
+		go func(i int, output Outputer) {
+			defer wg.Done()
+
+			for j := 0; j < steps; j++ {
+				output.Pending("worker", fmt.Sprint(i), "step", fmt.Sprint(j))
+				output.Info("info", fmt.Sprint(i), fmt.Sprint(j))
+				output.Done()
+			}
+		}(i, output)
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != workers*steps*2 {
+		t.Fatalf("expected %d lines, got %d", workers*steps*2, len(lines))
+	}
+
+	// every line should be complete and in order for each worker
+	next := make(map[int]int)
+	for _, l := range lines {
+		var i, j int
+		if strings.HasPrefix(l, "info") {
+			if _, err := fmt.Sscanf(l, "info %d %d", &i, &j); err != nil {
+				t.Fatalf("unexpected line %q", l)
+			}
+
+			continue
+		}
+
+		if _, err := fmt.Sscanf(l, "  … worker %d step %d ✓", &i, &j); err != nil || !strings.HasSuffix(l, " ✓") {
+			t.Fatalf("unexpected line %q", l)
+		}
+
+		if j != next[i] {
+			t.Errorf("expected worker %d to be on step %d, got %d", i, next[i], j)
+		}
+
+		next[i]++
+	}
+}
+
+func TestRendererWritesPendingLinesWhenDone(t *testing.T) {
+	buf := &bytes.Buffer{}
+	r := NewRenderer(buf, false)
+
+	one, two := r.Worker(), r.Worker()
+
+	one.Pending("creating", "one")
+	two.Pending("creating", "two")
+	two.Warning()
+	one.Done()
+	one.Success("finished")
+
+	want := "  … creating two ✗\n  … creating one ✓\n  ✓ finished\n"
+	if got := buf.String(); got != want {
+		t.Errorf("expected the output to be %q, got %q", want, got)
+	}
+}
+
+func TestRendererMultiline(t *testing.T) {
+	buf := &bytes.Buffer{}
+	r := NewRenderer(buf, true)
+
+	one, two := r.Worker(), r.Worker()
+
+	one.Pending("creating one")
+	two.Pending("creating two")
+	one.Done()
+
+	want := strings.Join([]string{
+		// the first pending line is drawn
+		"  … creating one\n",
+		// both pending lines are redrawn
+		"\033[1A\033[J",
+		"  … creating one\n  … creating two\n",
+		// the completed line is written above the remaining pending line
+		"\033[2A\033[J",
+		"  … creating one ✓\n  … creating two\n",
+	}, "")
+
+	if got := buf.String(); got != want {
+		t.Errorf("expected the output to be %q, got %q", want, got)
+	}
+}
+
+func TestOutputRendererWritesLinesToTheOutput(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := &bytes.Buffer{}
+	r := NewOutputRenderer(Tee(NewRenderer(buf, false).Worker(), log))
+
+	one, two := r.Worker(), r.Worker()
+
+	one.Pending("creating", "one")
+	two.Info("created two")
+	one.Done()
+
+	want := "created two\n  … creating one ✓\n"
+	if got := buf.String(); got != want {
+		t.Errorf("expected the output to be %q, got %q", want, got)
+	}
+
+	if got := log.String(); got != want {
+		t.Errorf("expected the log to be %q, got %q", want, got)
+	}
+}
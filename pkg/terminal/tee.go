@@ -0,0 +1,44 @@
+package terminal
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// tee writes the output to w as well as the Outputer. Prompts are only shown by the Outputer.
+type tee struct {
+	Outputer
+	w io.Writer
+}
+
+// Tee returns an Outputer that also writes the output to w, which is used to keep a log of a
+// commands output (e.g. the output from the last apply for bug reports).
+func Tee(output Outputer, w io.Writer) Outputer {
+	return &tee{Outputer: output, w: w}
+}
+
+func (t *tee) Info(s ...string) {
+	t.Outputer.Info(s...)
+	fmt.Fprintf(t.w, "%s\n", strings.Join(s, " "))
+}
+
+func (t *tee) Success(s ...string) {
+	t.Outputer.Success(s...)
+	fmt.Fprintf(t.w, "  \u2713 %s\n", strings.Join(s, " "))
+}
+
+func (t *tee) Pending(s ...string) {
+	t.Outputer.Pending(s...)
+	fmt.Fprintf(t.w, "  … %s ", strings.Join(s, " "))
+}
+
+func (t *tee) Done() {
+	t.Outputer.Done()
+	fmt.Fprint(t.w, "\u2713\n")
+}
+
+func (t *tee) Warning() {
+	t.Outputer.Warning()
+	fmt.Fprint(t.w, "\u2717\n")
+}
@@ -0,0 +1,29 @@
+package terminal
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTee(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := &bytes.Buffer{}
+
+	output := Tee(NewRenderer(out, false).Worker(), log)
+
+	output.Info("Checking Nitro…")
+	output.Pending("creating network")
+	output.Done()
+	output.Success("proxy ready")
+	output.Pending("starting tutorial.nitro")
+	output.Warning()
+
+	want := "Checking Nitro…\n  … creating network ✓\n  ✓ proxy ready\n  … starting tutorial.nitro ✗\n"
+	if got := log.String(); got != want {
+		t.Errorf("expected the log to be %q, got %q", want, got)
+	}
+
+	if out.Len() == 0 {
+		t.Errorf("expected the output to also be written to the outputer")
+	}
+}
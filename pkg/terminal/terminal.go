@@ -109,8 +109,6 @@ func (t *terminal) Confirm(message string, fallback bool, sep string) (bool, err
 		default:
 			return fallback, nil
 		}
-
-		break
 	}
 	if err := s.Err(); err != nil {
 		return fallback, err
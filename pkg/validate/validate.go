@@ -3,6 +3,7 @@ package validate
 import (
 	"errors"
 	"fmt"
+	"net/mail"
 	"strconv"
 	"strings"
 )
@@ -55,6 +56,17 @@ func (v *HostnameValidator) Validate(input string) error {
 	return nil
 }
 
+// EmailValidator is used to validate a provided email address
+type EmailValidator struct{}
+
+func (v *EmailValidator) Validate(input string) error {
+	if _, err := mail.ParseAddress(input); err != nil || !strings.Contains(input, "@") || strings.ContainsAny(input, "<> ") {
+		return fmt.Errorf("email must be a valid email address")
+	}
+
+	return nil
+}
+
 // IntegerValidator validates if the input is a valid integer
 type IntegerValidator struct{}
 
@@ -89,6 +89,41 @@ func TestHostnameValidator_Validate(t *testing.T) {
 	}
 }
 
+func TestEmailValidator_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{
+			name:  "valid emails do not return an err",
+			input: "admin@example.com",
+		},
+		{
+			name:    "missing the at sign returns an err",
+			input:   "admin.example.com",
+			wantErr: true,
+		},
+		{
+			name:    "names return an err",
+			input:   "Admin <admin@example.com>",
+			wantErr: true,
+		},
+		{
+			name:    "empty emails return an err",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &EmailValidator{}
+			if err := v.Validate(tt.input); (err != nil) != tt.wantErr {
+				t.Errorf("EmailValidator.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestPHPVersionValidator_Validate(t *testing.T) {
 	type args struct {
 		input string